@@ -13,8 +13,9 @@ import (
 	"github.com/aeromaintain/amss/internal/api/rest"
 	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/config"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/aeromaintain/amss/internal/infra/blobstore"
 	postgresinfra "github.com/aeromaintain/amss/internal/infra/postgres"
-	redisinfra "github.com/aeromaintain/amss/internal/infra/redis"
 	"github.com/aeromaintain/amss/internal/jobs"
 	"github.com/aeromaintain/amss/pkg/auth"
 	"github.com/aeromaintain/amss/pkg/observability"
@@ -73,7 +74,18 @@ func main() {
 		logger.Fatal().Err(err).Msg("failed to parse jwt public key")
 	}
 
-	restHandler := rest.NewRouter(rest.Deps{
+	blobs, err := blobstore.New(cfg.AttachmentStore, cfg.AttachmentStorageDir, blobstore.S3Store{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		Bucket:          cfg.S3Bucket,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to set up attachment storage")
+	}
+
+	restDeps := rest.Deps{
 		Logger:             logger,
 		DB:                 dbpool,
 		Redis:              redisClient,
@@ -84,8 +96,18 @@ func main() {
 		RefreshTokenTTL:    cfg.RefreshTokenTTL,
 		AppEnv:             cfg.AppEnv,
 		ImportStorageDir:   cfg.ImportStorageDir,
+		Blobs:              blobs,
 		CorsAllowedOrigins: cfg.CorsAllowedOrigins,
-	})
+		ProgramLookAhead: domain.ProgramLookAhead{
+			Days:        cfg.ProgramLookAheadDays,
+			FlightHours: cfg.ProgramLookAheadHours,
+			Cycles:      cfg.ProgramLookAheadCycles,
+		},
+	}
+	// REST and gRPC share one set of services so both run the same checks.
+	registry := rest.NewServices(restDeps)
+	restDeps.Services = &registry
+	restHandler := rest.NewRouter(restDeps)
 	restHandler = otelhttp.NewHandler(restHandler, "http-server")
 
 	httpServer := &http.Server{
@@ -94,28 +116,6 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	taskService := &services.TaskService{
-		Tasks:        &postgresinfra.TaskRepository{DB: dbpool},
-		Aircraft:     &postgresinfra.AircraftRepository{DB: dbpool},
-		Reservations: &postgresinfra.PartReservationRepository{DB: dbpool},
-		Compliance:   &postgresinfra.ComplianceRepository{DB: dbpool},
-		Certs:        &postgresinfra.CertificationRepository{DB: dbpool},
-		Audit:        &postgresinfra.AuditRepository{DB: dbpool},
-		Outbox:       &postgresinfra.OutboxRepository{DB: dbpool},
-	}
-	partService := &services.PartReservationService{
-		Reservations: &postgresinfra.PartReservationRepository{DB: dbpool},
-		PartItems:    &postgresinfra.PartItemRepository{DB: dbpool},
-		Tasks:        &postgresinfra.TaskRepository{DB: dbpool},
-		Locker:       &redisinfra.Locker{Client: redisClient},
-		Audit:        &postgresinfra.AuditRepository{DB: dbpool},
-		Outbox:       &postgresinfra.OutboxRepository{DB: dbpool},
-	}
-	programService := &services.MaintenanceProgramService{
-		Programs: &postgresinfra.MaintenanceProgramRepository{DB: dbpool},
-		Tasks:    &postgresinfra.TaskRepository{DB: dbpool},
-		TaskSvc:  taskService,
-	}
 	auditService := &services.AuditService{
 		Repo: &postgresinfra.AuditRepository{DB: dbpool},
 	}
@@ -123,10 +123,10 @@ func main() {
 	grpcServer := grpcapi.NewServer(grpcapi.Deps{
 		Logger:        logger,
 		AuthPublicKey: publicKey,
-		Tasks:         taskService,
-		Parts:         partService,
+		Tasks:         registry.Tasks,
+		Parts:         registry.Parts,
 		Audit:         auditService,
-		Programs:      programService,
+		Programs:      registry.Programs,
 	})
 	grpcListener, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
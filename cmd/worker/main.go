@@ -7,8 +7,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aeromaintain/amss/internal/api/rest"
 	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/config"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/aeromaintain/amss/internal/infra/blobstore"
 	"github.com/aeromaintain/amss/internal/infra/postgres"
 	"github.com/aeromaintain/amss/internal/jobs"
 	"github.com/aeromaintain/amss/pkg/observability"
@@ -57,6 +60,17 @@ func main() {
 	}
 	defer func() { _ = redisClient.Close() }()
 
+	blobs, err := blobstore.New(cfg.AttachmentStore, cfg.AttachmentStorageDir, blobstore.S3Store{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		Bucket:          cfg.S3Bucket,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to set up attachment storage")
+	}
+
 	auditRepo := &postgres.AuditRepository{DB: dbpool}
 	outboxRepo := &postgres.OutboxRepository{DB: dbpool}
 	webhookRepo := &postgres.WebhookRepository{DB: dbpool}
@@ -73,25 +87,53 @@ func main() {
 	importRepo := &postgres.ImportRepository{DB: dbpool}
 	importRowRepo := &postgres.ImportRowRepository{DB: dbpool}
 	retentionRepo := &postgres.RetentionRepository{DB: dbpool}
+	utilizationRepo := &postgres.AircraftUtilizationRepository{DB: dbpool}
 
 	certRepo := &postgres.CertificationRepository{DB: dbpool}
+	capacityService := &services.CapacityService{
+		Stations: &postgres.StationRepository{DB: dbpool},
+		Bays:     &postgres.HangarBayRepository{DB: dbpool},
+		Windows:  &postgres.BayCapacityWindowRepository{DB: dbpool},
+		Tasks:    taskRepo,
+		Audit:    auditRepo,
+	}
 	taskService := &services.TaskService{
 		Tasks:        taskRepo,
 		Aircraft:     aircraftRepo,
 		Reservations: reservationRepo,
 		Compliance:   complianceRepo,
 		Certs:        certRepo,
+		Capacity:     capacityService,
 		Audit:        auditRepo,
 		Outbox:       outboxRepo,
 	}
 	programService := &services.MaintenanceProgramService{
-		Programs: programRepo,
-		Tasks:    taskRepo,
-		TaskSvc:  taskService,
+		Programs:    programRepo,
+		Aircraft:    aircraftRepo,
+		Utilization: utilizationRepo,
+		Tasks:       taskRepo,
+		TaskSvc:     taskService,
+		LookAhead: domain.ProgramLookAhead{
+			Days:        cfg.ProgramLookAheadDays,
+			FlightHours: cfg.ProgramLookAheadHours,
+			Cycles:      cfg.ProgramLookAheadCycles,
+		},
 	}
 	policyService := &services.OrgPolicyService{
 		Policies: policyRepo,
 	}
+	utilizationService := &services.AircraftUtilizationService{
+		Utilization: utilizationRepo,
+		Aircraft:    aircraftRepo,
+		Audit:       auditRepo,
+		Outbox:      outboxRepo,
+	}
+	flightService := &services.FlightScheduleService{
+		Flights:  &postgres.PlannedFlightRepository{DB: dbpool},
+		Aircraft: aircraftRepo,
+		Audit:    auditRepo,
+		Outbox:   outboxRepo,
+	}
 
 	outboxPublisher := &jobs.OutboxPublisher{
 		Outbox:      outboxRepo,
@@ -109,6 +151,12 @@ func main() {
 		Policies:   policyService,
 		Logger:     logger,
 	}
+	templateService := &services.ProgramTemplateService{
+		Templates: &postgres.MaintenanceProgramTemplateRepository{DB: dbpool},
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+		Audit:     auditRepo,
+	}
 	importProcessor := &jobs.ImportProcessor{
 		Redis:       redisClient,
 		Imports:     importRepo,
@@ -117,8 +165,14 @@ func main() {
 		Definitions: defRepo,
 		Items:       partItemRepo,
 		Programs:    programRepo,
-		Logger:      logger,
-		WorkerID:    cfg.WorkerID,
+		Utilization: utilizationService,
+		Flights:     flightService,
+		Templates:   templateService,
+		Defects: &services.DefectService{
+			Defects: &postgres.DefectRepository{DB: dbpool},
+		},
+		Logger:   logger,
+		WorkerID: cfg.WorkerID,
 	}
 	programGenerator := &jobs.ProgramGenerator{
 		Programs: programService,
@@ -128,15 +182,42 @@ func main() {
 		Orgs:      orgRepo,
 		Retention: retentionRepo,
 		Policies:  policyService,
-		Logger:    logger,
-		Interval:  cfg.RetentionCleanupInterval,
+		Attachments: &services.AttachmentService{
+			Attachments: &postgres.AttachmentRepository{DB: dbpool},
+			Blobs:       blobs,
+		},
+		Logger:   logger,
+		Interval: cfg.RetentionCleanupInterval,
 	}
-	alertTrigger := &jobs.AlertTrigger{
-		DB:       dbpool,
-		Alerts:   &postgres.AlertRepository{DB: dbpool},
+	// The option generator shares the API's services so generated options
+	// pass the same flight, roster and capacity checks as a reschedule.
+	registry := rest.NewServices(rest.Deps{
+		Logger: logger,
+		DB:     dbpool,
+		Redis:  redisClient,
+		AppEnv: cfg.AppEnv,
+		Blobs:  blobs,
+		ProgramLookAhead: domain.ProgramLookAhead{
+			Days:        cfg.ProgramLookAheadDays,
+			FlightHours: cfg.ProgramLookAheadHours,
+			Cycles:      cfg.ProgramLookAheadCycles,
+		},
+	})
+	rescheduleOptionGenerator := &jobs.RescheduleOptionGenerator{
+		Options:  registry.RescheduleOptions,
 		Logger:   logger,
 		Interval: 15 * time.Minute,
 	}
+	alertTrigger := &jobs.AlertTrigger{
+		DB:                      dbpool,
+		Alerts:                  &postgres.AlertRepository{DB: dbpool},
+		PartItems:               partItemRepo,
+		Defects:                 &postgres.DefectRepository{DB: dbpool},
+		Logger:                  logger,
+		Interval:                15 * time.Minute,
+		PartLifeWarningPercent:  cfg.PartLifeWarningPercent,
+		PartLifeCriticalPercent: cfg.PartLifeCriticalPercent,
+	}
 
 	go outboxPublisher.Run(ctx)
 	go webhookDispatcher.Run(ctx)
@@ -144,6 +225,7 @@ func main() {
 	go programGenerator.Run(ctx)
 	go retentionCleaner.Run(ctx)
 	go alertTrigger.Run(ctx)
+	go rescheduleOptionGenerator.Run(ctx)
 
 	logger.Info().Str("worker_id", cfg.WorkerID).Msg("worker started")
 	<-ctx.Done()
@@ -24,9 +24,9 @@ func (s *ProgramServiceServer) GenerateTasks(ctx context.Context, req *amssv1.Ge
 	if actor.Role != domain.RoleAdmin {
 		return nil, mapError(domain.ErrForbidden)
 	}
-	created, err := s.Programs.GenerateDueTasks(ctx, actor, 100)
+	result, err := s.Programs.GenerateDueTasks(ctx, actor, 100)
 	if err != nil {
 		return nil, mapError(err)
 	}
-	return &amssv1.GenerateTasksResponse{Created: int32(created)}, nil
+	return &amssv1.GenerateTasksResponse{Created: int32(result.Created)}, nil
 }
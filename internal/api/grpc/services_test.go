@@ -397,6 +397,10 @@ func (f *fakeTaskRepo) Create(_ context.Context, task domain.MaintenanceTask) (d
 	return task, nil
 }
 
+func (f *fakeTaskRepo) CreateWithCompliance(ctx context.Context, task domain.MaintenanceTask, _ []domain.ComplianceItem) (domain.MaintenanceTask, error) {
+	return f.Create(ctx, task)
+}
+
 func (f *fakeTaskRepo) Update(_ context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
 	f.tasks[task.ID] = task
 	return task, nil
@@ -460,6 +464,14 @@ func (f *fakeAircraftRepo) Update(_ context.Context, aircraft domain.Aircraft) (
 	return aircraft, nil
 }
 
+func (f *fakeAircraftRepo) CreateWithPrograms(ctx context.Context, aircraft domain.Aircraft, _ []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	return f.Create(ctx, aircraft)
+}
+
+func (f *fakeAircraftRepo) UpdateWithPrograms(ctx context.Context, aircraft domain.Aircraft, _ []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	return f.Update(ctx, aircraft)
+}
+
 func (f *fakeAircraftRepo) SoftDelete(_ context.Context, _ uuid.UUID, _ uuid.UUID, _ time.Time) error {
 	return nil
 }
@@ -493,6 +505,10 @@ func (f *fakeReservationRepo) ListByTask(_ context.Context, _ uuid.UUID, _ uuid.
 	return nil, nil
 }
 
+func (f *fakeReservationRepo) ListByTasks(_ context.Context, _ uuid.UUID, _ []uuid.UUID) ([]domain.PartReservation, error) {
+	return nil, nil
+}
+
 func (f *fakeReservationRepo) UpdateState(_ context.Context, orgID, id uuid.UUID, state domain.PartReservationState, now time.Time) error {
 	item, ok := f.items[id]
 	if !ok || item.OrgID != orgID {
@@ -508,6 +524,10 @@ func (f *fakeReservationRepo) ReleaseByTask(_ context.Context, _ uuid.UUID, _ uu
 	return nil
 }
 
+func (f *fakeReservationRepo) ListUnavailable(_ context.Context, _ int) ([]domain.PartReservation, error) {
+	return nil, nil
+}
+
 type fakePartItemRepo struct {
 	items map[uuid.UUID]domain.PartItem
 }
@@ -602,7 +622,7 @@ func (f *fakeProgramRepo) List(_ context.Context, _ ports.MaintenanceProgramFilt
 	return nil, nil
 }
 
-func (f *fakeProgramRepo) ListDueCalendar(_ context.Context, _ time.Time, limit int) ([]domain.MaintenanceProgram, error) {
+func (f *fakeProgramRepo) ListDue(_ context.Context, _ time.Time, _ domain.ProgramLookAhead, limit int) ([]domain.MaintenanceProgram, error) {
 	if limit > 0 && len(f.due) > limit {
 		return f.due[:limit], nil
 	}
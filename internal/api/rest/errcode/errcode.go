@@ -11,6 +11,15 @@ const (
 	CodeRateLimited = "rate_limited"
 	CodeInternal    = "internal"
 	CodeUnavailable = "unavailable"
+
+	// Capacity conflicts refine CodeConflict for scheduling requests that
+	// do not fit existing bookings.
+	CodeAircraftOverlap     = "aircraft_overlap"
+	CodeBayCapacity         = "bay_capacity"
+	CodeMechanicOverlap     = "mechanic_overlap"
+	CodeMechanicUnavailable = "mechanic_unavailable"
+	CodeFlightOverlap       = "flight_overlap"
+	CodeLaborOverlap        = "labor_overlap"
 )
 
 func Normalize(code string) string {
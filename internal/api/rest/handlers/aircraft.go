@@ -15,6 +15,7 @@ type aircraftCreateRequest struct {
 	OrgID            string `json:"org_id" validate:"omitempty,uuid"`
 	TailNumber       string `json:"tail_number" validate:"required"`
 	Model            string `json:"model" validate:"required"`
+	AircraftTypeID   string `json:"aircraft_type_id" validate:"omitempty,uuid"`
 	LastMaintenance  string `json:"last_maintenance" validate:"omitempty,rfc3339"`
 	NextDue          string `json:"next_due" validate:"omitempty,rfc3339"`
 	Status           string `json:"status" validate:"omitempty,oneof=operational maintenance grounded"`
@@ -24,15 +25,14 @@ type aircraftCreateRequest struct {
 }
 
 type aircraftUpdateRequest struct {
-	OrgID            string  `json:"org_id" validate:"omitempty,uuid"`
-	TailNumber       *string `json:"tail_number" validate:"omitempty,min=1"`
-	Model            *string `json:"model" validate:"omitempty,min=1"`
-	LastMaintenance  *string `json:"last_maintenance" validate:"omitempty,rfc3339"`
-	NextDue          *string `json:"next_due" validate:"omitempty,rfc3339"`
-	Status           *string `json:"status" validate:"omitempty,oneof=operational maintenance grounded"`
-	CapacitySlots    *int    `json:"capacity_slots" validate:"omitempty,min=1"`
-	FlightHoursTotal *int    `json:"flight_hours_total" validate:"omitempty,min=0"`
-	CyclesTotal      *int    `json:"cycles_total" validate:"omitempty,min=0"`
+	OrgID           string  `json:"org_id" validate:"omitempty,uuid"`
+	TailNumber      *string `json:"tail_number" validate:"omitempty,min=1"`
+	Model           *string `json:"model" validate:"omitempty,min=1"`
+	AircraftTypeID  *string `json:"aircraft_type_id" validate:"omitempty,uuid"`
+	LastMaintenance *string `json:"last_maintenance" validate:"omitempty,rfc3339"`
+	NextDue         *string `json:"next_due" validate:"omitempty,rfc3339"`
+	Status          *string `json:"status" validate:"omitempty,oneof=operational maintenance grounded"`
+	CapacitySlots   *int    `json:"capacity_slots" validate:"omitempty,min=1"`
 }
 
 type aircraftResponse struct {
@@ -40,6 +40,7 @@ type aircraftResponse struct {
 	OrgID            uuid.UUID             `json:"org_id"`
 	TailNumber       string                `json:"tail_number"`
 	Model            string                `json:"model"`
+	AircraftTypeID   *uuid.UUID            `json:"aircraft_type_id,omitempty"`
 	LastMaintenance  *time.Time            `json:"last_maintenance,omitempty"`
 	NextDue          *time.Time            `json:"next_due,omitempty"`
 	Status           domain.AircraftStatus `json:"status"`
@@ -89,11 +90,21 @@ func CreateAircraft(w http.ResponseWriter, r *http.Request) {
 		}
 		nextDue = &value
 	}
+	var aircraftTypeID *uuid.UUID
+	if req.AircraftTypeID != "" {
+		parsed, err := uuid.Parse(req.AircraftTypeID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_type_id")
+			return
+		}
+		aircraftTypeID = &parsed
+	}
 	status := domain.AircraftStatus(req.Status)
 	input := services.AircraftCreateInput{
 		OrgID:            &orgID,
 		TailNumber:       req.TailNumber,
 		Model:            req.Model,
+		AircraftTypeID:   aircraftTypeID,
 		LastMaintenance:  lastMaintenance,
 		NextDue:          nextDue,
 		Status:           status,
@@ -138,6 +149,14 @@ func ListAircraft(w http.ResponseWriter, r *http.Request) {
 	}
 	filter.Model = query.Get("model")
 	filter.TailNumber = query.Get("tail_number")
+	if aircraftType := query.Get("aircraft_type_id"); aircraftType != "" {
+		aircraftTypeID, err := uuid.Parse(aircraftType)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_type_id")
+			return
+		}
+		filter.AircraftTypeID = &aircraftTypeID
+	}
 	if limit := query.Get("limit"); limit != "" {
 		value, err := parseInt(limit)
 		if err != nil {
@@ -250,20 +269,28 @@ func UpdateAircraft(w http.ResponseWriter, r *http.Request) {
 			nextDue = &value
 		}
 	}
+	var aircraftTypeID *uuid.UUID
+	if req.AircraftTypeID != nil && *req.AircraftTypeID != "" {
+		parsed, err := uuid.Parse(*req.AircraftTypeID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_type_id")
+			return
+		}
+		aircraftTypeID = &parsed
+	}
 	var status *domain.AircraftStatus
 	if req.Status != nil {
 		value := domain.AircraftStatus(*req.Status)
 		status = &value
 	}
 	input := services.AircraftUpdateInput{
-		TailNumber:       req.TailNumber,
-		Model:            req.Model,
-		LastMaintenance:  lastMaintenance,
-		NextDue:          nextDue,
-		Status:           status,
-		CapacitySlots:    req.CapacitySlots,
-		FlightHoursTotal: req.FlightHoursTotal,
-		CyclesTotal:      req.CyclesTotal,
+		TailNumber:      req.TailNumber,
+		Model:           req.Model,
+		AircraftTypeID:  aircraftTypeID,
+		LastMaintenance: lastMaintenance,
+		NextDue:         nextDue,
+		Status:          status,
+		CapacitySlots:   req.CapacitySlots,
 	}
 	updated, err := servicesReg.Aircraft.Update(r.Context(), actor, orgID, id, input)
 	if err != nil {
@@ -313,6 +340,7 @@ func mapAircraft(aircraft domain.Aircraft) aircraftResponse {
 		OrgID:            aircraft.OrgID,
 		TailNumber:       aircraft.TailNumber,
 		Model:            aircraft.Model,
+		AircraftTypeID:   aircraft.AircraftTypeID,
 		LastMaintenance:  aircraft.LastMaintenance,
 		NextDue:          aircraft.NextDue,
 		Status:           aircraft.Status,
@@ -204,6 +204,49 @@ func TestUpdateAircraft(t *testing.T) {
 	}
 }
 
+func TestUpdateAircraftRejectsTotals(t *testing.T) {
+	orgID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	registry := middleware.ServiceRegistry{Aircraft: &services.AircraftService{Aircraft: aircraftRepo}}
+
+	aircraft := domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N123AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    3,
+		FlightHoursTotal: 1500,
+		CyclesTotal:      900,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+	}
+	_, _ = aircraftRepo.Create(context.Background(), aircraft)
+	update := func(body map[string]any) *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/aircraft/"+aircraft.ID.String(), body)
+		req = withPrincipal(req, orgID, domain.RoleScheduler)
+		req = withRouteParam(req, "id", aircraft.ID.String())
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(http.HandlerFunc(UpdateAircraft)).ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := update(map[string]any{"flight_hours_total": 10}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 setting flight_hours_total, got %d", rr.Code)
+	}
+	rr := update(map[string]any{"model": "A320neo"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp aircraftResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.FlightHoursTotal != 1500 || resp.CyclesTotal != 900 {
+		t.Fatalf("expected totals to be kept, got %d/%d", resp.FlightHoursTotal, resp.CyclesTotal)
+	}
+}
+
 func TestDeleteAircraft(t *testing.T) {
 	orgID := uuid.New()
 	aircraftRepo := newFakeAircraftRepo()
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type attachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	OrgID       uuid.UUID `json:"org_id"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    uuid.UUID `json:"entity_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func UploadTaskAttachment(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, domain.AttachmentTask)
+}
+
+func ListTaskAttachments(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, domain.AttachmentTask)
+}
+
+func UploadComplianceAttachment(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, domain.AttachmentComplianceItem)
+}
+
+func ListComplianceAttachments(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, domain.AttachmentComplianceItem)
+}
+
+func UploadFindingAttachment(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, domain.AttachmentFinding)
+}
+
+func ListFindingAttachments(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, domain.AttachmentFinding)
+}
+
+func UploadCertificationAttachment(w http.ResponseWriter, r *http.Request) {
+	uploadAttachment(w, r, domain.AttachmentCertification)
+}
+
+func ListCertificationAttachments(w http.ResponseWriter, r *http.Request) {
+	listAttachments(w, r, domain.AttachmentCertification)
+}
+
+// uploadAttachment streams the "file" part of a multipart body to the
+// service, which enforces the size limit while reading it.
+func uploadAttachment(w http.ResponseWriter, r *http.Request, entityType domain.AttachmentEntityType) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Attachments == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	entityID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid "+string(entityType)+" id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid multipart form")
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "file is required")
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid multipart form")
+			return
+		}
+		if part.FormName() != "file" {
+			_ = part.Close()
+			continue
+		}
+		attachment, err := servicesReg.Attachments.Upload(r.Context(), actor, orgID, services.AttachmentUploadInput{
+			EntityType: entityType,
+			EntityID:   entityID,
+			FileName:   part.FileName(),
+			Content:    part,
+		})
+		_ = part.Close()
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, mapAttachment(attachment))
+		return
+	}
+}
+
+func listAttachments(w http.ResponseWriter, r *http.Request, entityType domain.AttachmentEntityType) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Attachments == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	entityID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid "+string(entityType)+" id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	attachments, err := servicesReg.Attachments.List(r.Context(), actor, orgID, entityType, entityID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]attachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		resp = append(resp, mapAttachment(attachment))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetAttachment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Attachments == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid attachment id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	attachment, err := servicesReg.Attachments.Get(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapAttachment(attachment))
+}
+
+// DownloadAttachment serves the content as a download under its sniffed
+// type, so browsers neither render it inline nor guess another type.
+func DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Attachments == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid attachment id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	attachment, content, err := servicesReg.Attachments.Open(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	defer content.Close()
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Attachments == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid attachment id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	if err := servicesReg.Attachments.Delete(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapAttachment(attachment domain.Attachment) attachmentResponse {
+	return attachmentResponse{
+		ID:          attachment.ID,
+		OrgID:       attachment.OrgID,
+		EntityType:  string(attachment.EntityType),
+		EntityID:    attachment.EntityID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		SHA256:      attachment.SHA256,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newUploadRequest(t *testing.T, path, fileName string, content []byte) *http.Request {
+	t.Helper()
+	return newMultipartRequest(t, http.MethodPost, path, map[string]string{"note": "left wing"}, "file", fileName, content)
+}
+
+func TestTaskAttachmentLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	orgID := uuid.New()
+	mechanic := uuid.New()
+	otherMechanic := uuid.New()
+	scheduler := uuid.New()
+
+	taskRepo := newFakeTaskRepo()
+	task, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: uuid.New(),
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateInProgress,
+		StartTime:  now.Add(-time.Hour),
+		EndTime:    now.Add(time.Hour),
+	})
+	blobs := newFakeBlobStore()
+	attachmentRepo := newFakeAttachmentRepo()
+	attachmentService := &services.AttachmentService{Attachments: attachmentRepo, Blobs: blobs, Tasks: taskRepo}
+	registry := middleware.ServiceRegistry{Attachments: attachmentService}
+	serve := func(req *http.Request, org, userID uuid.UUID, role domain.Role, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		req = withRouteParams(withUser(req, org, userID, role), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(handler).ServeHTTP(rr, req)
+		return rr
+	}
+	taskParams := map[string]string{"id": task.ID.String()}
+	uploadPath := "/api/v1/maintenance-tasks/" + task.ID.String() + "/attachments"
+	content := append(append([]byte{}, pngHeader...), "corrosion photo"...)
+
+	if rr := serve(newUploadRequest(t, uploadPath, "report.html", []byte("<html><script>alert(1)</script></html>")), orgID, mechanic, domain.RoleMechanic, UploadTaskAttachment, taskParams); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 uploading html, got %d", rr.Code)
+	}
+	if rr := serve(newUploadRequest(t, uploadPath, "photo.png", content), orgID, uuid.New(), domain.RoleAuditor, UploadTaskAttachment, taskParams); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for an auditor uploading, got %d", rr.Code)
+	}
+	rr := serve(newUploadRequest(t, uploadPath, `..\..\wing\photo.png`, content), orgID, mechanic, domain.RoleMechanic, UploadTaskAttachment, taskParams)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 uploading, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var uploaded attachmentResponse
+	if err := json.NewDecoder(rr.Body).Decode(&uploaded); err != nil {
+		t.Fatalf("decode attachment: %v", err)
+	}
+	digest := sha256.Sum256(content)
+	if uploaded.FileName != "photo.png" || uploaded.ContentType != "image/png" || uploaded.SizeBytes != int64(len(content)) || uploaded.SHA256 != hex.EncodeToString(digest[:]) {
+		t.Fatalf("unexpected attachment %+v", uploaded)
+	}
+
+	rr = serve(httptest.NewRequest(http.MethodGet, uploadPath, nil), orgID, uuid.New(), domain.RoleAuditor, ListTaskAttachments, taskParams)
+	var listed []attachmentResponse
+	if rr.Code != http.StatusOK || json.NewDecoder(rr.Body).Decode(&listed) != nil || len(listed) != 1 {
+		t.Fatalf("expected the auditor to list one attachment, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	attachmentParams := map[string]string{"id": uploaded.ID.String()}
+	contentPath := "/api/v1/attachments/" + uploaded.ID.String() + "/content"
+	rr = serve(httptest.NewRequest(http.MethodGet, contentPath, nil), orgID, otherMechanic, domain.RoleMechanic, DownloadAttachment, attachmentParams)
+	if rr.Code != http.StatusOK || !bytes.Equal(rr.Body.Bytes(), content) {
+		t.Fatalf("expected the content back, got %d", rr.Code)
+	}
+	if rr.Header().Get("X-Content-Type-Options") != "nosniff" || rr.Header().Get("Content-Disposition") != `attachment; filename=photo.png` {
+		t.Fatalf("unexpected download headers %v", rr.Header())
+	}
+	if rr := serve(httptest.NewRequest(http.MethodGet, contentPath, nil), uuid.New(), uuid.New(), domain.RoleMechanic, DownloadAttachment, attachmentParams); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 downloading from another organization, got %d", rr.Code)
+	}
+
+	deletePath := "/api/v1/attachments/" + uploaded.ID.String()
+	if rr := serve(httptest.NewRequest(http.MethodDelete, deletePath, nil), orgID, otherMechanic, domain.RoleMechanic, DeleteAttachment, attachmentParams); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for another mechanic deleting, got %d", rr.Code)
+	}
+	if rr := serve(httptest.NewRequest(http.MethodDelete, deletePath, nil), orgID, scheduler, domain.RoleScheduler, DeleteAttachment, attachmentParams); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204 for a scheduler deleting, got %d", rr.Code)
+	}
+	if rr := serve(httptest.NewRequest(http.MethodGet, contentPath, nil), orgID, mechanic, domain.RoleMechanic, DownloadAttachment, attachmentParams); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 after deleting, got %d", rr.Code)
+	}
+
+	// The content stays until retention purges it.
+	if len(blobs.blobs) != 1 {
+		t.Fatalf("expected the deleted content to be kept until retention, got %d blobs", len(blobs.blobs))
+	}
+	purged, err := attachmentService.PurgeExpired(ctx, orgID, time.Now().UTC().Add(time.Minute))
+	if err != nil || purged != 1 {
+		t.Fatalf("expected one attachment purged, got %d (%v)", purged, err)
+	}
+	if len(blobs.blobs) != 0 || len(attachmentRepo.attachments) != 0 {
+		t.Fatalf("expected the purge to remove the content and row")
+	}
+}
+
+func TestAttachmentSizeLimitFollowsOrgPolicy(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	mechanic := uuid.New()
+	complianceRepo := newFakeComplianceRepo()
+	item := domain.ComplianceItem{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		TaskID:      uuid.New(),
+		Description: "Borescope inspection",
+		Result:      domain.CompliancePending,
+	}
+	_ = complianceRepo.Create(ctx, item)
+	policies := &fakeOrgPolicyRepo{policies: map[uuid.UUID]domain.OrgPolicy{
+		orgID: {OrgID: orgID, MaxAttachmentBytes: 32},
+	}}
+	registry := middleware.ServiceRegistry{Attachments: &services.AttachmentService{
+		Attachments: newFakeAttachmentRepo(),
+		Blobs:       newFakeBlobStore(),
+		Compliance:  complianceRepo,
+		Policies:    &services.OrgPolicyService{Policies: policies},
+	}}
+	upload := func(size int) int {
+		content := bytes.Repeat([]byte("a"), size)
+		req := newUploadRequest(t, "/api/v1/compliance-items/"+item.ID.String()+"/attachments", "form.txt", content)
+		req = withRouteParams(withUser(req, orgID, mechanic, domain.RoleMechanic), map[string]string{"id": item.ID.String()})
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(http.HandlerFunc(UploadComplianceAttachment)).ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := upload(33); code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 over the policy limit, got %d", code)
+	}
+	if code := upload(32); code != http.StatusCreated {
+		t.Fatalf("expected status 201 at the policy limit, got %d", code)
+	}
+}
+
+func TestCertificationAttachmentsStayWithHolder(t *testing.T) {
+	orgID := uuid.New()
+	holder := uuid.New()
+	colleague := uuid.New()
+	cert := domain.EmployeeCertification{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		UserID:     holder,
+		CertTypeID: uuid.New(),
+		IssueDate:  time.Now().UTC().AddDate(-1, 0, 0),
+		Status:     domain.CertStatusActive,
+	}
+	registry := middleware.ServiceRegistry{Attachments: &services.AttachmentService{
+		Attachments: newFakeAttachmentRepo(),
+		Blobs:       newFakeBlobStore(),
+		Certs:       &fakeAuthorizationRepo{certs: map[uuid.UUID][]domain.EmployeeCertification{holder: {cert}}},
+	}}
+	params := map[string]string{"id": cert.ID.String()}
+	path := "/api/v1/certifications/" + cert.ID.String() + "/attachments"
+	serve := func(req *http.Request, userID uuid.UUID, role domain.Role, handler http.HandlerFunc) int {
+		req = withRouteParams(withUser(req, orgID, userID, role), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(handler).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	licence := append(append([]byte{}, "%PDF-1.7\n"...), "licence scan"...)
+
+	if code := serve(newUploadRequest(t, path, "licence.pdf", licence), colleague, domain.RoleMechanic, UploadCertificationAttachment); code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a colleague uploading, got %d", code)
+	}
+	if code := serve(newUploadRequest(t, path, "licence.pdf", licence), holder, domain.RoleMechanic, UploadCertificationAttachment); code != http.StatusCreated {
+		t.Fatalf("expected status 201 for the holder uploading, got %d", code)
+	}
+	if code := serve(httptest.NewRequest(http.MethodGet, path, nil), colleague, domain.RoleScheduler, ListCertificationAttachments); code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a scheduler listing, got %d", code)
+	}
+	if code := serve(httptest.NewRequest(http.MethodGet, path, nil), uuid.New(), domain.RoleTenantAdmin, ListCertificationAttachments); code != http.StatusOK {
+		t.Fatalf("expected status 200 for a tenant admin listing, got %d", code)
+	}
+}
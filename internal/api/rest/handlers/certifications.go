@@ -454,11 +454,50 @@ func GetQualifiedMechanics(w http.ResponseWriter, r *http.Request) {
 		aircraftTypeID = &parsed
 	}
 
+	// from and to narrow the answer to mechanics free for that whole window.
+	var window []time.Time
+	for _, key := range []string{"from", "to"} {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid "+key)
+			return
+		}
+		window = append(window, parsed)
+	}
+	if len(window) == 1 {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "from and to must be given together")
+		return
+	}
+	var stationID *uuid.UUID
+	if value := query.Get("station_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+			return
+		}
+		stationID = &parsed
+	}
+
 	ids, err := servicesReg.Certifications.GetQualifiedMechanics(r.Context(), actor, taskType, aircraftTypeID)
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
 	}
+	if len(window) == 2 {
+		if servicesReg.Roster == nil {
+			writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+			return
+		}
+		ids, err = servicesReg.Roster.AvailableMechanics(r.Context(), actor, actor.OrgID, ids, stationID, window[0], window[1])
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"mechanic_ids": ids})
 }
 
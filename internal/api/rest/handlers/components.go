@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type componentInstallRequest struct {
+	OrgID       string `json:"org_id" validate:"omitempty,uuid"`
+	PartItemID  string `json:"part_item_id" validate:"required,uuid"`
+	Position    string `json:"position" validate:"required,max=64"`
+	TaskID      string `json:"task_id" validate:"omitempty,uuid"`
+	Reason      string `json:"reason" validate:"required,oneof=scheduled unscheduled robbery"`
+	PerformedAt string `json:"performed_at" validate:"omitempty,rfc3339"`
+	Notes       string `json:"notes"`
+}
+
+type componentRemoveRequest struct {
+	OrgID       string `json:"org_id" validate:"omitempty,uuid"`
+	PartItemID  string `json:"part_item_id" validate:"required,uuid"`
+	TaskID      string `json:"task_id" validate:"omitempty,uuid"`
+	Reason      string `json:"reason" validate:"required,oneof=scheduled unscheduled robbery"`
+	PerformedAt string `json:"performed_at" validate:"omitempty,rfc3339"`
+	Notes       string `json:"notes"`
+}
+
+type componentChangeResponse struct {
+	ID                      uuid.UUID                    `json:"id"`
+	OrgID                   uuid.UUID                    `json:"org_id"`
+	PartItemID              uuid.UUID                    `json:"part_item_id"`
+	AircraftID              uuid.UUID                    `json:"aircraft_id"`
+	Action                  domain.ComponentChangeAction `json:"action"`
+	Position                string                       `json:"position"`
+	TaskID                  *uuid.UUID                   `json:"task_id,omitempty"`
+	Reason                  domain.ComponentChangeReason `json:"reason"`
+	AircraftHours           float64                      `json:"aircraft_hours"`
+	AircraftCycles          int                          `json:"aircraft_cycles"`
+	PartHoursSinceNew       float64                      `json:"part_hours_since_new"`
+	PartCyclesSinceNew      int                          `json:"part_cycles_since_new"`
+	PartHoursSinceOverhaul  float64                      `json:"part_hours_since_overhaul"`
+	PartCyclesSinceOverhaul int                          `json:"part_cycles_since_overhaul"`
+	PerformedBy             *uuid.UUID                   `json:"performed_by,omitempty"`
+	PerformedAt             time.Time                    `json:"performed_at"`
+	Notes                   string                       `json:"notes"`
+	CreatedAt               time.Time                    `json:"created_at"`
+}
+
+type componentHistoryResponse struct {
+	PartItem partItemResponse          `json:"part_item"`
+	Changes  []componentChangeResponse `json:"changes"`
+}
+
+func InstallComponent(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Components == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	var req componentInstallRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	partItemID, taskID, performedAt, msg := parseComponentChangeFields(req.PartItemID, req.TaskID, req.PerformedAt)
+	if msg != "" {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", msg)
+		return
+	}
+	change, err := servicesReg.Components.Install(r.Context(), actor, services.ComponentInstallInput{
+		OrgID:       &orgID,
+		AircraftID:  aircraftID,
+		PartItemID:  partItemID,
+		Position:    req.Position,
+		TaskID:      taskID,
+		Reason:      domain.ComponentChangeReason(req.Reason),
+		PerformedAt: performedAt,
+		Notes:       req.Notes,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapComponentChange(change))
+}
+
+func RemoveComponent(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Components == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	var req componentRemoveRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	partItemID, taskID, performedAt, msg := parseComponentChangeFields(req.PartItemID, req.TaskID, req.PerformedAt)
+	if msg != "" {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", msg)
+		return
+	}
+	change, err := servicesReg.Components.Remove(r.Context(), actor, services.ComponentRemoveInput{
+		OrgID:       &orgID,
+		AircraftID:  aircraftID,
+		PartItemID:  partItemID,
+		TaskID:      taskID,
+		Reason:      domain.ComponentChangeReason(req.Reason),
+		PerformedAt: performedAt,
+		Notes:       req.Notes,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapComponentChange(change))
+}
+
+func GetAircraftConfiguration(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Components == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	installed, err := servicesReg.Components.Configuration(r.Context(), actor, orgID, aircraftID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]partItemResponse, 0, len(installed))
+	for _, item := range installed {
+		resp = append(resp, mapPartItem(item))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetComponentHistory(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Components == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	serial := chi.URLParam(r, "serial")
+	if serial == "" {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "serial number is required")
+		return
+	}
+	query := r.URL.Query()
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	var limit, offset int
+	if value := query.Get("limit"); value != "" {
+		parsed, err := parseInt(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+	if value := query.Get("offset"); value != "" {
+		parsed, err := parseInt(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		offset = parsed
+	}
+	history, err := servicesReg.Components.History(r.Context(), actor, orgID, serial, limit, offset)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	changes := make([]componentChangeResponse, 0, len(history.Changes))
+	for _, change := range history.Changes {
+		changes = append(changes, mapComponentChange(change))
+	}
+	writeJSON(w, http.StatusOK, componentHistoryResponse{
+		PartItem: mapPartItem(history.Part),
+		Changes:  changes,
+	})
+}
+
+// parseComponentChangeFields parses the identifiers and timestamp shared by
+// install and remove requests. It returns a validation message on failure.
+func parseComponentChangeFields(partItem, task, performed string) (uuid.UUID, *uuid.UUID, *time.Time, string) {
+	partItemID, err := uuid.Parse(partItem)
+	if err != nil {
+		return uuid.Nil, nil, nil, "invalid part_item_id"
+	}
+	var taskID *uuid.UUID
+	if task != "" {
+		parsed, err := uuid.Parse(task)
+		if err != nil {
+			return uuid.Nil, nil, nil, "invalid task_id"
+		}
+		taskID = &parsed
+	}
+	var performedAt *time.Time
+	if performed != "" {
+		parsed, err := time.Parse(time.RFC3339, performed)
+		if err != nil {
+			return uuid.Nil, nil, nil, "invalid performed_at"
+		}
+		performedAt = &parsed
+	}
+	return partItemID, taskID, performedAt, ""
+}
+
+func mapComponentChange(change domain.ComponentChange) componentChangeResponse {
+	return componentChangeResponse{
+		ID:                      change.ID,
+		OrgID:                   change.OrgID,
+		PartItemID:              change.PartItemID,
+		AircraftID:              change.AircraftID,
+		Action:                  change.Action,
+		Position:                change.Position,
+		TaskID:                  change.TaskID,
+		Reason:                  change.Reason,
+		AircraftHours:           change.AircraftHours,
+		AircraftCycles:          change.AircraftCycles,
+		PartHoursSinceNew:       change.PartHoursSinceNew,
+		PartCyclesSinceNew:      change.PartCyclesSinceNew,
+		PartHoursSinceOverhaul:  change.PartHoursSinceOverhaul,
+		PartCyclesSinceOverhaul: change.PartCyclesSinceOverhaul,
+		PerformedBy:             change.PerformedBy,
+		PerformedAt:             change.PerformedAt,
+		Notes:                   change.Notes,
+		CreatedAt:               change.CreatedAt,
+	}
+}
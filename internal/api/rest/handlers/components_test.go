@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type componentFixture struct {
+	orgID    uuid.UUID
+	aircraft domain.Aircraft
+	engine   domain.PartItem
+	spare    domain.PartItem
+	parts    *fakePartItemRepo
+	// utilization logs the aircraft's usage for backdated changes.
+	utilization *fakeUtilizationRepo
+	registry    middleware.ServiceRegistry
+}
+
+func newComponentFixture(t *testing.T) componentFixture {
+	t.Helper()
+	orgID := uuid.New()
+	now := time.Now().UTC()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft := domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N321AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		FlightHoursTotal: 15000,
+		CyclesTotal:      9000,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	_, _ = aircraftRepo.Create(context.Background(), aircraft)
+
+	parts := newFakePartItemRepo()
+	cyclesLimit := 20000
+	engine := domain.PartItem{
+		ID:                  uuid.New(),
+		OrgID:               orgID,
+		DefinitionID:        uuid.New(),
+		SerialNumber:        "ESN-100",
+		Status:              domain.PartItemInStock,
+		LifeLimitCycles:     &cyclesLimit,
+		HoursSinceNew:       4200.5,
+		CyclesSinceNew:      3100,
+		HoursSinceOverhaul:  800,
+		CyclesSinceOverhaul: 600,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	spare := domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		DefinitionID: engine.DefinitionID,
+		SerialNumber: "ESN-200",
+		Status:       domain.PartItemInStock,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	_, _ = parts.Create(context.Background(), engine)
+	_, _ = parts.Create(context.Background(), spare)
+
+	utilization := newFakeUtilizationRepo(aircraftRepo)
+	componentService := &services.ComponentService{
+		Changes:     newFakeComponentChangeRepo(parts),
+		PartItems:   parts,
+		Aircraft:    aircraftRepo,
+		Utilization: utilization,
+		Tasks:       newFakeTaskRepo(),
+	}
+	return componentFixture{
+		orgID:       orgID,
+		aircraft:    aircraft,
+		engine:      engine,
+		spare:       spare,
+		parts:       parts,
+		utilization: utilization,
+		registry:    middleware.ServiceRegistry{Components: componentService},
+	}
+}
+
+func (f componentFixture) serve(t *testing.T, handler http.HandlerFunc, method, path string, body any, role domain.Role, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := newJSONRequest(t, method, path, body)
+	req = withPrincipal(req, f.orgID, role)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestInstallAndRemoveComponent(t *testing.T) {
+	f := newComponentFixture(t)
+	aircraftPath := "/api/v1/aircraft/" + f.aircraft.ID.String()
+	params := map[string]string{"id": f.aircraft.ID.String()}
+
+	rr := f.serve(t, InstallComponent, http.MethodPost, aircraftPath+"/components/install", map[string]any{
+		"part_item_id": f.engine.ID.String(),
+		"position":     "ENG1",
+		"reason":       "scheduled",
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var installed componentChangeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&installed); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if installed.Action != domain.ComponentInstall || installed.Position != "ENG1" {
+		t.Fatalf("unexpected install record %+v", installed)
+	}
+	if installed.AircraftHours != 15000 || installed.AircraftCycles != 9000 {
+		t.Fatalf("expected aircraft usage 15000/9000, got %v/%d", installed.AircraftHours, installed.AircraftCycles)
+	}
+	if installed.PartHoursSinceNew != 4200.5 || installed.PartCyclesSinceNew != 3100 || installed.PartCyclesSinceOverhaul != 600 {
+		t.Fatalf("expected part usage snapshot, got %+v", installed)
+	}
+
+	// The position is taken and the engine is already fitted.
+	rr = f.serve(t, InstallComponent, http.MethodPost, aircraftPath+"/components/install", map[string]any{
+		"part_item_id": f.spare.ID.String(),
+		"position":     "ENG1",
+		"reason":       "scheduled",
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for occupied position, got %d", rr.Code)
+	}
+	rr = f.serve(t, InstallComponent, http.MethodPost, aircraftPath+"/components/install", map[string]any{
+		"part_item_id": f.engine.ID.String(),
+		"position":     "ENG2",
+		"reason":       "scheduled",
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for installed part, got %d", rr.Code)
+	}
+
+	rr = f.serve(t, GetAircraftConfiguration, http.MethodGet, aircraftPath+"/configuration", nil, domain.RoleAuditor, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var configuration []partItemResponse
+	if err := json.NewDecoder(rr.Body).Decode(&configuration); err != nil {
+		t.Fatalf("decode configuration: %v", err)
+	}
+	if len(configuration) != 1 || configuration[0].SerialNumber != "ESN-100" || configuration[0].Position != "ENG1" {
+		t.Fatalf("expected ESN-100 at ENG1, got %+v", configuration)
+	}
+
+	rr = f.serve(t, RemoveComponent, http.MethodPost, aircraftPath+"/components/remove", map[string]any{
+		"part_item_id": f.engine.ID.String(),
+		"reason":       "robbery",
+		"notes":        "Fitted to N322AM",
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	removedPart, _ := f.parts.GetByID(context.Background(), f.orgID, f.engine.ID)
+	if removedPart.AircraftID != nil || removedPart.Position != "" || removedPart.Status != domain.PartItemInStock {
+		t.Fatalf("expected removed part to be back in stock, got %+v", removedPart)
+	}
+
+	rr = f.serve(t, GetComponentHistory, http.MethodGet, "/api/v1/part-items/serial/ESN-100/history", nil, domain.RoleAuditor, map[string]string{"serial": "ESN-100"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var history componentHistoryResponse
+	if err := json.NewDecoder(rr.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if history.PartItem.ID != f.engine.ID || len(history.Changes) != 2 {
+		t.Fatalf("expected two changes for ESN-100, got %+v", history)
+	}
+	removal := history.Changes[1]
+	if removal.Action != domain.ComponentRemove || removal.Reason != domain.ComponentChangeRobbery || removal.Position != "ENG1" {
+		t.Fatalf("unexpected removal record %+v", removal)
+	}
+}
+
+func TestInstallComponentBackdatedUsesUtilizationAtTheTime(t *testing.T) {
+	f := newComponentFixture(t)
+	aircraftPath := "/api/v1/aircraft/" + f.aircraft.ID.String()
+	params := map[string]string{"id": f.aircraft.ID.String()}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for i, day := range []int{3, 1} {
+		_, _ = f.utilization.Create(context.Background(), domain.AircraftUtilization{
+			ID:              uuid.New(),
+			OrgID:           f.orgID,
+			AircraftID:      f.aircraft.ID,
+			EntryType:       domain.UtilizationEntryDaily,
+			UtilizationDate: today.AddDate(0, 0, -day),
+			BlockHours:      10,
+			Cycles:          5,
+			HoursTotal:      15000 + float64(i+1)*10,
+			CyclesTotal:     9000 + (i+1)*5,
+		})
+	}
+
+	rr := f.serve(t, InstallComponent, http.MethodPost, aircraftPath+"/components/install", map[string]any{
+		"part_item_id": f.engine.ID.String(),
+		"position":     "ENG1",
+		"reason":       "scheduled",
+		"performed_at": today.AddDate(0, 0, -2).Add(12 * time.Hour).Format(time.RFC3339),
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var installed componentChangeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&installed); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if installed.AircraftHours != 15010 || installed.AircraftCycles != 9005 {
+		t.Fatalf("expected aircraft usage 15010/9005 at the time, got %v/%d", installed.AircraftHours, installed.AircraftCycles)
+	}
+
+	// Before the first logged entry the aircraft's totals are unknown.
+	rr = f.serve(t, InstallComponent, http.MethodPost, aircraftPath+"/components/install", map[string]any{
+		"part_item_id": f.spare.ID.String(),
+		"position":     "ENG2",
+		"reason":       "scheduled",
+		"performed_at": today.AddDate(0, 0, -5).Format(time.RFC3339),
+	}, domain.RoleMechanic, params)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a change before the utilization history, got %d", rr.Code)
+	}
+}
+
+func TestInstallComponentRequiresMechanic(t *testing.T) {
+	f := newComponentFixture(t)
+	rr := f.serve(t, InstallComponent, http.MethodPost, "/api/v1/aircraft/"+f.aircraft.ID.String()+"/components/install", map[string]any{
+		"part_item_id": f.engine.ID.String(),
+		"position":     "ENG1",
+		"reason":       "scheduled",
+	}, domain.RoleScheduler, map[string]string{"id": f.aircraft.ID.String()})
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+}
+
+func TestRemoveComponentNotInstalled(t *testing.T) {
+	f := newComponentFixture(t)
+	rr := f.serve(t, RemoveComponent, http.MethodPost, "/api/v1/aircraft/"+f.aircraft.ID.String()+"/components/remove", map[string]any{
+		"part_item_id": f.spare.ID.String(),
+		"reason":       "unscheduled",
+	}, domain.RoleMechanic, map[string]string{"id": f.aircraft.ID.String()})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rr.Code)
+	}
+}
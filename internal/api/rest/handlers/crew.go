@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type crewAssignRequest struct {
+	OrgID        string  `json:"org_id" validate:"omitempty,uuid"`
+	MechanicID   string  `json:"mechanic_id" validate:"required,uuid"`
+	Role         string  `json:"role" validate:"required,oneof=lead technician certifying_staff inspector"`
+	PlannedHours float64 `json:"planned_hours" validate:"gte=0,lte=1000"`
+}
+
+type crewUpdateRequest struct {
+	PlannedHours *float64 `json:"planned_hours" validate:"required,gte=0,lte=1000"`
+}
+
+type taskAssignmentResponse struct {
+	ID           uuid.UUID `json:"id"`
+	OrgID        uuid.UUID `json:"org_id"`
+	TaskID       uuid.UUID `json:"task_id"`
+	MechanicID   uuid.UUID `json:"mechanic_id"`
+	Role         string    `json:"role"`
+	PlannedHours float64   `json:"planned_hours"`
+	AssignedBy   uuid.UUID `json:"assigned_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func AssignCrew(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Crew == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	var req crewAssignRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	mechanicID, err := uuid.Parse(req.MechanicID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+		return
+	}
+	assignment, err := servicesReg.Crew.Assign(r.Context(), actor, orgID, taskID, services.CrewAssignInput{
+		MechanicID:   mechanicID,
+		Role:         domain.CrewRole(req.Role),
+		PlannedHours: req.PlannedHours,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapTaskAssignment(assignment))
+}
+
+func ListTaskCrew(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Crew == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	crew, err := servicesReg.Crew.List(r.Context(), actor, orgID, taskID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]taskAssignmentResponse, 0, len(crew))
+	for _, assignment := range crew {
+		resp = append(resp, mapTaskAssignment(assignment))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func UpdateCrewAssignment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Crew == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid assignment id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req crewUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	assignment, err := servicesReg.Crew.UpdatePlannedHours(r.Context(), actor, orgID, id, *req.PlannedHours)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapTaskAssignment(assignment))
+}
+
+func RemoveCrewAssignment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Crew == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid assignment id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	if err := servicesReg.Crew.Remove(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapTaskAssignment(assignment domain.TaskAssignment) taskAssignmentResponse {
+	return taskAssignmentResponse{
+		ID:           assignment.ID,
+		OrgID:        assignment.OrgID,
+		TaskID:       assignment.TaskID,
+		MechanicID:   assignment.MechanicID,
+		Role:         string(assignment.Role),
+		PlannedHours: assignment.PlannedHours,
+		AssignedBy:   assignment.AssignedBy,
+		CreatedAt:    assignment.CreatedAt,
+		UpdatedAt:    assignment.UpdatedAt,
+	}
+}
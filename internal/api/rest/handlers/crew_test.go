@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestCrewAssignmentRolesGateTaskTransitions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	orgID := uuid.New()
+	scheduler := uuid.New()
+	lead := uuid.New()
+	technician := uuid.New()
+	certifier := uuid.New()
+	outsider := uuid.New()
+
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N300AM",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+	})
+	taskRepo := newFakeTaskRepo()
+	task, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeOverhaul,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(-2 * time.Hour),
+		EndTime:    now.Add(-time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	licence := uuid.New()
+	release := uuid.New()
+	holds := func(userID uuid.UUID, certTypes ...uuid.UUID) []domain.EmployeeCertification {
+		var out []domain.EmployeeCertification
+		for _, certType := range certTypes {
+			out = append(out, domain.EmployeeCertification{
+				ID:         uuid.New(),
+				OrgID:      orgID,
+				UserID:     userID,
+				CertTypeID: certType,
+				IssueDate:  now.AddDate(-1, 0, 0),
+				Status:     domain.CertStatusActive,
+			})
+		}
+		return out
+	}
+	certs := &fakeAuthorizationRepo{
+		requirements: []domain.TaskSkillRequirement{
+			{ID: uuid.New(), OrgID: orgID, TaskType: domain.TaskTypeOverhaul, CertTypeID: &licence},
+			{ID: uuid.New(), OrgID: orgID, TaskType: domain.TaskTypeOverhaul, CertTypeID: &release, IsCertifyingRole: true},
+		},
+		certs: map[uuid.UUID][]domain.EmployeeCertification{
+			lead:       holds(lead, licence),
+			technician: holds(technician, licence),
+			certifier:  holds(certifier, licence, release),
+		},
+	}
+	crewRepo := newFakeTaskAssignmentRepo()
+	crewService := &services.CrewService{
+		Assignments:    crewRepo,
+		Tasks:          taskRepo,
+		Aircraft:       aircraftRepo,
+		Certifications: &services.CertificationService{Certs: certs},
+	}
+	registry := middleware.ServiceRegistry{
+		Tasks: &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Crew: crewService},
+		Labor: &services.LaborService{Labor: newFakeLaborRepo(), Tasks: taskRepo, Aircraft: aircraftRepo, Crew: crewRepo},
+		Crew:  crewService,
+	}
+	serve := func(req *http.Request, userID uuid.UUID, role domain.Role, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		req = withRouteParams(withUser(req, orgID, userID, role), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(handler).ServeHTTP(rr, req)
+		return rr
+	}
+	taskParams := map[string]string{"id": task.ID.String()}
+	crewPath := "/api/v1/maintenance-tasks/" + task.ID.String() + "/crew"
+	assign := func(userID uuid.UUID, role domain.Role, mechanicID uuid.UUID, crewRole string, hours float64) *httptest.ResponseRecorder {
+		body := map[string]any{"mechanic_id": mechanicID.String(), "role": crewRole, "planned_hours": hours}
+		return serve(newJSONRequest(t, http.MethodPost, crewPath, body), userID, role, AssignCrew, taskParams)
+	}
+	transition := func(userID uuid.UUID, state string) *httptest.ResponseRecorder {
+		body := map[string]any{"new_state": state, "notes": "Overhaul complete"}
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-tasks/"+task.ID.String()+"/state", body)
+		return serve(req, userID, domain.RoleMechanic, TransitionTaskState, taskParams)
+	}
+	plannedHours := func() float64 {
+		t.Helper()
+		rr := serve(httptest.NewRequest(http.MethodGet, "/api/v1/maintenance-tasks/"+task.ID.String()+"/labor/summary", nil), scheduler, domain.RoleScheduler, GetTaskLaborSummary, taskParams)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for the labor summary, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var summary taskLaborSummaryResponse
+		if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+			t.Fatalf("decode summary: %v", err)
+		}
+		return summary.PlannedHours
+	}
+
+	if rr := assign(lead, domain.RoleMechanic, lead, "lead", 4); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic assigning crew, got %d", rr.Code)
+	}
+	if rr := assign(scheduler, domain.RoleScheduler, technician, "certifying_staff", 2); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 assigning an unauthorized certifying staff, got %d", rr.Code)
+	}
+	if rr := assign(scheduler, domain.RoleScheduler, outsider, "technician", 2); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 assigning an unlicensed technician, got %d", rr.Code)
+	}
+	if rr := transition(technician, "in_progress"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 starting without a crew, got %d", rr.Code)
+	}
+
+	var leadAssignment taskAssignmentResponse
+	for _, member := range []struct {
+		id    uuid.UUID
+		role  string
+		hours float64
+	}{{lead, "lead", 4}, {technician, "technician", 6}, {certifier, "certifying_staff", 2}} {
+		rr := assign(scheduler, domain.RoleScheduler, member.id, member.role, member.hours)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201 assigning %s, got %d: %s", member.role, rr.Code, rr.Body.String())
+		}
+		if member.id == lead {
+			if err := json.NewDecoder(rr.Body).Decode(&leadAssignment); err != nil {
+				t.Fatalf("decode assignment: %v", err)
+			}
+		}
+	}
+	if rr := assign(scheduler, domain.RoleScheduler, technician, "technician", 1); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 assigning the same role twice, got %d", rr.Code)
+	}
+	if got := plannedHours(); got != 12 {
+		t.Fatalf("expected 12 planned hours from the crew, got %v", got)
+	}
+	assignmentParams := map[string]string{"id": leadAssignment.ID.String()}
+	assignmentPath := "/api/v1/crew-assignments/" + leadAssignment.ID.String()
+	rr := serve(newJSONRequest(t, http.MethodPatch, assignmentPath, map[string]any{"planned_hours": 3}), scheduler, domain.RoleScheduler, UpdateCrewAssignment, assignmentParams)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 updating planned hours, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := plannedHours(); got != 11 {
+		t.Fatalf("expected 11 planned hours after the update, got %v", got)
+	}
+
+	if rr := transition(outsider, "in_progress"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic outside the crew starting, got %d", rr.Code)
+	}
+	if rr := transition(technician, "in_progress"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for a crew member starting, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := transition(technician, "completed"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a technician completing, got %d", rr.Code)
+	}
+	if rr := transition(certifier, "completed"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for the certifying staff completing, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := serve(httptest.NewRequest(http.MethodDelete, assignmentPath, nil), scheduler, domain.RoleScheduler, RemoveCrewAssignment, assignmentParams); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 changing the crew of a completed task, got %d", rr.Code)
+	}
+}
+
+func TestCompletingCrewTaskNeedsCertifyingStaff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	orgID := uuid.New()
+	technician := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N301AM",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+	})
+	taskRepo := newFakeTaskRepo()
+	task, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateInProgress,
+		StartTime:  now.Add(-2 * time.Hour),
+		EndTime:    now.Add(-time.Hour),
+	})
+	crewRepo := newFakeTaskAssignmentRepo()
+	_, _ = crewRepo.Create(ctx, domain.TaskAssignment{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		TaskID:     task.ID,
+		MechanicID: technician,
+		Role:       domain.CrewRoleTechnician,
+		CreatedAt:  now,
+	})
+	crewService := &services.CrewService{Assignments: crewRepo, Tasks: taskRepo, Aircraft: aircraftRepo}
+	registry := middleware.ServiceRegistry{
+		Tasks: &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Crew: crewService},
+	}
+	body := map[string]any{"new_state": "completed", "notes": "Repair complete"}
+	req := newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-tasks/"+task.ID.String()+"/state", body)
+	req = withRouteParams(withUser(req, orgID, uuid.New(), domain.RoleScheduler), map[string]string{"id": task.ID.String()})
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(TransitionTaskState)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 completing without certifying staff, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type defectReportRequest struct {
+	OrgID       string `json:"org_id" validate:"omitempty,uuid"`
+	AircraftID  string `json:"aircraft_id" validate:"required,uuid"`
+	Title       string `json:"title" validate:"required,max=200"`
+	Description string `json:"description" validate:"max=4000"`
+	ATAChapter  string `json:"ata_chapter" validate:"max=20"`
+}
+
+type defectDeferRequest struct {
+	MELCategory             string `json:"mel_category" validate:"required,oneof=A B C D"`
+	MELItem                 string `json:"mel_item" validate:"required,max=100"`
+	DeferralReference       string `json:"deferral_reference" validate:"required,max=100"`
+	OperationalRestrictions string `json:"operational_restrictions" validate:"max=2000"`
+	ExpiresAt               string `json:"expires_at" validate:"omitempty,rfc3339"`
+	RectificationMinutes    int    `json:"rectification_minutes" validate:"min=0"`
+}
+
+type rectificationTaskRequest struct {
+	StartTime          string `json:"start_time" validate:"required,rfc3339"`
+	EndTime            string `json:"end_time" validate:"required,rfc3339"`
+	BayID              string `json:"bay_id" validate:"omitempty,uuid"`
+	AssignedMechanicID string `json:"assigned_mechanic_id" validate:"omitempty,uuid"`
+}
+
+type deferralExtensionRequest struct {
+	NewExpiresAt      string `json:"new_expires_at" validate:"required,rfc3339"`
+	Reason            string `json:"reason" validate:"required,max=2000"`
+	ApprovalReference string `json:"approval_reference" validate:"required,max=100"`
+}
+
+type defectNotesRequest struct {
+	Notes string `json:"notes" validate:"max=2000"`
+}
+
+type defectResponse struct {
+	ID                      uuid.UUID  `json:"id"`
+	OrgID                   uuid.UUID  `json:"org_id"`
+	AircraftID              uuid.UUID  `json:"aircraft_id"`
+	Title                   string     `json:"title"`
+	Description             string     `json:"description,omitempty"`
+	ATAChapter              string     `json:"ata_chapter,omitempty"`
+	State                   string     `json:"state"`
+	ReportedBy              uuid.UUID  `json:"reported_by"`
+	ReportedAt              time.Time  `json:"reported_at"`
+	MELCategory             *string    `json:"mel_category,omitempty"`
+	MELItem                 string     `json:"mel_item,omitempty"`
+	DeferralReference       string     `json:"deferral_reference,omitempty"`
+	OperationalRestrictions string     `json:"operational_restrictions,omitempty"`
+	DeferredBy              *uuid.UUID `json:"deferred_by,omitempty"`
+	DeferredAt              *time.Time `json:"deferred_at,omitempty"`
+	ExpiresAt               *time.Time `json:"expires_at,omitempty"`
+	Expired                 bool       `json:"expired"`
+	RectificationTaskID     *uuid.UUID `json:"rectification_task_id,omitempty"`
+	RectifiedBy             *uuid.UUID `json:"rectified_by,omitempty"`
+	RectifiedAt             *time.Time `json:"rectified_at,omitempty"`
+	ClosedBy                *uuid.UUID `json:"closed_by,omitempty"`
+	ClosedAt                *time.Time `json:"closed_at,omitempty"`
+	Notes                   string     `json:"notes,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+type deferralExtensionResponse struct {
+	ID                uuid.UUID `json:"id"`
+	DefectID          uuid.UUID `json:"defect_id"`
+	PreviousExpiresAt time.Time `json:"previous_expires_at"`
+	NewExpiresAt      time.Time `json:"new_expires_at"`
+	Reason            string    `json:"reason"`
+	ApprovalReference string    `json:"approval_reference"`
+	ApprovedBy        uuid.UUID `json:"approved_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func ReportDefect(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req defectReportRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	aircraftID, err := uuid.Parse(req.AircraftID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+		return
+	}
+	defect, err := servicesReg.Defects.Report(r.Context(), actor, services.DefectReportInput{
+		OrgID:       &orgID,
+		AircraftID:  aircraftID,
+		Title:       req.Title,
+		Description: req.Description,
+		ATAChapter:  req.ATAChapter,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapDefect(defect, time.Now()))
+}
+
+func ListDefects(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.DefectFilter{}
+	if actor.IsAdmin() && query.Get("org_id") != "" {
+		orgID, err := uuid.Parse(query.Get("org_id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+			return
+		}
+		filter.OrgID = &orgID
+	}
+	if aircraftID := query.Get("aircraft_id"); aircraftID != "" {
+		parsed, err := uuid.Parse(aircraftID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		filter.AircraftID = &parsed
+	}
+	if state := query.Get("state"); state != "" {
+		v := domain.DefectState(state)
+		filter.State = &v
+	}
+	if before := query.Get("expiring_before"); before != "" {
+		parsed, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid expiring_before")
+			return
+		}
+		filter.ExpiringBefore = &parsed
+	}
+	if limit := query.Get("limit"); limit != "" {
+		v, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = v
+	}
+	if offset := query.Get("offset"); offset != "" {
+		v, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = v
+	}
+
+	defects, err := servicesReg.Defects.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	now := time.Now()
+	resp := make([]defectResponse, 0, len(defects))
+	for _, defect := range defects {
+		resp = append(resp, mapDefect(defect, now))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetDefect(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	defect, err := servicesReg.Defects.Get(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapDefect(defect, time.Now()))
+}
+
+func DeferDefect(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req defectDeferRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid expires_at")
+			return
+		}
+		expiresAt = &parsed
+	}
+	defect, err := servicesReg.Defects.Defer(r.Context(), actor, orgID, id, services.DefectDeferInput{
+		Category:                domain.MELCategory(req.MELCategory),
+		MELItem:                 req.MELItem,
+		Reference:               req.DeferralReference,
+		OperationalRestrictions: req.OperationalRestrictions,
+		ExpiresAt:               expiresAt,
+		RectificationDuration:   time.Duration(req.RectificationMinutes) * time.Minute,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapDefect(defect, time.Now()))
+}
+
+func CreateRectificationTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req rectificationTaskRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid end_time")
+		return
+	}
+	input := services.RectificationTaskInput{StartTime: startTime, EndTime: endTime}
+	if req.BayID != "" {
+		parsed, err := uuid.Parse(req.BayID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay_id")
+			return
+		}
+		input.BayID = &parsed
+	}
+	if req.AssignedMechanicID != "" {
+		parsed, err := uuid.Parse(req.AssignedMechanicID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid assigned_mechanic_id")
+			return
+		}
+		input.AssignedMechanicID = &parsed
+	}
+	defect, err := servicesReg.Defects.CreateRectificationTask(r.Context(), actor, orgID, id, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapDefect(defect, time.Now()))
+}
+
+func ExtendDeferral(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req deferralExtensionRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	newExpiresAt, err := time.Parse(time.RFC3339, req.NewExpiresAt)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid new_expires_at")
+		return
+	}
+	defect, err := servicesReg.Defects.Extend(r.Context(), actor, orgID, id, services.DeferralExtensionInput{
+		NewExpiresAt:      newExpiresAt,
+		Reason:            req.Reason,
+		ApprovalReference: req.ApprovalReference,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapDefect(defect, time.Now()))
+}
+
+func ListDeferralExtensions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	extensions, err := servicesReg.Defects.ListExtensions(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]deferralExtensionResponse, 0, len(extensions))
+	for _, ext := range extensions {
+		resp = append(resp, deferralExtensionResponse{
+			ID:                ext.ID,
+			DefectID:          ext.DefectID,
+			PreviousExpiresAt: ext.PreviousExpiresAt,
+			NewExpiresAt:      ext.NewExpiresAt,
+			Reason:            ext.Reason,
+			ApprovalReference: ext.ApprovalReference,
+			ApprovedBy:        ext.ApprovedBy,
+			CreatedAt:         ext.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func RectifyDefect(w http.ResponseWriter, r *http.Request) {
+	finishDefect(w, r, (*services.DefectService).Rectify)
+}
+
+func CloseDefect(w http.ResponseWriter, r *http.Request) {
+	finishDefect(w, r, (*services.DefectService).Close)
+}
+
+// finishDefect serves the rectify and close endpoints, which both take
+// optional notes.
+func finishDefect(w http.ResponseWriter, r *http.Request, finish func(*services.DefectService, context.Context, app.Actor, uuid.UUID, uuid.UUID, string) (domain.Defect, error)) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Defects == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid defect id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req defectNotesRequest
+	if r.ContentLength != 0 {
+		if err := decodeAndValidateJSON(r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+			return
+		}
+	}
+	defect, err := finish(servicesReg.Defects, r.Context(), actor, orgID, id, req.Notes)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapDefect(defect, time.Now()))
+}
+
+func mapDefect(defect domain.Defect, now time.Time) defectResponse {
+	resp := defectResponse{
+		ID:                      defect.ID,
+		OrgID:                   defect.OrgID,
+		AircraftID:              defect.AircraftID,
+		Title:                   defect.Title,
+		Description:             defect.Description,
+		ATAChapter:              defect.ATAChapter,
+		State:                   string(defect.State),
+		ReportedBy:              defect.ReportedBy,
+		ReportedAt:              defect.ReportedAt,
+		MELItem:                 defect.MELItem,
+		DeferralReference:       defect.DeferralReference,
+		OperationalRestrictions: defect.OperationalRestrictions,
+		DeferredBy:              defect.DeferredBy,
+		DeferredAt:              defect.DeferredAt,
+		ExpiresAt:               defect.ExpiresAt,
+		Expired:                 defect.Expired(now),
+		RectificationTaskID:     defect.RectificationTaskID,
+		RectifiedBy:             defect.RectifiedBy,
+		RectifiedAt:             defect.RectifiedAt,
+		ClosedBy:                defect.ClosedBy,
+		ClosedAt:                defect.ClosedAt,
+		Notes:                   defect.Notes,
+		CreatedAt:               defect.CreatedAt,
+		UpdatedAt:               defect.UpdatedAt,
+	}
+	if defect.MELCategory != nil {
+		category := string(*defect.MELCategory)
+		resp.MELCategory = &category
+	}
+	return resp
+}
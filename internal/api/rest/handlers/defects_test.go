@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestDefectDeferralLifecycle(t *testing.T) {
+	ctx := context.Background()
+	clock := &manualClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+	orgID := uuid.New()
+	mechanic := uuid.New()
+	scheduler := uuid.New()
+	quality := uuid.New()
+
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N400AM",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+	})
+	taskRepo := newFakeTaskRepo()
+	defectRepo := newFakeDefectRepo()
+	taskService := &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Clock: clock}
+	defectService := &services.DefectService{
+		Defects:  defectRepo,
+		Aircraft: aircraftRepo,
+		Tasks:    taskRepo,
+		TaskSvc:  taskService,
+		Clock:    clock,
+	}
+	registry := middleware.ServiceRegistry{
+		Tasks:    taskService,
+		Aircraft: &services.AircraftService{Aircraft: aircraftRepo, Defects: defectService, Clock: clock},
+		Defects:  defectService,
+	}
+	serve := func(req *http.Request, userID uuid.UUID, role domain.Role, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		req = withRouteParams(withUser(req, orgID, userID, role), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(handler).ServeHTTP(rr, req)
+		return rr
+	}
+	decodeDefect := func(rr *httptest.ResponseRecorder) defectResponse {
+		t.Helper()
+		var resp defectResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode defect: %v", err)
+		}
+		return resp
+	}
+
+	rr := serve(newJSONRequest(t, http.MethodPost, "/api/v1/defects", map[string]any{
+		"aircraft_id": aircraft.ID.String(),
+		"title":       "Left landing light inoperative",
+		"ata_chapter": "33",
+	}), mechanic, domain.RoleMechanic, ReportDefect, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 reporting a defect, got %d: %s", rr.Code, rr.Body.String())
+	}
+	defect := decodeDefect(rr)
+	if defect.State != "open" {
+		t.Fatalf("expected an open defect, got %s", defect.State)
+	}
+	params := map[string]string{"id": defect.ID.String()}
+	path := "/api/v1/defects/" + defect.ID.String()
+
+	rr = serve(newJSONRequest(t, http.MethodPost, path+"/defer", map[string]any{
+		"mel_category":       "B",
+		"mel_item":           "33-41-01",
+		"deferral_reference": "DD-100",
+		"expires_at":         "2026-03-20T00:00:00Z",
+	}), mechanic, domain.RoleMechanic, DeferDefect, params)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 deferring beyond the category interval, got %d", rr.Code)
+	}
+	rr = serve(newJSONRequest(t, http.MethodPost, path+"/defer", map[string]any{
+		"mel_category":             "B",
+		"mel_item":                 "33-41-01",
+		"deferral_reference":       "DD-100",
+		"operational_restrictions": "Day VMC only",
+	}), mechanic, domain.RoleMechanic, DeferDefect, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deferring, got %d: %s", rr.Code, rr.Body.String())
+	}
+	defect = decodeDefect(rr)
+	expiry := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	if defect.State != "deferred" || defect.ExpiresAt == nil || !defect.ExpiresAt.Equal(expiry) {
+		t.Fatalf("expected a deferral expiring %s, got %+v", expiry, defect)
+	}
+	if defect.RectificationTaskID == nil {
+		t.Fatalf("expected a rectification task")
+	}
+	task, err := taskRepo.GetByID(ctx, orgID, *defect.RectificationTaskID)
+	if err != nil {
+		t.Fatalf("load rectification task: %v", err)
+	}
+	if task.Type != domain.TaskTypeRepair || task.Priority != domain.PriorityUrgent || !task.EndTime.Equal(expiry) || task.AircraftID != aircraft.ID {
+		t.Fatalf("unexpected rectification task: %+v", task)
+	}
+
+	extension := map[string]any{
+		"new_expires_at":     "2026-03-16T00:00:00Z",
+		"reason":             "Spare lamp assembly on back order",
+		"approval_reference": "QA-2026-17",
+	}
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/extensions", extension), mechanic, domain.RoleMechanic, ExtendDeferral, params); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic extending, got %d", rr.Code)
+	}
+	rr = serve(newJSONRequest(t, http.MethodPost, path+"/extensions", extension), quality, domain.RoleTenantAdmin, ExtendDeferral, params)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 extending, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if defect = decodeDefect(rr); !defect.ExpiresAt.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the extended expiry, got %v", defect.ExpiresAt)
+	}
+	extension["new_expires_at"] = "2026-03-17T00:00:00Z"
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/extensions", extension), quality, domain.RoleTenantAdmin, ExtendDeferral, params); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 extending twice, got %d", rr.Code)
+	}
+
+	// Past the extended expiry the aircraft may not return to service.
+	clock.Advance(6 * 24 * time.Hour)
+	aircraftParams := map[string]string{"id": aircraft.ID.String()}
+	setStatus := func(status string) *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/aircraft/"+aircraft.ID.String(), map[string]any{"status": status})
+		return serve(req, scheduler, domain.RoleScheduler, UpdateAircraft, aircraftParams)
+	}
+	if rr := setStatus("grounded"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 grounding, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := setStatus("operational"); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 returning to service with an expired deferral, got %d", rr.Code)
+	}
+
+	if rr := serve(httptest.NewRequest(http.MethodPost, path+"/rectify", nil), mechanic, domain.RoleMechanic, RectifyDefect, params); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 rectifying with the task still scheduled, got %d", rr.Code)
+	}
+	task.State = domain.TaskStateCancelled
+	_, _ = taskRepo.Update(ctx, task)
+	rr = serve(newJSONRequest(t, http.MethodPost, path+"/rectify", map[string]any{"notes": "Lamp replaced"}), mechanic, domain.RoleMechanic, RectifyDefect, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 rectifying, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if defect = decodeDefect(rr); defect.State != "rectified" || defect.Notes != "Lamp replaced" {
+		t.Fatalf("unexpected rectified defect: %+v", defect)
+	}
+	if rr := setStatus("operational"); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 returning to service, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := serve(httptest.NewRequest(http.MethodPost, path+"/close", nil), mechanic, domain.RoleMechanic, CloseDefect, params); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic closing, got %d", rr.Code)
+	}
+	rr = serve(httptest.NewRequest(http.MethodPost, path+"/close", nil), scheduler, domain.RoleScheduler, CloseDefect, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 closing, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if defect = decodeDefect(rr); defect.State != "closed" {
+		t.Fatalf("expected a closed defect, got %s", defect.State)
+	}
+}
+
+func TestReturnToServiceFindsExpiredDeferralBehindOpenOnes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := &manualClock{now: now}
+	orgID := uuid.New()
+	scheduler := uuid.New()
+
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N401AM",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+	})
+	defectRepo := newFakeDefectRepo()
+	deferred := func(reportedAt, expiresAt time.Time, reference string) {
+		category := domain.MELCategoryC
+		if _, err := defectRepo.Create(ctx, domain.Defect{
+			ID:                uuid.New(),
+			OrgID:             orgID,
+			AircraftID:        aircraft.ID,
+			Title:             "Cabin placard missing",
+			State:             domain.DefectDeferred,
+			ReportedAt:        reportedAt,
+			MELCategory:       &category,
+			DeferralReference: reference,
+			ExpiresAt:         &expiresAt,
+		}); err != nil {
+			t.Fatalf("seed defect: %v", err)
+		}
+	}
+	// The expired deferral was reported first, so it sorts behind more
+	// than a page of open deferrals reported since.
+	deferred(now.Add(-30*24*time.Hour), now, "DD-EXPIRED")
+	for i := 0; i < 201; i++ {
+		deferred(now.Add(-time.Duration(i)*time.Minute), now.Add(10*24*time.Hour), "DD-OPEN")
+	}
+
+	defectService := &services.DefectService{Defects: defectRepo, Aircraft: aircraftRepo, Clock: clock}
+	registry := middleware.ServiceRegistry{
+		Aircraft: &services.AircraftService{Aircraft: aircraftRepo, Defects: defectService, Clock: clock},
+		Defects:  defectService,
+	}
+	req := newJSONRequest(t, http.MethodPatch, "/api/v1/aircraft/"+aircraft.ID.String(), map[string]any{"status": "operational"})
+	req = withRouteParams(withUser(req, orgID, scheduler, domain.RoleScheduler), map[string]string{"id": aircraft.ID.String()})
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(UpdateAircraft)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 returning to service with an expired deferral, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
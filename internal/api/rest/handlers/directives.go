@@ -27,7 +27,7 @@ type directiveCreateRequest struct {
 	SourceURL             string   `json:"source_url"`
 }
 
-type complianceUpdateRequest struct {
+type aircraftComplianceUpdateRequest struct {
 	AircraftID  string  `json:"aircraft_id" validate:"required,uuid"`
 	DirectiveID string  `json:"directive_id" validate:"required,uuid"`
 	Status      string  `json:"status" validate:"required,oneof=pending in_progress compliant not_applicable overdue"`
@@ -292,7 +292,7 @@ func UpdateAircraftDirectiveCompliance(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
 		return
 	}
-	var req complianceUpdateRequest
+	var req aircraftComplianceUpdateRequest
 	if err := decodeAndValidateJSON(r, &req); err != nil {
 		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
 		return
@@ -8,6 +8,7 @@ import (
 )
 
 func writeDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var capacityConflict *domain.CapacityConflictError
 	switch {
 	case errors.Is(err, domain.ErrUnauthorized):
 		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
@@ -15,6 +16,8 @@ func writeDomainError(w http.ResponseWriter, r *http.Request, err error) {
 		writeError(w, r, http.StatusForbidden, "FORBIDDEN", "forbidden")
 	case errors.Is(err, domain.ErrNotFound):
 		writeError(w, r, http.StatusNotFound, "NOT_FOUND", "not found")
+	case errors.As(err, &capacityConflict):
+		writeError(w, r, http.StatusConflict, string(capacityConflict.Kind), capacityConflict.Message)
 	case errors.Is(err, domain.ErrConflict):
 		writeError(w, r, http.StatusConflict, "CONFLICT", "conflict")
 	case errors.Is(err, domain.ErrValidation):
@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"math"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +32,13 @@ func applyOffsetLimit[T any](items []T, offset, limit int) []T {
 type fakeTaskRepo struct {
 	mu    sync.Mutex
 	tasks map[uuid.UUID]domain.MaintenanceTask
+	// labor, aircraft and certs, when set, log the task's labor as recency
+	// when it completes.
+	labor    *fakeLaborRepo
+	aircraft *fakeAircraftRepo
+	certs    *fakeAuthorizationRepo
+	// compliance, when set, receives the checklist of created tasks.
+	compliance *fakeComplianceRepo
 }
 
 func newFakeTaskRepo() *fakeTaskRepo {
@@ -50,6 +62,20 @@ func (f *fakeTaskRepo) Create(_ context.Context, task domain.MaintenanceTask) (d
 	return task, nil
 }
 
+func (f *fakeTaskRepo) CreateWithCompliance(ctx context.Context, task domain.MaintenanceTask, items []domain.ComplianceItem) (domain.MaintenanceTask, error) {
+	if len(items) > 0 && f.compliance == nil {
+		return domain.MaintenanceTask{}, domain.NewValidationError("compliance repository unavailable")
+	}
+	created, err := f.Create(ctx, task)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	for _, item := range items {
+		_ = f.compliance.Create(ctx, item)
+	}
+	return created, nil
+}
+
 func (f *fakeTaskRepo) Update(_ context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -86,6 +112,12 @@ func (f *fakeTaskRepo) List(_ context.Context, filter ports.TaskFilter) ([]domai
 		if filter.AircraftID != nil && task.AircraftID != *filter.AircraftID {
 			continue
 		}
+		if filter.ProgramID != nil && (task.ProgramID == nil || *task.ProgramID != *filter.ProgramID) {
+			continue
+		}
+		if filter.WorkPackageID != nil && (task.WorkPackageID == nil || *task.WorkPackageID != *filter.WorkPackageID) {
+			continue
+		}
 		if filter.State != nil && task.State != *filter.State {
 			continue
 		}
@@ -98,6 +130,24 @@ func (f *fakeTaskRepo) List(_ context.Context, filter ports.TaskFilter) ([]domai
 		if filter.StartTo != nil && task.StartTime.After(*filter.StartTo) {
 			continue
 		}
+		if filter.BayID != nil && (task.BayID == nil || *task.BayID != *filter.BayID) {
+			continue
+		}
+		if filter.AssignedMechanicID != nil && (task.AssignedMechanicID == nil || *task.AssignedMechanicID != *filter.AssignedMechanicID) {
+			continue
+		}
+		if filter.ActiveOnly && !task.IsActive() {
+			continue
+		}
+		if filter.OverlapFrom != nil && !task.EndTime.After(*filter.OverlapFrom) {
+			continue
+		}
+		if filter.OverlapTo != nil && !task.StartTime.Before(*filter.OverlapTo) {
+			continue
+		}
+		if filter.IDs != nil && !slices.Contains(filter.IDs, task.ID) {
+			continue
+		}
 		out = append(out, task)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
@@ -114,6 +164,24 @@ func (f *fakeTaskRepo) UpdateState(_ context.Context, orgID, id uuid.UUID, newSt
 	task.Notes = notes
 	task.UpdatedAt = now
 	f.tasks[id] = task
+	if newState == domain.TaskStateCompleted && f.labor != nil && f.aircraft != nil && f.certs != nil {
+		aircraft, err := f.aircraft.GetByID(context.Background(), orgID, task.AircraftID)
+		if err == nil && aircraft.AircraftTypeID != nil {
+			entries, _ := f.labor.ListByTask(context.Background(), orgID, task.ID)
+			for _, day := range domain.LaborDays(entries) {
+				_ = f.certs.LogRecency(context.Background(), domain.EmployeeRecencyLog{
+					ID:             uuid.New(),
+					OrgID:          orgID,
+					UserID:         day.MechanicID,
+					AircraftTypeID: *aircraft.AircraftTypeID,
+					TaskID:         task.ID,
+					WorkDate:       day.WorkDate,
+					HoursLogged:    day.Hours,
+					CreatedAt:      now,
+				})
+			}
+		}
+	}
 	return task, nil
 }
 
@@ -263,9 +331,18 @@ func (f *fakePartItemRepo) List(_ context.Context, filter ports.PartItemFilter)
 		if filter.Status != nil && item.Status != *filter.Status {
 			continue
 		}
+		if filter.AircraftID != nil && (item.AircraftID == nil || *item.AircraftID != *filter.AircraftID) {
+			continue
+		}
+		if filter.LifeLimited && !item.IsLifeLimited() {
+			continue
+		}
 		if filter.ExpiryBefore != nil && item.ExpiryDate != nil && item.ExpiryDate.After(*filter.ExpiryBefore) {
 			continue
 		}
+		if filter.IDs != nil && !slices.Contains(filter.IDs, item.ID) {
+			continue
+		}
 		out = append(out, item)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
@@ -296,6 +373,24 @@ func (f *fakePartItemRepo) UpdateStatus(_ context.Context, orgID, id uuid.UUID,
 	return nil
 }
 
+// accrueUsage adds flown hours and cycles to the parts fitted to the
+// aircraft, as the utilization repository does when recording an entry.
+func (f *fakePartItemRepo) accrueUsage(orgID, aircraftID uuid.UUID, hours float64, cycles int, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, item := range f.items {
+		if item.DeletedAt != nil || item.OrgID != orgID || item.AircraftID == nil || *item.AircraftID != aircraftID {
+			continue
+		}
+		item.HoursSinceNew += hours
+		item.CyclesSinceNew += cycles
+		item.HoursSinceOverhaul += hours
+		item.CyclesSinceOverhaul += cycles
+		item.UpdatedAt = at
+		f.items[id] = item
+	}
+}
+
 type fakeComplianceRepo struct {
 	mu    sync.Mutex
 	items map[uuid.UUID]domain.ComplianceItem
@@ -560,6 +655,8 @@ func (f *fakeUserRepo) List(_ context.Context, filter ports.UserFilter) ([]domai
 type fakeAircraftRepo struct {
 	mu       sync.Mutex
 	aircraft map[uuid.UUID]domain.Aircraft
+	// programs, when set, receives the programs written with an aircraft.
+	programs *fakeProgramRepo
 }
 
 func newFakeAircraftRepo() *fakeAircraftRepo {
@@ -608,13 +705,44 @@ func (f *fakeAircraftRepo) Create(_ context.Context, aircraft domain.Aircraft) (
 func (f *fakeAircraftRepo) Update(_ context.Context, aircraft domain.Aircraft) (domain.Aircraft, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	if _, ok := f.aircraft[aircraft.ID]; !ok {
+	existing, ok := f.aircraft[aircraft.ID]
+	if !ok {
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
+	aircraft.FlightHoursTotal = existing.FlightHoursTotal
+	aircraft.CyclesTotal = existing.CyclesTotal
 	f.aircraft[aircraft.ID] = aircraft
 	return aircraft, nil
 }
 
+func (f *fakeAircraftRepo) CreateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	if len(programs) > 0 && f.programs == nil {
+		return domain.Aircraft{}, domain.NewValidationError("program repository unavailable")
+	}
+	created, err := f.Create(ctx, aircraft)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	for _, program := range programs {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return created, nil
+}
+
+func (f *fakeAircraftRepo) UpdateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	if len(programs) > 0 && f.programs == nil {
+		return domain.Aircraft{}, domain.NewValidationError("program repository unavailable")
+	}
+	updated, err := f.Update(ctx, aircraft)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	for _, program := range programs {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return updated, nil
+}
+
 func (f *fakeAircraftRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -647,11 +775,131 @@ func (f *fakeAircraftRepo) List(_ context.Context, filter ports.AircraftFilter)
 		if filter.TailNumber != "" && !strings.EqualFold(item.TailNumber, filter.TailNumber) {
 			continue
 		}
+		if filter.AircraftTypeID != nil && (item.AircraftTypeID == nil || *item.AircraftTypeID != *filter.AircraftTypeID) {
+			continue
+		}
 		out = append(out, item)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
 }
 
+type fakeUtilizationRepo struct {
+	mu       sync.Mutex
+	entries  []domain.AircraftUtilization
+	aircraft *fakeAircraftRepo
+	// items, when set, accrue each entry's usage on the aircraft's parts.
+	items *fakePartItemRepo
+	// beforeCreate, when set, runs once before the next entry is checked,
+	// standing in for an entry recorded concurrently.
+	beforeCreate func()
+}
+
+func newFakeUtilizationRepo(aircraft *fakeAircraftRepo) *fakeUtilizationRepo {
+	return &fakeUtilizationRepo{aircraft: aircraft}
+}
+
+func (f *fakeUtilizationRepo) Create(_ context.Context, entry domain.AircraftUtilization) (domain.AircraftUtilization, error) {
+	if hook := f.beforeCreate; hook != nil {
+		f.beforeCreate = nil
+		hook()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.aircraft != nil {
+		f.aircraft.mu.Lock()
+		item, ok := f.aircraft.aircraft[entry.AircraftID]
+		if ok {
+			prevHours := float64(item.FlightHoursTotal)
+			prevCycles := item.CyclesTotal
+			for i := len(f.entries) - 1; i >= 0; i-- {
+				if f.entries[i].AircraftID == entry.AircraftID {
+					prevHours = math.Max(prevHours, f.entries[i].HoursTotal)
+					if f.entries[i].CyclesTotal > prevCycles {
+						prevCycles = f.entries[i].CyclesTotal
+					}
+					break
+				}
+			}
+			if math.Abs(entry.HoursTotal-entry.BlockHours-prevHours) > 0.005 || entry.CyclesTotal-entry.Cycles != prevCycles {
+				f.aircraft.mu.Unlock()
+				return domain.AircraftUtilization{}, domain.NewConflictError("aircraft totals changed while the entry was recorded")
+			}
+			item.FlightHoursTotal = int(entry.HoursTotal)
+			item.CyclesTotal = entry.CyclesTotal
+			f.aircraft.aircraft[entry.AircraftID] = item
+		}
+		f.aircraft.mu.Unlock()
+	}
+	if f.items != nil && (entry.BlockHours > 0 || entry.Cycles > 0) {
+		f.items.accrueUsage(entry.OrgID, entry.AircraftID, entry.BlockHours, entry.Cycles, entry.CreatedAt)
+	}
+	f.entries = append(f.entries, entry)
+	return entry, nil
+}
+
+func (f *fakeUtilizationRepo) GetLatest(_ context.Context, orgID, aircraftID uuid.UUID) (domain.AircraftUtilization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		entry := f.entries[i]
+		if entry.OrgID == orgID && entry.AircraftID == aircraftID {
+			return entry, nil
+		}
+	}
+	return domain.AircraftUtilization{}, domain.ErrNotFound
+}
+
+func (f *fakeUtilizationRepo) List(_ context.Context, filter ports.AircraftUtilizationFilter) ([]domain.AircraftUtilization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.AircraftUtilization
+	// Entries are appended in date order; list them newest first.
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		entry := f.entries[i]
+		if filter.OrgID != nil && entry.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && entry.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.From != nil && entry.UtilizationDate.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && entry.UtilizationDate.After(*filter.To) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeUtilizationRepo) AverageDaily(_ context.Context, orgID, aircraftID uuid.UUID, from, to time.Time) (domain.UtilizationRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var hours float64
+	var cycles int
+	var first *time.Time
+	for _, entry := range f.entries {
+		if entry.OrgID != orgID || entry.AircraftID != aircraftID {
+			continue
+		}
+		if entry.UtilizationDate.Before(from) || entry.UtilizationDate.After(to) {
+			continue
+		}
+		hours += entry.BlockHours
+		cycles += entry.Cycles
+		if first == nil || entry.UtilizationDate.Before(*first) {
+			date := entry.UtilizationDate
+			first = &date
+		}
+	}
+	if first == nil {
+		return domain.UtilizationRate{}, nil
+	}
+	days := float64(int(to.Sub(*first).Hours()/24) + 1)
+	return domain.UtilizationRate{HoursPerDay: hours / days, CyclesPerDay: float64(cycles) / days}, nil
+}
+
 type fakeProgramRepo struct {
 	mu       sync.Mutex
 	programs map[uuid.UUID]domain.MaintenanceProgram
@@ -717,21 +965,26 @@ func (f *fakeProgramRepo) List(_ context.Context, filter ports.MaintenanceProgra
 				continue
 			}
 		}
+		if filter.TemplateID != nil {
+			if program.TemplateID == nil || *program.TemplateID != *filter.TemplateID {
+				continue
+			}
+		}
 		out = append(out, program)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
 }
 
-func (f *fakeProgramRepo) ListDueCalendar(_ context.Context, _ time.Time, limit int) ([]domain.MaintenanceProgram, error) {
+func (f *fakeProgramRepo) ListDue(_ context.Context, now time.Time, lookAhead domain.ProgramLookAhead, limit int) ([]domain.MaintenanceProgram, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	if len(f.due) == 0 {
 		var out []domain.MaintenanceProgram
 		for _, program := range f.programs {
-			if program.DeletedAt != nil {
+			if program.DeletedAt != nil || program.AircraftID == nil {
 				continue
 			}
-			if program.IntervalType != domain.ProgramIntervalCalendar {
+			if !program.IsUsageBased() && !program.IsDue(domain.Aircraft{}, now, lookAhead) {
 				continue
 			}
 			out = append(out, program)
@@ -764,6 +1017,286 @@ func (f *fakeProgramRepo) GetByName(_ context.Context, orgID uuid.UUID, name str
 	return domain.MaintenanceProgram{}, domain.ErrNotFound
 }
 
+type fakeProgramTemplateRepo struct {
+	mu        sync.Mutex
+	templates map[uuid.UUID]domain.MaintenanceProgramTemplate
+	// programs, when set, receives the instances written by Propagate.
+	programs *fakeProgramRepo
+}
+
+func newFakeProgramTemplateRepo() *fakeProgramTemplateRepo {
+	return &fakeProgramTemplateRepo{templates: make(map[uuid.UUID]domain.MaintenanceProgramTemplate)}
+}
+
+func (f *fakeProgramTemplateRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	template, ok := f.templates[id]
+	if !ok || template.OrgID != orgID || template.DeletedAt != nil {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) Create(_ context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.templates[template.ID] = template
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) Update(_ context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.templates[template.ID]; !ok {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	f.templates[template.ID] = template
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) CreateWithInstances(ctx context.Context, template domain.MaintenanceProgramTemplate, instances []domain.MaintenanceProgram) (domain.MaintenanceProgramTemplate, error) {
+	if len(instances) > 0 && f.programs == nil {
+		return domain.MaintenanceProgramTemplate{}, domain.NewValidationError("program repository unavailable")
+	}
+	created, err := f.Create(ctx, template)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	for _, program := range instances {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return created, nil
+}
+
+func (f *fakeProgramTemplateRepo) Propagate(_ context.Context, template domain.MaintenanceProgramTemplate, created, updated []domain.MaintenanceProgram) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.templates[template.ID]
+	if !ok || stored.OrgID != template.OrgID || stored.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	if stored.Revision != template.Revision {
+		return domain.NewConflictError("template revision has changed since the preview")
+	}
+	if f.programs == nil {
+		return domain.NewValidationError("program repository unavailable")
+	}
+	f.programs.mu.Lock()
+	defer f.programs.mu.Unlock()
+	for _, program := range updated {
+		if _, ok := f.programs.programs[program.ID]; !ok {
+			return domain.ErrNotFound
+		}
+	}
+	for _, program := range created {
+		f.programs.programs[program.ID] = program
+	}
+	for _, program := range updated {
+		f.programs.programs[program.ID] = program
+	}
+	return nil
+}
+
+func (f *fakeProgramTemplateRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	template, ok := f.templates[id]
+	if !ok || template.OrgID != orgID || template.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	template.DeletedAt = &at
+	f.templates[id] = template
+	return nil
+}
+
+func (f *fakeProgramTemplateRepo) List(_ context.Context, filter ports.MaintenanceProgramTemplateFilter) ([]domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.MaintenanceProgramTemplate
+	for _, template := range f.templates {
+		if template.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && template.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftTypeID != nil && template.AircraftTypeID != *filter.AircraftTypeID {
+			continue
+		}
+		out = append(out, template)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeWorkPackageRepo struct {
+	mu       sync.Mutex
+	packages map[uuid.UUID]domain.WorkPackage
+	tasks    *fakeTaskRepo
+}
+
+func newFakeWorkPackageRepo(tasks *fakeTaskRepo) *fakeWorkPackageRepo {
+	return &fakeWorkPackageRepo{packages: make(map[uuid.UUID]domain.WorkPackage), tasks: tasks}
+}
+
+func (f *fakeWorkPackageRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.WorkPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pkg, ok := f.packages[id]
+	if !ok || pkg.OrgID != orgID || pkg.DeletedAt != nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	return pkg, nil
+}
+
+func (f *fakeWorkPackageRepo) Create(_ context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.packages[pkg.ID] = pkg
+	return pkg, nil
+}
+
+func (f *fakeWorkPackageRepo) Update(_ context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.packages[pkg.ID]
+	if !ok || existing.OrgID != pkg.OrgID || existing.DeletedAt != nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	pkg.State = existing.State
+	f.packages[pkg.ID] = pkg
+	return pkg, nil
+}
+
+func (f *fakeWorkPackageRepo) UpdateState(_ context.Context, orgID, id uuid.UUID, newState domain.WorkPackageState, now time.Time) (domain.WorkPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pkg, ok := f.packages[id]
+	if !ok || pkg.OrgID != orgID || pkg.DeletedAt != nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	pkg.State = newState
+	pkg.UpdatedAt = now
+	f.packages[id] = pkg
+	return pkg, nil
+}
+
+func (f *fakeWorkPackageRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pkg, ok := f.packages[id]
+	if !ok || pkg.OrgID != orgID || pkg.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	pkg.DeletedAt = &at
+	f.packages[id] = pkg
+	if f.tasks != nil {
+		f.tasks.mu.Lock()
+		for taskID, task := range f.tasks.tasks {
+			if task.WorkPackageID != nil && *task.WorkPackageID == id {
+				task.WorkPackageID = nil
+				f.tasks.tasks[taskID] = task
+			}
+		}
+		f.tasks.mu.Unlock()
+	}
+	return nil
+}
+
+func (f *fakeWorkPackageRepo) List(_ context.Context, filter ports.WorkPackageFilter) ([]domain.WorkPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.WorkPackage
+	for _, pkg := range f.packages {
+		if pkg.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && pkg.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && pkg.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.State != nil && pkg.State != *filter.State {
+			continue
+		}
+		if filter.VisitFrom != nil && pkg.VisitEnd.Before(*filter.VisitFrom) {
+			continue
+		}
+		if filter.VisitTo != nil && pkg.VisitStart.After(*filter.VisitTo) {
+			continue
+		}
+		out = append(out, pkg)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeComponentChangeRepo struct {
+	mu      sync.Mutex
+	parts   *fakePartItemRepo
+	changes []domain.ComponentChange
+}
+
+func newFakeComponentChangeRepo(parts *fakePartItemRepo) *fakeComponentChangeRepo {
+	return &fakeComponentChangeRepo{parts: parts}
+}
+
+func (f *fakeComponentChangeRepo) Record(_ context.Context, change domain.ComponentChange) (domain.ComponentChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parts.mu.Lock()
+	defer f.parts.mu.Unlock()
+	item, ok := f.parts.items[change.PartItemID]
+	if !ok || item.OrgID != change.OrgID || item.DeletedAt != nil {
+		return domain.ComponentChange{}, domain.ErrNotFound
+	}
+	switch change.Action {
+	case domain.ComponentInstall:
+		if item.AircraftID != nil {
+			return domain.ComponentChange{}, domain.ErrConflict
+		}
+		for _, other := range f.parts.items {
+			if other.DeletedAt == nil && other.AircraftID != nil && *other.AircraftID == change.AircraftID && other.Position == change.Position {
+				return domain.ComponentChange{}, domain.ErrConflict
+			}
+		}
+		aircraftID := change.AircraftID
+		item.AircraftID = &aircraftID
+		item.Position = change.Position
+		item.Status = domain.PartItemUsed
+	case domain.ComponentRemove:
+		if item.AircraftID == nil || *item.AircraftID != change.AircraftID {
+			return domain.ComponentChange{}, domain.ErrConflict
+		}
+		item.AircraftID = nil
+		item.Position = ""
+		item.Status = domain.PartItemInStock
+	}
+	item.UpdatedAt = change.CreatedAt
+	f.parts.items[item.ID] = item
+	f.changes = append(f.changes, change)
+	return change, nil
+}
+
+func (f *fakeComponentChangeRepo) List(_ context.Context, filter ports.ComponentChangeFilter) ([]domain.ComponentChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.ComponentChange
+	for _, change := range f.changes {
+		if filter.OrgID != nil && change.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.PartItemID != nil && change.PartItemID != *filter.PartItemID {
+			continue
+		}
+		if filter.AircraftID != nil && change.AircraftID != *filter.AircraftID {
+			continue
+		}
+		out = append(out, change)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
 type fakeImportRepo struct {
 	mu      sync.Mutex
 	imports map[uuid.UUID]domain.Import
@@ -989,3 +1522,1623 @@ func (f *fakeReportRepo) Compliance(_ context.Context, filter ports.ComplianceRe
 	f.lastComplianceFilter = filter
 	return f.compliance, nil
 }
+
+type fakeStationRepo struct {
+	mu       sync.Mutex
+	stations map[uuid.UUID]domain.Station
+}
+
+func newFakeStationRepo() *fakeStationRepo {
+	return &fakeStationRepo{stations: make(map[uuid.UUID]domain.Station)}
+}
+
+func (f *fakeStationRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Station, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	station, ok := f.stations[id]
+	if !ok || station.OrgID != orgID || station.DeletedAt != nil {
+		return domain.Station{}, domain.ErrNotFound
+	}
+	return station, nil
+}
+
+func (f *fakeStationRepo) Create(_ context.Context, station domain.Station) (domain.Station, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stations[station.ID] = station
+	return station, nil
+}
+
+func (f *fakeStationRepo) Update(_ context.Context, station domain.Station) (domain.Station, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.stations[station.ID]
+	if !ok || existing.OrgID != station.OrgID || existing.DeletedAt != nil {
+		return domain.Station{}, domain.ErrNotFound
+	}
+	f.stations[station.ID] = station
+	return station, nil
+}
+
+func (f *fakeStationRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	station, ok := f.stations[id]
+	if !ok || station.OrgID != orgID || station.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	station.DeletedAt = &at
+	f.stations[id] = station
+	return nil
+}
+
+func (f *fakeStationRepo) List(_ context.Context, filter ports.StationFilter) ([]domain.Station, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Station
+	for _, station := range f.stations {
+		if station.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && station.OrgID != *filter.OrgID {
+			continue
+		}
+		out = append(out, station)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeHangarBayRepo struct {
+	mu   sync.Mutex
+	bays map[uuid.UUID]domain.HangarBay
+}
+
+func newFakeHangarBayRepo() *fakeHangarBayRepo {
+	return &fakeHangarBayRepo{bays: make(map[uuid.UUID]domain.HangarBay)}
+}
+
+func (f *fakeHangarBayRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.HangarBay, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bay, ok := f.bays[id]
+	if !ok || bay.OrgID != orgID || bay.DeletedAt != nil {
+		return domain.HangarBay{}, domain.ErrNotFound
+	}
+	return bay, nil
+}
+
+func (f *fakeHangarBayRepo) Create(_ context.Context, bay domain.HangarBay) (domain.HangarBay, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bays[bay.ID] = bay
+	return bay, nil
+}
+
+func (f *fakeHangarBayRepo) Update(_ context.Context, bay domain.HangarBay) (domain.HangarBay, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.bays[bay.ID]
+	if !ok || existing.OrgID != bay.OrgID || existing.DeletedAt != nil {
+		return domain.HangarBay{}, domain.ErrNotFound
+	}
+	f.bays[bay.ID] = bay
+	return bay, nil
+}
+
+func (f *fakeHangarBayRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bay, ok := f.bays[id]
+	if !ok || bay.OrgID != orgID || bay.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	bay.DeletedAt = &at
+	f.bays[id] = bay
+	return nil
+}
+
+func (f *fakeHangarBayRepo) List(_ context.Context, filter ports.HangarBayFilter) ([]domain.HangarBay, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.HangarBay
+	for _, bay := range f.bays {
+		if bay.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && bay.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.StationID != nil && bay.StationID != *filter.StationID {
+			continue
+		}
+		out = append(out, bay)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeBayCapacityWindowRepo struct {
+	mu      sync.Mutex
+	windows map[uuid.UUID]domain.BayCapacityWindow
+}
+
+func newFakeBayCapacityWindowRepo() *fakeBayCapacityWindowRepo {
+	return &fakeBayCapacityWindowRepo{windows: make(map[uuid.UUID]domain.BayCapacityWindow)}
+}
+
+func (f *fakeBayCapacityWindowRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.BayCapacityWindow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	window, ok := f.windows[id]
+	if !ok || window.OrgID != orgID {
+		return domain.BayCapacityWindow{}, domain.ErrNotFound
+	}
+	return window, nil
+}
+
+func (f *fakeBayCapacityWindowRepo) Create(_ context.Context, window domain.BayCapacityWindow) (domain.BayCapacityWindow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.windows[window.ID] = window
+	return window, nil
+}
+
+func (f *fakeBayCapacityWindowRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	window, ok := f.windows[id]
+	if !ok || window.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.windows, id)
+	return nil
+}
+
+func (f *fakeBayCapacityWindowRepo) ListOverlapping(_ context.Context, orgID, bayID uuid.UUID, from, to time.Time) ([]domain.BayCapacityWindow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.BayCapacityWindow
+	for _, window := range f.windows {
+		if window.OrgID != orgID || window.BayID != bayID {
+			continue
+		}
+		if !window.EndTime.After(from) || !window.StartTime.Before(to) {
+			continue
+		}
+		out = append(out, window)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+	return out, nil
+}
+
+type fakeTaskDependencyRepo struct {
+	mu   sync.Mutex
+	deps map[uuid.UUID]domain.TaskDependency
+}
+
+func newFakeTaskDependencyRepo() *fakeTaskDependencyRepo {
+	return &fakeTaskDependencyRepo{deps: make(map[uuid.UUID]domain.TaskDependency)}
+}
+
+func (f *fakeTaskDependencyRepo) Create(_ context.Context, dep domain.TaskDependency) (domain.TaskDependency, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deps[dep.ID] = dep
+	return dep, nil
+}
+
+func (f *fakeTaskDependencyRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dep, ok := f.deps[id]
+	if !ok || dep.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.deps, id)
+	return nil
+}
+
+func (f *fakeTaskDependencyRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.TaskDependency, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.TaskDependency
+	for _, dep := range f.deps {
+		if dep.OrgID == orgID && dep.TaskID == taskID {
+			out = append(out, dep)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTaskDependencyRepo) ListByTasks(_ context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.TaskDependency, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.TaskDependency
+	for _, dep := range f.deps {
+		if dep.OrgID == orgID && slices.Contains(taskIDs, dep.TaskID) {
+			out = append(out, dep)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTaskDependencyRepo) ListDependents(_ context.Context, orgID, taskID uuid.UUID) ([]domain.TaskDependency, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.TaskDependency
+	for _, dep := range f.deps {
+		if dep.OrgID == orgID && dep.DependsOnTaskID == taskID {
+			out = append(out, dep)
+		}
+	}
+	return out, nil
+}
+
+// fakeQualificationRepo answers GetQualifiedMechanics only; the embedded
+// interface is left nil.
+type fakeQualificationRepo struct {
+	ports.CertificationRepository
+	qualified map[domain.TaskType][]uuid.UUID
+}
+
+func (f *fakeQualificationRepo) GetQualifiedMechanics(_ context.Context, _ uuid.UUID, taskType domain.TaskType, _ *uuid.UUID) ([]uuid.UUID, error) {
+	return f.qualified[taskType], nil
+}
+
+// fakeDirectiveRepo answers directive and aircraft compliance lookups only;
+// the embedded interface is left nil.
+type fakeDirectiveRepo struct {
+	ports.DirectiveRepository
+	mu         sync.Mutex
+	directives map[uuid.UUID]domain.ComplianceDirective
+	compliance []domain.AircraftDirectiveCompliance
+}
+
+func newFakeDirectiveRepo() *fakeDirectiveRepo {
+	return &fakeDirectiveRepo{directives: make(map[uuid.UUID]domain.ComplianceDirective)}
+}
+
+func (f *fakeDirectiveRepo) GetDirectiveByID(_ context.Context, id uuid.UUID) (domain.ComplianceDirective, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	directive, ok := f.directives[id]
+	if !ok {
+		return domain.ComplianceDirective{}, domain.ErrNotFound
+	}
+	return directive, nil
+}
+
+func (f *fakeDirectiveRepo) ListAircraftCompliance(_ context.Context, filter ports.AircraftComplianceFilter) ([]domain.AircraftDirectiveCompliance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.AircraftDirectiveCompliance
+	for _, record := range f.compliance {
+		if filter.OrgID != nil && record.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && record.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.Status != nil && record.Status != *filter.Status {
+			continue
+		}
+		out = append(out, record)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakePartReservationRepo struct {
+	mu           sync.Mutex
+	reservations map[uuid.UUID]domain.PartReservation
+}
+
+func newFakePartReservationRepo() *fakePartReservationRepo {
+	return &fakePartReservationRepo{reservations: make(map[uuid.UUID]domain.PartReservation)}
+}
+
+func (f *fakePartReservationRepo) Create(_ context.Context, reservation domain.PartReservation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reservations[reservation.ID] = reservation
+	return nil
+}
+
+func (f *fakePartReservationRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.PartReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, ok := f.reservations[id]
+	if !ok || reservation.OrgID != orgID {
+		return domain.PartReservation{}, domain.ErrNotFound
+	}
+	return reservation, nil
+}
+
+func (f *fakePartReservationRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.PartReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.PartReservation
+	for _, reservation := range f.reservations {
+		if reservation.OrgID == orgID && reservation.TaskID == taskID {
+			out = append(out, reservation)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePartReservationRepo) ListByTasks(_ context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.PartReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.PartReservation
+	for _, reservation := range f.reservations {
+		if reservation.OrgID == orgID && slices.Contains(taskIDs, reservation.TaskID) {
+			out = append(out, reservation)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePartReservationRepo) UpdateState(_ context.Context, orgID, id uuid.UUID, state domain.PartReservationState, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	reservation, ok := f.reservations[id]
+	if !ok || reservation.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	reservation.State = state
+	reservation.UpdatedAt = now
+	f.reservations[id] = reservation
+	return nil
+}
+
+func (f *fakePartReservationRepo) ReleaseByTask(_ context.Context, orgID, taskID uuid.UUID, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, reservation := range f.reservations {
+		if reservation.OrgID == orgID && reservation.TaskID == taskID && reservation.State == domain.ReservationReserved {
+			reservation.State = domain.ReservationReleased
+			reservation.UpdatedAt = now
+			f.reservations[id] = reservation
+		}
+	}
+	return nil
+}
+
+func (f *fakePartReservationRepo) ListUnavailable(_ context.Context, _ int) ([]domain.PartReservation, error) {
+	return nil, nil
+}
+
+type fakeSchedulePlanRepo struct {
+	mu    sync.Mutex
+	tasks *fakeTaskRepo
+	plans map[uuid.UUID]domain.SchedulePlan
+}
+
+func newFakeSchedulePlanRepo(tasks *fakeTaskRepo) *fakeSchedulePlanRepo {
+	return &fakeSchedulePlanRepo{tasks: tasks, plans: make(map[uuid.UUID]domain.SchedulePlan)}
+}
+
+func (f *fakeSchedulePlanRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	plan, ok := f.plans[id]
+	if !ok || plan.OrgID != orgID {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	return plan, nil
+}
+
+func (f *fakeSchedulePlanRepo) Create(_ context.Context, plan domain.SchedulePlan) (domain.SchedulePlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.plans[plan.ID] = plan
+	return plan, nil
+}
+
+func (f *fakeSchedulePlanRepo) List(_ context.Context, filter ports.SchedulePlanFilter) ([]domain.SchedulePlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.SchedulePlan
+	for _, plan := range f.plans {
+		if filter.OrgID != nil && plan.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.State != nil && plan.State != *filter.State {
+			continue
+		}
+		plan.Items = nil
+		out = append(out, plan)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeSchedulePlanRepo) Discard(_ context.Context, orgID, id uuid.UUID, now time.Time) (domain.SchedulePlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	plan, ok := f.plans[id]
+	if !ok || plan.OrgID != orgID {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	if plan.State != domain.SchedulePlanProposed {
+		return domain.SchedulePlan{}, domain.NewConflictError("schedule plan is no longer proposed")
+	}
+	plan.State = domain.SchedulePlanDiscarded
+	plan.UpdatedAt = now
+	f.plans[id] = plan
+	return plan, nil
+}
+
+func (f *fakeSchedulePlanRepo) Apply(_ context.Context, plan domain.SchedulePlan, _ uuid.UUID, now time.Time) (domain.SchedulePlan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks.mu.Lock()
+	defer f.tasks.mu.Unlock()
+	stored, ok := f.plans[plan.ID]
+	if !ok || stored.OrgID != plan.OrgID {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	if stored.State != domain.SchedulePlanProposed {
+		return domain.SchedulePlan{}, domain.NewConflictError("schedule plan is no longer proposed")
+	}
+	updated := make(map[uuid.UUID]domain.MaintenanceTask, len(plan.Items))
+	for _, item := range plan.Items {
+		if !item.Placed() {
+			continue
+		}
+		task, ok := f.tasks.tasks[item.TaskID]
+		if !ok || task.DeletedAt != nil || task.State != domain.TaskStateScheduled || !task.UpdatedAt.Equal(item.TaskUpdatedAt) {
+			return domain.SchedulePlan{}, domain.NewConflictError("task changed since the plan was made")
+		}
+		task.StartTime = *item.StartTime
+		task.EndTime = *item.EndTime
+		task.BayID = item.BayID
+		task.BaySlot = item.BaySlot
+		task.AssignedMechanicID = item.MechanicID
+		task.UpdatedAt = now
+		updated[task.ID] = task
+	}
+	for id, task := range updated {
+		f.tasks.tasks[id] = task
+	}
+	stored.State = domain.SchedulePlanApplied
+	stored.AppliedAt = &now
+	stored.UpdatedAt = now
+	f.plans[plan.ID] = stored
+	return stored, nil
+}
+
+type fakeScheduleChangeRepo struct {
+	mu     sync.Mutex
+	tasks  *fakeTaskRepo
+	events []domain.ScheduleChangeEvent
+}
+
+func newFakeScheduleChangeRepo(tasks *fakeTaskRepo) *fakeScheduleChangeRepo {
+	return &fakeScheduleChangeRepo{tasks: tasks}
+}
+
+func (f *fakeScheduleChangeRepo) Create(_ context.Context, event domain.ScheduleChangeEvent) (domain.ScheduleChangeEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return event, nil
+}
+
+func (f *fakeScheduleChangeRepo) Reschedule(_ context.Context, event domain.ScheduleChangeEvent, tasks []domain.MaintenanceTask, now time.Time) (domain.ScheduleChangeEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks.mu.Lock()
+	defer f.tasks.mu.Unlock()
+	updated := make(map[uuid.UUID]domain.MaintenanceTask, len(tasks))
+	for _, task := range tasks {
+		stored, ok := f.tasks.tasks[task.ID]
+		if !ok || stored.DeletedAt != nil || !stored.IsActive() || !stored.UpdatedAt.Equal(task.UpdatedAt) {
+			return domain.ScheduleChangeEvent{}, domain.NewConflictError("task changed during rescheduling")
+		}
+		stored.StartTime = task.StartTime
+		stored.EndTime = task.EndTime
+		stored.BaySlot = task.BaySlot
+		stored.UpdatedAt = now
+		updated[task.ID] = stored
+	}
+	for id, task := range updated {
+		f.tasks.tasks[id] = task
+	}
+	f.events = append(f.events, event)
+	return event, nil
+}
+
+func (f *fakeScheduleChangeRepo) Preempt(_ context.Context, change ports.PreemptionChange, now time.Time) (domain.MaintenanceTask, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks.mu.Lock()
+	defer f.tasks.mu.Unlock()
+	updated := make(map[uuid.UUID]domain.MaintenanceTask, len(change.Moved)+len(change.Released))
+	for _, task := range change.Moved {
+		stored, ok := f.tasks.tasks[task.ID]
+		if !ok || stored.DeletedAt != nil || !stored.IsActive() || !stored.UpdatedAt.Equal(task.UpdatedAt) {
+			return domain.MaintenanceTask{}, domain.NewConflictError("task changed during preemption")
+		}
+		stored.StartTime = task.StartTime
+		stored.EndTime = task.EndTime
+		stored.BaySlot = task.BaySlot
+		stored.UpdatedAt = now
+		updated[task.ID] = stored
+	}
+	for _, task := range change.Released {
+		stored, ok := f.tasks.tasks[task.ID]
+		if !ok || stored.DeletedAt != nil || stored.State != domain.TaskStateScheduled || !stored.UpdatedAt.Equal(task.UpdatedAt) {
+			return domain.MaintenanceTask{}, domain.NewConflictError("task changed during preemption")
+		}
+		stored.AssignedMechanicID = nil
+		stored.UpdatedAt = now
+		updated[task.ID] = stored
+	}
+	for id, task := range updated {
+		f.tasks.tasks[id] = task
+	}
+	f.tasks.tasks[change.Task.ID] = change.Task
+	f.events = append(f.events, change.Events...)
+	return change.Task, nil
+}
+
+func (f *fakeScheduleChangeRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.ScheduleChangeEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.ScheduleChangeEvent
+	for _, event := range f.events {
+		if event.OrgID == orgID && event.TaskID == taskID {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+type fakeRescheduleOptionRepo struct {
+	mu           sync.Mutex
+	tasks        *fakeTaskRepo
+	reservations *fakePartReservationRepo
+	changes      *fakeScheduleChangeRepo
+	options      map[uuid.UUID]domain.RescheduleOption
+}
+
+func newFakeRescheduleOptionRepo(tasks *fakeTaskRepo, reservations *fakePartReservationRepo, changes *fakeScheduleChangeRepo) *fakeRescheduleOptionRepo {
+	return &fakeRescheduleOptionRepo{tasks: tasks, reservations: reservations, changes: changes, options: make(map[uuid.UUID]domain.RescheduleOption)}
+}
+
+func (f *fakeRescheduleOptionRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.RescheduleOption, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	option, ok := f.options[id]
+	if !ok || option.OrgID != orgID {
+		return domain.RescheduleOption{}, domain.ErrNotFound
+	}
+	return option, nil
+}
+
+func (f *fakeRescheduleOptionRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID, state *domain.RescheduleOptionState) ([]domain.RescheduleOption, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.RescheduleOption
+	for _, option := range f.options {
+		if option.OrgID == orgID && option.TaskID == taskID && (state == nil || option.State == *state) {
+			out = append(out, option)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].Rank < out[j].Rank
+	})
+	return out, nil
+}
+
+func (f *fakeRescheduleOptionRepo) Replace(_ context.Context, orgID, taskID uuid.UUID, options []domain.RescheduleOption, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.discardLocked(orgID, taskID, now)
+	for _, option := range options {
+		f.options[option.ID] = option
+	}
+	return nil
+}
+
+func (f *fakeRescheduleOptionRepo) Apply(_ context.Context, option domain.RescheduleOption, change ports.RescheduleOptionChange, now time.Time) (domain.ScheduleChangeEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks.mu.Lock()
+	defer f.tasks.mu.Unlock()
+	f.reservations.mu.Lock()
+	defer f.reservations.mu.Unlock()
+	stored, ok := f.options[option.ID]
+	if !ok || stored.OrgID != option.OrgID {
+		return domain.ScheduleChangeEvent{}, domain.ErrNotFound
+	}
+	if stored.State != domain.RescheduleOptionProposed {
+		return domain.ScheduleChangeEvent{}, domain.NewConflictError("reschedule option is no longer proposed")
+	}
+	updated := make(map[uuid.UUID]domain.MaintenanceTask, len(change.Moved)+1)
+	for _, task := range change.Moved {
+		current, ok := f.tasks.tasks[task.ID]
+		if !ok || current.DeletedAt != nil || !current.IsActive() || !current.UpdatedAt.Equal(task.UpdatedAt) {
+			return domain.ScheduleChangeEvent{}, domain.NewConflictError("task changed during rescheduling")
+		}
+		current.StartTime = task.StartTime
+		current.EndTime = task.EndTime
+		current.BaySlot = task.BaySlot
+		current.UpdatedAt = now
+		updated[task.ID] = current
+	}
+	var reservation *domain.PartReservation
+	if option.ReservationID != nil && (change.Continuation != nil || change.Substitute != nil) {
+		held, ok := f.reservations.reservations[*option.ReservationID]
+		if !ok || held.State != domain.ReservationReserved {
+			return domain.ScheduleChangeEvent{}, domain.NewConflictError("reservation is no longer held")
+		}
+		reservation = &held
+	}
+	if change.Continuation != nil {
+		updated[change.Continuation.ID] = *change.Continuation
+		if reservation != nil {
+			reservation.TaskID = change.Continuation.ID
+		}
+	}
+	if change.Substitute != nil && reservation != nil {
+		reservation.State = domain.ReservationReleased
+	}
+	for id, task := range updated {
+		f.tasks.tasks[id] = task
+	}
+	if reservation != nil {
+		reservation.UpdatedAt = now
+		f.reservations.reservations[reservation.ID] = *reservation
+	}
+	if change.Substitute != nil {
+		f.reservations.reservations[change.Substitute.ID] = *change.Substitute
+	}
+	f.discardLocked(option.OrgID, option.TaskID, now)
+	eventID := change.Event.ID
+	stored.State = domain.RescheduleOptionApplied
+	stored.ScheduleChangeID = &eventID
+	stored.AppliedAt = &now
+	stored.UpdatedAt = now
+	f.options[stored.ID] = stored
+
+	f.changes.mu.Lock()
+	defer f.changes.mu.Unlock()
+	f.changes.events = append(f.changes.events, change.Event)
+	return change.Event, nil
+}
+
+func (f *fakeRescheduleOptionRepo) discardLocked(orgID, taskID uuid.UUID, now time.Time) {
+	for id, option := range f.options {
+		if option.OrgID == orgID && option.TaskID == taskID && option.State == domain.RescheduleOptionProposed {
+			option.State = domain.RescheduleOptionDiscarded
+			option.UpdatedAt = now
+			f.options[id] = option
+		}
+	}
+}
+
+type fakeShiftPatternRepo struct {
+	mu       sync.Mutex
+	patterns map[uuid.UUID]domain.ShiftPattern
+}
+
+func newFakeShiftPatternRepo() *fakeShiftPatternRepo {
+	return &fakeShiftPatternRepo{patterns: make(map[uuid.UUID]domain.ShiftPattern)}
+}
+
+func (f *fakeShiftPatternRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.ShiftPattern, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pattern, ok := f.patterns[id]
+	if !ok || pattern.OrgID != orgID || pattern.DeletedAt != nil {
+		return domain.ShiftPattern{}, domain.ErrNotFound
+	}
+	return pattern, nil
+}
+
+func (f *fakeShiftPatternRepo) Create(_ context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.patterns[pattern.ID] = pattern
+	return pattern, nil
+}
+
+func (f *fakeShiftPatternRepo) Update(_ context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.patterns[pattern.ID]; !ok {
+		return domain.ShiftPattern{}, domain.ErrNotFound
+	}
+	f.patterns[pattern.ID] = pattern
+	return pattern, nil
+}
+
+func (f *fakeShiftPatternRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pattern, ok := f.patterns[id]
+	if !ok || pattern.OrgID != orgID || pattern.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	pattern.DeletedAt = &at
+	f.patterns[id] = pattern
+	return nil
+}
+
+func (f *fakeShiftPatternRepo) List(_ context.Context, filter ports.ShiftPatternFilter) ([]domain.ShiftPattern, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.ShiftPattern
+	for _, pattern := range f.patterns {
+		if pattern.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && pattern.OrgID != *filter.OrgID {
+			continue
+		}
+		out = append(out, pattern)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeRosterAssignmentRepo struct {
+	mu          sync.Mutex
+	assignments map[uuid.UUID]domain.RosterAssignment
+}
+
+func newFakeRosterAssignmentRepo() *fakeRosterAssignmentRepo {
+	return &fakeRosterAssignmentRepo{assignments: make(map[uuid.UUID]domain.RosterAssignment)}
+}
+
+func (f *fakeRosterAssignmentRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.RosterAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assignment, ok := f.assignments[id]
+	if !ok || assignment.OrgID != orgID {
+		return domain.RosterAssignment{}, domain.ErrNotFound
+	}
+	return assignment, nil
+}
+
+func (f *fakeRosterAssignmentRepo) Create(_ context.Context, assignment domain.RosterAssignment) (domain.RosterAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.assignments[assignment.ID] = assignment
+	return assignment, nil
+}
+
+func (f *fakeRosterAssignmentRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assignment, ok := f.assignments[id]
+	if !ok || assignment.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.assignments, id)
+	return nil
+}
+
+func (f *fakeRosterAssignmentRepo) List(_ context.Context, filter ports.RosterAssignmentFilter) ([]domain.RosterAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.RosterAssignment
+	for _, assignment := range f.assignments {
+		if filter.OrgID != nil && assignment.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.MechanicID != nil && assignment.MechanicID != *filter.MechanicID {
+			continue
+		}
+		if filter.StationID != nil && (assignment.StationID == nil || *assignment.StationID != *filter.StationID) {
+			continue
+		}
+		if filter.ActiveFrom != nil && filter.ActiveTo != nil && !assignment.Overlaps(*filter.ActiveFrom, *filter.ActiveTo) {
+			continue
+		}
+		out = append(out, assignment)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EffectiveFrom.Before(out[j].EffectiveFrom) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+type fakeMechanicAbsenceRepo struct {
+	mu       sync.Mutex
+	absences map[uuid.UUID]domain.MechanicAbsence
+}
+
+func newFakeMechanicAbsenceRepo() *fakeMechanicAbsenceRepo {
+	return &fakeMechanicAbsenceRepo{absences: make(map[uuid.UUID]domain.MechanicAbsence)}
+}
+
+func (f *fakeMechanicAbsenceRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.MechanicAbsence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	absence, ok := f.absences[id]
+	if !ok || absence.OrgID != orgID {
+		return domain.MechanicAbsence{}, domain.ErrNotFound
+	}
+	return absence, nil
+}
+
+func (f *fakeMechanicAbsenceRepo) Create(_ context.Context, absence domain.MechanicAbsence) (domain.MechanicAbsence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.absences[absence.ID] = absence
+	return absence, nil
+}
+
+func (f *fakeMechanicAbsenceRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	absence, ok := f.absences[id]
+	if !ok || absence.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.absences, id)
+	return nil
+}
+
+func (f *fakeMechanicAbsenceRepo) ListOverlapping(_ context.Context, orgID uuid.UUID, mechanicID *uuid.UUID, from, to time.Time) ([]domain.MechanicAbsence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.MechanicAbsence
+	for _, absence := range f.absences {
+		if absence.OrgID != orgID || (mechanicID != nil && absence.MechanicID != *mechanicID) {
+			continue
+		}
+		if !absence.EndTime.After(from) || !absence.StartTime.Before(to) {
+			continue
+		}
+		out = append(out, absence)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartTime.Before(out[j].StartTime) })
+	return out, nil
+}
+
+type fakePlannedFlightRepo struct {
+	mu      sync.Mutex
+	flights map[uuid.UUID]domain.PlannedFlight
+}
+
+func newFakePlannedFlightRepo() *fakePlannedFlightRepo {
+	return &fakePlannedFlightRepo{flights: make(map[uuid.UUID]domain.PlannedFlight)}
+}
+
+func (f *fakePlannedFlightRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flight, ok := f.flights[id]
+	if !ok || flight.OrgID != orgID {
+		return domain.PlannedFlight{}, domain.ErrNotFound
+	}
+	return flight, nil
+}
+
+func (f *fakePlannedFlightRepo) Upsert(_ context.Context, flight domain.PlannedFlight) (domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, existing := range f.flights {
+		if existing.OrgID == flight.OrgID && existing.AircraftID == flight.AircraftID && existing.FlightNumber == flight.FlightNumber && existing.DepartureTime.Equal(flight.DepartureTime) {
+			flight.ID = id
+			flight.CreatedAt = existing.CreatedAt
+			continue
+		}
+		if existing.AircraftID == flight.AircraftID && flight.Status == domain.FlightScheduled && existing.Overlaps(flight.DepartureTime, flight.ArrivalTime) {
+			return domain.PlannedFlight{}, domain.NewCapacityConflict(domain.CapacityConflictFlightOverlap, "aircraft already has a flight planned in this window")
+		}
+	}
+	f.flights[flight.ID] = flight
+	return flight, nil
+}
+
+func (f *fakePlannedFlightRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flight, ok := f.flights[id]
+	if !ok || flight.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.flights, id)
+	return nil
+}
+
+func (f *fakePlannedFlightRepo) List(_ context.Context, filter ports.PlannedFlightFilter) ([]domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	aircraft := make(map[uuid.UUID]bool, len(filter.AircraftIDs))
+	for _, id := range filter.AircraftIDs {
+		aircraft[id] = true
+	}
+	var out []domain.PlannedFlight
+	for _, flight := range f.flights {
+		if filter.OrgID != nil && flight.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftIDs != nil && !aircraft[flight.AircraftID] {
+			continue
+		}
+		if filter.Station != "" && !strings.EqualFold(flight.Origin, filter.Station) && !strings.EqualFold(flight.Destination, filter.Station) {
+			continue
+		}
+		if filter.Status != nil && flight.Status != *filter.Status {
+			continue
+		}
+		if filter.From != nil && !flight.ArrivalTime.After(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !flight.DepartureTime.Before(*filter.To) {
+			continue
+		}
+		out = append(out, flight)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DepartureTime.Before(out[j].DepartureTime) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakePlannedFlightRepo) ListPrevious(_ context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, at time.Time) ([]domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	aircraft := make(map[uuid.UUID]bool, len(aircraftIDs))
+	for _, id := range aircraftIDs {
+		aircraft[id] = true
+	}
+	latest := make(map[uuid.UUID]domain.PlannedFlight)
+	for _, flight := range f.flights {
+		if flight.OrgID != orgID || flight.Status != domain.FlightScheduled || flight.ArrivalTime.After(at) {
+			continue
+		}
+		if aircraftIDs != nil && !aircraft[flight.AircraftID] {
+			continue
+		}
+		if current, ok := latest[flight.AircraftID]; !ok || flight.ArrivalTime.After(current.ArrivalTime) {
+			latest[flight.AircraftID] = flight
+		}
+	}
+	out := make([]domain.PlannedFlight, 0, len(latest))
+	for _, flight := range latest {
+		out = append(out, flight)
+	}
+	return out, nil
+}
+
+type fakeTaskCardRepo struct {
+	mu    sync.Mutex
+	cards map[uuid.UUID]domain.TaskCard
+}
+
+func newFakeTaskCardRepo() *fakeTaskCardRepo {
+	return &fakeTaskCardRepo{cards: make(map[uuid.UUID]domain.TaskCard)}
+}
+
+func (f *fakeTaskCardRepo) Create(_ context.Context, card domain.TaskCard) (domain.TaskCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	card.Steps = slices.Clone(card.Steps)
+	f.cards[card.ID] = card
+	return card, nil
+}
+
+func (f *fakeTaskCardRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.TaskCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	card, ok := f.cards[id]
+	if !ok || card.OrgID != orgID {
+		return domain.TaskCard{}, domain.ErrNotFound
+	}
+	card.Steps = slices.Clone(card.Steps)
+	return card, nil
+}
+
+func (f *fakeTaskCardRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.TaskCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.TaskCard
+	for _, card := range f.cards {
+		if card.OrgID == orgID && card.TaskID == taskID {
+			card.Steps = slices.Clone(card.Steps)
+			out = append(out, card)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (f *fakeTaskCardRepo) PerformStep(_ context.Context, orgID, stepID, userID uuid.UUID, value *float64, at time.Time) (domain.TaskCardStep, error) {
+	return f.updateStep(orgID, stepID, func(step *domain.TaskCardStep) error {
+		if step.Performed() {
+			return domain.NewConflictError("step already performed")
+		}
+		step.MeasuredValue = value
+		step.PerformedBy = &userID
+		step.PerformedAt = &at
+		return nil
+	})
+}
+
+func (f *fakeTaskCardRepo) InspectStep(_ context.Context, orgID, stepID, userID uuid.UUID, at time.Time) (domain.TaskCardStep, error) {
+	return f.updateStep(orgID, stepID, func(step *domain.TaskCardStep) error {
+		if !step.RequiredInspection || !step.Performed() || step.Inspected() || *step.PerformedBy == userID {
+			return domain.NewConflictError("step cannot be inspected")
+		}
+		step.InspectedBy = &userID
+		step.InspectedAt = &at
+		return nil
+	})
+}
+
+func (f *fakeTaskCardRepo) updateStep(orgID, stepID uuid.UUID, update func(*domain.TaskCardStep) error) (domain.TaskCardStep, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, card := range f.cards {
+		if card.OrgID != orgID {
+			continue
+		}
+		for i := range card.Steps {
+			if card.Steps[i].ID != stepID {
+				continue
+			}
+			if err := update(&card.Steps[i]); err != nil {
+				return domain.TaskCardStep{}, err
+			}
+			f.cards[id] = card
+			return card.Steps[i], nil
+		}
+	}
+	return domain.TaskCardStep{}, domain.ErrNotFound
+}
+
+// fakeAuthorizationRepo answers the requirement, certification, skill and
+// type rating lookups of qualification checks; the embedded interface is
+// left nil.
+type fakeAuthorizationRepo struct {
+	ports.CertificationRepository
+	requirements []domain.TaskSkillRequirement
+	certs        map[uuid.UUID][]domain.EmployeeCertification
+	recency      []domain.EmployeeRecencyLog
+}
+
+func (f *fakeAuthorizationRepo) ListRequirements(_ context.Context, orgID uuid.UUID, taskType domain.TaskType, _ *uuid.UUID) ([]domain.TaskSkillRequirement, error) {
+	var out []domain.TaskSkillRequirement
+	for _, req := range f.requirements {
+		if req.OrgID == orgID && req.TaskType == taskType {
+			out = append(out, req)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAuthorizationRepo) ListCertsByUser(_ context.Context, _ uuid.UUID, userID uuid.UUID) ([]domain.EmployeeCertification, error) {
+	return f.certs[userID], nil
+}
+
+func (f *fakeAuthorizationRepo) ListSkillsByUser(context.Context, uuid.UUID, uuid.UUID) ([]domain.EmployeeSkill, error) {
+	return nil, nil
+}
+
+func (f *fakeAuthorizationRepo) GetCertTypeByID(_ context.Context, id uuid.UUID) (domain.CertificationType, error) {
+	return domain.CertificationType{ID: id}, nil
+}
+
+func (f *fakeAuthorizationRepo) HasTypeRating(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeAuthorizationRepo) LogRecency(_ context.Context, entry domain.EmployeeRecencyLog) error {
+	f.recency = append(f.recency, entry)
+	return nil
+}
+
+func (f *fakeAuthorizationRepo) GetRecencyHours(_ context.Context, orgID, userID, aircraftTypeID uuid.UUID, since time.Time) (float64, error) {
+	var hours float64
+	for _, entry := range f.recency {
+		if entry.OrgID == orgID && entry.UserID == userID && entry.AircraftTypeID == aircraftTypeID && !entry.WorkDate.Before(since) {
+			hours += entry.HoursLogged
+		}
+	}
+	return hours, nil
+}
+
+type fakeLaborRepo struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]domain.LaborEntry
+}
+
+func newFakeLaborRepo() *fakeLaborRepo {
+	return &fakeLaborRepo{entries: make(map[uuid.UUID]domain.LaborEntry)}
+}
+
+func (f *fakeLaborRepo) Create(_ context.Context, entry domain.LaborEntry) (domain.LaborEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, other := range f.entries {
+		if other.OrgID == entry.OrgID && other.MechanicID == entry.MechanicID && other.Overlaps(entry.StartedAt, entry.EndedAt) {
+			return domain.LaborEntry{}, domain.NewCapacityConflict(domain.CapacityConflictLaborOverlap, "mechanic already has labor booked in this time")
+		}
+	}
+	f.entries[entry.ID] = entry
+	return entry, nil
+}
+
+func (f *fakeLaborRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.LaborEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[id]
+	if !ok || entry.OrgID != orgID {
+		return domain.LaborEntry{}, domain.ErrNotFound
+	}
+	return entry, nil
+}
+
+func (f *fakeLaborRepo) ClockOut(_ context.Context, orgID, id uuid.UUID, endedAt time.Time) (domain.LaborEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[id]
+	if !ok || entry.OrgID != orgID {
+		return domain.LaborEntry{}, domain.ErrNotFound
+	}
+	if !entry.Open() {
+		return domain.LaborEntry{}, domain.NewConflictError("labor entry already clocked out")
+	}
+	entry.EndedAt = &endedAt
+	entry.UpdatedAt = endedAt
+	f.entries[id] = entry
+	return entry, nil
+}
+
+func (f *fakeLaborRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[id]
+	if !ok || entry.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.entries, id)
+	return nil
+}
+
+func (f *fakeLaborRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.LaborEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.LaborEntry
+	for _, entry := range f.entries {
+		if entry.OrgID == orgID && entry.TaskID == taskID {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out, nil
+}
+
+func (f *fakeLaborRepo) ListOverlapping(_ context.Context, orgID, mechanicID uuid.UUID, start time.Time, end *time.Time) ([]domain.LaborEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.LaborEntry
+	for _, entry := range f.entries {
+		if entry.OrgID == orgID && entry.MechanicID == mechanicID && entry.Overlaps(start, end) {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out, nil
+}
+
+type fakeDefectRepo struct {
+	mu         sync.Mutex
+	defects    map[uuid.UUID]domain.Defect
+	extensions []domain.DeferralExtension
+}
+
+func newFakeDefectRepo() *fakeDefectRepo {
+	return &fakeDefectRepo{defects: make(map[uuid.UUID]domain.Defect)}
+}
+
+func (f *fakeDefectRepo) Create(_ context.Context, defect domain.Defect) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.defects[defect.ID] = defect
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defect, ok := f.defects[id]
+	if !ok || defect.OrgID != orgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) List(_ context.Context, filter ports.DefectFilter) ([]domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Defect
+	for _, defect := range f.defects {
+		if filter.OrgID != nil && defect.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && defect.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.State != nil && defect.State != *filter.State {
+			continue
+		}
+		if filter.ExpiringBefore != nil && (defect.State != domain.DefectDeferred || !defect.ExpiresAt.Before(*filter.ExpiringBefore)) {
+			continue
+		}
+		out = append(out, defect)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ReportedAt.After(out[j].ReportedAt) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeDefectRepo) Update(_ context.Context, defect domain.Defect, from domain.DefectState) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.defects[defect.ID]
+	if !ok || existing.OrgID != defect.OrgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	if existing.State != from {
+		return domain.Defect{}, domain.NewConflictError("defect is no longer " + string(from))
+	}
+	f.defects[defect.ID] = defect
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) Extend(_ context.Context, extension domain.DeferralExtension) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defect, ok := f.defects[extension.DefectID]
+	if !ok || defect.OrgID != extension.OrgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	if defect.State != domain.DefectDeferred || !defect.ExpiresAt.Equal(extension.PreviousExpiresAt) {
+		return domain.Defect{}, domain.NewConflictError("deferral changed in the meantime")
+	}
+	expiresAt := extension.NewExpiresAt
+	defect.ExpiresAt = &expiresAt
+	defect.UpdatedAt = extension.CreatedAt
+	f.defects[defect.ID] = defect
+	f.extensions = append(f.extensions, extension)
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) ListExtensions(_ context.Context, orgID, defectID uuid.UUID) ([]domain.DeferralExtension, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.DeferralExtension
+	for _, ext := range f.extensions {
+		if ext.OrgID == orgID && ext.DefectID == defectID {
+			out = append(out, ext)
+		}
+	}
+	return out, nil
+}
+
+type fakeFindingRepo struct {
+	mu       sync.Mutex
+	findings map[uuid.UUID]domain.Finding
+	// tasks and dependencies, when set, receive the repair task and its
+	// dependency when a finding is converted.
+	tasks        *fakeTaskRepo
+	dependencies *fakeTaskDependencyRepo
+}
+
+func newFakeFindingRepo() *fakeFindingRepo {
+	return &fakeFindingRepo{findings: make(map[uuid.UUID]domain.Finding)}
+}
+
+func (f *fakeFindingRepo) Create(_ context.Context, finding domain.Finding) (domain.Finding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if finding.ComplianceItemID != nil {
+		for _, existing := range f.findings {
+			if existing.ComplianceItemID != nil && *existing.ComplianceItemID == *finding.ComplianceItemID {
+				return domain.Finding{}, domain.ErrConflict
+			}
+		}
+	}
+	f.findings[finding.ID] = finding
+	return finding, nil
+}
+
+func (f *fakeFindingRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Finding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	finding, ok := f.findings[id]
+	if !ok || finding.OrgID != orgID {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	return finding, nil
+}
+
+func (f *fakeFindingRepo) List(_ context.Context, filter ports.FindingFilter) ([]domain.Finding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Finding
+	for _, finding := range f.findings {
+		if filter.OrgID != nil && finding.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.TaskID != nil && finding.TaskID != *filter.TaskID {
+			continue
+		}
+		if filter.AircraftID != nil && finding.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.State != nil && finding.State != *filter.State {
+			continue
+		}
+		out = append(out, finding)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RaisedAt.After(out[j].RaisedAt) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeFindingRepo) Update(_ context.Context, finding domain.Finding, from domain.FindingState) (domain.Finding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.findings[finding.ID]
+	if !ok || existing.OrgID != finding.OrgID {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	if existing.State != from {
+		return domain.Finding{}, domain.NewConflictError("finding is no longer " + string(from))
+	}
+	f.findings[finding.ID] = finding
+	return finding, nil
+}
+
+func (f *fakeFindingRepo) Convert(ctx context.Context, finding domain.Finding, repair domain.MaintenanceTask, dependency domain.TaskDependency) (domain.Finding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.findings[finding.ID]
+	if !ok || existing.OrgID != finding.OrgID {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	if existing.State != domain.FindingOpen {
+		return domain.Finding{}, domain.NewConflictError("finding is no longer open")
+	}
+	if f.tasks == nil || f.dependencies == nil {
+		return domain.Finding{}, domain.NewValidationError("repair task repositories unavailable")
+	}
+	if _, err := f.tasks.Create(ctx, repair); err != nil {
+		return domain.Finding{}, err
+	}
+	if _, err := f.dependencies.Create(ctx, dependency); err != nil {
+		return domain.Finding{}, err
+	}
+	f.findings[finding.ID] = finding
+	return finding, nil
+}
+
+func (f *fakeFindingRepo) CountOpen(_ context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, finding := range f.findings {
+		if finding.OrgID != orgID || finding.State != domain.FindingOpen {
+			continue
+		}
+		for _, id := range taskIDs {
+			if finding.TaskID == id {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+type fakeTaskAssignmentRepo struct {
+	mu          sync.Mutex
+	assignments map[uuid.UUID]domain.TaskAssignment
+}
+
+func newFakeTaskAssignmentRepo() *fakeTaskAssignmentRepo {
+	return &fakeTaskAssignmentRepo{assignments: make(map[uuid.UUID]domain.TaskAssignment)}
+}
+
+func (f *fakeTaskAssignmentRepo) Create(_ context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, other := range f.assignments {
+		if other.TaskID == assignment.TaskID && other.MechanicID == assignment.MechanicID && other.Role == assignment.Role {
+			return domain.TaskAssignment{}, domain.ErrConflict
+		}
+	}
+	f.assignments[assignment.ID] = assignment
+	return assignment, nil
+}
+
+func (f *fakeTaskAssignmentRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.TaskAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assignment, ok := f.assignments[id]
+	if !ok || assignment.OrgID != orgID {
+		return domain.TaskAssignment{}, domain.ErrNotFound
+	}
+	return assignment, nil
+}
+
+func (f *fakeTaskAssignmentRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) (domain.Crew, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var crew domain.Crew
+	for _, assignment := range f.assignments {
+		if assignment.OrgID == orgID && assignment.TaskID == taskID {
+			crew = append(crew, assignment)
+		}
+	}
+	sort.Slice(crew, func(i, j int) bool { return crew[i].CreatedAt.Before(crew[j].CreatedAt) })
+	return crew, nil
+}
+
+func (f *fakeTaskAssignmentRepo) UpdatePlannedHours(_ context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.assignments[assignment.ID]
+	if !ok || existing.OrgID != assignment.OrgID {
+		return domain.TaskAssignment{}, domain.ErrNotFound
+	}
+	existing.PlannedHours = assignment.PlannedHours
+	existing.UpdatedAt = assignment.UpdatedAt
+	f.assignments[assignment.ID] = existing
+	return existing, nil
+}
+
+func (f *fakeTaskAssignmentRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assignment, ok := f.assignments[id]
+	if !ok || assignment.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.assignments, id)
+	return nil
+}
+
+func (f *fakeAuthorizationRepo) GetCertByID(_ context.Context, orgID, id uuid.UUID) (domain.EmployeeCertification, error) {
+	for _, certs := range f.certs {
+		for _, cert := range certs {
+			if cert.ID == id && cert.OrgID == orgID {
+				return cert, nil
+			}
+		}
+	}
+	return domain.EmployeeCertification{}, domain.ErrNotFound
+}
+
+type fakeOrgPolicyRepo struct {
+	policies map[uuid.UUID]domain.OrgPolicy
+}
+
+func (f *fakeOrgPolicyRepo) GetByOrgID(_ context.Context, orgID uuid.UUID) (domain.OrgPolicy, error) {
+	policy, ok := f.policies[orgID]
+	if !ok {
+		return domain.OrgPolicy{}, domain.ErrNotFound
+	}
+	return policy, nil
+}
+
+func (f *fakeOrgPolicyRepo) Upsert(_ context.Context, policy domain.OrgPolicy) (domain.OrgPolicy, error) {
+	f.policies[policy.OrgID] = policy
+	return policy, nil
+}
+
+type fakeBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newFakeBlobStore() *fakeBlobStore {
+	return &fakeBlobStore{blobs: make(map[string][]byte)}
+}
+
+func (f *fakeBlobStore) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[key] = data
+	return nil
+}
+
+func (f *fakeBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.blobs[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeBlobStore) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.blobs, key)
+	return nil
+}
+
+type fakeAttachmentRepo struct {
+	mu          sync.Mutex
+	attachments map[uuid.UUID]domain.Attachment
+}
+
+func newFakeAttachmentRepo() *fakeAttachmentRepo {
+	return &fakeAttachmentRepo{attachments: make(map[uuid.UUID]domain.Attachment)}
+}
+
+func (f *fakeAttachmentRepo) Create(_ context.Context, attachment domain.Attachment) (domain.Attachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attachments[attachment.ID] = attachment
+	return attachment, nil
+}
+
+func (f *fakeAttachmentRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Attachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attachment, ok := f.attachments[id]
+	if !ok || attachment.OrgID != orgID || attachment.DeletedAt != nil {
+		return domain.Attachment{}, domain.ErrNotFound
+	}
+	return attachment, nil
+}
+
+func (f *fakeAttachmentRepo) ListByEntity(_ context.Context, orgID uuid.UUID, entityType domain.AttachmentEntityType, entityID uuid.UUID) ([]domain.Attachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Attachment
+	for _, attachment := range f.attachments {
+		if attachment.OrgID == orgID && attachment.EntityType == entityType && attachment.EntityID == entityID && attachment.DeletedAt == nil {
+			out = append(out, attachment)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (f *fakeAttachmentRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attachment, ok := f.attachments[id]
+	if !ok || attachment.OrgID != orgID || attachment.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	attachment.DeletedAt = &at
+	attachment.UpdatedAt = at
+	f.attachments[id] = attachment
+	return nil
+}
+
+func (f *fakeAttachmentRepo) ListExpired(_ context.Context, orgID uuid.UUID, cutoff time.Time, limit int) ([]domain.Attachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Attachment
+	for _, attachment := range f.attachments {
+		if attachment.OrgID == orgID && attachment.DeletedAt != nil && attachment.DeletedAt.Before(cutoff) {
+			out = append(out, attachment)
+		}
+	}
+	return applyOffsetLimit(out, 0, limit), nil
+}
+
+func (f *fakeAttachmentRepo) Purge(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attachment, ok := f.attachments[id]
+	if !ok || attachment.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.attachments, id)
+	return nil
+}
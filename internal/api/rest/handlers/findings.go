@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type findingRaiseRequest struct {
+	OrgID       string   `json:"org_id" validate:"omitempty,uuid"`
+	Description string   `json:"description" validate:"required,max=4000"`
+	Zone        string   `json:"zone" validate:"max=100"`
+	ATAChapter  string   `json:"ata_chapter" validate:"max=20"`
+	PhotoURLs   []string `json:"photo_urls" validate:"max=20,dive,url,max=2048"`
+}
+
+type findingConvertRequest struct {
+	StartTime          string `json:"start_time" validate:"required,rfc3339"`
+	EndTime            string `json:"end_time" validate:"required,rfc3339"`
+	Priority           string `json:"priority" validate:"omitempty,oneof=routine urgent aog critical"`
+	BayID              string `json:"bay_id" validate:"omitempty,uuid"`
+	AssignedMechanicID string `json:"assigned_mechanic_id" validate:"omitempty,uuid"`
+	Notes              string `json:"notes" validate:"max=2000"`
+}
+
+type findingCloseRequest struct {
+	Notes string `json:"notes" validate:"required,max=2000"`
+}
+
+type findingResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	OrgID            uuid.UUID  `json:"org_id"`
+	TaskID           uuid.UUID  `json:"task_id"`
+	AircraftID       uuid.UUID  `json:"aircraft_id"`
+	ComplianceItemID *uuid.UUID `json:"compliance_item_id,omitempty"`
+	Description      string     `json:"description"`
+	Zone             string     `json:"zone,omitempty"`
+	ATAChapter       string     `json:"ata_chapter,omitempty"`
+	PhotoURLs        []string   `json:"photo_urls"`
+	State            string     `json:"state"`
+	RaisedBy         uuid.UUID  `json:"raised_by"`
+	RaisedAt         time.Time  `json:"raised_at"`
+	RepairTaskID     *uuid.UUID `json:"repair_task_id,omitempty"`
+	ConvertedBy      *uuid.UUID `json:"converted_by,omitempty"`
+	ConvertedAt      *time.Time `json:"converted_at,omitempty"`
+	ClosedBy         *uuid.UUID `json:"closed_by,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+	ResolutionNotes  string     `json:"resolution_notes,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func RaiseFinding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Findings == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	var req findingRaiseRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	finding, err := servicesReg.Findings.Raise(r.Context(), actor, services.FindingRaiseInput{
+		OrgID:       &orgID,
+		TaskID:      taskID,
+		Description: req.Description,
+		Zone:        req.Zone,
+		ATAChapter:  req.ATAChapter,
+		PhotoURLs:   req.PhotoURLs,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapFinding(finding))
+}
+
+func ListTaskFindings(w http.ResponseWriter, r *http.Request) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	listFindings(w, r, ports.FindingFilter{TaskID: &taskID})
+}
+
+func ListFindings(w http.ResponseWriter, r *http.Request) {
+	filter := ports.FindingFilter{}
+	if taskID := r.URL.Query().Get("task_id"); taskID != "" {
+		parsed, err := uuid.Parse(taskID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task_id")
+			return
+		}
+		filter.TaskID = &parsed
+	}
+	listFindings(w, r, filter)
+}
+
+// listFindings serves both finding lists, applying the query filters shared
+// by the two.
+func listFindings(w http.ResponseWriter, r *http.Request, filter ports.FindingFilter) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Findings == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	if actor.IsAdmin() && query.Get("org_id") != "" {
+		orgID, err := uuid.Parse(query.Get("org_id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+			return
+		}
+		filter.OrgID = &orgID
+	}
+	if aircraftID := query.Get("aircraft_id"); aircraftID != "" {
+		parsed, err := uuid.Parse(aircraftID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		filter.AircraftID = &parsed
+	}
+	if state := query.Get("state"); state != "" {
+		v := domain.FindingState(state)
+		filter.State = &v
+	}
+	if limit := query.Get("limit"); limit != "" {
+		v, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = v
+	}
+	if offset := query.Get("offset"); offset != "" {
+		v, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = v
+	}
+
+	findings, err := servicesReg.Findings.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]findingResponse, 0, len(findings))
+	for _, finding := range findings {
+		resp = append(resp, mapFinding(finding))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetFinding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Findings == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid finding id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	finding, err := servicesReg.Findings.Get(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapFinding(finding))
+}
+
+func ConvertFinding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Findings == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid finding id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req findingConvertRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid end_time")
+		return
+	}
+	input := services.FindingConvertInput{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Priority:  domain.TaskPriority(req.Priority),
+		Notes:     req.Notes,
+	}
+	if req.BayID != "" {
+		parsed, err := uuid.Parse(req.BayID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay_id")
+			return
+		}
+		input.BayID = &parsed
+	}
+	if req.AssignedMechanicID != "" {
+		parsed, err := uuid.Parse(req.AssignedMechanicID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid assigned_mechanic_id")
+			return
+		}
+		input.AssignedMechanicID = &parsed
+	}
+	finding, err := servicesReg.Findings.Convert(r.Context(), actor, orgID, id, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapFinding(finding))
+}
+
+func CloseFinding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Findings == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid finding id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var req findingCloseRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	finding, err := servicesReg.Findings.Close(r.Context(), actor, orgID, id, req.Notes)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapFinding(finding))
+}
+
+func mapFinding(finding domain.Finding) findingResponse {
+	photos := finding.PhotoURLs
+	if photos == nil {
+		photos = []string{}
+	}
+	return findingResponse{
+		ID:               finding.ID,
+		OrgID:            finding.OrgID,
+		TaskID:           finding.TaskID,
+		AircraftID:       finding.AircraftID,
+		ComplianceItemID: finding.ComplianceItemID,
+		Description:      finding.Description,
+		Zone:             finding.Zone,
+		ATAChapter:       finding.ATAChapter,
+		PhotoURLs:        photos,
+		State:            string(finding.State),
+		RaisedBy:         finding.RaisedBy,
+		RaisedAt:         finding.RaisedAt,
+		RepairTaskID:     finding.RepairTaskID,
+		ConvertedBy:      finding.ConvertedBy,
+		ConvertedAt:      finding.ConvertedAt,
+		ClosedBy:         finding.ClosedBy,
+		ClosedAt:         finding.ClosedAt,
+		ResolutionNotes:  finding.ResolutionNotes,
+		CreatedAt:        finding.CreatedAt,
+		UpdatedAt:        finding.UpdatedAt,
+	}
+}
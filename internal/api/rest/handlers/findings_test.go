@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestFindingBlocksPackageUntilConverted(t *testing.T) {
+	ctx := context.Background()
+	f := newWorkPackageFixture(t)
+	mechanic := uuid.New()
+	scheduler := uuid.New()
+
+	findingRepo := newFakeFindingRepo()
+	dependencyRepo := newFakeTaskDependencyRepo()
+	findingRepo.tasks = f.taskRepo
+	findingRepo.dependencies = dependencyRepo
+	aircraftRepo := newFakeAircraftRepo()
+	_, _ = aircraftRepo.Create(ctx, f.aircraft)
+	taskService := &services.TaskService{Tasks: f.taskRepo, Aircraft: aircraftRepo}
+	findingService := &services.FindingService{
+		Findings: findingRepo,
+		Tasks:    f.taskRepo,
+		Packages: f.packageRepo,
+		TaskSvc:  taskService,
+	}
+	f.registry.Findings = findingService
+	f.registry.WorkPackages.Findings = findingRepo
+	serve := func(req *http.Request, userID uuid.UUID, role domain.Role, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		req = withRouteParams(withUser(req, f.orgID, userID, role), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+		return rr
+	}
+	decodeFinding := func(rr *httptest.ResponseRecorder) findingResponse {
+		t.Helper()
+		var resp findingResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode finding: %v", err)
+		}
+		return resp
+	}
+
+	task := f.seedTask(t, nil, f.visitStart, domain.TaskStateScheduled)
+	task.WorkPackageID = &f.workPackage.ID
+	task, _ = f.taskRepo.Update(ctx, task)
+	taskParams := map[string]string{"id": task.ID.String()}
+	raise := map[string]any{
+		"description": "Corrosion on aft cargo door frame",
+		"zone":        "822",
+		"ata_chapter": "52-30",
+		"photo_urls":  []string{"https://photos.example.com/822-1.jpg"},
+	}
+
+	if rr := serve(newJSONRequest(t, http.MethodPost, "/api/v1/tasks/"+task.ID.String()+"/findings", raise), mechanic, domain.RoleMechanic, RaiseFinding, taskParams); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 raising on a scheduled task, got %d", rr.Code)
+	}
+	_, _ = f.taskRepo.UpdateState(ctx, f.orgID, task.ID, domain.TaskStateInProgress, "", time.Now().UTC())
+	rr := serve(newJSONRequest(t, http.MethodPost, "/api/v1/tasks/"+task.ID.String()+"/findings", raise), mechanic, domain.RoleMechanic, RaiseFinding, taskParams)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 raising a finding, got %d: %s", rr.Code, rr.Body.String())
+	}
+	finding := decodeFinding(rr)
+	if finding.State != "open" || finding.AircraftID != f.aircraft.ID || len(finding.PhotoURLs) != 1 {
+		t.Fatalf("unexpected finding: %+v", finding)
+	}
+
+	// With the parent task done, the open finding still holds the package.
+	_, _ = f.taskRepo.UpdateState(ctx, f.orgID, task.ID, domain.TaskStateCompleted, "done", time.Now().UTC())
+	transition := func() *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/state", map[string]any{"new_state": "completed"})
+		return serve(req, scheduler, domain.RoleScheduler, TransitionWorkPackageState, map[string]string{"id": f.workPackage.ID.String()})
+	}
+	if rr := serve(newJSONRequest(t, http.MethodPatch, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/state", map[string]any{"new_state": "in_progress"}), scheduler, domain.RoleScheduler, TransitionWorkPackageState, map[string]string{"id": f.workPackage.ID.String()}); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 starting the package, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := transition(); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 completing with an open finding, got %d", rr.Code)
+	}
+
+	findingParams := map[string]string{"id": finding.ID.String()}
+	convert := map[string]any{
+		"start_time": task.StartTime.Add(30 * time.Minute).Format(time.RFC3339),
+		"end_time":   task.StartTime.Add(2 * time.Hour).Format(time.RFC3339),
+		"priority":   "urgent",
+	}
+	path := "/api/v1/findings/" + finding.ID.String()
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/convert", convert), mechanic, domain.RoleMechanic, ConvertFinding, findingParams); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic converting, got %d", rr.Code)
+	}
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/convert", convert), scheduler, domain.RoleScheduler, ConvertFinding, findingParams); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 starting the repair before the task ends, got %d", rr.Code)
+	}
+	convert["start_time"] = task.EndTime.Add(time.Hour).Format(time.RFC3339)
+	convert["end_time"] = task.EndTime.Add(3 * time.Hour).Format(time.RFC3339)
+	rr = serve(newJSONRequest(t, http.MethodPost, path+"/convert", convert), scheduler, domain.RoleScheduler, ConvertFinding, findingParams)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 converting, got %d: %s", rr.Code, rr.Body.String())
+	}
+	finding = decodeFinding(rr)
+	if finding.State != "converted" || finding.RepairTaskID == nil {
+		t.Fatalf("expected a converted finding with a repair task, got %+v", finding)
+	}
+	repair, err := f.taskRepo.GetByID(ctx, f.orgID, *finding.RepairTaskID)
+	if err != nil {
+		t.Fatalf("load repair task: %v", err)
+	}
+	if repair.Type != domain.TaskTypeRepair || repair.AircraftID != f.aircraft.ID || repair.WorkPackageID == nil || *repair.WorkPackageID != f.workPackage.ID {
+		t.Fatalf("unexpected repair task: %+v", repair)
+	}
+	deps, _ := dependencyRepo.ListByTask(ctx, f.orgID, repair.ID)
+	if len(deps) != 1 || deps[0].DependsOnTaskID != task.ID || deps[0].DependencyType != domain.DependencyFinishToStart {
+		t.Fatalf("expected a finish-to-start dependency on the parent task, got %+v", deps)
+	}
+	taskCount := len(f.taskRepo.tasks)
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/convert", convert), scheduler, domain.RoleScheduler, ConvertFinding, findingParams); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 converting twice, got %d", rr.Code)
+	}
+	if len(f.taskRepo.tasks) != taskCount {
+		t.Fatalf("expected no second repair task, got %d tasks", len(f.taskRepo.tasks))
+	}
+	if rr := serve(newJSONRequest(t, http.MethodPost, path+"/close", map[string]any{"notes": "Repaired"}), scheduler, domain.RoleScheduler, CloseFinding, findingParams); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 closing a converted finding, got %d", rr.Code)
+	}
+
+	// The repair task now holds the package until it is done.
+	if rr := transition(); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 completing with the repair open, got %d", rr.Code)
+	}
+	_, _ = f.taskRepo.UpdateState(ctx, f.orgID, repair.ID, domain.TaskStateCompleted, "done", time.Now().UTC())
+	if rr := transition(); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 completing the package, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestComplianceFailRaisesFinding(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	mechanic := uuid.New()
+	taskRepo := newFakeTaskRepo()
+	complianceRepo := newFakeComplianceRepo()
+	findingRepo := newFakeFindingRepo()
+	task, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: uuid.New(),
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateInProgress,
+		StartTime:  time.Now().UTC(),
+		EndTime:    time.Now().Add(time.Hour).UTC(),
+	})
+	item := domain.ComplianceItem{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		TaskID:      task.ID,
+		Description: "Check brake wear pins",
+		Result:      domain.CompliancePending,
+	}
+	_ = complianceRepo.Create(ctx, item)
+	findingService := &services.FindingService{Findings: findingRepo, Tasks: taskRepo}
+	registry := middleware.ServiceRegistry{
+		Compliance: &services.ComplianceService{Compliance: complianceRepo, Findings: findingService},
+		Findings:   findingService,
+	}
+	fail := func() *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/compliance-items/"+item.ID.String(), map[string]any{
+			"description": item.Description,
+			"result":      "fail",
+		})
+		req = withRouteParams(withUser(req, orgID, mechanic, domain.RoleMechanic), map[string]string{"id": item.ID.String()})
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(http.HandlerFunc(UpdateComplianceItem)).ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Failing the item twice raises a single finding.
+	for i := 0; i < 2; i++ {
+		if rr := fail(); rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200 failing the item, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	req := withRouteParams(withUser(httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+task.ID.String()+"/findings", nil), orgID, mechanic, domain.RoleMechanic), map[string]string{"id": task.ID.String()})
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(ListTaskFindings)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 listing findings, got %d", rr.Code)
+	}
+	var findings []findingResponse
+	if err := json.NewDecoder(rr.Body).Decode(&findings); err != nil {
+		t.Fatalf("decode findings: %v", err)
+	}
+	if len(findings) != 1 || findings[0].ComplianceItemID == nil || *findings[0].ComplianceItemID != item.ID {
+		t.Fatalf("expected one finding for the failed item, got %+v", findings)
+	}
+}
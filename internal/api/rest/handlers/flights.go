@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type plannedFlightCreateRequest struct {
+	OrgID         string `json:"org_id" validate:"omitempty,uuid"`
+	FlightNumber  string `json:"flight_number" validate:"required,max=16"`
+	Origin        string `json:"origin" validate:"required,max=8"`
+	Destination   string `json:"destination" validate:"required,max=8"`
+	DepartureTime string `json:"departure_time" validate:"required,rfc3339"`
+	ArrivalTime   string `json:"arrival_time" validate:"required,rfc3339"`
+	Status        string `json:"status" validate:"omitempty,oneof=scheduled cancelled"`
+}
+
+type plannedFlightResponse struct {
+	ID            uuid.UUID           `json:"id"`
+	OrgID         uuid.UUID           `json:"org_id"`
+	AircraftID    uuid.UUID           `json:"aircraft_id"`
+	FlightNumber  string              `json:"flight_number"`
+	Origin        string              `json:"origin"`
+	Destination   string              `json:"destination"`
+	DepartureTime time.Time           `json:"departure_time"`
+	ArrivalTime   time.Time           `json:"arrival_time"`
+	Status        domain.FlightStatus `json:"status"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+type groundWindowResponse struct {
+	AircraftID        uuid.UUID  `json:"aircraft_id"`
+	Station           string     `json:"station,omitempty"`
+	Start             time.Time  `json:"start"`
+	End               time.Time  `json:"end"`
+	DurationMinutes   int        `json:"duration_minutes"`
+	ArrivalFlightID   *uuid.UUID `json:"arrival_flight_id,omitempty"`
+	DepartureFlightID *uuid.UUID `json:"departure_flight_id,omitempty"`
+}
+
+func CreatePlannedFlight(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Flights == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	var req plannedFlightCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	departure, err := time.Parse(time.RFC3339, req.DepartureTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid departure_time")
+		return
+	}
+	arrival, err := time.Parse(time.RFC3339, req.ArrivalTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid arrival_time")
+		return
+	}
+	flight, err := servicesReg.Flights.Record(r.Context(), actor, services.FlightRecordInput{
+		OrgID:         &orgID,
+		AircraftID:    aircraftID,
+		FlightNumber:  req.FlightNumber,
+		Origin:        req.Origin,
+		Destination:   req.Destination,
+		DepartureTime: departure,
+		ArrivalTime:   arrival,
+		Status:        domain.FlightStatus(req.Status),
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapPlannedFlight(flight))
+}
+
+func ListPlannedFlights(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Flights == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.PlannedFlightFilter{AircraftIDs: []uuid.UUID{aircraftID}}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if status := query.Get("status"); status != "" {
+		value := domain.FlightStatus(status)
+		if !value.IsValid() {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid status")
+			return
+		}
+		filter.Status = &value
+	}
+	if from := query.Get("from"); from != "" {
+		value, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+			return
+		}
+		filter.From = &value
+	}
+	if to := query.Get("to"); to != "" {
+		value, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+			return
+		}
+		filter.To = &value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	flights, err := servicesReg.Flights.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapPlannedFlights(flights))
+}
+
+func DeletePlannedFlight(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Flights == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	flightID, err := uuid.Parse(chi.URLParam(r, "flightId"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid flight id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	if err := servicesReg.Flights.Delete(r.Context(), actor, orgID, aircraftID, flightID); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func GetAircraftGroundWindows(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Flights == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	orgID, from, to, minDuration, ok := parseGroundWindowQuery(w, r, actor.OrgID, actor.IsAdmin())
+	if !ok {
+		return
+	}
+	windows, err := servicesReg.Flights.GroundWindows(r.Context(), actor, orgID, aircraftID, from, to, minDuration)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapGroundWindows(windows))
+}
+
+func GetStationGroundWindows(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Flights == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	stationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station id")
+		return
+	}
+	orgID, from, to, minDuration, ok := parseGroundWindowQuery(w, r, actor.OrgID, actor.IsAdmin())
+	if !ok {
+		return
+	}
+	windows, err := servicesReg.Flights.StationGroundWindows(r.Context(), actor, orgID, stationID, from, to, minDuration)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapGroundWindows(windows))
+}
+
+// parseGroundWindowQuery reads org_id, from, to and min_minutes, writing
+// the error response itself when one is invalid.
+func parseGroundWindowQuery(w http.ResponseWriter, r *http.Request, orgID uuid.UUID, isAdmin bool) (uuid.UUID, time.Time, time.Time, time.Duration, bool) {
+	query := r.URL.Query()
+	if isAdmin {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return uuid.Nil, time.Time{}, time.Time{}, 0, false
+			}
+			orgID = parsed
+		}
+	}
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return uuid.Nil, time.Time{}, time.Time{}, 0, false
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return uuid.Nil, time.Time{}, time.Time{}, 0, false
+	}
+	var minDuration time.Duration
+	if value := query.Get("min_minutes"); value != "" {
+		parsed, err := parseInt(value)
+		if err != nil || parsed < 0 {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid min_minutes")
+			return uuid.Nil, time.Time{}, time.Time{}, 0, false
+		}
+		minDuration = time.Duration(parsed) * time.Minute
+	}
+	return orgID, from, to, minDuration, true
+}
+
+func mapPlannedFlight(flight domain.PlannedFlight) plannedFlightResponse {
+	return plannedFlightResponse{
+		ID:            flight.ID,
+		OrgID:         flight.OrgID,
+		AircraftID:    flight.AircraftID,
+		FlightNumber:  flight.FlightNumber,
+		Origin:        flight.Origin,
+		Destination:   flight.Destination,
+		DepartureTime: flight.DepartureTime.UTC(),
+		ArrivalTime:   flight.ArrivalTime.UTC(),
+		Status:        flight.Status,
+		CreatedAt:     flight.CreatedAt,
+		UpdatedAt:     flight.UpdatedAt,
+	}
+}
+
+func mapPlannedFlights(flights []domain.PlannedFlight) []plannedFlightResponse {
+	resp := make([]plannedFlightResponse, 0, len(flights))
+	for _, flight := range flights {
+		resp = append(resp, mapPlannedFlight(flight))
+	}
+	return resp
+}
+
+func mapGroundWindow(window domain.GroundWindow) groundWindowResponse {
+	return groundWindowResponse{
+		AircraftID:        window.AircraftID,
+		Station:           window.Station,
+		Start:             window.Start.UTC(),
+		End:               window.End.UTC(),
+		DurationMinutes:   int(window.Duration() / time.Minute),
+		ArrivalFlightID:   window.ArrivalFlightID,
+		DepartureFlightID: window.DepartureFlightID,
+	}
+}
+
+func mapGroundWindows(windows []domain.GroundWindow) []groundWindowResponse {
+	resp := make([]groundWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		resp = append(resp, mapGroundWindow(window))
+	}
+	return resp
+}
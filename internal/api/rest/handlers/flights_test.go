@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type flightFixture struct {
+	orgID    uuid.UUID
+	aircraft domain.Aircraft
+	station  domain.Station
+	registry middleware.ServiceRegistry
+	day      time.Time
+}
+
+// newFlightFixture sets up one aircraft based at JFK and a JFK station.
+func newFlightFixture(t *testing.T) *flightFixture {
+	t.Helper()
+	orgID := uuid.New()
+	ctx := context.Background()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N100AM",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+	})
+	stationRepo := newFakeStationRepo()
+	station, _ := stationRepo.Create(ctx, domain.Station{ID: uuid.New(), OrgID: orgID, Code: "JFK", Name: "New York", Timezone: "UTC"})
+	flightService := &services.FlightScheduleService{
+		Flights:  newFakePlannedFlightRepo(),
+		Aircraft: aircraftRepo,
+		Stations: stationRepo,
+	}
+	taskRepo := newFakeTaskRepo()
+	return &flightFixture{
+		orgID:    orgID,
+		aircraft: aircraft,
+		station:  station,
+		registry: middleware.ServiceRegistry{
+			Tasks:   &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Flights: flightService},
+			Flights: flightService,
+			Scheduling: &services.SchedulingService{
+				Tasks:          taskRepo,
+				Dependencies:   newFakeTaskDependencyRepo(),
+				ScheduleEvents: newFakeScheduleChangeRepo(taskRepo),
+				Flights:        flightService,
+			},
+		},
+		day: time.Now().UTC().Add(48 * time.Hour).Truncate(24 * time.Hour),
+	}
+}
+
+func (f *flightFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *flightFixture) recordFlight(t *testing.T, number, origin, destination string, departure, arrival time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	body := map[string]any{
+		"flight_number":  number,
+		"origin":         origin,
+		"destination":    destination,
+		"departure_time": departure.Format(time.RFC3339),
+		"arrival_time":   arrival.Format(time.RFC3339),
+	}
+	return f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/aircraft/"+f.aircraft.ID.String()+"/flights", body), CreatePlannedFlight, map[string]string{"id": f.aircraft.ID.String()})
+}
+
+// planRoundTrip plans JFK-BOS 08:00-09:30 and BOS-JFK 14:00-15:30.
+func (f *flightFixture) planRoundTrip(t *testing.T) {
+	t.Helper()
+	if rr := f.recordFlight(t, "am100", "jfk", "bos", f.day.Add(8*time.Hour), f.day.Add(9*time.Hour+30*time.Minute)); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 for outbound flight, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := f.recordFlight(t, "AM101", "BOS", "JFK", f.day.Add(14*time.Hour), f.day.Add(15*time.Hour+30*time.Minute)); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 for return flight, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func (f *flightFixture) groundWindows(t *testing.T, path string, handler http.HandlerFunc, id uuid.UUID, minMinutes string) []groundWindowResponse {
+	t.Helper()
+	query := url.Values{}
+	query.Set("from", f.day.Format(time.RFC3339))
+	query.Set("to", f.day.Add(24*time.Hour).Format(time.RFC3339))
+	if minMinutes != "" {
+		query.Set("min_minutes", minMinutes)
+	}
+	req := httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil)
+	rr := f.serve(t, req, handler, map[string]string{"id": id.String()})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var windows []groundWindowResponse
+	if err := json.NewDecoder(rr.Body).Decode(&windows); err != nil {
+		t.Fatalf("decode ground windows: %v", err)
+	}
+	return windows
+}
+
+func TestAircraftGroundWindowsBetweenFlights(t *testing.T) {
+	f := newFlightFixture(t)
+	f.planRoundTrip(t)
+
+	rr := f.recordFlight(t, "AM102", "BOS", "ORD", f.day.Add(9*time.Hour), f.day.Add(11*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for an overlapping flight, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "flight_overlap" {
+		t.Fatalf("expected code flight_overlap, got %s", code)
+	}
+
+	path := "/api/v1/aircraft/" + f.aircraft.ID.String() + "/ground-windows"
+	windows := f.groundWindows(t, path, GetAircraftGroundWindows, f.aircraft.ID, "")
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 ground windows, got %+v", windows)
+	}
+	expected := []struct {
+		station    string
+		start, end time.Duration
+	}{
+		{"JFK", 0, 8 * time.Hour},
+		{"BOS", 9*time.Hour + 30*time.Minute, 14 * time.Hour},
+		{"JFK", 15*time.Hour + 30*time.Minute, 24 * time.Hour},
+	}
+	for i, want := range expected {
+		got := windows[i]
+		if got.Station != want.station || !got.Start.Equal(f.day.Add(want.start)) || !got.End.Equal(f.day.Add(want.end)) {
+			t.Fatalf("window %d: expected %s %s-%s, got %+v", i, want.station, want.start, want.end, got)
+		}
+	}
+	if windows[1].ArrivalFlightID == nil || windows[1].DepartureFlightID == nil {
+		t.Fatalf("expected the BOS window to name both flights, got %+v", windows[1])
+	}
+
+	windows = f.groundWindows(t, path, GetAircraftGroundWindows, f.aircraft.ID, "300")
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows of at least 5 hours, got %+v", windows)
+	}
+}
+
+func TestStationGroundWindowsKeepsStation(t *testing.T) {
+	f := newFlightFixture(t)
+	f.planRoundTrip(t)
+
+	windows := f.groundWindows(t, "/api/v1/stations/"+f.station.ID.String()+"/ground-windows", GetStationGroundWindows, f.station.ID, "")
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows at JFK, got %+v", windows)
+	}
+	for _, window := range windows {
+		if window.Station != "JFK" || window.AircraftID != f.aircraft.ID {
+			t.Fatalf("expected only JFK windows of the aircraft, got %+v", window)
+		}
+	}
+}
+
+func TestCreateTaskRejectsFlightOverlap(t *testing.T) {
+	f := newFlightFixture(t)
+	f.planRoundTrip(t)
+
+	body := map[string]any{
+		"aircraft_id": f.aircraft.ID.String(),
+		"type":        string(domain.TaskTypeInspection),
+		"start_time":  f.day.Add(7 * time.Hour).Format(time.RFC3339),
+		"end_time":    f.day.Add(10 * time.Hour).Format(time.RFC3339),
+	}
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), CreateTask, nil)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 over a planned flight, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "flight_overlap" {
+		t.Fatalf("expected code flight_overlap, got %s", code)
+	}
+
+	body["allow_flight_overlap"] = true
+	rr = f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), CreateTask, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 with allow_flight_overlap, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created taskResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode task: %v", err)
+	}
+	if len(created.FlightConflicts) != 1 || created.FlightConflicts[0].FlightNumber != "AM100" {
+		t.Fatalf("expected AM100 reported as a flight conflict, got %+v", created.FlightConflicts)
+	}
+
+	body = map[string]any{
+		"aircraft_id": f.aircraft.ID.String(),
+		"type":        string(domain.TaskTypeInspection),
+		"start_time":  f.day.Add(10 * time.Hour).Format(time.RFC3339),
+		"end_time":    f.day.Add(13 * time.Hour).Format(time.RFC3339),
+	}
+	rr = f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), CreateTask, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 within the BOS ground window, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRescheduleTaskRejectsFlightOverlap(t *testing.T) {
+	f := newFlightFixture(t)
+	f.planRoundTrip(t)
+	ctx := context.Background()
+	scheduling := f.registry.Scheduling
+	newTask := func(start, end time.Time) domain.MaintenanceTask {
+		task, _ := scheduling.Tasks.Create(ctx, domain.MaintenanceTask{
+			ID:         uuid.New(),
+			OrgID:      f.orgID,
+			AircraftID: f.aircraft.ID,
+			Type:       domain.TaskTypeInspection,
+			State:      domain.TaskStateScheduled,
+			StartTime:  start,
+			EndTime:    end,
+		})
+		return task
+	}
+	root := newTask(f.day.Add(10*time.Hour), f.day.Add(11*time.Hour))
+	dependent := newTask(f.day.Add(11*time.Hour), f.day.Add(12*time.Hour+30*time.Minute))
+	_, _ = scheduling.Dependencies.Create(ctx, domain.TaskDependency{
+		ID:              uuid.New(),
+		OrgID:           f.orgID,
+		TaskID:          dependent.ID,
+		DependsOnTaskID: root.ID,
+		DependencyType:  domain.DependencyFinishToStart,
+	})
+
+	// Moving the root to 11:00-13:00 pushes the dependent into AM101.
+	body := map[string]any{
+		"new_start_time": f.day.Add(11 * time.Hour).Format(time.RFC3339),
+		"new_end_time":   f.day.Add(13 * time.Hour).Format(time.RFC3339),
+		"reason":         "late parts",
+		"cascade":        true,
+	}
+	reschedule := func() *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks/"+root.ID.String()+"/reschedule", body)
+		return f.serve(t, req, RescheduleTask, map[string]string{"id": root.ID.String()})
+	}
+
+	body["dry_run"] = true
+	rr := reschedule()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for the preview, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var simulation rescheduleSimulationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&simulation); err != nil {
+		t.Fatalf("decode simulation: %v", err)
+	}
+	if len(simulation.CapacityViolations) != 1 || simulation.CapacityViolations[0].TaskID != dependent.ID || simulation.CapacityViolations[0].Kind != domain.CapacityConflictFlightOverlap {
+		t.Fatalf("expected a flight overlap for the dependent, got %+v", simulation.CapacityViolations)
+	}
+
+	body["dry_run"] = false
+	rr = reschedule()
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 over a planned flight, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if code := decodeErrorCode(t, rr); code != "flight_overlap" {
+		t.Fatalf("expected code flight_overlap, got %s", code)
+	}
+	if task, _ := scheduling.Tasks.GetByID(ctx, f.orgID, root.ID); !task.StartTime.Equal(root.StartTime) {
+		t.Fatalf("expected root to stay at %s, got %s", root.StartTime, task.StartTime)
+	}
+
+	body["allow_flight_overlap"] = true
+	rr = reschedule()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with allow_flight_overlap, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var event scheduleChangeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if len(event.FlightConflicts) != 1 || event.FlightConflicts[0].FlightNumber != "AM101" {
+		t.Fatalf("expected AM101 reported as a flight conflict, got %+v", event.FlightConflicts)
+	}
+}
@@ -0,0 +1,754 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type stationCreateRequest struct {
+	OrgID    string `json:"org_id" validate:"omitempty,uuid"`
+	Code     string `json:"code" validate:"required,max=16"`
+	Name     string `json:"name" validate:"required"`
+	Timezone string `json:"timezone"`
+}
+
+type stationUpdateRequest struct {
+	OrgID    string  `json:"org_id" validate:"omitempty,uuid"`
+	Name     *string `json:"name" validate:"omitempty,min=1"`
+	Timezone *string `json:"timezone" validate:"omitempty,min=1"`
+}
+
+type hangarBayCreateRequest struct {
+	OrgID         string `json:"org_id" validate:"omitempty,uuid"`
+	StationID     string `json:"station_id" validate:"required,uuid"`
+	Code          string `json:"code" validate:"required,max=16"`
+	Name          string `json:"name" validate:"required"`
+	Hangar        string `json:"hangar"`
+	CapacitySlots int    `json:"capacity_slots" validate:"required,min=1"`
+}
+
+type hangarBayUpdateRequest struct {
+	OrgID         string  `json:"org_id" validate:"omitempty,uuid"`
+	Name          *string `json:"name" validate:"omitempty,min=1"`
+	Hangar        *string `json:"hangar"`
+	CapacitySlots *int    `json:"capacity_slots" validate:"omitempty,min=1"`
+}
+
+type bayCapacityWindowRequest struct {
+	OrgID         string `json:"org_id" validate:"omitempty,uuid"`
+	StartTime     string `json:"start_time" validate:"required,rfc3339"`
+	EndTime       string `json:"end_time" validate:"required,rfc3339"`
+	CapacitySlots *int   `json:"capacity_slots" validate:"required,min=0"`
+	Reason        string `json:"reason"`
+}
+
+type stationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type hangarBayResponse struct {
+	ID            uuid.UUID `json:"id"`
+	OrgID         uuid.UUID `json:"org_id"`
+	StationID     uuid.UUID `json:"station_id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Hangar        string    `json:"hangar"`
+	CapacitySlots int       `json:"capacity_slots"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type bayCapacityWindowResponse struct {
+	ID            uuid.UUID `json:"id"`
+	OrgID         uuid.UUID `json:"org_id"`
+	BayID         uuid.UUID `json:"bay_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	CapacitySlots int       `json:"capacity_slots"`
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type capacityIntervalResponse struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"`
+	Occupied  int       `json:"occupied"`
+	FreeSlots []int     `json:"free_slots"`
+}
+
+type bayAvailabilityResponse struct {
+	Bay         hangarBayResponse          `json:"bay"`
+	FreeWindows []capacityIntervalResponse `json:"free_windows"`
+}
+
+func CreateStation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req stationCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	created, err := servicesReg.Capacity.CreateStation(r.Context(), actor, services.StationCreateInput{
+		OrgID:    &orgID,
+		Code:     req.Code,
+		Name:     req.Name,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapStation(created))
+}
+
+func ListStations(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.StationFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	stations, err := servicesReg.Capacity.ListStations(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]stationResponse, 0, len(stations))
+	for _, station := range stations {
+		resp = append(resp, mapStation(station))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetStation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	station, err := servicesReg.Capacity.GetStation(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapStation(station))
+}
+
+func UpdateStation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station id")
+		return
+	}
+	var req stationUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	if req.Name == nil && req.Timezone == nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "no changes provided")
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	updated, err := servicesReg.Capacity.UpdateStation(r.Context(), actor, orgID, id, services.StationUpdateInput{
+		Name:     req.Name,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapStation(updated))
+}
+
+func DeleteStation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Capacity.DeleteStation(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStationAvailability lists, per bay of the station, the windows between
+// from and to with at least min_slots free slots.
+func GetStationAvailability(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station id")
+		return
+	}
+	query := r.URL.Query()
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return
+	}
+	minSlots := 1
+	if value := query.Get("min_slots"); value != "" {
+		parsed, err := parseInt(value)
+		if err != nil || parsed < 1 {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid min_slots")
+			return
+		}
+		minSlots = parsed
+	}
+	availability, err := servicesReg.Capacity.FreeWindows(r.Context(), actor, orgID, id, from, to, minSlots)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]bayAvailabilityResponse, 0, len(availability))
+	for _, entry := range availability {
+		windows := make([]capacityIntervalResponse, 0, len(entry.Intervals))
+		for _, interval := range entry.Intervals {
+			windows = append(windows, capacityIntervalResponse{
+				Start:     interval.Start.UTC(),
+				End:       interval.End.UTC(),
+				Capacity:  interval.Capacity,
+				Occupied:  interval.Occupied,
+				FreeSlots: interval.FreeSlots,
+			})
+		}
+		resp = append(resp, bayAvailabilityResponse{
+			Bay:         mapHangarBay(entry.Bay),
+			FreeWindows: windows,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func CreateHangarBay(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req hangarBayCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	stationID, err := uuid.Parse(req.StationID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+		return
+	}
+	created, err := servicesReg.Capacity.CreateBay(r.Context(), actor, services.HangarBayCreateInput{
+		OrgID:         &orgID,
+		StationID:     stationID,
+		Code:          req.Code,
+		Name:          req.Name,
+		Hangar:        req.Hangar,
+		CapacitySlots: req.CapacitySlots,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapHangarBay(created))
+}
+
+func ListHangarBays(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.HangarBayFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if station := query.Get("station_id"); station != "" {
+		id, err := uuid.Parse(station)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+			return
+		}
+		filter.StationID = &id
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	bays, err := servicesReg.Capacity.ListBays(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]hangarBayResponse, 0, len(bays))
+	for _, bay := range bays {
+		resp = append(resp, mapHangarBay(bay))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetHangarBay(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	bay, err := servicesReg.Capacity.GetBay(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapHangarBay(bay))
+}
+
+func UpdateHangarBay(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	var req hangarBayUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	if req.Name == nil && req.Hangar == nil && req.CapacitySlots == nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "no changes provided")
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	updated, err := servicesReg.Capacity.UpdateBay(r.Context(), actor, orgID, id, services.HangarBayUpdateInput{
+		Name:          req.Name,
+		Hangar:        req.Hangar,
+		CapacitySlots: req.CapacitySlots,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapHangarBay(updated))
+}
+
+func DeleteHangarBay(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Capacity.DeleteBay(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func CreateBayCapacityWindow(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	bayID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	var req bayCapacityWindowRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid end_time")
+		return
+	}
+	created, err := servicesReg.Capacity.CreateCapacityWindow(r.Context(), actor, services.BayCapacityWindowCreateInput{
+		OrgID:         &orgID,
+		BayID:         bayID,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		CapacitySlots: *req.CapacitySlots,
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapBayCapacityWindow(created))
+}
+
+func ListBayCapacityWindows(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	bayID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	query := r.URL.Query()
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return
+	}
+	windows, err := servicesReg.Capacity.ListCapacityWindows(r.Context(), actor, orgID, bayID, from, to)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]bayCapacityWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		resp = append(resp, mapBayCapacityWindow(window))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func DeleteBayCapacityWindow(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Capacity == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	bayID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay id")
+		return
+	}
+	windowID, err := uuid.Parse(chi.URLParam(r, "windowId"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid capacity window id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Capacity.DeleteCapacityWindow(r.Context(), actor, orgID, bayID, windowID); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapStation(station domain.Station) stationResponse {
+	return stationResponse{
+		ID:        station.ID,
+		OrgID:     station.OrgID,
+		Code:      station.Code,
+		Name:      station.Name,
+		Timezone:  station.Timezone,
+		CreatedAt: station.CreatedAt,
+		UpdatedAt: station.UpdatedAt,
+	}
+}
+
+func mapHangarBay(bay domain.HangarBay) hangarBayResponse {
+	return hangarBayResponse{
+		ID:            bay.ID,
+		OrgID:         bay.OrgID,
+		StationID:     bay.StationID,
+		Code:          bay.Code,
+		Name:          bay.Name,
+		Hangar:        bay.Hangar,
+		CapacitySlots: bay.CapacitySlots,
+		CreatedAt:     bay.CreatedAt,
+		UpdatedAt:     bay.UpdatedAt,
+	}
+}
+
+func mapBayCapacityWindow(window domain.BayCapacityWindow) bayCapacityWindowResponse {
+	return bayCapacityWindowResponse{
+		ID:            window.ID,
+		OrgID:         window.OrgID,
+		BayID:         window.BayID,
+		StartTime:     window.StartTime.UTC(),
+		EndTime:       window.EndTime.UTC(),
+		CapacitySlots: window.CapacitySlots,
+		Reason:        window.Reason,
+		CreatedAt:     window.CreatedAt,
+	}
+}
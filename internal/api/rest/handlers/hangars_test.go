@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/api/rest/response"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type hangarFixture struct {
+	orgID    uuid.UUID
+	taskRepo *fakeTaskRepo
+	station  domain.Station
+	bay      domain.HangarBay
+	windows  *fakeBayCapacityWindowRepo
+	registry middleware.ServiceRegistry
+	start    time.Time
+}
+
+func newHangarFixture(t *testing.T, capacity int) *hangarFixture {
+	t.Helper()
+	orgID := uuid.New()
+	taskRepo := newFakeTaskRepo()
+	stationRepo := newFakeStationRepo()
+	bayRepo := newFakeHangarBayRepo()
+	windowRepo := newFakeBayCapacityWindowRepo()
+	station, _ := stationRepo.Create(context.Background(), domain.Station{
+		ID:       uuid.New(),
+		OrgID:    orgID,
+		Code:     "HAM",
+		Name:     "Hamburg",
+		Timezone: "Europe/Berlin",
+	})
+	bay, _ := bayRepo.Create(context.Background(), domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		StationID:     station.ID,
+		Code:          "B1",
+		Name:          "Bay 1",
+		CapacitySlots: capacity,
+	})
+	capacityService := &services.CapacityService{
+		Stations: stationRepo,
+		Bays:     bayRepo,
+		Windows:  windowRepo,
+		Tasks:    taskRepo,
+	}
+	taskService := &services.TaskService{Tasks: taskRepo, Capacity: capacityService}
+	return &hangarFixture{
+		orgID:    orgID,
+		taskRepo: taskRepo,
+		station:  station,
+		bay:      bay,
+		windows:  windowRepo,
+		registry: middleware.ServiceRegistry{Tasks: taskService, Capacity: capacityService},
+		start:    time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC(),
+	}
+}
+
+func (f *hangarFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *hangarFixture) createTask(t *testing.T, aircraftID uuid.UUID, bayID *uuid.UUID, start, end time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	body := map[string]any{
+		"aircraft_id": aircraftID.String(),
+		"type":        string(domain.TaskTypeInspection),
+		"start_time":  start.Format(time.RFC3339),
+		"end_time":    end.Format(time.RFC3339),
+	}
+	if bayID != nil {
+		body["bay_id"] = bayID.String()
+	}
+	return f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), CreateTask, nil)
+}
+
+func decodeErrorCode(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp response.ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return resp.Code
+}
+
+func TestCreateTaskRejectsFullBay(t *testing.T) {
+	f := newHangarFixture(t, 1)
+
+	rr := f.createTask(t, uuid.New(), &f.bay.ID, f.start, f.start.Add(4*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp taskResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.BaySlot == nil || *resp.BaySlot != 1 {
+		t.Fatalf("expected bay slot 1, got %v", resp.BaySlot)
+	}
+
+	rr = f.createTask(t, uuid.New(), &f.bay.ID, f.start.Add(2*time.Hour), f.start.Add(6*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for full bay, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "bay_capacity" {
+		t.Fatalf("expected code bay_capacity, got %s", code)
+	}
+
+	rr = f.createTask(t, uuid.New(), &f.bay.ID, f.start.Add(4*time.Hour), f.start.Add(6*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 once the slot is released, got %d", rr.Code)
+	}
+}
+
+func TestCreateTaskRejectsAircraftOverlap(t *testing.T) {
+	f := newHangarFixture(t, 2)
+	aircraftID := uuid.New()
+
+	rr := f.createTask(t, aircraftID, nil, f.start, f.start.Add(4*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	rr = f.createTask(t, aircraftID, &f.bay.ID, f.start.Add(time.Hour), f.start.Add(2*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for aircraft overlap, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "aircraft_overlap" {
+		t.Fatalf("expected code aircraft_overlap, got %s", code)
+	}
+}
+
+func TestCapacityWindowBelowBookedSlots(t *testing.T) {
+	f := newHangarFixture(t, 2)
+	for i := 0; i < 2; i++ {
+		if rr := f.createTask(t, uuid.New(), &f.bay.ID, f.start, f.start.Add(4*time.Hour)); rr.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d", rr.Code)
+		}
+	}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/hangar-bays/"+f.bay.ID.String()+"/capacity-windows", map[string]any{
+		"start_time":     f.start.Add(2 * time.Hour).Format(time.RFC3339),
+		"end_time":       f.start.Add(8 * time.Hour).Format(time.RFC3339),
+		"capacity_slots": 1,
+		"reason":         "dock maintenance",
+	})
+	rr := f.serve(t, req, CreateBayCapacityWindow, map[string]string{"id": f.bay.ID.String()})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "bay_capacity" {
+		t.Fatalf("expected code bay_capacity, got %s", code)
+	}
+}
+
+func TestStationAvailability(t *testing.T) {
+	f := newHangarFixture(t, 2)
+	if rr := f.createTask(t, uuid.New(), &f.bay.ID, f.start, f.start.Add(4*time.Hour)); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/hangar-bays/"+f.bay.ID.String()+"/capacity-windows", map[string]any{
+		"start_time":     f.start.Add(6 * time.Hour).Format(time.RFC3339),
+		"end_time":       f.start.Add(8 * time.Hour).Format(time.RFC3339),
+		"capacity_slots": 0,
+		"reason":         "floor resurfacing",
+	})
+	if rr := f.serve(t, req, CreateBayCapacityWindow, map[string]string{"id": f.bay.ID.String()}); rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+
+	path := "/api/v1/stations/" + f.station.ID.String() + "/availability?from=" + f.start.Format(time.RFC3339) + "&to=" + f.start.Add(10*time.Hour).Format(time.RFC3339) + "&min_slots=2"
+	rr := f.serve(t, newJSONRequest(t, http.MethodGet, path, nil), GetStationAvailability, map[string]string{"id": f.station.ID.String()})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp []bayAvailabilityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 {
+		t.Fatalf("expected 1 bay, got %d", len(resp))
+	}
+	windows := resp[0].FreeWindows
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 free windows, got %d", len(windows))
+	}
+	if !windows[0].Start.Equal(f.start.Add(4*time.Hour)) || !windows[0].End.Equal(f.start.Add(6*time.Hour)) {
+		t.Fatalf("unexpected first window %s - %s", windows[0].Start, windows[0].End)
+	}
+	if !windows[1].Start.Equal(f.start.Add(8*time.Hour)) || !windows[1].End.Equal(f.start.Add(10*time.Hour)) {
+		t.Fatalf("unexpected second window %s - %s", windows[1].Start, windows[1].End)
+	}
+}
@@ -43,3 +43,22 @@ func withRouteParam(req *http.Request, key, value string) *http.Request {
 	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
 	return req.WithContext(ctx)
 }
+
+// withUser is withPrincipal for a known user, for tests where who acts
+// matters.
+func withUser(req *http.Request, orgID, userID uuid.UUID, role domain.Role) *http.Request {
+	principal := middleware.Principal{
+		UserID: userID,
+		OrgID:  orgID,
+		Role:   role,
+	}
+	return req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+}
+
+func withRouteParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
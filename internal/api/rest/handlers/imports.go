@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app/ports"
@@ -87,7 +88,12 @@ func (h ImportHandler) CreateImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	impID := uuid.New()
-	filePath := filepath.Join(dir, impID.String()+".csv")
+	// JSON uploads keep their extension so the processor reads them as JSON.
+	ext := ".csv"
+	if strings.EqualFold(filepath.Ext(header.Filename), ".json") {
+		ext = ".json"
+	}
+	filePath := filepath.Join(dir, impID.String()+ext)
 	dest, err := os.Create(filePath)
 	if err != nil {
 		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "failed to create file")
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type laborClockInRequest struct {
+	OrgID      string `json:"org_id" validate:"omitempty,uuid"`
+	MechanicID string `json:"mechanic_id" validate:"omitempty,uuid"`
+	Notes      string `json:"notes" validate:"max=1000"`
+}
+
+type laborRecordRequest struct {
+	OrgID      string `json:"org_id" validate:"omitempty,uuid"`
+	MechanicID string `json:"mechanic_id" validate:"omitempty,uuid"`
+	StartedAt  string `json:"started_at" validate:"required,rfc3339"`
+	EndedAt    string `json:"ended_at" validate:"required,rfc3339"`
+	Notes      string `json:"notes" validate:"max=1000"`
+}
+
+type laborEntryResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	OrgID      uuid.UUID  `json:"org_id"`
+	TaskID     uuid.UUID  `json:"task_id"`
+	MechanicID uuid.UUID  `json:"mechanic_id"`
+	Source     string     `json:"source"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	Hours      float64    `json:"hours"`
+	Notes      string     `json:"notes,omitempty"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type taskLaborSummaryResponse struct {
+	TaskID        uuid.UUID                    `json:"task_id"`
+	PlannedHours  float64                      `json:"planned_hours"`
+	ActualHours   float64                      `json:"actual_hours"`
+	VarianceHours float64                      `json:"variance_hours"`
+	OpenEntries   int                          `json:"open_entries"`
+	Mechanics     []mechanicLaborHoursResponse `json:"mechanics"`
+}
+
+type mechanicLaborHoursResponse struct {
+	MechanicID uuid.UUID `json:"mechanic_id"`
+	Hours      float64   `json:"hours"`
+}
+
+func ClockInLabor(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	// Mechanics clocking themselves in may send an empty body.
+	var req laborClockInRequest
+	if r.ContentLength != 0 {
+		if err := decodeAndValidateJSON(r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+			return
+		}
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var mechanicID *uuid.UUID
+	if req.MechanicID != "" {
+		parsed, err := uuid.Parse(req.MechanicID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+			return
+		}
+		mechanicID = &parsed
+	}
+	entry, err := servicesReg.Labor.ClockIn(r.Context(), actor, services.LaborClockInInput{
+		OrgID:      &orgID,
+		TaskID:     taskID,
+		MechanicID: mechanicID,
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapLaborEntry(entry))
+}
+
+func RecordLabor(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	var req laborRecordRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	startedAt, err := time.Parse(time.RFC3339, req.StartedAt)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid started_at")
+		return
+	}
+	endedAt, err := time.Parse(time.RFC3339, req.EndedAt)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid ended_at")
+		return
+	}
+	var mechanicID *uuid.UUID
+	if req.MechanicID != "" {
+		parsed, err := uuid.Parse(req.MechanicID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+			return
+		}
+		mechanicID = &parsed
+	}
+	entry, err := servicesReg.Labor.RecordManual(r.Context(), actor, services.LaborManualInput{
+		OrgID:      &orgID,
+		TaskID:     taskID,
+		MechanicID: mechanicID,
+		StartedAt:  startedAt,
+		EndedAt:    endedAt,
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapLaborEntry(entry))
+}
+
+func ListTaskLabor(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	entries, err := servicesReg.Labor.ListByTask(r.Context(), actor, orgID, taskID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]laborEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, mapLaborEntry(entry))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetTaskLaborSummary(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	summary, err := servicesReg.Labor.Summary(r.Context(), actor, orgID, taskID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := taskLaborSummaryResponse{
+		TaskID:        summary.TaskID,
+		PlannedHours:  summary.PlannedHours,
+		ActualHours:   summary.ActualHours,
+		VarianceHours: summary.VarianceHours,
+		OpenEntries:   summary.OpenEntries,
+		Mechanics:     make([]mechanicLaborHoursResponse, 0, len(summary.Mechanics)),
+	}
+	for _, mechanic := range summary.Mechanics {
+		resp.Mechanics = append(resp.Mechanics, mechanicLaborHoursResponse{MechanicID: mechanic.MechanicID, Hours: mechanic.Hours})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func ClockOutLabor(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid labor entry id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	entry, err := servicesReg.Labor.ClockOut(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapLaborEntry(entry))
+}
+
+func DeleteLaborEntry(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Labor == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid labor entry id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	if err := servicesReg.Labor.Delete(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapLaborEntry(entry domain.LaborEntry) laborEntryResponse {
+	return laborEntryResponse{
+		ID:         entry.ID,
+		OrgID:      entry.OrgID,
+		TaskID:     entry.TaskID,
+		MechanicID: entry.MechanicID,
+		Source:     string(entry.Source),
+		StartedAt:  entry.StartedAt,
+		EndedAt:    entry.EndedAt,
+		Hours:      entry.Hours(),
+		Notes:      entry.Notes,
+		CreatedBy:  entry.CreatedBy,
+		CreatedAt:  entry.CreatedAt,
+		UpdatedAt:  entry.UpdatedAt,
+	}
+}
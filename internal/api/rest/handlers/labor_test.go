@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// manualClock is a clock tests move forward by hand.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLaborTrackingFeedsRecency(t *testing.T) {
+	ctx := context.Background()
+	clock := &manualClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+	orgID := uuid.New()
+	mechanic := uuid.New()
+	otherMechanic := uuid.New()
+	aircraftTypeID := uuid.New()
+
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		TailNumber:     "N300AM",
+		Model:          "A320",
+		AircraftTypeID: &aircraftTypeID,
+		Status:         domain.AircraftGrounded,
+		CapacitySlots:  1,
+	})
+	taskRepo := newFakeTaskRepo()
+	task, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              orgID,
+		AircraftID:         aircraft.ID,
+		Type:               domain.TaskTypeInspection,
+		State:              domain.TaskStateInProgress,
+		StartTime:          clock.Now().Add(-4 * time.Hour),
+		EndTime:            clock.Now().Add(-time.Hour),
+		AssignedMechanicID: &mechanic,
+	})
+	laborRepo := newFakeLaborRepo()
+	certs := &fakeAuthorizationRepo{}
+	taskRepo.labor = laborRepo
+	taskRepo.aircraft = aircraftRepo
+	taskRepo.certs = certs
+	laborService := &services.LaborService{
+		Labor:    laborRepo,
+		Tasks:    taskRepo,
+		Aircraft: aircraftRepo,
+		Clock:    clock,
+	}
+	registry := middleware.ServiceRegistry{
+		Tasks: &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Labor: laborService, Clock: clock},
+		Labor: laborService,
+	}
+	serve := func(req *http.Request, userID uuid.UUID, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+		req = withRouteParams(withUser(req, orgID, userID, domain.RoleMechanic), params)
+		rr := httptest.NewRecorder()
+		middleware.InjectServices(registry)(handler).ServeHTTP(rr, req)
+		return rr
+	}
+	taskParams := map[string]string{"id": task.ID.String()}
+	laborPath := "/api/v1/maintenance-tasks/" + task.ID.String() + "/labor"
+	record := func(userID uuid.UUID, body map[string]any) *httptest.ResponseRecorder {
+		return serve(newJSONRequest(t, http.MethodPost, laborPath, body), userID, RecordLabor, taskParams)
+	}
+	complete := func() *httptest.ResponseRecorder {
+		body := map[string]any{"new_state": "completed", "notes": "done"}
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-tasks/"+task.ID.String()+"/state", body)
+		return serve(req, mechanic, TransitionTaskState, taskParams)
+	}
+
+	// The first entry runs across UTC midnight into the task's day.
+	rr := record(mechanic, map[string]any{
+		"started_at": "2026-03-09T22:00:00Z",
+		"ended_at":   "2026-03-10T02:00:00Z",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 for a manual entry, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = record(mechanic, map[string]any{
+		"started_at": "2026-03-10T01:00:00Z",
+		"ended_at":   "2026-03-10T03:00:00Z",
+	})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for overlapping labor, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "labor_overlap" {
+		t.Fatalf("expected code labor_overlap, got %s", code)
+	}
+	rr = record(mechanic, map[string]any{
+		"mechanic_id": otherMechanic.String(),
+		"started_at":  "2026-03-10T01:00:00Z",
+		"ended_at":    "2026-03-10T03:00:00Z",
+	})
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic booking someone else, got %d", rr.Code)
+	}
+
+	rr = serve(httptest.NewRequest(http.MethodPost, laborPath+"/clock-in", nil), mechanic, ClockInLabor, taskParams)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 clocking in, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var clocked laborEntryResponse
+	if err := json.NewDecoder(rr.Body).Decode(&clocked); err != nil {
+		t.Fatalf("decode labor entry: %v", err)
+	}
+	rr = serve(httptest.NewRequest(http.MethodPost, laborPath+"/clock-in", nil), mechanic, ClockInLabor, taskParams)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 clocking in twice, got %d", rr.Code)
+	}
+	if rr := complete(); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 completing while clocked in, got %d", rr.Code)
+	}
+
+	clock.Advance(90 * time.Minute)
+	entryParams := map[string]string{"id": clocked.ID.String()}
+	clockOutPath := "/api/v1/labor-entries/" + clocked.ID.String() + "/clock-out"
+	if rr := serve(httptest.NewRequest(http.MethodPost, clockOutPath, nil), otherMechanic, ClockOutLabor, entryParams); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 clocking out another mechanic, got %d", rr.Code)
+	}
+	rr = serve(httptest.NewRequest(http.MethodPost, clockOutPath, nil), mechanic, ClockOutLabor, entryParams)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 clocking out, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(httptest.NewRequest(http.MethodGet, laborPath+"/summary", nil), mechanic, GetTaskLaborSummary, taskParams)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for the summary, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var summary taskLaborSummaryResponse
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	if summary.PlannedHours != 3 || summary.ActualHours != 5.5 || summary.VarianceHours != 2.5 || summary.OpenEntries != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(summary.Mechanics) != 1 || summary.Mechanics[0].MechanicID != mechanic {
+		t.Fatalf("expected hours for one mechanic, got %+v", summary.Mechanics)
+	}
+
+	if rr := complete(); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 completing, got %d: %s", rr.Code, rr.Body.String())
+	}
+	byDate := map[string]float64{}
+	for _, entry := range certs.recency {
+		if entry.UserID != mechanic || entry.AircraftTypeID != aircraftTypeID || entry.TaskID != task.ID {
+			t.Fatalf("unexpected recency entry: %+v", entry)
+		}
+		byDate[entry.WorkDate.Format("2006-01-02")] += entry.HoursLogged
+	}
+	if len(byDate) != 2 || byDate["2026-03-09"] != 2 || math.Abs(byDate["2026-03-10"]-3.5) > 1e-9 {
+		t.Fatalf("expected recency split by day, got %v", byDate)
+	}
+
+	rr = record(mechanic, map[string]any{
+		"started_at": "2026-03-10T05:00:00Z",
+		"ended_at":   "2026-03-10T06:00:00Z",
+	})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 booking labor on a completed task, got %d", rr.Code)
+	}
+}
@@ -1,28 +1,55 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/domain"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type partDefinitionRequest struct {
-	OrgID    string `json:"org_id" validate:"omitempty,uuid"`
-	Name     string `json:"name" validate:"required"`
-	Category string `json:"category" validate:"required"`
+	OrgID        string   `json:"org_id" validate:"omitempty,uuid"`
+	Name         string   `json:"name" validate:"required"`
+	Category     string   `json:"category" validate:"required"`
+	LeadTimeDays *int     `json:"lead_time_days" validate:"omitempty,min=0"`
+	AlternateIDs []string `json:"alternate_ids" validate:"omitempty,dive,uuid"`
+}
+
+// input converts the request, keeping a missing alternate_ids nil so an
+// update leaves the alternates unchanged.
+func (req partDefinitionRequest) input() (services.PartDefinitionInput, error) {
+	input := services.PartDefinitionInput{
+		Name:         req.Name,
+		Category:     req.Category,
+		LeadTimeDays: req.LeadTimeDays,
+	}
+	if req.AlternateIDs != nil {
+		input.AlternateIDs = make([]uuid.UUID, 0, len(req.AlternateIDs))
+		for _, raw := range req.AlternateIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return services.PartDefinitionInput{}, err
+			}
+			input.AlternateIDs = append(input.AlternateIDs, id)
+		}
+	}
+	return input, nil
 }
 
 type partDefinitionResponse struct {
-	ID        uuid.UUID `json:"id"`
-	OrgID     uuid.UUID `json:"org_id"`
-	Name      string    `json:"name"`
-	Category  string    `json:"category"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uuid.UUID   `json:"id"`
+	OrgID        uuid.UUID   `json:"org_id"`
+	Name         string      `json:"name"`
+	Category     string      `json:"category"`
+	LeadTimeDays *int        `json:"lead_time_days,omitempty"`
+	AlternateIDs []uuid.UUID `json:"alternate_ids"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
 }
 
 type partItemCreateRequest struct {
@@ -31,23 +58,63 @@ type partItemCreateRequest struct {
 	SerialNumber     string `json:"serial_number" validate:"required"`
 	Status           string `json:"status" validate:"omitempty,oneof=in_stock used disposed"`
 	ExpiryDate       string `json:"expiry_date" validate:"omitempty,rfc3339"`
+	partItemLifeRequest
 }
 
 type partItemUpdateRequest struct {
 	Status     string `json:"status" validate:"omitempty,oneof=in_stock used disposed"`
 	ExpiryDate string `json:"expiry_date" validate:"omitempty,rfc3339"`
 	OrgID      string `json:"org_id" validate:"omitempty,uuid"`
+	partItemLifeRequest
+}
+
+// partItemLifeRequest holds the life-limited part fields shared by the create
+// and update requests.
+type partItemLifeRequest struct {
+	LifeLimitHours      *float64 `json:"life_limit_hours" validate:"omitempty,gt=0"`
+	LifeLimitCycles     *int     `json:"life_limit_cycles" validate:"omitempty,min=1"`
+	HoursSinceNew       *float64 `json:"hours_since_new" validate:"omitempty,min=0"`
+	CyclesSinceNew      *int     `json:"cycles_since_new" validate:"omitempty,min=0"`
+	HoursSinceOverhaul  *float64 `json:"hours_since_overhaul" validate:"omitempty,min=0"`
+	CyclesSinceOverhaul *int     `json:"cycles_since_overhaul" validate:"omitempty,min=0"`
+}
+
+func (req partItemLifeRequest) empty() bool {
+	return req.LifeLimitHours == nil && req.LifeLimitCycles == nil &&
+		req.HoursSinceNew == nil && req.CyclesSinceNew == nil && req.HoursSinceOverhaul == nil && req.CyclesSinceOverhaul == nil
+}
+
+func (req partItemLifeRequest) input() services.PartItemLifeInput {
+	return services.PartItemLifeInput{
+		LifeLimitHours:      req.LifeLimitHours,
+		LifeLimitCycles:     req.LifeLimitCycles,
+		HoursSinceNew:       req.HoursSinceNew,
+		CyclesSinceNew:      req.CyclesSinceNew,
+		HoursSinceOverhaul:  req.HoursSinceOverhaul,
+		CyclesSinceOverhaul: req.CyclesSinceOverhaul,
+	}
 }
 
 type partItemResponse struct {
-	ID               uuid.UUID             `json:"id"`
-	OrgID            uuid.UUID             `json:"org_id"`
-	PartDefinitionID uuid.UUID             `json:"part_definition_id"`
-	SerialNumber     string                `json:"serial_number"`
-	Status           domain.PartItemStatus `json:"status"`
-	ExpiryDate       *time.Time            `json:"expiry_date,omitempty"`
-	CreatedAt        time.Time             `json:"created_at"`
-	UpdatedAt        time.Time             `json:"updated_at"`
+	ID                   uuid.UUID             `json:"id"`
+	OrgID                uuid.UUID             `json:"org_id"`
+	PartDefinitionID     uuid.UUID             `json:"part_definition_id"`
+	SerialNumber         string                `json:"serial_number"`
+	Status               domain.PartItemStatus `json:"status"`
+	ExpiryDate           *time.Time            `json:"expiry_date,omitempty"`
+	AircraftID           *uuid.UUID            `json:"aircraft_id,omitempty"`
+	Position             string                `json:"position,omitempty"`
+	LifeLimitHours       *float64              `json:"life_limit_hours,omitempty"`
+	LifeLimitCycles      *int                  `json:"life_limit_cycles,omitempty"`
+	HoursSinceNew        float64               `json:"hours_since_new"`
+	CyclesSinceNew       int                   `json:"cycles_since_new"`
+	HoursSinceOverhaul   float64               `json:"hours_since_overhaul"`
+	CyclesSinceOverhaul  int                   `json:"cycles_since_overhaul"`
+	RemainingHours       *float64              `json:"remaining_hours,omitempty"`
+	RemainingCycles      *int                  `json:"remaining_cycles,omitempty"`
+	RemainingLifePercent *float64              `json:"remaining_life_percent,omitempty"`
+	CreatedAt            time.Time             `json:"created_at"`
+	UpdatedAt            time.Time             `json:"updated_at"`
 }
 
 func CreatePartDefinition(w http.ResponseWriter, r *http.Request) {
@@ -72,7 +139,13 @@ func CreatePartDefinition(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	created, err := servicesReg.Catalog.CreateDefinition(r.Context(), actor, orgID, req.Name, req.Category)
+	input, err := req.input()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid alternate_ids")
+		return
+	}
+
+	created, err := servicesReg.Catalog.CreateDefinition(r.Context(), actor, orgID, input)
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
@@ -168,7 +241,13 @@ func UpdatePartDefinition(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	updated, err := servicesReg.Catalog.UpdateDefinition(r.Context(), actor, orgID, id, req.Name, req.Category)
+	input, err := req.input()
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid alternate_ids")
+		return
+	}
+
+	updated, err := servicesReg.Catalog.UpdateDefinition(r.Context(), actor, orgID, id, input)
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
@@ -252,7 +331,7 @@ func CreatePartItem(w http.ResponseWriter, r *http.Request) {
 		expiry = &value
 	}
 
-	created, err := servicesReg.Catalog.CreateItem(r.Context(), actor, orgID, defID, req.SerialNumber, status, expiry)
+	created, err := servicesReg.Catalog.CreateItem(r.Context(), actor, orgID, defID, req.SerialNumber, status, expiry, req.partItemLifeRequest.input())
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
@@ -307,6 +386,22 @@ func ListPartItems(w http.ResponseWriter, r *http.Request) {
 		}
 		filter.ExpiryBefore = &value
 	}
+	if aircraftID := query.Get("aircraft_id"); aircraftID != "" {
+		parsed, err := uuid.Parse(aircraftID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		filter.AircraftID = &parsed
+	}
+	if lifeLimited := query.Get("life_limited"); lifeLimited != "" {
+		value, err := parseBool(lifeLimited)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid life_limited")
+			return
+		}
+		filter.LifeLimited = value
+	}
 	if limit := query.Get("limit"); limit != "" {
 		value, err := parseInt(limit)
 		if err != nil {
@@ -357,7 +452,7 @@ func UpdatePartItem(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
 		return
 	}
-	if req.Status == "" && req.ExpiryDate == "" {
+	if req.Status == "" && req.ExpiryDate == "" && req.partItemLifeRequest.empty() {
 		writeError(w, r, http.StatusBadRequest, "VALIDATION", "no changes provided")
 		return
 	}
@@ -392,7 +487,7 @@ func UpdatePartItem(w http.ResponseWriter, r *http.Request) {
 		expiry = &value
 	}
 
-	updated, err := servicesReg.Catalog.UpdateItem(r.Context(), actor, orgID, id, status, expiry)
+	updated, err := servicesReg.Catalog.UpdateItem(r.Context(), actor, orgID, id, status, expiry, req.partItemLifeRequest.input())
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
@@ -435,25 +530,46 @@ func DeletePartItem(w http.ResponseWriter, r *http.Request) {
 }
 
 func mapPartDefinition(def domain.PartDefinition) partDefinitionResponse {
+	alternates := def.AlternateIDs
+	if alternates == nil {
+		alternates = []uuid.UUID{}
+	}
 	return partDefinitionResponse{
-		ID:        def.ID,
-		OrgID:     def.OrgID,
-		Name:      def.Name,
-		Category:  def.Category,
-		CreatedAt: def.CreatedAt,
-		UpdatedAt: def.UpdatedAt,
+		ID:           def.ID,
+		OrgID:        def.OrgID,
+		Name:         def.Name,
+		Category:     def.Category,
+		LeadTimeDays: def.LeadTimeDays,
+		AlternateIDs: alternates,
+		CreatedAt:    def.CreatedAt,
+		UpdatedAt:    def.UpdatedAt,
 	}
 }
 
 func mapPartItem(item domain.PartItem) partItemResponse {
-	return partItemResponse{
-		ID:               item.ID,
-		OrgID:            item.OrgID,
-		PartDefinitionID: item.DefinitionID,
-		SerialNumber:     item.SerialNumber,
-		Status:           item.Status,
-		ExpiryDate:       item.ExpiryDate,
-		CreatedAt:        item.CreatedAt,
-		UpdatedAt:        item.UpdatedAt,
-	}
+	resp := partItemResponse{
+		ID:                  item.ID,
+		OrgID:               item.OrgID,
+		PartDefinitionID:    item.DefinitionID,
+		SerialNumber:        item.SerialNumber,
+		Status:              item.Status,
+		ExpiryDate:          item.ExpiryDate,
+		AircraftID:          item.AircraftID,
+		Position:            item.Position,
+		LifeLimitHours:      item.LifeLimitHours,
+		LifeLimitCycles:     item.LifeLimitCycles,
+		HoursSinceNew:       item.HoursSinceNew,
+		CyclesSinceNew:      item.CyclesSinceNew,
+		HoursSinceOverhaul:  item.HoursSinceOverhaul,
+		CyclesSinceOverhaul: item.CyclesSinceOverhaul,
+		RemainingHours:      item.RemainingHours(),
+		RemainingCycles:     item.RemainingCycles(),
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
+	}
+	if percent, ok := item.RemainingLifePercent(); ok {
+		percent = math.Round(percent*100) / 100
+		resp.RemainingLifePercent = &percent
+	}
+	return resp
 }
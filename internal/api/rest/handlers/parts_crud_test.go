@@ -341,3 +341,58 @@ func TestDeletePartItem(t *testing.T) {
 		t.Fatalf("expected deleted_at to be set")
 	}
 }
+
+func TestCreatePartItemWithLifeLimits(t *testing.T) {
+	orgID := uuid.New()
+	defRepo := newFakePartDefinitionRepo()
+	itemRepo := newFakePartItemRepo()
+	catalogService := &services.PartCatalogService{Definitions: defRepo, Items: itemRepo}
+	registry := middleware.ServiceRegistry{Catalog: catalogService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/part-items", map[string]any{
+		"part_definition_id":    uuid.New().String(),
+		"serial_number":         "HPT-DISK-001",
+		"life_limit_hours":      20000,
+		"life_limit_cycles":     15000,
+		"hours_since_new":       12000,
+		"cycles_since_new":      13500,
+		"hours_since_overhaul":  2000,
+		"cycles_since_overhaul": 1500,
+	})
+	req = withPrincipal(req, orgID, domain.RoleMechanic)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreatePartItem))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp partItemResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.RemainingHours == nil || *resp.RemainingHours != 8000 {
+		t.Fatalf("expected 8000 remaining hours, got %v", resp.RemainingHours)
+	}
+	if resp.RemainingCycles == nil || *resp.RemainingCycles != 1500 {
+		t.Fatalf("expected 1500 remaining cycles, got %v", resp.RemainingCycles)
+	}
+	// Cycles are the limiting measure: 1500 of 15000 left.
+	if resp.RemainingLifePercent == nil || *resp.RemainingLifePercent != 10 {
+		t.Fatalf("expected 10%% remaining life, got %v", resp.RemainingLifePercent)
+	}
+
+	invalid := newJSONRequest(t, http.MethodPost, "/api/v1/part-items", map[string]any{
+		"part_definition_id":   uuid.New().String(),
+		"serial_number":        "HPT-DISK-002",
+		"hours_since_new":      100,
+		"hours_since_overhaul": 200,
+	})
+	invalid = withPrincipal(invalid, orgID, domain.RoleMechanic)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, invalid)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for overhaul usage above usage since new, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type preemptionFixture struct {
+	orgID    uuid.UUID
+	taskRepo *fakeTaskRepo
+	deps     *fakeTaskDependencyRepo
+	changes  *fakeScheduleChangeRepo
+	outbox   *fakeOutboxRepo
+	bay      domain.HangarBay
+	registry middleware.ServiceRegistry
+	start    time.Time
+}
+
+func newPreemptionFixture(t *testing.T) *preemptionFixture {
+	t.Helper()
+	orgID := uuid.New()
+	taskRepo := newFakeTaskRepo()
+	bayRepo := newFakeHangarBayRepo()
+	bay, _ := bayRepo.Create(context.Background(), domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		StationID:     uuid.New(),
+		Code:          "B1",
+		Name:          "Bay 1",
+		CapacitySlots: 1,
+	})
+	f := &preemptionFixture{
+		orgID:    orgID,
+		taskRepo: taskRepo,
+		deps:     newFakeTaskDependencyRepo(),
+		changes:  newFakeScheduleChangeRepo(taskRepo),
+		outbox:   &fakeOutboxRepo{},
+		bay:      bay,
+		start:    time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC(),
+	}
+	capacity := &services.CapacityService{
+		Stations: newFakeStationRepo(),
+		Bays:     bayRepo,
+		Windows:  newFakeBayCapacityWindowRepo(),
+		Tasks:    taskRepo,
+	}
+	scheduling := &services.SchedulingService{
+		Tasks:          taskRepo,
+		Dependencies:   f.deps,
+		ScheduleEvents: f.changes,
+		Capacity:       capacity,
+		Outbox:         f.outbox,
+	}
+	tasks := &services.TaskService{
+		Tasks:    taskRepo,
+		Capacity: capacity,
+		Outbox:   f.outbox,
+		Preemption: &services.PreemptionService{
+			ScheduleEvents: f.changes,
+			Scheduling:     scheduling,
+			Capacity:       capacity,
+			Outbox:         f.outbox,
+		},
+	}
+	f.registry = middleware.ServiceRegistry{Tasks: tasks, Capacity: capacity, Scheduling: scheduling}
+	return f
+}
+
+func (f *preemptionFixture) seedTask(t *testing.T, priority domain.TaskPriority, bayID, mechanicID *uuid.UUID, start, end time.Time) domain.MaintenanceTask {
+	t.Helper()
+	task := domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              f.orgID,
+		AircraftID:         uuid.New(),
+		BayID:              bayID,
+		Type:               domain.TaskTypeInspection,
+		Priority:           priority,
+		State:              domain.TaskStateScheduled,
+		StartTime:          start,
+		EndTime:            end,
+		AssignedMechanicID: mechanicID,
+		UpdatedAt:          f.start.Add(-48 * time.Hour),
+	}
+	if bayID != nil {
+		slot := 1
+		task.BaySlot = &slot
+	}
+	task, err := f.taskRepo.Create(context.Background(), task)
+	if err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	return task
+}
+
+func (f *preemptionFixture) createTask(t *testing.T, body map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	body["aircraft_id"] = uuid.New().String()
+	body["type"] = string(domain.TaskTypeInspection)
+	req := withPrincipal(newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), f.orgID, domain.RoleScheduler)
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(http.HandlerFunc(CreateTask)).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *preemptionFixture) outboxEvents(eventType string) []map[string]any {
+	f.outbox.mu.Lock()
+	defer f.outbox.mu.Unlock()
+	var out []map[string]any
+	for _, event := range f.outbox.events {
+		if event.EventType == eventType {
+			out = append(out, event.Payload)
+		}
+	}
+	return out
+}
+
+func TestCreateAOGTaskPreemptsBay(t *testing.T) {
+	f := newPreemptionFixture(t)
+	routine := f.seedTask(t, domain.PriorityRoutine, &f.bay.ID, nil, f.start, f.start.Add(4*time.Hour))
+	follower := f.seedTask(t, domain.PriorityRoutine, nil, nil, f.start.Add(4*time.Hour), f.start.Add(6*time.Hour))
+	_, _ = f.deps.Create(context.Background(), domain.TaskDependency{
+		ID:              uuid.New(),
+		OrgID:           f.orgID,
+		TaskID:          follower.ID,
+		DependsOnTaskID: routine.ID,
+		DependencyType:  domain.DependencyFinishToStart,
+	})
+
+	rr := f.createTask(t, map[string]any{
+		"bay_id":     f.bay.ID.String(),
+		"priority":   string(domain.PriorityAOG),
+		"start_time": f.start.Add(time.Hour).Format(time.RFC3339),
+		"end_time":   f.start.Add(3 * time.Hour).Format(time.RFC3339),
+	})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 without preempt, got %d", rr.Code)
+	}
+
+	rr = f.createTask(t, map[string]any{
+		"bay_id":     f.bay.ID.String(),
+		"priority":   string(domain.PriorityAOG),
+		"start_time": f.start.Add(time.Hour).Format(time.RFC3339),
+		"end_time":   f.start.Add(3 * time.Hour).Format(time.RFC3339),
+		"preempt":    true,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created taskResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if created.BaySlot == nil || *created.BaySlot != 1 {
+		t.Fatalf("expected the aog task in slot 1, got %v", created.BaySlot)
+	}
+
+	moved, _ := f.taskRepo.GetByID(context.Background(), f.orgID, routine.ID)
+	if !moved.StartTime.Equal(f.start.Add(3 * time.Hour)) {
+		t.Fatalf("expected routine task to start when the aog task ends, got %s", moved.StartTime)
+	}
+	movedFollower, _ := f.taskRepo.GetByID(context.Background(), f.orgID, follower.ID)
+	if !movedFollower.StartTime.Equal(moved.EndTime) {
+		t.Fatalf("expected dependent to follow the moved task, got %s", movedFollower.StartTime)
+	}
+
+	events, _ := f.changes.ListByTask(context.Background(), f.orgID, routine.ID)
+	if len(events) != 1 || events[0].ChangeType != domain.ScheduleChangeRescheduled {
+		t.Fatalf("expected one rescheduled event, got %+v", events)
+	}
+	if !strings.Contains(events[0].Reason, "preempted by aog task") {
+		t.Fatalf("expected automatic preemption reason, got %q", events[0].Reason)
+	}
+	if len(events[0].AffectedTaskIDs) != 1 || events[0].AffectedTaskIDs[0] != follower.ID {
+		t.Fatalf("expected the dependent among affected tasks, got %v", events[0].AffectedTaskIDs)
+	}
+	if notices := f.outboxEvents("task_preempted"); len(notices) != 1 {
+		t.Fatalf("expected one task_preempted event, got %d", len(notices))
+	}
+}
+
+func TestCreateCriticalTaskReleasesMechanic(t *testing.T) {
+	f := newPreemptionFixture(t)
+	mechanicID := uuid.New()
+	routine := f.seedTask(t, domain.PriorityRoutine, nil, &mechanicID, f.start, f.start.Add(4*time.Hour))
+	urgent := f.seedTask(t, domain.PriorityCritical, nil, &mechanicID, f.start.Add(4*time.Hour), f.start.Add(5*time.Hour))
+
+	rr := f.createTask(t, map[string]any{
+		"priority":             string(domain.PriorityCritical),
+		"assigned_mechanic_id": mechanicID.String(),
+		"start_time":           f.start.Add(2 * time.Hour).Format(time.RFC3339),
+		"end_time":             f.start.Add(5 * time.Hour).Format(time.RFC3339),
+		"preempt":              true,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	released, _ := f.taskRepo.GetByID(context.Background(), f.orgID, routine.ID)
+	if released.AssignedMechanicID != nil {
+		t.Fatalf("expected routine task to give up its mechanic")
+	}
+	if !released.StartTime.Equal(routine.StartTime) {
+		t.Fatalf("expected released task to keep its window")
+	}
+	kept, _ := f.taskRepo.GetByID(context.Background(), f.orgID, urgent.ID)
+	if kept.AssignedMechanicID == nil || *kept.AssignedMechanicID != mechanicID {
+		t.Fatalf("expected equal-priority task to keep its mechanic")
+	}
+
+	events, _ := f.changes.ListByTask(context.Background(), f.orgID, routine.ID)
+	if len(events) != 1 || events[0].ChangeType != domain.ScheduleChangeMechanicReassigned {
+		t.Fatalf("expected one mechanic_reassigned event, got %+v", events)
+	}
+	notices := f.outboxEvents("task_preempted")
+	if len(notices) != 1 {
+		t.Fatalf("expected one task_preempted event, got %d", len(notices))
+	}
+	mechanics, _ := notices[0]["mechanic_ids"].([]uuid.UUID)
+	if len(mechanics) != 1 || mechanics[0] != mechanicID {
+		t.Fatalf("expected the released mechanic to be notified, got %v", notices[0]["mechanic_ids"])
+	}
+}
+
+func TestPreemptionDryRunWritesNothing(t *testing.T) {
+	f := newPreemptionFixture(t)
+	routine := f.seedTask(t, domain.PriorityRoutine, &f.bay.ID, nil, f.start, f.start.Add(4*time.Hour))
+	f.seedTask(t, domain.PriorityAOG, &f.bay.ID, nil, f.start.Add(5*time.Hour), f.start.Add(7*time.Hour))
+
+	rr := f.createTask(t, map[string]any{
+		"bay_id":     f.bay.ID.String(),
+		"priority":   string(domain.PriorityAOG),
+		"start_time": f.start.Add(time.Hour).Format(time.RFC3339),
+		"end_time":   f.start.Add(6 * time.Hour).Format(time.RFC3339),
+		"preempt":    true,
+		"dry_run":    true,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp preemptionPlanResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Displacements) != 1 || resp.Displacements[0].TaskID != routine.ID || resp.Displacements[0].Resource != services.PreemptedBay {
+		t.Fatalf("expected routine task displaced from the bay, got %+v", resp.Displacements)
+	}
+	if len(resp.CapacityViolations) == 0 || resp.CapacityViolations[0].Kind != "bay_capacity" {
+		t.Fatalf("expected the aog task holding the bay to remain a violation, got %+v", resp.CapacityViolations)
+	}
+
+	stored, _ := f.taskRepo.GetByID(context.Background(), f.orgID, routine.ID)
+	if !stored.StartTime.Equal(routine.StartTime) {
+		t.Fatalf("expected dry run to leave the task in place")
+	}
+	if len(f.taskRepo.tasks) != 2 || len(f.changes.events) != 0 || len(f.outbox.events) != 0 {
+		t.Fatalf("expected dry run to write nothing")
+	}
+}
+
+func TestPreemptRejectsRoutineTask(t *testing.T) {
+	f := newPreemptionFixture(t)
+	f.seedTask(t, domain.PriorityRoutine, &f.bay.ID, nil, f.start, f.start.Add(4*time.Hour))
+
+	rr := f.createTask(t, map[string]any{
+		"bay_id":     f.bay.ID.String(),
+		"priority":   string(domain.PriorityUrgent),
+		"start_time": f.start.Format(time.RFC3339),
+		"end_time":   f.start.Add(2 * time.Hour).Format(time.RFC3339),
+		"preempt":    true,
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+
+	rr = f.createTask(t, map[string]any{
+		"priority":   string(domain.PriorityAOG),
+		"start_time": f.start.Format(time.RFC3339),
+		"end_time":   f.start.Add(2 * time.Hour).Format(time.RFC3339),
+		"dry_run":    true,
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for dry_run without preempt, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type programTemplateCreateRequest struct {
+	OrgID            string                    `json:"org_id" validate:"omitempty,uuid"`
+	AircraftTypeID   string                    `json:"aircraft_type_id" validate:"required,uuid"`
+	Name             string                    `json:"name" validate:"required"`
+	IntervalType     string                    `json:"interval_type" validate:"required,oneof=flight_hours cycles calendar"`
+	IntervalValue    int                       `json:"interval_value" validate:"min=1"`
+	TolerancePercent int                       `json:"tolerance_percent" validate:"min=0,max=50"`
+	Thresholds       []programThresholdRequest `json:"thresholds" validate:"omitempty,max=2,dive"`
+}
+
+type programTemplateUpdateRequest struct {
+	OrgID            string                     `json:"org_id" validate:"omitempty,uuid"`
+	Name             *string                    `json:"name" validate:"omitempty,min=1"`
+	IntervalType     *string                    `json:"interval_type" validate:"omitempty,oneof=flight_hours cycles calendar"`
+	IntervalValue    *int                       `json:"interval_value" validate:"omitempty,min=1"`
+	TolerancePercent *int                       `json:"tolerance_percent" validate:"omitempty,min=0,max=50"`
+	Thresholds       *[]programThresholdRequest `json:"thresholds" validate:"omitempty,max=2,dive"`
+}
+
+type programTemplatePropagateRequest struct {
+	OrgID    string `json:"org_id" validate:"omitempty,uuid"`
+	Revision int    `json:"revision" validate:"min=1"`
+}
+
+type programTemplateResponse struct {
+	ID               uuid.UUID                             `json:"id"`
+	OrgID            uuid.UUID                             `json:"org_id"`
+	AircraftTypeID   uuid.UUID                             `json:"aircraft_type_id"`
+	Name             string                                `json:"name"`
+	IntervalType     domain.MaintenanceProgramIntervalType `json:"interval_type"`
+	IntervalValue    int                                   `json:"interval_value"`
+	TolerancePercent int                                   `json:"tolerance_percent"`
+	Thresholds       []programThresholdResponse            `json:"thresholds"`
+	Revision         int                                   `json:"revision"`
+	CreatedAt        time.Time                             `json:"created_at"`
+	UpdatedAt        time.Time                             `json:"updated_at"`
+}
+
+type programTemplateChangeResponse struct {
+	AircraftID   uuid.UUID                          `json:"aircraft_id"`
+	TailNumber   string                             `json:"tail_number"`
+	ProgramID    *uuid.UUID                         `json:"program_id,omitempty"`
+	Action       domain.ProgramTemplateChangeAction `json:"action"`
+	FromRevision *int                               `json:"from_revision,omitempty"`
+	ToRevision   int                                `json:"to_revision"`
+	Changes      []programFieldChangeResponse       `json:"changes"`
+}
+
+type programFieldChangeResponse struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+func CreateProgramTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req programTemplateCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	aircraftTypeID, err := uuid.Parse(req.AircraftTypeID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_type_id")
+		return
+	}
+	input := services.ProgramTemplateCreateInput{
+		OrgID:            &orgID,
+		AircraftTypeID:   aircraftTypeID,
+		Name:             req.Name,
+		IntervalType:     domain.MaintenanceProgramIntervalType(req.IntervalType),
+		IntervalValue:    req.IntervalValue,
+		TolerancePercent: req.TolerancePercent,
+		Thresholds:       mapProgramThresholdRequests(req.Thresholds),
+	}
+	created, err := servicesReg.ProgramTemplates.Create(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapProgramTemplate(created))
+}
+
+func ListProgramTemplates(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.MaintenanceProgramTemplateFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if aircraftType := query.Get("aircraft_type_id"); aircraftType != "" {
+		id, err := uuid.Parse(aircraftType)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_type_id")
+			return
+		}
+		filter.AircraftTypeID = &id
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+
+	items, err := servicesReg.ProgramTemplates.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]programTemplateResponse, 0, len(items))
+	for _, template := range items {
+		resp = append(resp, mapProgramTemplate(template))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetProgramTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	template, err := servicesReg.ProgramTemplates.Get(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapProgramTemplate(template))
+}
+
+func UpdateProgramTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template id")
+		return
+	}
+	var req programTemplateUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	var intervalType *domain.MaintenanceProgramIntervalType
+	if req.IntervalType != nil {
+		value := domain.MaintenanceProgramIntervalType(*req.IntervalType)
+		intervalType = &value
+	}
+	var thresholds *[]domain.ProgramThreshold
+	if req.Thresholds != nil {
+		value := mapProgramThresholdRequests(*req.Thresholds)
+		thresholds = &value
+	}
+	input := services.ProgramTemplateUpdateInput{
+		Name:             req.Name,
+		IntervalType:     intervalType,
+		IntervalValue:    req.IntervalValue,
+		TolerancePercent: req.TolerancePercent,
+		Thresholds:       thresholds,
+	}
+	updated, err := servicesReg.ProgramTemplates.Update(r.Context(), actor, orgID, id, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapProgramTemplate(updated))
+}
+
+func DeleteProgramTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.ProgramTemplates.Delete(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func PreviewProgramTemplatePropagation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	changes, err := servicesReg.ProgramTemplates.PreviewPropagation(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapProgramTemplateChanges(changes))
+}
+
+func PropagateProgramTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ProgramTemplates == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template id")
+		return
+	}
+	var req programTemplatePropagateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	changes, err := servicesReg.ProgramTemplates.Propagate(r.Context(), actor, orgID, id, req.Revision)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapProgramTemplateChanges(changes))
+}
+
+func mapProgramTemplate(template domain.MaintenanceProgramTemplate) programTemplateResponse {
+	return programTemplateResponse{
+		ID:               template.ID,
+		OrgID:            template.OrgID,
+		AircraftTypeID:   template.AircraftTypeID,
+		Name:             template.Name,
+		IntervalType:     template.IntervalType,
+		IntervalValue:    template.IntervalValue,
+		TolerancePercent: template.TolerancePercent,
+		Thresholds:       mapProgramThresholds(template.Thresholds),
+		Revision:         template.Revision,
+		CreatedAt:        template.CreatedAt,
+		UpdatedAt:        template.UpdatedAt,
+	}
+}
+
+func mapProgramTemplateChanges(changes []domain.ProgramTemplateChange) []programTemplateChangeResponse {
+	resp := make([]programTemplateChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		fields := make([]programFieldChangeResponse, 0, len(change.Changes))
+		for _, field := range change.Changes {
+			from, to := field.From, field.To
+			if thresholds, ok := from.([]domain.ProgramThreshold); ok {
+				from = mapProgramThresholds(thresholds)
+			}
+			if thresholds, ok := to.([]domain.ProgramThreshold); ok {
+				to = mapProgramThresholds(thresholds)
+			}
+			fields = append(fields, programFieldChangeResponse{Field: field.Field, From: from, To: to})
+		}
+		resp = append(resp, programTemplateChangeResponse{
+			AircraftID:   change.AircraftID,
+			TailNumber:   change.TailNumber,
+			ProgramID:    change.ProgramID,
+			Action:       change.Action,
+			FromRevision: change.FromRevision,
+			ToRevision:   change.ToRevision,
+			Changes:      fields,
+		})
+	}
+	return resp
+}
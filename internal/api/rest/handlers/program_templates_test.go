@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+func seedTypedAircraft(t *testing.T, repo *fakeAircraftRepo, orgID uuid.UUID, tailNumber string, aircraftTypeID *uuid.UUID) domain.Aircraft {
+	t.Helper()
+	aircraft, err := repo.Create(context.Background(), domain.Aircraft{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		TailNumber:     tailNumber,
+		Model:          "A320",
+		AircraftTypeID: aircraftTypeID,
+		Status:         domain.AircraftOperational,
+		CapacitySlots:  2,
+	})
+	if err != nil {
+		t.Fatalf("seed aircraft: %v", err)
+	}
+	return aircraft
+}
+
+func TestCreateProgramTemplateInstantiatesFleet(t *testing.T) {
+	orgID := uuid.New()
+	aircraftTypeID := uuid.New()
+	otherTypeID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	programRepo := newFakeProgramRepo()
+	seedTypedAircraft(t, aircraftRepo, orgID, "N101", &aircraftTypeID)
+	seedTypedAircraft(t, aircraftRepo, orgID, "N102", &aircraftTypeID)
+	seedTypedAircraft(t, aircraftRepo, orgID, "N201", &otherTypeID)
+
+	templateRepo := newFakeProgramTemplateRepo()
+	templateRepo.programs = programRepo
+	templateService := &services.ProgramTemplateService{
+		Templates: templateRepo,
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+	}
+	registry := middleware.ServiceRegistry{ProgramTemplates: templateService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-program-templates", map[string]any{
+		"aircraft_type_id": aircraftTypeID.String(),
+		"name":             "A-Check",
+		"interval_type":    string(domain.ProgramIntervalFlightHours),
+		"interval_value":   600,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateProgramTemplate))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp programTemplateResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Revision != 1 {
+		t.Fatalf("expected revision 1, got %d", resp.Revision)
+	}
+	if len(programRepo.programs) != 2 {
+		t.Fatalf("expected 2 instantiated programs, got %d", len(programRepo.programs))
+	}
+	for _, program := range programRepo.programs {
+		if program.TemplateID == nil || *program.TemplateID != resp.ID {
+			t.Fatalf("expected program linked to template %s", resp.ID)
+		}
+		if program.TemplateRevision == nil || *program.TemplateRevision != 1 {
+			t.Fatalf("expected template revision 1, got %v", program.TemplateRevision)
+		}
+	}
+}
+
+func TestCreateAircraftInstantiatesProgramTemplates(t *testing.T) {
+	orgID := uuid.New()
+	aircraftTypeID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	programRepo := newFakeProgramRepo()
+	aircraftRepo.programs = programRepo
+	templateRepo := newFakeProgramTemplateRepo()
+	template, _ := templateRepo.Create(context.Background(), domain.MaintenanceProgramTemplate{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		AircraftTypeID: aircraftTypeID,
+		Name:           "Gear inspection",
+		IntervalType:   domain.ProgramIntervalCycles,
+		IntervalValue:  3000,
+		Revision:       1,
+	})
+
+	templateService := &services.ProgramTemplateService{
+		Templates: templateRepo,
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+	}
+	aircraftService := &services.AircraftService{Aircraft: aircraftRepo, Templates: templateService}
+	registry := middleware.ServiceRegistry{Aircraft: aircraftService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/aircraft", map[string]any{
+		"tail_number":      "N300AM",
+		"model":            "A320",
+		"aircraft_type_id": aircraftTypeID.String(),
+		"status":           string(domain.AircraftOperational),
+		"capacity_slots":   2,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateAircraft))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp aircraftResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.AircraftTypeID == nil || *resp.AircraftTypeID != aircraftTypeID {
+		t.Fatalf("expected aircraft type %s, got %v", aircraftTypeID, resp.AircraftTypeID)
+	}
+	if len(programRepo.programs) != 1 {
+		t.Fatalf("expected 1 instantiated program, got %d", len(programRepo.programs))
+	}
+	for _, program := range programRepo.programs {
+		if program.AircraftID == nil || *program.AircraftID != resp.ID {
+			t.Fatalf("expected program on aircraft %s", resp.ID)
+		}
+		if program.TemplateID == nil || *program.TemplateID != template.ID {
+			t.Fatalf("expected program linked to template %s", template.ID)
+		}
+	}
+}
+
+func TestProgramTemplatePropagation(t *testing.T) {
+	orgID := uuid.New()
+	aircraftTypeID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	programRepo := newFakeProgramRepo()
+	seedTypedAircraft(t, aircraftRepo, orgID, "N101", &aircraftTypeID)
+	seedTypedAircraft(t, aircraftRepo, orgID, "N102", &aircraftTypeID)
+
+	templateRepo := newFakeProgramTemplateRepo()
+	templateRepo.programs = programRepo
+	templateService := &services.ProgramTemplateService{
+		Templates: templateRepo,
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+	}
+	registry := middleware.ServiceRegistry{ProgramTemplates: templateService}
+
+	template, err := templateService.Create(context.Background(), app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleScheduler}, services.ProgramTemplateCreateInput{
+		AircraftTypeID: aircraftTypeID,
+		Name:           "A-Check",
+		IntervalType:   domain.ProgramIntervalFlightHours,
+		IntervalValue:  600,
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	req := newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-program-templates/"+template.ID.String(), map[string]any{
+		"interval_value": 750,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", template.ID.String())
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(UpdateProgramTemplate)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected update status 200, got %d", rr.Code)
+	}
+	var updated programTemplateResponse
+	if err := json.NewDecoder(rr.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode update: %v", err)
+	}
+	if updated.Revision != 2 {
+		t.Fatalf("expected revision 2, got %d", updated.Revision)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/maintenance-program-templates/"+template.ID.String()+"/propagation", nil)
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", template.ID.String())
+	rr = httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(PreviewProgramTemplatePropagation)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected preview status 200, got %d", rr.Code)
+	}
+	var preview []programTemplateChangeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&preview); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if len(preview) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(preview))
+	}
+	for _, change := range preview {
+		if change.Action != domain.ProgramTemplateChangeUpdate || change.ToRevision != 2 {
+			t.Fatalf("expected update to revision 2, got %s/%d", change.Action, change.ToRevision)
+		}
+		if len(change.Changes) != 1 || change.Changes[0].Field != "interval_value" {
+			t.Fatalf("expected interval_value change, got %+v", change.Changes)
+		}
+	}
+	if len(programRepo.programs) != 2 {
+		t.Fatalf("expected preview to leave programs untouched")
+	}
+	for _, program := range programRepo.programs {
+		if program.IntervalValue != 600 {
+			t.Fatalf("expected preview to leave interval 600, got %d", program.IntervalValue)
+		}
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-program-templates/"+template.ID.String()+"/propagation", map[string]any{
+		"revision": 1,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", template.ID.String())
+	rr = httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(PropagateProgramTemplate)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected stale revision status 409, got %d", rr.Code)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-program-templates/"+template.ID.String()+"/propagation", map[string]any{
+		"revision": 2,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", template.ID.String())
+	rr = httptest.NewRecorder()
+	middleware.InjectServices(registry)(http.HandlerFunc(PropagateProgramTemplate)).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected propagate status 200, got %d", rr.Code)
+	}
+	for _, program := range programRepo.programs {
+		if program.IntervalValue != 750 {
+			t.Fatalf("expected propagated interval 750, got %d", program.IntervalValue)
+		}
+		if program.TemplateRevision == nil || *program.TemplateRevision != 2 {
+			t.Fatalf("expected template revision 2, got %v", program.TemplateRevision)
+		}
+	}
+}
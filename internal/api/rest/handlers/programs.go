@@ -12,33 +12,92 @@ import (
 )
 
 type programCreateRequest struct {
-	OrgID         string `json:"org_id" validate:"omitempty,uuid"`
-	AircraftID    string `json:"aircraft_id" validate:"omitempty,uuid"`
-	Name          string `json:"name" validate:"required"`
-	IntervalType  string `json:"interval_type" validate:"required,oneof=flight_hours cycles calendar"`
-	IntervalValue int    `json:"interval_value" validate:"min=1"`
-	LastPerformed string `json:"last_performed" validate:"omitempty,rfc3339"`
+	OrgID               string                    `json:"org_id" validate:"omitempty,uuid"`
+	AircraftID          string                    `json:"aircraft_id" validate:"omitempty,uuid"`
+	Name                string                    `json:"name" validate:"required"`
+	IntervalType        string                    `json:"interval_type" validate:"required,oneof=flight_hours cycles calendar"`
+	IntervalValue       int                       `json:"interval_value" validate:"min=1"`
+	LastPerformed       string                    `json:"last_performed" validate:"omitempty,rfc3339"`
+	LastPerformedHours  *int                      `json:"last_performed_hours" validate:"omitempty,min=0"`
+	LastPerformedCycles *int                      `json:"last_performed_cycles" validate:"omitempty,min=0"`
+	TolerancePercent    int                       `json:"tolerance_percent" validate:"min=0,max=50"`
+	Thresholds          []programThresholdRequest `json:"thresholds" validate:"omitempty,max=2,dive"`
+	TaskType            string                    `json:"task_type" validate:"omitempty,oneof=inspection repair overhaul"`
+	Priority            string                    `json:"priority" validate:"omitempty,oneof=routine urgent aog critical"`
+	EstimatedDuration   *int                      `json:"estimated_duration_minutes" validate:"omitempty,min=1"`
+	ComplianceChecklist []string                  `json:"compliance_checklist" validate:"omitempty,max=50,dive,required"`
+	DefaultNotes        string                    `json:"default_notes"`
+}
+
+type programThresholdRequest struct {
+	IntervalType     string `json:"interval_type" validate:"required,oneof=flight_hours cycles calendar"`
+	IntervalValue    int    `json:"interval_value" validate:"min=1"`
+	TolerancePercent int    `json:"tolerance_percent" validate:"min=0,max=50"`
 }
 
 type programUpdateRequest struct {
-	OrgID         string  `json:"org_id" validate:"omitempty,uuid"`
-	AircraftID    *string `json:"aircraft_id" validate:"omitempty,uuid"`
-	Name          *string `json:"name" validate:"omitempty,min=1"`
-	IntervalType  *string `json:"interval_type" validate:"omitempty,oneof=flight_hours cycles calendar"`
-	IntervalValue *int    `json:"interval_value" validate:"omitempty,min=1"`
-	LastPerformed *string `json:"last_performed" validate:"omitempty,rfc3339"`
+	OrgID               string                     `json:"org_id" validate:"omitempty,uuid"`
+	AircraftID          *string                    `json:"aircraft_id" validate:"omitempty,uuid"`
+	Name                *string                    `json:"name" validate:"omitempty,min=1"`
+	IntervalType        *string                    `json:"interval_type" validate:"omitempty,oneof=flight_hours cycles calendar"`
+	IntervalValue       *int                       `json:"interval_value" validate:"omitempty,min=1"`
+	LastPerformed       *string                    `json:"last_performed" validate:"omitempty,rfc3339"`
+	LastPerformedHours  *int                       `json:"last_performed_hours" validate:"omitempty,min=0"`
+	LastPerformedCycles *int                       `json:"last_performed_cycles" validate:"omitempty,min=0"`
+	TolerancePercent    *int                       `json:"tolerance_percent" validate:"omitempty,min=0,max=50"`
+	Thresholds          *[]programThresholdRequest `json:"thresholds" validate:"omitempty,max=2,dive"`
+	TaskType            *string                    `json:"task_type" validate:"omitempty,oneof=inspection repair overhaul"`
+	Priority            *string                    `json:"priority" validate:"omitempty,oneof=routine urgent aog critical"`
+	EstimatedDuration   *int                       `json:"estimated_duration_minutes" validate:"omitempty,min=1"`
+	ComplianceChecklist *[]string                  `json:"compliance_checklist" validate:"omitempty,max=50,dive,required"`
+	DefaultNotes        *string                    `json:"default_notes"`
 }
 
 type programResponse struct {
-	ID            uuid.UUID                             `json:"id"`
-	OrgID         uuid.UUID                             `json:"org_id"`
-	AircraftID    *uuid.UUID                            `json:"aircraft_id,omitempty"`
-	Name          string                                `json:"name"`
+	ID                  uuid.UUID                             `json:"id"`
+	OrgID               uuid.UUID                             `json:"org_id"`
+	AircraftID          *uuid.UUID                            `json:"aircraft_id,omitempty"`
+	Name                string                                `json:"name"`
+	IntervalType        domain.MaintenanceProgramIntervalType `json:"interval_type"`
+	IntervalValue       int                                   `json:"interval_value"`
+	LastPerformed       *time.Time                            `json:"last_performed,omitempty"`
+	LastPerformedHours  *int                                  `json:"last_performed_hours,omitempty"`
+	LastPerformedCycles *int                                  `json:"last_performed_cycles,omitempty"`
+	TolerancePercent    int                                   `json:"tolerance_percent"`
+	Thresholds          []programThresholdResponse            `json:"thresholds"`
+	TemplateID          *uuid.UUID                            `json:"template_id,omitempty"`
+	TemplateRevision    *int                                  `json:"template_revision,omitempty"`
+	TaskType            domain.TaskType                       `json:"task_type"`
+	Priority            domain.TaskPriority                   `json:"priority"`
+	EstimatedDuration   *int                                  `json:"estimated_duration_minutes,omitempty"`
+	ComplianceChecklist []string                              `json:"compliance_checklist"`
+	DefaultNotes        string                                `json:"default_notes"`
+	CreatedAt           time.Time                             `json:"created_at"`
+	UpdatedAt           time.Time                             `json:"updated_at"`
+}
+
+type programThresholdResponse struct {
+	IntervalType     domain.MaintenanceProgramIntervalType `json:"interval_type"`
+	IntervalValue    int                                   `json:"interval_value"`
+	TolerancePercent int                                   `json:"tolerance_percent"`
+}
+
+type programForecastResponse struct {
+	ProgramID     uuid.UUID                             `json:"program_id"`
+	ProgramName   string                                `json:"program_name"`
+	AircraftID    uuid.UUID                             `json:"aircraft_id"`
+	TailNumber    string                                `json:"tail_number"`
 	IntervalType  domain.MaintenanceProgramIntervalType `json:"interval_type"`
 	IntervalValue int                                   `json:"interval_value"`
-	LastPerformed *time.Time                            `json:"last_performed,omitempty"`
-	CreatedAt     time.Time                             `json:"created_at"`
-	UpdatedAt     time.Time                             `json:"updated_at"`
+	Occurrence    int                                   `json:"occurrence"`
+	DueAt         time.Time                             `json:"due_at"`
+	WindowStart   time.Time                             `json:"window_start"`
+	WindowEnd     time.Time                             `json:"window_end"`
+	DueHours      *int                                  `json:"due_hours,omitempty"`
+	DueCycles     *int                                  `json:"due_cycles,omitempty"`
+	Overdue       bool                                  `json:"overdue"`
+	GroundMinutes *int                                  `json:"ground_minutes,omitempty"`
+	GroundWindow  *groundWindowResponse                 `json:"ground_window,omitempty"`
 }
 
 func CreateProgram(w http.ResponseWriter, r *http.Request) {
@@ -81,12 +140,21 @@ func CreateProgram(w http.ResponseWriter, r *http.Request) {
 		lastPerformed = &value
 	}
 	input := services.ProgramCreateInput{
-		OrgID:         &orgID,
-		AircraftID:    aircraftID,
-		Name:          req.Name,
-		IntervalType:  domain.MaintenanceProgramIntervalType(req.IntervalType),
-		IntervalValue: req.IntervalValue,
-		LastPerformed: lastPerformed,
+		OrgID:                    &orgID,
+		AircraftID:               aircraftID,
+		Name:                     req.Name,
+		IntervalType:             domain.MaintenanceProgramIntervalType(req.IntervalType),
+		IntervalValue:            req.IntervalValue,
+		LastPerformed:            lastPerformed,
+		LastPerformedHours:       req.LastPerformedHours,
+		LastPerformedCycles:      req.LastPerformedCycles,
+		TolerancePercent:         req.TolerancePercent,
+		Thresholds:               mapProgramThresholdRequests(req.Thresholds),
+		TaskType:                 domain.TaskType(req.TaskType),
+		Priority:                 domain.TaskPriority(req.Priority),
+		EstimatedDurationMinutes: req.EstimatedDuration,
+		ComplianceChecklist:      req.ComplianceChecklist,
+		DefaultNotes:             req.DefaultNotes,
 	}
 	created, err := servicesReg.Programs.Create(r.Context(), actor, input)
 	if err != nil {
@@ -127,6 +195,14 @@ func ListPrograms(w http.ResponseWriter, r *http.Request) {
 		}
 		filter.AircraftID = &id
 	}
+	if template := query.Get("template_id"); template != "" {
+		id, err := uuid.Parse(template)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid template_id")
+			return
+		}
+		filter.TemplateID = &id
+	}
 	if limit := query.Get("limit"); limit != "" {
 		value, err := parseInt(limit)
 		if err != nil {
@@ -156,6 +232,66 @@ func ListPrograms(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+func ForecastPrograms(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Programs == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	input := services.ProgramForecastInput{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			input.OrgID = &orgID
+		}
+	}
+	if aircraft := query.Get("aircraft_id"); aircraft != "" {
+		id, err := uuid.Parse(aircraft)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		input.AircraftID = &id
+	}
+	if horizon := query.Get("horizon_days"); horizon != "" {
+		value, err := parseInt(horizon)
+		if err != nil || value <= 0 {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid horizon_days")
+			return
+		}
+		input.HorizonDays = value
+	}
+	if occurrences := query.Get("occurrences"); occurrences != "" {
+		value, err := parseInt(occurrences)
+		if err != nil || value <= 0 {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid occurrences")
+			return
+		}
+		input.Occurrences = value
+	}
+
+	items, err := servicesReg.Programs.Forecast(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]programForecastResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, mapProgramForecast(item))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func GetProgram(w http.ResponseWriter, r *http.Request) {
 	actor, ok := actorFromRequest(r)
 	if !ok {
@@ -244,13 +380,37 @@ func UpdateProgram(w http.ResponseWriter, r *http.Request) {
 			lastPerformed = &value
 		}
 	}
+	var thresholds *[]domain.ProgramThreshold
+	if req.Thresholds != nil {
+		value := mapProgramThresholdRequests(*req.Thresholds)
+		thresholds = &value
+	}
 
+	var taskType *domain.TaskType
+	if req.TaskType != nil {
+		value := domain.TaskType(*req.TaskType)
+		taskType = &value
+	}
+	var priority *domain.TaskPriority
+	if req.Priority != nil {
+		value := domain.TaskPriority(*req.Priority)
+		priority = &value
+	}
 	input := services.ProgramUpdateInput{
-		AircraftID:    aircraftID,
-		Name:          req.Name,
-		IntervalType:  intervalType,
-		IntervalValue: req.IntervalValue,
-		LastPerformed: lastPerformed,
+		AircraftID:               aircraftID,
+		Name:                     req.Name,
+		IntervalType:             intervalType,
+		IntervalValue:            req.IntervalValue,
+		LastPerformed:            lastPerformed,
+		LastPerformedHours:       req.LastPerformedHours,
+		LastPerformedCycles:      req.LastPerformedCycles,
+		TolerancePercent:         req.TolerancePercent,
+		Thresholds:               thresholds,
+		TaskType:                 taskType,
+		Priority:                 priority,
+		EstimatedDurationMinutes: req.EstimatedDuration,
+		ComplianceChecklist:      req.ComplianceChecklist,
+		DefaultNotes:             req.DefaultNotes,
 	}
 	updated, err := servicesReg.Programs.Update(r.Context(), actor, orgID, id, input)
 	if err != nil {
@@ -295,15 +455,79 @@ func DeleteProgram(w http.ResponseWriter, r *http.Request) {
 }
 
 func mapProgram(program domain.MaintenanceProgram) programResponse {
+	thresholds := mapProgramThresholds(program.Thresholds)
+	checklist := program.ComplianceChecklist
+	if checklist == nil {
+		checklist = []string{}
+	}
 	return programResponse{
-		ID:            program.ID,
-		OrgID:         program.OrgID,
-		AircraftID:    program.AircraftID,
-		Name:          program.Name,
-		IntervalType:  program.IntervalType,
-		IntervalValue: program.IntervalValue,
-		LastPerformed: program.LastPerformed,
-		CreatedAt:     program.CreatedAt,
-		UpdatedAt:     program.UpdatedAt,
+		ID:                  program.ID,
+		OrgID:               program.OrgID,
+		AircraftID:          program.AircraftID,
+		Name:                program.Name,
+		IntervalType:        program.IntervalType,
+		IntervalValue:       program.IntervalValue,
+		LastPerformed:       program.LastPerformed,
+		LastPerformedHours:  program.LastPerformedHours,
+		LastPerformedCycles: program.LastPerformedCycles,
+		TolerancePercent:    program.TolerancePercent,
+		Thresholds:          thresholds,
+		TemplateID:          program.TemplateID,
+		TemplateRevision:    program.TemplateRevision,
+		TaskType:            program.GeneratedTaskType(),
+		Priority:            program.GeneratedTaskPriority(),
+		EstimatedDuration:   program.EstimatedDurationMinutes,
+		ComplianceChecklist: checklist,
+		DefaultNotes:        program.DefaultNotes,
+		CreatedAt:           program.CreatedAt,
+		UpdatedAt:           program.UpdatedAt,
 	}
 }
+
+func mapProgramThresholds(items []domain.ProgramThreshold) []programThresholdResponse {
+	thresholds := make([]programThresholdResponse, 0, len(items))
+	for _, threshold := range items {
+		thresholds = append(thresholds, programThresholdResponse{
+			IntervalType:     threshold.IntervalType,
+			IntervalValue:    threshold.IntervalValue,
+			TolerancePercent: threshold.TolerancePercent,
+		})
+	}
+	return thresholds
+}
+
+func mapProgramThresholdRequests(items []programThresholdRequest) []domain.ProgramThreshold {
+	thresholds := make([]domain.ProgramThreshold, 0, len(items))
+	for _, item := range items {
+		thresholds = append(thresholds, domain.ProgramThreshold{
+			IntervalType:     domain.MaintenanceProgramIntervalType(item.IntervalType),
+			IntervalValue:    item.IntervalValue,
+			TolerancePercent: item.TolerancePercent,
+		})
+	}
+	return thresholds
+}
+
+func mapProgramForecast(item domain.ProgramForecast) programForecastResponse {
+	resp := programForecastResponse{
+		ProgramID:     item.ProgramID,
+		ProgramName:   item.ProgramName,
+		AircraftID:    item.AircraftID,
+		TailNumber:    item.TailNumber,
+		IntervalType:  item.IntervalType,
+		IntervalValue: item.IntervalValue,
+		Occurrence:    item.Occurrence,
+		DueAt:         item.DueAt,
+		WindowStart:   item.WindowStart,
+		WindowEnd:     item.WindowEnd,
+		DueHours:      item.DueHours,
+		DueCycles:     item.DueCycles,
+		Overdue:       item.Overdue,
+		GroundMinutes: item.GroundMinutes,
+	}
+	if item.GroundWindow != nil {
+		window := mapGroundWindow(*item.GroundWindow)
+		resp.GroundWindow = &window
+	}
+	return resp
+}
@@ -65,6 +65,143 @@ func TestCreateProgramInvalidInterval(t *testing.T) {
 	}
 }
 
+func TestCreateProgramWithThresholds(t *testing.T) {
+	orgID := uuid.New()
+	programRepo := newFakeProgramRepo()
+	programService := &services.MaintenanceProgramService{Programs: programRepo}
+	registry := middleware.ServiceRegistry{Programs: programService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-programs", map[string]any{
+		"name":              "Gear inspection",
+		"interval_type":     string(domain.ProgramIntervalFlightHours),
+		"interval_value":    6000,
+		"tolerance_percent": 10,
+		"thresholds": []map[string]any{
+			{"interval_type": "cycles", "interval_value": 3000, "tolerance_percent": 5},
+			{"interval_type": "calendar", "interval_value": 730},
+		},
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateProgram))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp programResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TolerancePercent != 10 || len(resp.Thresholds) != 2 {
+		t.Fatalf("expected tolerance 10 and 2 thresholds, got %d and %d", resp.TolerancePercent, len(resp.Thresholds))
+	}
+	if resp.Thresholds[0].IntervalType != domain.ProgramIntervalCycles || resp.Thresholds[0].TolerancePercent != 5 {
+		t.Fatalf("unexpected threshold %+v", resp.Thresholds[0])
+	}
+}
+
+func TestCreateProgramWithTaskDefaults(t *testing.T) {
+	orgID := uuid.New()
+	programRepo := newFakeProgramRepo()
+	programService := &services.MaintenanceProgramService{Programs: programRepo}
+	registry := middleware.ServiceRegistry{Programs: programService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-programs", map[string]any{
+		"name":                       "Brake overhaul",
+		"interval_type":              string(domain.ProgramIntervalCycles),
+		"interval_value":             1500,
+		"task_type":                  "overhaul",
+		"priority":                   "urgent",
+		"estimated_duration_minutes": 360,
+		"compliance_checklist":       []string{"Measure wear pin", "Torque axle nut"},
+		"default_notes":              "Replace with overhauled unit",
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateProgram))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp programResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TaskType != domain.TaskTypeOverhaul || resp.Priority != domain.PriorityUrgent {
+		t.Fatalf("expected urgent overhaul, got %s %s", resp.Priority, resp.TaskType)
+	}
+	if resp.EstimatedDuration == nil || *resp.EstimatedDuration != 360 {
+		t.Fatalf("expected 360 minute duration, got %v", resp.EstimatedDuration)
+	}
+	if len(resp.ComplianceChecklist) != 2 || resp.DefaultNotes != "Replace with overhauled unit" {
+		t.Fatalf("unexpected checklist or notes: %v %q", resp.ComplianceChecklist, resp.DefaultNotes)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-programs", map[string]any{
+		"name":           "Cabin check",
+		"interval_type":  string(domain.ProgramIntervalCalendar),
+		"interval_value": 30,
+		"task_type":      "cleaning",
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for unknown task_type, got %d", rr.Code)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-programs", map[string]any{
+		"name":           "Cabin check",
+		"interval_type":  string(domain.ProgramIntervalCalendar),
+		"interval_value": 30,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var defaults programResponse
+	if err := json.NewDecoder(rr.Body).Decode(&defaults); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if defaults.TaskType != domain.TaskTypeInspection || defaults.Priority != domain.PriorityRoutine || defaults.EstimatedDuration != nil {
+		t.Fatalf("expected routine inspection defaults, got %+v", defaults)
+	}
+}
+
+func TestCreateProgramRejectsDuplicateThresholdType(t *testing.T) {
+	orgID := uuid.New()
+	programRepo := newFakeProgramRepo()
+	programService := &services.MaintenanceProgramService{Programs: programRepo}
+	registry := middleware.ServiceRegistry{Programs: programService}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-programs", map[string]any{
+		"name":           "Gear inspection",
+		"interval_type":  string(domain.ProgramIntervalCycles),
+		"interval_value": 3000,
+		"thresholds": []map[string]any{
+			{"interval_type": "cycles", "interval_value": 2500},
+		},
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateProgram))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if len(programRepo.programs) != 0 {
+		t.Fatalf("expected no programs, got %d", len(programRepo.programs))
+	}
+}
+
 func TestListPrograms(t *testing.T) {
 	orgID := uuid.New()
 	programRepo := newFakeProgramRepo()
@@ -226,3 +363,98 @@ func TestDeleteProgram(t *testing.T) {
 		t.Fatalf("expected deleted_at to be set")
 	}
 }
+
+func TestForecastPrograms(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	now := time.Now().UTC()
+	aircraftRepo := newFakeAircraftRepo()
+	programRepo := newFakeProgramRepo()
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N123AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 900,
+		CyclesTotal:      300,
+	})
+	for i := 9; i >= 0; i-- {
+		_, _ = utilizationRepo.Create(ctx, domain.AircraftUtilization{
+			ID:              uuid.New(),
+			OrgID:           orgID,
+			AircraftID:      aircraft.ID,
+			EntryType:       domain.UtilizationEntryDaily,
+			UtilizationDate: now.AddDate(0, 0, -i),
+			BlockHours:      10,
+			Cycles:          4,
+			HoursTotal:      float64(1000 - i*10),
+			CyclesTotal:     340 - i*4,
+		})
+	}
+	lastPerformed := now.AddDate(0, 0, -20)
+	lastHours := 800
+	calendar, _ := programRepo.Create(ctx, domain.MaintenanceProgram{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		AircraftID:    &aircraft.ID,
+		Name:          "Weekly check",
+		IntervalType:  domain.ProgramIntervalCalendar,
+		IntervalValue: 30,
+		LastPerformed: &lastPerformed,
+	})
+	hours, _ := programRepo.Create(ctx, domain.MaintenanceProgram{
+		ID:                 uuid.New(),
+		OrgID:              orgID,
+		AircraftID:         &aircraft.ID,
+		Name:               "Engine borescope",
+		IntervalType:       domain.ProgramIntervalFlightHours,
+		IntervalValue:      300,
+		LastPerformedHours: &lastHours,
+	})
+
+	programService := &services.MaintenanceProgramService{
+		Programs:    programRepo,
+		Aircraft:    aircraftRepo,
+		Utilization: utilizationRepo,
+	}
+	registry := middleware.ServiceRegistry{Programs: programService}
+
+	req := newJSONRequest(t, http.MethodGet, "/api/v1/maintenance-programs/forecast?horizon_days=90&occurrences=2&aircraft_id="+aircraft.ID.String(), nil)
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(ForecastPrograms))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp []programForecastResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	// Calendar: due in 10 and 40 days. Hours: 1100 due in 10 days at 10 FH/day,
+	// 1400 in 40 days.
+	if len(resp) != 4 {
+		t.Fatalf("expected 4 forecast entries, got %d", len(resp))
+	}
+	for i := 1; i < len(resp); i++ {
+		if resp[i].DueAt.Before(resp[i-1].DueAt) {
+			t.Fatalf("forecast not ordered by due time")
+		}
+	}
+	seen := map[uuid.UUID]int{}
+	for _, item := range resp {
+		seen[item.ProgramID]++
+		if item.ProgramID == hours.ID && item.Occurrence == 2 && (item.DueHours == nil || *item.DueHours != 1400) {
+			t.Fatalf("expected second hours occurrence at 1400, got %v", item.DueHours)
+		}
+	}
+	if seen[calendar.ID] != 2 || seen[hours.ID] != 2 {
+		t.Fatalf("expected two occurrences per program, got %v", seen)
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type rescheduleOptionsRequest struct {
+	OrgID            string `json:"org_id" validate:"omitempty,uuid"`
+	PartDefinitionID string `json:"part_definition_id" validate:"omitempty,uuid"`
+}
+
+type rescheduleOptionResponse struct {
+	ID               uuid.UUID                    `json:"id"`
+	OrgID            uuid.UUID                    `json:"org_id"`
+	TaskID           uuid.UUID                    `json:"task_id"`
+	ReservationID    *uuid.UUID                   `json:"reservation_id,omitempty"`
+	PartDefinitionID uuid.UUID                    `json:"part_definition_id"`
+	Rank             int                          `json:"rank"`
+	State            domain.RescheduleOptionState `json:"state"`
+	OptionType       domain.RescheduleOptionType  `json:"option_type"`
+	Description      string                       `json:"description"`
+	NewStartTime     *time.Time                   `json:"new_start_time,omitempty"`
+	NewEndTime       *time.Time                   `json:"new_end_time,omitempty"`
+	AffectedTaskIDs  []uuid.UUID                  `json:"affected_task_ids"`
+	SubstitutePartID *uuid.UUID                   `json:"substitute_part_id,omitempty"`
+	ScheduleChangeID *uuid.UUID                   `json:"schedule_change_id,omitempty"`
+	CreatedAt        time.Time                    `json:"created_at"`
+	AppliedAt        *time.Time                   `json:"applied_at,omitempty"`
+}
+
+// GenerateRescheduleOptions works out ranked options for a task short of a
+// part and replaces the task's earlier proposals with them. Without a
+// part_definition_id the task's reservations on items no longer in stock
+// are used.
+func GenerateRescheduleOptions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.RescheduleOptions == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	var req rescheduleOptionsRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	input := services.RescheduleOptionInput{OrgID: &orgID, TaskID: taskID}
+	if req.PartDefinitionID != "" {
+		defID, err := uuid.Parse(req.PartDefinitionID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid part_definition_id")
+			return
+		}
+		input.PartDefinitionID = &defID
+	}
+	options, err := servicesReg.RescheduleOptions.GenerateOptions(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapRescheduleOptions(options))
+}
+
+func ListRescheduleOptions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.RescheduleOptions == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	query := r.URL.Query()
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	var state *domain.RescheduleOptionState
+	if raw := query.Get("state"); raw != "" {
+		value := domain.RescheduleOptionState(raw)
+		switch value {
+		case domain.RescheduleOptionProposed, domain.RescheduleOptionApplied, domain.RescheduleOptionDiscarded:
+			state = &value
+		default:
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid state")
+			return
+		}
+	}
+	options, err := servicesReg.RescheduleOptions.ListOptions(r.Context(), actor, orgID, taskID, state)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapRescheduleOptions(options))
+}
+
+// ApplyRescheduleOption carries out a proposed option and returns the
+// schedule change recorded for it. It fails with 409 when the task or the
+// substitute part changed since the option was generated.
+func ApplyRescheduleOption(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.RescheduleOptions == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid option id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	event, err := servicesReg.RescheduleOptions.ApplyOption(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduleChangeResponse{
+		ID:              event.ID,
+		OrgID:           event.OrgID,
+		TaskID:          event.TaskID,
+		ChangeType:      event.ChangeType,
+		Reason:          event.Reason,
+		OldStartTime:    event.OldStartTime,
+		NewStartTime:    event.NewStartTime,
+		OldEndTime:      event.OldEndTime,
+		NewEndTime:      event.NewEndTime,
+		TriggeredBy:     event.TriggeredBy,
+		AffectedTaskIDs: event.AffectedTaskIDs,
+		CreatedAt:       event.CreatedAt,
+	})
+}
+
+func mapRescheduleOptions(options []domain.RescheduleOption) []rescheduleOptionResponse {
+	resp := make([]rescheduleOptionResponse, 0, len(options))
+	for _, option := range options {
+		affected := option.AffectedTasks
+		if affected == nil {
+			affected = []uuid.UUID{}
+		}
+		resp = append(resp, rescheduleOptionResponse{
+			ID:               option.ID,
+			OrgID:            option.OrgID,
+			TaskID:           option.TaskID,
+			ReservationID:    option.ReservationID,
+			PartDefinitionID: option.PartDefinitionID,
+			Rank:             option.Rank,
+			State:            option.State,
+			OptionType:       option.OptionType,
+			Description:      option.Description,
+			NewStartTime:     option.NewStartTime,
+			NewEndTime:       option.NewEndTime,
+			AffectedTaskIDs:  affected,
+			SubstitutePartID: option.SubstitutePart,
+			ScheduleChangeID: option.ScheduleChangeID,
+			CreatedAt:        option.CreatedAt,
+			AppliedAt:        option.AppliedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type rescheduleOptionFixture struct {
+	*schedulingFixture
+	defs    *fakePartDefinitionRepo
+	options *fakeRescheduleOptionRepo
+	part    domain.PartDefinition
+}
+
+func newRescheduleOptionFixture(t *testing.T) *rescheduleOptionFixture {
+	t.Helper()
+	f := &rescheduleOptionFixture{schedulingFixture: newSchedulingFixture(t), defs: newFakePartDefinitionRepo()}
+	f.options = newFakeRescheduleOptionRepo(f.taskRepo, f.reserved, f.changes)
+	leadTime := 3
+	f.part, _ = f.defs.Create(context.Background(), domain.PartDefinition{
+		ID:           uuid.New(),
+		OrgID:        f.orgID,
+		Name:         "Brake assembly",
+		Category:     "landing_gear",
+		LeadTimeDays: &leadTime,
+	})
+	f.registry.RescheduleOptions = &services.RescheduleOptionService{
+		Options:         f.options,
+		Tasks:           f.taskRepo,
+		Dependencies:    f.deps,
+		Reservations:    f.reserved,
+		PartItems:       f.parts,
+		PartDefinitions: f.defs,
+		Scheduling:      f.registry.Scheduling,
+		Capacity:        f.registry.Scheduling.Capacity,
+		Outbox:          f.outbox,
+	}
+	return f
+}
+
+// reserveUnavailable reserves an item of the fixture's part that is no
+// longer in stock for the task.
+func (f *rescheduleOptionFixture) reserveUnavailable(t *testing.T, task domain.MaintenanceTask) domain.PartReservation {
+	t.Helper()
+	item := f.addItem(t, f.part.ID, domain.PartItemUsed)
+	reservation := domain.PartReservation{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		TaskID:     task.ID,
+		PartItemID: item.ID,
+		State:      domain.ReservationReserved,
+		Quantity:   1,
+	}
+	_ = f.reserved.Create(context.Background(), reservation)
+	return reservation
+}
+
+func (f *rescheduleOptionFixture) addItem(t *testing.T, defID uuid.UUID, status domain.PartItemStatus) domain.PartItem {
+	t.Helper()
+	item, _ := f.parts.Create(context.Background(), domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        f.orgID,
+		DefinitionID: defID,
+		SerialNumber: "SN-" + uuid.NewString()[:8],
+		Status:       status,
+	})
+	return item
+}
+
+func (f *rescheduleOptionFixture) generate(t *testing.T, task domain.MaintenanceTask) []rescheduleOptionResponse {
+	t.Helper()
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks/"+task.ID.String()+"/reschedule-options", map[string]any{})
+	rr := f.serve(t, withRouteParam(req, "id", task.ID.String()), GenerateRescheduleOptions)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var options []rescheduleOptionResponse
+	if err := json.NewDecoder(rr.Body).Decode(&options); err != nil {
+		t.Fatalf("decode options: %v", err)
+	}
+	return options
+}
+
+func (f *rescheduleOptionFixture) apply(t *testing.T, option rescheduleOptionResponse, wantStatus int) scheduleChangeResponse {
+	t.Helper()
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/reschedule-options/"+option.ID.String()+"/apply", nil)
+	rr := f.serve(t, withRouteParam(req, "id", option.ID.String()), ApplyRescheduleOption)
+	if rr.Code != wantStatus {
+		t.Fatalf("expected status %d, got %d: %s", wantStatus, rr.Code, rr.Body.String())
+	}
+	var event scheduleChangeResponse
+	if wantStatus == http.StatusOK {
+		if err := json.NewDecoder(rr.Body).Decode(&event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+	}
+	return event
+}
+
+func optionOfType(t *testing.T, options []rescheduleOptionResponse, optionType domain.RescheduleOptionType) rescheduleOptionResponse {
+	t.Helper()
+	for _, option := range options {
+		if option.OptionType == optionType {
+			return option
+		}
+	}
+	t.Fatalf("no %s option in %+v", optionType, options)
+	return rescheduleOptionResponse{}
+}
+
+func TestGenerateRescheduleOptionsRanksByCompletion(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	f.reserveUnavailable(t, task)
+	f.addItem(t, f.part.ID, domain.PartItemInStock)
+
+	options := f.generate(t, task)
+	if len(options) != 3 {
+		t.Fatalf("expected substitute, split and delay options, got %+v", options)
+	}
+	want := []domain.RescheduleOptionType{domain.RescheduleOptionSubstitute, domain.RescheduleOptionSplit, domain.RescheduleOptionDelay}
+	for i, option := range options {
+		if option.OptionType != want[i] || option.Rank != i+1 {
+			t.Fatalf("option %d: expected %s at rank %d, got %s at rank %d", i, want[i], i+1, option.OptionType, option.Rank)
+		}
+		if option.State != domain.RescheduleOptionProposed || option.PartDefinitionID != f.part.ID {
+			t.Fatalf("unexpected option %+v", option)
+		}
+	}
+	if len(f.outbox.events) != 1 || f.outbox.events[0].EventType != "reschedule_options_generated" {
+		t.Fatalf("expected reschedule_options_generated outbox event, got %+v", f.outbox.events)
+	}
+}
+
+func TestGenerateRescheduleOptionsUsesAlternatePart(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	alternate, _ := f.defs.Create(context.Background(), domain.PartDefinition{
+		ID:       uuid.New(),
+		OrgID:    f.orgID,
+		Name:     "Brake assembly Mod B",
+		Category: "landing_gear",
+	})
+	f.part.AlternateIDs = []uuid.UUID{alternate.ID}
+	_, _ = f.defs.Update(context.Background(), f.part)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	f.reserveUnavailable(t, task)
+	item := f.addItem(t, alternate.ID, domain.PartItemInStock)
+
+	option := optionOfType(t, f.generate(t, task), domain.RescheduleOptionSubstitute)
+	if option.SubstitutePartID == nil || *option.SubstitutePartID != item.ID {
+		t.Fatalf("expected alternate item %s, got %+v", item.ID, option)
+	}
+}
+
+func TestApplyRescheduleOptionDelaysTask(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	f.reserveUnavailable(t, task)
+
+	options := f.generate(t, task)
+	delay := optionOfType(t, options, domain.RescheduleOptionDelay)
+	event := f.apply(t, delay, http.StatusOK)
+
+	if event.ChangeType != domain.ScheduleChangeRescheduled {
+		t.Fatalf("expected rescheduled change, got %s", event.ChangeType)
+	}
+	moved := f.task(t, task.ID)
+	if !moved.StartTime.Equal(*delay.NewStartTime) || !moved.EndTime.Equal(*delay.NewEndTime) {
+		t.Fatalf("expected task moved to %s-%s, got %s-%s", delay.NewStartTime, delay.NewEndTime, moved.StartTime, moved.EndTime)
+	}
+	history, _ := f.changes.ListByTask(context.Background(), f.orgID, task.ID)
+	if len(history) != 1 || history[0].ID != event.ID {
+		t.Fatalf("expected the applied change in history, got %+v", history)
+	}
+	stored, _ := f.options.ListByTask(context.Background(), f.orgID, task.ID, nil)
+	for _, option := range stored {
+		want := domain.RescheduleOptionDiscarded
+		if option.ID == delay.ID {
+			want = domain.RescheduleOptionApplied
+		}
+		if option.State != want {
+			t.Fatalf("expected option %s %s, got %s", option.OptionType, want, option.State)
+		}
+	}
+
+	// Applying again fails now that the option is no longer proposed.
+	f.apply(t, delay, http.StatusConflict)
+}
+
+func TestApplyRescheduleOptionSubstitutesPart(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	held := f.reserveUnavailable(t, task)
+	item := f.addItem(t, f.part.ID, domain.PartItemInStock)
+
+	substitute := optionOfType(t, f.generate(t, task), domain.RescheduleOptionSubstitute)
+	event := f.apply(t, substitute, http.StatusOK)
+
+	if event.ChangeType != domain.ScheduleChangePartSubstituted {
+		t.Fatalf("expected part_substituted change, got %s", event.ChangeType)
+	}
+	if unchanged := f.task(t, task.ID); !unchanged.StartTime.Equal(task.StartTime) {
+		t.Fatalf("expected task to keep its window, got %s", unchanged.StartTime)
+	}
+	reservations, _ := f.reserved.ListByTask(context.Background(), f.orgID, task.ID)
+	var reservedItem uuid.UUID
+	for _, reservation := range reservations {
+		switch {
+		case reservation.ID == held.ID && reservation.State != domain.ReservationReleased:
+			t.Fatalf("expected original reservation released, got %s", reservation.State)
+		case reservation.State == domain.ReservationReserved:
+			reservedItem = reservation.PartItemID
+		}
+	}
+	if reservedItem != item.ID {
+		t.Fatalf("expected substitute item %s reserved, got %s", item.ID, reservedItem)
+	}
+}
+
+func TestApplyRescheduleOptionRejectsChangedTask(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	f.reserveUnavailable(t, task)
+	delay := optionOfType(t, f.generate(t, task), domain.RescheduleOptionDelay)
+
+	if rr := f.reschedule(t, task, f.start.Add(time.Hour), f.start.Add(5*time.Hour)); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	f.apply(t, delay, http.StatusConflict)
+}
+
+func TestGenerateRescheduleOptionsDropsRosterConflicts(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, &f.mechanic)
+	f.reserveUnavailable(t, task)
+	f.addItem(t, f.part.ID, domain.PartItemInStock)
+	// The mechanic is away from before the part can be on hand until well
+	// after, so neither a delay nor a continuation task can keep them.
+	_, _ = f.absences.Create(context.Background(), domain.MechanicAbsence{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		MechanicID: f.mechanic,
+		Kind:       domain.AbsenceLeave,
+		StartTime:  f.start.Add(24 * time.Hour),
+		EndTime:    f.start.Add(10 * 24 * time.Hour),
+	})
+
+	options := f.generate(t, task)
+	if len(options) != 1 || options[0].OptionType != domain.RescheduleOptionSubstitute {
+		t.Fatalf("expected only the substitute option, got %+v", options)
+	}
+}
+
+func TestApplyRescheduleOptionRechecksRoster(t *testing.T) {
+	f := newRescheduleOptionFixture(t)
+	task := f.addTask(t, uuid.New(), f.start, 4*time.Hour, &f.mechanic)
+	f.reserveUnavailable(t, task)
+	delay := optionOfType(t, f.generate(t, task), domain.RescheduleOptionDelay)
+
+	_, _ = f.absences.Create(context.Background(), domain.MechanicAbsence{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		MechanicID: f.mechanic,
+		Kind:       domain.AbsenceLeave,
+		StartTime:  delay.NewStartTime.Add(-time.Hour),
+		EndTime:    delay.NewEndTime.Add(time.Hour),
+	})
+	f.apply(t, delay, http.StatusConflict)
+	if moved := f.task(t, task.ID); !moved.StartTime.Equal(task.StartTime) {
+		t.Fatalf("expected task to keep its window, got %s", moved.StartTime)
+	}
+}
@@ -0,0 +1,755 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var weekdayNames = [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type shiftPatternCreateRequest struct {
+	OrgID           string   `json:"org_id" validate:"omitempty,uuid"`
+	Name            string   `json:"name" validate:"required"`
+	Timezone        string   `json:"timezone"`
+	StartTime       string   `json:"start_time" validate:"required"`
+	DurationMinutes int      `json:"duration_minutes" validate:"required,min=1,max=1440"`
+	Weekdays        []string `json:"weekdays" validate:"required,min=1,dive,oneof=sun mon tue wed thu fri sat"`
+}
+
+type shiftPatternUpdateRequest struct {
+	OrgID           string    `json:"org_id" validate:"omitempty,uuid"`
+	Name            *string   `json:"name" validate:"omitempty,min=1"`
+	Timezone        *string   `json:"timezone" validate:"omitempty,min=1"`
+	StartTime       *string   `json:"start_time"`
+	DurationMinutes *int      `json:"duration_minutes" validate:"omitempty,min=1,max=1440"`
+	Weekdays        *[]string `json:"weekdays" validate:"omitempty,min=1,dive,oneof=sun mon tue wed thu fri sat"`
+}
+
+type rosterAssignmentCreateRequest struct {
+	OrgID          string  `json:"org_id" validate:"omitempty,uuid"`
+	MechanicID     string  `json:"mechanic_id" validate:"required,uuid"`
+	ShiftPatternID string  `json:"shift_pattern_id" validate:"required,uuid"`
+	StationID      *string `json:"station_id" validate:"omitempty,uuid"`
+	EffectiveFrom  string  `json:"effective_from" validate:"required,rfc3339"`
+	EffectiveTo    *string `json:"effective_to" validate:"omitempty,rfc3339"`
+}
+
+type mechanicAbsenceCreateRequest struct {
+	OrgID      string `json:"org_id" validate:"omitempty,uuid"`
+	MechanicID string `json:"mechanic_id" validate:"required,uuid"`
+	Kind       string `json:"kind" validate:"required,oneof=leave sick training other"`
+	StartTime  string `json:"start_time" validate:"required,rfc3339"`
+	EndTime    string `json:"end_time" validate:"required,rfc3339"`
+	Reason     string `json:"reason"`
+}
+
+type shiftPatternResponse struct {
+	ID              uuid.UUID `json:"id"`
+	OrgID           uuid.UUID `json:"org_id"`
+	Name            string    `json:"name"`
+	Timezone        string    `json:"timezone"`
+	StartTime       string    `json:"start_time"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Weekdays        []string  `json:"weekdays"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type rosterAssignmentResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	OrgID          uuid.UUID  `json:"org_id"`
+	MechanicID     uuid.UUID  `json:"mechanic_id"`
+	ShiftPatternID uuid.UUID  `json:"shift_pattern_id"`
+	StationID      *uuid.UUID `json:"station_id"`
+	EffectiveFrom  time.Time  `json:"effective_from"`
+	EffectiveTo    *time.Time `json:"effective_to"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type mechanicAbsenceResponse struct {
+	ID         uuid.UUID `json:"id"`
+	OrgID      uuid.UUID `json:"org_id"`
+	MechanicID uuid.UUID `json:"mechanic_id"`
+	Kind       string    `json:"kind"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type timeWindowResponse struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type mechanicAvailabilityResponse struct {
+	MechanicID  uuid.UUID            `json:"mechanic_id"`
+	Rostered    bool                 `json:"rostered"`
+	FreeWindows []timeWindowResponse `json:"free_windows"`
+}
+
+func CreateShiftPattern(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req shiftPatternCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	startMinute, err := parseClockTime(req.StartTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+		return
+	}
+	created, err := servicesReg.Roster.CreateShiftPattern(r.Context(), actor, services.ShiftPatternCreateInput{
+		OrgID:           &orgID,
+		Name:            req.Name,
+		Timezone:        req.Timezone,
+		StartMinute:     startMinute,
+		DurationMinutes: req.DurationMinutes,
+		Weekdays:        parseWeekdays(req.Weekdays),
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapShiftPattern(created))
+}
+
+func ListShiftPatterns(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.ShiftPatternFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	patterns, err := servicesReg.Roster.ListShiftPatterns(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]shiftPatternResponse, 0, len(patterns))
+	for _, pattern := range patterns {
+		resp = append(resp, mapShiftPattern(pattern))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetShiftPattern(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid shift pattern id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	pattern, err := servicesReg.Roster.GetShiftPattern(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapShiftPattern(pattern))
+}
+
+func UpdateShiftPattern(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid shift pattern id")
+		return
+	}
+	var req shiftPatternUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	if req.Name == nil && req.Timezone == nil && req.StartTime == nil && req.DurationMinutes == nil && req.Weekdays == nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "no changes provided")
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	input := services.ShiftPatternUpdateInput{
+		Name:            req.Name,
+		Timezone:        req.Timezone,
+		DurationMinutes: req.DurationMinutes,
+	}
+	if req.StartTime != nil {
+		startMinute, err := parseClockTime(*req.StartTime)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+			return
+		}
+		input.StartMinute = &startMinute
+	}
+	if req.Weekdays != nil {
+		weekdays := parseWeekdays(*req.Weekdays)
+		input.Weekdays = &weekdays
+	}
+	updated, err := servicesReg.Roster.UpdateShiftPattern(r.Context(), actor, orgID, id, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapShiftPattern(updated))
+}
+
+func DeleteShiftPattern(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid shift pattern id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Roster.DeleteShiftPattern(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func CreateRosterAssignment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req rosterAssignmentCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	mechanicID, _ := uuid.Parse(req.MechanicID)
+	patternID, _ := uuid.Parse(req.ShiftPatternID)
+	input := services.RosterAssignmentCreateInput{
+		OrgID:          &orgID,
+		MechanicID:     mechanicID,
+		ShiftPatternID: patternID,
+	}
+	if req.StationID != nil {
+		stationID, _ := uuid.Parse(*req.StationID)
+		input.StationID = &stationID
+	}
+	input.EffectiveFrom, err = time.Parse(time.RFC3339, req.EffectiveFrom)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid effective_from")
+		return
+	}
+	if req.EffectiveTo != nil {
+		effectiveTo, err := time.Parse(time.RFC3339, *req.EffectiveTo)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid effective_to")
+			return
+		}
+		input.EffectiveTo = &effectiveTo
+	}
+	created, err := servicesReg.Roster.CreateAssignment(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapRosterAssignment(created))
+}
+
+func ListRosterAssignments(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.RosterAssignmentFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if value := query.Get("mechanic_id"); value != "" {
+		mechanicID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+			return
+		}
+		filter.MechanicID = &mechanicID
+	}
+	if value := query.Get("station_id"); value != "" {
+		stationID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+			return
+		}
+		filter.StationID = &stationID
+	}
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+			return
+		}
+		filter.ActiveFrom = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+			return
+		}
+		filter.ActiveTo = &to
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	assignments, err := servicesReg.Roster.ListAssignments(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]rosterAssignmentResponse, 0, len(assignments))
+	for _, assignment := range assignments {
+		resp = append(resp, mapRosterAssignment(assignment))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func DeleteRosterAssignment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid roster assignment id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Roster.DeleteAssignment(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func CreateMechanicAbsence(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req mechanicAbsenceCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid start_time")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid end_time")
+		return
+	}
+	mechanicID, _ := uuid.Parse(req.MechanicID)
+	created, err := servicesReg.Roster.CreateAbsence(r.Context(), actor, services.MechanicAbsenceCreateInput{
+		OrgID:      &orgID,
+		MechanicID: mechanicID,
+		Kind:       domain.AbsenceKind(req.Kind),
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapMechanicAbsence(created))
+}
+
+func ListMechanicAbsences(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	var mechanicID *uuid.UUID
+	if value := query.Get("mechanic_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+			return
+		}
+		mechanicID = &parsed
+	}
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return
+	}
+	absences, err := servicesReg.Roster.ListAbsences(r.Context(), actor, orgID, mechanicID, from, to)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]mechanicAbsenceResponse, 0, len(absences))
+	for _, absence := range absences {
+		resp = append(resp, mapMechanicAbsence(absence))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func DeleteMechanicAbsence(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid absence id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.Roster.DeleteAbsence(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMechanicAvailability lists, per mechanic, the windows between from and
+// to in which they are on shift, not absent and not working another task.
+func GetMechanicAvailability(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Roster == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	availabilityQuery := services.MechanicAvailabilityQuery{OrgID: actor.OrgID}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			availabilityQuery.OrgID = parsed
+		}
+	}
+	for _, value := range query["mechanic_id"] {
+		mechanicID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid mechanic_id")
+			return
+		}
+		availabilityQuery.MechanicIDs = append(availabilityQuery.MechanicIDs, mechanicID)
+	}
+	if value := query.Get("station_id"); value != "" {
+		stationID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+			return
+		}
+		availabilityQuery.StationID = &stationID
+	}
+	var err error
+	availabilityQuery.From, err = time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return
+	}
+	availabilityQuery.To, err = time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return
+	}
+	availability, err := servicesReg.Roster.Availability(r.Context(), actor, availabilityQuery)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]mechanicAvailabilityResponse, 0, len(availability))
+	for _, entry := range availability {
+		windows := make([]timeWindowResponse, 0, len(entry.Free))
+		for _, window := range entry.Free {
+			windows = append(windows, timeWindowResponse{Start: window.Start.UTC(), End: window.End.UTC()})
+		}
+		resp = append(resp, mechanicAvailabilityResponse{
+			MechanicID:  entry.MechanicID,
+			Rostered:    entry.Rostered,
+			FreeWindows: windows,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseClockTime turns "HH:MM" into minutes after midnight.
+func parseClockTime(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func parseWeekdays(names []string) domain.WeekdaySet {
+	var set domain.WeekdaySet
+	for _, name := range names {
+		for day, weekday := range weekdayNames {
+			if name == weekday {
+				set |= domain.NewWeekdaySet(time.Weekday(day))
+			}
+		}
+	}
+	return set
+}
+
+func mapShiftPattern(pattern domain.ShiftPattern) shiftPatternResponse {
+	weekdays := make([]string, 0, 7)
+	for _, day := range pattern.Weekdays.Days() {
+		weekdays = append(weekdays, weekdayNames[day])
+	}
+	return shiftPatternResponse{
+		ID:              pattern.ID,
+		OrgID:           pattern.OrgID,
+		Name:            pattern.Name,
+		Timezone:        pattern.Timezone,
+		StartTime:       fmt.Sprintf("%02d:%02d", pattern.StartMinute/60, pattern.StartMinute%60),
+		DurationMinutes: pattern.DurationMinutes,
+		Weekdays:        weekdays,
+		CreatedAt:       pattern.CreatedAt,
+		UpdatedAt:       pattern.UpdatedAt,
+	}
+}
+
+func mapRosterAssignment(assignment domain.RosterAssignment) rosterAssignmentResponse {
+	return rosterAssignmentResponse{
+		ID:             assignment.ID,
+		OrgID:          assignment.OrgID,
+		MechanicID:     assignment.MechanicID,
+		ShiftPatternID: assignment.ShiftPatternID,
+		StationID:      assignment.StationID,
+		EffectiveFrom:  assignment.EffectiveFrom.UTC(),
+		EffectiveTo:    assignment.EffectiveTo,
+		CreatedAt:      assignment.CreatedAt,
+	}
+}
+
+func mapMechanicAbsence(absence domain.MechanicAbsence) mechanicAbsenceResponse {
+	return mechanicAbsenceResponse{
+		ID:         absence.ID,
+		OrgID:      absence.OrgID,
+		MechanicID: absence.MechanicID,
+		Kind:       string(absence.Kind),
+		StartTime:  absence.StartTime.UTC(),
+		EndTime:    absence.EndTime.UTC(),
+		Reason:     absence.Reason,
+		CreatedAt:  absence.CreatedAt,
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type rosterFixture struct {
+	orgID      uuid.UUID
+	mechanic   domain.User
+	unrostered domain.User
+	registry   middleware.ServiceRegistry
+	day        time.Time
+}
+
+// newRosterFixture rosters one mechanic on a daily 06:00-14:00 UTC shift
+// and leaves a second mechanic without a roster.
+func newRosterFixture(t *testing.T) *rosterFixture {
+	t.Helper()
+	orgID := uuid.New()
+	ctx := context.Background()
+	userRepo := newFakeUserRepo()
+	mechanic, _ := userRepo.Create(ctx, domain.User{ID: uuid.New(), OrgID: orgID, Email: "day@example.com", Role: domain.RoleMechanic})
+	unrostered, _ := userRepo.Create(ctx, domain.User{ID: uuid.New(), OrgID: orgID, Email: "any@example.com", Role: domain.RoleMechanic})
+	patternRepo := newFakeShiftPatternRepo()
+	pattern, _ := patternRepo.Create(ctx, domain.ShiftPattern{
+		ID:              uuid.New(),
+		OrgID:           orgID,
+		Name:            "Day",
+		Timezone:        "UTC",
+		StartMinute:     6 * 60,
+		DurationMinutes: 8 * 60,
+		Weekdays:        domain.AllWeekdays,
+	})
+	day := time.Now().UTC().Add(48 * time.Hour).Truncate(24 * time.Hour)
+	assignmentRepo := newFakeRosterAssignmentRepo()
+	_, _ = assignmentRepo.Create(ctx, domain.RosterAssignment{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		MechanicID:     mechanic.ID,
+		ShiftPatternID: pattern.ID,
+		EffectiveFrom:  day.Add(-7 * 24 * time.Hour),
+	})
+	taskRepo := newFakeTaskRepo()
+	rosterService := &services.RosterService{
+		Patterns:    patternRepo,
+		Assignments: assignmentRepo,
+		Absences:    newFakeMechanicAbsenceRepo(),
+		Users:       userRepo,
+		Stations:    newFakeStationRepo(),
+		Tasks:       taskRepo,
+	}
+	qualified := &fakeQualificationRepo{qualified: map[domain.TaskType][]uuid.UUID{
+		domain.TaskTypeInspection: {mechanic.ID, unrostered.ID},
+	}}
+	return &rosterFixture{
+		orgID:      orgID,
+		mechanic:   mechanic,
+		unrostered: unrostered,
+		registry: middleware.ServiceRegistry{
+			Tasks:          &services.TaskService{Tasks: taskRepo, Roster: rosterService},
+			Certifications: &services.CertificationService{Certs: qualified},
+			Roster:         rosterService,
+		},
+		day: day,
+	}
+}
+
+func (f *rosterFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *rosterFixture) createTask(t *testing.T, mechanicID uuid.UUID, start, end time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	body := map[string]any{
+		"aircraft_id":          uuid.New().String(),
+		"type":                 string(domain.TaskTypeInspection),
+		"start_time":           start.Format(time.RFC3339),
+		"end_time":             end.Format(time.RFC3339),
+		"assigned_mechanic_id": mechanicID.String(),
+	}
+	return f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks", body), CreateTask, nil)
+}
+
+func TestCreateTaskRejectsMechanicOffShift(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.createTask(t, f.mechanic.ID, f.day.Add(12*time.Hour), f.day.Add(16*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 past the end of the shift, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "mechanic_unavailable" {
+		t.Fatalf("expected code mechanic_unavailable, got %s", code)
+	}
+
+	rr = f.createTask(t, f.mechanic.ID, f.day.Add(8*time.Hour), f.day.Add(12*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 within the shift, got %d", rr.Code)
+	}
+
+	rr = f.createTask(t, f.unrostered.ID, f.day.Add(20*time.Hour), f.day.Add(23*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 for a mechanic without a roster, got %d", rr.Code)
+	}
+}
+
+func TestCreateTaskRejectsMechanicDoubleBooking(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.createTask(t, f.mechanic.ID, f.day.Add(8*time.Hour), f.day.Add(10*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	rr = f.createTask(t, f.mechanic.ID, f.day.Add(9*time.Hour), f.day.Add(11*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for overlapping work, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "mechanic_overlap" {
+		t.Fatalf("expected code mechanic_overlap, got %s", code)
+	}
+	rr = f.createTask(t, f.mechanic.ID, f.day.Add(10*time.Hour), f.day.Add(12*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 back to back, got %d", rr.Code)
+	}
+}
+
+func TestCreateTaskRejectsMechanicOnLeave(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/mechanic-absences", map[string]any{
+		"mechanic_id": f.unrostered.ID.String(),
+		"kind":        "leave",
+		"start_time":  f.day.Format(time.RFC3339),
+		"end_time":    f.day.Add(24 * time.Hour).Format(time.RFC3339),
+	}), CreateMechanicAbsence, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+
+	rr = f.createTask(t, f.unrostered.ID, f.day.Add(8*time.Hour), f.day.Add(10*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 during leave, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "mechanic_unavailable" {
+		t.Fatalf("expected code mechanic_unavailable, got %s", code)
+	}
+}
+
+func TestUpdateTaskRejectsMoveOffShift(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.createTask(t, f.mechanic.ID, f.day.Add(8*time.Hour), f.day.Add(10*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var created taskResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	rr = f.serve(t, newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-tasks/"+created.ID.String(), map[string]any{
+		"start_time": f.day.Add(18 * time.Hour).Format(time.RFC3339),
+		"end_time":   f.day.Add(20 * time.Hour).Format(time.RFC3339),
+	}), UpdateTask, map[string]string{"id": created.ID.String()})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "mechanic_unavailable" {
+		t.Fatalf("expected code mechanic_unavailable, got %s", code)
+	}
+}
+
+func TestCreateShiftPatternRoundTripsWeekdays(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/shift-patterns", map[string]any{
+		"name":             "Night",
+		"timezone":         "Europe/Berlin",
+		"start_time":       "22:00",
+		"duration_minutes": 600,
+		"weekdays":         []string{"fri", "mon"},
+	}), CreateShiftPattern, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp shiftPatternResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.StartTime != "22:00" || len(resp.Weekdays) != 2 || resp.Weekdays[0] != "mon" || resp.Weekdays[1] != "fri" {
+		t.Fatalf("unexpected pattern %+v", resp)
+	}
+
+	rr = f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/shift-patterns", map[string]any{
+		"name":             "Broken",
+		"start_time":       "25:00",
+		"duration_minutes": 60,
+		"weekdays":         []string{"mon"},
+	}), CreateShiftPattern, nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an invalid start time, got %d", rr.Code)
+	}
+}
+
+func TestGetMechanicAvailability(t *testing.T) {
+	f := newRosterFixture(t)
+
+	rr := f.createTask(t, f.mechanic.ID, f.day.Add(8*time.Hour), f.day.Add(10*time.Hour))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+
+	query := url.Values{}
+	query.Set("from", f.day.Format(time.RFC3339))
+	query.Set("to", f.day.Add(24*time.Hour).Format(time.RFC3339))
+	query.Add("mechanic_id", f.mechanic.ID.String())
+	rr = f.serve(t, newJSONRequest(t, http.MethodGet, "/api/v1/mechanics/availability?"+query.Encode(), nil), GetMechanicAvailability, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp []mechanicAvailabilityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || !resp[0].Rostered {
+		t.Fatalf("expected one rostered mechanic, got %+v", resp)
+	}
+	want := []timeWindowResponse{
+		{Start: f.day.Add(6 * time.Hour), End: f.day.Add(8 * time.Hour)},
+		{Start: f.day.Add(10 * time.Hour), End: f.day.Add(14 * time.Hour)},
+	}
+	got := resp[0].FreeWindows
+	if len(got) != len(want) {
+		t.Fatalf("expected %d free windows, got %+v", len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Fatalf("window %d: expected %v-%v, got %v-%v", i, want[i].Start, want[i].End, got[i].Start, got[i].End)
+		}
+	}
+}
+
+func TestGetQualifiedMechanicsFiltersByAvailability(t *testing.T) {
+	f := newRosterFixture(t)
+
+	query := url.Values{}
+	query.Set("task_type", string(domain.TaskTypeInspection))
+	rr := f.serve(t, newJSONRequest(t, http.MethodGet, "/api/v1/qualified-mechanics?"+query.Encode(), nil), GetQualifiedMechanics, nil)
+	var resp struct {
+		MechanicIDs []uuid.UUID `json:"mechanic_ids"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.MechanicIDs) != 2 {
+		t.Fatalf("expected both mechanics without a window, got %v", resp.MechanicIDs)
+	}
+
+	query.Set("from", f.day.Add(16*time.Hour).Format(time.RFC3339))
+	query.Set("to", f.day.Add(18*time.Hour).Format(time.RFC3339))
+	rr = f.serve(t, newJSONRequest(t, http.MethodGet, "/api/v1/qualified-mechanics?"+query.Encode(), nil), GetQualifiedMechanics, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.MechanicIDs) != 1 || resp.MechanicIDs[0] != f.unrostered.ID {
+		t.Fatalf("expected only the unrostered mechanic off shift, got %v", resp.MechanicIDs)
+	}
+}
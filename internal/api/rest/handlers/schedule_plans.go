@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type schedulePlanCreateRequest struct {
+	OrgID     string   `json:"org_id" validate:"omitempty,uuid"`
+	TaskIDs   []string `json:"task_ids" validate:"omitempty,dive,uuid"`
+	From      string   `json:"from" validate:"required,rfc3339"`
+	To        string   `json:"to" validate:"required,rfc3339"`
+	StationID string   `json:"station_id" validate:"omitempty,uuid"`
+}
+
+type schedulePlanItemResponse struct {
+	TaskID        uuid.UUID           `json:"task_id"`
+	AircraftID    uuid.UUID           `json:"aircraft_id"`
+	Priority      domain.TaskPriority `json:"priority"`
+	Placed        bool                `json:"placed"`
+	OldStartTime  time.Time           `json:"old_start_time"`
+	OldEndTime    time.Time           `json:"old_end_time"`
+	OldBayID      *uuid.UUID          `json:"old_bay_id,omitempty"`
+	OldMechanicID *uuid.UUID          `json:"old_mechanic_id,omitempty"`
+	StartTime     *time.Time          `json:"start_time,omitempty"`
+	EndTime       *time.Time          `json:"end_time,omitempty"`
+	BayID         *uuid.UUID          `json:"bay_id,omitempty"`
+	BaySlot       *int                `json:"bay_slot,omitempty"`
+	MechanicID    *uuid.UUID          `json:"mechanic_id,omitempty"`
+	Reason        string              `json:"reason,omitempty"`
+}
+
+type schedulePlanResponse struct {
+	ID           uuid.UUID                  `json:"id"`
+	OrgID        uuid.UUID                  `json:"org_id"`
+	State        domain.SchedulePlanState   `json:"state"`
+	HorizonStart time.Time                  `json:"horizon_start"`
+	HorizonEnd   time.Time                  `json:"horizon_end"`
+	StationID    *uuid.UUID                 `json:"station_id,omitempty"`
+	CreatedBy    uuid.UUID                  `json:"created_by"`
+	Items        []schedulePlanItemResponse `json:"items,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+	AppliedAt    *time.Time                 `json:"applied_at,omitempty"`
+}
+
+// CreateSchedulePlan runs the optimizer and returns the proposed plan for
+// review. Tasks are not changed until the plan is applied.
+func CreateSchedulePlan(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ScheduleOptimizer == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req schedulePlanCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, req.To)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+		return
+	}
+	taskIDs := make([]uuid.UUID, 0, len(req.TaskIDs))
+	for _, raw := range req.TaskIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task_ids")
+			return
+		}
+		taskIDs = append(taskIDs, id)
+	}
+	var stationID *uuid.UUID
+	if req.StationID != "" {
+		parsed, err := uuid.Parse(req.StationID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid station_id")
+			return
+		}
+		stationID = &parsed
+	}
+	plan, err := servicesReg.ScheduleOptimizer.Propose(r.Context(), actor, services.ScheduleOptimizeInput{
+		OrgID:     &orgID,
+		TaskIDs:   taskIDs,
+		From:      from,
+		To:        to,
+		StationID: stationID,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapSchedulePlan(plan))
+}
+
+func ListSchedulePlans(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ScheduleOptimizer == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.SchedulePlanFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if state := query.Get("state"); state != "" {
+		value := domain.SchedulePlanState(state)
+		switch value {
+		case domain.SchedulePlanProposed, domain.SchedulePlanApplied, domain.SchedulePlanDiscarded:
+			filter.State = &value
+		default:
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid state")
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	plans, err := servicesReg.ScheduleOptimizer.ListPlans(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]schedulePlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		resp = append(resp, mapSchedulePlan(plan))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetSchedulePlan(w http.ResponseWriter, r *http.Request) {
+	schedulePlanAction(w, r, func(svc *services.ScheduleOptimizerService, r *http.Request, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+		actor, _ := actorFromRequest(r)
+		return svc.GetPlan(r.Context(), actor, orgID, id)
+	})
+}
+
+// ApplySchedulePlan writes a proposed plan onto its tasks. The whole plan
+// is rejected with 409 when any task changed since it was proposed.
+func ApplySchedulePlan(w http.ResponseWriter, r *http.Request) {
+	schedulePlanAction(w, r, func(svc *services.ScheduleOptimizerService, r *http.Request, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+		actor, _ := actorFromRequest(r)
+		return svc.ApplyPlan(r.Context(), actor, orgID, id)
+	})
+}
+
+func DiscardSchedulePlan(w http.ResponseWriter, r *http.Request) {
+	schedulePlanAction(w, r, func(svc *services.ScheduleOptimizerService, r *http.Request, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+		actor, _ := actorFromRequest(r)
+		return svc.DiscardPlan(r.Context(), actor, orgID, id)
+	})
+}
+
+func schedulePlanAction(w http.ResponseWriter, r *http.Request, action func(*services.ScheduleOptimizerService, *http.Request, uuid.UUID, uuid.UUID) (domain.SchedulePlan, error)) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.ScheduleOptimizer == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid plan id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	plan, err := action(servicesReg.ScheduleOptimizer, r, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSchedulePlan(plan))
+}
+
+func mapSchedulePlan(plan domain.SchedulePlan) schedulePlanResponse {
+	resp := schedulePlanResponse{
+		ID:           plan.ID,
+		OrgID:        plan.OrgID,
+		State:        plan.State,
+		HorizonStart: plan.HorizonStart.UTC(),
+		HorizonEnd:   plan.HorizonEnd.UTC(),
+		StationID:    plan.StationID,
+		CreatedBy:    plan.CreatedBy,
+		CreatedAt:    plan.CreatedAt,
+		UpdatedAt:    plan.UpdatedAt,
+		AppliedAt:    plan.AppliedAt,
+	}
+	for _, item := range plan.Items {
+		resp.Items = append(resp.Items, schedulePlanItemResponse{
+			TaskID:        item.TaskID,
+			AircraftID:    item.AircraftID,
+			Priority:      item.Priority,
+			Placed:        item.Placed(),
+			OldStartTime:  item.OldStartTime.UTC(),
+			OldEndTime:    item.OldEndTime.UTC(),
+			OldBayID:      item.OldBayID,
+			OldMechanicID: item.OldMechanicID,
+			StartTime:     item.StartTime,
+			EndTime:       item.EndTime,
+			BayID:         item.BayID,
+			BaySlot:       item.BaySlot,
+			MechanicID:    item.MechanicID,
+			Reason:        item.Reason,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type schedulePlanFixture struct {
+	orgID    uuid.UUID
+	taskRepo *fakeTaskRepo
+	deps     *fakeTaskDependencyRepo
+	certs    *fakeQualificationRepo
+	parts    *fakePartItemRepo
+	defs     *fakePartDefinitionRepo
+	reserved *fakePartReservationRepo
+	station  domain.Station
+	bay      domain.HangarBay
+	registry middleware.ServiceRegistry
+	start    time.Time
+}
+
+func newSchedulePlanFixture(t *testing.T, bayCapacity int) *schedulePlanFixture {
+	t.Helper()
+	orgID := uuid.New()
+	taskRepo := newFakeTaskRepo()
+	stationRepo := newFakeStationRepo()
+	bayRepo := newFakeHangarBayRepo()
+	station, _ := stationRepo.Create(context.Background(), domain.Station{
+		ID:       uuid.New(),
+		OrgID:    orgID,
+		Code:     "HAM",
+		Name:     "Hamburg",
+		Timezone: "Europe/Berlin",
+	})
+	bay, _ := bayRepo.Create(context.Background(), domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		StationID:     station.ID,
+		Code:          "B1",
+		Name:          "Bay 1",
+		CapacitySlots: bayCapacity,
+	})
+	capacityService := &services.CapacityService{
+		Stations: stationRepo,
+		Bays:     bayRepo,
+		Windows:  newFakeBayCapacityWindowRepo(),
+		Tasks:    taskRepo,
+	}
+	f := &schedulePlanFixture{
+		orgID:    orgID,
+		taskRepo: taskRepo,
+		deps:     newFakeTaskDependencyRepo(),
+		certs: &fakeQualificationRepo{qualified: map[domain.TaskType][]uuid.UUID{
+			domain.TaskTypeInspection: {uuid.New(), uuid.New()},
+		}},
+		parts:    newFakePartItemRepo(),
+		defs:     newFakePartDefinitionRepo(),
+		reserved: newFakePartReservationRepo(),
+		station:  station,
+		bay:      bay,
+		start:    time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC(),
+	}
+	optimizer := &services.ScheduleOptimizerService{
+		Plans:           newFakeSchedulePlanRepo(taskRepo),
+		Tasks:           taskRepo,
+		Dependencies:    f.deps,
+		Aircraft:        newFakeAircraftRepo(),
+		Certs:           f.certs,
+		Reservations:    f.reserved,
+		PartItems:       f.parts,
+		PartDefinitions: f.defs,
+		Capacity:        capacityService,
+		Outbox:          &fakeOutboxRepo{},
+	}
+	f.registry = middleware.ServiceRegistry{Capacity: capacityService, ScheduleOptimizer: optimizer}
+	return f
+}
+
+func (f *schedulePlanFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *schedulePlanFixture) addTask(t *testing.T, priority domain.TaskPriority, start time.Time, duration time.Duration, mechanicID *uuid.UUID) domain.MaintenanceTask {
+	t.Helper()
+	task, _ := f.taskRepo.Create(context.Background(), domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              f.orgID,
+		AircraftID:         uuid.New(),
+		Type:               domain.TaskTypeInspection,
+		State:              domain.TaskStateScheduled,
+		Priority:           priority,
+		StartTime:          start,
+		EndTime:            start.Add(duration),
+		AssignedMechanicID: mechanicID,
+		CreatedAt:          time.Now().UTC(),
+		UpdatedAt:          time.Now().UTC(),
+	})
+	return task
+}
+
+func (f *schedulePlanFixture) propose(t *testing.T, body map[string]any) schedulePlanResponse {
+	t.Helper()
+	if _, ok := body["from"]; !ok {
+		body["from"] = f.start.Format(time.RFC3339)
+	}
+	if _, ok := body["to"]; !ok {
+		body["to"] = f.start.Add(7 * 24 * time.Hour).Format(time.RFC3339)
+	}
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/plans", body), CreateSchedulePlan, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var plan schedulePlanResponse
+	if err := json.NewDecoder(rr.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if plan.State != domain.SchedulePlanProposed {
+		t.Fatalf("expected proposed plan, got %s", plan.State)
+	}
+	return plan
+}
+
+func planItem(t *testing.T, plan schedulePlanResponse, taskID uuid.UUID) schedulePlanItemResponse {
+	t.Helper()
+	for _, item := range plan.Items {
+		if item.TaskID == taskID {
+			return item
+		}
+	}
+	t.Fatalf("task %s missing from plan", taskID)
+	return schedulePlanItemResponse{}
+}
+
+func TestSchedulePlanOrdersByPriorityWithinBayCapacity(t *testing.T) {
+	f := newSchedulePlanFixture(t, 1)
+	routine := f.addTask(t, domain.PriorityRoutine, f.start.Add(2*time.Hour), 4*time.Hour, nil)
+	aog := f.addTask(t, domain.PriorityAOG, f.start.Add(2*time.Hour), 4*time.Hour, nil)
+
+	plan := f.propose(t, map[string]any{"station_id": f.station.ID.String()})
+	if len(plan.Items) != 2 {
+		t.Fatalf("expected both unassigned tasks in the plan, got %d", len(plan.Items))
+	}
+	first := planItem(t, plan, aog.ID)
+	second := planItem(t, plan, routine.ID)
+	if !first.Placed || !second.Placed {
+		t.Fatalf("expected both tasks placed, got %+v %+v", first, second)
+	}
+	if !first.StartTime.Equal(f.start) {
+		t.Fatalf("expected aog task at horizon start, got %s", first.StartTime)
+	}
+	if second.StartTime.Before(*first.EndTime) {
+		t.Fatalf("expected routine task after aog task in the single slot, got %s", second.StartTime)
+	}
+	for _, item := range []schedulePlanItemResponse{first, second} {
+		if item.BayID == nil || *item.BayID != f.bay.ID || item.BaySlot == nil || *item.BaySlot != 1 {
+			t.Fatalf("expected bay %s slot 1, got %v %v", f.bay.ID, item.BayID, item.BaySlot)
+		}
+	}
+}
+
+func TestSchedulePlanRespectsDependenciesAndQualifications(t *testing.T) {
+	f := newSchedulePlanFixture(t, 2)
+	qualified := uuid.New()
+	unqualified := uuid.New()
+	f.certs.qualified[domain.TaskTypeInspection] = []uuid.UUID{qualified}
+	prereq := f.addTask(t, domain.PriorityRoutine, f.start, 4*time.Hour, &qualified)
+	dependent := f.addTask(t, domain.PriorityCritical, f.start, 2*time.Hour, &unqualified)
+	_, _ = f.deps.Create(context.Background(), domain.TaskDependency{
+		ID:              uuid.New(),
+		OrgID:           f.orgID,
+		TaskID:          dependent.ID,
+		DependsOnTaskID: prereq.ID,
+		DependencyType:  domain.DependencyFinishToStart,
+	})
+
+	plan := f.propose(t, map[string]any{"task_ids": []string{prereq.ID.String(), dependent.ID.String()}})
+	first := planItem(t, plan, prereq.ID)
+	second := planItem(t, plan, dependent.ID)
+	if !first.Placed || !second.Placed {
+		t.Fatalf("expected both tasks placed, got %+v %+v", first, second)
+	}
+	if second.StartTime.Before(*first.EndTime) {
+		t.Fatalf("expected dependent task after %s, got %s", first.EndTime, second.StartTime)
+	}
+	if second.MechanicID == nil || *second.MechanicID != qualified {
+		t.Fatalf("expected qualified mechanic %s, got %v", qualified, second.MechanicID)
+	}
+}
+
+func TestSchedulePlanWaitsForPartLeadTime(t *testing.T) {
+	f := newSchedulePlanFixture(t, 1)
+	leadDays := 3
+	def, _ := f.defs.Create(context.Background(), domain.PartDefinition{ID: uuid.New(), OrgID: f.orgID, Name: "Brake", LeadTimeDays: &leadDays})
+	item, _ := f.parts.Create(context.Background(), domain.PartItem{ID: uuid.New(), OrgID: f.orgID, DefinitionID: def.ID, SerialNumber: "BRK-1", Status: domain.PartItemUsed})
+	mechanicID := uuid.New()
+	task := f.addTask(t, domain.PriorityUrgent, f.start, 2*time.Hour, &mechanicID)
+	_ = f.reserved.Create(context.Background(), domain.PartReservation{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		TaskID:     task.ID,
+		PartItemID: item.ID,
+		State:      domain.ReservationReserved,
+		Quantity:   1,
+	})
+
+	plan := f.propose(t, map[string]any{})
+	got := planItem(t, plan, task.ID)
+	if !got.Placed {
+		t.Fatalf("expected task placed, got reason %q", got.Reason)
+	}
+	if got.StartTime.Before(time.Now().Add(time.Duration(leadDays) * 24 * time.Hour).Add(-time.Minute)) {
+		t.Fatalf("expected start after part lead time, got %s", got.StartTime)
+	}
+}
+
+func TestApplySchedulePlanUpdatesTasksOnce(t *testing.T) {
+	f := newSchedulePlanFixture(t, 1)
+	task := f.addTask(t, domain.PriorityRoutine, f.start.Add(6*time.Hour), 2*time.Hour, nil)
+	plan := f.propose(t, map[string]any{"station_id": f.station.ID.String()})
+
+	params := map[string]string{"id": plan.ID.String()}
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/plans/"+plan.ID.String()+"/apply", nil), ApplySchedulePlan, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	stored, _ := f.taskRepo.GetByID(context.Background(), f.orgID, task.ID)
+	if !stored.StartTime.Equal(f.start) || stored.BayID == nil || *stored.BayID != f.bay.ID {
+		t.Fatalf("expected task moved into bay at horizon start, got %s %v", stored.StartTime, stored.BayID)
+	}
+
+	rr = f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/plans/"+plan.ID.String()+"/apply", nil), ApplySchedulePlan, params)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for applied plan, got %d", rr.Code)
+	}
+}
+
+func TestApplySchedulePlanRejectsStalePlan(t *testing.T) {
+	f := newSchedulePlanFixture(t, 1)
+	task := f.addTask(t, domain.PriorityRoutine, f.start.Add(6*time.Hour), 2*time.Hour, nil)
+	plan := f.propose(t, map[string]any{"station_id": f.station.ID.String()})
+
+	task.Notes = "edited after planning"
+	task.UpdatedAt = time.Now().Add(time.Minute).UTC()
+	_, _ = f.taskRepo.Update(context.Background(), task)
+
+	params := map[string]string{"id": plan.ID.String()}
+	rr := f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/plans/"+plan.ID.String()+"/apply", nil), ApplySchedulePlan, params)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for stale plan, got %d", rr.Code)
+	}
+	stored, _ := f.taskRepo.GetByID(context.Background(), f.orgID, task.ID)
+	if stored.BayID != nil {
+		t.Fatalf("expected task untouched, got bay %v", stored.BayID)
+	}
+
+	rr = f.serve(t, newJSONRequest(t, http.MethodPost, "/api/v1/scheduling/plans/"+plan.ID.String()+"/discard", nil), DiscardSchedulePlan, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for discard, got %d", rr.Code)
+	}
+}
+
+func TestSchedulePlanWaitsForMechanicShift(t *testing.T) {
+	f := newSchedulePlanFixture(t, 1)
+	mechanic := uuid.New()
+	f.certs.qualified[domain.TaskTypeInspection] = []uuid.UUID{mechanic}
+	shiftStart := f.start.Add(4 * time.Hour)
+	patterns := newFakeShiftPatternRepo()
+	pattern, _ := patterns.Create(context.Background(), domain.ShiftPattern{
+		ID:              uuid.New(),
+		OrgID:           f.orgID,
+		Name:            "Late",
+		Timezone:        "UTC",
+		StartMinute:     shiftStart.Hour() * 60,
+		DurationMinutes: 8 * 60,
+		Weekdays:        domain.AllWeekdays,
+	})
+	assignments := newFakeRosterAssignmentRepo()
+	_, _ = assignments.Create(context.Background(), domain.RosterAssignment{
+		ID:             uuid.New(),
+		OrgID:          f.orgID,
+		MechanicID:     mechanic,
+		ShiftPatternID: pattern.ID,
+		EffectiveFrom:  f.start.Add(-24 * time.Hour),
+	})
+	f.registry.ScheduleOptimizer.Roster = &services.RosterService{
+		Patterns:    patterns,
+		Assignments: assignments,
+		Absences:    newFakeMechanicAbsenceRepo(),
+		Tasks:       f.taskRepo,
+	}
+	task := f.addTask(t, domain.PriorityRoutine, f.start, 2*time.Hour, &mechanic)
+
+	plan := f.propose(t, map[string]any{"task_ids": []string{task.ID.String()}})
+	item := planItem(t, plan, task.ID)
+	if !item.Placed {
+		t.Fatalf("expected task placed, got %+v", item)
+	}
+	if !item.StartTime.Equal(shiftStart) {
+		t.Fatalf("expected task at shift start %s, got %s", shiftStart, item.StartTime)
+	}
+}
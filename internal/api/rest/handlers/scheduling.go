@@ -22,6 +22,12 @@ type rescheduleRequest struct {
 	NewEndTime   string `json:"new_end_time" validate:"required,rfc3339"`
 	Reason       string `json:"reason" validate:"required"`
 	Cascade      bool   `json:"cascade"`
+	// DryRun previews the reschedule without writing anything.
+	DryRun bool `json:"dry_run"`
+	// AllowFlightOverlap moves the tasks even though their aircraft is
+	// planned to fly during the new windows, reporting the flights as a
+	// warning.
+	AllowFlightOverlap bool `json:"allow_flight_overlap"`
 }
 
 type dependencyResponse struct {
@@ -46,6 +52,58 @@ type scheduleChangeResponse struct {
 	TriggeredBy     *uuid.UUID                `json:"triggered_by,omitempty"`
 	AffectedTaskIDs []uuid.UUID               `json:"affected_task_ids,omitempty"`
 	CreatedAt       time.Time                 `json:"created_at"`
+	// FlightConflicts lists the planned flights the moved tasks overlap
+	// after a reschedule made with allow_flight_overlap.
+	FlightConflicts []plannedFlightResponse `json:"flight_conflicts,omitempty"`
+}
+
+type taskMoveResponse struct {
+	TaskID       uuid.UUID `json:"task_id"`
+	OldStartTime time.Time `json:"old_start_time"`
+	OldEndTime   time.Time `json:"old_end_time"`
+	NewStartTime time.Time `json:"new_start_time"`
+	NewEndTime   time.Time `json:"new_end_time"`
+}
+
+type capacityViolationResponse struct {
+	TaskID  uuid.UUID                   `json:"task_id"`
+	Kind    domain.CapacityConflictKind `json:"kind"`
+	Message string                      `json:"message"`
+}
+
+type directiveBreachResponse struct {
+	TaskID          uuid.UUID `json:"task_id"`
+	AircraftID      uuid.UUID `json:"aircraft_id"`
+	DirectiveID     uuid.UUID `json:"directive_id"`
+	ReferenceNumber string    `json:"reference_number"`
+	Deadline        time.Time `json:"deadline"`
+	NewEndTime      time.Time `json:"new_end_time"`
+}
+
+type preemptionDisplacementResponse struct {
+	TaskID       uuid.UUID                  `json:"task_id"`
+	Priority     domain.TaskPriority        `json:"priority"`
+	Resource     services.PreemptedResource `json:"resource"`
+	ChangeType   domain.ScheduleChangeType  `json:"change_type"`
+	Reason       string                     `json:"reason"`
+	OldStartTime time.Time                  `json:"old_start_time"`
+	OldEndTime   time.Time                  `json:"old_end_time"`
+	NewStartTime time.Time                  `json:"new_start_time"`
+	NewEndTime   time.Time                  `json:"new_end_time"`
+	Dependents   []uuid.UUID                `json:"dependents"`
+	MechanicIDs  []uuid.UUID                `json:"mechanic_ids"`
+}
+
+type preemptionPlanResponse struct {
+	Displacements      []preemptionDisplacementResponse `json:"displacements"`
+	CapacityViolations []capacityViolationResponse      `json:"capacity_violations"`
+}
+
+type rescheduleSimulationResponse struct {
+	Moves              []taskMoveResponse          `json:"moves"`
+	Conflicts          []services.ScheduleConflict `json:"conflicts"`
+	CapacityViolations []capacityViolationResponse `json:"capacity_violations"`
+	DirectiveBreaches  []directiveBreachResponse   `json:"directive_breaches"`
 }
 
 // --- Handlers ---
@@ -173,19 +231,31 @@ func RescheduleTask(w http.ResponseWriter, r *http.Request) {
 	}
 	newStart, _ := time.Parse(time.RFC3339, req.NewStartTime)
 	newEnd, _ := time.Parse(time.RFC3339, req.NewEndTime)
+	input := services.RescheduleInput{
+		TaskID:             taskID,
+		NewStartTime:       newStart,
+		NewEndTime:         newEnd,
+		Reason:             req.Reason,
+		Cascade:            req.Cascade,
+		AllowFlightOverlap: req.AllowFlightOverlap,
+	}
 
-	event, err := servicesReg.Scheduling.RescheduleTask(r.Context(), actor, services.RescheduleInput{
-		TaskID:       taskID,
-		NewStartTime: newStart,
-		NewEndTime:   newEnd,
-		Reason:       req.Reason,
-		Cascade:      req.Cascade,
-	})
+	if req.DryRun {
+		simulation, err := servicesReg.Scheduling.SimulateReschedule(r.Context(), actor, input)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mapRescheduleSimulation(simulation))
+		return
+	}
+
+	event, err := servicesReg.Scheduling.RescheduleTask(r.Context(), actor, input)
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, scheduleChangeResponse{
+	resp := scheduleChangeResponse{
 		ID:              event.ID,
 		OrgID:           event.OrgID,
 		TaskID:          event.TaskID,
@@ -198,7 +268,15 @@ func RescheduleTask(w http.ResponseWriter, r *http.Request) {
 		TriggeredBy:     event.TriggeredBy,
 		AffectedTaskIDs: event.AffectedTaskIDs,
 		CreatedAt:       event.CreatedAt,
-	})
+	}
+	// The tasks are already moved, so a failed lookup only drops the
+	// warning.
+	if req.AllowFlightOverlap {
+		if flights, err := servicesReg.Scheduling.OverlappingFlights(r.Context(), event); err == nil && len(flights) > 0 {
+			resp.FlightConflicts = mapPlannedFlights(flights)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func ListScheduleChanges(w http.ResponseWriter, r *http.Request) {
@@ -253,10 +331,162 @@ func DetectScheduleConflicts(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
 		return
 	}
-	conflicts, err := servicesReg.Scheduling.DetectConflicts(r.Context(), actor)
+	query := r.URL.Query()
+	var filter services.ConflictFilter
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+			return
+		}
+		filter.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+			return
+		}
+		filter.To = &to
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+	conflicts, err := servicesReg.Scheduling.DetectConflicts(r.Context(), actor, filter)
 	if err != nil {
 		writeDomainError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, conflicts)
 }
+
+// GetCriticalPath returns the critical path analysis of a work package or of
+// an aircraft's open tasks, named by the work_package_id or aircraft_id
+// query parameter.
+func GetCriticalPath(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Scheduling == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	var input services.CriticalPathInput
+	if value := query.Get("org_id"); value != "" {
+		orgID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+			return
+		}
+		input.OrgID = &orgID
+	}
+	if value := query.Get("work_package_id"); value != "" {
+		pkgID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work_package_id")
+			return
+		}
+		input.WorkPackageID = &pkgID
+	}
+	if value := query.Get("aircraft_id"); value != "" {
+		aircraftID, err := uuid.Parse(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		input.AircraftID = &aircraftID
+	}
+	path, err := servicesReg.Scheduling.CriticalPath(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, path)
+}
+
+func mapRescheduleSimulation(simulation services.RescheduleSimulation) rescheduleSimulationResponse {
+	resp := rescheduleSimulationResponse{
+		Moves:              make([]taskMoveResponse, 0, len(simulation.Moves)),
+		Conflicts:          simulation.Conflicts,
+		CapacityViolations: make([]capacityViolationResponse, 0, len(simulation.CapacityViolations)),
+		DirectiveBreaches:  make([]directiveBreachResponse, 0, len(simulation.DirectiveBreaches)),
+	}
+	for _, move := range simulation.Moves {
+		resp.Moves = append(resp.Moves, taskMoveResponse{
+			TaskID:       move.TaskID,
+			OldStartTime: move.OldStartTime,
+			OldEndTime:   move.OldEndTime,
+			NewStartTime: move.NewStartTime,
+			NewEndTime:   move.NewEndTime,
+		})
+	}
+	for _, violation := range simulation.CapacityViolations {
+		resp.CapacityViolations = append(resp.CapacityViolations, capacityViolationResponse{
+			TaskID:  violation.TaskID,
+			Kind:    violation.Kind,
+			Message: violation.Message,
+		})
+	}
+	for _, breach := range simulation.DirectiveBreaches {
+		resp.DirectiveBreaches = append(resp.DirectiveBreaches, directiveBreachResponse{
+			TaskID:          breach.TaskID,
+			AircraftID:      breach.AircraftID,
+			DirectiveID:     breach.DirectiveID,
+			ReferenceNumber: breach.ReferenceNumber,
+			Deadline:        breach.Deadline,
+			NewEndTime:      breach.NewEndTime,
+		})
+	}
+	return resp
+}
+
+func mapPreemptionPlan(plan services.PreemptionPlan) preemptionPlanResponse {
+	resp := preemptionPlanResponse{
+		Displacements:      make([]preemptionDisplacementResponse, 0, len(plan.Displacements)),
+		CapacityViolations: make([]capacityViolationResponse, 0, len(plan.CapacityViolations)),
+	}
+	for _, displacement := range plan.Displacements {
+		dependents := displacement.Dependents
+		if dependents == nil {
+			dependents = []uuid.UUID{}
+		}
+		resp.Displacements = append(resp.Displacements, preemptionDisplacementResponse{
+			TaskID:       displacement.TaskID,
+			Priority:     displacement.Priority,
+			Resource:     displacement.Resource,
+			ChangeType:   displacement.ChangeType,
+			Reason:       displacement.Reason,
+			OldStartTime: displacement.OldStartTime,
+			OldEndTime:   displacement.OldEndTime,
+			NewStartTime: displacement.NewStartTime,
+			NewEndTime:   displacement.NewEndTime,
+			Dependents:   dependents,
+			MechanicIDs:  displacement.MechanicIDs,
+		})
+	}
+	for _, violation := range plan.CapacityViolations {
+		resp.CapacityViolations = append(resp.CapacityViolations, capacityViolationResponse{
+			TaskID:  violation.TaskID,
+			Kind:    violation.Kind,
+			Message: violation.Message,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,712 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type schedulingFixture struct {
+	orgID      uuid.UUID
+	taskRepo   *fakeTaskRepo
+	deps       *fakeTaskDependencyRepo
+	parts      *fakePartItemRepo
+	reserved   *fakePartReservationRepo
+	changes    *fakeScheduleChangeRepo
+	outbox     *fakeOutboxRepo
+	directives *fakeDirectiveRepo
+	absences   *fakeMechanicAbsenceRepo
+	bay        domain.HangarBay
+	mechanic   uuid.UUID
+	registry   middleware.ServiceRegistry
+	start      time.Time
+}
+
+func newSchedulingFixture(t *testing.T) *schedulingFixture {
+	t.Helper()
+	orgID := uuid.New()
+	taskRepo := newFakeTaskRepo()
+	bayRepo := newFakeHangarBayRepo()
+	bay, _ := bayRepo.Create(context.Background(), domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		StationID:     uuid.New(),
+		Code:          "B1",
+		Name:          "Bay 1",
+		CapacitySlots: 1,
+	})
+	f := &schedulingFixture{
+		orgID:      orgID,
+		taskRepo:   taskRepo,
+		deps:       newFakeTaskDependencyRepo(),
+		parts:      newFakePartItemRepo(),
+		reserved:   newFakePartReservationRepo(),
+		changes:    newFakeScheduleChangeRepo(taskRepo),
+		outbox:     &fakeOutboxRepo{},
+		directives: newFakeDirectiveRepo(),
+		absences:   newFakeMechanicAbsenceRepo(),
+		bay:        bay,
+		mechanic:   uuid.New(),
+		start:      time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC(),
+	}
+	scheduling := &services.SchedulingService{
+		Tasks:          taskRepo,
+		Dependencies:   f.deps,
+		ScheduleEvents: f.changes,
+		Capacity: &services.CapacityService{
+			Stations: newFakeStationRepo(),
+			Bays:     bayRepo,
+			Windows:  newFakeBayCapacityWindowRepo(),
+			Tasks:    taskRepo,
+		},
+		Roster: &services.RosterService{
+			Absences: f.absences,
+			Tasks:    taskRepo,
+		},
+		Reservations: f.reserved,
+		PartItems:    f.parts,
+		Certs: &fakeQualificationRepo{qualified: map[domain.TaskType][]uuid.UUID{
+			domain.TaskTypeInspection: {f.mechanic},
+		}},
+		Aircraft:   newFakeAircraftRepo(),
+		Directives: f.directives,
+		Outbox:     f.outbox,
+	}
+	f.registry = middleware.ServiceRegistry{Scheduling: scheduling}
+	return f
+}
+
+func (f *schedulingFixture) addTask(t *testing.T, aircraftID uuid.UUID, start time.Time, duration time.Duration, mechanicID *uuid.UUID) domain.MaintenanceTask {
+	t.Helper()
+	task, _ := f.taskRepo.Create(context.Background(), domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              f.orgID,
+		AircraftID:         aircraftID,
+		Type:               domain.TaskTypeInspection,
+		State:              domain.TaskStateScheduled,
+		Priority:           domain.PriorityRoutine,
+		StartTime:          start,
+		EndTime:            start.Add(duration),
+		AssignedMechanicID: mechanicID,
+		CreatedAt:          time.Now().UTC(),
+		UpdatedAt:          time.Now().UTC(),
+	})
+	return task
+}
+
+func (f *schedulingFixture) addDependency(t *testing.T, task, dependsOn domain.MaintenanceTask, depType domain.DependencyType) {
+	t.Helper()
+	_, _ = f.deps.Create(context.Background(), domain.TaskDependency{
+		ID:              uuid.New(),
+		OrgID:           f.orgID,
+		TaskID:          task.ID,
+		DependsOnTaskID: dependsOn.ID,
+		DependencyType:  depType,
+		CreatedAt:       time.Now().UTC(),
+	})
+}
+
+func (f *schedulingFixture) reservePart(t *testing.T, task domain.MaintenanceTask, status domain.PartItemStatus, expiry *time.Time) {
+	t.Helper()
+	item, _ := f.parts.Create(context.Background(), domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        f.orgID,
+		DefinitionID: uuid.New(),
+		SerialNumber: "SN-" + task.ID.String()[:8],
+		Status:       status,
+		ExpiryDate:   expiry,
+	})
+	_ = f.reserved.Create(context.Background(), domain.PartReservation{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		TaskID:     task.ID,
+		PartItemID: item.ID,
+		State:      domain.ReservationReserved,
+		Quantity:   1,
+	})
+}
+
+func (f *schedulingFixture) detect(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	return f.serve(t, httptest.NewRequest(http.MethodGet, "/api/v1/scheduling/conflicts"+query, nil), DetectScheduleConflicts)
+}
+
+func (f *schedulingFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *schedulingFixture) reschedule(t *testing.T, task domain.MaintenanceTask, start, end time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	return f.postReschedule(t, task, start, end, false)
+}
+
+func (f *schedulingFixture) postReschedule(t *testing.T, task domain.MaintenanceTask, start, end time.Time, dryRun bool) *httptest.ResponseRecorder {
+	t.Helper()
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/maintenance-tasks/"+task.ID.String()+"/reschedule", map[string]any{
+		"new_start_time": start.Format(time.RFC3339),
+		"new_end_time":   end.Format(time.RFC3339),
+		"reason":         "hangar slip",
+		"cascade":        true,
+		"dry_run":        dryRun,
+	})
+	return f.serve(t, withRouteParam(req, "id", task.ID.String()), RescheduleTask)
+}
+
+func (f *schedulingFixture) task(t *testing.T, id uuid.UUID) domain.MaintenanceTask {
+	t.Helper()
+	task, err := f.taskRepo.GetByID(context.Background(), f.orgID, id)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	return task
+}
+
+func (f *schedulingFixture) conflicts(t *testing.T, query string) []services.ScheduleConflict {
+	t.Helper()
+	rr := f.detect(t, query)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var conflicts []services.ScheduleConflict
+	if err := json.NewDecoder(rr.Body).Decode(&conflicts); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return conflicts
+}
+
+func findConflict(conflicts []services.ScheduleConflict, taskID uuid.UUID, conflictType string) (services.ScheduleConflict, bool) {
+	for _, conflict := range conflicts {
+		if conflict.TaskID == taskID && conflict.ConflictType == conflictType {
+			return conflict, true
+		}
+	}
+	return services.ScheduleConflict{}, false
+}
+
+func TestDetectConflictsReportsEachType(t *testing.T) {
+	f := newSchedulingFixture(t)
+	day := func(n int) time.Time { return f.start.Add(time.Duration(n) * 24 * time.Hour) }
+
+	aircraftID := uuid.New()
+	f.addTask(t, aircraftID, day(0), 4*time.Hour, nil)
+	secondOnAircraft := f.addTask(t, aircraftID, day(0).Add(2*time.Hour), 4*time.Hour, nil)
+
+	f.addTask(t, uuid.New(), day(1), 4*time.Hour, &f.mechanic)
+	doubleBooked := f.addTask(t, uuid.New(), day(1).Add(time.Hour), 2*time.Hour, &f.mechanic)
+
+	slot := 1
+	firstInBay := f.addTask(t, uuid.New(), day(2), 4*time.Hour, nil)
+	secondInBay := f.addTask(t, uuid.New(), day(2).Add(time.Hour), 4*time.Hour, nil)
+	for _, task := range []domain.MaintenanceTask{firstInBay, secondInBay} {
+		task.BayID, task.BaySlot = &f.bay.ID, &slot
+		_, _ = f.taskRepo.Update(context.Background(), task)
+	}
+
+	ssPrereq := f.addTask(t, uuid.New(), day(3).Add(2*time.Hour), 2*time.Hour, nil)
+	ssTask := f.addTask(t, uuid.New(), day(3), 2*time.Hour, nil)
+	f.addDependency(t, ssTask, ssPrereq, domain.DependencyStartToStart)
+
+	ffPrereq := f.addTask(t, uuid.New(), day(4), 6*time.Hour, nil)
+	ffTask := f.addTask(t, uuid.New(), day(4).Add(time.Hour), 2*time.Hour, nil)
+	f.addDependency(t, ffTask, ffPrereq, domain.DependencyFinishToFinish)
+
+	expired := f.addTask(t, uuid.New(), day(5), 4*time.Hour, nil)
+	expiry := day(5).Add(time.Hour)
+	f.reservePart(t, expired, domain.PartItemInStock, &expiry)
+	unavailable := f.addTask(t, uuid.New(), day(6), 4*time.Hour, nil)
+	f.reservePart(t, unavailable, domain.PartItemUsed, nil)
+
+	unqualified := uuid.New()
+	lapsed := f.addTask(t, uuid.New(), day(7), 4*time.Hour, &unqualified)
+
+	conflicts := f.conflicts(t, "?limit=200")
+	cases := []struct {
+		name         string
+		taskID       uuid.UUID
+		conflictType string
+		severity     domain.ConflictSeverity
+	}{
+		{"aircraft overlap", secondOnAircraft.ID, "aircraft_overlap", domain.ConflictSeverityCritical},
+		{"mechanic double booking", doubleBooked.ID, "mechanic_double_booked", domain.ConflictSeverityCritical},
+		{"bay overrun", secondInBay.ID, "bay_capacity", domain.ConflictSeverityHigh},
+		{"start to start", ssTask.ID, "unmet_dependency", domain.ConflictSeverityMedium},
+		{"finish to finish", ffTask.ID, "unmet_dependency", domain.ConflictSeverityMedium},
+		{"expired part", expired.ID, "part_expired", domain.ConflictSeverityCritical},
+		{"unavailable part", unavailable.ID, "part_unavailable", domain.ConflictSeverityHigh},
+		{"lapsed qualification", lapsed.ID, "qualification_lapsed", domain.ConflictSeverityCritical},
+	}
+	for _, tc := range cases {
+		conflict, ok := findConflict(conflicts, tc.taskID, tc.conflictType)
+		if !ok {
+			t.Errorf("%s: expected %s conflict on task %s", tc.name, tc.conflictType, tc.taskID)
+			continue
+		}
+		if conflict.Severity != tc.severity {
+			t.Errorf("%s: expected severity %s, got %s", tc.name, tc.severity, conflict.Severity)
+		}
+	}
+	if len(conflicts) != len(cases) {
+		t.Fatalf("expected %d conflicts, got %d: %+v", len(cases), len(conflicts), conflicts)
+	}
+	if conflict, _ := findConflict(conflicts, ssTask.ID, "unmet_dependency"); conflict.DependencyType != domain.DependencyStartToStart {
+		t.Fatalf("expected start_to_start dependency, got %s", conflict.DependencyType)
+	}
+	for i := 1; i < len(conflicts); i++ {
+		if conflicts[i].StartTime.Before(conflicts[i-1].StartTime) {
+			t.Fatalf("expected conflicts ordered by task start")
+		}
+	}
+}
+
+func TestDetectConflictsFinishToStartOutsideRange(t *testing.T) {
+	f := newSchedulingFixture(t)
+	prereq := f.addTask(t, uuid.New(), f.start, 30*time.Hour, nil)
+	task := f.addTask(t, uuid.New(), f.start.Add(20*time.Hour), 20*time.Hour, nil)
+	f.addDependency(t, task, prereq, domain.DependencyFinishToStart)
+
+	to := f.start.Add(72 * time.Hour).Format(time.RFC3339)
+	if conflicts := f.conflicts(t, "?from="+f.start.Add(42*time.Hour).Format(time.RFC3339)+"&to="+to); len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts after the task, got %+v", conflicts)
+	}
+	// The prerequisite ends before the range but is still loaded to check
+	// the dependency of a task inside it.
+	conflicts := f.conflicts(t, "?from="+f.start.Add(32*time.Hour).Format(time.RFC3339)+"&to="+to)
+	conflict, ok := findConflict(conflicts, task.ID, "unmet_dependency")
+	if !ok {
+		t.Fatalf("expected unmet dependency, got %+v", conflicts)
+	}
+	if conflict.Severity != domain.ConflictSeverityHigh || conflict.DependencyType != domain.DependencyFinishToStart {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+	if len(conflict.BlockingTaskIDs) != 1 || conflict.BlockingTaskIDs[0] != prereq.ID {
+		t.Fatalf("expected blocking task %s, got %v", prereq.ID, conflict.BlockingTaskIDs)
+	}
+}
+
+func TestDetectConflictsPaginates(t *testing.T) {
+	f := newSchedulingFixture(t)
+	aircraftID := uuid.New()
+	var later []uuid.UUID
+	f.addTask(t, aircraftID, f.start, 10*time.Hour, nil)
+	for i := 1; i <= 3; i++ {
+		later = append(later, f.addTask(t, aircraftID, f.start.Add(time.Duration(i)*time.Hour), time.Hour, nil).ID)
+	}
+
+	all := f.conflicts(t, "")
+	if len(all) != 3 {
+		t.Fatalf("expected 3 conflicts, got %d", len(all))
+	}
+	page := f.conflicts(t, "?limit=2&offset=1")
+	if len(page) != 2 || page[0].TaskID != later[1] || page[1].TaskID != later[2] {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if beyond := f.conflicts(t, "?offset=5"); len(beyond) != 0 {
+		t.Fatalf("expected empty page, got %+v", beyond)
+	}
+}
+
+func TestDetectConflictsRejectsInvalidRange(t *testing.T) {
+	f := newSchedulingFixture(t)
+	from := f.start.Format(time.RFC3339)
+	rr := f.detect(t, "?from="+from+"&to="+from)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if rr = f.detect(t, "?from=yesterday"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+}
+
+// addPackage puts the tasks into a new work package on aircraftID with
+// the given visit end.
+func (f *schedulingFixture) addPackage(t *testing.T, aircraftID uuid.UUID, visitEnd time.Time, tasks ...domain.MaintenanceTask) domain.WorkPackage {
+	t.Helper()
+	if f.registry.Scheduling.WorkPackages == nil {
+		f.registry.Scheduling.WorkPackages = newFakeWorkPackageRepo(f.taskRepo)
+	}
+	pkg, _ := f.registry.Scheduling.WorkPackages.Create(context.Background(), domain.WorkPackage{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		AircraftID: aircraftID,
+		Name:       "A-check",
+		State:      domain.WorkPackagePlanned,
+		VisitStart: f.start,
+		VisitEnd:   visitEnd,
+	})
+	for _, task := range tasks {
+		task.WorkPackageID = &pkg.ID
+		_, _ = f.taskRepo.Update(context.Background(), task)
+	}
+	return pkg
+}
+
+func (f *schedulingFixture) criticalPath(t *testing.T, query string) services.CriticalPath {
+	t.Helper()
+	rr := f.serve(t, httptest.NewRequest(http.MethodGet, "/api/v1/scheduling/critical-path"+query, nil), GetCriticalPath)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var path services.CriticalPath
+	if err := json.NewDecoder(rr.Body).Decode(&path); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return path
+}
+
+func TestCriticalPathForWorkPackage(t *testing.T) {
+	f := newSchedulingFixture(t)
+	at := func(hours int) time.Time { return f.start.Add(time.Duration(hours) * time.Hour) }
+
+	aircraftID := uuid.New()
+	first := f.addTask(t, aircraftID, at(0), 4*time.Hour, nil)
+	second := f.addTask(t, aircraftID, at(4), 4*time.Hour, nil)
+	parallel := f.addTask(t, aircraftID, at(0), 2*time.Hour, nil)
+	alongside := f.addTask(t, aircraftID, at(1), 2*time.Hour, nil)
+	f.addDependency(t, second, first, domain.DependencyFinishToStart)
+	f.addDependency(t, alongside, first, domain.DependencyStartToStart)
+	f.addTask(t, uuid.New(), at(0), 12*time.Hour, nil)
+	pkg := f.addPackage(t, aircraftID, at(10), first, second, parallel, alongside)
+
+	path := f.criticalPath(t, "?work_package_id="+pkg.ID.String())
+	if len(path.Tasks) != 4 {
+		t.Fatalf("expected the package's 4 tasks, got %d", len(path.Tasks))
+	}
+	if !path.ProjectedFinish.Equal(at(8)) || !path.ReturnToService.Equal(at(10)) {
+		t.Fatalf("expected finish %s and return to service %s, got %s and %s", at(8), at(10), path.ProjectedFinish, path.ReturnToService)
+	}
+	want := map[uuid.UUID]struct {
+		latestStart time.Time
+		float       int64
+		critical    bool
+	}{
+		first.ID:     {at(2), 120, true},
+		second.ID:    {at(6), 120, true},
+		parallel.ID:  {at(8), 480, false},
+		alongside.ID: {at(8), 420, false},
+	}
+	for _, float := range path.Tasks {
+		expected := want[float.TaskID]
+		if !float.LatestStart.Equal(expected.latestStart) || float.TotalFloatMinutes != expected.float || float.Critical != expected.critical {
+			t.Fatalf("task %s: expected latest start %s, float %d, critical %v; got %+v", float.TaskID, expected.latestStart, expected.float, expected.critical, float)
+		}
+		if float.DelaysReturnToService {
+			t.Fatalf("task %s has float but delays return to service", float.TaskID)
+		}
+	}
+	if len(path.CriticalChain) != 2 || path.CriticalChain[0] != first.ID || path.CriticalChain[1] != second.ID {
+		t.Fatalf("expected critical chain [%s %s], got %v", first.ID, second.ID, path.CriticalChain)
+	}
+}
+
+func TestCriticalPathForAircraft(t *testing.T) {
+	f := newSchedulingFixture(t)
+	aircraftID := uuid.New()
+	panel := f.addTask(t, aircraftID, f.start, 2*time.Hour, nil)
+	closeUp := f.addTask(t, aircraftID, f.start, time.Hour, nil)
+	f.addDependency(t, closeUp, panel, domain.DependencyFinishToFinish)
+	f.addTask(t, uuid.New(), f.start, 8*time.Hour, nil)
+
+	path := f.criticalPath(t, "?aircraft_id="+aircraftID.String())
+	if len(path.Tasks) != 2 || !path.ReturnToService.Equal(f.start.Add(2*time.Hour)) {
+		t.Fatalf("expected 2 tasks finishing at %s, got %+v", f.start.Add(2*time.Hour), path)
+	}
+	for _, float := range path.Tasks {
+		if float.TotalFloatMinutes != 0 || !float.Critical || !float.DelaysReturnToService {
+			t.Fatalf("expected task %s on the critical path, got %+v", float.TaskID, float)
+		}
+	}
+	if got := path.Tasks[1]; got.TaskID != closeUp.ID || !got.EarliestStart.Equal(f.start.Add(time.Hour)) {
+		t.Fatalf("expected close-up pushed to %s by finish-to-finish, got %+v", f.start.Add(time.Hour), got)
+	}
+	if len(path.CriticalChain) != 2 || path.CriticalChain[0] != panel.ID || path.CriticalChain[1] != closeUp.ID {
+		t.Fatalf("expected critical chain [%s %s], got %v", panel.ID, closeUp.ID, path.CriticalChain)
+	}
+}
+
+func TestCriticalPathRequiresOneScope(t *testing.T) {
+	f := newSchedulingFixture(t)
+	for _, query := range []string{"", "?aircraft_id=" + uuid.NewString() + "&work_package_id=" + uuid.NewString(), "?aircraft_id=nose"} {
+		rr := f.serve(t, httptest.NewRequest(http.MethodGet, "/api/v1/scheduling/critical-path"+query, nil), GetCriticalPath)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected status 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestDetectConflictsReportsReturnToServiceAtRisk(t *testing.T) {
+	f := newSchedulingFixture(t)
+	aircraftID := uuid.New()
+	first := f.addTask(t, aircraftID, f.start, 4*time.Hour, nil)
+	second := f.addTask(t, aircraftID, f.start.Add(4*time.Hour), 4*time.Hour, nil)
+	spare := f.addTask(t, aircraftID, f.start, 2*time.Hour, nil)
+	f.addDependency(t, second, first, domain.DependencyFinishToStart)
+	pkg := f.addPackage(t, aircraftID, f.start.Add(7*time.Hour), first, second, spare)
+
+	conflicts := f.conflicts(t, "")
+	for _, task := range []domain.MaintenanceTask{first, second} {
+		conflict, ok := findConflict(conflicts, task.ID, "return_to_service_at_risk")
+		if !ok {
+			t.Fatalf("expected return_to_service_at_risk on task %s, got %+v", task.ID, conflicts)
+		}
+		if conflict.Severity != domain.ConflictSeverityHigh || conflict.WorkPackageID == nil || *conflict.WorkPackageID != pkg.ID {
+			t.Fatalf("unexpected conflict %+v", conflict)
+		}
+	}
+	if _, ok := findConflict(conflicts, spare.ID, "return_to_service_at_risk"); ok {
+		t.Fatalf("task with float reported as delaying return to service")
+	}
+}
+
+func TestRescheduleCascadeFollowsDependencyTypes(t *testing.T) {
+	f := newSchedulingFixture(t)
+	hour := func(n int) time.Time { return f.start.Add(time.Duration(n) * time.Hour) }
+	root := f.addTask(t, uuid.New(), hour(0), 4*time.Hour, nil)
+	finishToStart := f.addTask(t, uuid.New(), hour(4), 2*time.Hour, nil)
+	startToStart := f.addTask(t, uuid.New(), hour(3), 2*time.Hour, nil)
+	finishToFinish := f.addTask(t, uuid.New(), hour(1), 4*time.Hour, nil)
+	transitive := f.addTask(t, uuid.New(), hour(7), 2*time.Hour, nil)
+	satisfied := f.addTask(t, uuid.New(), hour(10), time.Hour, nil)
+	f.addDependency(t, finishToStart, root, domain.DependencyFinishToStart)
+	f.addDependency(t, startToStart, root, domain.DependencyStartToStart)
+	f.addDependency(t, finishToFinish, root, domain.DependencyFinishToFinish)
+	f.addDependency(t, transitive, finishToStart, domain.DependencyFinishToStart)
+	f.addDependency(t, satisfied, transitive, domain.DependencyFinishToStart)
+
+	rr := f.reschedule(t, root, hour(2), hour(6))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var event scheduleChangeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&event); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !event.OldStartTime.Equal(hour(0)) || !event.NewStartTime.Equal(hour(2)) {
+		t.Fatalf("expected move from %s to %s, got %s to %s", hour(0), hour(2), event.OldStartTime, event.NewStartTime)
+	}
+	if len(event.AffectedTaskIDs) != 3 {
+		t.Fatalf("expected 3 affected tasks, got %v", event.AffectedTaskIDs)
+	}
+
+	expected := map[uuid.UUID]time.Time{
+		root.ID:           hour(2),
+		finishToStart.ID:  hour(6),
+		startToStart.ID:   hour(3),
+		finishToFinish.ID: hour(2),
+		transitive.ID:     hour(8),
+		satisfied.ID:      hour(10),
+	}
+	for id, start := range expected {
+		if task := f.task(t, id); !task.StartTime.Equal(start) {
+			t.Errorf("task %s: expected start %s, got %s", id, start, task.StartTime)
+		}
+	}
+}
+
+func TestRescheduleCascadeMovesChainOnOneAircraft(t *testing.T) {
+	f := newSchedulingFixture(t)
+	aircraftID := uuid.New()
+	root := f.addTask(t, aircraftID, f.start, 4*time.Hour, nil)
+	next := f.addTask(t, aircraftID, f.start.Add(4*time.Hour), 4*time.Hour, nil)
+	f.addDependency(t, next, root, domain.DependencyFinishToStart)
+
+	// The root's new window overlaps where the dependent is stored, but not
+	// where it moves to.
+	rr := f.reschedule(t, root, f.start.Add(2*time.Hour), f.start.Add(6*time.Hour))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if task := f.task(t, next.ID); !task.StartTime.Equal(f.start.Add(6 * time.Hour)) {
+		t.Fatalf("expected dependent to start at %s, got %s", f.start.Add(6*time.Hour), task.StartTime)
+	}
+}
+
+func TestRescheduleCascadeIsAllOrNothing(t *testing.T) {
+	f := newSchedulingFixture(t)
+	aircraftID := uuid.New()
+	root := f.addTask(t, uuid.New(), f.start, 4*time.Hour, nil)
+	dependent := f.addTask(t, aircraftID, f.start.Add(4*time.Hour), 2*time.Hour, nil)
+	f.addTask(t, aircraftID, f.start.Add(7*time.Hour), 2*time.Hour, nil)
+	f.addDependency(t, dependent, root, domain.DependencyFinishToStart)
+
+	rr := f.reschedule(t, root, f.start.Add(2*time.Hour), f.start.Add(6*time.Hour))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if code := decodeErrorCode(t, rr); code != "aircraft_overlap" {
+		t.Fatalf("expected code aircraft_overlap, got %s", code)
+	}
+	if task := f.task(t, root.ID); !task.StartTime.Equal(root.StartTime) {
+		t.Fatalf("expected root to stay at %s, got %s", root.StartTime, task.StartTime)
+	}
+	if task := f.task(t, dependent.ID); !task.StartTime.Equal(dependent.StartTime) {
+		t.Fatalf("expected dependent to stay at %s, got %s", dependent.StartTime, task.StartTime)
+	}
+	if len(f.changes.events) != 0 {
+		t.Fatalf("expected no schedule change event, got %d", len(f.changes.events))
+	}
+}
+
+func TestRescheduleChecksMechanicRoster(t *testing.T) {
+	f := newSchedulingFixture(t)
+	hour := func(n int) time.Time { return f.start.Add(time.Duration(n) * time.Hour) }
+	root := f.addTask(t, uuid.New(), hour(0), 2*time.Hour, nil)
+	dependent := f.addTask(t, uuid.New(), hour(2), 2*time.Hour, &f.mechanic)
+	f.addDependency(t, dependent, root, domain.DependencyFinishToStart)
+	_, _ = f.absences.Create(context.Background(), domain.MechanicAbsence{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		MechanicID: f.mechanic,
+		Kind:       domain.AbsenceLeave,
+		StartTime:  hour(4),
+		EndTime:    hour(8),
+	})
+
+	rr := f.postReschedule(t, root, hour(3), hour(5), true)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var simulation rescheduleSimulationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&simulation); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(simulation.CapacityViolations) != 1 || simulation.CapacityViolations[0].TaskID != dependent.ID || simulation.CapacityViolations[0].Kind != domain.CapacityConflictMechanicUnavailable {
+		t.Fatalf("expected the dependent's mechanic to be unavailable, got %+v", simulation.CapacityViolations)
+	}
+
+	rr = f.reschedule(t, root, hour(3), hour(5))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if code := decodeErrorCode(t, rr); code != "mechanic_unavailable" {
+		t.Fatalf("expected code mechanic_unavailable, got %s", code)
+	}
+	if task := f.task(t, dependent.ID); !task.StartTime.Equal(dependent.StartTime) {
+		t.Fatalf("expected dependent to stay at %s, got %s", dependent.StartTime, task.StartTime)
+	}
+	if len(f.changes.events) != 0 {
+		t.Fatalf("expected no schedule change event, got %d", len(f.changes.events))
+	}
+}
+
+func TestRescheduleCascadeKeepsMechanicAcrossChain(t *testing.T) {
+	f := newSchedulingFixture(t)
+	root := f.addTask(t, uuid.New(), f.start, 4*time.Hour, &f.mechanic)
+	next := f.addTask(t, uuid.New(), f.start.Add(4*time.Hour), 4*time.Hour, &f.mechanic)
+	f.addDependency(t, next, root, domain.DependencyFinishToStart)
+
+	// The root's new window overlaps where the mechanic's other task is
+	// stored, but not where it moves to.
+	rr := f.reschedule(t, root, f.start.Add(2*time.Hour), f.start.Add(6*time.Hour))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if task := f.task(t, next.ID); !task.StartTime.Equal(f.start.Add(6 * time.Hour)) {
+		t.Fatalf("expected dependent to start at %s, got %s", f.start.Add(6*time.Hour), task.StartTime)
+	}
+}
+
+func TestRescheduleDryRunPreviewsWithoutWriting(t *testing.T) {
+	f := newSchedulingFixture(t)
+	hour := func(n int) time.Time { return f.start.Add(time.Duration(n) * time.Hour) }
+	aircraftID := uuid.New()
+	root := f.addTask(t, uuid.New(), hour(0), 4*time.Hour, nil)
+	dependent := f.addTask(t, aircraftID, hour(4), 2*time.Hour, nil)
+	blocking := f.addTask(t, aircraftID, hour(7), 2*time.Hour, nil)
+	f.addDependency(t, dependent, root, domain.DependencyFinishToStart)
+
+	directive := domain.ComplianceDirective{ID: uuid.New(), OrgID: f.orgID, ReferenceNumber: "AD 2026-01-01"}
+	deadline := hour(7)
+	f.directives.directives[directive.ID] = directive
+	f.directives.compliance = append(f.directives.compliance, domain.AircraftDirectiveCompliance{
+		ID:          uuid.New(),
+		OrgID:       f.orgID,
+		AircraftID:  aircraftID,
+		DirectiveID: directive.ID,
+		Status:      domain.ComplianceStatusPending,
+		NextDueDate: &deadline,
+		TaskID:      &dependent.ID,
+	})
+
+	rr := f.postReschedule(t, root, hour(2), hour(6), true)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var simulation rescheduleSimulationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&simulation); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if len(simulation.Moves) != 2 || simulation.Moves[0].TaskID != root.ID || simulation.Moves[1].TaskID != dependent.ID {
+		t.Fatalf("expected root and dependent to move, got %+v", simulation.Moves)
+	}
+	if move := simulation.Moves[1]; !move.OldStartTime.Equal(hour(4)) || !move.NewStartTime.Equal(hour(6)) {
+		t.Fatalf("expected dependent to move from %s to %s, got %+v", hour(4), hour(6), move)
+	}
+	if len(simulation.CapacityViolations) != 1 || simulation.CapacityViolations[0].TaskID != dependent.ID || simulation.CapacityViolations[0].Kind != domain.CapacityConflictAircraftOverlap {
+		t.Fatalf("expected aircraft overlap for the dependent, got %+v", simulation.CapacityViolations)
+	}
+	conflict, ok := findConflict(simulation.Conflicts, blocking.ID, "aircraft_overlap")
+	if !ok || len(simulation.Conflicts) != 1 {
+		t.Fatalf("expected one new aircraft overlap, got %+v", simulation.Conflicts)
+	}
+	if len(conflict.BlockingTaskIDs) != 1 || conflict.BlockingTaskIDs[0] != dependent.ID {
+		t.Fatalf("expected overlap with the dependent, got %v", conflict.BlockingTaskIDs)
+	}
+	if len(simulation.DirectiveBreaches) != 1 || simulation.DirectiveBreaches[0].ReferenceNumber != directive.ReferenceNumber {
+		t.Fatalf("expected directive breach, got %+v", simulation.DirectiveBreaches)
+	}
+
+	if task := f.task(t, root.ID); !task.StartTime.Equal(root.StartTime) {
+		t.Fatalf("expected root unchanged, got %s", task.StartTime)
+	}
+	if task := f.task(t, dependent.ID); !task.StartTime.Equal(dependent.StartTime) {
+		t.Fatalf("expected dependent unchanged, got %s", task.StartTime)
+	}
+	if len(f.changes.events) != 0 || len(f.outbox.events) != 0 {
+		t.Fatalf("expected no events, got %d changes and %d outbox events", len(f.changes.events), len(f.outbox.events))
+	}
+}
+
+func TestRescheduleDryRunMatchesRealRun(t *testing.T) {
+	f := newSchedulingFixture(t)
+	hour := func(n int) time.Time { return f.start.Add(time.Duration(n) * time.Hour) }
+	root := f.addTask(t, uuid.New(), hour(0), 4*time.Hour, nil)
+	dependent := f.addTask(t, uuid.New(), hour(4), 2*time.Hour, nil)
+	f.addDependency(t, dependent, root, domain.DependencyFinishToStart)
+
+	rr := f.postReschedule(t, root, hour(1), hour(5), true)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var simulation rescheduleSimulationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&simulation); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(simulation.Conflicts) != 0 || len(simulation.CapacityViolations) != 0 || len(simulation.DirectiveBreaches) != 0 {
+		t.Fatalf("expected a clean preview, got %+v", simulation)
+	}
+
+	if rr := f.reschedule(t, root, hour(1), hour(5)); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for _, move := range simulation.Moves {
+		if task := f.task(t, move.TaskID); !task.StartTime.Equal(move.NewStartTime) || !task.EndTime.Equal(move.NewEndTime) {
+			t.Fatalf("task %s: preview %s-%s, applied %s-%s", move.TaskID, move.NewStartTime, move.NewEndTime, task.StartTime, task.EndTime)
+		}
+	}
+	if len(f.outbox.events) != 1 {
+		t.Fatalf("expected one outbox event, got %d", len(f.outbox.events))
+	}
+}
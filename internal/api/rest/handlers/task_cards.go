@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type taskCardCreateRequest struct {
+	OrgID     string                      `json:"org_id" validate:"omitempty,uuid"`
+	Title     string                      `json:"title" validate:"required,max=200"`
+	Reference string                      `json:"reference" validate:"max=200"`
+	Steps     []taskCardStepCreateRequest `json:"steps" validate:"required,min=1,max=200,dive"`
+}
+
+type taskCardStepCreateRequest struct {
+	Instruction        string   `json:"instruction" validate:"required"`
+	RequiredInspection bool     `json:"required_inspection"`
+	MeasurementUnit    string   `json:"measurement_unit" validate:"max=32"`
+	MinValue           *float64 `json:"min_value"`
+	MaxValue           *float64 `json:"max_value"`
+}
+
+type taskCardStepPerformRequest struct {
+	MeasuredValue *float64 `json:"measured_value"`
+}
+
+type taskCardResponse struct {
+	ID        uuid.UUID              `json:"id"`
+	OrgID     uuid.UUID              `json:"org_id"`
+	TaskID    uuid.UUID              `json:"task_id"`
+	Title     string                 `json:"title"`
+	Reference string                 `json:"reference,omitempty"`
+	CreatedBy uuid.UUID              `json:"created_by"`
+	Complete  bool                   `json:"complete"`
+	Steps     []taskCardStepResponse `json:"steps"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type taskCardStepResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	CardID             uuid.UUID  `json:"task_card_id"`
+	Position           int        `json:"position"`
+	Instruction        string     `json:"instruction"`
+	RequiredInspection bool       `json:"required_inspection"`
+	MeasurementUnit    string     `json:"measurement_unit,omitempty"`
+	MinValue           *float64   `json:"min_value,omitempty"`
+	MaxValue           *float64   `json:"max_value,omitempty"`
+	MeasuredValue      *float64   `json:"measured_value,omitempty"`
+	PerformedBy        *uuid.UUID `json:"performed_by,omitempty"`
+	PerformedAt        *time.Time `json:"performed_at,omitempty"`
+	InspectedBy        *uuid.UUID `json:"inspected_by,omitempty"`
+	InspectedAt        *time.Time `json:"inspected_at,omitempty"`
+	Complete           bool       `json:"complete"`
+}
+
+func CreateTaskCard(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.TaskCards == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	var req taskCardCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	input := services.TaskCardCreateInput{
+		OrgID:     &orgID,
+		TaskID:    taskID,
+		Title:     req.Title,
+		Reference: req.Reference,
+	}
+	for _, step := range req.Steps {
+		input.Steps = append(input.Steps, services.TaskCardStepInput{
+			Instruction:        step.Instruction,
+			RequiredInspection: step.RequiredInspection,
+			MeasurementUnit:    step.MeasurementUnit,
+			MinValue:           step.MinValue,
+			MaxValue:           step.MaxValue,
+		})
+	}
+	card, err := servicesReg.TaskCards.Create(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapTaskCard(card))
+}
+
+func ListTaskCards(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.TaskCards == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	cards, err := servicesReg.TaskCards.ListByTask(r.Context(), actor, orgID, taskID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]taskCardResponse, 0, len(cards))
+	for _, card := range cards {
+		resp = append(resp, mapTaskCard(card))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetTaskCard(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.TaskCards == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task card id")
+		return
+	}
+	orgID, err := resolveOrgID(actor, r.URL.Query().Get("org_id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	card, err := servicesReg.TaskCards.Get(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapTaskCard(card))
+}
+
+func PerformTaskCardStep(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.TaskCards == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	cardID, stepID, ok := parseTaskCardStepParams(w, r)
+	if !ok {
+		return
+	}
+	// Steps without a tolerance may be performed with an empty body.
+	var req taskCardStepPerformRequest
+	if r.ContentLength != 0 {
+		if err := decodeAndValidateJSON(r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+			return
+		}
+	}
+	step, err := servicesReg.TaskCards.PerformStep(r.Context(), actor, cardID, stepID, req.MeasuredValue)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapTaskCardStep(step))
+}
+
+func InspectTaskCardStep(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.TaskCards == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	cardID, stepID, ok := parseTaskCardStepParams(w, r)
+	if !ok {
+		return
+	}
+	step, err := servicesReg.TaskCards.InspectStep(r.Context(), actor, cardID, stepID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapTaskCardStep(step))
+}
+
+func parseTaskCardStepParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task card id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	stepID, err := uuid.Parse(chi.URLParam(r, "stepId"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid step id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return cardID, stepID, true
+}
+
+func mapTaskCard(card domain.TaskCard) taskCardResponse {
+	steps := make([]taskCardStepResponse, 0, len(card.Steps))
+	for _, step := range card.Steps {
+		steps = append(steps, mapTaskCardStep(step))
+	}
+	return taskCardResponse{
+		ID:        card.ID,
+		OrgID:     card.OrgID,
+		TaskID:    card.TaskID,
+		Title:     card.Title,
+		Reference: card.Reference,
+		CreatedBy: card.CreatedBy,
+		Complete:  card.Complete(),
+		Steps:     steps,
+		CreatedAt: card.CreatedAt,
+		UpdatedAt: card.UpdatedAt,
+	}
+}
+
+func mapTaskCardStep(step domain.TaskCardStep) taskCardStepResponse {
+	return taskCardStepResponse{
+		ID:                 step.ID,
+		CardID:             step.CardID,
+		Position:           step.Position,
+		Instruction:        step.Instruction,
+		RequiredInspection: step.RequiredInspection,
+		MeasurementUnit:    step.MeasurementUnit,
+		MinValue:           step.MinValue,
+		MaxValue:           step.MaxValue,
+		MeasuredValue:      step.MeasuredValue,
+		PerformedBy:        step.PerformedBy,
+		PerformedAt:        step.PerformedAt,
+		InspectedBy:        step.InspectedBy,
+		InspectedAt:        step.InspectedAt,
+		Complete:           step.Complete(),
+	}
+}
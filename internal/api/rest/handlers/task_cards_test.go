@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type taskCardFixture struct {
+	orgID     uuid.UUID
+	scheduler uuid.UUID
+	mechanic  uuid.UUID
+	inspector uuid.UUID
+	task      domain.MaintenanceTask
+	registry  middleware.ServiceRegistry
+}
+
+// newTaskCardFixture sets up an in-progress inspection task worked by
+// mechanic, and an inspector holding the inspection authorization the task
+// type requires.
+func newTaskCardFixture(t *testing.T) *taskCardFixture {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	f := &taskCardFixture{
+		orgID:     uuid.New(),
+		scheduler: uuid.New(),
+		mechanic:  uuid.New(),
+		inspector: uuid.New(),
+	}
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         f.orgID,
+		TailNumber:    "N200AM",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+	})
+	taskRepo := newFakeTaskRepo()
+	f.task, _ = taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              f.orgID,
+		AircraftID:         aircraft.ID,
+		Type:               domain.TaskTypeInspection,
+		State:              domain.TaskStateInProgress,
+		StartTime:          now.Add(-4 * time.Hour),
+		EndTime:            now.Add(-time.Hour),
+		AssignedMechanicID: &f.mechanic,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	})
+	inspectionCert := uuid.New()
+	certs := &fakeAuthorizationRepo{
+		requirements: []domain.TaskSkillRequirement{{
+			ID:               uuid.New(),
+			OrgID:            f.orgID,
+			TaskType:         domain.TaskTypeInspection,
+			CertTypeID:       &inspectionCert,
+			IsInspectionRole: true,
+		}},
+		certs: map[uuid.UUID][]domain.EmployeeCertification{
+			f.inspector: {{
+				ID:         uuid.New(),
+				OrgID:      f.orgID,
+				UserID:     f.inspector,
+				CertTypeID: inspectionCert,
+				IssueDate:  now.AddDate(-1, 0, 0),
+				Status:     domain.CertStatusActive,
+			}},
+		},
+	}
+	cardRepo := newFakeTaskCardRepo()
+	f.registry = middleware.ServiceRegistry{
+		Tasks: &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Cards: cardRepo},
+		TaskCards: &services.TaskCardService{
+			Cards:          cardRepo,
+			Tasks:          taskRepo,
+			Aircraft:       aircraftRepo,
+			Certifications: &services.CertificationService{Certs: certs},
+		},
+	}
+	return f
+}
+
+func (f *taskCardFixture) serve(req *http.Request, userID uuid.UUID, role domain.Role, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	req = withRouteParams(withUser(req, f.orgID, userID, role), params)
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *taskCardFixture) stepAction(t *testing.T, card taskCardResponse, position int, action string, userID uuid.UUID, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	step := card.Steps[position-1]
+	path := "/api/v1/task-cards/" + card.ID.String() + "/steps/" + step.ID.String() + "/" + action
+	handler := PerformTaskCardStep
+	if action == "inspect" {
+		handler = InspectTaskCardStep
+	}
+	var req *http.Request
+	if body == nil {
+		req = httptest.NewRequest(http.MethodPost, path, nil)
+	} else {
+		req = newJSONRequest(t, http.MethodPost, path, body)
+	}
+	return f.serve(req, userID, domain.RoleMechanic, handler, map[string]string{"id": card.ID.String(), "stepId": step.ID.String()})
+}
+
+func (f *taskCardFixture) completeTask(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	body := map[string]any{"new_state": "completed", "notes": "done"}
+	req := newJSONRequest(t, http.MethodPatch, "/api/v1/maintenance-tasks/"+f.task.ID.String()+"/state", body)
+	return f.serve(req, f.mechanic, domain.RoleMechanic, TransitionTaskState, map[string]string{"id": f.task.ID.String()})
+}
+
+func TestTaskCardRequiredInspectionSignOff(t *testing.T) {
+	f := newTaskCardFixture(t)
+
+	body := map[string]any{
+		"title":     "Main wheel replacement",
+		"reference": "AMM 32-41-11",
+		"steps": []map[string]any{
+			{"instruction": "Torque axle nut", "required_inspection": true, "measurement_unit": "Nm", "min_value": 20, "max_value": 25},
+			{"instruction": "Install hub cap"},
+		},
+	}
+	path := "/api/v1/maintenance-tasks/" + f.task.ID.String() + "/task-cards"
+	rr := f.serve(newJSONRequest(t, http.MethodPost, path, body), f.mechanic, domain.RoleMechanic, CreateTaskCard, map[string]string{"id": f.task.ID.String()})
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a mechanic creating a card, got %d", rr.Code)
+	}
+	rr = f.serve(newJSONRequest(t, http.MethodPost, path, body), f.scheduler, domain.RoleScheduler, CreateTaskCard, map[string]string{"id": f.task.ID.String()})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var card taskCardResponse
+	if err := json.NewDecoder(rr.Body).Decode(&card); err != nil {
+		t.Fatalf("decode task card: %v", err)
+	}
+	if len(card.Steps) != 2 || card.Steps[0].Position != 1 || !card.Steps[0].RequiredInspection || card.Complete {
+		t.Fatalf("unexpected card: %+v", card)
+	}
+
+	if rr := f.stepAction(t, card, 1, "perform", f.mechanic, nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without a measured value, got %d", rr.Code)
+	}
+	if rr := f.stepAction(t, card, 1, "perform", f.mechanic, map[string]any{"measured_value": 27.5}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 above tolerance, got %d", rr.Code)
+	}
+	if rr := f.stepAction(t, card, 1, "perform", f.mechanic, map[string]any{"measured_value": 22.5}); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 within tolerance, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = f.stepAction(t, card, 1, "inspect", f.mechanic, nil)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 when the performer inspects, got %d", rr.Code)
+	}
+	if rr := f.stepAction(t, card, 1, "inspect", uuid.New(), nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an inspector without authorization, got %d", rr.Code)
+	}
+	if rr := f.stepAction(t, card, 2, "inspect", f.inspector, nil); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 inspecting a step that is not an RII, got %d", rr.Code)
+	}
+
+	if rr := f.completeTask(t); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 completing with open steps, got %d", rr.Code)
+	}
+
+	rr = f.stepAction(t, card, 1, "inspect", f.inspector, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for an authorized inspector, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var step taskCardStepResponse
+	if err := json.NewDecoder(rr.Body).Decode(&step); err != nil {
+		t.Fatalf("decode step: %v", err)
+	}
+	if !step.Complete || step.InspectedBy == nil || *step.InspectedBy != f.inspector || *step.PerformedBy != f.mechanic {
+		t.Fatalf("expected step performed by the mechanic and inspected by the inspector, got %+v", step)
+	}
+	if rr := f.stepAction(t, card, 2, "perform", f.mechanic, nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 performing a plain step, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := f.completeTask(t); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 completing with all steps signed off, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
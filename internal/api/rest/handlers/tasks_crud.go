@@ -16,36 +16,55 @@ type taskCreateRequest struct {
 	OrgID              string `json:"org_id" validate:"omitempty,uuid"`
 	AircraftID         string `json:"aircraft_id" validate:"required,uuid"`
 	ProgramID          string `json:"program_id" validate:"omitempty,uuid"`
+	BayID              string `json:"bay_id" validate:"omitempty,uuid"`
 	Type               string `json:"type" validate:"required,oneof=inspection repair overhaul"`
+	Priority           string `json:"priority" validate:"omitempty,oneof=routine urgent aog critical"`
 	StartTime          string `json:"start_time" validate:"required,rfc3339"`
 	EndTime            string `json:"end_time" validate:"required,rfc3339"`
 	AssignedMechanicID string `json:"assigned_mechanic_id" validate:"omitempty,uuid"`
 	Notes              string `json:"notes"`
+	// Preempt lets an aog or critical task displace lower-priority work
+	// holding its bay or mechanic.
+	Preempt bool `json:"preempt"`
+	// DryRun previews the preemption without writing anything.
+	DryRun bool `json:"dry_run"`
+	// AllowFlightOverlap schedules the task even though the aircraft is
+	// planned to fly during it, reporting the flights as a warning.
+	AllowFlightOverlap bool `json:"allow_flight_overlap"`
 }
 
 type taskUpdateRequest struct {
 	ProgramID          string  `json:"program_id" validate:"omitempty,uuid"`
+	BayID              string  `json:"bay_id" validate:"omitempty,uuid"`
 	Type               string  `json:"type" validate:"omitempty,oneof=inspection repair overhaul"`
 	StartTime          string  `json:"start_time" validate:"omitempty,rfc3339"`
 	EndTime            string  `json:"end_time" validate:"omitempty,rfc3339"`
 	AssignedMechanicID string  `json:"assigned_mechanic_id" validate:"omitempty,uuid"`
 	Notes              *string `json:"notes"`
 	OrgID              string  `json:"org_id" validate:"omitempty,uuid"`
+	AllowFlightOverlap bool    `json:"allow_flight_overlap"`
 }
 
 type taskResponse struct {
-	ID                 uuid.UUID        `json:"id"`
-	OrgID              uuid.UUID        `json:"org_id"`
-	AircraftID         uuid.UUID        `json:"aircraft_id"`
-	ProgramID          *uuid.UUID       `json:"program_id,omitempty"`
-	Type               domain.TaskType  `json:"type"`
-	State              domain.TaskState `json:"state"`
-	StartTime          time.Time        `json:"start_time"`
-	EndTime            time.Time        `json:"end_time"`
-	AssignedMechanicID *uuid.UUID       `json:"assigned_mechanic_id,omitempty"`
-	Notes              string           `json:"notes"`
-	CreatedAt          time.Time        `json:"created_at"`
-	UpdatedAt          time.Time        `json:"updated_at"`
+	ID                 uuid.UUID           `json:"id"`
+	OrgID              uuid.UUID           `json:"org_id"`
+	AircraftID         uuid.UUID           `json:"aircraft_id"`
+	ProgramID          *uuid.UUID          `json:"program_id,omitempty"`
+	WorkPackageID      *uuid.UUID          `json:"work_package_id,omitempty"`
+	BayID              *uuid.UUID          `json:"bay_id,omitempty"`
+	BaySlot            *int                `json:"bay_slot,omitempty"`
+	Type               domain.TaskType     `json:"type"`
+	State              domain.TaskState    `json:"state"`
+	Priority           domain.TaskPriority `json:"priority"`
+	StartTime          time.Time           `json:"start_time"`
+	EndTime            time.Time           `json:"end_time"`
+	AssignedMechanicID *uuid.UUID          `json:"assigned_mechanic_id,omitempty"`
+	Notes              string              `json:"notes"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	// FlightConflicts lists the planned flights a task scheduled with
+	// allow_flight_overlap overlaps.
+	FlightConflicts []plannedFlightResponse `json:"flight_conflicts,omitempty"`
 }
 
 type taskDetailResponse struct {
@@ -108,6 +127,15 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		}
 		programID = &parsed
 	}
+	var bayID *uuid.UUID
+	if req.BayID != "" {
+		parsed, err := uuid.Parse(req.BayID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay_id")
+			return
+		}
+		bayID = &parsed
+	}
 	var mechanicID *uuid.UUID
 	if req.AssignedMechanicID != "" {
 		parsed, err := uuid.Parse(req.AssignedMechanicID)
@@ -118,18 +146,39 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		mechanicID = &parsed
 	}
 
-	created, err := servicesReg.Tasks.Create(r.Context(), actor, services.TaskCreateInput{
+	input := services.TaskCreateInput{
 		OrgID:              &orgID,
 		AircraftID:         aircraftID,
 		ProgramID:          programID,
+		BayID:              bayID,
 		Type:               taskType,
+		Priority:           domain.TaskPriority(req.Priority),
 		StartTime:          startTime,
 		EndTime:            endTime,
 		AssignedMechanicID: mechanicID,
 		Notes:              req.Notes,
-	})
+		Preempt:            req.Preempt,
+		AllowFlightOverlap: req.AllowFlightOverlap,
+	}
+
+	if req.DryRun {
+		if !req.Preempt {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "dry_run requires preempt")
+			return
+		}
+		plan, err := servicesReg.Tasks.PlanPreemption(r.Context(), actor, input)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mapPreemptionPlan(plan))
+		return
+	}
+
+	created, err := servicesReg.Tasks.Create(r.Context(), actor, input)
 	if err != nil {
-		if errors.Is(err, domain.ErrConflict) {
+		var capacityConflict *domain.CapacityConflictError
+		if errors.Is(err, domain.ErrConflict) && !errors.As(err, &capacityConflict) {
 			writeError(w, r, http.StatusConflict, "CONFLICT", "maintenance window overlaps existing task")
 			return
 		}
@@ -137,7 +186,11 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, mapTask(created))
+	resp := mapTask(created)
+	if req.AllowFlightOverlap {
+		resp.FlightConflicts = flightConflicts(r, servicesReg.Tasks, created)
+	}
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 func ListTasks(w http.ResponseWriter, r *http.Request) {
@@ -173,6 +226,22 @@ func ListTasks(w http.ResponseWriter, r *http.Request) {
 		}
 		filter.AircraftID = &id
 	}
+	if workPackage := query.Get("work_package_id"); workPackage != "" {
+		id, err := uuid.Parse(workPackage)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work_package_id")
+			return
+		}
+		filter.WorkPackageID = &id
+	}
+	if bay := query.Get("bay_id"); bay != "" {
+		id, err := uuid.Parse(bay)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay_id")
+			return
+		}
+		filter.BayID = &id
+	}
 	if state := query.Get("state"); state != "" {
 		value := domain.TaskState(state)
 		if !validTaskState(value) {
@@ -331,7 +400,7 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
 		return
 	}
-	if req.ProgramID == "" && req.Type == "" && req.StartTime == "" && req.EndTime == "" && req.AssignedMechanicID == "" && req.Notes == nil {
+	if req.ProgramID == "" && req.BayID == "" && req.Type == "" && req.StartTime == "" && req.EndTime == "" && req.AssignedMechanicID == "" && req.Notes == nil {
 		writeError(w, r, http.StatusBadRequest, "VALIDATION", "no changes provided")
 		return
 	}
@@ -350,6 +419,14 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		}
 		input.ProgramID = &parsed
 	}
+	if req.BayID != "" {
+		parsed, err := uuid.Parse(req.BayID)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid bay_id")
+			return
+		}
+		input.BayID = &parsed
+	}
 	if req.Type != "" {
 		value := domain.TaskType(req.Type)
 		if !validTaskType(value) {
@@ -385,17 +462,33 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if req.Notes != nil {
 		input.Notes = req.Notes
 	}
+	input.AllowFlightOverlap = req.AllowFlightOverlap
 
 	updated, err := servicesReg.Tasks.Update(r.Context(), actor, orgID, id, input)
 	if err != nil {
-		if errors.Is(err, domain.ErrConflict) {
+		var capacityConflict *domain.CapacityConflictError
+		if errors.Is(err, domain.ErrConflict) && !errors.As(err, &capacityConflict) {
 			writeError(w, r, http.StatusConflict, "CONFLICT", "maintenance window overlaps existing task")
 			return
 		}
 		writeDomainError(w, r, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, mapTask(updated))
+	resp := mapTask(updated)
+	if req.AllowFlightOverlap {
+		resp.FlightConflicts = flightConflicts(r, servicesReg.Tasks, updated)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// flightConflicts returns the planned flights the task overlaps. The task
+// is already saved, so a failed lookup only drops the warning.
+func flightConflicts(r *http.Request, tasks *services.TaskService, task domain.MaintenanceTask) []plannedFlightResponse {
+	flights, err := tasks.OverlappingFlights(r.Context(), task)
+	if err != nil || len(flights) == 0 {
+		return nil
+	}
+	return mapPlannedFlights(flights)
 }
 
 func DeleteTask(w http.ResponseWriter, r *http.Request) {
@@ -439,8 +532,12 @@ func mapTask(task domain.MaintenanceTask) taskResponse {
 		OrgID:              task.OrgID,
 		AircraftID:         task.AircraftID,
 		ProgramID:          task.ProgramID,
+		WorkPackageID:      task.WorkPackageID,
+		BayID:              task.BayID,
+		BaySlot:            task.BaySlot,
 		Type:               task.Type,
 		State:              task.State,
+		Priority:           task.Priority,
 		StartTime:          task.StartTime.UTC(),
 		EndTime:            task.EndTime.UTC(),
 		AssignedMechanicID: task.AssignedMechanicID,
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type utilizationCreateRequest struct {
+	OrgID        string   `json:"org_id" validate:"omitempty,uuid"`
+	EntryType    string   `json:"entry_type" validate:"required,oneof=flight daily"`
+	Date         string   `json:"date" validate:"required,rfc3339"`
+	FlightNumber string   `json:"flight_number" validate:"omitempty,max=16"`
+	BlockHours   *float64 `json:"block_hours" validate:"omitempty,min=0"`
+	Cycles       *int     `json:"cycles" validate:"omitempty,min=0"`
+	HoursTotal   *float64 `json:"hours_total" validate:"omitempty,min=0"`
+	CyclesTotal  *int     `json:"cycles_total" validate:"omitempty,min=0"`
+}
+
+type utilizationResponse struct {
+	ID           uuid.UUID                   `json:"id"`
+	OrgID        uuid.UUID                   `json:"org_id"`
+	AircraftID   uuid.UUID                   `json:"aircraft_id"`
+	EntryType    domain.UtilizationEntryType `json:"entry_type"`
+	Date         string                      `json:"date"`
+	FlightNumber string                      `json:"flight_number,omitempty"`
+	BlockHours   float64                     `json:"block_hours"`
+	Cycles       int                         `json:"cycles"`
+	HoursTotal   float64                     `json:"hours_total"`
+	CyclesTotal  int                         `json:"cycles_total"`
+	RecordedBy   *uuid.UUID                  `json:"recorded_by,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+}
+
+func CreateAircraftUtilization(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Utilization == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	var req utilizationCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	date, err := time.Parse(time.RFC3339, req.Date)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid date")
+		return
+	}
+	input := services.UtilizationRecordInput{
+		OrgID:        &orgID,
+		AircraftID:   aircraftID,
+		EntryType:    domain.UtilizationEntryType(req.EntryType),
+		Date:         date,
+		FlightNumber: req.FlightNumber,
+		BlockHours:   req.BlockHours,
+		Cycles:       req.Cycles,
+		HoursTotal:   req.HoursTotal,
+		CyclesTotal:  req.CyclesTotal,
+	}
+	created, err := servicesReg.Utilization.Record(r.Context(), actor, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapUtilization(created))
+}
+
+func ListAircraftUtilization(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.Utilization == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	aircraftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft id")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.AircraftUtilizationFilter{AircraftID: &aircraftID}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if from := query.Get("from"); from != "" {
+		value, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid from")
+			return
+		}
+		filter.From = &value
+	}
+	if to := query.Get("to"); to != "" {
+		value, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid to")
+			return
+		}
+		filter.To = &value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+
+	items, err := servicesReg.Utilization.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]utilizationResponse, 0, len(items))
+	for _, entry := range items {
+		resp = append(resp, mapUtilization(entry))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func mapUtilization(entry domain.AircraftUtilization) utilizationResponse {
+	return utilizationResponse{
+		ID:           entry.ID,
+		OrgID:        entry.OrgID,
+		AircraftID:   entry.AircraftID,
+		EntryType:    entry.EntryType,
+		Date:         entry.UtilizationDate.Format("2006-01-02"),
+		FlightNumber: entry.FlightNumber,
+		BlockHours:   entry.BlockHours,
+		Cycles:       entry.Cycles,
+		HoursTotal:   entry.HoursTotal,
+		CyclesTotal:  entry.CyclesTotal,
+		RecordedBy:   entry.RecordedBy,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+func newUtilizationTestRegistry(t *testing.T, orgID uuid.UUID) (middleware.ServiceRegistry, *fakeAircraftRepo, *fakeOutboxRepo, domain.Aircraft) {
+	t.Helper()
+	aircraftRepo := newFakeAircraftRepo()
+	outbox := &fakeOutboxRepo{}
+	aircraft := domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N123AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    3,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+	}
+	_, _ = aircraftRepo.Create(context.Background(), aircraft)
+	utilizationService := &services.AircraftUtilizationService{
+		Utilization: newFakeUtilizationRepo(aircraftRepo),
+		Aircraft:    aircraftRepo,
+		Outbox:      outbox,
+	}
+	return middleware.ServiceRegistry{Utilization: utilizationService}, aircraftRepo, outbox, aircraft
+}
+
+func TestCreateAircraftUtilizationRollsUpTotals(t *testing.T) {
+	orgID := uuid.New()
+	registry, aircraftRepo, outbox, aircraft := newUtilizationTestRegistry(t, orgID)
+
+	date := time.Now().UTC().Add(-24 * time.Hour)
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/aircraft/"+aircraft.ID.String()+"/utilization", map[string]any{
+		"entry_type":    "flight",
+		"date":          date.Format(time.RFC3339),
+		"flight_number": "AM101",
+		"block_hours":   2.5,
+		"cycles":        1,
+	})
+	req = withPrincipal(req, orgID, domain.RoleMechanic)
+	req = withRouteParam(req, "id", aircraft.ID.String())
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateAircraftUtilization))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp utilizationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.HoursTotal != 1002.5 || resp.CyclesTotal != 401 {
+		t.Fatalf("expected totals 1002.5/401, got %v/%d", resp.HoursTotal, resp.CyclesTotal)
+	}
+	updated, err := aircraftRepo.GetByID(context.Background(), orgID, aircraft.ID)
+	if err != nil {
+		t.Fatalf("fetch aircraft: %v", err)
+	}
+	if updated.FlightHoursTotal != 1002 || updated.CyclesTotal != 401 {
+		t.Fatalf("expected aircraft totals 1002/401, got %d/%d", updated.FlightHoursTotal, updated.CyclesTotal)
+	}
+	if len(outbox.events) != 1 || outbox.events[0].EventType != "aircraft_utilization_recorded" {
+		t.Fatalf("expected aircraft_utilization_recorded event, got %+v", outbox.events)
+	}
+}
+
+func TestCreateAircraftUtilizationRejectsDecreasingTotals(t *testing.T) {
+	orgID := uuid.New()
+	registry, _, outbox, aircraft := newUtilizationTestRegistry(t, orgID)
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/aircraft/"+aircraft.ID.String()+"/utilization", map[string]any{
+		"entry_type":   "daily",
+		"date":         time.Now().UTC().Format(time.RFC3339),
+		"hours_total":  990,
+		"cycles_total": 410,
+	})
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", aircraft.ID.String())
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateAircraftUtilization))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if len(outbox.events) != 0 {
+		t.Fatalf("expected no events, got %d", len(outbox.events))
+	}
+}
+
+func TestListAircraftUtilization(t *testing.T) {
+	orgID := uuid.New()
+	registry, _, _, aircraft := newUtilizationTestRegistry(t, orgID)
+	start := time.Now().UTC().Add(-48 * time.Hour)
+	for i := 0; i < 2; i++ {
+		hours := 3.0
+		cycles := 2
+		_, err := registry.Utilization.Record(context.Background(), app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleScheduler}, services.UtilizationRecordInput{
+			AircraftID: aircraft.ID,
+			EntryType:  domain.UtilizationEntryDaily,
+			Date:       start.Add(time.Duration(i) * 24 * time.Hour),
+			BlockHours: &hours,
+			Cycles:     &cycles,
+		})
+		if err != nil {
+			t.Fatalf("record utilization: %v", err)
+		}
+	}
+
+	req := newJSONRequest(t, http.MethodGet, "/api/v1/aircraft/"+aircraft.ID.String()+"/utilization", nil)
+	req = withPrincipal(req, orgID, domain.RoleScheduler)
+	req = withRouteParam(req, "id", aircraft.ID.String())
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(ListAircraftUtilization))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp []utilizationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(resp))
+	}
+}
+
+func TestCreateAircraftUtilizationAccruesInstalledParts(t *testing.T) {
+	orgID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	itemRepo := newFakePartItemRepo()
+	aircraft := domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N456AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+	}
+	_, _ = aircraftRepo.Create(context.Background(), aircraft)
+	limit := 5000
+	installed := domain.PartItem{
+		ID:              uuid.New(),
+		OrgID:           orgID,
+		DefinitionID:    uuid.New(),
+		SerialNumber:    "LG-001",
+		Status:          domain.PartItemInStock,
+		AircraftID:      &aircraft.ID,
+		LifeLimitCycles: &limit,
+		HoursSinceNew:   300,
+		CyclesSinceNew:  120,
+	}
+	spare := domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		DefinitionID: uuid.New(),
+		SerialNumber: "LG-002",
+		Status:       domain.PartItemInStock,
+	}
+	_, _ = itemRepo.Create(context.Background(), installed)
+	_, _ = itemRepo.Create(context.Background(), spare)
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+	utilizationRepo.items = itemRepo
+	registry := middleware.ServiceRegistry{Utilization: &services.AircraftUtilizationService{
+		Utilization: utilizationRepo,
+		Aircraft:    aircraftRepo,
+	}}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/aircraft/"+aircraft.ID.String()+"/utilization", map[string]any{
+		"entry_type":  "daily",
+		"date":        time.Now().UTC().Format(time.RFC3339),
+		"block_hours": 6.5,
+		"cycles":      3,
+	})
+	req = withPrincipal(req, orgID, domain.RoleMechanic)
+	req = withRouteParam(req, "id", aircraft.ID.String())
+
+	rr := httptest.NewRecorder()
+	handler := middleware.InjectServices(registry)(http.HandlerFunc(CreateAircraftUtilization))
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	got, _ := itemRepo.GetByID(context.Background(), orgID, installed.ID)
+	if got.HoursSinceNew != 306.5 || got.CyclesSinceNew != 123 || got.HoursSinceOverhaul != 6.5 || got.CyclesSinceOverhaul != 3 {
+		t.Fatalf("expected installed part to accrue usage, got %+v", got)
+	}
+	if remaining := got.RemainingCycles(); remaining == nil || *remaining != 4877 {
+		t.Fatalf("expected 4877 remaining cycles, got %v", remaining)
+	}
+	untouched, _ := itemRepo.GetByID(context.Background(), orgID, spare.ID)
+	if untouched.HoursSinceNew != 0 || untouched.CyclesSinceNew != 0 {
+		t.Fatalf("expected spare part to be unchanged, got %+v", untouched)
+	}
+}
+
+func TestCreateAircraftUtilizationRederivesAfterConcurrentEntry(t *testing.T) {
+	orgID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	itemRepo := newFakePartItemRepo()
+	aircraft := domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N789AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+	}
+	_, _ = aircraftRepo.Create(context.Background(), aircraft)
+	installed := domain.PartItem{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		DefinitionID:   uuid.New(),
+		SerialNumber:   "APU-001",
+		Status:         domain.PartItemInStock,
+		AircraftID:     &aircraft.ID,
+		HoursSinceNew:  100,
+		CyclesSinceNew: 50,
+	}
+	_, _ = itemRepo.Create(context.Background(), installed)
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+	utilizationRepo.items = itemRepo
+	service := &services.AircraftUtilizationService{
+		Utilization: utilizationRepo,
+		Aircraft:    aircraftRepo,
+	}
+	actor := app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleMechanic}
+	date := time.Now().UTC()
+	flight := func(number string, hours float64) services.UtilizationRecordInput {
+		cycles := 1
+		return services.UtilizationRecordInput{
+			AircraftID:   aircraft.ID,
+			EntryType:    domain.UtilizationEntryFlight,
+			Date:         date,
+			FlightNumber: number,
+			BlockHours:   &hours,
+			Cycles:       &cycles,
+		}
+	}
+	// The second flight is derived from the same totals as the first, but the
+	// first is stored before it.
+	utilizationRepo.beforeCreate = func() {
+		if _, err := service.Record(context.Background(), actor, flight("AM101", 2.5)); err != nil {
+			t.Errorf("record first flight: %v", err)
+		}
+	}
+
+	created, err := service.Record(context.Background(), actor, flight("AM102", 3))
+	if err != nil {
+		t.Fatalf("record second flight: %v", err)
+	}
+	if created.BlockHours != 3 || created.HoursTotal != 1005.5 || created.CyclesTotal != 402 {
+		t.Fatalf("expected the second flight on top of the first, got %+v", created)
+	}
+	updated, _ := aircraftRepo.GetByID(context.Background(), orgID, aircraft.ID)
+	if updated.FlightHoursTotal != 1005 || updated.CyclesTotal != 402 {
+		t.Fatalf("expected aircraft totals 1005/402, got %d/%d", updated.FlightHoursTotal, updated.CyclesTotal)
+	}
+	part, _ := itemRepo.GetByID(context.Background(), orgID, installed.ID)
+	if part.HoursSinceNew != 105.5 || part.CyclesSinceNew != 52 {
+		t.Fatalf("expected part to accrue both flights once, got %v/%d", part.HoursSinceNew, part.CyclesSinceNew)
+	}
+}
@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type workPackageCreateRequest struct {
+	OrgID      string `json:"org_id" validate:"omitempty,uuid"`
+	AircraftID string `json:"aircraft_id" validate:"required,uuid"`
+	Name       string `json:"name" validate:"required"`
+	VisitStart string `json:"visit_start" validate:"required,rfc3339"`
+	VisitEnd   string `json:"visit_end" validate:"required,rfc3339"`
+	Notes      string `json:"notes"`
+}
+
+type workPackageUpdateRequest struct {
+	OrgID      string  `json:"org_id" validate:"omitempty,uuid"`
+	Name       *string `json:"name" validate:"omitempty,min=1"`
+	VisitStart string  `json:"visit_start" validate:"omitempty,rfc3339"`
+	VisitEnd   string  `json:"visit_end" validate:"omitempty,rfc3339"`
+	Notes      *string `json:"notes"`
+}
+
+type workPackageStateRequest struct {
+	OrgID    string `json:"org_id" validate:"omitempty,uuid"`
+	NewState string `json:"new_state" validate:"required,oneof=planned in_progress completed cancelled"`
+}
+
+type workPackageTasksRequest struct {
+	OrgID   string   `json:"org_id" validate:"omitempty,uuid"`
+	TaskIDs []string `json:"task_ids" validate:"required,min=1,max=200,dive,uuid"`
+}
+
+type workPackageResponse struct {
+	ID         uuid.UUID               `json:"id"`
+	OrgID      uuid.UUID               `json:"org_id"`
+	AircraftID uuid.UUID               `json:"aircraft_id"`
+	Name       string                  `json:"name"`
+	State      domain.WorkPackageState `json:"state"`
+	VisitStart time.Time               `json:"visit_start"`
+	VisitEnd   time.Time               `json:"visit_end"`
+	Notes      string                  `json:"notes"`
+	CreatedAt  time.Time               `json:"created_at"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+type workPackageSummaryResponse struct {
+	Tasks               int  `json:"tasks"`
+	Scheduled           int  `json:"scheduled"`
+	InProgress          int  `json:"in_progress"`
+	Completed           int  `json:"completed"`
+	Cancelled           int  `json:"cancelled"`
+	CompletionPercent   int  `json:"completion_percent"`
+	PartsReserved       int  `json:"parts_reserved"`
+	PartsUsed           int  `json:"parts_used"`
+	PartsReleased       int  `json:"parts_released"`
+	PartsReady          bool `json:"parts_ready"`
+	ComplianceItems     int  `json:"compliance_items"`
+	ComplianceSignedOff int  `json:"compliance_signed_off"`
+	ComplianceComplete  bool `json:"compliance_complete"`
+	OpenFindings        int  `json:"open_findings"`
+}
+
+type workPackageDetailResponse struct {
+	WorkPackage workPackageResponse        `json:"work_package"`
+	Tasks       []taskResponse             `json:"tasks"`
+	Summary     workPackageSummaryResponse `json:"summary"`
+}
+
+type workPackageAutoFillResponse struct {
+	Added    []taskResponse            `json:"added"`
+	Unplaced []programForecastResponse `json:"unplaced"`
+}
+
+func CreateWorkPackage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	var req workPackageCreateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	aircraftID, err := uuid.Parse(req.AircraftID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+		return
+	}
+	visitStart, err := time.Parse(time.RFC3339, req.VisitStart)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_start")
+		return
+	}
+	visitEnd, err := time.Parse(time.RFC3339, req.VisitEnd)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_end")
+		return
+	}
+	created, err := servicesReg.WorkPackages.Create(r.Context(), actor, services.WorkPackageCreateInput{
+		OrgID:      &orgID,
+		AircraftID: aircraftID,
+		Name:       req.Name,
+		VisitStart: visitStart,
+		VisitEnd:   visitEnd,
+		Notes:      req.Notes,
+	})
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, mapWorkPackage(created))
+}
+
+func ListWorkPackages(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	query := r.URL.Query()
+	filter := ports.WorkPackageFilter{}
+	if actor.IsAdmin() {
+		if org := query.Get("org_id"); org != "" {
+			orgID, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			filter.OrgID = &orgID
+		}
+	}
+	if aircraft := query.Get("aircraft_id"); aircraft != "" {
+		id, err := uuid.Parse(aircraft)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid aircraft_id")
+			return
+		}
+		filter.AircraftID = &id
+	}
+	if state := query.Get("state"); state != "" {
+		value := domain.WorkPackageState(state)
+		if !validWorkPackageState(value) {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid state")
+			return
+		}
+		filter.State = &value
+	}
+	if visitFrom := query.Get("visit_from"); visitFrom != "" {
+		value, err := time.Parse(time.RFC3339, visitFrom)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_from")
+			return
+		}
+		filter.VisitFrom = &value
+	}
+	if visitTo := query.Get("visit_to"); visitTo != "" {
+		value, err := time.Parse(time.RFC3339, visitTo)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_to")
+			return
+		}
+		filter.VisitTo = &value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := parseInt(limit)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid limit")
+			return
+		}
+		filter.Limit = value
+	}
+	if offset := query.Get("offset"); offset != "" {
+		value, err := parseInt(offset)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid offset")
+			return
+		}
+		filter.Offset = value
+	}
+
+	items, err := servicesReg.WorkPackages.List(r.Context(), actor, filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]workPackageResponse, 0, len(items))
+	for _, pkg := range items {
+		resp = append(resp, mapWorkPackage(pkg))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func GetWorkPackage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	detail, err := servicesReg.WorkPackages.Detail(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	tasks := make([]taskResponse, 0, len(detail.Tasks))
+	for _, task := range detail.Tasks {
+		tasks = append(tasks, mapTask(task))
+	}
+	writeJSON(w, http.StatusOK, workPackageDetailResponse{
+		WorkPackage: mapWorkPackage(detail.Package),
+		Tasks:       tasks,
+		Summary:     mapWorkPackageSummary(detail.Summary),
+	})
+}
+
+func UpdateWorkPackage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	var req workPackageUpdateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	input := services.WorkPackageUpdateInput{
+		Name:  req.Name,
+		Notes: req.Notes,
+	}
+	if req.VisitStart != "" {
+		value, err := time.Parse(time.RFC3339, req.VisitStart)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_start")
+			return
+		}
+		input.VisitStart = &value
+	}
+	if req.VisitEnd != "" {
+		value, err := time.Parse(time.RFC3339, req.VisitEnd)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid visit_end")
+			return
+		}
+		input.VisitEnd = &value
+	}
+	updated, err := servicesReg.WorkPackages.Update(r.Context(), actor, orgID, id, input)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapWorkPackage(updated))
+}
+
+func DeleteWorkPackage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.WorkPackages.Delete(r.Context(), actor, orgID, id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TransitionWorkPackageState(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	var req workPackageStateRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	updated, err := servicesReg.WorkPackages.TransitionState(r.Context(), actor, orgID, id, domain.WorkPackageState(req.NewState))
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapWorkPackage(updated))
+}
+
+func AddWorkPackageTasks(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	var req workPackageTasksRequest
+	if err := decodeAndValidateJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", err.Error())
+		return
+	}
+	orgID, err := resolveOrgID(actor, req.OrgID)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+		return
+	}
+	taskIDs := make([]uuid.UUID, 0, len(req.TaskIDs))
+	for _, raw := range req.TaskIDs {
+		taskID, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task_ids")
+			return
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+	added, err := servicesReg.WorkPackages.AddTasks(r.Context(), actor, orgID, id, taskIDs)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := make([]taskResponse, 0, len(added))
+	for _, task := range added {
+		resp = append(resp, mapTask(task))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func RemoveWorkPackageTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskId"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid task id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	if err := servicesReg.WorkPackages.RemoveTask(r.Context(), actor, orgID, id, taskID); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func AutoFillWorkPackage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFromRequest(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "AUTH", "unauthorized")
+		return
+	}
+	servicesReg, ok := servicesFromRequest(r)
+	if !ok || servicesReg.WorkPackages == nil {
+		writeError(w, r, http.StatusInternalServerError, "INTERNAL", "service unavailable")
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid work package id")
+		return
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() {
+		if org := r.URL.Query().Get("org_id"); org != "" {
+			parsed, err := uuid.Parse(org)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "VALIDATION", "invalid org_id")
+				return
+			}
+			orgID = parsed
+		}
+	}
+	result, err := servicesReg.WorkPackages.AutoFill(r.Context(), actor, orgID, id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	resp := workPackageAutoFillResponse{
+		Added:    make([]taskResponse, 0, len(result.Added)),
+		Unplaced: make([]programForecastResponse, 0, len(result.Unplaced)),
+	}
+	for _, task := range result.Added {
+		resp.Added = append(resp.Added, mapTask(task))
+	}
+	for _, item := range result.Unplaced {
+		resp.Unplaced = append(resp.Unplaced, mapProgramForecast(item))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func mapWorkPackage(pkg domain.WorkPackage) workPackageResponse {
+	return workPackageResponse{
+		ID:         pkg.ID,
+		OrgID:      pkg.OrgID,
+		AircraftID: pkg.AircraftID,
+		Name:       pkg.Name,
+		State:      pkg.State,
+		VisitStart: pkg.VisitStart.UTC(),
+		VisitEnd:   pkg.VisitEnd.UTC(),
+		Notes:      pkg.Notes,
+		CreatedAt:  pkg.CreatedAt,
+		UpdatedAt:  pkg.UpdatedAt,
+	}
+}
+
+func mapWorkPackageSummary(summary domain.WorkPackageSummary) workPackageSummaryResponse {
+	return workPackageSummaryResponse{
+		Tasks:               summary.Tasks,
+		Scheduled:           summary.Scheduled,
+		InProgress:          summary.InProgress,
+		Completed:           summary.Completed,
+		Cancelled:           summary.Cancelled,
+		CompletionPercent:   summary.CompletionPercent,
+		PartsReserved:       summary.PartsReserved,
+		PartsUsed:           summary.PartsUsed,
+		PartsReleased:       summary.PartsReleased,
+		PartsReady:          summary.PartsReady,
+		ComplianceItems:     summary.ComplianceItems,
+		ComplianceSignedOff: summary.ComplianceSignedOff,
+		ComplianceComplete:  summary.ComplianceComplete(),
+		OpenFindings:        summary.OpenFindings,
+	}
+}
+
+func validWorkPackageState(state domain.WorkPackageState) bool {
+	switch state {
+	case domain.WorkPackagePlanned, domain.WorkPackageInProgress, domain.WorkPackageCompleted, domain.WorkPackageCancelled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type workPackageFixture struct {
+	orgID       uuid.UUID
+	aircraft    domain.Aircraft
+	taskRepo    *fakeTaskRepo
+	programRepo *fakeProgramRepo
+	packageRepo *fakeWorkPackageRepo
+	compliance  *fakeComplianceRepo
+	registry    middleware.ServiceRegistry
+	visitStart  time.Time
+	visitEnd    time.Time
+	workPackage domain.WorkPackage
+}
+
+func newWorkPackageFixture(t *testing.T) *workPackageFixture {
+	t.Helper()
+	orgID := uuid.New()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft := seedTypedAircraft(t, aircraftRepo, orgID, "N500", nil)
+	taskRepo := newFakeTaskRepo()
+	programRepo := newFakeProgramRepo()
+	complianceRepo := newFakeComplianceRepo()
+	packageRepo := newFakeWorkPackageRepo(taskRepo)
+	taskService := &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo, Compliance: complianceRepo}
+	programService := &services.MaintenanceProgramService{
+		Programs: programRepo,
+		Aircraft: aircraftRepo,
+		Tasks:    taskRepo,
+		TaskSvc:  taskService,
+	}
+	packageService := &services.WorkPackageService{
+		Packages:   packageRepo,
+		Tasks:      taskRepo,
+		Aircraft:   aircraftRepo,
+		Compliance: complianceRepo,
+		Programs:   programService,
+		TaskSvc:    taskService,
+	}
+	visitStart := time.Now().Add(24 * time.Hour).Truncate(time.Hour).UTC()
+	visitEnd := visitStart.Add(8 * time.Hour)
+	pkg, err := packageService.Create(context.Background(), app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleScheduler}, services.WorkPackageCreateInput{
+		AircraftID: aircraft.ID,
+		Name:       "A-check",
+		VisitStart: visitStart,
+		VisitEnd:   visitEnd,
+	})
+	if err != nil {
+		t.Fatalf("create work package: %v", err)
+	}
+	return &workPackageFixture{
+		orgID:       orgID,
+		aircraft:    aircraft,
+		taskRepo:    taskRepo,
+		programRepo: programRepo,
+		packageRepo: packageRepo,
+		compliance:  complianceRepo,
+		registry:    middleware.ServiceRegistry{WorkPackages: packageService},
+		visitStart:  visitStart,
+		visitEnd:    visitEnd,
+		workPackage: pkg,
+	}
+}
+
+func (f *workPackageFixture) seedTask(t *testing.T, programID *uuid.UUID, start time.Time, state domain.TaskState) domain.MaintenanceTask {
+	t.Helper()
+	task, err := f.taskRepo.Create(context.Background(), domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      f.orgID,
+		AircraftID: f.aircraft.ID,
+		ProgramID:  programID,
+		Type:       domain.TaskTypeInspection,
+		State:      state,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	return task
+}
+
+func (f *workPackageFixture) serve(t *testing.T, req *http.Request, handler http.HandlerFunc, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req = withPrincipal(req, f.orgID, domain.RoleScheduler)
+	for key, value := range params {
+		req = withRouteParam(req, key, value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.InjectServices(f.registry)(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCreateWorkPackage(t *testing.T) {
+	f := newWorkPackageFixture(t)
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/work-packages", map[string]any{
+		"aircraft_id": f.aircraft.ID.String(),
+		"name":        "C-check",
+		"visit_start": f.visitStart.Add(48 * time.Hour).Format(time.RFC3339),
+		"visit_end":   f.visitStart.Add(96 * time.Hour).Format(time.RFC3339),
+	})
+	rr := f.serve(t, req, CreateWorkPackage, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp workPackageResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.State != domain.WorkPackagePlanned {
+		t.Fatalf("expected state planned, got %s", resp.State)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/work-packages", map[string]any{
+		"aircraft_id": f.aircraft.ID.String(),
+		"name":        "Backwards",
+		"visit_start": f.visitEnd.Format(time.RFC3339),
+		"visit_end":   f.visitStart.Format(time.RFC3339),
+	})
+	rr = f.serve(t, req, CreateWorkPackage, nil)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for inverted visit window, got %d", rr.Code)
+	}
+}
+
+func TestAddWorkPackageTasksAndRollUp(t *testing.T) {
+	f := newWorkPackageFixture(t)
+	inside := f.seedTask(t, nil, f.visitStart, domain.TaskStateScheduled)
+	outside := f.seedTask(t, nil, f.visitEnd.Add(time.Hour), domain.TaskStateScheduled)
+	signedAt := time.Now().UTC()
+	signedBy := uuid.New()
+	_ = f.compliance.Create(context.Background(), domain.ComplianceItem{ID: uuid.New(), OrgID: f.orgID, TaskID: inside.ID, Description: "Torque check", Result: domain.CompliancePass, SignOffUserID: &signedBy, SignOffTime: &signedAt})
+	_ = f.compliance.Create(context.Background(), domain.ComplianceItem{ID: uuid.New(), OrgID: f.orgID, TaskID: inside.ID, Description: "Leak check", Result: domain.CompliancePending})
+	params := map[string]string{"id": f.workPackage.ID.String()}
+
+	req := newJSONRequest(t, http.MethodPost, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/tasks", map[string]any{
+		"task_ids": []string{outside.ID.String()},
+	})
+	rr := f.serve(t, req, AddWorkPackageTasks, params)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for task outside the visit, got %d", rr.Code)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/tasks", map[string]any{
+		"task_ids": []string{inside.ID.String()},
+	})
+	rr = f.serve(t, req, AddWorkPackageTasks, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/work-packages/"+f.workPackage.ID.String(), nil)
+	rr = f.serve(t, req, GetWorkPackage, params)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var detail workPackageDetailResponse
+	if err := json.NewDecoder(rr.Body).Decode(&detail); err != nil {
+		t.Fatalf("decode detail: %v", err)
+	}
+	if len(detail.Tasks) != 1 || detail.Tasks[0].ID != inside.ID {
+		t.Fatalf("expected the added task, got %+v", detail.Tasks)
+	}
+	if detail.Tasks[0].WorkPackageID == nil || *detail.Tasks[0].WorkPackageID != f.workPackage.ID {
+		t.Fatalf("expected task to reference the work package")
+	}
+	summary := detail.Summary
+	if summary.Tasks != 1 || summary.Scheduled != 1 || summary.CompletionPercent != 0 {
+		t.Fatalf("unexpected task roll-up %+v", summary)
+	}
+	if summary.ComplianceItems != 2 || summary.ComplianceSignedOff != 1 || summary.ComplianceComplete {
+		t.Fatalf("unexpected compliance roll-up %+v", summary)
+	}
+	if !summary.PartsReady {
+		t.Fatalf("expected parts ready without reservations")
+	}
+}
+
+func TestWorkPackageStateTransitions(t *testing.T) {
+	f := newWorkPackageFixture(t)
+	params := map[string]string{"id": f.workPackage.ID.String()}
+	transition := func(state domain.WorkPackageState) *httptest.ResponseRecorder {
+		req := newJSONRequest(t, http.MethodPatch, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/state", map[string]any{
+			"new_state": string(state),
+		})
+		return f.serve(t, req, TransitionWorkPackageState, params)
+	}
+
+	if rr := transition(domain.WorkPackageInProgress); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for empty package, got %d", rr.Code)
+	}
+
+	task := f.seedTask(t, nil, f.visitStart, domain.TaskStateScheduled)
+	task.WorkPackageID = &f.workPackage.ID
+	_, _ = f.taskRepo.Update(context.Background(), task)
+
+	if rr := transition(domain.WorkPackageInProgress); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 to start, got %d", rr.Code)
+	}
+	if rr := transition(domain.WorkPackageCompleted); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 with open tasks, got %d", rr.Code)
+	}
+
+	_, _ = f.taskRepo.UpdateState(context.Background(), f.orgID, task.ID, domain.TaskStateCompleted, "done", time.Now().UTC())
+	rr := transition(domain.WorkPackageCompleted)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 to complete, got %d", rr.Code)
+	}
+	var resp workPackageResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.State != domain.WorkPackageCompleted {
+		t.Fatalf("expected state completed, got %s", resp.State)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/work-packages/"+f.workPackage.ID.String(), nil)
+	if rr := f.serve(t, req, DeleteWorkPackage, params); rr.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 deleting a completed package, got %d", rr.Code)
+	}
+}
+
+func TestAutoFillWorkPackage(t *testing.T) {
+	f := newWorkPackageFixture(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	createProgram := func(name string, lastPerformed time.Time) domain.MaintenanceProgram {
+		program, err := f.programRepo.Create(ctx, domain.MaintenanceProgram{
+			ID:            uuid.New(),
+			OrgID:         f.orgID,
+			AircraftID:    &f.aircraft.ID,
+			Name:          name,
+			IntervalType:  domain.ProgramIntervalCalendar,
+			IntervalValue: 30,
+			LastPerformed: &lastPerformed,
+		})
+		if err != nil {
+			t.Fatalf("create program: %v", err)
+		}
+		return program
+	}
+	dueNew := createProgram("Cabin inspection", now.AddDate(0, 0, -30))
+	dueExisting := createProgram("Wheel inspection", now.AddDate(0, 0, -29))
+	createProgram("Engine wash", now.AddDate(0, 0, -5))
+	existing := f.seedTask(t, &dueExisting.ID, f.visitEnd.Add(72*time.Hour), domain.TaskStateScheduled)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/work-packages/"+f.workPackage.ID.String()+"/auto-fill", nil)
+	rr := f.serve(t, req, AutoFillWorkPackage, map[string]string{"id": f.workPackage.ID.String()})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp workPackageAutoFillResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Added) != 2 || len(resp.Unplaced) != 0 {
+		t.Fatalf("expected 2 added tasks, got %d added and %d unplaced", len(resp.Added), len(resp.Unplaced))
+	}
+
+	byProgram := make(map[uuid.UUID]taskResponse)
+	for _, task := range resp.Added {
+		if task.ProgramID == nil {
+			t.Fatalf("expected program task, got %+v", task)
+		}
+		if task.WorkPackageID == nil || *task.WorkPackageID != f.workPackage.ID {
+			t.Fatalf("expected task in the work package")
+		}
+		if task.StartTime.Before(f.visitStart) || task.EndTime.After(f.visitEnd) {
+			t.Fatalf("expected task inside the visit window, got %s-%s", task.StartTime, task.EndTime)
+		}
+		byProgram[*task.ProgramID] = task
+	}
+	created, ok := byProgram[dueNew.ID]
+	if !ok || created.ID == existing.ID {
+		t.Fatalf("expected a new task for the due program")
+	}
+	moved, ok := byProgram[dueExisting.ID]
+	if !ok || moved.ID != existing.ID {
+		t.Fatalf("expected the existing task to be pulled into the visit")
+	}
+	if created.StartTime.Before(moved.EndTime) && moved.StartTime.Before(created.EndTime) {
+		t.Fatalf("expected auto-filled tasks not to overlap")
+	}
+}
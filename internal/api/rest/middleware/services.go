@@ -18,7 +18,11 @@ type ServiceRegistry struct {
 	Organizations *services.OrganizationService
 	Users         *services.UserService
 	Aircraft      *services.AircraftService
+	Utilization   *services.AircraftUtilizationService
+	Components    *services.ComponentService
 	Programs      *services.MaintenanceProgramService
+	ProgramTemplates *services.ProgramTemplateService
+	WorkPackages  *services.WorkPackageService
 	Imports       *services.ImportService
 	Webhooks      *services.WebhookService
 	Policies      *services.OrgPolicyService
@@ -27,6 +31,17 @@ type ServiceRegistry struct {
 	Directives     *services.DirectiveService
 	Alerts         *services.AlertService
 	Scheduling     *services.SchedulingService
+	Capacity       *services.CapacityService
+	ScheduleOptimizer *services.ScheduleOptimizerService
+	RescheduleOptions *services.RescheduleOptionService
+	Roster *services.RosterService
+	Flights *services.FlightScheduleService
+	TaskCards *services.TaskCardService
+	Labor *services.LaborService
+	Crew *services.CrewService
+	Defects *services.DefectService
+	Findings *services.FindingService
+	Attachments *services.AttachmentService
 	Metrics        *services.MetricsService
 }
 
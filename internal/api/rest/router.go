@@ -18,10 +18,11 @@ import (
 
 	"github.com/aeromaintain/amss/internal/api/rest/handlers"
 	amiddleware "github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/ports"
 	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
 	postgresinfra "github.com/aeromaintain/amss/internal/infra/postgres"
 	redisinfra "github.com/aeromaintain/amss/internal/infra/redis"
-	"github.com/aeromaintain/amss/internal/infra/streams"
 	"github.com/aeromaintain/amss/internal/infra/ws"
 )
 
@@ -29,17 +30,26 @@ import (
 var WSHub *ws.Hub
 
 type Deps struct {
-	Logger             zerolog.Logger
-	DB                 *pgxpool.Pool
-	Redis              *redis.Client
-	PrometheusEnabled  bool
-	AuthPublicKey      *rsa.PublicKey
-	AuthPrivateKey     *rsa.PrivateKey
-	AccessTokenTTL     time.Duration
-	RefreshTokenTTL    time.Duration
-	AppEnv             string
-	ImportStorageDir   string
+	Logger            zerolog.Logger
+	DB                *pgxpool.Pool
+	Redis             *redis.Client
+	PrometheusEnabled bool
+	AuthPublicKey     *rsa.PublicKey
+	AuthPrivateKey    *rsa.PrivateKey
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	AppEnv            string
+	ImportStorageDir  string
+	// Blobs keeps the content of attachments. Optional; without it the
+	// attachment endpoints are unavailable.
+	Blobs              ports.BlobStore
 	CorsAllowedOrigins []string
+	// ProgramLookAhead is how far ahead of due maintenance programs
+	// generate their tasks.
+	ProgramLookAhead domain.ProgramLookAhead
+	// Services are the application services behind the handlers.
+	// Optional; NewRouter builds them from the other dependencies when nil.
+	Services *amiddleware.ServiceRegistry
 }
 
 func NewRouter(deps Deps) http.Handler {
@@ -68,101 +78,11 @@ func NewRouter(deps Deps) http.Handler {
 	r.Route("/api/v1", func(api chi.Router) {
 		idempotencyStore := &postgresinfra.IdempotencyStore{DB: deps.DB}
 		rateLimiter := &redisinfra.RateLimiter{Client: deps.Redis}
-		locker := &redisinfra.Locker{Client: deps.Redis}
-		auditRepo := &postgresinfra.AuditRepository{DB: deps.DB}
-		outboxRepo := &postgresinfra.OutboxRepository{DB: deps.DB}
-		orgRepo := &postgresinfra.OrganizationRepository{DB: deps.DB}
-		userRepo := &postgresinfra.UserRepository{DB: deps.DB}
-		aircraftRepo := &postgresinfra.AircraftRepository{DB: deps.DB}
-		programRepo := &postgresinfra.MaintenanceProgramRepository{DB: deps.DB}
-		importRepo := &postgresinfra.ImportRepository{DB: deps.DB}
-		importRowRepo := &postgresinfra.ImportRowRepository{DB: deps.DB}
-		webhookRepo := &postgresinfra.WebhookRepository{DB: deps.DB}
-		policyRepo := &postgresinfra.OrgPolicyRepository{DB: deps.DB}
-		certRepo := &postgresinfra.CertificationRepository{DB: deps.DB}
-		taskService := &services.TaskService{
-			Tasks:        &postgresinfra.TaskRepository{DB: deps.DB},
-			Aircraft:     aircraftRepo,
-			Reservations: &postgresinfra.PartReservationRepository{DB: deps.DB},
-			Compliance:   &postgresinfra.ComplianceRepository{DB: deps.DB},
-			Certs:        certRepo,
-			Audit:        auditRepo,
-			Outbox:       outboxRepo,
-		}
-		alertRepo := &postgresinfra.AlertRepository{DB: deps.DB}
-		partDefRepo := &postgresinfra.PartDefinitionRepository{DB: deps.DB}
-		partService := &services.PartReservationService{
-			Reservations:    &postgresinfra.PartReservationRepository{DB: deps.DB},
-			PartItems:       &postgresinfra.PartItemRepository{DB: deps.DB},
-			PartDefinitions: partDefRepo,
-			Tasks:           &postgresinfra.TaskRepository{DB: deps.DB},
-			Alerts:          alertRepo,
-			Locker:          locker,
-			Audit:           auditRepo,
-			Outbox:          outboxRepo,
-		}
-		complianceService := &services.ComplianceService{
-			Compliance: &postgresinfra.ComplianceRepository{DB: deps.DB},
-			Audit:      auditRepo,
-			Outbox:     outboxRepo,
-		}
-		auditQueryService := &services.AuditQueryService{
-			Repo: &postgresinfra.AuditQueryRepository{DB: deps.DB},
-		}
-		catalogService := &services.PartCatalogService{
-			Definitions: partDefRepo,
-			Items:       &postgresinfra.PartItemRepository{DB: deps.DB},
-		}
-		orgService := &services.OrganizationService{
-			Organizations: orgRepo,
-		}
-		userService := &services.UserService{
-			Users: userRepo,
-		}
-		aircraftService := &services.AircraftService{
-			Aircraft: aircraftRepo,
-		}
-		programService := &services.MaintenanceProgramService{
-			Programs: programRepo,
-			Tasks:    taskService.Tasks,
-			TaskSvc:  taskService,
-		}
-		importService := &services.ImportService{
-			Imports: importRepo,
-			Rows:    importRowRepo,
-			Jobs:    &streams.ImportQueue{Client: deps.Redis},
-		}
-		webhookService := &services.WebhookService{
-			Webhooks:     webhookRepo,
-			Outbox:       outboxRepo,
-			RequireHTTPS: deps.AppEnv == "production",
-		}
-		policyService := &services.OrgPolicyService{
-			Policies: policyRepo,
-		}
-		reportService := &services.ReportService{
-			Reports: &postgresinfra.ReportRepository{DB: deps.DB},
-		}
-		certificationService := &services.CertificationService{
-			Certs: certRepo,
-			Audit: auditRepo,
-		}
-		directiveService := &services.DirectiveService{
-			Directives: &postgresinfra.DirectiveRepository{DB: deps.DB},
-			Aircraft:   aircraftRepo,
-			Audit:      auditRepo,
-		}
-		alertService := &services.AlertService{
-			Alerts: alertRepo,
-		}
-		schedulingService := &services.SchedulingService{
-			Tasks:          &postgresinfra.TaskRepository{DB: deps.DB},
-			Dependencies:   &postgresinfra.TaskDependencyRepository{DB: deps.DB},
-			ScheduleEvents: &postgresinfra.ScheduleChangeRepository{DB: deps.DB},
-			Outbox:         outboxRepo,
-		}
-		metricsService := &services.MetricsService{
-			Metrics: &postgresinfra.MetricsRepository{DB: deps.DB},
+		var registry amiddleware.ServiceRegistry
+		if deps.Services != nil {
+			registry = *deps.Services
+		} else {
+			registry = NewServices(deps)
 		}
 
 		authRepo := &postgresinfra.AuthRepository{DB: deps.DB}
@@ -200,26 +120,7 @@ func NewRouter(deps Deps) http.Handler {
 			if deps.AuthPublicKey != nil {
 				protected.Use(amiddleware.Authenticator{PublicKey: deps.AuthPublicKey}.Middleware)
 			}
-			protected.Use(amiddleware.InjectServices(amiddleware.ServiceRegistry{
-				Tasks:         taskService,
-				Parts:         partService,
-				Compliance:    complianceService,
-				AuditQuery:    auditQueryService,
-				Catalog:       catalogService,
-				Organizations: orgService,
-				Users:         userService,
-				Aircraft:      aircraftService,
-				Programs:      programService,
-				Imports:       importService,
-				Webhooks:      webhookService,
-				Policies:       policyService,
-				Reports:        reportService,
-				Certifications: certificationService,
-				Directives:     directiveService,
-				Alerts:         alertService,
-				Scheduling:     schedulingService,
-				Metrics:        metricsService,
-			}))
+			protected.Use(amiddleware.InjectServices(registry))
 			protected.Use(amiddleware.Idempotency(amiddleware.IdempotencyConfig{Store: idempotencyStore}))
 			protected.Use(amiddleware.RateLimit(amiddleware.RateLimitConfig{
 				Limiter:      rateLimiter,
@@ -227,7 +128,7 @@ func NewRouter(deps Deps) http.Handler {
 				Category:     "default",
 				DefaultLimit: 100,
 				LimitFor: func(ctx context.Context, orgID uuid.UUID) (int, error) {
-					policy, err := policyService.Get(ctx, orgID)
+					policy, err := registry.Policies.Get(ctx, orgID)
 					if err != nil {
 						return 0, err
 					}
@@ -237,7 +138,7 @@ func NewRouter(deps Deps) http.Handler {
 				APIKeyCategory:     "api_key",
 				APIKeyDefaultLimit: 10,
 				APIKeyLimitFor: func(ctx context.Context, orgID uuid.UUID) (int, error) {
-					policy, err := policyService.Get(ctx, orgID)
+					policy, err := registry.Policies.Get(ctx, orgID)
 					if err != nil {
 						return 0, err
 					}
@@ -252,6 +153,55 @@ func NewRouter(deps Deps) http.Handler {
 				tasks.Patch("/{id}", handlers.UpdateTask)
 				tasks.Delete("/{id}", handlers.DeleteTask)
 				tasks.Patch("/{id}/state", handlers.TransitionTaskState)
+				tasks.Post("/{id}/task-cards", handlers.CreateTaskCard)
+				tasks.Get("/{id}/task-cards", handlers.ListTaskCards)
+				tasks.Post("/{id}/labor", handlers.RecordLabor)
+				tasks.Get("/{id}/labor", handlers.ListTaskLabor)
+				tasks.Post("/{id}/labor/clock-in", handlers.ClockInLabor)
+				tasks.Get("/{id}/labor/summary", handlers.GetTaskLaborSummary)
+				tasks.Post("/{id}/findings", handlers.RaiseFinding)
+				tasks.Get("/{id}/findings", handlers.ListTaskFindings)
+				tasks.Post("/{id}/crew", handlers.AssignCrew)
+				tasks.Get("/{id}/crew", handlers.ListTaskCrew)
+				tasks.Post("/{id}/attachments", handlers.UploadTaskAttachment)
+				tasks.Get("/{id}/attachments", handlers.ListTaskAttachments)
+			})
+			protected.Route("/crew-assignments", func(crew chi.Router) {
+				crew.Patch("/{id}", handlers.UpdateCrewAssignment)
+				crew.Delete("/{id}", handlers.RemoveCrewAssignment)
+			})
+			protected.Route("/labor-entries", func(labor chi.Router) {
+				labor.Post("/{id}/clock-out", handlers.ClockOutLabor)
+				labor.Delete("/{id}", handlers.DeleteLaborEntry)
+			})
+			protected.Route("/findings", func(findings chi.Router) {
+				findings.Get("/", handlers.ListFindings)
+				findings.Get("/{id}", handlers.GetFinding)
+				findings.Post("/{id}/convert", handlers.ConvertFinding)
+				findings.Post("/{id}/close", handlers.CloseFinding)
+				findings.Post("/{id}/attachments", handlers.UploadFindingAttachment)
+				findings.Get("/{id}/attachments", handlers.ListFindingAttachments)
+			})
+			protected.Route("/attachments", func(attachments chi.Router) {
+				attachments.Get("/{id}", handlers.GetAttachment)
+				attachments.Get("/{id}/content", handlers.DownloadAttachment)
+				attachments.Delete("/{id}", handlers.DeleteAttachment)
+			})
+			protected.Route("/defects", func(defects chi.Router) {
+				defects.Post("/", handlers.ReportDefect)
+				defects.Get("/", handlers.ListDefects)
+				defects.Get("/{id}", handlers.GetDefect)
+				defects.Post("/{id}/defer", handlers.DeferDefect)
+				defects.Post("/{id}/rectification-task", handlers.CreateRectificationTask)
+				defects.Post("/{id}/extensions", handlers.ExtendDeferral)
+				defects.Get("/{id}/extensions", handlers.ListDeferralExtensions)
+				defects.Post("/{id}/rectify", handlers.RectifyDefect)
+				defects.Post("/{id}/close", handlers.CloseDefect)
+			})
+			protected.Route("/task-cards", func(cards chi.Router) {
+				cards.Get("/{id}", handlers.GetTaskCard)
+				cards.Post("/{id}/steps/{stepId}/perform", handlers.PerformTaskCardStep)
+				cards.Post("/{id}/steps/{stepId}/inspect", handlers.InspectTaskCardStep)
 			})
 			protected.Route("/organizations", func(orgs chi.Router) {
 				orgs.Post("/", handlers.CreateOrganization)
@@ -272,14 +222,81 @@ func NewRouter(deps Deps) http.Handler {
 				aircraft.Get("/{id}", handlers.GetAircraft)
 				aircraft.Patch("/{id}", handlers.UpdateAircraft)
 				aircraft.Delete("/{id}", handlers.DeleteAircraft)
+				aircraft.Post("/{id}/utilization", handlers.CreateAircraftUtilization)
+				aircraft.Get("/{id}/utilization", handlers.ListAircraftUtilization)
+				aircraft.Post("/{id}/components/install", handlers.InstallComponent)
+				aircraft.Post("/{id}/components/remove", handlers.RemoveComponent)
+				aircraft.Get("/{id}/configuration", handlers.GetAircraftConfiguration)
+				aircraft.Post("/{id}/flights", handlers.CreatePlannedFlight)
+				aircraft.Get("/{id}/flights", handlers.ListPlannedFlights)
+				aircraft.Delete("/{id}/flights/{flightId}", handlers.DeletePlannedFlight)
+				aircraft.Get("/{id}/ground-windows", handlers.GetAircraftGroundWindows)
 			})
 			protected.Route("/maintenance-programs", func(programs chi.Router) {
 				programs.Post("/", handlers.CreateProgram)
 				programs.Get("/", handlers.ListPrograms)
+				programs.Get("/forecast", handlers.ForecastPrograms)
 				programs.Get("/{id}", handlers.GetProgram)
 				programs.Patch("/{id}", handlers.UpdateProgram)
 				programs.Delete("/{id}", handlers.DeleteProgram)
 			})
+			protected.Route("/maintenance-program-templates", func(templates chi.Router) {
+				templates.Post("/", handlers.CreateProgramTemplate)
+				templates.Get("/", handlers.ListProgramTemplates)
+				templates.Get("/{id}", handlers.GetProgramTemplate)
+				templates.Patch("/{id}", handlers.UpdateProgramTemplate)
+				templates.Delete("/{id}", handlers.DeleteProgramTemplate)
+				templates.Get("/{id}/propagation", handlers.PreviewProgramTemplatePropagation)
+				templates.Post("/{id}/propagation", handlers.PropagateProgramTemplate)
+			})
+			protected.Route("/work-packages", func(packages chi.Router) {
+				packages.Post("/", handlers.CreateWorkPackage)
+				packages.Get("/", handlers.ListWorkPackages)
+				packages.Get("/{id}", handlers.GetWorkPackage)
+				packages.Patch("/{id}", handlers.UpdateWorkPackage)
+				packages.Delete("/{id}", handlers.DeleteWorkPackage)
+				packages.Patch("/{id}/state", handlers.TransitionWorkPackageState)
+				packages.Post("/{id}/tasks", handlers.AddWorkPackageTasks)
+				packages.Delete("/{id}/tasks/{taskId}", handlers.RemoveWorkPackageTask)
+				packages.Post("/{id}/auto-fill", handlers.AutoFillWorkPackage)
+			})
+			protected.Route("/stations", func(stations chi.Router) {
+				stations.Post("/", handlers.CreateStation)
+				stations.Get("/", handlers.ListStations)
+				stations.Get("/{id}", handlers.GetStation)
+				stations.Patch("/{id}", handlers.UpdateStation)
+				stations.Delete("/{id}", handlers.DeleteStation)
+				stations.Get("/{id}/availability", handlers.GetStationAvailability)
+				stations.Get("/{id}/ground-windows", handlers.GetStationGroundWindows)
+			})
+			protected.Route("/hangar-bays", func(bays chi.Router) {
+				bays.Post("/", handlers.CreateHangarBay)
+				bays.Get("/", handlers.ListHangarBays)
+				bays.Get("/{id}", handlers.GetHangarBay)
+				bays.Patch("/{id}", handlers.UpdateHangarBay)
+				bays.Delete("/{id}", handlers.DeleteHangarBay)
+				bays.Post("/{id}/capacity-windows", handlers.CreateBayCapacityWindow)
+				bays.Get("/{id}/capacity-windows", handlers.ListBayCapacityWindows)
+				bays.Delete("/{id}/capacity-windows/{windowId}", handlers.DeleteBayCapacityWindow)
+			})
+			protected.Route("/shift-patterns", func(patterns chi.Router) {
+				patterns.Post("/", handlers.CreateShiftPattern)
+				patterns.Get("/", handlers.ListShiftPatterns)
+				patterns.Get("/{id}", handlers.GetShiftPattern)
+				patterns.Patch("/{id}", handlers.UpdateShiftPattern)
+				patterns.Delete("/{id}", handlers.DeleteShiftPattern)
+			})
+			protected.Route("/roster-assignments", func(assignments chi.Router) {
+				assignments.Post("/", handlers.CreateRosterAssignment)
+				assignments.Get("/", handlers.ListRosterAssignments)
+				assignments.Delete("/{id}", handlers.DeleteRosterAssignment)
+			})
+			protected.Route("/mechanic-absences", func(absences chi.Router) {
+				absences.Post("/", handlers.CreateMechanicAbsence)
+				absences.Get("/", handlers.ListMechanicAbsences)
+				absences.Delete("/{id}", handlers.DeleteMechanicAbsence)
+			})
+			protected.Get("/mechanics/availability", handlers.GetMechanicAvailability)
 			protected.Route("/part-definitions", func(defs chi.Router) {
 				defs.Post("/", handlers.CreatePartDefinition)
 				defs.Get("/", handlers.ListPartDefinitions)
@@ -291,6 +308,7 @@ func NewRouter(deps Deps) http.Handler {
 				items.Get("/", handlers.ListPartItems)
 				items.Patch("/{id}", handlers.UpdatePartItem)
 				items.Delete("/{id}", handlers.DeletePartItem)
+				items.Get("/serial/{serial}/history", handlers.GetComponentHistory)
 			})
 			protected.Route("/part-reservations", func(parts chi.Router) {
 				parts.Post("/", handlers.ReservePart)
@@ -301,9 +319,11 @@ func NewRouter(deps Deps) http.Handler {
 				compliance.Post("/", handlers.CreateComplianceItem)
 				compliance.Patch("/{id}", handlers.UpdateComplianceItem)
 				compliance.Patch("/{id}/sign-off", handlers.SignOffComplianceItem)
+				compliance.Post("/{id}/attachments", handlers.UploadComplianceAttachment)
+				compliance.Get("/{id}/attachments", handlers.ListComplianceAttachments)
 			})
 			importHandler := handlers.ImportHandler{
-				Service:    importService,
+				Service:    registry.Imports,
 				StorageDir: deps.ImportStorageDir,
 			}
 			protected.Route("/imports", func(imports chi.Router) {
@@ -333,6 +353,10 @@ func NewRouter(deps Deps) http.Handler {
 				certs.Post("/", handlers.CreateUserCertification)
 				certs.Patch("/{certId}", handlers.UpdateUserCertification)
 			})
+			protected.Route("/certifications", func(certs chi.Router) {
+				certs.Post("/{id}/attachments", handlers.UploadCertificationAttachment)
+				certs.Get("/{id}/attachments", handlers.ListCertificationAttachments)
+			})
 			protected.Route("/users/{userId}/type-ratings", func(ratings chi.Router) {
 				ratings.Get("/", handlers.ListUserTypeRatings)
 				ratings.Post("/", handlers.CreateUserTypeRating)
@@ -367,12 +391,25 @@ func NewRouter(deps Deps) http.Handler {
 
 			// Scheduling & dependency endpoints
 			protected.Get("/scheduling/conflicts", handlers.DetectScheduleConflicts)
+			protected.Get("/scheduling/critical-path", handlers.GetCriticalPath)
+			protected.Route("/scheduling/plans", func(plans chi.Router) {
+				plans.Post("/", handlers.CreateSchedulePlan)
+				plans.Get("/", handlers.ListSchedulePlans)
+				plans.Get("/{id}", handlers.GetSchedulePlan)
+				plans.Post("/{id}/apply", handlers.ApplySchedulePlan)
+				plans.Post("/{id}/discard", handlers.DiscardSchedulePlan)
+			})
 			protected.Route("/maintenance-tasks/{id}/dependencies", func(deps chi.Router) {
 				deps.Get("/", handlers.ListTaskDependencies)
 				deps.Post("/", handlers.CreateTaskDependency)
 				deps.Delete("/{depId}", handlers.DeleteTaskDependency)
 			})
 			protected.Post("/maintenance-tasks/{id}/reschedule", handlers.RescheduleTask)
+			protected.Route("/maintenance-tasks/{id}/reschedule-options", func(options chi.Router) {
+				options.Post("/", handlers.GenerateRescheduleOptions)
+				options.Get("/", handlers.ListRescheduleOptions)
+			})
+			protected.Post("/scheduling/reschedule-options/{id}/apply", handlers.ApplyRescheduleOption)
 			protected.Get("/maintenance-tasks/{id}/schedule-history", handlers.ListScheduleChanges)
 		})
 	})
@@ -0,0 +1,319 @@
+package rest
+
+import (
+	amiddleware "github.com/aeromaintain/amss/internal/api/rest/middleware"
+	"github.com/aeromaintain/amss/internal/app/services"
+	postgresinfra "github.com/aeromaintain/amss/internal/infra/postgres"
+	redisinfra "github.com/aeromaintain/amss/internal/infra/redis"
+	"github.com/aeromaintain/amss/internal/infra/streams"
+)
+
+// NewServices builds the application services behind the API from deps.
+// The REST router and the gRPC server share one set so both apply the
+// same checks.
+func NewServices(deps Deps) amiddleware.ServiceRegistry {
+	locker := &redisinfra.Locker{Client: deps.Redis}
+	auditRepo := &postgresinfra.AuditRepository{DB: deps.DB}
+	outboxRepo := &postgresinfra.OutboxRepository{DB: deps.DB}
+	orgRepo := &postgresinfra.OrganizationRepository{DB: deps.DB}
+	userRepo := &postgresinfra.UserRepository{DB: deps.DB}
+	aircraftRepo := &postgresinfra.AircraftRepository{DB: deps.DB}
+	programRepo := &postgresinfra.MaintenanceProgramRepository{DB: deps.DB}
+	importRepo := &postgresinfra.ImportRepository{DB: deps.DB}
+	importRowRepo := &postgresinfra.ImportRowRepository{DB: deps.DB}
+	webhookRepo := &postgresinfra.WebhookRepository{DB: deps.DB}
+	policyRepo := &postgresinfra.OrgPolicyRepository{DB: deps.DB}
+	certRepo := &postgresinfra.CertificationRepository{DB: deps.DB}
+	capacityService := &services.CapacityService{
+		Stations: &postgresinfra.StationRepository{DB: deps.DB},
+		Bays:     &postgresinfra.HangarBayRepository{DB: deps.DB},
+		Windows:  &postgresinfra.BayCapacityWindowRepository{DB: deps.DB},
+		Tasks:    &postgresinfra.TaskRepository{DB: deps.DB},
+		Audit:    auditRepo,
+	}
+	rosterService := &services.RosterService{
+		Patterns:    &postgresinfra.ShiftPatternRepository{DB: deps.DB},
+		Assignments: &postgresinfra.RosterAssignmentRepository{DB: deps.DB},
+		Absences:    &postgresinfra.MechanicAbsenceRepository{DB: deps.DB},
+		Users:       userRepo,
+		Stations:    capacityService.Stations,
+		Tasks:       &postgresinfra.TaskRepository{DB: deps.DB},
+		Audit:       auditRepo,
+	}
+	flightService := &services.FlightScheduleService{
+		Flights:  &postgresinfra.PlannedFlightRepository{DB: deps.DB},
+		Aircraft: aircraftRepo,
+		Stations: capacityService.Stations,
+		Audit:    auditRepo,
+		Outbox:   outboxRepo,
+	}
+	taskService := &services.TaskService{
+		Tasks:        &postgresinfra.TaskRepository{DB: deps.DB},
+		Aircraft:     aircraftRepo,
+		Reservations: &postgresinfra.PartReservationRepository{DB: deps.DB},
+		Compliance:   &postgresinfra.ComplianceRepository{DB: deps.DB},
+		Certs:        certRepo,
+		Capacity:     capacityService,
+		Roster:       rosterService,
+		Flights:      flightService,
+		Audit:        auditRepo,
+		Outbox:       outboxRepo,
+	}
+	taskCardRepo := &postgresinfra.TaskCardRepository{DB: deps.DB}
+	taskService.Cards = taskCardRepo
+	laborService := &services.LaborService{
+		Labor:    &postgresinfra.LaborEntryRepository{DB: deps.DB},
+		Tasks:    taskService.Tasks,
+		Aircraft: aircraftRepo,
+		Audit:    auditRepo,
+		Outbox:   outboxRepo,
+	}
+	taskService.Labor = laborService
+	crewRepo := &postgresinfra.TaskAssignmentRepository{DB: deps.DB}
+	laborService.Crew = crewRepo
+	alertRepo := &postgresinfra.AlertRepository{DB: deps.DB}
+	partDefRepo := &postgresinfra.PartDefinitionRepository{DB: deps.DB}
+	partService := &services.PartReservationService{
+		Reservations:    &postgresinfra.PartReservationRepository{DB: deps.DB},
+		PartItems:       &postgresinfra.PartItemRepository{DB: deps.DB},
+		PartDefinitions: partDefRepo,
+		Tasks:           &postgresinfra.TaskRepository{DB: deps.DB},
+		Alerts:          alertRepo,
+		Locker:          locker,
+		Audit:           auditRepo,
+		Outbox:          outboxRepo,
+	}
+	complianceService := &services.ComplianceService{
+		Compliance: &postgresinfra.ComplianceRepository{DB: deps.DB},
+		Audit:      auditRepo,
+		Outbox:     outboxRepo,
+	}
+	auditQueryService := &services.AuditQueryService{
+		Repo: &postgresinfra.AuditQueryRepository{DB: deps.DB},
+	}
+	catalogService := &services.PartCatalogService{
+		Definitions: partDefRepo,
+		Items:       &postgresinfra.PartItemRepository{DB: deps.DB},
+	}
+	orgService := &services.OrganizationService{
+		Organizations: orgRepo,
+	}
+	userService := &services.UserService{
+		Users: userRepo,
+	}
+	utilizationRepo := &postgresinfra.AircraftUtilizationRepository{DB: deps.DB}
+	programTemplateService := &services.ProgramTemplateService{
+		Templates: &postgresinfra.MaintenanceProgramTemplateRepository{DB: deps.DB},
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+		Audit:     auditRepo,
+	}
+	aircraftService := &services.AircraftService{
+		Aircraft:  aircraftRepo,
+		Templates: programTemplateService,
+	}
+	utilizationService := &services.AircraftUtilizationService{
+		Utilization: utilizationRepo,
+		Aircraft:    aircraftRepo,
+		Audit:       auditRepo,
+		Outbox:      outboxRepo,
+	}
+	componentService := &services.ComponentService{
+		Changes:     &postgresinfra.ComponentChangeRepository{DB: deps.DB},
+		PartItems:   &postgresinfra.PartItemRepository{DB: deps.DB},
+		Aircraft:    aircraftRepo,
+		Utilization: utilizationRepo,
+		Tasks:       taskService.Tasks,
+		Audit:       auditRepo,
+		Outbox:      outboxRepo,
+	}
+	programService := &services.MaintenanceProgramService{
+		Programs:    programRepo,
+		Aircraft:    aircraftRepo,
+		Utilization: utilizationRepo,
+		Tasks:       taskService.Tasks,
+		TaskSvc:     taskService,
+		Flights:     flightService,
+		LookAhead:   deps.ProgramLookAhead,
+	}
+	findingRepo := &postgresinfra.FindingRepository{DB: deps.DB}
+	findingService := &services.FindingService{
+		Findings: findingRepo,
+		Tasks:    taskService.Tasks,
+		Packages: &postgresinfra.WorkPackageRepository{DB: deps.DB},
+		TaskSvc:  taskService,
+		Audit:    auditRepo,
+		Outbox:   outboxRepo,
+	}
+	complianceService.Findings = findingService
+	workPackageService := &services.WorkPackageService{
+		Packages:     &postgresinfra.WorkPackageRepository{DB: deps.DB},
+		Findings:     findingRepo,
+		Tasks:        taskService.Tasks,
+		Aircraft:     aircraftRepo,
+		Reservations: taskService.Reservations,
+		Compliance:   taskService.Compliance,
+		Programs:     programService,
+		TaskSvc:      taskService,
+		Audit:        auditRepo,
+		Outbox:       outboxRepo,
+	}
+	importService := &services.ImportService{
+		Imports: importRepo,
+		Rows:    importRowRepo,
+		Jobs:    &streams.ImportQueue{Client: deps.Redis},
+	}
+	webhookService := &services.WebhookService{
+		Webhooks:     webhookRepo,
+		Outbox:       outboxRepo,
+		RequireHTTPS: deps.AppEnv == "production",
+	}
+	policyService := &services.OrgPolicyService{
+		Policies: policyRepo,
+	}
+	reportService := &services.ReportService{
+		Reports: &postgresinfra.ReportRepository{DB: deps.DB},
+	}
+	certificationService := &services.CertificationService{
+		Certs: certRepo,
+		Audit: auditRepo,
+	}
+	crewService := &services.CrewService{
+		Assignments:    crewRepo,
+		Tasks:          taskService.Tasks,
+		Aircraft:       aircraftRepo,
+		Certifications: certificationService,
+		Roster:         rosterService,
+		Audit:          auditRepo,
+		Outbox:         outboxRepo,
+	}
+	taskService.Crew = crewService
+	var attachmentService *services.AttachmentService
+	if deps.Blobs != nil {
+		attachmentService = &services.AttachmentService{
+			Attachments: &postgresinfra.AttachmentRepository{DB: deps.DB},
+			Blobs:       deps.Blobs,
+			Tasks:       taskService.Tasks,
+			Compliance:  taskService.Compliance,
+			Findings:    findingRepo,
+			Certs:       certRepo,
+			Policies:    policyService,
+			Audit:       auditRepo,
+			Outbox:      outboxRepo,
+		}
+	}
+	taskCardService := &services.TaskCardService{
+		Cards:          taskCardRepo,
+		Tasks:          taskService.Tasks,
+		Aircraft:       aircraftRepo,
+		Certifications: certificationService,
+		Audit:          auditRepo,
+		Outbox:         outboxRepo,
+	}
+	defectService := &services.DefectService{
+		Defects:  &postgresinfra.DefectRepository{DB: deps.DB},
+		Aircraft: aircraftRepo,
+		Tasks:    taskService.Tasks,
+		TaskSvc:  taskService,
+		Audit:    auditRepo,
+		Outbox:   outboxRepo,
+	}
+	aircraftService.Defects = defectService
+	directiveService := &services.DirectiveService{
+		Directives: &postgresinfra.DirectiveRepository{DB: deps.DB},
+		Aircraft:   aircraftRepo,
+		Audit:      auditRepo,
+	}
+	alertService := &services.AlertService{
+		Alerts: alertRepo,
+	}
+	schedulingService := &services.SchedulingService{
+		Tasks:          &postgresinfra.TaskRepository{DB: deps.DB},
+		Dependencies:   &postgresinfra.TaskDependencyRepository{DB: deps.DB},
+		ScheduleEvents: &postgresinfra.ScheduleChangeRepository{DB: deps.DB},
+		Capacity:       capacityService,
+		Roster:         rosterService,
+		Reservations:   &postgresinfra.PartReservationRepository{DB: deps.DB},
+		PartItems:      &postgresinfra.PartItemRepository{DB: deps.DB},
+		Certs:          certRepo,
+		Aircraft:       aircraftRepo,
+		Directives:     &postgresinfra.DirectiveRepository{DB: deps.DB},
+		WorkPackages:   &postgresinfra.WorkPackageRepository{DB: deps.DB},
+		Flights:        flightService,
+		Outbox:         outboxRepo,
+	}
+	taskService.Preemption = &services.PreemptionService{
+		ScheduleEvents: schedulingService.ScheduleEvents,
+		Scheduling:     schedulingService,
+		Capacity:       capacityService,
+		Roster:         rosterService,
+		Audit:          auditRepo,
+		Outbox:         outboxRepo,
+	}
+	scheduleOptimizerService := &services.ScheduleOptimizerService{
+		Plans:           &postgresinfra.SchedulePlanRepository{DB: deps.DB},
+		Tasks:           &postgresinfra.TaskRepository{DB: deps.DB},
+		Dependencies:    &postgresinfra.TaskDependencyRepository{DB: deps.DB},
+		Aircraft:        aircraftRepo,
+		Certs:           certRepo,
+		Reservations:    &postgresinfra.PartReservationRepository{DB: deps.DB},
+		PartItems:       &postgresinfra.PartItemRepository{DB: deps.DB},
+		PartDefinitions: partDefRepo,
+		Capacity:        capacityService,
+		Roster:          rosterService,
+		Flights:         flightService,
+		Audit:           auditRepo,
+		Outbox:          outboxRepo,
+	}
+	rescheduleOptionService := &services.RescheduleOptionService{
+		Options:         &postgresinfra.RescheduleOptionRepository{DB: deps.DB},
+		Tasks:           &postgresinfra.TaskRepository{DB: deps.DB},
+		Dependencies:    &postgresinfra.TaskDependencyRepository{DB: deps.DB},
+		Reservations:    &postgresinfra.PartReservationRepository{DB: deps.DB},
+		PartItems:       &postgresinfra.PartItemRepository{DB: deps.DB},
+		PartDefinitions: partDefRepo,
+		Scheduling:      schedulingService,
+		Capacity:        capacityService,
+		Audit:           auditRepo,
+		Outbox:          outboxRepo,
+	}
+	metricsService := &services.MetricsService{
+		Metrics: &postgresinfra.MetricsRepository{DB: deps.DB},
+	}
+
+	return amiddleware.ServiceRegistry{
+		Tasks:             taskService,
+		Parts:             partService,
+		Compliance:        complianceService,
+		AuditQuery:        auditQueryService,
+		Catalog:           catalogService,
+		Organizations:     orgService,
+		Users:             userService,
+		Aircraft:          aircraftService,
+		Utilization:       utilizationService,
+		Components:        componentService,
+		Programs:          programService,
+		ProgramTemplates:  programTemplateService,
+		WorkPackages:      workPackageService,
+		Imports:           importService,
+		Webhooks:          webhookService,
+		Policies:          policyService,
+		Reports:           reportService,
+		Certifications:    certificationService,
+		Directives:        directiveService,
+		Alerts:            alertService,
+		Scheduling:        schedulingService,
+		Capacity:          capacityService,
+		ScheduleOptimizer: scheduleOptimizerService,
+		RescheduleOptions: rescheduleOptionService,
+		Roster:            rosterService,
+		Flights:           flightService,
+		TaskCards:         taskCardService,
+		Labor:             laborService,
+		Crew:              crewService,
+		Defects:           defectService,
+		Findings:          findingService,
+		Attachments:       attachmentService,
+		Metrics:           metricsService,
+	}
+}
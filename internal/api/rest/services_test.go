@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/aeromaintain/amss/internal/domain"
+)
+
+func TestNewServicesWiresTaskChecks(t *testing.T) {
+	lookAhead := domain.ProgramLookAhead{Days: 14, FlightHours: 50, Cycles: 25}
+	registry := NewServices(Deps{ProgramLookAhead: lookAhead})
+
+	tasks := registry.Tasks
+	if tasks == nil {
+		t.Fatalf("expected a task service")
+	}
+	if tasks.Roster == nil || tasks.Flights == nil || tasks.Crew == nil || tasks.Preemption == nil || tasks.Labor == nil || tasks.Capacity == nil {
+		t.Fatalf("expected the task service to carry its roster, flight, crew, preemption, labor and capacity checks")
+	}
+	if registry.Programs == nil || registry.Programs.TaskSvc != tasks {
+		t.Fatalf("expected programs to create tasks through the shared task service")
+	}
+	if registry.Programs.LookAhead != lookAhead {
+		t.Fatalf("expected program look-ahead %+v, got %+v", lookAhead, registry.Programs.LookAhead)
+	}
+	if registry.RescheduleOptions == nil || registry.RescheduleOptions.Scheduling != registry.Scheduling {
+		t.Fatalf("expected reschedule options to check moves through the shared scheduling service")
+	}
+	if registry.Scheduling.Roster == nil || registry.Scheduling.Flights == nil {
+		t.Fatalf("expected the scheduling service to carry its roster and flight checks")
+	}
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// BlobStore keeps the content of attachments. Keys are opaque slash
+// separated paths chosen by the caller.
+type BlobStore interface {
+	// Put stores size bytes read from body under key, replacing any
+	// existing blob.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns domain.ErrNotFound when no blob is stored under key. The
+	// caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when no blob is stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment domain.Attachment) (domain.Attachment, error)
+	// GetByID ignores deleted attachments.
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Attachment, error)
+	// ListByEntity returns the entity's attachments, oldest first.
+	ListByEntity(ctx context.Context, orgID uuid.UUID, entityType domain.AttachmentEntityType, entityID uuid.UUID) ([]domain.Attachment, error)
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	// ListExpired returns attachments deleted before cutoff, or whose task or
+	// compliance item was deleted before cutoff.
+	ListExpired(ctx context.Context, orgID uuid.UUID, cutoff time.Time, limit int) ([]domain.Attachment, error)
+	// Purge removes the attachment row, deleted or not.
+	Purge(ctx context.Context, orgID, id uuid.UUID) error
+}
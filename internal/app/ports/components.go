@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type ComponentChangeRepository interface {
+	// Record stores the change and fits the part to, or removes it from, the
+	// aircraft position in the same transaction. It returns a conflict error
+	// when the part or the position is no longer in the expected state.
+	Record(ctx context.Context, change domain.ComponentChange) (domain.ComponentChange, error)
+	// List returns changes oldest first.
+	List(ctx context.Context, filter ComponentChangeFilter) ([]domain.ComponentChange, error)
+}
+
+type ComponentChangeFilter struct {
+	OrgID      *uuid.UUID
+	PartItemID *uuid.UUID
+	AircraftID *uuid.UUID
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type DefectFilter struct {
+	OrgID      *uuid.UUID
+	AircraftID *uuid.UUID
+	State      *domain.DefectState
+	// ExpiringBefore keeps deferred defects whose expiry is before it.
+	ExpiringBefore *time.Time
+	Limit          int
+	Offset         int
+}
+
+type DefectRepository interface {
+	Create(ctx context.Context, defect domain.Defect) (domain.Defect, error)
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Defect, error)
+	// List returns defects newest first, or by expiry when ExpiringBefore
+	// is set.
+	List(ctx context.Context, filter DefectFilter) ([]domain.Defect, error)
+	// Update stores the defect's state, deferral, rectification and closure
+	// fields. It returns a conflict error when the stored defect is no
+	// longer in state from.
+	Update(ctx context.Context, defect domain.Defect, from domain.DefectState) (domain.Defect, error)
+	// Extend moves a deferred defect's expiry and records the extension in
+	// one transaction. It returns a conflict error when the expiry changed
+	// in the meantime.
+	Extend(ctx context.Context, extension domain.DeferralExtension) (domain.Defect, error)
+	ListExtensions(ctx context.Context, orgID, defectID uuid.UUID) ([]domain.DeferralExtension, error)
+}
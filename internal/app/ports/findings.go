@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type FindingFilter struct {
+	OrgID      *uuid.UUID
+	TaskID     *uuid.UUID
+	AircraftID *uuid.UUID
+	State      *domain.FindingState
+	Limit      int
+	Offset     int
+}
+
+type FindingRepository interface {
+	Create(ctx context.Context, finding domain.Finding) (domain.Finding, error)
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Finding, error)
+	// List returns findings most recently raised first.
+	List(ctx context.Context, filter FindingFilter) ([]domain.Finding, error)
+	// Update stores the finding's state, repair task and resolution. It
+	// returns a conflict error when the stored finding is no longer in
+	// state from.
+	Update(ctx context.Context, finding domain.Finding, from domain.FindingState) (domain.Finding, error)
+	// Convert stores the repair task, its dependency on the task the finding
+	// was raised on and the converted finding in one transaction. It returns
+	// a conflict error when the stored finding is no longer open.
+	Convert(ctx context.Context, finding domain.Finding, repair domain.MaintenanceTask, dependency domain.TaskDependency) (domain.Finding, error)
+	// CountOpen counts the open findings raised on any of the tasks.
+	CountOpen(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) (int, error)
+}
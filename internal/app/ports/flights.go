@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type PlannedFlightRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.PlannedFlight, error)
+	// Upsert inserts the flight or, when the aircraft already has a flight
+	// with the same number departing at the same time, updates that one.
+	// It returns a conflict error when the flight would overlap another
+	// scheduled flight of the aircraft.
+	Upsert(ctx context.Context, flight domain.PlannedFlight) (domain.PlannedFlight, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+	// List returns the matching flights ordered by departure time.
+	List(ctx context.Context, filter PlannedFlightFilter) ([]domain.PlannedFlight, error)
+	// ListPrevious returns, for each aircraft, its last scheduled flight
+	// arriving at or before at. Nil aircraftIDs covers every aircraft of
+	// the organization.
+	ListPrevious(ctx context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, at time.Time) ([]domain.PlannedFlight, error)
+}
+
+type PlannedFlightFilter struct {
+	OrgID       *uuid.UUID
+	AircraftIDs []uuid.UUID
+	// Station matches flights leaving from or arriving at the station code.
+	Station string
+	Status  *domain.FlightStatus
+	// From and To limit the result to flights in the air at some time in
+	// [From, To).
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type StationRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Station, error)
+	Create(ctx context.Context, station domain.Station) (domain.Station, error)
+	Update(ctx context.Context, station domain.Station) (domain.Station, error)
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	List(ctx context.Context, filter StationFilter) ([]domain.Station, error)
+}
+
+type StationFilter struct {
+	OrgID  *uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type HangarBayRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.HangarBay, error)
+	Create(ctx context.Context, bay domain.HangarBay) (domain.HangarBay, error)
+	Update(ctx context.Context, bay domain.HangarBay) (domain.HangarBay, error)
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	List(ctx context.Context, filter HangarBayFilter) ([]domain.HangarBay, error)
+}
+
+type HangarBayFilter struct {
+	OrgID     *uuid.UUID
+	StationID *uuid.UUID
+	Limit     int
+	Offset    int
+}
+
+type BayCapacityWindowRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.BayCapacityWindow, error)
+	Create(ctx context.Context, window domain.BayCapacityWindow) (domain.BayCapacityWindow, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+	// ListOverlapping returns the bay's windows that intersect [from, to),
+	// ordered by start time.
+	ListOverlapping(ctx context.Context, orgID, bayID uuid.UUID, from, to time.Time) ([]domain.BayCapacityWindow, error)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type LaborEntryRepository interface {
+	// Create stores the entry. It returns a labor_overlap capacity conflict
+	// when the mechanic already has labor booked in the entry's time.
+	Create(ctx context.Context, entry domain.LaborEntry) (domain.LaborEntry, error)
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.LaborEntry, error)
+	// ClockOut closes an open entry. It returns a conflict error when the
+	// entry was clocked out in the meantime.
+	ClockOut(ctx context.Context, orgID, id uuid.UUID, endedAt time.Time) (domain.LaborEntry, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+	// ListByTask returns the task's entries, earliest first.
+	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.LaborEntry, error)
+	// ListOverlapping returns the mechanic's entries intersecting
+	// [start, end); a nil end leaves the range open.
+	ListOverlapping(ctx context.Context, orgID, mechanicID uuid.UUID, start time.Time, end *time.Time) ([]domain.LaborEntry, error)
+}
@@ -14,13 +14,38 @@ type MaintenanceProgramRepository interface {
 	Update(ctx context.Context, program domain.MaintenanceProgram) (domain.MaintenanceProgram, error)
 	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
 	List(ctx context.Context, filter MaintenanceProgramFilter) ([]domain.MaintenanceProgram, error)
-	ListDueCalendar(ctx context.Context, now time.Time, limit int) ([]domain.MaintenanceProgram, error)
+	// ListDue returns up to limit due aircraft-bound programs, oldest first,
+	// leaving out programs that already have a scheduled or in-progress task.
+	ListDue(ctx context.Context, now time.Time, lookAhead domain.ProgramLookAhead, limit int) ([]domain.MaintenanceProgram, error)
 	GetByName(ctx context.Context, orgID uuid.UUID, name string, aircraftID *uuid.UUID) (domain.MaintenanceProgram, error)
 }
 
 type MaintenanceProgramFilter struct {
 	OrgID      *uuid.UUID
 	AircraftID *uuid.UUID
+	TemplateID *uuid.UUID
 	Limit      int
 	Offset     int
 }
+
+type MaintenanceProgramTemplateRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.MaintenanceProgramTemplate, error)
+	Create(ctx context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error)
+	// CreateWithInstances inserts the template and its program instances in
+	// one transaction.
+	CreateWithInstances(ctx context.Context, template domain.MaintenanceProgramTemplate, instances []domain.MaintenanceProgram) (domain.MaintenanceProgramTemplate, error)
+	Update(ctx context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error)
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	List(ctx context.Context, filter MaintenanceProgramTemplateFilter) ([]domain.MaintenanceProgramTemplate, error)
+	// Propagate creates and updates the template's program instances in one
+	// transaction. It returns a conflict error when the stored template is
+	// no longer at template.Revision.
+	Propagate(ctx context.Context, template domain.MaintenanceProgramTemplate, created, updated []domain.MaintenanceProgram) error
+}
+
+type MaintenanceProgramTemplateFilter struct {
+	OrgID          *uuid.UUID
+	AircraftTypeID *uuid.UUID
+	Limit          int
+	Offset         int
+}
@@ -11,9 +11,17 @@ import (
 type TaskRepository interface {
 	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.MaintenanceTask, error)
 	Create(ctx context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error)
+	// CreateWithCompliance inserts the task and its compliance items in one
+	// transaction.
+	CreateWithCompliance(ctx context.Context, task domain.MaintenanceTask, items []domain.ComplianceItem) (domain.MaintenanceTask, error)
 	Update(ctx context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error)
 	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
 	List(ctx context.Context, filter TaskFilter) ([]domain.MaintenanceTask, error)
+	// UpdateState moves the task to newState. Completing a task generated
+	// from a program also records the completion time and the aircraft's
+	// flight hours and cycles as the program's last performance, and
+	// completing any task logs its labor as recency on the aircraft's type,
+	// in the same transaction.
 	UpdateState(ctx context.Context, orgID, id uuid.UUID, newState domain.TaskState, notes string, now time.Time) (domain.MaintenanceTask, error)
 	HasActiveForProgram(ctx context.Context, orgID, programID uuid.UUID) (bool, error)
 }
@@ -23,7 +31,15 @@ type AircraftRepository interface {
 	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Aircraft, error)
 	GetByTailNumber(ctx context.Context, orgID uuid.UUID, tailNumber string) (domain.Aircraft, error)
 	Create(ctx context.Context, aircraft domain.Aircraft) (domain.Aircraft, error)
+	// CreateWithPrograms inserts the aircraft and the maintenance programs
+	// instantiated for it in one transaction.
+	CreateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error)
+	// Update writes the aircraft's details. The flight hour and cycle
+	// totals are left as stored; only utilization entries move them.
 	Update(ctx context.Context, aircraft domain.Aircraft) (domain.Aircraft, error)
+	// UpdateWithPrograms updates the aircraft like Update and inserts the
+	// programs instantiated for its new type in the same transaction.
+	UpdateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error)
 	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
 	List(ctx context.Context, filter AircraftFilter) ([]domain.Aircraft, error)
 }
@@ -58,8 +74,14 @@ type PartReservationRepository interface {
 	Create(ctx context.Context, reservation domain.PartReservation) error
 	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.PartReservation, error)
 	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.PartReservation, error)
+	// ListByTasks returns the reservations of all the given tasks at once.
+	ListByTasks(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.PartReservation, error)
 	UpdateState(ctx context.Context, orgID, id uuid.UUID, state domain.PartReservationState, now time.Time) error
 	ReleaseByTask(ctx context.Context, orgID, taskID uuid.UUID, now time.Time) error
+	// ListUnavailable returns, across organizations, reservations still held
+	// for scheduled tasks whose part item is no longer in stock, soonest
+	// task first.
+	ListUnavailable(ctx context.Context, limit int) ([]domain.PartReservation, error)
 }
 
 type ComplianceRepository interface {
@@ -84,23 +106,36 @@ type OutboxRepository interface {
 }
 
 type TaskFilter struct {
-	OrgID      *uuid.UUID
-	AircraftID *uuid.UUID
-	State      *domain.TaskState
-	Type       *domain.TaskType
-	StartFrom  *time.Time
-	StartTo    *time.Time
-	Limit      int
-	Offset     int
+	OrgID              *uuid.UUID
+	AircraftID         *uuid.UUID
+	ProgramID          *uuid.UUID
+	WorkPackageID      *uuid.UUID
+	BayID              *uuid.UUID
+	AssignedMechanicID *uuid.UUID
+	State              *domain.TaskState
+	Type               *domain.TaskType
+	StartFrom          *time.Time
+	StartTo            *time.Time
+	// ActiveOnly limits the result to scheduled and in-progress tasks.
+	ActiveOnly bool
+	// OverlapFrom and OverlapTo limit the result to tasks whose window
+	// intersects [OverlapFrom, OverlapTo).
+	OverlapFrom *time.Time
+	OverlapTo   *time.Time
+	// IDs limits the result to these tasks.
+	IDs    []uuid.UUID
+	Limit  int
+	Offset int
 }
 
 type AircraftFilter struct {
-	OrgID      *uuid.UUID
-	Status     *domain.AircraftStatus
-	Model      string
-	TailNumber string
-	Limit      int
-	Offset     int
+	OrgID          *uuid.UUID
+	Status         *domain.AircraftStatus
+	Model          string
+	TailNumber     string
+	AircraftTypeID *uuid.UUID
+	Limit          int
+	Offset         int
 }
 
 type PartDefinitionFilter struct {
@@ -115,8 +150,14 @@ type PartItemFilter struct {
 	DefinitionID *uuid.UUID
 	Status       *domain.PartItemStatus
 	ExpiryBefore *time.Time
-	Limit        int
-	Offset       int
+	AircraftID   *uuid.UUID
+	LifeLimited  bool
+	// Unreserved limits the result to items no task holds a reservation on.
+	Unreserved bool
+	// IDs limits the result to these items.
+	IDs    []uuid.UUID
+	Limit  int
+	Offset int
 }
 
 type ComplianceFilter struct {
@@ -245,13 +286,41 @@ type TaskDependencyRepository interface {
 	Delete(ctx context.Context, orgID, id uuid.UUID) error
 	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.TaskDependency, error)
 	ListDependents(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.TaskDependency, error)
+	// ListByTasks returns the dependencies of all the given tasks at once.
+	ListByTasks(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.TaskDependency, error)
 }
 
 type ScheduleChangeRepository interface {
 	Create(ctx context.Context, event domain.ScheduleChangeEvent) (domain.ScheduleChangeEvent, error)
+	// Reschedule writes the new windows and bay slots of the tasks and
+	// records the event in one transaction. Each task must still carry the
+	// updated_at it was read with; a conflict error is returned when one has
+	// changed since, and nothing is written.
+	Reschedule(ctx context.Context, event domain.ScheduleChangeEvent, tasks []domain.MaintenanceTask, now time.Time) (domain.ScheduleChangeEvent, error)
+	// Preempt creates the preempting task, writes the displaced tasks and
+	// records their events in one transaction. A conflict error is returned
+	// when a displaced task changed since it was read, and nothing is
+	// written.
+	Preempt(ctx context.Context, change PreemptionChange, now time.Time) (domain.MaintenanceTask, error)
 	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.ScheduleChangeEvent, error)
 }
 
+// PreemptionChange is what creating a task that preempts other work
+// writes.
+type PreemptionChange struct {
+	Task domain.MaintenanceTask
+	// Moved tasks are written at their new windows and bay slots, guarded
+	// by their UpdatedAt.
+	Moved []domain.MaintenanceTask
+	// Released tasks give up their assigned mechanic, guarded by their
+	// UpdatedAt.
+	Released []domain.MaintenanceTask
+	// Events holds one schedule change per displaced task.
+	Events []domain.ScheduleChangeEvent
+	// Compliance items are inserted with the preempting task.
+	Compliance []domain.ComplianceItem
+}
+
 // --- Metrics Repository ---
 
 type MetricsRepository interface {
@@ -0,0 +1,38 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type RescheduleOptionRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.RescheduleOption, error)
+	// ListByTask returns the task's options, newest first and then by rank.
+	// A nil state returns options in every state.
+	ListByTask(ctx context.Context, orgID, taskID uuid.UUID, state *domain.RescheduleOptionState) ([]domain.RescheduleOption, error)
+	// Replace discards the task's proposed options and stores options in
+	// their place in one transaction.
+	Replace(ctx context.Context, orgID, taskID uuid.UUID, options []domain.RescheduleOption, now time.Time) error
+	// Apply writes change, marks the option applied and discards the task's
+	// other proposed options in one transaction. It returns a conflict error
+	// when the option is no longer proposed or a moved task changed since it
+	// was read.
+	Apply(ctx context.Context, option domain.RescheduleOption, change RescheduleOptionChange, now time.Time) (domain.ScheduleChangeEvent, error)
+}
+
+// RescheduleOptionChange is what applying an option writes.
+type RescheduleOptionChange struct {
+	Event domain.ScheduleChangeEvent
+	// Moved tasks are written at their new windows and bay slots, guarded
+	// by their UpdatedAt.
+	Moved []domain.MaintenanceTask
+	// Continuation is the task a split creates. The option's reservation
+	// moves to it.
+	Continuation *domain.MaintenanceTask
+	// Substitute is reserved in place of the option's reservation, which is
+	// released.
+	Substitute *domain.PartReservation
+}
@@ -16,11 +16,14 @@ type RetentionStats struct {
 	PartDefinitions  int64
 	Aircraft         int64
 	Users            int64
+	// Attachments counts the attachments whose content was purged from the
+	// blob store.
+	Attachments int64
 }
 
 func (s RetentionStats) Total() int64 {
 	return s.PartReservations + s.ComplianceItems + s.MaintenanceTasks + s.Programs +
-		s.PartItems + s.PartDefinitions + s.Aircraft + s.Users
+		s.PartItems + s.PartDefinitions + s.Aircraft + s.Users + s.Attachments
 }
 
 type RetentionRepository interface {
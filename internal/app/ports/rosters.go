@@ -0,0 +1,53 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type ShiftPatternRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.ShiftPattern, error)
+	Create(ctx context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error)
+	Update(ctx context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error)
+	// SoftDelete returns a conflict error while an assignment still uses
+	// the pattern.
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	List(ctx context.Context, filter ShiftPatternFilter) ([]domain.ShiftPattern, error)
+}
+
+type ShiftPatternFilter struct {
+	OrgID  *uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type RosterAssignmentRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.RosterAssignment, error)
+	Create(ctx context.Context, assignment domain.RosterAssignment) (domain.RosterAssignment, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+	List(ctx context.Context, filter RosterAssignmentFilter) ([]domain.RosterAssignment, error)
+}
+
+type RosterAssignmentFilter struct {
+	OrgID      *uuid.UUID
+	MechanicID *uuid.UUID
+	StationID  *uuid.UUID
+	// ActiveFrom and ActiveTo limit the result to assignments in effect at
+	// some time in [ActiveFrom, ActiveTo).
+	ActiveFrom *time.Time
+	ActiveTo   *time.Time
+	Limit      int
+	Offset     int
+}
+
+type MechanicAbsenceRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.MechanicAbsence, error)
+	Create(ctx context.Context, absence domain.MechanicAbsence) (domain.MechanicAbsence, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+	// ListOverlapping returns the absences that intersect [from, to),
+	// ordered by start time. A nil mechanicID returns every mechanic's.
+	ListOverlapping(ctx context.Context, orgID uuid.UUID, mechanicID *uuid.UUID, from, to time.Time) ([]domain.MechanicAbsence, error)
+}
@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type SchedulePlanRepository interface {
+	// GetByID returns the plan with its items.
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.SchedulePlan, error)
+	// Create stores the plan and its items in one transaction.
+	Create(ctx context.Context, plan domain.SchedulePlan) (domain.SchedulePlan, error)
+	// List returns plans without their items, newest first.
+	List(ctx context.Context, filter SchedulePlanFilter) ([]domain.SchedulePlan, error)
+	// Discard marks a proposed plan discarded. It returns a conflict error
+	// when the plan is no longer proposed.
+	Discard(ctx context.Context, orgID, id uuid.UUID, now time.Time) (domain.SchedulePlan, error)
+	// Apply writes every placed item onto its task, records the schedule
+	// changes and marks the plan applied in one transaction. It returns a
+	// conflict error when the plan is no longer proposed or a task changed
+	// since the plan was made.
+	Apply(ctx context.Context, plan domain.SchedulePlan, actorID uuid.UUID, now time.Time) (domain.SchedulePlan, error)
+}
+
+type SchedulePlanFilter struct {
+	OrgID  *uuid.UUID
+	State  *domain.SchedulePlanState
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type TaskAssignmentRepository interface {
+	// Create returns a conflict error when the mechanic already holds the
+	// role on the task.
+	Create(ctx context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error)
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.TaskAssignment, error)
+	// ListByTask returns the task's crew, oldest assignment first.
+	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) (domain.Crew, error)
+	// UpdatePlannedHours stores the assignment's planned hours.
+	UpdatePlannedHours(ctx context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error)
+	Delete(ctx context.Context, orgID, id uuid.UUID) error
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type TaskCardRepository interface {
+	// Create stores the card and its steps in one transaction.
+	Create(ctx context.Context, card domain.TaskCard) (domain.TaskCard, error)
+	// GetByID returns the card with its steps in order.
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.TaskCard, error)
+	// ListByTask returns the task's cards with their steps, oldest first.
+	ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.TaskCard, error)
+	// PerformStep signs a step off as performed. It returns a conflict error
+	// when the step was performed in the meantime.
+	PerformStep(ctx context.Context, orgID, stepID, userID uuid.UUID, value *float64, at time.Time) (domain.TaskCardStep, error)
+	// InspectStep signs a performed RII step off as inspected. It returns a
+	// conflict error when the step was inspected in the meantime or userID
+	// performed it.
+	InspectStep(ctx context.Context, orgID, stepID, userID uuid.UUID, at time.Time) (domain.TaskCardStep, error)
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AircraftUtilizationRepository interface {
+	// Create inserts the entry, rolls its totals onto the aircraft and adds
+	// its block hours and cycles to every part fitted to the aircraft, both
+	// since new and since overhaul, in the same transaction. It returns a
+	// conflict error if the totals would decrease relative to the latest
+	// entry or the aircraft, or if the entry's totals less its usage do not
+	// match the aircraft's current totals because another entry was recorded
+	// in between.
+	Create(ctx context.Context, entry domain.AircraftUtilization) (domain.AircraftUtilization, error)
+	GetLatest(ctx context.Context, orgID, aircraftID uuid.UUID) (domain.AircraftUtilization, error)
+	// List returns the matching entries, newest first.
+	List(ctx context.Context, filter AircraftUtilizationFilter) ([]domain.AircraftUtilization, error)
+	// AverageDaily returns the average daily usage logged between from and to,
+	// measured from the first entry in the range so new aircraft are not
+	// under-reported.
+	AverageDaily(ctx context.Context, orgID, aircraftID uuid.UUID, from, to time.Time) (domain.UtilizationRate, error)
+}
+
+type AircraftUtilizationFilter struct {
+	OrgID      *uuid.UUID
+	AircraftID *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
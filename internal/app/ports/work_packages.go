@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type WorkPackageRepository interface {
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.WorkPackage, error)
+	Create(ctx context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error)
+	Update(ctx context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error)
+	UpdateState(ctx context.Context, orgID, id uuid.UUID, newState domain.WorkPackageState, now time.Time) (domain.WorkPackage, error)
+	// SoftDelete deletes the package and detaches its tasks in the same
+	// transaction.
+	SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error
+	List(ctx context.Context, filter WorkPackageFilter) ([]domain.WorkPackage, error)
+}
+
+type WorkPackageFilter struct {
+	OrgID      *uuid.UUID
+	AircraftID *uuid.UUID
+	State      *domain.WorkPackageState
+	VisitFrom  *time.Time
+	VisitTo    *time.Time
+	Limit      int
+	Offset     int
+}
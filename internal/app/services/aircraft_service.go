@@ -12,30 +12,42 @@ import (
 
 type AircraftService struct {
 	Aircraft ports.AircraftRepository
-	Clock    app.Clock
+	// Templates instantiates the aircraft type's program templates on new
+	// aircraft and on aircraft that change type, in the same transaction as
+	// the aircraft write. Optional.
+	Templates *ProgramTemplateService
+	// Defects blocks returning an aircraft to service while any of its
+	// deferrals has expired. Optional.
+	Defects *DefectService
+	Clock   app.Clock
 }
 
 type AircraftCreateInput struct {
-	OrgID            *uuid.UUID
-	TailNumber       string
-	Model            string
-	LastMaintenance  *time.Time
-	NextDue          *time.Time
-	Status           domain.AircraftStatus
-	CapacitySlots    int
+	OrgID           *uuid.UUID
+	TailNumber      string
+	Model           string
+	AircraftTypeID  *uuid.UUID
+	LastMaintenance *time.Time
+	NextDue         *time.Time
+	Status          domain.AircraftStatus
+	CapacitySlots   int
+	// FlightHoursTotal and CyclesTotal are the opening totals. Afterwards
+	// they only move through AircraftUtilizationService.Record.
 	FlightHoursTotal int
 	CyclesTotal      int
 }
 
+// AircraftUpdateInput changes an aircraft's details. The flight hour and
+// cycle totals are not part of it; corrections are recorded as
+// utilization entries so the history and part accrual stay consistent.
 type AircraftUpdateInput struct {
-	TailNumber       *string
-	Model            *string
-	LastMaintenance  *time.Time
-	NextDue          *time.Time
-	Status           *domain.AircraftStatus
-	CapacitySlots    *int
-	FlightHoursTotal *int
-	CyclesTotal      *int
+	TailNumber      *string
+	Model           *string
+	AircraftTypeID  *uuid.UUID
+	LastMaintenance *time.Time
+	NextDue         *time.Time
+	Status          *domain.AircraftStatus
+	CapacitySlots   *int
 }
 
 func (s *AircraftService) Create(ctx context.Context, actor app.Actor, input AircraftCreateInput) (domain.Aircraft, error) {
@@ -68,6 +80,7 @@ func (s *AircraftService) Create(ctx context.Context, actor app.Actor, input Air
 		OrgID:            orgID,
 		TailNumber:       input.TailNumber,
 		Model:            input.Model,
+		AircraftTypeID:   input.AircraftTypeID,
 		LastMaintenance:  input.LastMaintenance,
 		NextDue:          input.NextDue,
 		Status:           status,
@@ -77,7 +90,15 @@ func (s *AircraftService) Create(ctx context.Context, actor app.Actor, input Air
 		CreatedAt:        s.Clock.Now(),
 		UpdatedAt:        s.Clock.Now(),
 	}
-	return s.Aircraft.Create(ctx, aircraft)
+	var programs []domain.MaintenanceProgram
+	if s.Templates != nil {
+		var err error
+		programs, err = s.Templates.InstancesForAircraft(ctx, aircraft)
+		if err != nil {
+			return domain.Aircraft{}, err
+		}
+	}
+	return s.Aircraft.CreateWithPrograms(ctx, aircraft, programs)
 }
 
 func (s *AircraftService) List(ctx context.Context, actor app.Actor, filter ports.AircraftFilter) ([]domain.Aircraft, error) {
@@ -114,6 +135,11 @@ func (s *AircraftService) Update(ctx context.Context, actor app.Actor, orgID, id
 	if input.Model != nil {
 		aircraft.Model = *input.Model
 	}
+	typeChanged := false
+	if input.AircraftTypeID != nil {
+		typeChanged = aircraft.AircraftTypeID == nil || *aircraft.AircraftTypeID != *input.AircraftTypeID
+		aircraft.AircraftTypeID = input.AircraftTypeID
+	}
 	if input.LastMaintenance != nil {
 		aircraft.LastMaintenance = input.LastMaintenance
 	}
@@ -121,6 +147,12 @@ func (s *AircraftService) Update(ctx context.Context, actor app.Actor, orgID, id
 		aircraft.NextDue = input.NextDue
 	}
 	if input.Status != nil {
+		returning := aircraft.Status != domain.AircraftOperational && *input.Status == domain.AircraftOperational
+		if returning && s.Defects != nil {
+			if err := s.Defects.CheckReturnToService(ctx, aircraft.OrgID, aircraft.ID); err != nil {
+				return domain.Aircraft{}, err
+			}
+		}
 		aircraft.Status = *input.Status
 	}
 	if input.CapacitySlots != nil {
@@ -129,17 +161,18 @@ func (s *AircraftService) Update(ctx context.Context, actor app.Actor, orgID, id
 		}
 		aircraft.CapacitySlots = *input.CapacitySlots
 	}
-	if input.FlightHoursTotal != nil {
-		aircraft.FlightHoursTotal = *input.FlightHoursTotal
-	}
-	if input.CyclesTotal != nil {
-		aircraft.CyclesTotal = *input.CyclesTotal
-	}
 	if aircraft.NextDue != nil && aircraft.LastMaintenance != nil && !aircraft.NextDue.After(*aircraft.LastMaintenance) {
 		return domain.Aircraft{}, domain.NewValidationError("next_due must be after last_maintenance")
 	}
 	aircraft.UpdatedAt = s.Clock.Now()
-	return s.Aircraft.Update(ctx, aircraft)
+	var programs []domain.MaintenanceProgram
+	if typeChanged && s.Templates != nil {
+		programs, err = s.Templates.InstancesForAircraft(ctx, aircraft)
+		if err != nil {
+			return domain.Aircraft{}, err
+		}
+	}
+	return s.Aircraft.UpdateWithPrograms(ctx, aircraft, programs)
 }
 
 func (s *AircraftService) Delete(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
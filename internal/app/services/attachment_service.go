@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// AttachmentService stores files as evidence against tasks, compliance
+// items, findings and certifications. Content goes to the blob store;
+// the repository keeps its name, size, digest and sniffed type.
+type AttachmentService struct {
+	Attachments ports.AttachmentRepository
+	Blobs       ports.BlobStore
+	Tasks       ports.TaskRepository
+	Compliance  ports.ComplianceRepository
+	Findings    ports.FindingRepository
+	Certs       ports.CertificationRepository
+	// Policies supplies each organization's upload limit. Optional; without
+	// it domain.DefaultMaxAttachmentBytes applies.
+	Policies *OrgPolicyService
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
+}
+
+type AttachmentUploadInput struct {
+	EntityType domain.AttachmentEntityType
+	EntityID   uuid.UUID
+	FileName   string
+	Content    io.Reader
+}
+
+// Upload reads the content up to the organization's size limit, hashes and
+// sniffs it, stores it in the blob store and records the attachment.
+// Mechanics and planners may attach files to work; a certification takes
+// files from its holder or a tenant admin.
+func (s *AttachmentService) Upload(ctx context.Context, actor app.Actor, orgID uuid.UUID, input AttachmentUploadInput) (domain.Attachment, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Attachment{}, domain.ErrForbidden
+	}
+	holder, err := s.loadEntity(ctx, orgID, input.EntityType, input.EntityID)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if !canAttach(actor, input.EntityType, holder) {
+		return domain.Attachment{}, domain.ErrForbidden
+	}
+	if input.Content == nil {
+		return domain.Attachment{}, domain.NewValidationError("file is required")
+	}
+
+	limit := s.maxBytes(ctx, orgID)
+	content, err := io.ReadAll(io.LimitReader(input.Content, limit+1))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if int64(len(content)) > limit {
+		return domain.Attachment{}, domain.NewValidationError(fmt.Sprintf("attachment exceeds the %d byte limit", limit))
+	}
+	contentType := http.DetectContentType(content)
+	if domain.IsActiveContentType(contentType) {
+		return domain.Attachment{}, domain.NewValidationError("attachments may not be " + contentType)
+	}
+	digest := sha256.Sum256(content)
+
+	now := s.Clock.Now().UTC()
+	id := uuid.New()
+	attachment := domain.Attachment{
+		ID:          id,
+		OrgID:       orgID,
+		EntityType:  input.EntityType,
+		EntityID:    input.EntityID,
+		FileName:    domain.SanitizeFileName(input.FileName),
+		ContentType: contentType,
+		SizeBytes:   int64(len(content)),
+		SHA256:      hex.EncodeToString(digest[:]),
+		StorageKey:  domain.AttachmentStorageKey(orgID, input.EntityType, id),
+		UploadedBy:  actor.UserID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := attachment.Validate(); err != nil {
+		return domain.Attachment{}, err
+	}
+	if err := s.Blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(content), attachment.SizeBytes, attachment.ContentType); err != nil {
+		return domain.Attachment{}, err
+	}
+	created, err := s.Attachments.Create(ctx, attachment)
+	if err != nil {
+		_ = s.Blobs.Delete(ctx, attachment.StorageKey)
+		return domain.Attachment{}, err
+	}
+	s.audit(ctx, actor, created, domain.AuditActionCreate, map[string]any{
+		"entity_type":  created.EntityType,
+		"entity_id":    created.EntityID,
+		"file_name":    created.FileName,
+		"content_type": created.ContentType,
+		"size_bytes":   created.SizeBytes,
+		"sha256":       created.SHA256,
+	})
+	s.emitOutbox(ctx, created, "attachment_uploaded")
+	return created, nil
+}
+
+// List returns the attachments of an entity the actor may see.
+func (s *AttachmentService) List(ctx context.Context, actor app.Actor, orgID uuid.UUID, entityType domain.AttachmentEntityType, entityID uuid.UUID) ([]domain.Attachment, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return nil, domain.ErrForbidden
+	}
+	holder, err := s.loadEntity(ctx, orgID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewAttachments(actor, entityType, holder) {
+		return nil, domain.ErrForbidden
+	}
+	return s.Attachments.ListByEntity(ctx, orgID, entityType, entityID)
+}
+
+func (s *AttachmentService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Attachment, error) {
+	return s.load(ctx, actor, orgID, id)
+}
+
+// Open returns the attachment with a reader over its content. The caller
+// closes the reader.
+func (s *AttachmentService) Open(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	content, err := s.Blobs.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+// Delete hides the attachment. Its row and content are kept until the
+// retention cleanup purges them. The uploader may delete their own files;
+// otherwise a planner is needed, or a tenant admin for certifications.
+func (s *AttachmentService) Delete(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	attachment, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return err
+	}
+	if attachment.UploadedBy != actor.UserID && !canManageAttachments(actor, attachment.EntityType) {
+		return domain.ErrForbidden
+	}
+	if err := s.Attachments.SoftDelete(ctx, attachment.OrgID, attachment.ID, s.Clock.Now().UTC()); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, attachment, domain.AuditActionDelete, map[string]any{
+		"entity_type": attachment.EntityType,
+		"entity_id":   attachment.EntityID,
+		"file_name":   attachment.FileName,
+	})
+	s.emitOutbox(ctx, attachment, "attachment_deleted")
+	return nil
+}
+
+// PurgeExpired removes the content and rows of attachments deleted before
+// cutoff, and of attachments whose task or compliance item was deleted
+// before cutoff. It returns how many attachments were purged.
+func (s *AttachmentService) PurgeExpired(ctx context.Context, orgID uuid.UUID, cutoff time.Time) (int64, error) {
+	const batch = 100
+	var purged int64
+	for {
+		expired, err := s.Attachments.ListExpired(ctx, orgID, cutoff, batch)
+		if err != nil {
+			return purged, err
+		}
+		for _, attachment := range expired {
+			// The blob goes first; a failure leaves the row for the next run.
+			if err := s.Blobs.Delete(ctx, attachment.StorageKey); err != nil {
+				return purged, err
+			}
+			if err := s.Attachments.Purge(ctx, attachment.OrgID, attachment.ID); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(expired) < batch {
+			return purged, nil
+		}
+	}
+}
+
+func (s *AttachmentService) maxBytes(ctx context.Context, orgID uuid.UUID) int64 {
+	if s.Policies == nil {
+		return domain.DefaultMaxAttachmentBytes
+	}
+	policy, err := s.Policies.Get(ctx, orgID)
+	if err != nil || policy.MaxAttachmentBytes <= 0 {
+		return domain.DefaultMaxAttachmentBytes
+	}
+	return policy.MaxAttachmentBytes
+}
+
+// load returns the attachment, checking the actor may see it.
+func (s *AttachmentService) load(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Attachment, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Attachment{}, domain.ErrForbidden
+	}
+	attachment, err := s.Attachments.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	var holder uuid.UUID
+	if attachment.EntityType == domain.AttachmentCertification {
+		if holder, err = s.loadEntity(ctx, orgID, attachment.EntityType, attachment.EntityID); err != nil {
+			return domain.Attachment{}, err
+		}
+	}
+	if !canViewAttachments(actor, attachment.EntityType, holder) {
+		return domain.Attachment{}, domain.ErrForbidden
+	}
+	return attachment, nil
+}
+
+// loadEntity checks the entity exists in the organization. For a
+// certification it returns the holder.
+func (s *AttachmentService) loadEntity(ctx context.Context, orgID uuid.UUID, entityType domain.AttachmentEntityType, entityID uuid.UUID) (uuid.UUID, error) {
+	switch {
+	case entityType == domain.AttachmentTask && s.Tasks != nil:
+		_, err := s.Tasks.GetByID(ctx, orgID, entityID)
+		return uuid.Nil, err
+	case entityType == domain.AttachmentComplianceItem && s.Compliance != nil:
+		_, err := s.Compliance.GetByID(ctx, orgID, entityID)
+		return uuid.Nil, err
+	case entityType == domain.AttachmentFinding && s.Findings != nil:
+		_, err := s.Findings.GetByID(ctx, orgID, entityID)
+		return uuid.Nil, err
+	case entityType == domain.AttachmentCertification && s.Certs != nil:
+		cert, err := s.Certs.GetCertByID(ctx, orgID, entityID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return cert.UserID, nil
+	case !entityType.IsValid():
+		return uuid.Nil, domain.NewValidationError("entity_type must be task, compliance_item, finding or certification")
+	}
+	return uuid.Nil, domain.NewValidationError("attachments to " + string(entityType) + " are not supported")
+}
+
+func canAttach(actor app.Actor, entityType domain.AttachmentEntityType, holder uuid.UUID) bool {
+	if entityType == domain.AttachmentCertification {
+		return canManageCertifications(actor) || actor.UserID == holder
+	}
+	return canCarryOutWork(actor)
+}
+
+// canViewAttachments lets anyone in the organization see the evidence of
+// work, while certification documents stay with their holder and tenant
+// admins.
+func canViewAttachments(actor app.Actor, entityType domain.AttachmentEntityType, holder uuid.UUID) bool {
+	if entityType == domain.AttachmentCertification {
+		return canManageCertifications(actor) || actor.UserID == holder
+	}
+	return true
+}
+
+func canManageAttachments(actor app.Actor, entityType domain.AttachmentEntityType) bool {
+	if entityType == domain.AttachmentCertification {
+		return canManageCertifications(actor)
+	}
+	return canManageCapacity(actor)
+}
+
+func canManageCertifications(actor app.Actor) bool {
+	return actor.Role == domain.RoleAdmin || actor.Role == domain.RoleTenantAdmin
+}
+
+func (s *AttachmentService) audit(ctx context.Context, actor app.Actor, attachment domain.Attachment, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      attachment.OrgID,
+		EntityType: "attachment",
+		EntityID:   attachment.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *AttachmentService) emitOutbox(ctx context.Context, attachment domain.Attachment, eventType string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":       1,
+		"org_id":        attachment.OrgID,
+		"attachment_id": attachment.ID,
+		"entity_type":   attachment.EntityType,
+		"entity_id":     attachment.EntityID,
+		"sha256":        attachment.SHA256,
+		"timestamp":     s.Clock.Now(),
+	}
+	_ = s.Outbox.Enqueue(ctx, attachment.OrgID, eventType, "attachment", attachment.ID, payload, fmt.Sprintf("%s:%s:%s", eventType, attachment.OrgID, attachment.ID))
+}
@@ -0,0 +1,617 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	capacityPageSize = 200
+	// maxAvailabilitySpan bounds the range of a free capacity query.
+	maxAvailabilitySpan = 92 * 24 * time.Hour
+)
+
+// CapacityService manages stations, hangar bays and their capacity windows,
+// and places tasks into bay slots.
+type CapacityService struct {
+	Stations ports.StationRepository
+	Bays     ports.HangarBayRepository
+	Windows  ports.BayCapacityWindowRepository
+	Tasks    ports.TaskRepository
+	Audit    ports.AuditRepository
+	Clock    app.Clock
+}
+
+type StationCreateInput struct {
+	OrgID    *uuid.UUID
+	Code     string
+	Name     string
+	Timezone string
+}
+
+type StationUpdateInput struct {
+	Name     *string
+	Timezone *string
+}
+
+type HangarBayCreateInput struct {
+	OrgID         *uuid.UUID
+	StationID     uuid.UUID
+	Code          string
+	Name          string
+	Hangar        string
+	CapacitySlots int
+}
+
+type HangarBayUpdateInput struct {
+	Name          *string
+	Hangar        *string
+	CapacitySlots *int
+}
+
+type BayCapacityWindowCreateInput struct {
+	OrgID         *uuid.UUID
+	BayID         uuid.UUID
+	StartTime     time.Time
+	EndTime       time.Time
+	CapacitySlots int
+	Reason        string
+}
+
+// BayAvailability lists the intervals in which a bay has free slots.
+type BayAvailability struct {
+	Bay       domain.HangarBay
+	Intervals []domain.CapacityInterval
+}
+
+func (s *CapacityService) CreateStation(ctx context.Context, actor app.Actor, input StationCreateInput) (domain.Station, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Station{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	timezone := strings.TrimSpace(input.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	now := s.Clock.Now()
+	station := domain.Station{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		Code:      strings.TrimSpace(input.Code),
+		Name:      strings.TrimSpace(input.Name),
+		Timezone:  timezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := station.Validate(); err != nil {
+		return domain.Station{}, err
+	}
+	created, err := s.Stations.Create(ctx, station)
+	if err != nil {
+		return domain.Station{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "station", created.ID, domain.AuditActionCreate, nil)
+	return created, nil
+}
+
+func (s *CapacityService) GetStation(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Station, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	return s.Stations.GetByID(ctx, orgID, id)
+}
+
+func (s *CapacityService) ListStations(ctx context.Context, actor app.Actor, filter ports.StationFilter) ([]domain.Station, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Stations.List(ctx, filter)
+}
+
+func (s *CapacityService) UpdateStation(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input StationUpdateInput) (domain.Station, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Station{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	station, err := s.Stations.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.Station{}, err
+	}
+	if input.Name != nil {
+		station.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Timezone != nil {
+		station.Timezone = strings.TrimSpace(*input.Timezone)
+	}
+	if err := station.Validate(); err != nil {
+		return domain.Station{}, err
+	}
+	station.UpdatedAt = s.Clock.Now()
+	updated, err := s.Stations.Update(ctx, station)
+	if err != nil {
+		return domain.Station{}, err
+	}
+	s.audit(ctx, actor, updated.OrgID, "station", updated.ID, domain.AuditActionUpdate, nil)
+	return updated, nil
+}
+
+// DeleteStation removes a station that has no bays left.
+func (s *CapacityService) DeleteStation(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Stations.SoftDelete(ctx, orgID, id, s.Clock.Now()); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "station", id, domain.AuditActionDelete, nil)
+	return nil
+}
+
+func (s *CapacityService) CreateBay(ctx context.Context, actor app.Actor, input HangarBayCreateInput) (domain.HangarBay, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.HangarBay{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now()
+	bay := domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		StationID:     input.StationID,
+		Code:          strings.TrimSpace(input.Code),
+		Name:          strings.TrimSpace(input.Name),
+		Hangar:        strings.TrimSpace(input.Hangar),
+		CapacitySlots: input.CapacitySlots,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := bay.Validate(); err != nil {
+		return domain.HangarBay{}, err
+	}
+	if _, err := s.Stations.GetByID(ctx, orgID, input.StationID); err != nil {
+		return domain.HangarBay{}, err
+	}
+	created, err := s.Bays.Create(ctx, bay)
+	if err != nil {
+		return domain.HangarBay{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "hangar_bay", created.ID, domain.AuditActionCreate, map[string]any{
+		"station_id":     created.StationID,
+		"capacity_slots": created.CapacitySlots,
+	})
+	return created, nil
+}
+
+func (s *CapacityService) GetBay(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.HangarBay, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	return s.Bays.GetByID(ctx, orgID, id)
+}
+
+func (s *CapacityService) ListBays(ctx context.Context, actor app.Actor, filter ports.HangarBayFilter) ([]domain.HangarBay, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Bays.List(ctx, filter)
+}
+
+// UpdateBay changes a bay. Its slot count cannot drop below a slot held by
+// an open task outside the bay's capacity windows.
+func (s *CapacityService) UpdateBay(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input HangarBayUpdateInput) (domain.HangarBay, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.HangarBay{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	bay, err := s.Bays.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.HangarBay{}, err
+	}
+	previous := bay.CapacitySlots
+	if input.Name != nil {
+		bay.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Hangar != nil {
+		bay.Hangar = strings.TrimSpace(*input.Hangar)
+	}
+	if input.CapacitySlots != nil {
+		bay.CapacitySlots = *input.CapacitySlots
+	}
+	if err := bay.Validate(); err != nil {
+		return domain.HangarBay{}, err
+	}
+	if bay.CapacitySlots < previous {
+		booked, err := s.activeTasks(ctx, ports.TaskFilter{OrgID: &bay.OrgID, BayID: &bay.ID}, s.Clock.Now(), time.Time{})
+		if err != nil {
+			return domain.HangarBay{}, err
+		}
+		for _, task := range booked {
+			windows, err := s.Windows.ListOverlapping(ctx, bay.OrgID, bay.ID, task.StartTime, task.EndTime)
+			if err != nil {
+				return domain.HangarBay{}, err
+			}
+			if *task.BaySlot > bay.CapacityDuring(windows, task.StartTime, task.EndTime) {
+				return domain.HangarBay{}, domain.NewCapacityConflict(domain.CapacityConflictBayFull, fmt.Sprintf("task %s holds slot %d of bay %s", task.ID, *task.BaySlot, bay.Code))
+			}
+		}
+	}
+	bay.UpdatedAt = s.Clock.Now()
+	updated, err := s.Bays.Update(ctx, bay)
+	if err != nil {
+		return domain.HangarBay{}, err
+	}
+	s.audit(ctx, actor, updated.OrgID, "hangar_bay", updated.ID, domain.AuditActionUpdate, map[string]any{
+		"capacity_slots": updated.CapacitySlots,
+	})
+	return updated, nil
+}
+
+// DeleteBay removes a bay that no open task is booked into.
+func (s *CapacityService) DeleteBay(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Bays.SoftDelete(ctx, orgID, id, s.Clock.Now()); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "hangar_bay", id, domain.AuditActionDelete, nil)
+	return nil
+}
+
+// CreateCapacityWindow overrides the bay's slot count for a period. Open
+// tasks in the period must still fit the reduced capacity.
+func (s *CapacityService) CreateCapacityWindow(ctx context.Context, actor app.Actor, input BayCapacityWindowCreateInput) (domain.BayCapacityWindow, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.BayCapacityWindow{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	window := domain.BayCapacityWindow{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		BayID:         input.BayID,
+		StartTime:     input.StartTime.UTC(),
+		EndTime:       input.EndTime.UTC(),
+		CapacitySlots: input.CapacitySlots,
+		Reason:        strings.TrimSpace(input.Reason),
+		CreatedAt:     s.Clock.Now(),
+	}
+	if err := window.Validate(); err != nil {
+		return domain.BayCapacityWindow{}, err
+	}
+	bay, err := s.Bays.GetByID(ctx, orgID, input.BayID)
+	if err != nil {
+		return domain.BayCapacityWindow{}, err
+	}
+	existing, err := s.Windows.ListOverlapping(ctx, orgID, bay.ID, window.StartTime, window.EndTime)
+	if err != nil {
+		return domain.BayCapacityWindow{}, err
+	}
+	if len(existing) > 0 {
+		return domain.BayCapacityWindow{}, domain.NewConflictError("capacity window overlaps an existing window of the bay")
+	}
+	booked, err := s.activeTasks(ctx, ports.TaskFilter{OrgID: &orgID, BayID: &bay.ID}, window.StartTime, window.EndTime)
+	if err != nil {
+		return domain.BayCapacityWindow{}, err
+	}
+	for _, task := range booked {
+		if *task.BaySlot > window.CapacitySlots {
+			return domain.BayCapacityWindow{}, domain.NewCapacityConflict(domain.CapacityConflictBayFull, fmt.Sprintf("task %s holds slot %d of bay %s during the window", task.ID, *task.BaySlot, bay.Code))
+		}
+	}
+	created, err := s.Windows.Create(ctx, window)
+	if err != nil {
+		return domain.BayCapacityWindow{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "hangar_bay", bay.ID, domain.AuditActionUpdate, map[string]any{
+		"capacity_window_id": created.ID,
+		"capacity_slots":     created.CapacitySlots,
+		"start_time":         created.StartTime,
+		"end_time":           created.EndTime,
+	})
+	return created, nil
+}
+
+func (s *CapacityService) ListCapacityWindows(ctx context.Context, actor app.Actor, orgID, bayID uuid.UUID, from, to time.Time) ([]domain.BayCapacityWindow, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if !to.After(from) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	if _, err := s.Bays.GetByID(ctx, orgID, bayID); err != nil {
+		return nil, err
+	}
+	return s.Windows.ListOverlapping(ctx, orgID, bayID, from, to)
+}
+
+func (s *CapacityService) DeleteCapacityWindow(ctx context.Context, actor app.Actor, orgID, bayID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	window, err := s.Windows.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if window.BayID != bayID {
+		return domain.ErrNotFound
+	}
+	if err := s.Windows.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "hangar_bay", bayID, domain.AuditActionUpdate, map[string]any{
+		"capacity_window_id": id,
+		"deleted":            true,
+	})
+	return nil
+}
+
+// FreeWindows returns, for each bay of the station, the intervals between
+// from and to in which at least minSlots slots are free.
+func (s *CapacityService) FreeWindows(ctx context.Context, actor app.Actor, orgID, stationID uuid.UUID, from, to time.Time, minSlots int) ([]BayAvailability, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > maxAvailabilitySpan {
+		return nil, domain.NewValidationError("range must not exceed 92 days")
+	}
+	if minSlots <= 0 {
+		minSlots = 1
+	}
+	if _, err := s.Stations.GetByID(ctx, orgID, stationID); err != nil {
+		return nil, err
+	}
+	var bays []domain.HangarBay
+	for offset := 0; ; offset += capacityPageSize {
+		page, err := s.Bays.List(ctx, ports.HangarBayFilter{OrgID: &orgID, StationID: &stationID, Limit: capacityPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		bays = append(bays, page...)
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+
+	availability := make([]BayAvailability, 0, len(bays))
+	for _, bay := range bays {
+		windows, err := s.Windows.ListOverlapping(ctx, orgID, bay.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		booked, err := s.activeTasks(ctx, ports.TaskFilter{OrgID: &orgID, BayID: &bay.ID}, from, to)
+		if err != nil {
+			return nil, err
+		}
+		free := make([]domain.CapacityInterval, 0)
+		for _, interval := range bay.Timeline(windows, booked, from, to) {
+			if interval.Free() >= minSlots {
+				free = append(free, interval)
+			}
+		}
+		availability = append(availability, BayAvailability{Bay: bay, Intervals: free})
+	}
+	return availability, nil
+}
+
+// Reserve checks an active task against the aircraft's other bookings and,
+// when the task is placed in a bay, assigns it a slot free for its whole
+// window. The task keeps its current slot when that slot is still free.
+func (s *CapacityService) Reserve(ctx context.Context, task *domain.MaintenanceTask) error {
+	return s.reserve(ctx, task, nil)
+}
+
+// CapacityViolation is a capacity conflict found for one task of a set.
+type CapacityViolation struct {
+	TaskID  uuid.UUID
+	Kind    domain.CapacityConflictKind
+	Message string
+}
+
+// ReserveMoved reserves tasks that move together, such as a rescheduled
+// task and its cascaded dependents. Each task is checked against the others
+// at their new windows rather than the stored ones, and takes its slot in
+// turn.
+func (s *CapacityService) ReserveMoved(ctx context.Context, tasks []domain.MaintenanceTask) error {
+	_, err := s.reserveMoved(ctx, tasks, true)
+	return err
+}
+
+// CheckMoved runs the ReserveMoved checks on a copy of tasks without
+// stopping at the first conflict, and returns every capacity conflict
+// found.
+func (s *CapacityService) CheckMoved(ctx context.Context, tasks []domain.MaintenanceTask) ([]CapacityViolation, error) {
+	return s.reserveMoved(ctx, append([]domain.MaintenanceTask(nil), tasks...), false)
+}
+
+func (s *CapacityService) reserveMoved(ctx context.Context, tasks []domain.MaintenanceTask, stop bool) ([]CapacityViolation, error) {
+	moved := make(map[uuid.UUID]domain.MaintenanceTask, len(tasks))
+	for _, task := range tasks {
+		moved[task.ID] = task
+	}
+	var violations []CapacityViolation
+	for i := range tasks {
+		err := s.reserve(ctx, &tasks[i], moved)
+		var conflict *domain.CapacityConflictError
+		if err != nil && (stop || !errors.As(err, &conflict)) {
+			return nil, err
+		}
+		if conflict != nil {
+			violations = append(violations, CapacityViolation{TaskID: tasks[i].ID, Kind: conflict.Kind, Message: conflict.Message})
+			continue
+		}
+		moved[tasks[i].ID] = tasks[i]
+	}
+	return violations, nil
+}
+
+func (s *CapacityService) reserve(ctx context.Context, task *domain.MaintenanceTask, moved map[uuid.UUID]domain.MaintenanceTask) error {
+	if !task.IsActive() {
+		return nil
+	}
+	overlapping, err := s.activeTasks(ctx, ports.TaskFilter{OrgID: &task.OrgID, AircraftID: &task.AircraftID}, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	overlapping = withMoved(overlapping, moved, task.StartTime, task.EndTime, func(other domain.MaintenanceTask) bool {
+		return other.AircraftID == task.AircraftID
+	})
+	for _, other := range overlapping {
+		if other.ID != task.ID {
+			return domain.NewCapacityConflict(domain.CapacityConflictAircraftOverlap, fmt.Sprintf("aircraft already has task %s scheduled from %s to %s", other.ID, other.StartTime.Format(time.RFC3339), other.EndTime.Format(time.RFC3339)))
+		}
+	}
+	if task.BayID == nil {
+		task.BaySlot = nil
+		return nil
+	}
+
+	bay, err := s.Bays.GetByID(ctx, task.OrgID, *task.BayID)
+	if err != nil {
+		return err
+	}
+	windows, err := s.Windows.ListOverlapping(ctx, task.OrgID, bay.ID, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	candidates, err := s.activeTasks(ctx, ports.TaskFilter{OrgID: &task.OrgID, BayID: &bay.ID}, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	candidates = withMoved(candidates, moved, task.StartTime, task.EndTime, func(other domain.MaintenanceTask) bool {
+		return other.OccupiesBay(bay.ID)
+	})
+	booked := make([]domain.MaintenanceTask, 0, len(candidates))
+	for _, other := range candidates {
+		if other.ID != task.ID {
+			booked = append(booked, other)
+		}
+	}
+	slot, ok := domain.FreeBaySlot(bay.CapacityDuring(windows, task.StartTime, task.EndTime), booked, task.BaySlot)
+	if !ok {
+		return domain.NewCapacityConflict(domain.CapacityConflictBayFull, fmt.Sprintf("bay %s has no free slot from %s to %s", bay.Code, task.StartTime.Format(time.RFC3339), task.EndTime.Format(time.RFC3339)))
+	}
+	task.BaySlot = &slot
+	return nil
+}
+
+// withMoved swaps the stored versions of moved tasks for their new ones,
+// keeping a moved task only when it matches and still overlaps [from, to).
+func withMoved(stored []domain.MaintenanceTask, moved map[uuid.UUID]domain.MaintenanceTask, from, to time.Time, match func(domain.MaintenanceTask) bool) []domain.MaintenanceTask {
+	if len(moved) == 0 {
+		return stored
+	}
+	out := make([]domain.MaintenanceTask, 0, len(stored))
+	for _, task := range stored {
+		if _, ok := moved[task.ID]; !ok {
+			out = append(out, task)
+		}
+	}
+	for _, task := range moved {
+		if task.IsActive() && task.Overlaps(from, to) && match(task) {
+			out = append(out, task)
+		}
+	}
+	return out
+}
+
+// activeTasks pages through the open tasks matching filter whose window
+// intersects [from, to). A zero to leaves the range open-ended.
+func (s *CapacityService) activeTasks(ctx context.Context, filter ports.TaskFilter, from, to time.Time) ([]domain.MaintenanceTask, error) {
+	filter.ActiveOnly = true
+	filter.OverlapFrom = &from
+	if !to.IsZero() {
+		filter.OverlapTo = &to
+	}
+	filter.Limit = capacityPageSize
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += capacityPageSize {
+		filter.Offset = offset
+		page, err := s.Tasks.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+	return tasks, nil
+}
+
+func (s *CapacityService) audit(ctx context.Context, actor app.Actor, orgID uuid.UUID, entityType string, entityID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func canManageCapacity(actor app.Actor) bool {
+	return actor.Role == domain.RoleScheduler || actor.Role == domain.RoleAdmin || actor.Role == domain.RoleTenantAdmin
+}
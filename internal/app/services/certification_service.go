@@ -222,7 +222,6 @@ func (s *CertificationService) CheckQualification(ctx context.Context, orgID, us
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
-	now := s.Clock.Now()
 	result := domain.QualificationCheckResult{Qualified: true}
 
 	// Get requirements for this task type
@@ -236,10 +235,35 @@ func (s *CertificationService) CheckQualification(ctx context.Context, orgID, us
 		return result, nil
 	}
 
+	if err := s.checkRequirements(ctx, orgID, userID, requirements, aircraftTypeID, &result); err != nil {
+		return result, err
+	}
+
+	// Check type rating if aircraft type is specified
+	if aircraftTypeID != nil {
+		hasRating, err := s.Certs.HasTypeRating(ctx, orgID, userID, *aircraftTypeID)
+		if err != nil {
+			return result, err
+		}
+		if !hasRating {
+			result.Qualified = false
+			result.MissingTypeRatings = append(result.MissingTypeRatings, aircraftTypeID.String())
+			result.Reasons = append(result.Reasons, "missing type rating for aircraft")
+		}
+	}
+
+	return result, nil
+}
+
+// checkRequirements checks the user's certifications, recency and skills
+// against requirements and records what is missing in result.
+func (s *CertificationService) checkRequirements(ctx context.Context, orgID, userID uuid.UUID, requirements []domain.TaskSkillRequirement, aircraftTypeID *uuid.UUID, result *domain.QualificationCheckResult) error {
+	now := s.Clock.Now()
+
 	// Get user's certifications
 	certs, err := s.Certs.ListCertsByUser(ctx, orgID, userID)
 	if err != nil {
-		return result, err
+		return err
 	}
 	certMap := make(map[uuid.UUID]domain.EmployeeCertification)
 	for _, c := range certs {
@@ -249,7 +273,7 @@ func (s *CertificationService) CheckQualification(ctx context.Context, orgID, us
 	// Get user's skills
 	skills, err := s.Certs.ListSkillsByUser(ctx, orgID, userID)
 	if err != nil {
-		return result, err
+		return err
 	}
 	skillMap := make(map[uuid.UUID]domain.EmployeeSkill)
 	for _, sk := range skills {
@@ -312,8 +336,65 @@ func (s *CertificationService) CheckQualification(ctx context.Context, orgID, us
 			}
 		}
 	}
+	return nil
+}
 
-	// Check type rating if aircraft type is specified
+// CheckInspectionAuthorization checks that the user holds the inspection
+// authorization required to inspect a task's required inspection items:
+// every requirement of the task type flagged IsInspectionRole. A task type
+// without such a requirement has no authorized inspectors.
+func (s *CertificationService) CheckInspectionAuthorization(ctx context.Context, orgID, userID uuid.UUID, taskType domain.TaskType, aircraftTypeID *uuid.UUID) (domain.QualificationCheckResult, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	result := domain.QualificationCheckResult{Qualified: true}
+	requirements, err := s.Certs.ListRequirements(ctx, orgID, taskType, aircraftTypeID)
+	if err != nil {
+		return result, err
+	}
+	inspection := make([]domain.TaskSkillRequirement, 0, len(requirements))
+	for _, req := range requirements {
+		if req.IsInspectionRole {
+			inspection = append(inspection, req)
+		}
+	}
+	if len(inspection) == 0 {
+		result.Qualified = false
+		result.Reasons = append(result.Reasons, "no inspection authorization is defined for this task type")
+		return result, nil
+	}
+	if err := s.checkRequirements(ctx, orgID, userID, inspection, aircraftTypeID, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// CheckCertifyingAuthorization checks that the user may certify a task as
+// complete: every requirement of the task type except those only an
+// inspector needs (flagged IsInspectionRole but not IsCertifyingRole), and
+// the aircraft's type rating. As with CheckQualification, a task type
+// without requirements can be certified by any mechanic.
+func (s *CertificationService) CheckCertifyingAuthorization(ctx context.Context, orgID, userID uuid.UUID, taskType domain.TaskType, aircraftTypeID *uuid.UUID) (domain.QualificationCheckResult, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	result := domain.QualificationCheckResult{Qualified: true}
+	requirements, err := s.Certs.ListRequirements(ctx, orgID, taskType, aircraftTypeID)
+	if err != nil {
+		return result, err
+	}
+	if len(requirements) == 0 {
+		return result, nil
+	}
+	certifying := make([]domain.TaskSkillRequirement, 0, len(requirements))
+	for _, req := range requirements {
+		if req.IsCertifyingRole || !req.IsInspectionRole {
+			certifying = append(certifying, req)
+		}
+	}
+	if err := s.checkRequirements(ctx, orgID, userID, certifying, aircraftTypeID, &result); err != nil {
+		return result, err
+	}
 	if aircraftTypeID != nil {
 		hasRating, err := s.Certs.HasTypeRating(ctx, orgID, userID, *aircraftTypeID)
 		if err != nil {
@@ -325,7 +406,37 @@ func (s *CertificationService) CheckQualification(ctx context.Context, orgID, us
 			result.Reasons = append(result.Reasons, "missing type rating for aircraft")
 		}
 	}
+	return result, nil
+}
 
+// CheckCrewQualification checks the user against the requirements of a crew
+// role on a task. Certifying staff and inspectors need the matching
+// authorization; leads and technicians need the requirements flagged for
+// neither role.
+func (s *CertificationService) CheckCrewQualification(ctx context.Context, orgID, userID uuid.UUID, role domain.CrewRole, taskType domain.TaskType, aircraftTypeID *uuid.UUID) (domain.QualificationCheckResult, error) {
+	switch role {
+	case domain.CrewRoleCertifying:
+		return s.CheckCertifyingAuthorization(ctx, orgID, userID, taskType, aircraftTypeID)
+	case domain.CrewRoleInspector:
+		return s.CheckInspectionAuthorization(ctx, orgID, userID, taskType, aircraftTypeID)
+	}
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	result := domain.QualificationCheckResult{Qualified: true}
+	requirements, err := s.Certs.ListRequirements(ctx, orgID, taskType, aircraftTypeID)
+	if err != nil {
+		return result, err
+	}
+	general := make([]domain.TaskSkillRequirement, 0, len(requirements))
+	for _, req := range requirements {
+		if !req.IsCertifyingRole && !req.IsInspectionRole {
+			general = append(general, req)
+		}
+	}
+	if err := s.checkRequirements(ctx, orgID, userID, general, aircraftTypeID, &result); err != nil {
+		return result, err
+	}
 	return result, nil
 }
 
@@ -12,9 +12,11 @@ import (
 
 type ComplianceService struct {
 	Compliance ports.ComplianceRepository
-	Audit      ports.AuditRepository
-	Outbox     ports.OutboxRepository
-	Clock      app.Clock
+	// Findings raises a finding on the task when an item fails. Optional.
+	Findings *FindingService
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
 }
 
 func (s *ComplianceService) List(ctx context.Context, actor app.Actor, filter ports.ComplianceFilter) ([]domain.ComplianceItem, error) {
@@ -67,6 +69,7 @@ func (s *ComplianceService) Update(ctx context.Context, actor app.Actor, item do
 	}
 
 	item.OrgID = actor.OrgID
+	item.TaskID = existing.TaskID
 	item.UpdatedAt = s.Clock.Now()
 
 	if err := s.Compliance.Update(ctx, item); err != nil {
@@ -76,6 +79,12 @@ func (s *ComplianceService) Update(ctx context.Context, actor app.Actor, item do
 	s.emitComplianceAudit(ctx, actor, item, domain.AuditActionUpdate)
 	s.emitComplianceOutbox(ctx, item, "compliance_updated")
 
+	if item.Result == domain.ComplianceFail && s.Findings != nil {
+		if err := s.Findings.RaiseFromCompliance(ctx, actor, item); err != nil {
+			return domain.ComplianceItem{}, err
+		}
+	}
+
 	return item, nil
 }
 
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+const componentPageSize = 200
+
+// ComponentService fits serialized parts to aircraft positions and removes
+// them again, keeping a traceable log of every change.
+type ComponentService struct {
+	Changes   ports.ComponentChangeRepository
+	PartItems ports.PartItemRepository
+	Aircraft  ports.AircraftRepository
+	// Utilization refines the aircraft hours captured on each change beyond
+	// the whole hours kept on the aircraft record, and supplies the totals
+	// at the time of a backdated change. Optional.
+	Utilization ports.AircraftUtilizationRepository
+	// Tasks validates the linked task, if any. Optional.
+	Tasks  ports.TaskRepository
+	Audit  ports.AuditRepository
+	Outbox ports.OutboxRepository
+	Clock  app.Clock
+}
+
+type ComponentInstallInput struct {
+	OrgID       *uuid.UUID
+	AircraftID  uuid.UUID
+	PartItemID  uuid.UUID
+	Position    string
+	TaskID      *uuid.UUID
+	Reason      domain.ComponentChangeReason
+	PerformedAt *time.Time
+	Notes       string
+}
+
+type ComponentRemoveInput struct {
+	OrgID       *uuid.UUID
+	AircraftID  uuid.UUID
+	PartItemID  uuid.UUID
+	TaskID      *uuid.UUID
+	Reason      domain.ComponentChangeReason
+	PerformedAt *time.Time
+	Notes       string
+}
+
+// ComponentHistory is the installation record of one serialized part.
+type ComponentHistory struct {
+	Part    domain.PartItem
+	Changes []domain.ComponentChange
+}
+
+func (s *ComponentService) Install(ctx context.Context, actor app.Actor, input ComponentInstallInput) (domain.ComponentChange, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canChangeComponents(actor) {
+		return domain.ComponentChange{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	part, err := s.PartItems.GetByID(ctx, orgID, input.PartItemID)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	if err := part.CanInstall(); err != nil {
+		return domain.ComponentChange{}, err
+	}
+	change, err := s.newChange(ctx, actor, orgID, domain.ComponentInstall, input.AircraftID, part, strings.TrimSpace(input.Position), input.TaskID, input.Reason, input.PerformedAt, input.Notes)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	return s.record(ctx, actor, change, part.SerialNumber)
+}
+
+func (s *ComponentService) Remove(ctx context.Context, actor app.Actor, input ComponentRemoveInput) (domain.ComponentChange, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canChangeComponents(actor) {
+		return domain.ComponentChange{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	part, err := s.PartItems.GetByID(ctx, orgID, input.PartItemID)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	if err := part.CanRemove(input.AircraftID); err != nil {
+		return domain.ComponentChange{}, err
+	}
+	change, err := s.newChange(ctx, actor, orgID, domain.ComponentRemove, input.AircraftID, part, part.Position, input.TaskID, input.Reason, input.PerformedAt, input.Notes)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	return s.record(ctx, actor, change, part.SerialNumber)
+}
+
+// Configuration returns the parts currently fitted to the aircraft, ordered
+// by position.
+func (s *ComponentService) Configuration(ctx context.Context, actor app.Actor, orgID, aircraftID uuid.UUID) ([]domain.PartItem, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if s.Aircraft != nil {
+		if _, err := s.Aircraft.GetByID(ctx, orgID, aircraftID); err != nil {
+			return nil, err
+		}
+	}
+	var installed []domain.PartItem
+	for offset := 0; ; offset += componentPageSize {
+		page, err := s.PartItems.List(ctx, ports.PartItemFilter{OrgID: &orgID, AircraftID: &aircraftID, Limit: componentPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		installed = append(installed, page...)
+		if len(page) < componentPageSize {
+			break
+		}
+	}
+	sort.Slice(installed, func(i, j int) bool {
+		return installed[i].Position < installed[j].Position
+	})
+	return installed, nil
+}
+
+// History returns every installation and removal of the part with the given
+// serial number, oldest first.
+func (s *ComponentService) History(ctx context.Context, actor app.Actor, orgID uuid.UUID, serial string, limit, offset int) (ComponentHistory, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	part, err := s.PartItems.GetBySerialNumber(ctx, orgID, serial)
+	if err != nil {
+		return ComponentHistory{}, err
+	}
+	changes, err := s.Changes.List(ctx, ports.ComponentChangeFilter{OrgID: &orgID, PartItemID: &part.ID, Limit: limit, Offset: offset})
+	if err != nil {
+		return ComponentHistory{}, err
+	}
+	return ComponentHistory{Part: part, Changes: changes}, nil
+}
+
+func (s *ComponentService) newChange(ctx context.Context, actor app.Actor, orgID uuid.UUID, action domain.ComponentChangeAction, aircraftID uuid.UUID, part domain.PartItem, position string, taskID *uuid.UUID, reason domain.ComponentChangeReason, performedAt *time.Time, notes string) (domain.ComponentChange, error) {
+	now := s.Clock.Now()
+	at := now
+	if performedAt != nil {
+		at = performedAt.UTC()
+		if at.After(now) {
+			return domain.ComponentChange{}, domain.NewValidationError("performed_at must not be in the future")
+		}
+	}
+	aircraft, err := s.Aircraft.GetByID(ctx, orgID, aircraftID)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	hours := float64(aircraft.FlightHoursTotal)
+	cycles := aircraft.CyclesTotal
+	if s.Utilization != nil {
+		latest, err := s.Utilization.GetLatest(ctx, orgID, aircraft.ID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return domain.ComponentChange{}, err
+		}
+		if err == nil {
+			hours = math.Max(hours, latest.HoursTotal)
+			if latest.CyclesTotal > cycles {
+				cycles = latest.CyclesTotal
+			}
+			// Usage logged after a backdated change is not part of the
+			// aircraft's totals at the time.
+			if latest.UtilizationDate.After(at) {
+				hours, cycles, err = s.totalsAt(ctx, orgID, aircraft.ID, at)
+				if err != nil {
+					return domain.ComponentChange{}, err
+				}
+			}
+		}
+	}
+	if taskID != nil && s.Tasks != nil {
+		task, err := s.Tasks.GetByID(ctx, orgID, *taskID)
+		if err != nil {
+			return domain.ComponentChange{}, err
+		}
+		if task.AircraftID != aircraft.ID {
+			return domain.ComponentChange{}, domain.NewValidationError("task belongs to a different aircraft")
+		}
+	}
+
+	var performedBy *uuid.UUID
+	if actor.UserID != uuid.Nil {
+		userID := actor.UserID
+		performedBy = &userID
+	}
+	change := domain.ComponentChange{
+		ID:                      uuid.New(),
+		OrgID:                   orgID,
+		PartItemID:              part.ID,
+		AircraftID:              aircraft.ID,
+		Action:                  action,
+		Position:                position,
+		TaskID:                  taskID,
+		Reason:                  reason,
+		AircraftHours:           roundHours(hours),
+		AircraftCycles:          cycles,
+		PartHoursSinceNew:       part.HoursSinceNew,
+		PartCyclesSinceNew:      part.CyclesSinceNew,
+		PartHoursSinceOverhaul:  part.HoursSinceOverhaul,
+		PartCyclesSinceOverhaul: part.CyclesSinceOverhaul,
+		PerformedBy:             performedBy,
+		PerformedAt:             at,
+		Notes:                   notes,
+		CreatedAt:               now,
+	}
+	if err := change.Validate(); err != nil {
+		return domain.ComponentChange{}, err
+	}
+	return change, nil
+}
+
+// totalsAt returns the aircraft totals from the last utilization entry on or
+// before at. Changes dated before the aircraft's first entry are rejected, as
+// its totals at the time are unknown.
+func (s *ComponentService) totalsAt(ctx context.Context, orgID, aircraftID uuid.UUID, at time.Time) (float64, int, error) {
+	entries, err := s.Utilization.List(ctx, ports.AircraftUtilizationFilter{
+		OrgID:      &orgID,
+		AircraftID: &aircraftID,
+		To:         &at,
+		Limit:      1,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(entries) == 0 {
+		return 0, 0, domain.NewValidationError("performed_at precedes the aircraft's utilization history")
+	}
+	return entries[0].HoursTotal, entries[0].CyclesTotal, nil
+}
+
+func (s *ComponentService) record(ctx context.Context, actor app.Actor, change domain.ComponentChange, serial string) (domain.ComponentChange, error) {
+	created, err := s.Changes.Record(ctx, change)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	if s.Audit != nil {
+		_ = s.Audit.Insert(ctx, domain.AuditLog{
+			ID:         uuid.New(),
+			OrgID:      created.OrgID,
+			EntityType: "part_item",
+			EntityID:   created.PartItemID,
+			Action:     domain.AuditActionUpdate,
+			UserID:     actor.UserID,
+			RequestID:  uuid.Nil,
+			Timestamp:  s.Clock.Now(),
+			Details: map[string]any{
+				"component_change_id": created.ID,
+				"action":              created.Action,
+				"aircraft_id":         created.AircraftID,
+				"position":            created.Position,
+				"reason":              created.Reason,
+			},
+		})
+	}
+	s.emit(ctx, created, serial)
+	return created, nil
+}
+
+func (s *ComponentService) emit(ctx context.Context, change domain.ComponentChange, serial string) {
+	if s.Outbox == nil {
+		return
+	}
+	eventType := "component_installed"
+	if change.Action == domain.ComponentRemove {
+		eventType = "component_removed"
+	}
+	payload := map[string]any{
+		"version":             1,
+		"org_id":              change.OrgID,
+		"component_change_id": change.ID,
+		"part_item_id":        change.PartItemID,
+		"serial_number":       serial,
+		"aircraft_id":         change.AircraftID,
+		"position":            change.Position,
+		"reason":              change.Reason,
+		"aircraft_hours":      change.AircraftHours,
+		"aircraft_cycles":     change.AircraftCycles,
+		"performed_at":        change.PerformedAt,
+		"timestamp":           s.Clock.Now(),
+	}
+	dedupeKey := fmt.Sprintf("%s:%s:%s", eventType, change.OrgID, change.ID)
+	_ = s.Outbox.Enqueue(ctx, change.OrgID, eventType, "part_item", change.PartItemID, payload, dedupeKey)
+}
+
+func canChangeComponents(actor app.Actor) bool {
+	return actor.Role == domain.RoleMechanic || actor.Role == domain.RoleAdmin || actor.Role == domain.RoleTenantAdmin
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CrewService assigns mechanics to the crew of a task, each in a role with
+// planned hours.
+type CrewService struct {
+	Assignments ports.TaskAssignmentRepository
+	Tasks       ports.TaskRepository
+	Aircraft    ports.AircraftRepository
+	// Certifications checks each member against the requirements of their
+	// role. Optional; without it any mechanic may hold any role.
+	Certifications *CertificationService
+	// Roster rejects members outside their shifts, during their absences or
+	// assigned to overlapping tasks. Optional.
+	Roster *RosterService
+	Audit  ports.AuditRepository
+	Outbox ports.OutboxRepository
+	Clock  app.Clock
+}
+
+type CrewAssignInput struct {
+	MechanicID   uuid.UUID
+	Role         domain.CrewRole
+	PlannedHours float64
+}
+
+// Assign adds a mechanic to the task's crew. The task must be scheduled or
+// in progress.
+func (s *CrewService) Assign(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID, input CrewAssignInput) (domain.TaskAssignment, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.TaskAssignment{}, domain.ErrForbidden
+	}
+	task, err := s.activeTask(ctx, actor, orgID, taskID)
+	if err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	now := s.Clock.Now().UTC()
+	assignment := domain.TaskAssignment{
+		ID:           uuid.New(),
+		OrgID:        task.OrgID,
+		TaskID:       task.ID,
+		MechanicID:   input.MechanicID,
+		Role:         input.Role,
+		PlannedHours: input.PlannedHours,
+		AssignedBy:   actor.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := assignment.Validate(); err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	if err := s.checkQualification(ctx, task, assignment.MechanicID, assignment.Role); err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	crew, err := s.Assignments.ListByTask(ctx, task.OrgID, task.ID)
+	if err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	// A mechanic already working the task has been checked against the
+	// roster; their own task would otherwise count as an overlap.
+	if s.Roster != nil && !crew.Includes(assignment.MechanicID) {
+		check := task
+		check.AssignedMechanicID = &assignment.MechanicID
+		if err := s.Roster.CheckAssignment(ctx, check); err != nil {
+			return domain.TaskAssignment{}, err
+		}
+	}
+	created, err := s.Assignments.Create(ctx, assignment)
+	if err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	s.audit(ctx, actor, created, domain.AuditActionCreate, map[string]any{
+		"task_id":       created.TaskID,
+		"mechanic_id":   created.MechanicID,
+		"role":          created.Role,
+		"planned_hours": created.PlannedHours,
+	})
+	s.emitOutbox(ctx, created, "task_crew_assigned")
+	return created, nil
+}
+
+func (s *CrewService) List(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID) (domain.Crew, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return nil, domain.ErrForbidden
+	}
+	if _, err := s.Tasks.GetByID(ctx, orgID, taskID); err != nil {
+		return nil, err
+	}
+	return s.Assignments.ListByTask(ctx, orgID, taskID)
+}
+
+// UpdatePlannedHours changes the hours planned for a crew member.
+func (s *CrewService) UpdatePlannedHours(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, hours float64) (domain.TaskAssignment, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.TaskAssignment{}, domain.ErrForbidden
+	}
+	assignment, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	if _, err := s.activeTask(ctx, actor, orgID, assignment.TaskID); err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	previous := assignment.PlannedHours
+	assignment.PlannedHours = hours
+	assignment.UpdatedAt = s.Clock.Now().UTC()
+	if err := assignment.Validate(); err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	updated, err := s.Assignments.UpdatePlannedHours(ctx, assignment)
+	if err != nil {
+		return domain.TaskAssignment{}, err
+	}
+	s.audit(ctx, actor, updated, domain.AuditActionUpdate, map[string]any{
+		"task_id": updated.TaskID,
+		"from":    previous,
+		"to":      updated.PlannedHours,
+	})
+	return updated, nil
+}
+
+// Remove takes a mechanic off the task's crew.
+func (s *CrewService) Remove(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	assignment, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.activeTask(ctx, actor, orgID, assignment.TaskID); err != nil {
+		return err
+	}
+	if err := s.Assignments.Delete(ctx, assignment.OrgID, assignment.ID); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, assignment, domain.AuditActionDelete, map[string]any{
+		"task_id":     assignment.TaskID,
+		"mechanic_id": assignment.MechanicID,
+		"role":        assignment.Role,
+	})
+	s.emitOutbox(ctx, assignment, "task_crew_removed")
+	return nil
+}
+
+// CheckSignOff re-checks at completion that the task is signed off by
+// certifying staff who still hold the authorization. A mechanic signs off
+// as themselves; when a scheduler completes the task, one of the crew's
+// certifying staff must be authorized.
+func (s *CrewService) CheckSignOff(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, crew domain.Crew) error {
+	if s.Certifications == nil {
+		return nil
+	}
+	certifying := crew.Certifying()
+	if actor.Role == domain.RoleMechanic {
+		certifying = []uuid.UUID{actor.UserID}
+	}
+	var reasons []string
+	for _, mechanicID := range certifying {
+		result, err := s.qualification(ctx, task, mechanicID, domain.CrewRoleCertifying)
+		if err != nil {
+			return err
+		}
+		if result.Qualified {
+			return nil
+		}
+		reasons = append(reasons, result.Reasons...)
+	}
+	return domain.NewValidationError("certifying staff lacks authorization: " + strings.Join(reasons, ", "))
+}
+
+func (s *CrewService) checkQualification(ctx context.Context, task domain.MaintenanceTask, mechanicID uuid.UUID, role domain.CrewRole) error {
+	if s.Certifications == nil {
+		return nil
+	}
+	result, err := s.qualification(ctx, task, mechanicID, role)
+	if err != nil {
+		return err
+	}
+	if !result.Qualified {
+		return domain.NewValidationError(fmt.Sprintf("mechanic is not qualified as %s: %s", role, strings.Join(result.Reasons, ", ")))
+	}
+	return nil
+}
+
+func (s *CrewService) qualification(ctx context.Context, task domain.MaintenanceTask, mechanicID uuid.UUID, role domain.CrewRole) (domain.QualificationCheckResult, error) {
+	aircraft, err := s.Aircraft.GetByID(ctx, task.OrgID, task.AircraftID)
+	if err != nil {
+		return domain.QualificationCheckResult{}, err
+	}
+	return s.Certifications.CheckCrewQualification(ctx, task.OrgID, mechanicID, role, task.Type, aircraft.AircraftTypeID)
+}
+
+func (s *CrewService) activeTask(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID) (domain.MaintenanceTask, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.MaintenanceTask{}, domain.ErrForbidden
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, taskID)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if !task.IsActive() {
+		return domain.MaintenanceTask{}, domain.NewConflictError("task is " + string(task.State))
+	}
+	return task, nil
+}
+
+func (s *CrewService) load(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.TaskAssignment, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.TaskAssignment{}, domain.ErrForbidden
+	}
+	return s.Assignments.GetByID(ctx, orgID, id)
+}
+
+func (s *CrewService) audit(ctx context.Context, actor app.Actor, assignment domain.TaskAssignment, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      assignment.OrgID,
+		EntityType: "task_assignment",
+		EntityID:   assignment.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *CrewService) emitOutbox(ctx context.Context, assignment domain.TaskAssignment, eventType string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":       1,
+		"org_id":        assignment.OrgID,
+		"assignment_id": assignment.ID,
+		"task_id":       assignment.TaskID,
+		"mechanic_id":   assignment.MechanicID,
+		"role":          assignment.Role,
+		"timestamp":     s.Clock.Now(),
+	}
+	_ = s.Outbox.Enqueue(ctx, assignment.OrgID, eventType, "task_assignment", assignment.ID, payload, fmt.Sprintf("%s:%s:%s", eventType, assignment.OrgID, assignment.ID))
+}
@@ -0,0 +1,491 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// defaultRectificationDuration is the window booked for an automatically
+// raised rectification task when the deferral does not say how long the
+// repair takes.
+const defaultRectificationDuration = 2 * time.Hour
+
+// DefectService records defects against aircraft and their deferral under
+// the Minimum Equipment List.
+type DefectService struct {
+	Defects  ports.DefectRepository
+	Aircraft ports.AircraftRepository
+	Tasks    ports.TaskRepository
+	// TaskSvc raises a rectification task when a defect is deferred.
+	// Optional; without it rectification tasks are created by hand.
+	TaskSvc *TaskService
+	Audit   ports.AuditRepository
+	Outbox  ports.OutboxRepository
+	Clock   app.Clock
+}
+
+type DefectReportInput struct {
+	OrgID       *uuid.UUID
+	AircraftID  uuid.UUID
+	Title       string
+	Description string
+	ATAChapter  string
+}
+
+type DefectDeferInput struct {
+	Category                domain.MELCategory
+	MELItem                 string
+	Reference               string
+	OperationalRestrictions string
+	ExpiresAt               *time.Time
+	// RectificationDuration is the window booked for the rectification
+	// task, ending at the deferral's expiry. Zero books two hours.
+	RectificationDuration time.Duration
+}
+
+type DeferralExtensionInput struct {
+	NewExpiresAt      time.Time
+	Reason            string
+	ApprovalReference string
+}
+
+type RectificationTaskInput struct {
+	StartTime          time.Time
+	EndTime            time.Time
+	BayID              *uuid.UUID
+	AssignedMechanicID *uuid.UUID
+}
+
+func (s *DefectService) Report(ctx context.Context, actor app.Actor, input DefectReportInput) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canCarryOutWork(actor) {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	if _, err := s.Aircraft.GetByID(ctx, orgID, input.AircraftID); err != nil {
+		return domain.Defect{}, err
+	}
+
+	now := s.Clock.Now().UTC()
+	defect := domain.Defect{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		AircraftID:  input.AircraftID,
+		Title:       strings.TrimSpace(input.Title),
+		Description: strings.TrimSpace(input.Description),
+		ATAChapter:  strings.TrimSpace(input.ATAChapter),
+		State:       domain.DefectOpen,
+		ReportedBy:  actor.UserID,
+		ReportedAt:  now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := defect.Validate(); err != nil {
+		return domain.Defect{}, err
+	}
+	created, err := s.Defects.Create(ctx, defect)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, created.ID, domain.AuditActionCreate, map[string]any{
+		"aircraft_id": created.AircraftID,
+		"title":       created.Title,
+		"ata_chapter": created.ATAChapter,
+	})
+	s.emitOutbox(ctx, created, "defect_reported")
+	return created, nil
+}
+
+func (s *DefectService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Defect, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	return s.Defects.GetByID(ctx, orgID, id)
+}
+
+func (s *DefectService) List(ctx context.Context, actor app.Actor, filter ports.DefectFilter) ([]domain.Defect, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Defects.List(ctx, filter)
+}
+
+func (s *DefectService) ListExtensions(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) ([]domain.DeferralExtension, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return nil, domain.ErrForbidden
+	}
+	if _, err := s.Defects.GetByID(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	return s.Defects.ListExtensions(ctx, orgID, id)
+}
+
+// Defer defers an open defect under an MEL item and raises a rectification
+// task finishing by the deferral's expiry. A task that cannot be booked,
+// for example because the window is taken, does not fail the deferral; the
+// defect is returned without a rectification task for a scheduler to add.
+func (s *DefectService) Defer(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input DefectDeferInput) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canCarryOutWork(actor) {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	defect, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	if input.RectificationDuration < 0 {
+		return domain.Defect{}, domain.NewValidationError("rectification duration must not be negative")
+	}
+	now := s.Clock.Now().UTC()
+	if err := defect.Defer(domain.DefectDeferral{
+		Category:                input.Category,
+		MELItem:                 input.MELItem,
+		Reference:               input.Reference,
+		OperationalRestrictions: input.OperationalRestrictions,
+		ExpiresAt:               input.ExpiresAt,
+	}, actor.UserID, now); err != nil {
+		return domain.Defect{}, err
+	}
+	defect.UpdatedAt = now
+	deferred, err := s.Defects.Update(ctx, defect, domain.DefectOpen)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+
+	details := map[string]any{
+		"mel_category":       *deferred.MELCategory,
+		"mel_item":           deferred.MELItem,
+		"deferral_reference": deferred.DeferralReference,
+		"expires_at":         *deferred.ExpiresAt,
+	}
+	if s.TaskSvc != nil {
+		duration := input.RectificationDuration
+		if duration == 0 {
+			duration = defaultRectificationDuration
+		}
+		end := *deferred.ExpiresAt
+		start := end.Add(-duration)
+		if start.Before(now) {
+			start = now
+		}
+		withTask, err := s.createRectificationTask(ctx, actor, deferred, RectificationTaskInput{StartTime: start, EndTime: end})
+		if err != nil {
+			details["rectification_task_error"] = err.Error()
+		} else {
+			deferred = withTask
+			details["rectification_task_id"] = *deferred.RectificationTaskID
+		}
+	}
+	s.audit(ctx, actor, deferred.OrgID, deferred.ID, domain.AuditActionStateChange, details)
+	s.emitOutbox(ctx, deferred, "defect_deferred")
+	return deferred, nil
+}
+
+// CreateRectificationTask books the rectification task of a deferred defect
+// that has none, such as when booking it on deferral failed.
+func (s *DefectService) CreateRectificationTask(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input RectificationTaskInput) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	if s.TaskSvc == nil {
+		return domain.Defect{}, domain.NewValidationError("task service unavailable")
+	}
+	defect, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	if defect.State != domain.DefectDeferred {
+		return domain.Defect{}, domain.NewConflictError("only deferred defects get a rectification task")
+	}
+	if defect.RectificationTaskID != nil {
+		return domain.Defect{}, domain.NewConflictError("defect already has a rectification task")
+	}
+	if input.EndTime.After(*defect.ExpiresAt) {
+		return domain.Defect{}, domain.NewValidationError("rectification task must end by the deferral's expiry")
+	}
+	updated, err := s.createRectificationTask(ctx, actor, defect, input)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	s.audit(ctx, actor, updated.OrgID, updated.ID, domain.AuditActionUpdate, map[string]any{
+		"rectification_task_id": *updated.RectificationTaskID,
+	})
+	return updated, nil
+}
+
+// createRectificationTask raises the repair task and links it to the
+// defect. Mechanics may defer defects but not schedule work, so the task
+// is booked on the deferring user's behalf with the scheduler role.
+func (s *DefectService) createRectificationTask(ctx context.Context, actor app.Actor, defect domain.Defect, input RectificationTaskInput) (domain.Defect, error) {
+	scheduler := app.Actor{UserID: actor.UserID, OrgID: defect.OrgID, Role: domain.RoleScheduler}
+	task, err := s.TaskSvc.Create(ctx, scheduler, TaskCreateInput{
+		AircraftID:         defect.AircraftID,
+		BayID:              input.BayID,
+		Type:               domain.TaskTypeRepair,
+		Priority:           rectificationPriority(*defect.MELCategory),
+		StartTime:          input.StartTime,
+		EndTime:            input.EndTime,
+		AssignedMechanicID: input.AssignedMechanicID,
+		Notes:              rectificationNotes(defect),
+		AllowFlightOverlap: true,
+	})
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	defect.RectificationTaskID = &task.ID
+	defect.UpdatedAt = s.Clock.Now().UTC()
+	return s.Defects.Update(ctx, defect, domain.DefectDeferred)
+}
+
+// Extend moves a deferral's expiry out under a quality approval. Only
+// tenant admins and admins approve extensions.
+func (s *DefectService) Extend(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input DeferralExtensionInput) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleAdmin && actor.Role != domain.RoleTenantAdmin {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	defect, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	reason := strings.TrimSpace(input.Reason)
+	approval := strings.TrimSpace(input.ApprovalReference)
+	if reason == "" || approval == "" {
+		return domain.Defect{}, domain.NewValidationError("reason and approval_reference are required")
+	}
+	extensions, err := s.Defects.ListExtensions(ctx, defect.OrgID, defect.ID)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	now := s.Clock.Now().UTC()
+	newExpiry := input.NewExpiresAt.UTC()
+	if err := defect.CanExtend(newExpiry, len(extensions), now); err != nil {
+		return domain.Defect{}, err
+	}
+
+	extended, err := s.Defects.Extend(ctx, domain.DeferralExtension{
+		ID:                uuid.New(),
+		OrgID:             defect.OrgID,
+		DefectID:          defect.ID,
+		PreviousExpiresAt: *defect.ExpiresAt,
+		NewExpiresAt:      newExpiry,
+		Reason:            reason,
+		ApprovalReference: approval,
+		ApprovedBy:        actor.UserID,
+		CreatedAt:         now,
+	})
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	s.audit(ctx, actor, extended.OrgID, extended.ID, domain.AuditActionUpdate, map[string]any{
+		"previous_expires_at": *defect.ExpiresAt,
+		"new_expires_at":      newExpiry,
+		"approval_reference":  approval,
+		"reason":              reason,
+	})
+	s.emitOutbox(ctx, extended, "defect_deferral_extended")
+	return extended, nil
+}
+
+// Rectify signs the defect off as rectified. A rectification task that is
+// still scheduled or in progress must be completed or cancelled first.
+func (s *DefectService) Rectify(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, notes string) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canCarryOutWork(actor) {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	defect, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	if err := defect.CanRectify(); err != nil {
+		return domain.Defect{}, err
+	}
+	if defect.RectificationTaskID != nil && s.Tasks != nil {
+		task, err := s.Tasks.GetByID(ctx, defect.OrgID, *defect.RectificationTaskID)
+		if err != nil {
+			return domain.Defect{}, err
+		}
+		if task.IsActive() {
+			return domain.Defect{}, domain.NewConflictError("rectification task must be completed or cancelled")
+		}
+	}
+
+	from := defect.State
+	now := s.Clock.Now().UTC()
+	defect.State = domain.DefectRectified
+	defect.RectifiedBy = &actor.UserID
+	defect.RectifiedAt = &now
+	defect.Notes = appendDefectNotes(defect.Notes, notes)
+	defect.UpdatedAt = now
+	rectified, err := s.Defects.Update(ctx, defect, from)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	s.audit(ctx, actor, rectified.OrgID, rectified.ID, domain.AuditActionStateChange, map[string]any{
+		"from":  from,
+		"to":    rectified.State,
+		"notes": strings.TrimSpace(notes),
+	})
+	s.emitOutbox(ctx, rectified, "defect_rectified")
+	return rectified, nil
+}
+
+// Close closes a rectified defect after review, or an open one that needs
+// no rectification. Schedulers and admins close defects.
+func (s *DefectService) Close(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, notes string) (domain.Defect, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	defect, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	if err := defect.CanClose(notes); err != nil {
+		return domain.Defect{}, err
+	}
+
+	from := defect.State
+	now := s.Clock.Now().UTC()
+	defect.State = domain.DefectClosed
+	defect.ClosedBy = &actor.UserID
+	defect.ClosedAt = &now
+	defect.Notes = appendDefectNotes(defect.Notes, notes)
+	defect.UpdatedAt = now
+	closed, err := s.Defects.Update(ctx, defect, from)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	s.audit(ctx, actor, closed.OrgID, closed.ID, domain.AuditActionStateChange, map[string]any{
+		"from":  from,
+		"to":    closed.State,
+		"notes": strings.TrimSpace(notes),
+	})
+	s.emitOutbox(ctx, closed, "defect_closed")
+	return closed, nil
+}
+
+// CheckReturnToService rejects returning the aircraft to service while any
+// of its deferrals has expired.
+func (s *DefectService) CheckReturnToService(ctx context.Context, orgID, aircraftID uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	now := s.Clock.Now()
+	// ExpiringBefore is exclusive, while a deferral expiring right now has
+	// already expired; asking only for expired deferrals keeps the check
+	// independent of how many open deferrals the aircraft carries.
+	expiredBy := now.Add(time.Nanosecond)
+	state := domain.DefectDeferred
+	defects, err := s.Defects.List(ctx, ports.DefectFilter{OrgID: &orgID, AircraftID: &aircraftID, State: &state, ExpiringBefore: &expiredBy, Limit: 200})
+	if err != nil {
+		return err
+	}
+	return domain.CheckReturnToService(defects, now)
+}
+
+func (s *DefectService) load(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Defect, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Defect{}, domain.ErrForbidden
+	}
+	return s.Defects.GetByID(ctx, orgID, id)
+}
+
+// canCarryOutWork reports whether the actor may record work on aircraft,
+// such as reporting defects or raising findings: mechanics as well as
+// schedulers and admins.
+func canCarryOutWork(actor app.Actor) bool {
+	return actor.Role == domain.RoleMechanic || canManageCapacity(actor)
+}
+
+// rectificationPriority ranks the rectification task by how short the
+// category's interval is.
+func rectificationPriority(category domain.MELCategory) domain.TaskPriority {
+	switch category {
+	case domain.MELCategoryA, domain.MELCategoryB:
+		return domain.PriorityUrgent
+	default:
+		return domain.PriorityRoutine
+	}
+}
+
+func rectificationNotes(defect domain.Defect) string {
+	notes := fmt.Sprintf("Rectify deferred defect %s (MEL %s, category %s): %s", defect.DeferralReference, defect.MELItem, *defect.MELCategory, defect.Title)
+	if defect.OperationalRestrictions != "" {
+		notes += ". Restrictions: " + defect.OperationalRestrictions
+	}
+	return notes
+}
+
+func appendDefectNotes(existing, notes string) string {
+	notes = strings.TrimSpace(notes)
+	switch {
+	case notes == "":
+		return existing
+	case existing == "":
+		return notes
+	default:
+		return existing + "\n" + notes
+	}
+}
+
+func (s *DefectService) audit(ctx context.Context, actor app.Actor, orgID, defectID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: "defect",
+		EntityID:   defectID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *DefectService) emitOutbox(ctx context.Context, defect domain.Defect, eventType string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":     1,
+		"org_id":      defect.OrgID,
+		"defect_id":   defect.ID,
+		"aircraft_id": defect.AircraftID,
+		"state":       defect.State,
+		"timestamp":   s.Clock.Now(),
+	}
+	if defect.MELCategory != nil {
+		payload["mel_category"] = *defect.MELCategory
+		payload["deferral_reference"] = defect.DeferralReference
+		payload["expires_at"] = *defect.ExpiresAt
+	}
+	_ = s.Outbox.Enqueue(ctx, defect.OrgID, eventType, "defect", defect.ID, payload, fmt.Sprintf("%s:%s:%s:%d", eventType, defect.OrgID, defect.ID, defect.UpdatedAt.UnixNano()))
+}
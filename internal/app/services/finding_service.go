@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// FindingService records non-routine findings raised while tasks are worked
+// and turns them into follow-up repair tasks.
+type FindingService struct {
+	Findings ports.FindingRepository
+	Tasks    ports.TaskRepository
+	// Packages places repair tasks in the work package of the task the
+	// finding was raised on. Optional.
+	Packages ports.WorkPackageRepository
+	TaskSvc  *TaskService
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
+}
+
+type FindingRaiseInput struct {
+	OrgID       *uuid.UUID
+	TaskID      uuid.UUID
+	Description string
+	Zone        string
+	ATAChapter  string
+	PhotoURLs   []string
+}
+
+type FindingConvertInput struct {
+	StartTime          time.Time
+	EndTime            time.Time
+	Priority           domain.TaskPriority
+	BayID              *uuid.UUID
+	AssignedMechanicID *uuid.UUID
+	Notes              string
+}
+
+func (s *FindingService) Raise(ctx context.Context, actor app.Actor, input FindingRaiseInput) (domain.Finding, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canCarryOutWork(actor) {
+		return domain.Finding{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, input.TaskID)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	if err := domain.CanRaiseFinding(task); err != nil {
+		return domain.Finding{}, err
+	}
+	return s.raise(ctx, actor, task, nil, input)
+}
+
+// RaiseFromCompliance raises a finding for a failed compliance item, once
+// per item. Findings are not raised on cancelled tasks.
+func (s *FindingService) RaiseFromCompliance(ctx context.Context, actor app.Actor, item domain.ComplianceItem) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	task, err := s.Tasks.GetByID(ctx, item.OrgID, item.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.State == domain.TaskStateCancelled {
+		return nil
+	}
+	_, err = s.raise(ctx, actor, task, &item.ID, FindingRaiseInput{
+		Description: "Failed compliance item: " + item.Description,
+	})
+	if errors.Is(err, domain.ErrConflict) {
+		// The item failed before and its finding already exists.
+		return nil
+	}
+	return err
+}
+
+func (s *FindingService) raise(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, complianceItemID *uuid.UUID, input FindingRaiseInput) (domain.Finding, error) {
+	now := s.Clock.Now().UTC()
+	photos := make([]string, 0, len(input.PhotoURLs))
+	for _, photo := range input.PhotoURLs {
+		photos = append(photos, strings.TrimSpace(photo))
+	}
+	finding := domain.Finding{
+		ID:               uuid.New(),
+		OrgID:            task.OrgID,
+		TaskID:           task.ID,
+		AircraftID:       task.AircraftID,
+		ComplianceItemID: complianceItemID,
+		Description:      strings.TrimSpace(input.Description),
+		Zone:             strings.TrimSpace(input.Zone),
+		ATAChapter:       strings.TrimSpace(input.ATAChapter),
+		PhotoURLs:        photos,
+		State:            domain.FindingOpen,
+		RaisedBy:         actor.UserID,
+		RaisedAt:         now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := finding.Validate(); err != nil {
+		return domain.Finding{}, err
+	}
+	created, err := s.Findings.Create(ctx, finding)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	details := map[string]any{
+		"task_id":     created.TaskID,
+		"zone":        created.Zone,
+		"ata_chapter": created.ATAChapter,
+		"photos":      len(created.PhotoURLs),
+	}
+	if complianceItemID != nil {
+		details["compliance_item_id"] = *complianceItemID
+	}
+	s.audit(ctx, actor, created, domain.AuditActionCreate, details)
+	s.emitOutbox(ctx, created, "finding_raised")
+	return created, nil
+}
+
+func (s *FindingService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Finding, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Finding{}, domain.ErrForbidden
+	}
+	return s.Findings.GetByID(ctx, orgID, id)
+}
+
+func (s *FindingService) List(ctx context.Context, actor app.Actor, filter ports.FindingFilter) ([]domain.Finding, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Findings.List(ctx, filter)
+}
+
+// Convert turns an open finding into a repair task on the same aircraft
+// that starts once the task the finding was raised on has finished. The
+// repair task joins that task's work package when it fits the visit. The
+// repair task, its dependency and the finding are stored together or not at
+// all.
+func (s *FindingService) Convert(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input FindingConvertInput) (domain.Finding, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Finding{}, domain.ErrForbidden
+	}
+	finding, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	if err := finding.CanConvert(); err != nil {
+		return domain.Finding{}, err
+	}
+	parent, err := s.Tasks.GetByID(ctx, finding.OrgID, finding.TaskID)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	dependency := domain.TaskDependency{
+		ID:              uuid.New(),
+		OrgID:           finding.OrgID,
+		DependsOnTaskID: parent.ID,
+		DependencyType:  domain.DependencyFinishToStart,
+	}
+	if dependency.Shift(parent, domain.MaintenanceTask{StartTime: input.StartTime, EndTime: input.EndTime}) > 0 {
+		return domain.Finding{}, domain.NewValidationError("repair task must start after the task the finding was raised on ends")
+	}
+
+	notes := strings.TrimSpace(input.Notes)
+	if notes == "" {
+		notes = repairNotes(finding)
+	}
+	var packageID *uuid.UUID
+	if parent.WorkPackageID != nil && s.Packages != nil {
+		pkg, err := s.Packages.GetByID(ctx, finding.OrgID, *parent.WorkPackageID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return domain.Finding{}, err
+		}
+		if err == nil && pkg.IsOpen() && pkg.Covers(domain.MaintenanceTask{StartTime: input.StartTime, EndTime: input.EndTime}) {
+			packageID = &pkg.ID
+		}
+	}
+	repair, err := s.TaskSvc.prepare(ctx, actor, TaskCreateInput{
+		OrgID:              &finding.OrgID,
+		AircraftID:         finding.AircraftID,
+		WorkPackageID:      packageID,
+		BayID:              input.BayID,
+		Type:               domain.TaskTypeRepair,
+		Priority:           input.Priority,
+		StartTime:          input.StartTime,
+		EndTime:            input.EndTime,
+		AssignedMechanicID: input.AssignedMechanicID,
+		Notes:              notes,
+	})
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	now := s.Clock.Now().UTC()
+	dependency.TaskID = repair.ID
+	dependency.CreatedAt = now
+
+	finding.State = domain.FindingConverted
+	finding.RepairTaskID = &repair.ID
+	finding.ConvertedBy = &actor.UserID
+	finding.ConvertedAt = &now
+	finding.UpdatedAt = now
+	converted, err := s.Findings.Convert(ctx, finding, repair, dependency)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	s.TaskSvc.created(ctx, actor, repair)
+	s.audit(ctx, actor, converted, domain.AuditActionStateChange, map[string]any{
+		"from":           domain.FindingOpen,
+		"to":             converted.State,
+		"repair_task_id": repair.ID,
+		"work_package":   packageID,
+	})
+	s.emitOutbox(ctx, converted, "finding_converted")
+	return converted, nil
+}
+
+// Close closes an open finding that needs no repair, with notes saying why.
+func (s *FindingService) Close(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, notes string) (domain.Finding, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.Finding{}, domain.ErrForbidden
+	}
+	finding, err := s.load(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	if err := finding.CanClose(notes); err != nil {
+		return domain.Finding{}, err
+	}
+	now := s.Clock.Now().UTC()
+	finding.State = domain.FindingClosed
+	finding.ClosedBy = &actor.UserID
+	finding.ClosedAt = &now
+	finding.ResolutionNotes = strings.TrimSpace(notes)
+	finding.UpdatedAt = now
+	closed, err := s.Findings.Update(ctx, finding, domain.FindingOpen)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	s.audit(ctx, actor, closed, domain.AuditActionStateChange, map[string]any{
+		"from":  domain.FindingOpen,
+		"to":    closed.State,
+		"notes": closed.ResolutionNotes,
+	})
+	s.emitOutbox(ctx, closed, "finding_closed")
+	return closed, nil
+}
+
+func (s *FindingService) load(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.Finding, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.Finding{}, domain.ErrForbidden
+	}
+	return s.Findings.GetByID(ctx, orgID, id)
+}
+
+func repairNotes(finding domain.Finding) string {
+	notes := "Repair finding: " + finding.Description
+	var location []string
+	if finding.Zone != "" {
+		location = append(location, "zone "+finding.Zone)
+	}
+	if finding.ATAChapter != "" {
+		location = append(location, "ATA "+finding.ATAChapter)
+	}
+	if len(location) > 0 {
+		notes += " (" + strings.Join(location, ", ") + ")"
+	}
+	return notes
+}
+
+func (s *FindingService) audit(ctx context.Context, actor app.Actor, finding domain.Finding, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      finding.OrgID,
+		EntityType: "finding",
+		EntityID:   finding.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *FindingService) emitOutbox(ctx context.Context, finding domain.Finding, eventType string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":     1,
+		"org_id":      finding.OrgID,
+		"finding_id":  finding.ID,
+		"task_id":     finding.TaskID,
+		"aircraft_id": finding.AircraftID,
+		"state":       finding.State,
+		"timestamp":   s.Clock.Now(),
+	}
+	if finding.RepairTaskID != nil {
+		payload["repair_task_id"] = *finding.RepairTaskID
+	}
+	_ = s.Outbox.Enqueue(ctx, finding.OrgID, eventType, "finding", finding.ID, payload, fmt.Sprintf("%s:%s:%s", eventType, finding.OrgID, finding.ID))
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// flightPageSize bounds each page of flights read at once.
+const flightPageSize = 200
+
+// FlightScheduleService keeps the flights each aircraft is planned to fly
+// and works out when it is on the ground between them, so that maintenance
+// can be fitted into ground time.
+type FlightScheduleService struct {
+	Flights  ports.PlannedFlightRepository
+	Aircraft ports.AircraftRepository
+	// Stations resolves a station's code for its ground windows. Optional;
+	// without it ground windows cannot be queried per station.
+	Stations ports.StationRepository
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
+}
+
+// FlightRecordInput describes a planned flight. Recording a flight the
+// aircraft already has, by flight number and departure time, updates it;
+// a status of cancelled takes it out of the plan.
+type FlightRecordInput struct {
+	OrgID         *uuid.UUID
+	AircraftID    uuid.UUID
+	FlightNumber  string
+	Origin        string
+	Destination   string
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+	Status        domain.FlightStatus
+}
+
+func (s *FlightScheduleService) Record(ctx context.Context, actor app.Actor, input FlightRecordInput) (domain.PlannedFlight, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.PlannedFlight{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now().UTC()
+	flight := domain.PlannedFlight{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		AircraftID:    input.AircraftID,
+		FlightNumber:  strings.ToUpper(strings.TrimSpace(input.FlightNumber)),
+		Origin:        strings.ToUpper(strings.TrimSpace(input.Origin)),
+		Destination:   strings.ToUpper(strings.TrimSpace(input.Destination)),
+		DepartureTime: input.DepartureTime.UTC(),
+		ArrivalTime:   input.ArrivalTime.UTC(),
+		Status:        input.Status,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if flight.Status == "" {
+		flight.Status = domain.FlightScheduled
+	}
+	if err := flight.Validate(); err != nil {
+		return domain.PlannedFlight{}, err
+	}
+	if _, err := s.Aircraft.GetByID(ctx, orgID, flight.AircraftID); err != nil {
+		return domain.PlannedFlight{}, err
+	}
+	stored, err := s.Flights.Upsert(ctx, flight)
+	if err != nil {
+		return domain.PlannedFlight{}, err
+	}
+
+	action := domain.AuditActionCreate
+	if stored.ID != flight.ID {
+		action = domain.AuditActionUpdate
+	}
+	s.audit(ctx, actor, stored, action, map[string]any{
+		"aircraft_id":    stored.AircraftID,
+		"flight_number":  stored.FlightNumber,
+		"departure_time": stored.DepartureTime,
+		"status":         string(stored.Status),
+	})
+	if s.Outbox != nil {
+		_ = s.Outbox.Enqueue(ctx, stored.OrgID, "planned_flight_recorded", "aircraft", stored.AircraftID, map[string]any{
+			"version":        1,
+			"org_id":         stored.OrgID,
+			"aircraft_id":    stored.AircraftID,
+			"flight_id":      stored.ID,
+			"flight_number":  stored.FlightNumber,
+			"origin":         stored.Origin,
+			"destination":    stored.Destination,
+			"departure_time": stored.DepartureTime,
+			"arrival_time":   stored.ArrivalTime,
+			"status":         string(stored.Status),
+			"timestamp":      now,
+		}, fmt.Sprintf("planned_flight_recorded:%s:%d", stored.ID, stored.UpdatedAt.UnixNano()))
+	}
+	return stored, nil
+}
+
+func (s *FlightScheduleService) Delete(ctx context.Context, actor app.Actor, orgID, aircraftID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	flight, err := s.Flights.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if flight.AircraftID != aircraftID {
+		return domain.ErrNotFound
+	}
+	if err := s.Flights.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, flight, domain.AuditActionDelete, map[string]any{
+		"aircraft_id":   flight.AircraftID,
+		"flight_number": flight.FlightNumber,
+		"deleted":       true,
+	})
+	return nil
+}
+
+func (s *FlightScheduleService) List(ctx context.Context, actor app.Actor, filter ports.PlannedFlightFilter) ([]domain.PlannedFlight, error) {
+	if !actor.IsAdmin() || filter.OrgID == nil {
+		filter.OrgID = &actor.OrgID
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	return s.Flights.List(ctx, filter)
+}
+
+// GroundWindows returns the aircraft's time on the ground between from and
+// to, keeping windows of at least minDuration.
+func (s *FlightScheduleService) GroundWindows(ctx context.Context, actor app.Actor, orgID, aircraftID uuid.UUID, from, to time.Time, minDuration time.Duration) ([]domain.GroundWindow, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	from, to, err := groundRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Aircraft.GetByID(ctx, orgID, aircraftID); err != nil {
+		return nil, err
+	}
+	windows, err := s.AircraftGroundWindows(ctx, orgID, []uuid.UUID{aircraftID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return longEnough(windows[aircraftID], minDuration), nil
+}
+
+// StationGroundWindows returns the windows in which aircraft are on the
+// ground at the station between from and to, ordered by start. Aircraft
+// without a planned flight telling where they are do not appear.
+func (s *FlightScheduleService) StationGroundWindows(ctx context.Context, actor app.Actor, orgID, stationID uuid.UUID, from, to time.Time, minDuration time.Duration) ([]domain.GroundWindow, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if s.Stations == nil {
+		return nil, domain.NewValidationError("stations unavailable")
+	}
+	from, to, err := groundRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	station, err := s.Stations.GetByID(ctx, orgID, stationID)
+	if err != nil {
+		return nil, err
+	}
+	byAircraft, err := s.AircraftGroundWindows(ctx, orgID, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var windows []domain.GroundWindow
+	for _, aircraftWindows := range byAircraft {
+		for _, window := range aircraftWindows {
+			if window.Station != "" && window.AtStation(station.Code) {
+				windows = append(windows, window)
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].Start.Equal(windows[j].Start) {
+			return windows[i].Start.Before(windows[j].Start)
+		}
+		return windows[i].AircraftID.String() < windows[j].AircraftID.String()
+	})
+	return longEnough(windows, minDuration), nil
+}
+
+// AircraftGroundWindows works out the ground windows in [from, to) of each
+// of the aircraft. Nil aircraftIDs covers every aircraft with a planned
+// flight. An aircraft without flights is on the ground for the whole range
+// at a station its last flight tells, if any.
+func (s *FlightScheduleService) AircraftGroundWindows(ctx context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID][]domain.GroundWindow, error) {
+	flights, err := s.FlightsDuring(ctx, orgID, aircraftIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byAircraft := make(map[uuid.UUID][]domain.PlannedFlight)
+	for _, id := range aircraftIDs {
+		byAircraft[id] = nil
+	}
+	for _, flight := range flights {
+		byAircraft[flight.AircraftID] = append(byAircraft[flight.AircraftID], flight)
+	}
+
+	previous := make(map[uuid.UUID]domain.PlannedFlight)
+	batches := idBatches(aircraftIDs)
+	if aircraftIDs == nil {
+		batches = [][]uuid.UUID{nil}
+	}
+	for _, batch := range batches {
+		page, err := s.Flights.ListPrevious(ctx, orgID, batch, from)
+		if err != nil {
+			return nil, err
+		}
+		for _, flight := range page {
+			previous[flight.AircraftID] = flight
+			if _, ok := byAircraft[flight.AircraftID]; !ok {
+				byAircraft[flight.AircraftID] = nil
+			}
+		}
+	}
+
+	windows := make(map[uuid.UUID][]domain.GroundWindow, len(byAircraft))
+	for aircraftID, aircraftFlights := range byAircraft {
+		var last *domain.PlannedFlight
+		if flight, ok := previous[aircraftID]; ok {
+			last = &flight
+		}
+		windows[aircraftID] = domain.GroundWindows(aircraftID, last, aircraftFlights, from, to)
+	}
+	return windows, nil
+}
+
+// FlightsDuring returns the scheduled flights of the aircraft that are in
+// the air at some time in [from, to), ordered by departure. Nil aircraftIDs
+// covers every aircraft.
+func (s *FlightScheduleService) FlightsDuring(ctx context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, from, to time.Time) ([]domain.PlannedFlight, error) {
+	status := domain.FlightScheduled
+	batches := idBatches(aircraftIDs)
+	if aircraftIDs == nil {
+		batches = [][]uuid.UUID{nil}
+	}
+	var flights []domain.PlannedFlight
+	for _, batch := range batches {
+		filter := ports.PlannedFlightFilter{
+			OrgID:       &orgID,
+			AircraftIDs: batch,
+			Status:      &status,
+			From:        &from,
+			To:          &to,
+			Limit:       flightPageSize,
+		}
+		for offset := 0; ; offset += flightPageSize {
+			filter.Offset = offset
+			page, err := s.Flights.List(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+			flights = append(flights, page...)
+			if len(page) < flightPageSize {
+				break
+			}
+		}
+	}
+	return flights, nil
+}
+
+// CheckTask returns a flight_overlap conflict when the task's aircraft is
+// planned to fly during the task's window.
+func (s *FlightScheduleService) CheckTask(ctx context.Context, task domain.MaintenanceTask) error {
+	if !task.IsActive() {
+		return nil
+	}
+	flights, err := s.FlightsDuring(ctx, task.OrgID, []uuid.UUID{task.AircraftID}, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	if len(flights) == 0 {
+		return nil
+	}
+	flight := flights[0]
+	return domain.NewCapacityConflict(domain.CapacityConflictFlightOverlap, fmt.Sprintf("aircraft is planned to fly %s from %s at %s to %s at %s", flight.FlightNumber, flight.Origin, flight.DepartureTime.Format(time.RFC3339), flight.Destination, flight.ArrivalTime.Format(time.RFC3339)))
+}
+
+func (s *FlightScheduleService) audit(ctx context.Context, actor app.Actor, flight domain.PlannedFlight, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      flight.OrgID,
+		EntityType: "planned_flight",
+		EntityID:   flight.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+// groundRange checks a ground window query range.
+func groundRange(from, to time.Time) (time.Time, time.Time, error) {
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return from, to, domain.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > maxAvailabilitySpan {
+		return from, to, domain.NewValidationError("range must not exceed 92 days")
+	}
+	return from, to, nil
+}
+
+func longEnough(windows []domain.GroundWindow, minDuration time.Duration) []domain.GroundWindow {
+	out := make([]domain.GroundWindow, 0, len(windows))
+	for _, window := range windows {
+		if window.Duration() >= minDuration {
+			out = append(out, window)
+		}
+	}
+	return out
+}
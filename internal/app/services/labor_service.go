@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// LaborService records the hours mechanics actually work on tasks. The task
+// repository logs them as recency experience on the aircraft's type when the
+// task is completed.
+type LaborService struct {
+	Labor    ports.LaborEntryRepository
+	Tasks    ports.TaskRepository
+	Aircraft ports.AircraftRepository
+	// Crew supplies the planned hours of the task's crew, used in place of
+	// the task's window when summarizing. Optional.
+	Crew   ports.TaskAssignmentRepository
+	Audit  ports.AuditRepository
+	Outbox ports.OutboxRepository
+	Clock  app.Clock
+}
+
+type LaborClockInInput struct {
+	OrgID  *uuid.UUID
+	TaskID uuid.UUID
+	// MechanicID defaults to the actor. Only schedulers and admins may clock
+	// in someone else.
+	MechanicID *uuid.UUID
+	Notes      string
+}
+
+type LaborManualInput struct {
+	OrgID      *uuid.UUID
+	TaskID     uuid.UUID
+	MechanicID *uuid.UUID
+	StartedAt  time.Time
+	EndedAt    time.Time
+	Notes      string
+}
+
+// ClockIn opens a labor entry for the mechanic on an in-progress task.
+func (s *LaborService) ClockIn(ctx context.Context, actor app.Actor, input LaborClockInInput) (domain.LaborEntry, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	now := s.Clock.Now().UTC()
+	return s.record(ctx, actor, input.OrgID, input.TaskID, input.MechanicID, domain.LaborSourceClock, now, nil, input.Notes)
+}
+
+// RecordManual books worked time after the fact. The entry must end by now.
+func (s *LaborService) RecordManual(ctx context.Context, actor app.Actor, input LaborManualInput) (domain.LaborEntry, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if input.EndedAt.After(s.Clock.Now()) {
+		return domain.LaborEntry{}, domain.NewValidationError("ended_at cannot be in the future")
+	}
+	endedAt := input.EndedAt.UTC()
+	return s.record(ctx, actor, input.OrgID, input.TaskID, input.MechanicID, domain.LaborSourceManual, input.StartedAt.UTC(), &endedAt, input.Notes)
+}
+
+func (s *LaborService) record(ctx context.Context, actor app.Actor, inputOrgID *uuid.UUID, taskID uuid.UUID, mechanicID *uuid.UUID, source domain.LaborSource, start time.Time, end *time.Time, notes string) (domain.LaborEntry, error) {
+	if actor.Role != domain.RoleMechanic && !canManageCapacity(actor) {
+		return domain.LaborEntry{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && inputOrgID != nil && *inputOrgID != uuid.Nil {
+		orgID = *inputOrgID
+	}
+	mechanic := actor.UserID
+	if mechanicID != nil && *mechanicID != uuid.Nil {
+		mechanic = *mechanicID
+	}
+	if actor.Role == domain.RoleMechanic && mechanic != actor.UserID {
+		return domain.LaborEntry{}, domain.ErrForbidden
+	}
+	task, err := s.taskInProgress(ctx, orgID, taskID)
+	if err != nil {
+		return domain.LaborEntry{}, err
+	}
+
+	now := s.Clock.Now().UTC()
+	entry := domain.LaborEntry{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		TaskID:     task.ID,
+		MechanicID: mechanic,
+		Source:     source,
+		StartedAt:  start,
+		EndedAt:    end,
+		Notes:      strings.TrimSpace(notes),
+		CreatedBy:  actor.UserID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := entry.Validate(); err != nil {
+		return domain.LaborEntry{}, err
+	}
+	if err := s.checkOverlap(ctx, entry); err != nil {
+		return domain.LaborEntry{}, err
+	}
+
+	created, err := s.Labor.Create(ctx, entry)
+	if err != nil {
+		return domain.LaborEntry{}, err
+	}
+	details := map[string]any{
+		"task_id":     created.TaskID,
+		"mechanic_id": created.MechanicID,
+		"source":      created.Source,
+		"started_at":  created.StartedAt,
+	}
+	if created.EndedAt != nil {
+		details["ended_at"] = *created.EndedAt
+		details["hours"] = created.Hours()
+	}
+	s.audit(ctx, actor, created.OrgID, created.ID, domain.AuditActionCreate, details)
+	eventType := "labor_recorded"
+	if created.Open() {
+		eventType = "labor_clocked_in"
+	}
+	s.emitOutbox(ctx, created, eventType)
+	return created, nil
+}
+
+// ClockOut closes the mechanic's open entry. Mechanics may only clock
+// themselves out.
+func (s *LaborService) ClockOut(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.LaborEntry, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	entry, err := s.loadOwnEntry(ctx, actor, orgID, id)
+	if err != nil {
+		return domain.LaborEntry{}, err
+	}
+	if !entry.Open() {
+		return domain.LaborEntry{}, domain.NewConflictError("labor entry already clocked out")
+	}
+	now := s.Clock.Now().UTC()
+	if !now.After(entry.StartedAt) {
+		return domain.LaborEntry{}, domain.NewValidationError("clock out must be after clock in")
+	}
+
+	closed, err := s.Labor.ClockOut(ctx, orgID, id, now)
+	if err != nil {
+		return domain.LaborEntry{}, err
+	}
+	s.audit(ctx, actor, closed.OrgID, closed.ID, domain.AuditActionUpdate, map[string]any{
+		"task_id":     closed.TaskID,
+		"mechanic_id": closed.MechanicID,
+		"ended_at":    now,
+		"hours":       closed.Hours(),
+	})
+	s.emitOutbox(ctx, closed, "labor_clocked_out")
+	return closed, nil
+}
+
+// Delete removes a mistaken entry while its task is still in progress.
+func (s *LaborService) Delete(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	entry, err := s.loadOwnEntry(ctx, actor, orgID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.taskInProgress(ctx, orgID, entry.TaskID); err != nil {
+		return err
+	}
+	if err := s.Labor.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, id, domain.AuditActionDelete, map[string]any{
+		"task_id":     entry.TaskID,
+		"mechanic_id": entry.MechanicID,
+	})
+	return nil
+}
+
+func (s *LaborService) ListByTask(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID) ([]domain.LaborEntry, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return nil, domain.ErrForbidden
+	}
+	if _, err := s.Tasks.GetByID(ctx, orgID, taskID); err != nil {
+		return nil, err
+	}
+	return s.Labor.ListByTask(ctx, orgID, taskID)
+}
+
+// Summary compares the task's planned hours with the labor booked to it.
+func (s *LaborService) Summary(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID) (domain.TaskLaborSummary, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.TaskLaborSummary{}, domain.ErrForbidden
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, taskID)
+	if err != nil {
+		return domain.TaskLaborSummary{}, err
+	}
+	entries, err := s.Labor.ListByTask(ctx, orgID, taskID)
+	if err != nil {
+		return domain.TaskLaborSummary{}, err
+	}
+	var crew domain.Crew
+	if s.Crew != nil {
+		crew, err = s.Crew.ListByTask(ctx, orgID, taskID)
+		if err != nil {
+			return domain.TaskLaborSummary{}, err
+		}
+	}
+	return domain.SummarizeLabor(task, crew, entries), nil
+}
+
+// ClockedOut reports whether no mechanic is still clocked in on the task.
+func (s *LaborService) ClockedOut(ctx context.Context, task domain.MaintenanceTask) (bool, error) {
+	entries, err := s.Labor.ListByTask(ctx, task.OrgID, task.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Open() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// loadOwnEntry loads an entry the actor may change: any entry for
+// schedulers and admins, their own for mechanics.
+func (s *LaborService) loadOwnEntry(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.LaborEntry, error) {
+	if actor.Role != domain.RoleMechanic && !canManageCapacity(actor) {
+		return domain.LaborEntry{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.LaborEntry{}, domain.ErrForbidden
+	}
+	entry, err := s.Labor.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.LaborEntry{}, err
+	}
+	if actor.Role == domain.RoleMechanic && entry.MechanicID != actor.UserID {
+		return domain.LaborEntry{}, domain.ErrForbidden
+	}
+	return entry, nil
+}
+
+func (s *LaborService) taskInProgress(ctx context.Context, orgID, taskID uuid.UUID) (domain.MaintenanceTask, error) {
+	task, err := s.Tasks.GetByID(ctx, orgID, taskID)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if task.State != domain.TaskStateInProgress {
+		return domain.MaintenanceTask{}, domain.NewConflictError("task must be in progress")
+	}
+	return task, nil
+}
+
+// checkOverlap rejects an entry intersecting the mechanic's other labor,
+// on any task. The database enforces the same rule for concurrent writes.
+func (s *LaborService) checkOverlap(ctx context.Context, entry domain.LaborEntry) error {
+	existing, err := s.Labor.ListOverlapping(ctx, entry.OrgID, entry.MechanicID, entry.StartedAt, entry.EndedAt)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if !other.Overlaps(entry.StartedAt, entry.EndedAt) {
+			continue
+		}
+		if other.Open() {
+			return domain.NewCapacityConflict(domain.CapacityConflictLaborOverlap, "mechanic is already clocked in")
+		}
+		return domain.NewCapacityConflict(domain.CapacityConflictLaborOverlap, fmt.Sprintf(
+			"mechanic already has labor booked from %s to %s",
+			other.StartedAt.UTC().Format(time.RFC3339), other.EndedAt.UTC().Format(time.RFC3339)))
+	}
+	return nil
+}
+
+func (s *LaborService) audit(ctx context.Context, actor app.Actor, orgID, entryID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: "labor_entry",
+		EntityID:   entryID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *LaborService) emitOutbox(ctx context.Context, entry domain.LaborEntry, eventType string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":     1,
+		"org_id":      entry.OrgID,
+		"task_id":     entry.TaskID,
+		"entry_id":    entry.ID,
+		"mechanic_id": entry.MechanicID,
+		"started_at":  entry.StartedAt,
+		"timestamp":   s.Clock.Now(),
+	}
+	if entry.EndedAt != nil {
+		payload["ended_at"] = *entry.EndedAt
+		payload["hours"] = entry.Hours()
+	}
+	_ = s.Outbox.Enqueue(ctx, entry.OrgID, eventType, "labor_entry", entry.ID, payload, fmt.Sprintf("%s:%s:%s", eventType, entry.OrgID, entry.ID))
+}
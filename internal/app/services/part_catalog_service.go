@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app"
@@ -16,7 +17,17 @@ type PartCatalogService struct {
 	Clock       app.Clock
 }
 
-func (s *PartCatalogService) CreateDefinition(ctx context.Context, actor app.Actor, orgID uuid.UUID, name, category string) (domain.PartDefinition, error) {
+// PartDefinitionInput carries the fields of a part definition. On update a
+// nil LeadTimeDays or AlternateIDs leaves the field unchanged; an empty
+// AlternateIDs clears it.
+type PartDefinitionInput struct {
+	Name         string
+	Category     string
+	LeadTimeDays *int
+	AlternateIDs []uuid.UUID
+}
+
+func (s *PartCatalogService) CreateDefinition(ctx context.Context, actor app.Actor, orgID uuid.UUID, input PartDefinitionInput) (domain.PartDefinition, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
@@ -29,13 +40,19 @@ func (s *PartCatalogService) CreateDefinition(ctx context.Context, actor app.Act
 	}
 
 	def := domain.PartDefinition{
-		ID:        uuid.New(),
-		OrgID:     resolvedOrg,
-		Name:      name,
-		Category:  category,
-		CreatedAt: s.Clock.Now(),
-		UpdatedAt: s.Clock.Now(),
+		ID:           uuid.New(),
+		OrgID:        resolvedOrg,
+		Name:         input.Name,
+		Category:     input.Category,
+		LeadTimeDays: input.LeadTimeDays,
+		CreatedAt:    s.Clock.Now(),
+		UpdatedAt:    s.Clock.Now(),
+	}
+	alternates, err := s.alternates(ctx, def, input.AlternateIDs)
+	if err != nil {
+		return domain.PartDefinition{}, err
 	}
+	def.AlternateIDs = alternates
 
 	created, err := s.Definitions.Create(ctx, def)
 	if err != nil {
@@ -51,7 +68,7 @@ func (s *PartCatalogService) ListDefinitions(ctx context.Context, actor app.Acto
 	return s.Definitions.List(ctx, filter)
 }
 
-func (s *PartCatalogService) UpdateDefinition(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, name, category string) (domain.PartDefinition, error) {
+func (s *PartCatalogService) UpdateDefinition(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input PartDefinitionInput) (domain.PartDefinition, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
@@ -65,8 +82,18 @@ func (s *PartCatalogService) UpdateDefinition(ctx context.Context, actor app.Act
 	if err != nil {
 		return domain.PartDefinition{}, err
 	}
-	def.Name = name
-	def.Category = category
+	def.Name = input.Name
+	def.Category = input.Category
+	if input.LeadTimeDays != nil {
+		def.LeadTimeDays = input.LeadTimeDays
+	}
+	if input.AlternateIDs != nil {
+		alternates, err := s.alternates(ctx, def, input.AlternateIDs)
+		if err != nil {
+			return domain.PartDefinition{}, err
+		}
+		def.AlternateIDs = alternates
+	}
 	def.UpdatedAt = s.Clock.Now()
 
 	updated, err := s.Definitions.Update(ctx, def)
@@ -76,6 +103,30 @@ func (s *PartCatalogService) UpdateDefinition(ctx context.Context, actor app.Act
 	return updated, nil
 }
 
+// alternates checks that each alternate is another definition of the same
+// organization and drops duplicates.
+func (s *PartCatalogService) alternates(ctx context.Context, def domain.PartDefinition, ids []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == def.ID {
+			return nil, domain.NewValidationError("a part definition cannot be its own alternate")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.Definitions.GetByID(ctx, def.OrgID, id); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, domain.NewValidationError("alternate part definition not found")
+			}
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
 func (s *PartCatalogService) DeleteDefinition(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
@@ -89,7 +140,40 @@ func (s *PartCatalogService) DeleteDefinition(ctx context.Context, actor app.Act
 	return s.Definitions.SoftDelete(ctx, orgID, id, s.Clock.Now())
 }
 
-func (s *PartCatalogService) CreateItem(ctx context.Context, actor app.Actor, orgID uuid.UUID, defID uuid.UUID, serial string, status domain.PartItemStatus, expiry *time.Time) (domain.PartItem, error) {
+// PartItemLifeInput carries the usage and life-limit fields of a serialized
+// part. Nil fields are left unchanged on update. Parts are fitted to aircraft
+// through ComponentService so every change is logged.
+type PartItemLifeInput struct {
+	LifeLimitHours      *float64
+	LifeLimitCycles     *int
+	HoursSinceNew       *float64
+	CyclesSinceNew      *int
+	HoursSinceOverhaul  *float64
+	CyclesSinceOverhaul *int
+}
+
+func (in PartItemLifeInput) apply(item *domain.PartItem) {
+	if in.LifeLimitHours != nil {
+		item.LifeLimitHours = in.LifeLimitHours
+	}
+	if in.LifeLimitCycles != nil {
+		item.LifeLimitCycles = in.LifeLimitCycles
+	}
+	if in.HoursSinceNew != nil {
+		item.HoursSinceNew = roundHours(*in.HoursSinceNew)
+	}
+	if in.CyclesSinceNew != nil {
+		item.CyclesSinceNew = *in.CyclesSinceNew
+	}
+	if in.HoursSinceOverhaul != nil {
+		item.HoursSinceOverhaul = roundHours(*in.HoursSinceOverhaul)
+	}
+	if in.CyclesSinceOverhaul != nil {
+		item.CyclesSinceOverhaul = *in.CyclesSinceOverhaul
+	}
+}
+
+func (s *PartCatalogService) CreateItem(ctx context.Context, actor app.Actor, orgID uuid.UUID, defID uuid.UUID, serial string, status domain.PartItemStatus, expiry *time.Time, life PartItemLifeInput) (domain.PartItem, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
@@ -114,6 +198,10 @@ func (s *PartCatalogService) CreateItem(ctx context.Context, actor app.Actor, or
 		CreatedAt:    s.Clock.Now(),
 		UpdatedAt:    s.Clock.Now(),
 	}
+	life.apply(&item)
+	if err := item.ValidateLife(); err != nil {
+		return domain.PartItem{}, err
+	}
 
 	created, err := s.Items.Create(ctx, item)
 	if err != nil {
@@ -129,7 +217,7 @@ func (s *PartCatalogService) ListItems(ctx context.Context, actor app.Actor, fil
 	return s.Items.List(ctx, filter)
 }
 
-func (s *PartCatalogService) UpdateItem(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, status *domain.PartItemStatus, expiry *time.Time) (domain.PartItem, error) {
+func (s *PartCatalogService) UpdateItem(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, status *domain.PartItemStatus, expiry *time.Time, life PartItemLifeInput) (domain.PartItem, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
@@ -149,6 +237,10 @@ func (s *PartCatalogService) UpdateItem(ctx context.Context, actor app.Actor, or
 	if expiry != nil {
 		item.ExpiryDate = expiry
 	}
+	life.apply(&item)
+	if err := item.ValidateLife(); err != nil {
+		return domain.PartItem{}, err
+	}
 	item.UpdatedAt = s.Clock.Now()
 
 	updated, err := s.Items.Update(ctx, item)
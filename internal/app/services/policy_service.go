@@ -51,6 +51,9 @@ func (s *OrgPolicyService) Upsert(ctx context.Context, actor app.Actor, policy d
 	if policy.APIKeyRateLimitPerMin <= 0 {
 		policy.APIKeyRateLimitPerMin = 10
 	}
+	if policy.MaxAttachmentBytes <= 0 {
+		policy.MaxAttachmentBytes = domain.DefaultMaxAttachmentBytes
+	}
 	policy.UpdatedAt = s.Clock.Now()
 	if policy.CreatedAt.IsZero() {
 		policy.CreatedAt = policy.UpdatedAt
@@ -69,6 +72,7 @@ func defaultOrgPolicy(orgID uuid.UUID, now time.Time) domain.OrgPolicy {
 		WebhookReplayWindowSeconds: 300,
 		APIRateLimitPerMin:         100,
 		APIKeyRateLimitPerMin:      10,
+		MaxAttachmentBytes:         domain.DefaultMaxAttachmentBytes,
 		CreatedAt:                  now,
 		UpdatedAt:                  now,
 	}
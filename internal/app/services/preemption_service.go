@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// PreemptionService lets a new AOG or critical task take the hangar bay
+// slot and mechanic of lower-priority scheduled work. Tasks in the way in
+// the bay move behind the new task together with their dependents; tasks
+// holding its mechanic give the mechanic up. Each displaced task gets a
+// schedule change event giving the reason, and the mechanics affected are
+// notified.
+type PreemptionService struct {
+	ScheduleEvents ports.ScheduleChangeRepository
+	// Scheduling moves the dependents of a displaced task along with it.
+	Scheduling *SchedulingService
+	// Capacity finds the tasks holding the bay and places the moved tasks
+	// into slots. Optional; without it bays are not preempted.
+	Capacity *CapacityService
+	// Roster re-checks the mechanic once the displaced tasks let go.
+	// Optional.
+	Roster *RosterService
+	Audit  ports.AuditRepository
+	Outbox ports.OutboxRepository
+	Clock  app.Clock
+}
+
+// PreemptedResource is what a displaced task gives up.
+type PreemptedResource string
+
+const (
+	PreemptedBay      PreemptedResource = "bay"
+	PreemptedMechanic PreemptedResource = "mechanic"
+)
+
+// PreemptionDisplacement is a lower-priority task preemption displaces. A
+// task leaving the bay moves behind the preempting task; a task giving up
+// its mechanic keeps its window.
+type PreemptionDisplacement struct {
+	TaskID       uuid.UUID
+	Priority     domain.TaskPriority
+	Resource     PreemptedResource
+	ChangeType   domain.ScheduleChangeType
+	Reason       string
+	OldStartTime time.Time
+	OldEndTime   time.Time
+	NewStartTime time.Time
+	NewEndTime   time.Time
+	// Dependents are moved along with a task leaving the bay.
+	Dependents []uuid.UUID
+	// MechanicIDs are the mechanics of the task and its moved dependents,
+	// who are notified of the change.
+	MechanicIDs []uuid.UUID
+}
+
+// PreemptionPlan is what preempting for a task displaces. Capacity
+// violations are the conflicts preemption cannot clear, such as work in
+// progress or of equal priority holding the bay or the mechanic.
+type PreemptionPlan struct {
+	Task               domain.MaintenanceTask
+	Displacements      []PreemptionDisplacement
+	CapacityViolations []CapacityViolation
+	change             ports.PreemptionChange
+	// before holds the displaced tasks and moved dependents as stored.
+	before map[uuid.UUID]domain.MaintenanceTask
+}
+
+// Plan works out what creating task with preemption would displace and
+// which conflicts would remain, without writing anything.
+func (s *PreemptionService) Plan(ctx context.Context, actor app.Actor, task domain.MaintenanceTask) (PreemptionPlan, error) {
+	plan, err := s.plan(ctx, actor, task)
+	if err != nil {
+		return PreemptionPlan{}, err
+	}
+	if s.Capacity != nil {
+		violations, err := s.Capacity.CheckMoved(ctx, plan.placed())
+		if err != nil {
+			return PreemptionPlan{}, err
+		}
+		plan.CapacityViolations = append(plan.CapacityViolations, violations...)
+	}
+	if s.Roster != nil {
+		err := s.Roster.CheckAssignmentWithout(ctx, task, plan.freed())
+		var conflict *domain.CapacityConflictError
+		if err != nil && !errors.As(err, &conflict) {
+			return PreemptionPlan{}, err
+		}
+		if conflict != nil {
+			plan.CapacityViolations = append(plan.CapacityViolations, CapacityViolation{TaskID: task.ID, Kind: conflict.Kind, Message: conflict.Message})
+		}
+	}
+	return plan, nil
+}
+
+// Preempt creates task with its compliance checklist, displacing the
+// lower-priority work in its way. The task, its checklist, the displaced
+// tasks and their schedule change events are written together or not at
+// all; a conflict that preemption cannot clear fails the whole request.
+func (s *PreemptionService) Preempt(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, checklist []domain.ComplianceItem) (domain.MaintenanceTask, PreemptionPlan, error) {
+	plan, err := s.plan(ctx, actor, task)
+	if err != nil {
+		return domain.MaintenanceTask{}, PreemptionPlan{}, err
+	}
+	if s.Capacity != nil {
+		placed := plan.placed()
+		if err := s.Capacity.ReserveMoved(ctx, placed); err != nil {
+			return domain.MaintenanceTask{}, PreemptionPlan{}, err
+		}
+		copy(plan.change.Moved, placed)
+		task = placed[len(placed)-1]
+	} else if task.BayID != nil {
+		return domain.MaintenanceTask{}, PreemptionPlan{}, domain.NewValidationError("hangar capacity unavailable")
+	}
+	if s.Roster != nil {
+		if err := s.Roster.CheckAssignmentWithout(ctx, task, plan.freed()); err != nil {
+			return domain.MaintenanceTask{}, PreemptionPlan{}, err
+		}
+	}
+
+	now := s.Clock.Now().UTC()
+	plan.change.Task = task
+	plan.change.Compliance = checklist
+	created, err := s.ScheduleEvents.Preempt(ctx, plan.change, now)
+	if err != nil {
+		return domain.MaintenanceTask{}, PreemptionPlan{}, err
+	}
+	plan.Task = created
+	for i, displacement := range plan.Displacements {
+		event := plan.change.Events[i]
+		if s.Audit != nil {
+			_ = s.Audit.Insert(ctx, domain.AuditLog{
+				ID:         uuid.New(),
+				OrgID:      created.OrgID,
+				EntityType: "maintenance_task",
+				EntityID:   displacement.TaskID,
+				Action:     domain.AuditActionUpdate,
+				UserID:     actor.UserID,
+				RequestID:  uuid.Nil,
+				Timestamp:  now,
+				Details: map[string]any{
+					"preempted_by":       created.ID,
+					"resource":           string(displacement.Resource),
+					"schedule_change_id": event.ID,
+				},
+			})
+		}
+		if s.Outbox != nil {
+			_ = s.Outbox.Enqueue(ctx, created.OrgID, "task_preempted", "maintenance_task", displacement.TaskID, map[string]any{
+				"version":            1,
+				"org_id":             created.OrgID,
+				"task_id":            displacement.TaskID,
+				"preempted_by":       created.ID,
+				"priority":           created.Priority,
+				"resource":           displacement.Resource,
+				"change_type":        displacement.ChangeType,
+				"reason":             displacement.Reason,
+				"schedule_change_id": event.ID,
+				"mechanic_ids":       displacement.MechanicIDs,
+				"new_start_time":     displacement.NewStartTime,
+				"new_end_time":       displacement.NewEndTime,
+				"affected_tasks":     displacement.Dependents,
+				"timestamp":          now,
+			}, fmt.Sprintf("task_preempted:%s", event.ID))
+		}
+	}
+	return created, plan, nil
+}
+
+// plan picks the tasks to displace: first the bay's, until a slot is free
+// for the task, then the mechanic's.
+func (s *PreemptionService) plan(ctx context.Context, actor app.Actor, task domain.MaintenanceTask) (PreemptionPlan, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return PreemptionPlan{}, domain.ErrForbidden
+	}
+	if !task.Priority.Preempts(domain.PriorityUrgent) {
+		return PreemptionPlan{}, domain.NewValidationError("only aog and critical tasks can preempt other work")
+	}
+	plan := PreemptionPlan{
+		Task:               task,
+		Displacements:      []PreemptionDisplacement{},
+		CapacityViolations: []CapacityViolation{},
+		before:             make(map[uuid.UUID]domain.MaintenanceTask),
+	}
+	now := s.Clock.Now().UTC()
+	if task.BayID != nil && s.Capacity != nil {
+		if err := s.displaceFromBay(ctx, actor, &plan, now); err != nil {
+			return PreemptionPlan{}, err
+		}
+	}
+	if task.AssignedMechanicID != nil {
+		if err := s.releaseMechanic(ctx, actor, &plan, now); err != nil {
+			return PreemptionPlan{}, err
+		}
+	}
+	return plan, nil
+}
+
+// displaceFromBay moves lower-priority scheduled tasks out of the task's
+// bay until a slot is free for its whole window. The least urgent work
+// gives way first and, among equals, the work starting last, which has the
+// least distance to move.
+func (s *PreemptionService) displaceFromBay(ctx context.Context, actor app.Actor, plan *PreemptionPlan, now time.Time) error {
+	task := plan.Task
+	bay, err := s.Capacity.Bays.GetByID(ctx, task.OrgID, *task.BayID)
+	if err != nil {
+		return err
+	}
+	windows, err := s.Capacity.Windows.ListOverlapping(ctx, task.OrgID, bay.ID, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	stored, err := s.Capacity.activeTasks(ctx, ports.TaskFilter{OrgID: &task.OrgID, BayID: &bay.ID}, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	capacity := bay.CapacityDuring(windows, task.StartTime, task.EndTime)
+
+	var candidates []domain.MaintenanceTask
+	for _, other := range stored {
+		if other.ID != task.ID && other.State == domain.TaskStateScheduled && task.Priority.Preempts(other.Priority) {
+			candidates = append(candidates, other)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Priority.Rank() != b.Priority.Rank() {
+			return a.Priority.Rank() < b.Priority.Rank()
+		}
+		return a.StartTime.After(b.StartTime)
+	})
+
+	for {
+		booked := make([]domain.MaintenanceTask, 0, len(stored))
+		for _, other := range stored {
+			if _, moved := plan.before[other.ID]; !moved && other.ID != task.ID && other.OccupiesBay(bay.ID) {
+				booked = append(booked, other)
+			}
+		}
+		if _, ok := domain.FreeBaySlot(capacity, booked, task.BaySlot); ok || len(candidates) == 0 {
+			return nil
+		}
+		victim := candidates[0]
+		candidates = candidates[1:]
+		if _, moved := plan.before[victim.ID]; moved {
+			continue
+		}
+		err := s.moveBehind(ctx, actor, plan, victim, bay, now)
+		if errors.Is(err, domain.ErrConflict) {
+			// Its dependents cannot move; try the next candidate.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// moveBehind moves victim to start when the preempting task ends, cascading
+// to its dependents.
+func (s *PreemptionService) moveBehind(ctx context.Context, actor app.Actor, plan *PreemptionPlan, victim domain.MaintenanceTask, bay domain.HangarBay, now time.Time) error {
+	task := plan.Task
+	shift := task.EndTime.Sub(victim.StartTime)
+	moved := victim
+	moved.StartTime = moved.StartTime.Add(shift)
+	moved.EndTime = moved.EndTime.Add(shift)
+
+	cascaded := make(map[uuid.UUID]domain.MaintenanceTask)
+	dependents, err := s.Scheduling.cascadeReschedule(ctx, victim.OrgID, moved, cascaded)
+	if err != nil {
+		return err
+	}
+	for id := range cascaded {
+		if _, ok := plan.before[id]; ok {
+			return domain.NewConflictError(fmt.Sprintf("task %s is already displaced", id))
+		}
+	}
+	plan.before[victim.ID] = victim
+	for id, dependent := range cascaded {
+		plan.before[id] = dependent
+	}
+	plan.change.Moved = append(plan.change.Moved, moved)
+	plan.change.Moved = append(plan.change.Moved, dependents...)
+
+	dependentIDs := make([]uuid.UUID, 0, len(dependents))
+	for _, dependent := range dependents {
+		dependentIDs = append(dependentIDs, dependent.ID)
+	}
+	oldStart, oldEnd := victim.StartTime, victim.EndTime
+	newStart, newEnd := moved.StartTime, moved.EndTime
+	reason := fmt.Sprintf("preempted by %s task %s needing bay %s", task.Priority, task.ID, bay.Code)
+	plan.change.Events = append(plan.change.Events, domain.ScheduleChangeEvent{
+		ID:              uuid.New(),
+		OrgID:           victim.OrgID,
+		TaskID:          victim.ID,
+		ChangeType:      domain.ScheduleChangeRescheduled,
+		Reason:          reason,
+		OldStartTime:    &oldStart,
+		NewStartTime:    &newStart,
+		OldEndTime:      &oldEnd,
+		NewEndTime:      &newEnd,
+		TriggeredBy:     &actor.UserID,
+		AffectedTaskIDs: dependentIDs,
+		CreatedAt:       now,
+	})
+	plan.Displacements = append(plan.Displacements, PreemptionDisplacement{
+		TaskID:       victim.ID,
+		Priority:     victim.Priority,
+		Resource:     PreemptedBay,
+		ChangeType:   domain.ScheduleChangeRescheduled,
+		Reason:       reason,
+		OldStartTime: oldStart,
+		OldEndTime:   oldEnd,
+		NewStartTime: newStart,
+		NewEndTime:   newEnd,
+		Dependents:   dependentIDs,
+		MechanicIDs:  assignedMechanics(append([]domain.MaintenanceTask{moved}, dependents...)),
+	})
+	return nil
+}
+
+// releaseMechanic takes the task's mechanic off the lower-priority
+// scheduled tasks they work during its window. Tasks already moved out of
+// the bay are left alone.
+func (s *PreemptionService) releaseMechanic(ctx context.Context, actor app.Actor, plan *PreemptionPlan, now time.Time) error {
+	task := plan.Task
+	mechanicID := *task.AssignedMechanicID
+	busy, err := s.Scheduling.allTasks(ctx, ports.TaskFilter{
+		OrgID:              &task.OrgID,
+		AssignedMechanicID: &mechanicID,
+		ActiveOnly:         true,
+		OverlapFrom:        &task.StartTime,
+		OverlapTo:          &task.EndTime,
+	})
+	if err != nil {
+		return err
+	}
+	for _, other := range busy {
+		if _, moved := plan.before[other.ID]; moved || other.ID == task.ID {
+			continue
+		}
+		if other.State != domain.TaskStateScheduled || !task.Priority.Preempts(other.Priority) {
+			continue
+		}
+		plan.before[other.ID] = other
+		plan.change.Released = append(plan.change.Released, other)
+		reason := fmt.Sprintf("preempted by %s task %s needing mechanic %s", task.Priority, task.ID, mechanicID)
+		plan.change.Events = append(plan.change.Events, domain.ScheduleChangeEvent{
+			ID:          uuid.New(),
+			OrgID:       other.OrgID,
+			TaskID:      other.ID,
+			ChangeType:  domain.ScheduleChangeMechanicReassigned,
+			Reason:      reason,
+			TriggeredBy: &actor.UserID,
+			CreatedAt:   now,
+		})
+		plan.Displacements = append(plan.Displacements, PreemptionDisplacement{
+			TaskID:       other.ID,
+			Priority:     other.Priority,
+			Resource:     PreemptedMechanic,
+			ChangeType:   domain.ScheduleChangeMechanicReassigned,
+			Reason:       reason,
+			OldStartTime: other.StartTime,
+			OldEndTime:   other.EndTime,
+			NewStartTime: other.StartTime,
+			NewEndTime:   other.EndTime,
+			MechanicIDs:  []uuid.UUID{mechanicID},
+		})
+	}
+	return nil
+}
+
+// placed lists the tasks to book into bay slots: the moved tasks at their
+// new windows, then the preempting task.
+func (p PreemptionPlan) placed() []domain.MaintenanceTask {
+	placed := make([]domain.MaintenanceTask, 0, len(p.change.Moved)+1)
+	placed = append(placed, p.change.Moved...)
+	return append(placed, p.Task)
+}
+
+// freed returns the displaced tasks that no longer hold the mechanic during
+// the preempting task's window.
+func (p PreemptionPlan) freed() map[uuid.UUID]bool {
+	freed := make(map[uuid.UUID]bool, len(p.change.Released)+len(p.change.Moved))
+	for _, task := range p.change.Released {
+		freed[task.ID] = true
+	}
+	for _, task := range p.change.Moved {
+		if !task.Overlaps(p.Task.StartTime, p.Task.EndTime) {
+			freed[task.ID] = true
+		}
+	}
+	return freed
+}
+
+// assignedMechanics lists the distinct mechanics assigned to tasks.
+func assignedMechanics(tasks []domain.MaintenanceTask) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(tasks))
+	mechanics := make([]uuid.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		if task.AssignedMechanicID != nil && !seen[*task.AssignedMechanicID] {
+			seen[*task.AssignedMechanicID] = true
+			mechanics = append(mechanics, *task.AssignedMechanicID)
+		}
+	}
+	return mechanics
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app"
@@ -12,26 +14,72 @@ import (
 
 type MaintenanceProgramService struct {
 	Programs ports.MaintenanceProgramRepository
-	Tasks    ports.TaskRepository
-	TaskSvc  *TaskService
-	Clock    app.Clock
+	Aircraft ports.AircraftRepository
+	// Utilization supplies average daily usage for forecasting usage based
+	// programs. When nil, only calendar programs are forecast.
+	Utilization ports.AircraftUtilizationRepository
+	Tasks       ports.TaskRepository
+	TaskSvc     *TaskService
+	// Flights annotates forecast items with the aircraft's ground time in
+	// their window. Optional.
+	Flights   *FlightScheduleService
+	LookAhead domain.ProgramLookAhead
+	Clock     app.Clock
 }
 
 type ProgramCreateInput struct {
-	OrgID         *uuid.UUID
-	AircraftID    *uuid.UUID
-	Name          string
-	IntervalType  domain.MaintenanceProgramIntervalType
-	IntervalValue int
-	LastPerformed *time.Time
+	OrgID               *uuid.UUID
+	AircraftID          *uuid.UUID
+	Name                string
+	IntervalType        domain.MaintenanceProgramIntervalType
+	IntervalValue       int
+	LastPerformed       *time.Time
+	LastPerformedHours  *int
+	LastPerformedCycles *int
+	TolerancePercent    int
+	Thresholds          []domain.ProgramThreshold
+	// TaskType and Priority default to inspection and routine when empty.
+	TaskType                 domain.TaskType
+	Priority                 domain.TaskPriority
+	EstimatedDurationMinutes *int
+	ComplianceChecklist      []string
+	DefaultNotes             string
 }
 
 type ProgramUpdateInput struct {
-	AircraftID    *uuid.UUID
-	Name          *string
-	IntervalType  *domain.MaintenanceProgramIntervalType
-	IntervalValue *int
-	LastPerformed *time.Time
+	AircraftID          *uuid.UUID
+	Name                *string
+	IntervalType        *domain.MaintenanceProgramIntervalType
+	IntervalValue       *int
+	LastPerformed       *time.Time
+	LastPerformedHours  *int
+	LastPerformedCycles *int
+	TolerancePercent    *int
+	// Thresholds replaces the additional thresholds when non-nil; an empty
+	// slice clears them.
+	Thresholds               *[]domain.ProgramThreshold
+	TaskType                 *domain.TaskType
+	Priority                 *domain.TaskPriority
+	EstimatedDurationMinutes *int
+	// ComplianceChecklist replaces the checklist when non-nil; an empty
+	// slice clears it.
+	ComplianceChecklist *[]string
+	DefaultNotes        *string
+}
+
+const (
+	defaultForecastHorizonDays = 180
+	maxForecastHorizonDays     = 730
+	defaultForecastOccurrences = 3
+	maxForecastOccurrences     = 20
+	forecastUtilizationDays    = 90
+)
+
+type ProgramForecastInput struct {
+	OrgID       *uuid.UUID
+	AircraftID  *uuid.UUID
+	HorizonDays int
+	Occurrences int
 }
 
 func (s *MaintenanceProgramService) Create(ctx context.Context, actor app.Actor, input ProgramCreateInput) (domain.MaintenanceProgram, error) {
@@ -44,21 +92,41 @@ func (s *MaintenanceProgramService) Create(ctx context.Context, actor app.Actor,
 	if input.Name == "" || input.IntervalType == "" || input.IntervalValue <= 0 {
 		return domain.MaintenanceProgram{}, domain.NewValidationError("name, interval_type, and interval_value are required")
 	}
+	if err := validateLastPerformedUsage(input.LastPerformedHours, input.LastPerformedCycles); err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
 	orgID := actor.OrgID
 	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
 		orgID = *input.OrgID
 	}
 	program := domain.MaintenanceProgram{
-		ID:            uuid.New(),
-		OrgID:         orgID,
-		AircraftID:    input.AircraftID,
-		Name:          input.Name,
-		IntervalType:  input.IntervalType,
-		IntervalValue: input.IntervalValue,
-		LastPerformed: input.LastPerformed,
-		CreatedAt:     s.Clock.Now(),
-		UpdatedAt:     s.Clock.Now(),
+		ID:                       uuid.New(),
+		OrgID:                    orgID,
+		AircraftID:               input.AircraftID,
+		Name:                     input.Name,
+		IntervalType:             input.IntervalType,
+		IntervalValue:            input.IntervalValue,
+		LastPerformed:            input.LastPerformed,
+		LastPerformedHours:       input.LastPerformedHours,
+		LastPerformedCycles:      input.LastPerformedCycles,
+		TolerancePercent:         input.TolerancePercent,
+		Thresholds:               input.Thresholds,
+		TaskType:                 input.TaskType,
+		Priority:                 input.Priority,
+		EstimatedDurationMinutes: input.EstimatedDurationMinutes,
+		ComplianceChecklist:      input.ComplianceChecklist,
+		DefaultNotes:             input.DefaultNotes,
+		CreatedAt:                s.Clock.Now(),
+		UpdatedAt:                s.Clock.Now(),
+	}
+	if err := program.ValidateThresholds(); err != nil {
+		return domain.MaintenanceProgram{}, err
 	}
+	if err := program.ValidateTaskDefaults(); err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	program.TaskType = program.GeneratedTaskType()
+	program.Priority = program.GeneratedTaskPriority()
 	return s.Programs.Create(ctx, program)
 }
 
@@ -117,6 +185,42 @@ func (s *MaintenanceProgramService) Update(ctx context.Context, actor app.Actor,
 	if input.LastPerformed != nil {
 		program.LastPerformed = input.LastPerformed
 	}
+	if err := validateLastPerformedUsage(input.LastPerformedHours, input.LastPerformedCycles); err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	if input.LastPerformedHours != nil {
+		program.LastPerformedHours = input.LastPerformedHours
+	}
+	if input.LastPerformedCycles != nil {
+		program.LastPerformedCycles = input.LastPerformedCycles
+	}
+	if input.TolerancePercent != nil {
+		program.TolerancePercent = *input.TolerancePercent
+	}
+	if input.Thresholds != nil {
+		program.Thresholds = *input.Thresholds
+	}
+	if input.TaskType != nil {
+		program.TaskType = *input.TaskType
+	}
+	if input.Priority != nil {
+		program.Priority = *input.Priority
+	}
+	if input.EstimatedDurationMinutes != nil {
+		program.EstimatedDurationMinutes = input.EstimatedDurationMinutes
+	}
+	if input.ComplianceChecklist != nil {
+		program.ComplianceChecklist = *input.ComplianceChecklist
+	}
+	if input.DefaultNotes != nil {
+		program.DefaultNotes = *input.DefaultNotes
+	}
+	if err := program.ValidateThresholds(); err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	if err := program.ValidateTaskDefaults(); err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
 	program.UpdatedAt = s.Clock.Now()
 	return s.Programs.Update(ctx, program)
 }
@@ -134,21 +238,41 @@ func (s *MaintenanceProgramService) Delete(ctx context.Context, actor app.Actor,
 	return s.Programs.SoftDelete(ctx, orgID, id, s.Clock.Now())
 }
 
-func (s *MaintenanceProgramService) GenerateDueTasks(ctx context.Context, actor app.Actor, limit int) (int, error) {
+// ProgramGenerationResult reports a GenerateDueTasks run. Failed lists the
+// due programs whose task could not be created; they are retried on the next
+// run.
+type ProgramGenerationResult struct {
+	Created int
+	Failed  []ProgramGenerationFailure
+}
+
+type ProgramGenerationFailure struct {
+	ProgramID  uuid.UUID
+	AircraftID uuid.UUID
+	Err        error
+}
+
+// GenerateDueTasks creates a task for every due program without an active
+// one. Each task is placed after the aircraft's open tasks, including those
+// generated earlier in the run, so several due programs on one aircraft do not
+// collide.
+func (s *MaintenanceProgramService) GenerateDueTasks(ctx context.Context, actor app.Actor, limit int) (ProgramGenerationResult, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
 	if actor.Role != domain.RoleAdmin && actor.Role != domain.RoleScheduler {
-		return 0, domain.ErrForbidden
+		return ProgramGenerationResult{}, domain.ErrForbidden
 	}
 	if s.TaskSvc == nil {
-		return 0, domain.NewValidationError("task service unavailable")
+		return ProgramGenerationResult{}, domain.NewValidationError("task service unavailable")
 	}
-	programs, err := s.Programs.ListDueCalendar(ctx, s.Clock.Now(), limit)
+	now := s.Clock.Now()
+	programs, err := s.Programs.ListDue(ctx, now, s.LookAhead, limit)
 	if err != nil {
-		return 0, err
+		return ProgramGenerationResult{}, err
 	}
-	created := 0
+	result := ProgramGenerationResult{}
+	busy := make(map[uuid.UUID][]domain.MaintenanceTask)
 	for _, program := range programs {
 		if program.AircraftID == nil {
 			continue
@@ -157,37 +281,298 @@ func (s *MaintenanceProgramService) GenerateDueTasks(ctx context.Context, actor
 		if s.Tasks != nil {
 			active, err := s.Tasks.HasActiveForProgram(ctx, program.OrgID, program.ID)
 			if err != nil {
-				return created, err
+				return result, err
 			}
 			hasActive = active
 		}
 		if hasActive {
 			continue
 		}
-		due := s.Clock.Now()
-		if program.LastPerformed != nil {
-			due = program.LastPerformed.Add(time.Duration(program.IntervalValue) * 24 * time.Hour)
-		} else {
-			due = s.Clock.Now().Add(time.Duration(program.IntervalValue) * 24 * time.Hour)
-		}
-		if due.Before(s.Clock.Now()) {
-			due = s.Clock.Now().Add(1 * time.Hour)
-		}
-		end := due.Add(2 * time.Hour)
-		_, err := s.TaskSvc.Create(ctx, actor, TaskCreateInput{
-			OrgID:              &program.OrgID,
-			AircraftID:         *program.AircraftID,
-			ProgramID:          &program.ID,
-			Type:               domain.TaskTypeInspection,
-			StartTime:          due,
-			EndTime:            end,
-			AssignedMechanicID: nil,
-			Notes:              "Generated from maintenance program",
+		start, end, ok, err := s.nextDueWindow(ctx, program, now)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			continue
+		}
+		aircraftID := *program.AircraftID
+		booked, loaded := busy[aircraftID]
+		if !loaded {
+			booked, err = s.openAircraftTasks(ctx, program.OrgID, aircraftID, now)
+			if err != nil {
+				return result, err
+			}
+		}
+		start, end = domain.NextFreeSlot(booked, start, end.Sub(start))
+		task, err := s.TaskSvc.Create(ctx, actor, programTaskInput(program, start, end))
+		if err != nil {
+			result.Failed = append(result.Failed, ProgramGenerationFailure{ProgramID: program.ID, AircraftID: aircraftID, Err: err})
+			busy[aircraftID] = booked
+			continue
+		}
+		busy[aircraftID] = append(booked, task)
+		result.Created++
+	}
+	return result, nil
+}
+
+// openAircraftTasks lists the aircraft's scheduled and in-progress tasks that
+// end after now.
+func (s *MaintenanceProgramService) openAircraftTasks(ctx context.Context, orgID, aircraftID uuid.UUID, now time.Time) ([]domain.MaintenanceTask, error) {
+	if s.Tasks == nil {
+		return nil, nil
+	}
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += 200 {
+		page, err := s.Tasks.List(ctx, ports.TaskFilter{
+			OrgID:       &orgID,
+			AircraftID:  &aircraftID,
+			ActiveOnly:  true,
+			OverlapFrom: &now,
+			Limit:       200,
+			Offset:      offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < 200 {
+			return tasks, nil
+		}
+	}
+}
+
+// Forecast projects the next due occurrences of every aircraft-bound program
+// within the horizon, ordered by due time. Usage based programs are converted
+// to dates using the aircraft's average daily utilization over the last 90
+// days. Nothing is persisted.
+func (s *MaintenanceProgramService) Forecast(ctx context.Context, actor app.Actor, input ProgramForecastInput) ([]domain.ProgramForecast, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleAuditor && actor.Role != domain.RoleTenantAdmin {
+		return nil, domain.ErrForbidden
+	}
+	if s.Aircraft == nil {
+		return nil, domain.NewValidationError("aircraft repository unavailable")
+	}
+	horizon := input.HorizonDays
+	if horizon <= 0 {
+		horizon = defaultForecastHorizonDays
+	}
+	if horizon > maxForecastHorizonDays {
+		return nil, domain.NewValidationError("horizon_days must not exceed 730")
+	}
+	occurrences := input.Occurrences
+	if occurrences <= 0 {
+		occurrences = defaultForecastOccurrences
+	}
+	if occurrences > maxForecastOccurrences {
+		return nil, domain.NewValidationError("occurrences must not exceed 20")
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now()
+	until := now.AddDate(0, 0, horizon)
+
+	var programs []domain.MaintenanceProgram
+	for offset := 0; ; offset += 200 {
+		page, err := s.Programs.List(ctx, ports.MaintenanceProgramFilter{
+			OrgID:      &orgID,
+			AircraftID: input.AircraftID,
+			Limit:      200,
+			Offset:     offset,
 		})
 		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, page...)
+		if len(page) < 200 {
+			break
+		}
+	}
+
+	aircraftByID := make(map[uuid.UUID]domain.Aircraft)
+	rates := make(map[uuid.UUID]domain.UtilizationRate)
+	durations := make(map[uuid.UUID]time.Duration)
+	var forecast []domain.ProgramForecast
+	for _, program := range programs {
+		if program.AircraftID == nil {
 			continue
 		}
-		created++
+		durations[program.ID] = program.TaskDuration()
+		aircraft, ok := aircraftByID[*program.AircraftID]
+		if !ok {
+			var err error
+			aircraft, err = s.Aircraft.GetByID(ctx, orgID, *program.AircraftID)
+			if err != nil {
+				if errors.Is(err, domain.ErrNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			aircraftByID[aircraft.ID] = aircraft
+		}
+		rate, ok := rates[aircraft.ID]
+		if !ok && program.IsUsageBased() && s.Utilization != nil {
+			var err error
+			rate, err = s.Utilization.AverageDaily(ctx, orgID, aircraft.ID, now.AddDate(0, 0, -forecastUtilizationDays), now)
+			if err != nil {
+				return nil, err
+			}
+			rates[aircraft.ID] = rate
+		}
+		forecast = append(forecast, program.Forecast(aircraft, rate, now, until, occurrences)...)
+	}
+	if err := s.annotateGroundTime(ctx, orgID, forecast, durations, now); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(forecast, func(i, j int) bool {
+		if !forecast[i].DueAt.Equal(forecast[j].DueAt) {
+			return forecast[i].DueAt.Before(forecast[j].DueAt)
+		}
+		if forecast[i].TailNumber != forecast[j].TailNumber {
+			return forecast[i].TailNumber < forecast[j].TailNumber
+		}
+		return forecast[i].ProgramName < forecast[j].ProgramName
+	})
+	return forecast, nil
+}
+
+// annotateGroundTime sets each item's ground time from the flight schedule.
+// Windows that have already opened are counted from now. Flights are read
+// once per aircraft over the span of its items' windows.
+func (s *MaintenanceProgramService) annotateGroundTime(ctx context.Context, orgID uuid.UUID, forecast []domain.ProgramForecast, durations map[uuid.UUID]time.Duration, now time.Time) error {
+	if s.Flights == nil || len(forecast) == 0 {
+		return nil
+	}
+	type span struct{ from, to time.Time }
+	spans := make(map[uuid.UUID]span)
+	windowOf := func(item domain.ProgramForecast) (time.Time, time.Time) {
+		start, end := item.WindowStart, item.WindowEnd
+		if start.Before(now) {
+			start = now
+		}
+		if !end.After(start) {
+			// Without tolerance the task still needs time around the due point.
+			end = start.Add(durations[item.ProgramID])
+		}
+		return start, end
+	}
+	for _, item := range forecast {
+		start, end := windowOf(item)
+		current, ok := spans[item.AircraftID]
+		if !ok {
+			current = span{from: start, to: end}
+		}
+		if start.Before(current.from) {
+			current.from = start
+		}
+		if end.After(current.to) {
+			current.to = end
+		}
+		spans[item.AircraftID] = current
+	}
+	windows := make(map[uuid.UUID][]domain.GroundWindow, len(spans))
+	for aircraftID, current := range spans {
+		byAircraft, err := s.Flights.AircraftGroundWindows(ctx, orgID, []uuid.UUID{aircraftID}, current.from, current.to)
+		if err != nil {
+			return err
+		}
+		windows[aircraftID] = byAircraft[aircraftID]
+	}
+	for i := range forecast {
+		item := &forecast[i]
+		start, end := windowOf(*item)
+		var total time.Duration
+		for _, window := range domain.ClipGroundWindows(windows[item.AircraftID], start, end) {
+			total += window.Duration()
+			if item.GroundWindow == nil && window.Duration() >= durations[item.ProgramID] {
+				found := window
+				item.GroundWindow = &found
+			}
+		}
+		minutes := int(total / time.Minute)
+		item.GroundMinutes = &minutes
+	}
+	return nil
+}
+
+// nextDueWindow returns the start and end time for a generated task. The
+// earliest threshold drives the due point and the window spans its tolerance,
+// with usage thresholds projected from the aircraft's average daily
+// utilization over the last 90 days. Windows that have already opened start
+// in an hour. Tasks last the program's estimated duration when it has one and
+// otherwise span the tolerance window, or two hours without tolerance. ok is
+// false when the program is not due after all.
+func (s *MaintenanceProgramService) nextDueWindow(ctx context.Context, program domain.MaintenanceProgram, now time.Time) (time.Time, time.Time, bool, error) {
+	var aircraft domain.Aircraft
+	var rate domain.UtilizationRate
+	if program.IsUsageBased() && s.Aircraft != nil {
+		var err error
+		aircraft, err = s.Aircraft.GetByID(ctx, program.OrgID, *program.AircraftID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return time.Time{}, time.Time{}, false, nil
+			}
+			return time.Time{}, time.Time{}, false, err
+		}
+		if !program.IsDue(aircraft, now, s.LookAhead) {
+			return time.Time{}, time.Time{}, false, nil
+		}
+		if s.Utilization != nil {
+			rate, err = s.Utilization.AverageDaily(ctx, program.OrgID, aircraft.ID, now.AddDate(0, 0, -forecastUtilizationDays), now)
+			if err != nil {
+				return time.Time{}, time.Time{}, false, err
+			}
+		}
+	}
+
+	start := now.Add(1 * time.Hour)
+	end := start.Add(program.TaskDuration())
+	due, ok := program.NextDue(aircraft, rate, now)
+	if !ok {
+		// Usage thresholds within the look-ahead but without a utilization
+		// rate to project from are scheduled immediately.
+		return start, end, true, nil
+	}
+	if due.WindowStart.After(start) {
+		start = due.WindowStart
+	}
+	end = due.WindowEnd
+	if program.EstimatedDurationMinutes != nil || !end.After(start) {
+		end = start.Add(program.TaskDuration())
+	}
+	return start, end, true, nil
+}
+
+// programTaskInput builds the task generated for a program occurrence,
+// carrying the program's task type, priority, notes and compliance checklist.
+// Due work is generated even over planned flights; conflict detection
+// reports the clash for the scheduler to resolve.
+func programTaskInput(program domain.MaintenanceProgram, start, end time.Time) TaskCreateInput {
+	return TaskCreateInput{
+		OrgID:               &program.OrgID,
+		AircraftID:          *program.AircraftID,
+		ProgramID:           &program.ID,
+		Type:                program.GeneratedTaskType(),
+		Priority:            program.GeneratedTaskPriority(),
+		StartTime:           start,
+		EndTime:             end,
+		Notes:               program.TaskNotes(),
+		ComplianceChecklist: program.ComplianceChecklist,
+		AllowFlightOverlap:  true,
+	}
+}
+
+func validateLastPerformedUsage(hours, cycles *int) error {
+	if hours != nil && *hours < 0 {
+		return domain.NewValidationError("last_performed_hours must not be negative")
+	}
+	if cycles != nil && *cycles < 0 {
+		return domain.NewValidationError("last_performed_cycles must not be negative")
 	}
-	return created, nil
+	return nil
 }
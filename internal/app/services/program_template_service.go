@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// ProgramTemplateService manages program templates keyed by aircraft type and
+// keeps their per-aircraft program instances in step with them.
+type ProgramTemplateService struct {
+	Templates ports.MaintenanceProgramTemplateRepository
+	Programs  ports.MaintenanceProgramRepository
+	Aircraft  ports.AircraftRepository
+	Audit     ports.AuditRepository
+	Clock     app.Clock
+}
+
+type ProgramTemplateCreateInput struct {
+	OrgID            *uuid.UUID
+	AircraftTypeID   uuid.UUID
+	Name             string
+	IntervalType     domain.MaintenanceProgramIntervalType
+	IntervalValue    int
+	TolerancePercent int
+	Thresholds       []domain.ProgramThreshold
+}
+
+type ProgramTemplateUpdateInput struct {
+	Name             *string
+	IntervalType     *domain.MaintenanceProgramIntervalType
+	IntervalValue    *int
+	TolerancePercent *int
+	Thresholds       *[]domain.ProgramThreshold
+}
+
+const programTemplatePageSize = 200
+
+// Create stores the template together with its instances on every aircraft of
+// the type in the organization, so a template never exists without them.
+func (s *ProgramTemplateService) Create(ctx context.Context, actor app.Actor, input ProgramTemplateCreateInput) (domain.MaintenanceProgramTemplate, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrForbidden
+	}
+	if input.AircraftTypeID == uuid.Nil || input.Name == "" || input.IntervalType == "" || input.IntervalValue <= 0 {
+		return domain.MaintenanceProgramTemplate{}, domain.NewValidationError("aircraft_type_id, name, interval_type, and interval_value are required")
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now()
+	template := domain.MaintenanceProgramTemplate{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		AircraftTypeID:   input.AircraftTypeID,
+		Name:             input.Name,
+		IntervalType:     input.IntervalType,
+		IntervalValue:    input.IntervalValue,
+		TolerancePercent: input.TolerancePercent,
+		Thresholds:       input.Thresholds,
+		Revision:         1,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := template.Definition().ValidateThresholds(); err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	fleet, err := s.fleet(ctx, orgID, template.AircraftTypeID)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	instances := make([]domain.MaintenanceProgram, 0, len(fleet))
+	for _, aircraft := range fleet {
+		instances = append(instances, template.Instantiate(aircraft.ID, now))
+	}
+	created, err := s.Templates.CreateWithInstances(ctx, template, instances)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	s.audit(ctx, actor, created, domain.AuditActionCreate, map[string]any{
+		"aircraft_type_id":  created.AircraftTypeID,
+		"name":              created.Name,
+		"instances_created": len(instances),
+	})
+	return created, nil
+}
+
+func (s *ProgramTemplateService) List(ctx context.Context, actor app.Actor, filter ports.MaintenanceProgramTemplateFilter) ([]domain.MaintenanceProgramTemplate, error) {
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleAuditor && actor.Role != domain.RoleTenantAdmin {
+		return nil, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Templates.List(ctx, filter)
+}
+
+func (s *ProgramTemplateService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.MaintenanceProgramTemplate, error) {
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleAuditor && actor.Role != domain.RoleTenantAdmin {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() && orgID != actor.OrgID {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrForbidden
+	}
+	return s.Templates.GetByID(ctx, orgID, id)
+}
+
+// Update revises the template definition. Existing instances are left as they
+// are until the revision is propagated.
+func (s *ProgramTemplateService) Update(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input ProgramTemplateUpdateInput) (domain.MaintenanceProgramTemplate, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	template, err := s.Templates.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	previous := template.Definition()
+	if input.Name != nil {
+		if *input.Name == "" {
+			return domain.MaintenanceProgramTemplate{}, domain.NewValidationError("name is required")
+		}
+		template.Name = *input.Name
+	}
+	if input.IntervalType != nil {
+		template.IntervalType = *input.IntervalType
+	}
+	if input.IntervalValue != nil {
+		template.IntervalValue = *input.IntervalValue
+	}
+	if input.TolerancePercent != nil {
+		template.TolerancePercent = *input.TolerancePercent
+	}
+	if input.Thresholds != nil {
+		template.Thresholds = *input.Thresholds
+	}
+	if err := template.Definition().ValidateThresholds(); err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	changes := template.Diff(previous)
+	if len(changes) == 0 {
+		return template, nil
+	}
+	template.Revision++
+	template.UpdatedAt = s.Clock.Now()
+	updated, err := s.Templates.Update(ctx, template)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	s.audit(ctx, actor, updated, domain.AuditActionUpdate, map[string]any{
+		"revision": updated.Revision,
+		"fields":   fields,
+	})
+	return updated, nil
+}
+
+// Delete removes the template. Its instances remain as regular programs.
+func (s *ProgramTemplateService) Delete(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Templates.SoftDelete(ctx, orgID, id, s.Clock.Now()); err != nil {
+		return err
+	}
+	if s.Audit != nil {
+		_ = s.Audit.Insert(ctx, domain.AuditLog{
+			ID:         uuid.New(),
+			OrgID:      orgID,
+			EntityType: "maintenance_program_template",
+			EntityID:   id,
+			Action:     domain.AuditActionDelete,
+			UserID:     actor.UserID,
+			RequestID:  uuid.Nil,
+			Timestamp:  s.Clock.Now(),
+		})
+	}
+	return nil
+}
+
+// PreviewPropagation lists the changes propagating the current template
+// revision would make, one entry per aircraft whose instance is missing or
+// differs from the template. Nothing is persisted.
+func (s *ProgramTemplateService) PreviewPropagation(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) ([]domain.ProgramTemplateChange, error) {
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleAuditor && actor.Role != domain.RoleTenantAdmin {
+		return nil, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	template, err := s.Templates.GetByID(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	changes, _, err := s.plan(ctx, template)
+	return changes, err
+}
+
+// Propagate applies the template to every aircraft of its type in one
+// transaction. revision must match the template's current revision, so a
+// planner only applies what they previewed; a conflict is returned if the
+// template changed in between.
+func (s *ProgramTemplateService) Propagate(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, revision int) ([]domain.ProgramTemplateChange, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin {
+		return nil, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	template, err := s.Templates.GetByID(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Revision != revision {
+		return nil, domain.NewConflictError("template revision has changed since the preview")
+	}
+	changes, instances, err := s.plan(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Clock.Now()
+	var created, updated []domain.MaintenanceProgram
+	for _, change := range changes {
+		switch change.Action {
+		case domain.ProgramTemplateChangeCreate:
+			created = append(created, template.Instantiate(change.AircraftID, now))
+		case domain.ProgramTemplateChangeUpdate:
+			program := template.ApplyTo(instances[change.AircraftID])
+			program.UpdatedAt = now
+			updated = append(updated, program)
+		}
+	}
+	if err := s.Templates.Propagate(ctx, template, created, updated); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, actor, template, domain.AuditActionUpdate, map[string]any{
+		"propagated_revision": template.Revision,
+		"instances_changed":   len(changes),
+	})
+	return changes, nil
+}
+
+// InstancesForAircraft builds the programs for every template of the
+// aircraft's type that does not have an instance on it yet. Callers store them
+// in the same transaction as the aircraft they create or move to the type.
+func (s *ProgramTemplateService) InstancesForAircraft(ctx context.Context, aircraft domain.Aircraft) ([]domain.MaintenanceProgram, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if aircraft.AircraftTypeID == nil || s.Templates == nil {
+		return nil, nil
+	}
+	now := s.Clock.Now()
+	var instances []domain.MaintenanceProgram
+	for offset := 0; ; offset += programTemplatePageSize {
+		templates, err := s.Templates.List(ctx, ports.MaintenanceProgramTemplateFilter{
+			OrgID:          &aircraft.OrgID,
+			AircraftTypeID: aircraft.AircraftTypeID,
+			Limit:          programTemplatePageSize,
+			Offset:         offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range templates {
+			exists, err := s.hasInstance(ctx, template, aircraft)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				instances = append(instances, template.Instantiate(aircraft.ID, now))
+			}
+		}
+		if len(templates) < programTemplatePageSize {
+			return instances, nil
+		}
+	}
+}
+
+func (s *ProgramTemplateService) hasInstance(ctx context.Context, template domain.MaintenanceProgramTemplate, aircraft domain.Aircraft) (bool, error) {
+	existing, err := s.Programs.List(ctx, ports.MaintenanceProgramFilter{
+		OrgID:      &template.OrgID,
+		AircraftID: &aircraft.ID,
+		TemplateID: &template.ID,
+		Limit:      1,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(existing) > 0, nil
+}
+
+// plan compares the template with its instances across the fleet. It returns
+// the changes ordered by tail number and the existing instances by aircraft.
+func (s *ProgramTemplateService) plan(ctx context.Context, template domain.MaintenanceProgramTemplate) ([]domain.ProgramTemplateChange, map[uuid.UUID]domain.MaintenanceProgram, error) {
+	fleet, err := s.fleet(ctx, template.OrgID, template.AircraftTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	instances := make(map[uuid.UUID]domain.MaintenanceProgram)
+	for offset := 0; ; offset += programTemplatePageSize {
+		page, err := s.Programs.List(ctx, ports.MaintenanceProgramFilter{
+			OrgID:      &template.OrgID,
+			TemplateID: &template.ID,
+			Limit:      programTemplatePageSize,
+			Offset:     offset,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, program := range page {
+			if program.AircraftID != nil {
+				instances[*program.AircraftID] = program
+			}
+		}
+		if len(page) < programTemplatePageSize {
+			break
+		}
+	}
+
+	var changes []domain.ProgramTemplateChange
+	for _, aircraft := range fleet {
+		program, ok := instances[aircraft.ID]
+		if !ok {
+			changes = append(changes, domain.ProgramTemplateChange{
+				AircraftID: aircraft.ID,
+				TailNumber: aircraft.TailNumber,
+				Action:     domain.ProgramTemplateChangeCreate,
+				ToRevision: template.Revision,
+				Changes:    template.Diff(domain.MaintenanceProgram{}),
+			})
+			continue
+		}
+		diff := template.Diff(program)
+		if len(diff) == 0 && program.TemplateRevision != nil && *program.TemplateRevision == template.Revision {
+			continue
+		}
+		programID := program.ID
+		changes = append(changes, domain.ProgramTemplateChange{
+			AircraftID:   aircraft.ID,
+			TailNumber:   aircraft.TailNumber,
+			ProgramID:    &programID,
+			Action:       domain.ProgramTemplateChangeUpdate,
+			FromRevision: program.TemplateRevision,
+			ToRevision:   template.Revision,
+			Changes:      diff,
+		})
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].TailNumber < changes[j].TailNumber
+	})
+	return changes, instances, nil
+}
+
+// fleet returns every aircraft of the type in the organization.
+func (s *ProgramTemplateService) fleet(ctx context.Context, orgID, aircraftTypeID uuid.UUID) ([]domain.Aircraft, error) {
+	if s.Aircraft == nil {
+		return nil, domain.NewValidationError("aircraft repository unavailable")
+	}
+	var fleet []domain.Aircraft
+	for offset := 0; ; offset += programTemplatePageSize {
+		page, err := s.Aircraft.List(ctx, ports.AircraftFilter{
+			OrgID:          &orgID,
+			AircraftTypeID: &aircraftTypeID,
+			Limit:          programTemplatePageSize,
+			Offset:         offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fleet = append(fleet, page...)
+		if len(page) < programTemplatePageSize {
+			return fleet, nil
+		}
+	}
+}
+
+func (s *ProgramTemplateService) audit(ctx context.Context, actor app.Actor, template domain.MaintenanceProgramTemplate, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      template.OrgID,
+		EntityType: "maintenance_program_template",
+		EntityID:   template.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
@@ -0,0 +1,772 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSwapCandidates bounds the later tasks considered for a swap.
+	maxSwapCandidates = 50
+	// minSplitPart is the shortest piece of work a split may leave on
+	// either side.
+	minSplitPart = time.Hour
+)
+
+// RescheduleOptionService works out ranked ways around a part a task cannot
+// get in time — delaying it, fitting a substitute, swapping it with a later
+// task or splitting it — and applies the one a planner picks as a schedule
+// change.
+type RescheduleOptionService struct {
+	Options         ports.RescheduleOptionRepository
+	Tasks           ports.TaskRepository
+	Dependencies    ports.TaskDependencyRepository
+	Reservations    ports.PartReservationRepository
+	PartItems       ports.PartItemRepository
+	PartDefinitions ports.PartDefinitionRepository
+	// Scheduling works out which dependents a delay moves along with the
+	// task, and holds moved tasks to the planned flights and the mechanic
+	// roster as a reschedule does.
+	Scheduling *SchedulingService
+	// Capacity drops options that clash with other bookings or a full bay,
+	// and places moved tasks into bay slots. Optional.
+	Capacity *CapacityService
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
+}
+
+type RescheduleOptionInput struct {
+	OrgID  *uuid.UUID
+	TaskID uuid.UUID
+	// PartDefinitionID names a part that could not be reserved. When nil,
+	// options are generated for the task's reservations whose item is no
+	// longer in stock.
+	PartDefinitionID *uuid.UUID
+}
+
+// GenerateOptions works out the options for a scheduled task, ranked by how
+// soon the task's work would be done and then by how many other tasks they
+// move, and stores them in place of the task's earlier proposals.
+func (s *RescheduleOptionService) GenerateOptions(ctx context.Context, actor app.Actor, input RescheduleOptionInput) ([]domain.RescheduleOption, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return nil, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, input.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.State != domain.TaskStateScheduled {
+		return nil, domain.NewConflictError("only scheduled tasks can be rescheduled around missing parts")
+	}
+	now := s.Clock.Now().UTC()
+	options, err := s.generate(ctx, actor, task, input.PartDefinitionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store(ctx, task, options, now); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// GenerateForUnavailableParts generates options for scheduled tasks holding
+// a reservation whose item is no longer in stock and that have no proposed
+// options yet. It returns the number of tasks given options.
+func (s *RescheduleOptionService) GenerateForUnavailableParts(ctx context.Context, actor app.Actor, limit int) (int, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleAdmin && actor.Role != domain.RoleScheduler {
+		return 0, domain.ErrForbidden
+	}
+	reservations, err := s.Reservations.ListUnavailable(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	proposed := domain.RescheduleOptionProposed
+	seen := make(map[uuid.UUID]bool)
+	generated := 0
+	for _, reservation := range reservations {
+		if seen[reservation.TaskID] {
+			continue
+		}
+		seen[reservation.TaskID] = true
+		existing, err := s.Options.ListByTask(ctx, reservation.OrgID, reservation.TaskID, &proposed)
+		if err != nil {
+			return generated, err
+		}
+		if len(existing) > 0 {
+			continue
+		}
+		task, err := s.Tasks.GetByID(ctx, reservation.OrgID, reservation.TaskID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return generated, err
+		}
+		now := s.Clock.Now().UTC()
+		options, err := s.generate(ctx, actor, task, nil, now)
+		if errors.Is(err, domain.ErrValidation) || errors.Is(err, domain.ErrConflict) || errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return generated, err
+		}
+		if len(options) == 0 {
+			continue
+		}
+		if err := s.store(ctx, task, options, now); err != nil {
+			return generated, err
+		}
+		generated++
+	}
+	return generated, nil
+}
+
+func (s *RescheduleOptionService) ListOptions(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID, state *domain.RescheduleOptionState) ([]domain.RescheduleOption, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	return s.Options.ListByTask(ctx, orgID, taskID, state)
+}
+
+// ApplyOption carries out a proposed option and records it as a schedule
+// change, discarding the task's other options. It fails when the task, a
+// task the option moves or the substitute part changed since the option
+// was generated, or when a moved task now clashes with a planned flight or
+// its mechanic's roster.
+func (s *RescheduleOptionService) ApplyOption(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.ScheduleChangeEvent, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ScheduleChangeEvent{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	option, err := s.Options.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	if err := option.CanApply(); err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, option.TaskID)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	if !task.UpdatedAt.Equal(option.TaskUpdatedAt) {
+		return domain.ScheduleChangeEvent{}, domain.NewConflictError("task changed since the option was generated")
+	}
+
+	now := s.Clock.Now().UTC()
+	actor.OrgID = orgID
+	change, err := s.change(ctx, actor, option, task, now)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	tasks := append([]domain.MaintenanceTask(nil), change.Moved...)
+	if change.Continuation != nil {
+		tasks = append(tasks, *change.Continuation)
+	}
+	if s.Scheduling != nil && len(tasks) > 0 {
+		violations, err := s.Scheduling.movedViolations(ctx, tasks, false)
+		if err != nil {
+			return domain.ScheduleChangeEvent{}, err
+		}
+		if len(violations) > 0 {
+			return domain.ScheduleChangeEvent{}, domain.NewCapacityConflict(violations[0].Kind, violations[0].Message)
+		}
+	}
+	if s.Capacity != nil {
+		if err := s.Capacity.ReserveMoved(ctx, tasks); err != nil {
+			return domain.ScheduleChangeEvent{}, err
+		}
+		copy(change.Moved, tasks)
+		if change.Continuation != nil {
+			*change.Continuation = tasks[len(tasks)-1]
+		}
+	}
+
+	event, err := s.Options.Apply(ctx, option, change, now)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	if s.Audit != nil {
+		_ = s.Audit.Insert(ctx, domain.AuditLog{
+			ID:         uuid.New(),
+			OrgID:      orgID,
+			EntityType: "reschedule_option",
+			EntityID:   option.ID,
+			Action:     domain.AuditActionStateChange,
+			UserID:     actor.UserID,
+			RequestID:  uuid.Nil,
+			Timestamp:  now,
+			Details: map[string]any{
+				"state":       string(domain.RescheduleOptionApplied),
+				"option_type": string(option.OptionType),
+				"task_id":     option.TaskID,
+			},
+		})
+	}
+	if s.Outbox != nil {
+		_ = s.Outbox.Enqueue(ctx, orgID, "reschedule_option_applied", "maintenance_task", option.TaskID, map[string]any{
+			"version":            1,
+			"org_id":             orgID,
+			"task_id":            option.TaskID,
+			"option_id":          option.ID,
+			"option_type":        option.OptionType,
+			"schedule_change_id": event.ID,
+			"affected_tasks":     event.AffectedTaskIDs,
+			"timestamp":          now,
+		}, fmt.Sprintf("reschedule_option_applied:%s", option.ID))
+	}
+	return event, nil
+}
+
+// change works out what applying the option writes, re-checking the state
+// the option was generated from.
+func (s *RescheduleOptionService) change(ctx context.Context, actor app.Actor, option domain.RescheduleOption, task domain.MaintenanceTask, now time.Time) (ports.RescheduleOptionChange, error) {
+	oldStart, oldEnd := task.StartTime, task.EndTime
+	change := ports.RescheduleOptionChange{Event: domain.ScheduleChangeEvent{
+		ID:           uuid.New(),
+		OrgID:        task.OrgID,
+		TaskID:       task.ID,
+		ChangeType:   domain.ScheduleChangeRescheduled,
+		Reason:       option.Description,
+		OldStartTime: &oldStart,
+		OldEndTime:   &oldEnd,
+		TriggeredBy:  &actor.UserID,
+		CreatedAt:    now,
+	}}
+	switch option.OptionType {
+	case domain.RescheduleOptionSubstitute:
+		if option.SubstitutePart == nil {
+			return ports.RescheduleOptionChange{}, domain.NewValidationError("option has no substitute part")
+		}
+		item, err := s.PartItems.GetByID(ctx, task.OrgID, *option.SubstitutePart)
+		if err != nil {
+			return ports.RescheduleOptionChange{}, err
+		}
+		if item.Status != domain.PartItemInStock {
+			return ports.RescheduleOptionChange{}, domain.NewConflictError("substitute part is no longer in stock")
+		}
+		change.Event.ChangeType = domain.ScheduleChangePartSubstituted
+		change.Event.NewStartTime = &oldStart
+		change.Event.NewEndTime = &oldEnd
+		change.Substitute = &domain.PartReservation{
+			ID:         uuid.New(),
+			OrgID:      task.OrgID,
+			TaskID:     task.ID,
+			PartItemID: item.ID,
+			State:      domain.ReservationReserved,
+			Quantity:   1,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		return change, nil
+	}
+
+	if option.NewStartTime == nil || option.NewEndTime == nil {
+		return ports.RescheduleOptionChange{}, domain.NewValidationError("option has no new window")
+	}
+	switch option.OptionType {
+	case domain.RescheduleOptionDelay:
+		plan, err := s.Scheduling.planReschedule(ctx, actor, RescheduleInput{
+			TaskID:       task.ID,
+			NewStartTime: *option.NewStartTime,
+			NewEndTime:   *option.NewEndTime,
+			Reason:       option.Description,
+			Cascade:      true,
+		})
+		if err != nil {
+			return ports.RescheduleOptionChange{}, err
+		}
+		plan.event.CreatedAt = now
+		change.Event = plan.event
+		change.Moved = plan.moved
+	case domain.RescheduleOptionSwap:
+		if len(option.AffectedTasks) != 1 {
+			return ports.RescheduleOptionChange{}, domain.NewValidationError("swap option must name one other task")
+		}
+		other, err := s.Tasks.GetByID(ctx, task.OrgID, option.AffectedTasks[0])
+		if err != nil {
+			return ports.RescheduleOptionChange{}, err
+		}
+		if other.State != domain.TaskStateScheduled || !other.StartTime.Equal(*option.NewStartTime) {
+			return ports.RescheduleOptionChange{}, domain.NewConflictError(fmt.Sprintf("task %s changed since the option was generated", other.ID))
+		}
+		moved := swapWindows(task, other)
+		change.Moved = moved
+		change.Event.NewStartTime = &moved[0].StartTime
+		change.Event.NewEndTime = &moved[0].EndTime
+		change.Event.AffectedTaskIDs = []uuid.UUID{other.ID}
+	case domain.RescheduleOptionSplit:
+		rest := option.NewEndTime.Sub(*option.NewStartTime)
+		first := task
+		first.EndTime = task.EndTime.Add(-rest)
+		if first.EndTime.Sub(first.StartTime) < minSplitPart {
+			return ports.RescheduleOptionChange{}, domain.NewValidationError("split leaves too little work in the task")
+		}
+		continuation := continuationTask(task, *option.NewStartTime, rest, now)
+		change.Moved = []domain.MaintenanceTask{first}
+		change.Continuation = &continuation
+		change.Event.NewStartTime = &change.Moved[0].StartTime
+		change.Event.NewEndTime = &change.Moved[0].EndTime
+		change.Event.AffectedTaskIDs = []uuid.UUID{continuation.ID}
+	default:
+		return ports.RescheduleOptionChange{}, domain.NewValidationError("unknown option type")
+	}
+	return change, nil
+}
+
+// partShortage is a part a task needs but cannot have in time. reservation
+// and item are nil when the part could not be reserved at all; readyAt is
+// nil when the part has no lead time.
+type partShortage struct {
+	reservation *domain.PartReservation
+	item        *domain.PartItem
+	definition  domain.PartDefinition
+	readyAt     *time.Time
+}
+
+// rankedOption is an option with the time the task's work would be done.
+type rankedOption struct {
+	option    domain.RescheduleOption
+	completes time.Time
+}
+
+// optionOrder breaks ties between options finishing the work at the same
+// time, least disruptive first.
+var optionOrder = map[domain.RescheduleOptionType]int{
+	domain.RescheduleOptionSubstitute: 0,
+	domain.RescheduleOptionSwap:       1,
+	domain.RescheduleOptionSplit:      2,
+	domain.RescheduleOptionDelay:      3,
+}
+
+func (s *RescheduleOptionService) generate(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, definitionID *uuid.UUID, now time.Time) ([]domain.RescheduleOption, error) {
+	shortages, err := s.shortages(ctx, task, definitionID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(shortages) == 0 {
+		return nil, domain.NewValidationError("task has no unavailable parts")
+	}
+	actor.OrgID = task.OrgID
+	var ranked []rankedOption
+	for _, shortage := range shortages {
+		for _, build := range []func(context.Context, app.Actor, domain.MaintenanceTask, partShortage) (*rankedOption, error){
+			s.substituteOption, s.swapOption, s.splitOption, s.delayOption,
+		} {
+			candidate, err := build(ctx, actor, task, shortage)
+			if err != nil {
+				return nil, err
+			}
+			if candidate == nil {
+				continue
+			}
+			candidate.option.ID = uuid.New()
+			candidate.option.OrgID = task.OrgID
+			candidate.option.TaskID = task.ID
+			candidate.option.PartDefinitionID = shortage.definition.ID
+			candidate.option.State = domain.RescheduleOptionProposed
+			candidate.option.TaskUpdatedAt = task.UpdatedAt
+			candidate.option.CreatedAt = now
+			candidate.option.UpdatedAt = now
+			if shortage.reservation != nil {
+				reservationID := shortage.reservation.ID
+				candidate.option.ReservationID = &reservationID
+			}
+			ranked = append(ranked, *candidate)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if !a.completes.Equal(b.completes) {
+			return a.completes.Before(b.completes)
+		}
+		if len(a.option.AffectedTasks) != len(b.option.AffectedTasks) {
+			return len(a.option.AffectedTasks) < len(b.option.AffectedTasks)
+		}
+		return optionOrder[a.option.OptionType] < optionOrder[b.option.OptionType]
+	})
+	options := make([]domain.RescheduleOption, 0, len(ranked))
+	for i, candidate := range ranked {
+		candidate.option.Rank = i + 1
+		options = append(options, candidate.option)
+	}
+	return options, nil
+}
+
+// shortages lists the parts the task is short of: the named definition, or
+// else the items it holds reservations on that are no longer in stock.
+func (s *RescheduleOptionService) shortages(ctx context.Context, task domain.MaintenanceTask, definitionID *uuid.UUID, now time.Time) ([]partShortage, error) {
+	if definitionID != nil {
+		def, err := s.PartDefinitions.GetByID(ctx, task.OrgID, *definitionID)
+		if err != nil {
+			return nil, err
+		}
+		return []partShortage{{definition: def, readyAt: leadTimeReady(def, now)}}, nil
+	}
+	reservations, err := s.Reservations.ListByTask(ctx, task.OrgID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	var shortages []partShortage
+	for _, reservation := range reservations {
+		if reservation.State != domain.ReservationReserved {
+			continue
+		}
+		item, err := s.PartItems.GetByID(ctx, task.OrgID, reservation.PartItemID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item.Status == domain.PartItemInStock {
+			continue
+		}
+		def, err := s.PartDefinitions.GetByID(ctx, task.OrgID, item.DefinitionID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reservation := reservation
+		shortages = append(shortages, partShortage{reservation: &reservation, item: &item, definition: def, readyAt: leadTimeReady(def, now)})
+	}
+	return shortages, nil
+}
+
+// substituteOption picks an unreserved in-stock item of the definition, or
+// failing that of one of its alternates, that does not expire before the
+// task ends. Of several, the one expiring first is used.
+func (s *RescheduleOptionService) substituteOption(ctx context.Context, _ app.Actor, task domain.MaintenanceTask, shortage partShortage) (*rankedOption, error) {
+	inStock := domain.PartItemInStock
+	for i, defID := range append([]uuid.UUID{shortage.definition.ID}, shortage.definition.AlternateIDs...) {
+		items, err := s.PartItems.List(ctx, ports.PartItemFilter{
+			OrgID:        &task.OrgID,
+			DefinitionID: &defID,
+			Status:       &inStock,
+			Unreserved:   true,
+			Limit:        200,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var best *domain.PartItem
+		for j := range items {
+			item := items[j]
+			if shortage.item != nil && item.ID == shortage.item.ID {
+				continue
+			}
+			if item.ExpiryDate != nil && item.ExpiryDate.Before(task.EndTime) {
+				continue
+			}
+			if best == nil || (item.ExpiryDate != nil && (best.ExpiryDate == nil || item.ExpiryDate.Before(*best.ExpiryDate))) {
+				best = &item
+			}
+		}
+		if best == nil {
+			continue
+		}
+		description := fmt.Sprintf("Reserve %s serial %s in place of the unavailable part", shortage.definition.Name, best.SerialNumber)
+		if i > 0 {
+			alternate, err := s.PartDefinitions.GetByID(ctx, task.OrgID, defID)
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			description = fmt.Sprintf("Reserve alternate part %s serial %s in place of %s", alternate.Name, best.SerialNumber, shortage.definition.Name)
+		}
+		itemID := best.ID
+		return &rankedOption{
+			option: domain.RescheduleOption{
+				OptionType:     domain.RescheduleOptionSubstitute,
+				Description:    description,
+				SubstitutePart: &itemID,
+			},
+			completes: task.EndTime,
+		}, nil
+	}
+	return nil, nil
+}
+
+// swapOption trades windows with the earliest later task on the same
+// aircraft that starts once the part is on hand and has all its own parts.
+// Tasks linked by dependencies are never swapped, since trading windows
+// would reorder linked work.
+func (s *RescheduleOptionService) swapOption(ctx context.Context, _ app.Actor, task domain.MaintenanceTask, shortage partShortage) (*rankedOption, error) {
+	if shortage.readyAt == nil || !shortage.readyAt.After(task.StartTime) {
+		return nil, nil
+	}
+	if linked, err := s.linked(ctx, task); err != nil || linked {
+		return nil, err
+	}
+	scheduled := domain.TaskStateScheduled
+	candidates, err := s.Tasks.List(ctx, ports.TaskFilter{
+		OrgID:      &task.OrgID,
+		AircraftID: &task.AircraftID,
+		State:      &scheduled,
+		StartFrom:  shortage.readyAt,
+		Limit:      maxSwapCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].StartTime.Before(candidates[j].StartTime) })
+	for _, other := range candidates {
+		if other.ID == task.ID {
+			continue
+		}
+		linked, err := s.linked(ctx, other)
+		if err != nil {
+			return nil, err
+		}
+		if linked {
+			continue
+		}
+		ready, err := s.partsReady(ctx, other)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			continue
+		}
+		moved := swapWindows(task, other)
+		if ok, err := s.fits(ctx, moved); err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return &rankedOption{
+			option: domain.RescheduleOption{
+				OptionType:    domain.RescheduleOptionSwap,
+				Description:   fmt.Sprintf("Swap windows with task %s, which has all its parts, so this task starts %s once %s is on hand", other.ID, moved[0].StartTime.Format(time.RFC3339), shortage.definition.Name),
+				NewStartTime:  &moved[0].StartTime,
+				NewEndTime:    &moved[0].EndTime,
+				AffectedTasks: []uuid.UUID{other.ID},
+			},
+			completes: moved[0].EndTime,
+		}, nil
+	}
+	return nil, nil
+}
+
+// splitOption keeps the first half of the work in place and moves the rest
+// to a continuation task starting once the part is on hand.
+func (s *RescheduleOptionService) splitOption(ctx context.Context, _ app.Actor, task domain.MaintenanceTask, shortage partShortage) (*rankedOption, error) {
+	if shortage.readyAt == nil || !shortage.readyAt.After(task.StartTime) {
+		return nil, nil
+	}
+	duration := task.EndTime.Sub(task.StartTime)
+	if duration < 2*minSplitPart {
+		return nil, nil
+	}
+	first := task
+	first.EndTime = task.StartTime.Add(duration / 2)
+	rest := task.EndTime.Sub(first.EndTime)
+	start := *shortage.readyAt
+	if start.Before(first.EndTime) {
+		start = first.EndTime
+	}
+	continuation := continuationTask(task, start, rest, task.UpdatedAt)
+	if ok, err := s.fits(ctx, []domain.MaintenanceTask{first, continuation}); err != nil || !ok {
+		return nil, err
+	}
+	return &rankedOption{
+		option: domain.RescheduleOption{
+			OptionType:   domain.RescheduleOptionSplit,
+			Description:  fmt.Sprintf("Do the first %s of the work as planned and the remaining %s in a continuation task from %s, once %s is on hand", first.EndTime.Sub(first.StartTime), rest, start.Format(time.RFC3339), shortage.definition.Name),
+			NewStartTime: &continuation.StartTime,
+			NewEndTime:   &continuation.EndTime,
+		},
+		completes: continuation.EndTime,
+	}, nil
+}
+
+// delayOption moves the task, and the dependents that move with it, to
+// when the part can be on hand after its lead time.
+func (s *RescheduleOptionService) delayOption(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, shortage partShortage) (*rankedOption, error) {
+	if shortage.readyAt == nil || !shortage.readyAt.After(task.StartTime) || s.Scheduling == nil {
+		return nil, nil
+	}
+	start := *shortage.readyAt
+	end := start.Add(task.EndTime.Sub(task.StartTime))
+	description := fmt.Sprintf("Delay until %s, when %s can be on hand after its %d-day lead time", start.Format(time.RFC3339), shortage.definition.Name, *shortage.definition.LeadTimeDays)
+	plan, err := s.Scheduling.planReschedule(ctx, actor, RescheduleInput{
+		TaskID:       task.ID,
+		NewStartTime: start,
+		NewEndTime:   end,
+		Reason:       description,
+		Cascade:      true,
+	})
+	if errors.Is(err, domain.ErrConflict) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := s.fits(ctx, plan.moved); err != nil || !ok {
+		return nil, err
+	}
+	if n := len(plan.event.AffectedTaskIDs); n > 0 {
+		description += fmt.Sprintf(", moving %d dependent tasks", n)
+	}
+	return &rankedOption{
+		option: domain.RescheduleOption{
+			OptionType:    domain.RescheduleOptionDelay,
+			Description:   description,
+			NewStartTime:  &start,
+			NewEndTime:    &end,
+			AffectedTasks: plan.event.AffectedTaskIDs,
+		},
+		completes: end,
+	}, nil
+}
+
+// fits reports whether the tasks can take their new windows without a
+// capacity conflict, a planned flight or a mechanic off the roster.
+func (s *RescheduleOptionService) fits(ctx context.Context, tasks []domain.MaintenanceTask) (bool, error) {
+	if s.Capacity != nil {
+		violations, err := s.Capacity.CheckMoved(ctx, tasks)
+		if err != nil || len(violations) > 0 {
+			return false, err
+		}
+	}
+	if s.Scheduling == nil {
+		return true, nil
+	}
+	violations, err := s.Scheduling.movedViolations(ctx, tasks, false)
+	if err != nil {
+		return false, err
+	}
+	return len(violations) == 0, nil
+}
+
+// linked reports whether the task depends on another task or has
+// dependents.
+func (s *RescheduleOptionService) linked(ctx context.Context, task domain.MaintenanceTask) (bool, error) {
+	if s.Dependencies == nil {
+		return false, nil
+	}
+	deps, err := s.Dependencies.ListByTask(ctx, task.OrgID, task.ID)
+	if err != nil || len(deps) > 0 {
+		return len(deps) > 0, err
+	}
+	dependents, err := s.Dependencies.ListDependents(ctx, task.OrgID, task.ID)
+	return len(dependents) > 0, err
+}
+
+// partsReady reports whether every part the task holds a reservation on is
+// in stock.
+func (s *RescheduleOptionService) partsReady(ctx context.Context, task domain.MaintenanceTask) (bool, error) {
+	reservations, err := s.Reservations.ListByTask(ctx, task.OrgID, task.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, reservation := range reservations {
+		if reservation.State != domain.ReservationReserved {
+			continue
+		}
+		item, err := s.PartItems.GetByID(ctx, task.OrgID, reservation.PartItemID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if item.Status != domain.PartItemInStock {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// store replaces the task's proposed options and tells planners about the
+// new ones.
+func (s *RescheduleOptionService) store(ctx context.Context, task domain.MaintenanceTask, options []domain.RescheduleOption, now time.Time) error {
+	if err := s.Options.Replace(ctx, task.OrgID, task.ID, options, now); err != nil {
+		return err
+	}
+	if s.Outbox == nil || len(options) == 0 {
+		return nil
+	}
+	optionIDs := make([]uuid.UUID, 0, len(options))
+	for _, option := range options {
+		optionIDs = append(optionIDs, option.ID)
+	}
+	_ = s.Outbox.Enqueue(ctx, task.OrgID, "reschedule_options_generated", "maintenance_task", task.ID, map[string]any{
+		"version":    1,
+		"org_id":     task.OrgID,
+		"task_id":    task.ID,
+		"option_ids": optionIDs,
+		"timestamp":  now,
+	}, fmt.Sprintf("reschedule_options_generated:%s", options[0].ID))
+	return nil
+}
+
+// swapWindows returns task and other with their start times traded, each
+// keeping its own duration.
+func swapWindows(task, other domain.MaintenanceTask) []domain.MaintenanceTask {
+	movedTask, movedOther := task, other
+	movedTask.StartTime = other.StartTime
+	movedTask.EndTime = other.StartTime.Add(task.EndTime.Sub(task.StartTime))
+	movedOther.StartTime = task.StartTime
+	movedOther.EndTime = task.StartTime.Add(other.EndTime.Sub(other.StartTime))
+	return []domain.MaintenanceTask{movedTask, movedOther}
+}
+
+// continuationTask is the task a split moves the rest of the work into. It
+// inherits the original's aircraft, bay, mechanic and classification.
+func continuationTask(task domain.MaintenanceTask, start time.Time, duration time.Duration, now time.Time) domain.MaintenanceTask {
+	return domain.MaintenanceTask{
+		ID:                 uuid.New(),
+		OrgID:              task.OrgID,
+		AircraftID:         task.AircraftID,
+		ProgramID:          task.ProgramID,
+		WorkPackageID:      task.WorkPackageID,
+		BayID:              task.BayID,
+		Type:               task.Type,
+		State:              domain.TaskStateScheduled,
+		Priority:           task.Priority,
+		StartTime:          start,
+		EndTime:            start.Add(duration),
+		AssignedMechanicID: task.AssignedMechanicID,
+		Notes:              fmt.Sprintf("Continuation of task %s", task.ID),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
+func leadTimeReady(def domain.PartDefinition, now time.Time) *time.Time {
+	if def.LeadTimeDays == nil {
+		return nil
+	}
+	ready := now.Add(time.Duration(*def.LeadTimeDays) * 24 * time.Hour)
+	return &ready
+}
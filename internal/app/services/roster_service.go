@@ -0,0 +1,691 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// RosterService manages shift patterns, mechanics' roster assignments and
+// absences, and answers when mechanics can work. Mechanics without any
+// roster assignment are not limited to shifts; absences and other tasks
+// still apply to them.
+type RosterService struct {
+	Patterns    ports.ShiftPatternRepository
+	Assignments ports.RosterAssignmentRepository
+	Absences    ports.MechanicAbsenceRepository
+	Users       ports.UserRepository
+	Stations    ports.StationRepository
+	Tasks       ports.TaskRepository
+	Audit       ports.AuditRepository
+	Clock       app.Clock
+}
+
+type ShiftPatternCreateInput struct {
+	OrgID           *uuid.UUID
+	Name            string
+	Timezone        string
+	StartMinute     int
+	DurationMinutes int
+	Weekdays        domain.WeekdaySet
+}
+
+type ShiftPatternUpdateInput struct {
+	Name            *string
+	Timezone        *string
+	StartMinute     *int
+	DurationMinutes *int
+	Weekdays        *domain.WeekdaySet
+}
+
+type RosterAssignmentCreateInput struct {
+	OrgID          *uuid.UUID
+	MechanicID     uuid.UUID
+	ShiftPatternID uuid.UUID
+	StationID      *uuid.UUID
+	EffectiveFrom  time.Time
+	EffectiveTo    *time.Time
+}
+
+type MechanicAbsenceCreateInput struct {
+	OrgID      *uuid.UUID
+	MechanicID uuid.UUID
+	Kind       domain.AbsenceKind
+	StartTime  time.Time
+	EndTime    time.Time
+	Reason     string
+}
+
+type MechanicAvailabilityQuery struct {
+	OrgID uuid.UUID
+	// MechanicIDs limits the answer to these mechanics. When empty, the
+	// mechanics rostered at StationID, or all mechanics of the org, are
+	// returned.
+	MechanicIDs []uuid.UUID
+	// StationID limits working time to shifts at the station.
+	StationID *uuid.UUID
+	From      time.Time
+	To        time.Time
+}
+
+// MechanicAvailability lists the windows in which a mechanic is on shift,
+// not absent and not working another task.
+type MechanicAvailability struct {
+	MechanicID uuid.UUID
+	// Rostered is false when the mechanic has no roster assignment, so
+	// their time is not limited to shifts.
+	Rostered bool
+	Free     []domain.TimeWindow
+}
+
+func (s *RosterService) CreateShiftPattern(ctx context.Context, actor app.Actor, input ShiftPatternCreateInput) (domain.ShiftPattern, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ShiftPattern{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	timezone := strings.TrimSpace(input.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	now := s.Clock.Now()
+	pattern := domain.ShiftPattern{
+		ID:              uuid.New(),
+		OrgID:           orgID,
+		Name:            strings.TrimSpace(input.Name),
+		Timezone:        timezone,
+		StartMinute:     input.StartMinute,
+		DurationMinutes: input.DurationMinutes,
+		Weekdays:        input.Weekdays,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := pattern.Validate(); err != nil {
+		return domain.ShiftPattern{}, err
+	}
+	created, err := s.Patterns.Create(ctx, pattern)
+	if err != nil {
+		return domain.ShiftPattern{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "shift_pattern", created.ID, domain.AuditActionCreate, nil)
+	return created, nil
+}
+
+func (s *RosterService) GetShiftPattern(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.ShiftPattern, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	return s.Patterns.GetByID(ctx, orgID, id)
+}
+
+func (s *RosterService) ListShiftPatterns(ctx context.Context, actor app.Actor, filter ports.ShiftPatternFilter) ([]domain.ShiftPattern, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Patterns.List(ctx, filter)
+}
+
+// UpdateShiftPattern changes a pattern for all its assignments. Tasks
+// already assigned are not re-checked against the new shifts.
+func (s *RosterService) UpdateShiftPattern(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input ShiftPatternUpdateInput) (domain.ShiftPattern, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ShiftPattern{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pattern, err := s.Patterns.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.ShiftPattern{}, err
+	}
+	if input.Name != nil {
+		pattern.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Timezone != nil {
+		pattern.Timezone = strings.TrimSpace(*input.Timezone)
+	}
+	if input.StartMinute != nil {
+		pattern.StartMinute = *input.StartMinute
+	}
+	if input.DurationMinutes != nil {
+		pattern.DurationMinutes = *input.DurationMinutes
+	}
+	if input.Weekdays != nil {
+		pattern.Weekdays = *input.Weekdays
+	}
+	if err := pattern.Validate(); err != nil {
+		return domain.ShiftPattern{}, err
+	}
+	pattern.UpdatedAt = s.Clock.Now()
+	updated, err := s.Patterns.Update(ctx, pattern)
+	if err != nil {
+		return domain.ShiftPattern{}, err
+	}
+	s.audit(ctx, actor, updated.OrgID, "shift_pattern", updated.ID, domain.AuditActionUpdate, nil)
+	return updated, nil
+}
+
+// DeleteShiftPattern removes a pattern no current or future assignment
+// uses.
+func (s *RosterService) DeleteShiftPattern(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Patterns.SoftDelete(ctx, orgID, id, s.Clock.Now()); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "shift_pattern", id, domain.AuditActionDelete, nil)
+	return nil
+}
+
+func (s *RosterService) CreateAssignment(ctx context.Context, actor app.Actor, input RosterAssignmentCreateInput) (domain.RosterAssignment, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.RosterAssignment{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	assignment := domain.RosterAssignment{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		MechanicID:     input.MechanicID,
+		ShiftPatternID: input.ShiftPatternID,
+		StationID:      input.StationID,
+		EffectiveFrom:  input.EffectiveFrom.UTC(),
+		CreatedAt:      s.Clock.Now(),
+	}
+	if input.EffectiveTo != nil {
+		effectiveTo := input.EffectiveTo.UTC()
+		assignment.EffectiveTo = &effectiveTo
+	}
+	if err := assignment.Validate(); err != nil {
+		return domain.RosterAssignment{}, err
+	}
+	if err := s.requireMechanic(ctx, orgID, assignment.MechanicID); err != nil {
+		return domain.RosterAssignment{}, err
+	}
+	if _, err := s.Patterns.GetByID(ctx, orgID, assignment.ShiftPatternID); err != nil {
+		return domain.RosterAssignment{}, err
+	}
+	if assignment.StationID != nil {
+		if s.Stations == nil {
+			return domain.RosterAssignment{}, domain.NewValidationError("stations unavailable")
+		}
+		if _, err := s.Stations.GetByID(ctx, orgID, *assignment.StationID); err != nil {
+			return domain.RosterAssignment{}, err
+		}
+	}
+	created, err := s.Assignments.Create(ctx, assignment)
+	if err != nil {
+		return domain.RosterAssignment{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "roster_assignment", created.ID, domain.AuditActionCreate, map[string]any{
+		"mechanic_id":      created.MechanicID,
+		"shift_pattern_id": created.ShiftPatternID,
+	})
+	return created, nil
+}
+
+func (s *RosterService) ListAssignments(ctx context.Context, actor app.Actor, filter ports.RosterAssignmentFilter) ([]domain.RosterAssignment, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Assignments.List(ctx, filter)
+}
+
+func (s *RosterService) DeleteAssignment(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Assignments.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "roster_assignment", id, domain.AuditActionDelete, nil)
+	return nil
+}
+
+func (s *RosterService) CreateAbsence(ctx context.Context, actor app.Actor, input MechanicAbsenceCreateInput) (domain.MechanicAbsence, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.MechanicAbsence{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	absence := domain.MechanicAbsence{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		MechanicID: input.MechanicID,
+		Kind:       input.Kind,
+		StartTime:  input.StartTime.UTC(),
+		EndTime:    input.EndTime.UTC(),
+		Reason:     strings.TrimSpace(input.Reason),
+		CreatedAt:  s.Clock.Now(),
+	}
+	if err := absence.Validate(); err != nil {
+		return domain.MechanicAbsence{}, err
+	}
+	if err := s.requireMechanic(ctx, orgID, absence.MechanicID); err != nil {
+		return domain.MechanicAbsence{}, err
+	}
+	created, err := s.Absences.Create(ctx, absence)
+	if err != nil {
+		return domain.MechanicAbsence{}, err
+	}
+	s.audit(ctx, actor, created.OrgID, "mechanic_absence", created.ID, domain.AuditActionCreate, map[string]any{
+		"mechanic_id": created.MechanicID,
+		"kind":        string(created.Kind),
+		"start_time":  created.StartTime,
+		"end_time":    created.EndTime,
+	})
+	return created, nil
+}
+
+func (s *RosterService) ListAbsences(ctx context.Context, actor app.Actor, orgID uuid.UUID, mechanicID *uuid.UUID, from, to time.Time) ([]domain.MechanicAbsence, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if !to.After(from) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	return s.Absences.ListOverlapping(ctx, orgID, mechanicID, from.UTC(), to.UTC())
+}
+
+func (s *RosterService) DeleteAbsence(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	if err := s.Absences.Delete(ctx, orgID, id); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, orgID, "mechanic_absence", id, domain.AuditActionDelete, nil)
+	return nil
+}
+
+// Availability returns, per mechanic, the windows between From and To in
+// which the mechanic is free to take a task.
+func (s *RosterService) Availability(ctx context.Context, actor app.Actor, query MechanicAvailabilityQuery) ([]MechanicAvailability, error) {
+	if !actor.IsAdmin() {
+		query.OrgID = actor.OrgID
+	}
+	from, to := query.From.UTC(), query.To.UTC()
+	if !to.After(from) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > maxAvailabilitySpan {
+		return nil, domain.NewValidationError("range must not exceed 92 days")
+	}
+	orgID := query.OrgID
+	if query.StationID != nil {
+		if s.Stations == nil {
+			return nil, domain.NewValidationError("stations unavailable")
+		}
+		if _, err := s.Stations.GetByID(ctx, orgID, *query.StationID); err != nil {
+			return nil, err
+		}
+	}
+	mechanicIDs := query.MechanicIDs
+	if len(mechanicIDs) == 0 {
+		var err error
+		mechanicIDs, err = s.mechanicsFor(ctx, orgID, query.StationID, from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+	availability := make([]MechanicAvailability, 0, len(mechanicIDs))
+	for _, mechanicID := range mechanicIDs {
+		free, rostered, err := s.freeWindows(ctx, orgID, mechanicID, query.StationID, from, to, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+		availability = append(availability, MechanicAvailability{MechanicID: mechanicID, Rostered: rostered, Free: free})
+	}
+	return availability, nil
+}
+
+// AvailableMechanics keeps the mechanics free for the whole of [from, to),
+// on shift at the station when one is given.
+func (s *RosterService) AvailableMechanics(ctx context.Context, actor app.Actor, orgID uuid.UUID, mechanicIDs []uuid.UUID, stationID *uuid.UUID, from, to time.Time) ([]uuid.UUID, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	available := make([]uuid.UUID, 0, len(mechanicIDs))
+	for _, mechanicID := range mechanicIDs {
+		free, _, err := s.freeWindows(ctx, orgID, mechanicID, stationID, from, to, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+		if domain.WindowsCover(free, from, to) {
+			available = append(available, mechanicID)
+		}
+	}
+	return available, nil
+}
+
+// WorkingWindows returns when the mechanic is on shift and not absent
+// between from and to, ignoring their tasks.
+func (s *RosterService) WorkingWindows(ctx context.Context, orgID, mechanicID uuid.UUID, from, to time.Time) ([]domain.TimeWindow, error) {
+	windows, _, err := s.workingWindows(ctx, orgID, mechanicID, nil, from, to)
+	return windows, err
+}
+
+// CheckAssignment rejects an active task whose mechanic works another
+// active task at the same time, is absent, or is rostered but off shift
+// for part of the window.
+func (s *RosterService) CheckAssignment(ctx context.Context, task domain.MaintenanceTask) error {
+	return s.CheckAssignmentWithout(ctx, task, nil)
+}
+
+// CheckAssignmentWithout runs the CheckAssignment checks as if the released
+// tasks no longer held the mechanic, as when a preempting task displaces
+// them.
+func (s *RosterService) CheckAssignmentWithout(ctx context.Context, task domain.MaintenanceTask, released map[uuid.UUID]bool) error {
+	if task.AssignedMechanicID == nil || !task.IsActive() {
+		return nil
+	}
+	mechanicID := *task.AssignedMechanicID
+	busy, err := s.mechanicTasks(ctx, task.OrgID, mechanicID, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	for _, other := range busy {
+		if other.ID != task.ID && !released[other.ID] {
+			return domain.NewCapacityConflict(domain.CapacityConflictMechanicOverlap, fmt.Sprintf("mechanic already works task %s from %s to %s", other.ID, other.StartTime.Format(time.RFC3339), other.EndTime.Format(time.RFC3339)))
+		}
+	}
+	if s.Absences != nil {
+		absences, err := s.Absences.ListOverlapping(ctx, task.OrgID, &mechanicID, task.StartTime, task.EndTime)
+		if err != nil {
+			return err
+		}
+		if len(absences) > 0 {
+			absence := absences[0]
+			return domain.NewCapacityConflict(domain.CapacityConflictMechanicUnavailable, fmt.Sprintf("mechanic is absent (%s) from %s to %s", absence.Kind, absence.StartTime.Format(time.RFC3339), absence.EndTime.Format(time.RFC3339)))
+		}
+	}
+	shifts, rostered, err := s.rosterWindows(ctx, task.OrgID, mechanicID, nil, task.StartTime, task.EndTime)
+	if err != nil {
+		return err
+	}
+	if rostered && !domain.WindowsCover(shifts, task.StartTime, task.EndTime) {
+		return domain.NewCapacityConflict(domain.CapacityConflictMechanicUnavailable, fmt.Sprintf("task window %s to %s falls outside the mechanic's shifts", task.StartTime.Format(time.RFC3339), task.EndTime.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// CheckMoved runs the CheckAssignment checks over tasks that move together,
+// such as a rescheduled task and its cascaded dependents. Each task is
+// checked against the others at their new windows rather than the stored
+// ones. Conflicts are listed per task rather than returned as an error.
+func (s *RosterService) CheckMoved(ctx context.Context, tasks []domain.MaintenanceTask) ([]CapacityViolation, error) {
+	released := make(map[uuid.UUID]bool, len(tasks))
+	for _, task := range tasks {
+		released[task.ID] = true
+	}
+	var violations []CapacityViolation
+	for i, task := range tasks {
+		err := s.CheckAssignmentWithout(ctx, task, released)
+		if err == nil {
+			err = movedMechanicOverlap(task, tasks[:i])
+		}
+		var conflict *domain.CapacityConflictError
+		if errors.As(err, &conflict) {
+			violations = append(violations, CapacityViolation{TaskID: task.ID, Kind: conflict.Kind, Message: conflict.Message})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return violations, nil
+}
+
+// movedMechanicOverlap rejects a moved task whose mechanic works one of the
+// other moved tasks during its new window.
+func movedMechanicOverlap(task domain.MaintenanceTask, others []domain.MaintenanceTask) error {
+	if task.AssignedMechanicID == nil || !task.IsActive() {
+		return nil
+	}
+	for _, other := range others {
+		if other.AssignedMechanicID == nil || *other.AssignedMechanicID != *task.AssignedMechanicID || !other.IsActive() {
+			continue
+		}
+		if other.StartTime.Before(task.EndTime) && task.StartTime.Before(other.EndTime) {
+			return domain.NewCapacityConflict(domain.CapacityConflictMechanicOverlap, fmt.Sprintf("mechanic already works task %s from %s to %s", other.ID, other.StartTime.Format(time.RFC3339), other.EndTime.Format(time.RFC3339)))
+		}
+	}
+	return nil
+}
+
+// freeWindows is the mechanic's working time minus their active tasks,
+// leaving out the task being placed.
+func (s *RosterService) freeWindows(ctx context.Context, orgID, mechanicID uuid.UUID, stationID *uuid.UUID, from, to time.Time, except uuid.UUID) ([]domain.TimeWindow, bool, error) {
+	working, rostered, err := s.workingWindows(ctx, orgID, mechanicID, stationID, from, to)
+	if err != nil {
+		return nil, false, err
+	}
+	tasks, err := s.mechanicTasks(ctx, orgID, mechanicID, from, to)
+	if err != nil {
+		return nil, false, err
+	}
+	busy := make([]domain.TimeWindow, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ID != except {
+			busy = append(busy, domain.TimeWindow{Start: task.StartTime, End: task.EndTime})
+		}
+	}
+	return domain.SubtractWindows(working, busy), rostered, nil
+}
+
+// workingWindows is the mechanic's rostered time, or all of [from, to) when
+// they have no roster, minus their absences.
+func (s *RosterService) workingWindows(ctx context.Context, orgID, mechanicID uuid.UUID, stationID *uuid.UUID, from, to time.Time) ([]domain.TimeWindow, bool, error) {
+	working, rostered, err := s.rosterWindows(ctx, orgID, mechanicID, stationID, from, to)
+	if err != nil {
+		return nil, false, err
+	}
+	if !rostered {
+		working = []domain.TimeWindow{{Start: from, End: to}}
+	}
+	if s.Absences == nil {
+		return working, rostered, nil
+	}
+	absences, err := s.Absences.ListOverlapping(ctx, orgID, &mechanicID, from, to)
+	if err != nil {
+		return nil, false, err
+	}
+	away := make([]domain.TimeWindow, 0, len(absences))
+	for _, absence := range absences {
+		away = append(away, domain.TimeWindow{Start: absence.StartTime, End: absence.EndTime})
+	}
+	return domain.SubtractWindows(working, away), rostered, nil
+}
+
+// rosterWindows returns the mechanic's shifts between from and to, limited
+// to a station when given, and whether the mechanic has any roster at all.
+func (s *RosterService) rosterWindows(ctx context.Context, orgID, mechanicID uuid.UUID, stationID *uuid.UUID, from, to time.Time) ([]domain.TimeWindow, bool, error) {
+	if s.Assignments == nil {
+		return nil, false, nil
+	}
+	any, err := s.Assignments.List(ctx, ports.RosterAssignmentFilter{OrgID: &orgID, MechanicID: &mechanicID, Limit: 1})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(any) == 0 {
+		return nil, false, nil
+	}
+	assignments, err := s.listAssignments(ctx, ports.RosterAssignmentFilter{
+		OrgID:      &orgID,
+		MechanicID: &mechanicID,
+		StationID:  stationID,
+		ActiveFrom: &from,
+		ActiveTo:   &to,
+	})
+	if err != nil {
+		return nil, true, err
+	}
+	patterns := make(map[uuid.UUID]domain.ShiftPattern, len(assignments))
+	for _, assignment := range assignments {
+		if _, ok := patterns[assignment.ShiftPatternID]; ok {
+			continue
+		}
+		pattern, err := s.Patterns.GetByID(ctx, orgID, assignment.ShiftPatternID)
+		if err != nil {
+			return nil, true, err
+		}
+		patterns[pattern.ID] = pattern
+	}
+	return domain.RosterWindows(assignments, patterns, from, to), true, nil
+}
+
+// mechanicsFor lists the mechanics rostered at the station in the range,
+// or every mechanic of the org when no station is given.
+func (s *RosterService) mechanicsFor(ctx context.Context, orgID uuid.UUID, stationID *uuid.UUID, from, to time.Time) ([]uuid.UUID, error) {
+	if stationID != nil {
+		assignments, err := s.listAssignments(ctx, ports.RosterAssignmentFilter{OrgID: &orgID, StationID: stationID, ActiveFrom: &from, ActiveTo: &to})
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[uuid.UUID]bool, len(assignments))
+		ids := make([]uuid.UUID, 0, len(assignments))
+		for _, assignment := range assignments {
+			if !seen[assignment.MechanicID] {
+				seen[assignment.MechanicID] = true
+				ids = append(ids, assignment.MechanicID)
+			}
+		}
+		return ids, nil
+	}
+	if s.Users == nil {
+		return nil, domain.NewValidationError("users unavailable")
+	}
+	role := domain.RoleMechanic
+	var ids []uuid.UUID
+	for offset := 0; ; offset += capacityPageSize {
+		page, err := s.Users.List(ctx, ports.UserFilter{OrgID: &orgID, Role: &role, Limit: capacityPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range page {
+			ids = append(ids, user.ID)
+		}
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+	return ids, nil
+}
+
+func (s *RosterService) listAssignments(ctx context.Context, filter ports.RosterAssignmentFilter) ([]domain.RosterAssignment, error) {
+	filter.Limit = capacityPageSize
+	var assignments []domain.RosterAssignment
+	for offset := 0; ; offset += capacityPageSize {
+		filter.Offset = offset
+		page, err := s.Assignments.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, page...)
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+	return assignments, nil
+}
+
+// mechanicTasks pages through the mechanic's active tasks whose window
+// intersects [from, to).
+func (s *RosterService) mechanicTasks(ctx context.Context, orgID, mechanicID uuid.UUID, from, to time.Time) ([]domain.MaintenanceTask, error) {
+	if s.Tasks == nil {
+		return nil, nil
+	}
+	filter := ports.TaskFilter{OrgID: &orgID, AssignedMechanicID: &mechanicID, ActiveOnly: true, OverlapFrom: &from, OverlapTo: &to, Limit: capacityPageSize}
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += capacityPageSize {
+		filter.Offset = offset
+		page, err := s.Tasks.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+	return tasks, nil
+}
+
+func (s *RosterService) requireMechanic(ctx context.Context, orgID, mechanicID uuid.UUID) error {
+	if s.Users == nil {
+		return nil
+	}
+	user, err := s.Users.GetByID(ctx, orgID, mechanicID)
+	if err != nil {
+		return err
+	}
+	if user.Role != domain.RoleMechanic {
+		return domain.NewValidationError("user is not a mechanic")
+	}
+	return nil
+}
+
+func (s *RosterService) audit(ctx context.Context, actor app.Actor, orgID uuid.UUID, entityType string, entityID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
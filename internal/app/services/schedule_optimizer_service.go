@@ -0,0 +1,1168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// maxPlanTasks bounds the number of tasks one optimizer run may place.
+const maxPlanTasks = 500
+
+// ScheduleOptimizerService proposes start times, bays and mechanics for a
+// set of tasks and applies reviewed proposals. Tasks are placed greedily in
+// dependency order, higher priority first, each at the earliest time at
+// which the aircraft, a bay slot and a qualified mechanic are free and its
+// reserved parts are available.
+type ScheduleOptimizerService struct {
+	Plans        ports.SchedulePlanRepository
+	Tasks        ports.TaskRepository
+	Dependencies ports.TaskDependencyRepository
+	Aircraft     ports.AircraftRepository
+	// Certs limits mechanics to those qualified for the task type and
+	// aircraft type. Without it tasks keep their current mechanic.
+	Certs           ports.CertificationRepository
+	Reservations    ports.PartReservationRepository
+	PartItems       ports.PartItemRepository
+	PartDefinitions ports.PartDefinitionRepository
+	Capacity        *CapacityService
+	// Roster keeps mechanics to their shifts and away from their absences.
+	// Without it mechanics are only limited by their other tasks.
+	Roster *RosterService
+	// Flights keeps tasks inside their aircraft's ground time, at the
+	// station of the bay when known. Optional.
+	Flights *FlightScheduleService
+	Audit   ports.AuditRepository
+	Outbox  ports.OutboxRepository
+	Clock   app.Clock
+}
+
+type ScheduleOptimizeInput struct {
+	OrgID *uuid.UUID
+	// TaskIDs lists the tasks to plan. When empty, the scheduled tasks in
+	// the horizon that are at risk are planned.
+	TaskIDs   []uuid.UUID
+	From      time.Time
+	To        time.Time
+	StationID *uuid.UUID
+}
+
+// Propose plans the tasks and stores the result as a proposed plan. Nothing
+// changes on the tasks until the plan is applied.
+func (s *ScheduleOptimizerService) Propose(ctx context.Context, actor app.Actor, input ScheduleOptimizeInput) (domain.SchedulePlan, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.SchedulePlan{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now().UTC()
+	from, to := input.From.UTC(), input.To.UTC()
+	if !to.After(from) {
+		return domain.SchedulePlan{}, domain.NewValidationError("to must be after from")
+	}
+	if to.Sub(from) > maxAvailabilitySpan {
+		return domain.SchedulePlan{}, domain.NewValidationError("horizon must not exceed 92 days")
+	}
+	if from.Before(now) {
+		from = now
+	}
+	if !to.After(from) {
+		return domain.SchedulePlan{}, domain.NewValidationError("horizon has already passed")
+	}
+	if len(input.TaskIDs) > maxPlanTasks {
+		return domain.SchedulePlan{}, domain.NewValidationError(fmt.Sprintf("at most %d tasks can be planned at once", maxPlanTasks))
+	}
+
+	planner := newSchedulePlanner(from, to)
+	if input.StationID != nil {
+		if s.Capacity == nil {
+			return domain.SchedulePlan{}, domain.NewValidationError("hangar capacity unavailable")
+		}
+		station, err := s.Capacity.Stations.GetByID(ctx, orgID, *input.StationID)
+		if err != nil {
+			return domain.SchedulePlan{}, err
+		}
+		planner.stationCode = station.Code
+		for offset := 0; ; offset += capacityPageSize {
+			page, err := s.Capacity.Bays.List(ctx, ports.HangarBayFilter{OrgID: &orgID, StationID: input.StationID, Limit: capacityPageSize, Offset: offset})
+			if err != nil {
+				return domain.SchedulePlan{}, err
+			}
+			for _, bay := range page {
+				planner.stationBays = append(planner.stationBays, bay.ID)
+				planner.bays[bay.ID] = bay
+			}
+			if len(page) < capacityPageSize {
+				break
+			}
+		}
+	}
+
+	active, err := s.activeTasks(ctx, orgID, from, to)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	known := make(map[uuid.UUID]domain.MaintenanceTask, len(active))
+	for _, task := range active {
+		known[task.ID] = task
+	}
+
+	var candidates []domain.MaintenanceTask
+	if len(input.TaskIDs) > 0 {
+		seen := make(map[uuid.UUID]bool, len(input.TaskIDs))
+		for _, id := range input.TaskIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			task, err := s.Tasks.GetByID(ctx, orgID, id)
+			if err != nil {
+				return domain.SchedulePlan{}, fmt.Errorf("task %s: %w", id, err)
+			}
+			if task.State != domain.TaskStateScheduled {
+				return domain.SchedulePlan{}, domain.NewValidationError(fmt.Sprintf("task %s is not scheduled", id))
+			}
+			known[task.ID] = task
+			candidates = append(candidates, task)
+		}
+	} else {
+		for _, task := range active {
+			if task.State == domain.TaskStateScheduled {
+				candidates = append(candidates, task)
+			}
+		}
+	}
+
+	lookup := func(id uuid.UUID) (domain.MaintenanceTask, bool) {
+		if task, ok := known[id]; ok {
+			return task, true
+		}
+		task, err := s.Tasks.GetByID(ctx, orgID, id)
+		if err != nil {
+			return domain.MaintenanceTask{}, false
+		}
+		known[id] = task
+		return task, true
+	}
+	qualifiedCache := make(map[string][]uuid.UUID)
+	planTasks := make([]*planTask, 0, len(candidates))
+	for _, task := range candidates {
+		pt, err := s.prepare(ctx, orgID, task, now, qualifiedCache)
+		if err != nil {
+			return domain.SchedulePlan{}, err
+		}
+		planTasks = append(planTasks, pt)
+	}
+	if err := s.loadGroundWindows(ctx, orgID, planner, planTasks); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	if len(input.TaskIDs) == 0 {
+		atRisk := planTasks[:0]
+		for _, pt := range planTasks {
+			if _, onGround := planner.onGround(pt.task, pt.task.StartTime, pt.task.EndTime, ""); pt.atRisk(lookup, input.StationID != nil) || !onGround {
+				atRisk = append(atRisk, pt)
+			}
+		}
+		planTasks = atRisk
+		if len(planTasks) > maxPlanTasks {
+			sortPlanTasks(planTasks)
+			planTasks = planTasks[:maxPlanTasks]
+		}
+	}
+	if len(planTasks) == 0 {
+		return domain.SchedulePlan{}, domain.NewValidationError("no tasks to plan")
+	}
+
+	planned := make(map[uuid.UUID]bool, len(planTasks))
+	for _, pt := range planTasks {
+		planned[pt.task.ID] = true
+	}
+	for _, pt := range planTasks {
+		if err := s.linkDependents(ctx, orgID, pt, planned, lookup); err != nil {
+			return domain.SchedulePlan{}, err
+		}
+	}
+	for _, task := range active {
+		if !planned[task.ID] {
+			planner.fixed = append(planner.fixed, task)
+		}
+	}
+	if err := s.loadBays(ctx, orgID, planner, planTasks); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	if err := s.loadMechanicWindows(ctx, orgID, planner, planTasks); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	if err := s.loadBayStations(ctx, orgID, planner); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+
+	results := planner.run(orderPlanTasks(planTasks, planned), lookup)
+	plan := domain.SchedulePlan{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		State:        domain.SchedulePlanProposed,
+		HorizonStart: from,
+		HorizonEnd:   to,
+		StationID:    input.StationID,
+		CreatedBy:    actor.UserID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for _, result := range results {
+		task := result.pt.task
+		item := domain.SchedulePlanItem{
+			TaskID:        task.ID,
+			AircraftID:    task.AircraftID,
+			Priority:      task.Priority,
+			TaskUpdatedAt: task.UpdatedAt,
+			OldStartTime:  task.StartTime,
+			OldEndTime:    task.EndTime,
+			OldBayID:      task.BayID,
+			OldMechanicID: task.AssignedMechanicID,
+			Reason:        result.reason,
+		}
+		if item.Priority == "" {
+			item.Priority = domain.PriorityRoutine
+		}
+		if result.placed != nil {
+			start, end := result.placed.StartTime, result.placed.EndTime
+			item.StartTime = &start
+			item.EndTime = &end
+			item.BayID = result.placed.BayID
+			item.BaySlot = result.placed.BaySlot
+			item.MechanicID = result.placed.AssignedMechanicID
+		}
+		plan.Items = append(plan.Items, item)
+	}
+
+	created, err := s.Plans.Create(ctx, plan)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	placedCount := 0
+	for _, item := range created.Items {
+		if item.Placed() {
+			placedCount++
+		}
+	}
+	s.audit(ctx, actor, orgID, created.ID, domain.AuditActionCreate, map[string]any{
+		"tasks":  len(created.Items),
+		"placed": placedCount,
+	})
+	return created, nil
+}
+
+func (s *ScheduleOptimizerService) GetPlan(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	return s.Plans.GetByID(ctx, orgID, id)
+}
+
+func (s *ScheduleOptimizerService) ListPlans(ctx context.Context, actor app.Actor, filter ports.SchedulePlanFilter) ([]domain.SchedulePlan, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Plans.List(ctx, filter)
+}
+
+// ApplyPlan writes a proposed plan onto its tasks in one transaction. It
+// fails as a whole when any task changed since the plan was made or a new
+// booking now clashes with it.
+func (s *ScheduleOptimizerService) ApplyPlan(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.SchedulePlan{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	plan, err := s.Plans.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	if err := plan.CanApply(); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	if err := s.checkMechanics(ctx, plan); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+
+	now := s.Clock.Now().UTC()
+	applied, err := s.Plans.Apply(ctx, plan, actor.UserID, now)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	taskIDs := make([]uuid.UUID, 0, len(applied.Items))
+	for _, item := range applied.Items {
+		if item.Placed() {
+			taskIDs = append(taskIDs, item.TaskID)
+		}
+	}
+	s.audit(ctx, actor, orgID, applied.ID, domain.AuditActionStateChange, map[string]any{
+		"state": string(applied.State),
+		"tasks": taskIDs,
+	})
+	if s.Outbox != nil {
+		_ = s.Outbox.Enqueue(ctx, orgID, "schedule_plan_applied", "schedule_plan", applied.ID, map[string]any{
+			"version":   1,
+			"org_id":    orgID,
+			"plan_id":   applied.ID,
+			"task_ids":  taskIDs,
+			"timestamp": now,
+		}, fmt.Sprintf("schedule_plan_applied:%s", applied.ID))
+	}
+	return applied, nil
+}
+
+func (s *ScheduleOptimizerService) DiscardPlan(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.SchedulePlan{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	discarded, err := s.Plans.Discard(ctx, orgID, id, s.Clock.Now().UTC())
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	s.audit(ctx, actor, orgID, discarded.ID, domain.AuditActionStateChange, map[string]any{
+		"state": string(discarded.State),
+	})
+	return discarded, nil
+}
+
+// prepare collects what the planner needs to know about a task: its
+// dependencies, when its reserved parts are available and which mechanics
+// may work it.
+func (s *ScheduleOptimizerService) prepare(ctx context.Context, orgID uuid.UUID, task domain.MaintenanceTask, now time.Time, qualifiedCache map[string][]uuid.UUID) (*planTask, error) {
+	pt := &planTask{task: task, readyAt: now}
+	if s.Dependencies != nil {
+		deps, err := s.Dependencies.ListByTask(ctx, orgID, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		pt.deps = deps
+	}
+	if err := s.partsReadiness(ctx, orgID, pt, now); err != nil {
+		return nil, err
+	}
+	if s.Certs != nil {
+		var aircraftTypeID *uuid.UUID
+		if s.Aircraft != nil {
+			aircraft, err := s.Aircraft.GetByID(ctx, orgID, task.AircraftID)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return nil, err
+			}
+			aircraftTypeID = aircraft.AircraftTypeID
+		}
+		key := string(task.Type)
+		if aircraftTypeID != nil {
+			key += ":" + aircraftTypeID.String()
+		}
+		qualified, ok := qualifiedCache[key]
+		if !ok {
+			var err error
+			qualified, err = s.Certs.GetQualifiedMechanics(ctx, orgID, task.Type, aircraftTypeID)
+			if err != nil {
+				return nil, err
+			}
+			if qualified == nil {
+				qualified = []uuid.UUID{}
+			}
+			qualifiedCache[key] = qualified
+		}
+		pt.qualified = qualified
+		if len(qualified) == 0 && pt.blocked == "" {
+			pt.blocked = "no mechanic is qualified for the task"
+		}
+	}
+	return pt, nil
+}
+
+// partsReadiness sets when the task's reserved parts can be on hand. A part
+// that is no longer in stock is expected after its definition's lead time;
+// one that expires bounds the task's end.
+func (s *ScheduleOptimizerService) partsReadiness(ctx context.Context, orgID uuid.UUID, pt *planTask, now time.Time) error {
+	if s.Reservations == nil || s.PartItems == nil {
+		return nil
+	}
+	reservations, err := s.Reservations.ListByTask(ctx, orgID, pt.task.ID)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if reservation.State != domain.ReservationReserved {
+			continue
+		}
+		item, err := s.PartItems.GetByID(ctx, orgID, reservation.PartItemID)
+		if errors.Is(err, domain.ErrNotFound) {
+			pt.blocked = fmt.Sprintf("reserved part %s no longer exists", reservation.PartItemID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if item.ExpiryDate != nil && (pt.latestEnd == nil || item.ExpiryDate.Before(*pt.latestEnd)) {
+			expiry := *item.ExpiryDate
+			pt.latestEnd = &expiry
+		}
+		if item.Status == domain.PartItemInStock {
+			continue
+		}
+		var leadDays *int
+		if s.PartDefinitions != nil {
+			def, err := s.PartDefinitions.GetByID(ctx, orgID, item.DefinitionID)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+			leadDays = def.LeadTimeDays
+		}
+		if leadDays == nil {
+			pt.blocked = fmt.Sprintf("reserved part %s is not in stock and has no lead time", item.SerialNumber)
+			continue
+		}
+		ready := now.Add(time.Duration(*leadDays) * 24 * time.Hour)
+		if ready.After(pt.readyAt) {
+			pt.readyAt = ready
+		}
+	}
+	return nil
+}
+
+// linkDependents bounds a planned task by the open tasks outside the plan
+// that depend on it, so moving it never breaks their dependency.
+func (s *ScheduleOptimizerService) linkDependents(ctx context.Context, orgID uuid.UUID, pt *planTask, planned map[uuid.UUID]bool, lookup func(uuid.UUID) (domain.MaintenanceTask, bool)) error {
+	if s.Dependencies == nil {
+		return nil
+	}
+	dependents, err := s.Dependencies.ListDependents(ctx, orgID, pt.task.ID)
+	if err != nil {
+		return err
+	}
+	for _, dep := range dependents {
+		if planned[dep.TaskID] {
+			continue
+		}
+		dependent, ok := lookup(dep.TaskID)
+		if !ok || !dependent.IsActive() {
+			continue
+		}
+		switch dep.DependencyType {
+		case domain.DependencyStartToStart:
+			pt.boundStart(dependent.StartTime)
+		case domain.DependencyFinishToFinish:
+			pt.boundEnd(dependent.EndTime)
+		default:
+			pt.boundEnd(dependent.StartTime)
+		}
+	}
+	return nil
+}
+
+// loadBays fetches the bays the planned tasks are booked into and the
+// capacity windows of every bay the planner may use.
+func (s *ScheduleOptimizerService) loadBays(ctx context.Context, orgID uuid.UUID, planner *schedulePlanner, planTasks []*planTask) error {
+	for _, pt := range planTasks {
+		if pt.task.BayID == nil {
+			continue
+		}
+		if _, ok := planner.bays[*pt.task.BayID]; ok {
+			continue
+		}
+		if s.Capacity == nil {
+			return domain.NewValidationError("hangar capacity unavailable")
+		}
+		bay, err := s.Capacity.Bays.GetByID(ctx, orgID, *pt.task.BayID)
+		if err != nil {
+			return err
+		}
+		planner.bays[bay.ID] = bay
+	}
+	for bayID := range planner.bays {
+		windows, err := s.Capacity.Windows.ListOverlapping(ctx, orgID, bayID, planner.from, planner.to)
+		if err != nil {
+			return err
+		}
+		planner.windows[bayID] = windows
+	}
+	return nil
+}
+
+// loadMechanicWindows fetches the working time of every mechanic the
+// planner may assign.
+func (s *ScheduleOptimizerService) loadMechanicWindows(ctx context.Context, orgID uuid.UUID, planner *schedulePlanner, planTasks []*planTask) error {
+	if s.Roster == nil {
+		return nil
+	}
+	load := func(mechanicID uuid.UUID) error {
+		if _, ok := planner.mechanicWindows[mechanicID]; ok {
+			return nil
+		}
+		windows, err := s.Roster.WorkingWindows(ctx, orgID, mechanicID, planner.from, planner.to)
+		if err != nil {
+			return err
+		}
+		planner.mechanicWindows[mechanicID] = windows
+		return nil
+	}
+	for _, pt := range planTasks {
+		if pt.task.AssignedMechanicID != nil {
+			if err := load(*pt.task.AssignedMechanicID); err != nil {
+				return err
+			}
+		}
+		for _, mechanicID := range pt.qualified {
+			if err := load(mechanicID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadGroundWindows fetches the ground time of the planned tasks' aircraft
+// over the horizon.
+func (s *ScheduleOptimizerService) loadGroundWindows(ctx context.Context, orgID uuid.UUID, planner *schedulePlanner, planTasks []*planTask) error {
+	if s.Flights == nil {
+		return nil
+	}
+	aircraft := make(map[uuid.UUID]bool, len(planTasks))
+	for _, pt := range planTasks {
+		aircraft[pt.task.AircraftID] = true
+	}
+	windows, err := s.Flights.AircraftGroundWindows(ctx, orgID, mapKeys(aircraft), planner.from, planner.to)
+	if err != nil {
+		return err
+	}
+	planner.groundWindows = windows
+	return nil
+}
+
+// loadBayStations resolves the station code of each bay the planner may
+// use, so ground windows can be matched to the bay's station.
+func (s *ScheduleOptimizerService) loadBayStations(ctx context.Context, orgID uuid.UUID, planner *schedulePlanner) error {
+	if len(planner.groundWindows) == 0 || s.Capacity == nil {
+		return nil
+	}
+	codes := make(map[uuid.UUID]string)
+	for bayID, bay := range planner.bays {
+		code, ok := codes[bay.StationID]
+		if !ok {
+			station, err := s.Capacity.Stations.GetByID(ctx, orgID, bay.StationID)
+			if err != nil {
+				return err
+			}
+			code = station.Code
+			codes[bay.StationID] = code
+		}
+		planner.bayStations[bayID] = code
+	}
+	return nil
+}
+
+// checkMechanics rejects a plan when a mechanic it assigns has since been
+// booked on another task in the same window, or is no longer working then.
+func (s *ScheduleOptimizerService) checkMechanics(ctx context.Context, plan domain.SchedulePlan) error {
+	inPlan := make(map[uuid.UUID]bool, len(plan.Items))
+	var from, to time.Time
+	for _, item := range plan.Items {
+		inPlan[item.TaskID] = true
+		if !item.Placed() || item.MechanicID == nil {
+			continue
+		}
+		if from.IsZero() || item.StartTime.Before(from) {
+			from = *item.StartTime
+		}
+		if item.EndTime.After(to) {
+			to = *item.EndTime
+		}
+	}
+	if from.IsZero() {
+		return nil
+	}
+	active, err := s.activeTasks(ctx, plan.OrgID, from, to)
+	if err != nil {
+		return err
+	}
+	for _, item := range plan.Items {
+		if !item.Placed() || item.MechanicID == nil {
+			continue
+		}
+		for _, other := range active {
+			if inPlan[other.ID] || other.AssignedMechanicID == nil || *other.AssignedMechanicID != *item.MechanicID {
+				continue
+			}
+			if other.Overlaps(*item.StartTime, *item.EndTime) {
+				return domain.NewConflictError(fmt.Sprintf("mechanic %s is now booked on task %s", *item.MechanicID, other.ID))
+			}
+		}
+		if s.Roster != nil {
+			windows, err := s.Roster.WorkingWindows(ctx, plan.OrgID, *item.MechanicID, *item.StartTime, *item.EndTime)
+			if err != nil {
+				return err
+			}
+			if !domain.WindowsCover(windows, *item.StartTime, *item.EndTime) {
+				return domain.NewConflictError(fmt.Sprintf("mechanic %s is no longer working during task %s", *item.MechanicID, item.TaskID))
+			}
+		}
+	}
+	return nil
+}
+
+// activeTasks pages through the org's open tasks whose window intersects
+// [from, to).
+func (s *ScheduleOptimizerService) activeTasks(ctx context.Context, orgID uuid.UUID, from, to time.Time) ([]domain.MaintenanceTask, error) {
+	filter := ports.TaskFilter{OrgID: &orgID, ActiveOnly: true, OverlapFrom: &from, OverlapTo: &to, Limit: capacityPageSize}
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += capacityPageSize {
+		filter.Offset = offset
+		page, err := s.Tasks.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < capacityPageSize {
+			break
+		}
+	}
+	return tasks, nil
+}
+
+func (s *ScheduleOptimizerService) audit(ctx context.Context, actor app.Actor, orgID, planID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: "schedule_plan",
+		EntityID:   planID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+// planTask is a task being planned together with the constraints gathered
+// for it.
+type planTask struct {
+	task domain.MaintenanceTask
+	deps []domain.TaskDependency
+	// readyAt is the earliest start its reserved parts allow.
+	readyAt time.Time
+	// latestStart and latestEnd come from part expiry and from dependents
+	// outside the plan.
+	latestStart *time.Time
+	latestEnd   *time.Time
+	// qualified lists the mechanics allowed on the task; nil when
+	// qualifications are not checked.
+	qualified []uuid.UUID
+	// blocked explains why the task cannot be planned at all.
+	blocked string
+}
+
+func (pt *planTask) boundStart(at time.Time) {
+	if pt.latestStart == nil || at.Before(*pt.latestStart) {
+		pt.latestStart = &at
+	}
+}
+
+func (pt *planTask) boundEnd(at time.Time) {
+	if pt.latestEnd == nil || at.Before(*pt.latestEnd) {
+		pt.latestEnd = &at
+	}
+}
+
+func (pt *planTask) isQualified(mechanicID uuid.UUID) bool {
+	if pt.qualified == nil {
+		return true
+	}
+	for _, id := range pt.qualified {
+		if id == mechanicID {
+			return true
+		}
+	}
+	return false
+}
+
+// atRisk reports whether the task, as currently scheduled, misses a
+// mechanic, a qualification, its parts or a dependency, or lacks a bay when
+// a station is being planned.
+func (pt *planTask) atRisk(lookup func(uuid.UUID) (domain.MaintenanceTask, bool), needsBay bool) bool {
+	task := pt.task
+	if pt.blocked != "" || task.AssignedMechanicID == nil || !pt.isQualified(*task.AssignedMechanicID) {
+		return true
+	}
+	if needsBay && task.BayID == nil {
+		return true
+	}
+	if task.StartTime.Before(pt.readyAt) || (pt.latestEnd != nil && task.EndTime.After(*pt.latestEnd)) {
+		return true
+	}
+	for _, dep := range pt.deps {
+		prereq, ok := lookup(dep.DependsOnTaskID)
+		if !ok || !prereq.IsActive() {
+			continue
+		}
+		if task.StartTime.Before(dependencyBound(dep, prereq, task.EndTime.Sub(task.StartTime))) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyBound returns the earliest start a dependency on prereq allows
+// for a task of the given duration.
+func dependencyBound(dep domain.TaskDependency, prereq domain.MaintenanceTask, duration time.Duration) time.Time {
+	switch dep.DependencyType {
+	case domain.DependencyStartToStart:
+		return prereq.StartTime
+	case domain.DependencyFinishToFinish:
+		return prereq.EndTime.Add(-duration)
+	default:
+		return prereq.EndTime
+	}
+}
+
+func sortPlanTasks(tasks []*planTask) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].task, tasks[j].task
+		if a.Priority.Rank() != b.Priority.Rank() {
+			return a.Priority.Rank() > b.Priority.Rank()
+		}
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+}
+
+// orderPlanTasks sorts the tasks so every task comes after the planned
+// tasks it depends on, picking the highest priority among the ready ones.
+func orderPlanTasks(tasks []*planTask, planned map[uuid.UUID]bool) []*planTask {
+	waiting := make(map[uuid.UUID]int, len(tasks))
+	dependents := make(map[uuid.UUID][]*planTask)
+	var ready []*planTask
+	for _, pt := range tasks {
+		for _, dep := range pt.deps {
+			if planned[dep.DependsOnTaskID] {
+				waiting[pt.task.ID]++
+				dependents[dep.DependsOnTaskID] = append(dependents[dep.DependsOnTaskID], pt)
+			}
+		}
+		if waiting[pt.task.ID] == 0 {
+			ready = append(ready, pt)
+		}
+	}
+	ordered := make([]*planTask, 0, len(tasks))
+	done := make(map[uuid.UUID]bool, len(tasks))
+	for len(ready) > 0 {
+		sortPlanTasks(ready)
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, next)
+		done[next.task.ID] = true
+		for _, dependent := range dependents[next.task.ID] {
+			waiting[dependent.task.ID]--
+			if waiting[dependent.task.ID] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	// Dependency cycles are rejected when dependencies are created; keep
+	// any leftovers rather than dropping them.
+	for _, pt := range tasks {
+		if !done[pt.task.ID] {
+			ordered = append(ordered, pt)
+		}
+	}
+	return ordered
+}
+
+type planResult struct {
+	pt     *planTask
+	placed *domain.MaintenanceTask
+	reason string
+}
+
+// schedulePlanner places tasks against the bookings that stay where they
+// are. Placed tasks become bookings for the tasks after them.
+type schedulePlanner struct {
+	from        time.Time
+	to          time.Time
+	fixed       []domain.MaintenanceTask
+	bays        map[uuid.UUID]domain.HangarBay
+	windows     map[uuid.UUID][]domain.BayCapacityWindow
+	stationBays []uuid.UUID
+	// mechanicWindows holds the working time of rostered or absent
+	// mechanics; a mechanic missing from it can work at any time.
+	mechanicWindows map[uuid.UUID][]domain.TimeWindow
+	// groundWindows holds the ground time of aircraft with a flight
+	// schedule; an aircraft missing from it is always available.
+	groundWindows map[uuid.UUID][]domain.GroundWindow
+	// stationCode is the code of the planned station and bayStations the
+	// station code of each bay, for matching ground windows.
+	stationCode string
+	bayStations map[uuid.UUID]string
+
+	booked []domain.MaintenanceTask
+}
+
+func newSchedulePlanner(from, to time.Time) *schedulePlanner {
+	return &schedulePlanner{
+		from:            from,
+		to:              to,
+		bays:            make(map[uuid.UUID]domain.HangarBay),
+		windows:         make(map[uuid.UUID][]domain.BayCapacityWindow),
+		mechanicWindows: make(map[uuid.UUID][]domain.TimeWindow),
+		bayStations:     make(map[uuid.UUID]string),
+	}
+}
+
+// run places the ordered tasks. A task left unplaced keeps its current
+// window, so when that window clashes with a task placed before it the run
+// is repeated with the unplaced task pinned in place.
+func (p *schedulePlanner) run(ordered []*planTask, lookup func(uuid.UUID) (domain.MaintenanceTask, bool)) []planResult {
+	pinned := make(map[uuid.UUID]bool)
+	for _, pt := range ordered {
+		if pt.blocked != "" {
+			pinned[pt.task.ID] = true
+		}
+	}
+	for {
+		results := p.pass(ordered, pinned, lookup)
+		clashed := false
+		for _, result := range results {
+			if result.placed != nil || pinned[result.pt.task.ID] {
+				continue
+			}
+			for _, other := range results {
+				if other.placed != nil && bookingsClash(result.pt.task, *other.placed) {
+					pinned[result.pt.task.ID] = true
+					clashed = true
+					break
+				}
+			}
+		}
+		if !clashed {
+			return results
+		}
+	}
+}
+
+func (p *schedulePlanner) pass(ordered []*planTask, pinned map[uuid.UUID]bool, lookup func(uuid.UUID) (domain.MaintenanceTask, bool)) []planResult {
+	p.booked = append(p.booked[:0], p.fixed...)
+	for _, pt := range ordered {
+		if pinned[pt.task.ID] && pt.task.IsActive() {
+			p.booked = append(p.booked, pt.task)
+		}
+	}
+	placedAt := make(map[uuid.UUID]domain.MaintenanceTask, len(ordered))
+	results := make([]planResult, 0, len(ordered))
+	for _, pt := range ordered {
+		if pinned[pt.task.ID] {
+			reason := pt.blocked
+			if reason == "" {
+				reason = "its current window is needed by other planned tasks and no other window fits"
+			}
+			results = append(results, planResult{pt: pt, reason: reason})
+			continue
+		}
+		earliest := p.from
+		if pt.readyAt.After(earliest) {
+			earliest = pt.readyAt
+		}
+		duration := pt.task.EndTime.Sub(pt.task.StartTime)
+		for _, dep := range pt.deps {
+			prereq, ok := placedAt[dep.DependsOnTaskID]
+			if !ok {
+				prereq, ok = lookup(dep.DependsOnTaskID)
+			}
+			if !ok || !prereq.IsActive() {
+				continue
+			}
+			if bound := dependencyBound(dep, prereq, duration); bound.After(earliest) {
+				earliest = bound
+			}
+		}
+		placed, reason := p.place(pt, earliest)
+		if placed == nil {
+			results = append(results, planResult{pt: pt, reason: reason})
+			continue
+		}
+		placedAt[placed.ID] = *placed
+		p.booked = append(p.booked, *placed)
+		results = append(results, planResult{pt: pt, placed: placed})
+	}
+	return results
+}
+
+// place finds the earliest start from earliest at which the aircraft, a bay
+// slot and a mechanic are all free for the task's duration.
+func (p *schedulePlanner) place(pt *planTask, earliest time.Time) (*domain.MaintenanceTask, string) {
+	duration := pt.task.EndTime.Sub(pt.task.StartTime)
+	start := earliest
+	blockedBy := ""
+	for {
+		end := start.Add(duration)
+		if pt.latestStart != nil && start.After(*pt.latestStart) {
+			return nil, "no window opens before a dependent task must start"
+		}
+		if pt.latestEnd != nil && end.After(*pt.latestEnd) {
+			return nil, "no window ends before a reserved part expires or a dependent task needs it"
+		}
+		if end.After(p.to) {
+			if blockedBy == "" {
+				return nil, "the task does not fit before the end of the horizon"
+			}
+			return nil, fmt.Sprintf("no free %s before the end of the horizon", blockedBy)
+		}
+
+		var next time.Time
+		later := func(at time.Time, what string) {
+			if at.After(next) {
+				next = at
+				blockedBy = what
+			}
+		}
+		if at, busy := p.aircraftBusy(pt.task, start, end); busy {
+			later(at, "aircraft window")
+		}
+		if at, onGround := p.onGround(pt.task, start, end, p.taskStation(pt.task)); !onGround {
+			if at.IsZero() {
+				return nil, "the aircraft is not on the ground long enough before the end of the horizon"
+			}
+			later(at, "ground window")
+		}
+		bayID, slot, bayFree, bayAt := p.freeBay(pt.task, start, end)
+		if !bayFree {
+			if bayAt.IsZero() {
+				return nil, "no bay of the station can take the task"
+			}
+			later(bayAt, "bay slot")
+		}
+		mechanicID, mechanicFree, mechanicAt := p.freeMechanic(pt, start, end)
+		if !mechanicFree {
+			if mechanicAt.IsZero() {
+				return nil, "no qualified mechanic works before the end of the horizon"
+			}
+			later(mechanicAt, "qualified mechanic")
+		}
+		if next.IsZero() {
+			placed := pt.task
+			placed.StartTime = start
+			placed.EndTime = end
+			placed.BayID = bayID
+			placed.BaySlot = slot
+			placed.AssignedMechanicID = mechanicID
+			return &placed, ""
+		}
+		start = next
+	}
+}
+
+// aircraftBusy reports whether the aircraft is booked in [start, end) and
+// when the clashing booking ends.
+func (p *schedulePlanner) aircraftBusy(task domain.MaintenanceTask, start, end time.Time) (time.Time, bool) {
+	var until time.Time
+	busy := false
+	for _, other := range p.booked {
+		if other.ID == task.ID || other.AircraftID != task.AircraftID || !other.Overlaps(start, end) {
+			continue
+		}
+		if !busy || other.EndTime.Before(until) {
+			until = other.EndTime
+		}
+		busy = true
+	}
+	return until, busy
+}
+
+// onGround reports whether the aircraft is on the ground for all of
+// [start, end), at the station with the given code when one is given. When
+// it is not, it returns the start of the next window long enough for the
+// task, or the zero time if there is none.
+func (p *schedulePlanner) onGround(task domain.MaintenanceTask, start, end time.Time, station string) (time.Time, bool) {
+	windows, ok := p.groundWindows[task.AircraftID]
+	if !ok {
+		return time.Time{}, true
+	}
+	duration := end.Sub(start)
+	for _, window := range windows {
+		if !window.AtStation(station) {
+			continue
+		}
+		if window.Covers(start, end) {
+			return time.Time{}, true
+		}
+		if window.Start.After(start) && window.Duration() >= duration {
+			return window.Start, false
+		}
+	}
+	return time.Time{}, false
+}
+
+// taskStation returns the station code the task must be on the ground at:
+// that of its bay, or of the planned station. It is empty when unknown.
+func (p *schedulePlanner) taskStation(task domain.MaintenanceTask) string {
+	if task.BayID != nil {
+		return p.bayStations[*task.BayID]
+	}
+	return p.stationCode
+}
+
+// freeBay picks a bay and slot for [start, end): the task's own bay when it
+// has one, otherwise any bay of the planned station. When none is free it
+// returns the earliest time a booking or capacity window blocking one of
+// them ends.
+func (p *schedulePlanner) freeBay(task domain.MaintenanceTask, start, end time.Time) (*uuid.UUID, *int, bool, time.Time) {
+	options := p.stationBays
+	if task.BayID != nil {
+		options = []uuid.UUID{*task.BayID}
+	}
+	if len(options) == 0 {
+		return nil, nil, true, time.Time{}
+	}
+	var until time.Time
+	earlier := func(at time.Time) {
+		if at.After(start) && (until.IsZero() || at.Before(until)) {
+			until = at
+		}
+	}
+	for _, bayID := range options {
+		bay, ok := p.bays[bayID]
+		if !ok {
+			continue
+		}
+		windows := p.windows[bayID]
+		var booked []domain.MaintenanceTask
+		for _, other := range p.booked {
+			if other.ID != task.ID && other.OccupiesBay(bayID) && other.Overlaps(start, end) {
+				booked = append(booked, other)
+			}
+		}
+		var preferred *int
+		if task.BayID != nil && *task.BayID == bayID {
+			preferred = task.BaySlot
+		}
+		if slot, ok := domain.FreeBaySlot(bay.CapacityDuring(windows, start, end), booked, preferred); ok {
+			id := bayID
+			return &id, &slot, true, time.Time{}
+		}
+		for _, other := range booked {
+			earlier(other.EndTime)
+		}
+		for _, window := range windows {
+			if window.StartTime.Before(end) && window.EndTime.After(start) {
+				earlier(window.EndTime)
+			}
+		}
+	}
+	return nil, nil, false, until
+}
+
+// freeMechanic picks a mechanic free for [start, end). The current mechanic
+// is kept when qualified and free; otherwise the least booked qualified
+// mechanic is taken. When none is free it returns the earliest time one of
+// them is released or starts a shift, or the zero time if none will work
+// again within the horizon.
+func (p *schedulePlanner) freeMechanic(pt *planTask, start, end time.Time) (*uuid.UUID, bool, time.Time) {
+	var options []uuid.UUID
+	current := pt.task.AssignedMechanicID
+	if current != nil && pt.isQualified(*current) {
+		options = append(options, *current)
+	}
+	if pt.qualified != nil {
+		load := make(map[uuid.UUID]int, len(pt.qualified))
+		for _, other := range p.booked {
+			if other.AssignedMechanicID != nil {
+				load[*other.AssignedMechanicID]++
+			}
+		}
+		others := make([]uuid.UUID, 0, len(pt.qualified))
+		for _, id := range pt.qualified {
+			if current == nil || id != *current {
+				others = append(others, id)
+			}
+		}
+		sort.SliceStable(others, func(i, j int) bool {
+			if load[others[i]] != load[others[j]] {
+				return load[others[i]] < load[others[j]]
+			}
+			return others[i].String() < others[j].String()
+		})
+		options = append(options, others...)
+	}
+	if len(options) == 0 {
+		return nil, true, time.Time{}
+	}
+	var until time.Time
+	for _, mechanicID := range options {
+		if windows, ok := p.mechanicWindows[mechanicID]; ok && !domain.WindowsCover(windows, start, end) {
+			for _, window := range windows {
+				if window.Start.After(start) {
+					if until.IsZero() || window.Start.Before(until) {
+						until = window.Start
+					}
+					break
+				}
+			}
+			continue
+		}
+		var released time.Time
+		busy := false
+		for _, other := range p.booked {
+			if other.ID == pt.task.ID || other.AssignedMechanicID == nil || *other.AssignedMechanicID != mechanicID || !other.Overlaps(start, end) {
+				continue
+			}
+			if !busy || other.EndTime.Before(released) {
+				released = other.EndTime
+			}
+			busy = true
+		}
+		if !busy {
+			id := mechanicID
+			return &id, true, time.Time{}
+		}
+		if until.IsZero() || released.Before(until) {
+			until = released
+		}
+	}
+	return nil, false, until
+}
+
+// bookingsClash reports whether two tasks cannot hold their windows at the
+// same time: same aircraft, same bay slot or same mechanic.
+func bookingsClash(a, b domain.MaintenanceTask) bool {
+	if a.ID == b.ID || !a.Overlaps(b.StartTime, b.EndTime) {
+		return false
+	}
+	if a.AircraftID == b.AircraftID {
+		return true
+	}
+	if a.BayID != nil && b.BayID != nil && *a.BayID == *b.BayID && a.BaySlot != nil && b.BaySlot != nil && *a.BaySlot == *b.BaySlot {
+		return true
+	}
+	return a.AssignedMechanicID != nil && b.AssignedMechanicID != nil && *a.AssignedMechanicID == *b.AssignedMechanicID
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app"
@@ -15,8 +18,29 @@ type SchedulingService struct {
 	Tasks          ports.TaskRepository
 	Dependencies   ports.TaskDependencyRepository
 	ScheduleEvents ports.ScheduleChangeRepository
-	Outbox         ports.OutboxRepository
-	Clock          app.Clock
+	// Capacity re-checks aircraft overlap and bay slots for moved tasks.
+	// Optional.
+	Capacity *CapacityService
+	// Roster re-checks the assigned mechanics of moved tasks against their
+	// shifts, absences and other tasks. Optional.
+	Roster *RosterService
+	// Reservations, PartItems, Certs and Aircraft let conflict detection
+	// check reserved parts and mechanics' qualifications. Optional.
+	Reservations ports.PartReservationRepository
+	PartItems    ports.PartItemRepository
+	Certs        ports.CertificationRepository
+	Aircraft     ports.AircraftRepository
+	// Directives lets a reschedule simulation report directive deadlines
+	// the move would breach. Optional.
+	Directives ports.DirectiveRepository
+	// WorkPackages gives critical path analysis and conflict detection
+	// each package's visit end. Optional.
+	WorkPackages ports.WorkPackageRepository
+	// Flights lets conflict detection report tasks during which the
+	// aircraft is planned to fly. Optional.
+	Flights *FlightScheduleService
+	Outbox  ports.OutboxRepository
+	Clock   app.Clock
 }
 
 // --- Task Dependencies ---
@@ -119,61 +143,35 @@ type RescheduleInput struct {
 	NewEndTime   time.Time
 	Reason       string
 	Cascade      bool
+	// AllowFlightOverlap keeps the new windows even though an aircraft is
+	// planned to fly during them.
+	AllowFlightOverlap bool
 }
 
+// RescheduleTask moves a task to a new window. With Cascade, dependents
+// whose dependency the new window would break are moved as well. The tasks
+// and the change event are written together or not at all.
 func (s *SchedulingService) RescheduleTask(ctx context.Context, actor app.Actor, input RescheduleInput) (domain.ScheduleChangeEvent, error) {
-	if s.Clock == nil {
-		s.Clock = app.RealClock{}
-	}
-	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleTenantAdmin {
-		return domain.ScheduleChangeEvent{}, domain.ErrForbidden
-	}
-
-	task, err := s.Tasks.GetByID(ctx, actor.OrgID, input.TaskID)
+	plan, err := s.planReschedule(ctx, actor, input)
 	if err != nil {
 		return domain.ScheduleChangeEvent{}, err
 	}
-
-	if task.State == domain.TaskStateCompleted || task.State == domain.TaskStateCancelled {
-		return domain.ScheduleChangeEvent{}, domain.NewConflictError("cannot reschedule completed or cancelled task")
-	}
-
-	now := s.Clock.Now()
-
-	// Record schedule change event
-	event := domain.ScheduleChangeEvent{
-		ID:           uuid.New(),
-		OrgID:        actor.OrgID,
-		TaskID:       input.TaskID,
-		ChangeType:   domain.ScheduleChangeRescheduled,
-		Reason:       input.Reason,
-		OldStartTime: &task.StartTime,
-		NewStartTime: &input.NewStartTime,
-		OldEndTime:   &task.EndTime,
-		NewEndTime:   &input.NewEndTime,
-		TriggeredBy:  &actor.UserID,
-		CreatedAt:    now,
-	}
-
-	// Update the task times
-	task.StartTime = input.NewStartTime.UTC()
-	task.EndTime = input.NewEndTime.UTC()
-	task.UpdatedAt = now
-
-	if _, err := s.Tasks.Update(ctx, task); err != nil {
+	violations, err := s.movedViolations(ctx, plan.moved, input.AllowFlightOverlap)
+	if err != nil {
 		return domain.ScheduleChangeEvent{}, err
 	}
-
-	// Cascade to dependent tasks if requested
-	if input.Cascade {
-		affectedIDs, err := s.cascadeReschedule(ctx, actor.OrgID, input.TaskID, input.NewEndTime, *event.OldEndTime)
-		if err != nil {
+	if len(violations) > 0 {
+		return domain.ScheduleChangeEvent{}, domain.NewCapacityConflict(violations[0].Kind, violations[0].Message)
+	}
+	if s.Capacity != nil {
+		if err := s.Capacity.ReserveMoved(ctx, plan.moved); err != nil {
 			return domain.ScheduleChangeEvent{}, err
 		}
-		event.AffectedTaskIDs = affectedIDs
 	}
 
-	created, err := s.ScheduleEvents.Create(ctx, event)
+	event := plan.event
+	now := event.CreatedAt
+	created, err := s.ScheduleEvents.Reschedule(ctx, event, plan.moved, now)
 	if err != nil {
 		return domain.ScheduleChangeEvent{}, err
 	}
@@ -198,43 +196,427 @@ func (s *SchedulingService) RescheduleTask(ctx context.Context, actor app.Actor,
 	return created, nil
 }
 
-// cascadeReschedule shifts all downstream dependent tasks by the same time delta
-func (s *SchedulingService) cascadeReschedule(ctx context.Context, orgID, taskID uuid.UUID, newEndTime, oldEndTime time.Time) ([]uuid.UUID, error) {
-	delta := newEndTime.Sub(oldEndTime)
-	if delta == 0 {
-		return nil, nil
-	}
+// TaskMove is one task's window before and after a reschedule.
+type TaskMove struct {
+	TaskID       uuid.UUID
+	OldStartTime time.Time
+	OldEndTime   time.Time
+	NewStartTime time.Time
+	NewEndTime   time.Time
+}
 
-	dependents, err := s.Dependencies.ListDependents(ctx, orgID, taskID)
+// DirectiveBreach is a directive whose deadline a moved task would no
+// longer meet.
+type DirectiveBreach struct {
+	TaskID          uuid.UUID
+	AircraftID      uuid.UUID
+	DirectiveID     uuid.UUID
+	ReferenceNumber string
+	Deadline        time.Time
+	NewEndTime      time.Time
+}
+
+// RescheduleSimulation previews a reschedule: the tasks it would move and
+// the conflicts, capacity violations and directive deadline breaches it
+// would introduce.
+type RescheduleSimulation struct {
+	Moves              []TaskMove
+	Conflicts          []ScheduleConflict
+	CapacityViolations []CapacityViolation
+	DirectiveBreaches  []DirectiveBreach
+}
+
+// SimulateReschedule works out a reschedule exactly as RescheduleTask does
+// but writes nothing and emits no events. Capacity and roster violations are
+// listed rather than returned as an error, as are planned flights the moved
+// tasks would overlap unless AllowFlightOverlap is set.
+func (s *SchedulingService) SimulateReschedule(ctx context.Context, actor app.Actor, input RescheduleInput) (RescheduleSimulation, error) {
+	plan, err := s.planReschedule(ctx, actor, input)
 	if err != nil {
-		return nil, err
+		return RescheduleSimulation{}, err
 	}
+	simulation := RescheduleSimulation{
+		Moves:              make([]TaskMove, 0, len(plan.moved)),
+		Conflicts:          []ScheduleConflict{},
+		CapacityViolations: []CapacityViolation{},
+		DirectiveBreaches:  []DirectiveBreach{},
+	}
+	for _, task := range plan.moved {
+		old := plan.before[task.ID]
+		simulation.Moves = append(simulation.Moves, TaskMove{
+			TaskID:       task.ID,
+			OldStartTime: old.StartTime,
+			OldEndTime:   old.EndTime,
+			NewStartTime: task.StartTime,
+			NewEndTime:   task.EndTime,
+		})
+	}
+	if s.Capacity != nil {
+		violations, err := s.Capacity.CheckMoved(ctx, plan.moved)
+		if err != nil {
+			return RescheduleSimulation{}, err
+		}
+		simulation.CapacityViolations = append(simulation.CapacityViolations, violations...)
+	}
+	if !input.AllowFlightOverlap {
+		violations, err := s.flightViolations(ctx, plan.moved)
+		if err != nil {
+			return RescheduleSimulation{}, err
+		}
+		simulation.CapacityViolations = append(simulation.CapacityViolations, violations...)
+	}
+	if s.Roster != nil {
+		violations, err := s.Roster.CheckMoved(ctx, plan.moved)
+		if err != nil {
+			return RescheduleSimulation{}, err
+		}
+		simulation.CapacityViolations = append(simulation.CapacityViolations, violations...)
+	}
+	conflicts, err := s.newConflicts(ctx, actor.OrgID, plan)
+	if err != nil {
+		return RescheduleSimulation{}, err
+	}
+	simulation.Conflicts = append(simulation.Conflicts, conflicts...)
+	breaches, err := s.directiveBreaches(ctx, actor.OrgID, plan)
+	if err != nil {
+		return RescheduleSimulation{}, err
+	}
+	simulation.DirectiveBreaches = append(simulation.DirectiveBreaches, breaches...)
+	return simulation, nil
+}
 
-	var affected []uuid.UUID
-	for _, dep := range dependents {
-		depTask, err := s.Tasks.GetByID(ctx, orgID, dep.TaskID)
+// OverlappingFlights returns the planned flights the rescheduled task and
+// the dependents moved with it overlap, for warning about reschedules made
+// with AllowFlightOverlap.
+func (s *SchedulingService) OverlappingFlights(ctx context.Context, event domain.ScheduleChangeEvent) ([]domain.PlannedFlight, error) {
+	if s.Flights == nil {
+		return nil, nil
+	}
+	seen := make(map[uuid.UUID]bool)
+	var flights []domain.PlannedFlight
+	for _, id := range append([]uuid.UUID{event.TaskID}, event.AffectedTaskIDs...) {
+		task, err := s.Tasks.GetByID(ctx, event.OrgID, id)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		if depTask.State == domain.TaskStateCompleted || depTask.State == domain.TaskStateCancelled {
+		if !task.IsActive() {
 			continue
 		}
+		overlapping, err := s.Flights.FlightsDuring(ctx, task.OrgID, []uuid.UUID{task.AircraftID}, task.StartTime, task.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		for _, flight := range overlapping {
+			if !seen[flight.ID] {
+				seen[flight.ID] = true
+				flights = append(flights, flight)
+			}
+		}
+	}
+	return flights, nil
+}
 
-		depTask.StartTime = depTask.StartTime.Add(delta)
-		depTask.EndTime = depTask.EndTime.Add(delta)
-		if _, err := s.Tasks.Update(ctx, depTask); err != nil {
+// movedViolations lists the planned flights, unless allowFlightOverlap is
+// set, and the roster gaps that tasks at new windows would run into.
+func (s *SchedulingService) movedViolations(ctx context.Context, tasks []domain.MaintenanceTask, allowFlightOverlap bool) ([]CapacityViolation, error) {
+	var violations []CapacityViolation
+	if !allowFlightOverlap {
+		flights, err := s.flightViolations(ctx, tasks)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, flights...)
+	}
+	if s.Roster != nil {
+		roster, err := s.Roster.CheckMoved(ctx, tasks)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, roster...)
+	}
+	return violations, nil
+}
+
+// flightViolations lists the moved tasks whose aircraft is planned to fly
+// during their new window. Without a flight schedule any window is
+// accepted.
+func (s *SchedulingService) flightViolations(ctx context.Context, tasks []domain.MaintenanceTask) ([]CapacityViolation, error) {
+	if s.Flights == nil {
+		return nil, nil
+	}
+	var violations []CapacityViolation
+	for _, task := range tasks {
+		err := s.Flights.CheckTask(ctx, task)
+		var conflict *domain.CapacityConflictError
+		if errors.As(err, &conflict) {
+			violations = append(violations, CapacityViolation{TaskID: task.ID, Kind: conflict.Kind, Message: conflict.Message})
 			continue
 		}
-		affected = append(affected, dep.TaskID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return violations, nil
+}
+
+// reschedulePlan is a reschedule worked out but not yet written.
+type reschedulePlan struct {
+	event domain.ScheduleChangeEvent
+	// moved holds the task and its cascaded dependents at their new
+	// windows, the task first; before holds them as stored.
+	moved  []domain.MaintenanceTask
+	before map[uuid.UUID]domain.MaintenanceTask
+}
+
+// planReschedule validates the reschedule and works out the cascade. Both
+// the real run and the simulation start from it.
+func (s *SchedulingService) planReschedule(ctx context.Context, actor app.Actor, input RescheduleInput) (reschedulePlan, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleAdmin && actor.Role != domain.RoleTenantAdmin {
+		return reschedulePlan{}, domain.ErrForbidden
+	}
+
+	task, err := s.Tasks.GetByID(ctx, actor.OrgID, input.TaskID)
+	if err != nil {
+		return reschedulePlan{}, err
+	}
+
+	if task.State == domain.TaskStateCompleted || task.State == domain.TaskStateCancelled {
+		return reschedulePlan{}, domain.NewConflictError("cannot reschedule completed or cancelled task")
+	}
 
-		// Recursively cascade
-		childAffected, err := s.cascadeReschedule(ctx, orgID, dep.TaskID, depTask.EndTime, depTask.EndTime.Add(-delta))
-		if err == nil {
-			affected = append(affected, childAffected...)
+	plan := reschedulePlan{before: map[uuid.UUID]domain.MaintenanceTask{task.ID: task}}
+	oldStart, oldEnd := task.StartTime, task.EndTime
+	task.StartTime = input.NewStartTime.UTC()
+	task.EndTime = input.NewEndTime.UTC()
+	if err := task.ValidateCreate(); err != nil {
+		return reschedulePlan{}, err
+	}
+
+	// Record schedule change event
+	plan.event = domain.ScheduleChangeEvent{
+		ID:           uuid.New(),
+		OrgID:        actor.OrgID,
+		TaskID:       input.TaskID,
+		ChangeType:   domain.ScheduleChangeRescheduled,
+		Reason:       input.Reason,
+		OldStartTime: &oldStart,
+		NewStartTime: &task.StartTime,
+		OldEndTime:   &oldEnd,
+		NewEndTime:   &task.EndTime,
+		TriggeredBy:  &actor.UserID,
+		CreatedAt:    s.Clock.Now(),
+	}
+
+	plan.moved = []domain.MaintenanceTask{task}
+	if input.Cascade {
+		dependents, err := s.cascadeReschedule(ctx, actor.OrgID, task, plan.before)
+		if err != nil {
+			return reschedulePlan{}, err
+		}
+		for _, dependent := range dependents {
+			plan.event.AffectedTaskIDs = append(plan.event.AffectedTaskIDs, dependent.ID)
 		}
+		plan.moved = append(plan.moved, dependents...)
 	}
+	return plan, nil
+}
 
-	return affected, nil
+// newConflicts runs conflict detection over the window the plan touches,
+// once as stored and once with the plan applied, and returns the conflicts
+// only the latter has.
+func (s *SchedulingService) newConflicts(ctx context.Context, orgID uuid.UUID, plan reschedulePlan) ([]ScheduleConflict, error) {
+	from, to := plan.moved[0].StartTime, plan.moved[0].EndTime
+	for _, task := range plan.moved {
+		for _, window := range []domain.MaintenanceTask{task, plan.before[task.ID]} {
+			if window.StartTime.Before(from) {
+				from = window.StartTime
+			}
+			if window.EndTime.After(to) {
+				to = window.EndTime
+			}
+		}
+	}
+	stored, err := s.conflictTasks(ctx, orgID, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+	moved := make(map[uuid.UUID]domain.MaintenanceTask, len(plan.moved))
+	for _, task := range plan.moved {
+		moved[task.ID] = task
+	}
+	after := make([]domain.MaintenanceTask, 0, len(stored)+len(moved))
+	for _, task := range stored {
+		if _, ok := moved[task.ID]; !ok {
+			after = append(after, task)
+		}
+	}
+	after = append(after, plan.moved...)
+
+	existing, err := s.detect(ctx, orgID, stored)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, conflict := range existing {
+		seen[conflictKey(conflict)] = true
+	}
+	conflicts, err := s.detect(ctx, orgID, after)
+	if err != nil {
+		return nil, err
+	}
+	var introduced []ScheduleConflict
+	for _, conflict := range conflicts {
+		if !seen[conflictKey(conflict)] {
+			introduced = append(introduced, conflict)
+		}
+	}
+	return introduced, nil
+}
+
+// conflictKey identifies a conflict independently of the task's window, so
+// the same conflict before and after a move compares equal.
+func conflictKey(conflict ScheduleConflict) string {
+	blocking := make([]string, 0, len(conflict.BlockingTaskIDs))
+	for _, id := range conflict.BlockingTaskIDs {
+		blocking = append(blocking, id.String())
+	}
+	sort.Strings(blocking)
+	key := fmt.Sprintf("%s|%s|%s|%v", conflict.TaskID, conflict.ConflictType, conflict.DependencyType, blocking)
+	for _, id := range []*uuid.UUID{conflict.MechanicID, conflict.BayID, conflict.PartItemID, conflict.WorkPackageID} {
+		if id != nil {
+			key += "|" + id.String()
+		}
+	}
+	return key
+}
+
+// directiveBreaches lists the open directive compliance records linked to a
+// moved task whose deadline the task met before the move but no longer
+// does.
+func (s *SchedulingService) directiveBreaches(ctx context.Context, orgID uuid.UUID, plan reschedulePlan) ([]DirectiveBreach, error) {
+	if s.Directives == nil {
+		return nil, nil
+	}
+	byAircraft := make(map[uuid.UUID]map[uuid.UUID]domain.MaintenanceTask)
+	for _, task := range plan.moved {
+		if byAircraft[task.AircraftID] == nil {
+			byAircraft[task.AircraftID] = make(map[uuid.UUID]domain.MaintenanceTask)
+		}
+		byAircraft[task.AircraftID][task.ID] = task
+	}
+
+	directives := make(map[uuid.UUID]domain.ComplianceDirective)
+	var breaches []DirectiveBreach
+	for aircraftID, tasks := range byAircraft {
+		filter := ports.AircraftComplianceFilter{OrgID: &orgID, AircraftID: &aircraftID, Limit: conflictBatchSize}
+		for offset := 0; ; offset += conflictBatchSize {
+			filter.Offset = offset
+			records, err := s.Directives.ListAircraftCompliance(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				if record.TaskID == nil || record.Status == domain.ComplianceStatusCompliant || record.Status == domain.ComplianceStatusNotApplicable {
+					continue
+				}
+				task, ok := tasks[*record.TaskID]
+				if !ok {
+					continue
+				}
+				directive, ok := directives[record.DirectiveID]
+				if !ok {
+					var err error
+					directive, err = s.Directives.GetDirectiveByID(ctx, record.DirectiveID)
+					if err != nil && !errors.Is(err, domain.ErrNotFound) {
+						return nil, err
+					}
+					directives[record.DirectiveID] = directive
+				}
+				deadline := record.NextDueDate
+				if deadline == nil {
+					deadline = directive.ComplianceDeadline
+				}
+				if deadline == nil || !task.EndTime.After(*deadline) || plan.before[task.ID].EndTime.After(*deadline) {
+					continue
+				}
+				breaches = append(breaches, DirectiveBreach{
+					TaskID:          task.ID,
+					AircraftID:      aircraftID,
+					DirectiveID:     record.DirectiveID,
+					ReferenceNumber: directive.ReferenceNumber,
+					Deadline:        *deadline,
+					NewEndTime:      task.EndTime,
+				})
+			}
+			if len(records) < conflictBatchSize {
+				break
+			}
+		}
+	}
+	return breaches, nil
+}
+
+// cascadeReschedule works out which dependents of root its new window
+// breaks, directly or through other moved dependents, and moves each later
+// by the least amount that satisfies its dependency type. Dependents whose
+// constraints still hold stay put. Nothing is written; the moved tasks are
+// returned in the order they were first moved, and their stored versions
+// are added to before.
+func (s *SchedulingService) cascadeReschedule(ctx context.Context, orgID uuid.UUID, root domain.MaintenanceTask, before map[uuid.UUID]domain.MaintenanceTask) ([]domain.MaintenanceTask, error) {
+	current := map[uuid.UUID]domain.MaintenanceTask{root.ID: root}
+	var order []uuid.UUID
+	queue := []uuid.UUID{root.ID}
+	for len(queue) > 0 {
+		prereq := current[queue[0]]
+		queue = queue[1:]
+
+		dependents, err := s.Dependencies.ListDependents(ctx, orgID, prereq.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range dependents {
+			task, ok := current[dep.TaskID]
+			if !ok {
+				task, err = s.Tasks.GetByID(ctx, orgID, dep.TaskID)
+				if errors.Is(err, domain.ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			if task.State == domain.TaskStateCompleted || task.State == domain.TaskStateCancelled {
+				continue
+			}
+			shift := dep.Shift(prereq, task)
+			if shift == 0 {
+				continue
+			}
+			if task.ID == root.ID {
+				return nil, domain.NewConflictError("circular dependency detected")
+			}
+			if task.State != domain.TaskStateScheduled {
+				return nil, domain.NewConflictError(fmt.Sprintf("dependent task %s is already in progress and cannot be moved", task.ID))
+			}
+			if _, seen := current[task.ID]; !seen {
+				order = append(order, task.ID)
+				before[task.ID] = task
+			}
+			task.StartTime = task.StartTime.Add(shift)
+			task.EndTime = task.EndTime.Add(shift)
+			current[task.ID] = task
+			queue = append(queue, task.ID)
+		}
+	}
+
+	moved := make([]domain.MaintenanceTask, 0, len(order))
+	for _, id := range order {
+		moved = append(moved, current[id])
+	}
+	return moved, nil
 }
 
 // --- Schedule Change History ---
@@ -245,56 +627,948 @@ func (s *SchedulingService) ListScheduleChanges(ctx context.Context, actor app.A
 
 // --- Conflict Detection ---
 
+// conflictBatchSize bounds each batched read made by conflict detection.
+const conflictBatchSize = 200
+
+const (
+	conflictUnmetDependency     = "unmet_dependency"
+	conflictAircraftOverlap     = "aircraft_overlap"
+	conflictMechanicOverlap     = "mechanic_double_booked"
+	conflictBayCapacity         = "bay_capacity"
+	conflictPartExpired         = "part_expired"
+	conflictPartUnavailable     = "part_unavailable"
+	conflictQualificationLapsed = "qualification_lapsed"
+	conflictReturnToService     = "return_to_service_at_risk"
+	conflictFlightOverlap       = "flight_overlap"
+)
+
 type ScheduleConflict struct {
-	TaskID          uuid.UUID `json:"task_id"`
-	ConflictType    string    `json:"conflict_type"`
-	Description     string    `json:"description"`
+	TaskID         uuid.UUID               `json:"task_id"`
+	ConflictType   string                  `json:"conflict_type"`
+	Severity       domain.ConflictSeverity `json:"severity"`
+	Description    string                  `json:"description"`
+	StartTime      time.Time               `json:"start_time"`
+	DependencyType domain.DependencyType   `json:"dependency_type,omitempty"`
+	// BlockingTaskIDs lists the prerequisite or overlapping tasks.
 	BlockingTaskIDs []uuid.UUID `json:"blocking_task_ids,omitempty"`
+	MechanicID      *uuid.UUID  `json:"mechanic_id,omitempty"`
+	BayID           *uuid.UUID  `json:"bay_id,omitempty"`
+	PartItemID      *uuid.UUID  `json:"part_item_id,omitempty"`
+	WorkPackageID   *uuid.UUID  `json:"work_package_id,omitempty"`
+	// FlightIDs lists the planned flights the task overlaps.
+	FlightIDs []uuid.UUID `json:"flight_ids,omitempty"`
+}
+
+// ConflictFilter limits detection to the open tasks whose window
+// intersects [From, To). Either bound may be left open.
+type ConflictFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
 }
 
-func (s *SchedulingService) DetectConflicts(ctx context.Context, actor app.Actor) ([]ScheduleConflict, error) {
+// DetectConflicts checks the open tasks in the range against their
+// dependencies, against each other on the same aircraft, mechanic and bay,
+// and against their reserved parts and mechanics' qualifications. Related
+// records are read in batches for the whole set rather than per task.
+// Conflicts are ordered by task start and then severity.
+func (s *SchedulingService) DetectConflicts(ctx context.Context, actor app.Actor, filter ConflictFilter) ([]ScheduleConflict, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, domain.NewValidationError("to must be after from")
+	}
+	tasks, err := s.conflictTasks(ctx, actor.OrgID, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := s.detect(ctx, actor.OrgID, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if filter.Offset >= len(conflicts) {
+		return []ScheduleConflict{}, nil
+	}
+	conflicts = conflicts[max(filter.Offset, 0):]
+	if len(conflicts) > limit {
+		conflicts = conflicts[:limit]
+	}
+	return conflicts, nil
+}
+
+// conflictTasks pages through the open tasks whose window intersects
+// [from, to). Either bound may be nil.
+func (s *SchedulingService) conflictTasks(ctx context.Context, orgID uuid.UUID, from, to *time.Time) ([]domain.MaintenanceTask, error) {
+	filter := ports.TaskFilter{OrgID: &orgID, ActiveOnly: true}
+	if from != nil {
+		value := from.UTC()
+		filter.OverlapFrom = &value
+	}
+	if to != nil {
+		value := to.UTC()
+		filter.OverlapTo = &value
+	}
+	return s.allTasks(ctx, filter)
+}
+
+// allTasks pages through every task matching the filter.
+func (s *SchedulingService) allTasks(ctx context.Context, filter ports.TaskFilter) ([]domain.MaintenanceTask, error) {
+	filter.Limit = conflictBatchSize
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += conflictBatchSize {
+		filter.Offset = offset
+		page, err := s.Tasks.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < conflictBatchSize {
+			break
+		}
+	}
+	return tasks, nil
+}
 
-	// Get all active tasks
-	scheduledState := domain.TaskStateScheduled
-	inProgressState := domain.TaskStateInProgress
-	scheduledTasks, err := s.Tasks.List(ctx, ports.TaskFilter{OrgID: &actor.OrgID, State: &scheduledState, Limit: 200})
+// detect runs every conflict check over tasks, which need not match what
+// is stored, and orders the result.
+func (s *SchedulingService) detect(ctx context.Context, orgID uuid.UUID, tasks []domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	byID := make(map[uuid.UUID]domain.MaintenanceTask, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	conflicts, err := s.dependencyConflicts(ctx, orgID, byID)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, overlapConflicts(tasks)...)
+	bays, err := s.bayConflicts(ctx, orgID, tasks)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, bays...)
+	parts, err := s.partConflicts(ctx, orgID, byID)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, parts...)
+	quals, err := s.qualificationConflicts(ctx, orgID, tasks)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, quals...)
+	late, err := s.returnToServiceConflicts(ctx, orgID, byID)
 	if err != nil {
 		return nil, err
 	}
-	inProgressTasks, err := s.Tasks.List(ctx, ports.TaskFilter{OrgID: &actor.OrgID, State: &inProgressState, Limit: 200})
+	conflicts = append(conflicts, late...)
+	flights, err := s.flightConflicts(ctx, orgID, tasks)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, flights...)
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		if a.TaskID != b.TaskID {
+			return a.TaskID.String() < b.TaskID.String()
+		}
+		if a.Severity.Rank() != b.Severity.Rank() {
+			return a.Severity.Rank() > b.Severity.Rank()
+		}
+		if a.ConflictType != b.ConflictType {
+			return a.ConflictType < b.ConflictType
+		}
+		return a.Description < b.Description
+	})
+	return conflicts, nil
+}
+
+// dependencyConflicts reports tasks scheduled against their dependencies.
+// Prerequisites outside the set are fetched together in one pass.
+func (s *SchedulingService) dependencyConflicts(ctx context.Context, orgID uuid.UUID, byID map[uuid.UUID]domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.Dependencies == nil {
+		return nil, nil
+	}
+	var deps []domain.TaskDependency
+	for _, batch := range idBatches(mapKeys(byID)) {
+		page, err := s.Dependencies.ListByTasks(ctx, orgID, batch)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, page...)
+	}
+
+	prereqs := make(map[uuid.UUID]domain.MaintenanceTask, len(byID))
+	for id, task := range byID {
+		prereqs[id] = task
+	}
+	missing := make(map[uuid.UUID]bool)
+	for _, dep := range deps {
+		if _, ok := prereqs[dep.DependsOnTaskID]; !ok {
+			missing[dep.DependsOnTaskID] = true
+		}
+	}
+	for _, batch := range idBatches(mapKeys(missing)) {
+		page, err := s.Tasks.List(ctx, ports.TaskFilter{OrgID: &orgID, IDs: batch, Limit: conflictBatchSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range page {
+			prereqs[task.ID] = task
+		}
+	}
+
+	var conflicts []ScheduleConflict
+	for _, dep := range deps {
+		task, ok := byID[dep.TaskID]
+		if !ok {
+			continue
+		}
+		prereq, ok := prereqs[dep.DependsOnTaskID]
+		if !ok || prereq.State == domain.TaskStateCompleted {
+			continue
+		}
+		var description string
+		severity := domain.ConflictSeverityMedium
+		switch dep.DependencyType {
+		case domain.DependencyStartToStart:
+			if prereq.State == domain.TaskStateScheduled && task.StartTime.Before(prereq.StartTime) {
+				description = fmt.Sprintf("Task starts before dependency %s starts", prereq.ID)
+			}
+		case domain.DependencyFinishToFinish:
+			if task.EndTime.Before(prereq.EndTime) {
+				description = fmt.Sprintf("Task finishes before dependency %s finishes", prereq.ID)
+			}
+		default:
+			severity = domain.ConflictSeverityHigh
+			if task.StartTime.Before(prereq.EndTime) {
+				description = fmt.Sprintf("Task starts before dependency %s completes", prereq.ID)
+			}
+		}
+		if description == "" {
+			continue
+		}
+		depType := dep.DependencyType
+		if depType == "" {
+			depType = domain.DependencyFinishToStart
+		}
+		conflicts = append(conflicts, ScheduleConflict{
+			TaskID:          task.ID,
+			ConflictType:    conflictUnmetDependency,
+			Severity:        severity,
+			Description:     description,
+			StartTime:       task.StartTime,
+			DependencyType:  depType,
+			BlockingTaskIDs: []uuid.UUID{prereq.ID},
+		})
+	}
+	return conflicts, nil
+}
+
+// flightConflicts reports tasks during which their aircraft is planned to
+// fly. The flights of every aircraft in the set are read together.
+func (s *SchedulingService) flightConflicts(ctx context.Context, orgID uuid.UUID, tasks []domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.Flights == nil {
+		return nil, nil
+	}
+	aircraft := make(map[uuid.UUID]bool)
+	var from, to time.Time
+	for _, task := range tasks {
+		if !task.IsActive() {
+			continue
+		}
+		aircraft[task.AircraftID] = true
+		if from.IsZero() || task.StartTime.Before(from) {
+			from = task.StartTime
+		}
+		if task.EndTime.After(to) {
+			to = task.EndTime
+		}
+	}
+	if len(aircraft) == 0 {
+		return nil, nil
+	}
+	flights, err := s.Flights.FlightsDuring(ctx, orgID, mapKeys(aircraft), from, to)
 	if err != nil {
 		return nil, err
 	}
+	byAircraft := make(map[uuid.UUID][]domain.PlannedFlight)
+	for _, flight := range flights {
+		byAircraft[flight.AircraftID] = append(byAircraft[flight.AircraftID], flight)
+	}
+
+	var conflicts []ScheduleConflict
+	for _, task := range tasks {
+		if !task.IsActive() {
+			continue
+		}
+		var numbers []string
+		var ids []uuid.UUID
+		for _, flight := range byAircraft[task.AircraftID] {
+			if flight.Overlaps(task.StartTime, task.EndTime) {
+				numbers = append(numbers, flight.FlightNumber)
+				ids = append(ids, flight.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		conflicts = append(conflicts, ScheduleConflict{
+			TaskID:       task.ID,
+			ConflictType: conflictFlightOverlap,
+			Severity:     domain.ConflictSeverityCritical,
+			Description:  fmt.Sprintf("Aircraft %s is planned to fly %s during the task", task.AircraftID, strings.Join(numbers, ", ")),
+			StartTime:    task.StartTime,
+			FlightIDs:    ids,
+		})
+	}
+	return conflicts, nil
+}
+
+// overlapConflicts reports tasks that overlap another task on the same
+// aircraft or with the same assigned mechanic. Each overlap is reported
+// on the task that starts later.
+func overlapConflicts(tasks []domain.MaintenanceTask) []ScheduleConflict {
+	byAircraft := make(map[uuid.UUID][]domain.MaintenanceTask)
+	byMechanic := make(map[uuid.UUID][]domain.MaintenanceTask)
+	for _, task := range tasks {
+		byAircraft[task.AircraftID] = append(byAircraft[task.AircraftID], task)
+		if task.AssignedMechanicID != nil {
+			byMechanic[*task.AssignedMechanicID] = append(byMechanic[*task.AssignedMechanicID], task)
+		}
+	}
+
+	var conflicts []ScheduleConflict
+	for _, group := range byAircraft {
+		for _, overlap := range sweepOverlaps(group) {
+			conflicts = append(conflicts, ScheduleConflict{
+				TaskID:          overlap.task.ID,
+				ConflictType:    conflictAircraftOverlap,
+				Severity:        domain.ConflictSeverityCritical,
+				Description:     fmt.Sprintf("Aircraft %s has %d other task(s) scheduled at the same time", overlap.task.AircraftID, len(overlap.blocking)),
+				StartTime:       overlap.task.StartTime,
+				BlockingTaskIDs: overlap.blocking,
+			})
+		}
+	}
+	for mechanicID, group := range byMechanic {
+		for _, overlap := range sweepOverlaps(group) {
+			conflicts = append(conflicts, ScheduleConflict{
+				TaskID:          overlap.task.ID,
+				ConflictType:    conflictMechanicOverlap,
+				Severity:        domain.ConflictSeverityCritical,
+				Description:     fmt.Sprintf("Mechanic %s is assigned to %d other task(s) at the same time", mechanicID, len(overlap.blocking)),
+				StartTime:       overlap.task.StartTime,
+				BlockingTaskIDs: overlap.blocking,
+				MechanicID:      &mechanicID,
+			})
+		}
+	}
+	return conflicts
+}
+
+type taskOverlap struct {
+	task     domain.MaintenanceTask
+	blocking []uuid.UUID
+}
+
+// sweepOverlaps walks the tasks in start order, keeping those still running,
+// and pairs each task with the running tasks it overlaps.
+func sweepOverlaps(tasks []domain.MaintenanceTask) []taskOverlap {
+	if len(tasks) < 2 {
+		return nil
+	}
+	sorted := append([]domain.MaintenanceTask(nil), tasks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].StartTime.Equal(sorted[j].StartTime) {
+			return sorted[i].StartTime.Before(sorted[j].StartTime)
+		}
+		return sorted[i].ID.String() < sorted[j].ID.String()
+	})
+	var overlaps []taskOverlap
+	var running []domain.MaintenanceTask
+	for _, task := range sorted {
+		kept := running[:0]
+		for _, other := range running {
+			if other.EndTime.After(task.StartTime) {
+				kept = append(kept, other)
+			}
+		}
+		running = kept
+		if len(running) > 0 {
+			blocking := make([]uuid.UUID, 0, len(running))
+			for _, other := range running {
+				blocking = append(blocking, other.ID)
+			}
+			overlaps = append(overlaps, taskOverlap{task: task, blocking: blocking})
+		}
+		running = append(running, task)
+	}
+	return overlaps
+}
+
+// bayConflicts reports tasks that overrun the capacity of their bay.
+func (s *SchedulingService) bayConflicts(ctx context.Context, orgID uuid.UUID, tasks []domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.Capacity == nil {
+		return nil, nil
+	}
+	byBay := make(map[uuid.UUID][]domain.MaintenanceTask)
+	for _, task := range tasks {
+		if task.BayID != nil {
+			byBay[*task.BayID] = append(byBay[*task.BayID], task)
+		}
+	}
 
-	allTasks := append(scheduledTasks, inProgressTasks...)
 	var conflicts []ScheduleConflict
+	for bayID, booked := range byBay {
+		bay, err := s.Capacity.Bays.GetByID(ctx, orgID, bayID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		from, to := booked[0].StartTime, booked[0].EndTime
+		for _, task := range booked[1:] {
+			if task.StartTime.Before(from) {
+				from = task.StartTime
+			}
+			if task.EndTime.After(to) {
+				to = task.EndTime
+			}
+		}
+		windows, err := s.Capacity.Windows.ListOverlapping(ctx, orgID, bayID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range bay.Overbooked(windows, booked) {
+			conflicts = append(conflicts, ScheduleConflict{
+				TaskID:       task.ID,
+				ConflictType: conflictBayCapacity,
+				Severity:     domain.ConflictSeverityHigh,
+				Description:  fmt.Sprintf("Bay %s has more tasks booked than free slots", bay.Code),
+				StartTime:    task.StartTime,
+				BayID:        &bay.ID,
+			})
+		}
+	}
+	return conflicts, nil
+}
 
-	for _, task := range allTasks {
-		// Check for unmet dependencies
-		deps, err := s.Dependencies.ListByTask(ctx, actor.OrgID, task.ID)
+// partConflicts reports reserved parts that are gone, no longer in stock
+// or expire before the task ends.
+func (s *SchedulingService) partConflicts(ctx context.Context, orgID uuid.UUID, byID map[uuid.UUID]domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.Reservations == nil || s.PartItems == nil {
+		return nil, nil
+	}
+	var reservations []domain.PartReservation
+	for _, batch := range idBatches(mapKeys(byID)) {
+		page, err := s.Reservations.ListByTasks(ctx, orgID, batch)
 		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range page {
+			if reservation.State == domain.ReservationReserved {
+				reservations = append(reservations, reservation)
+			}
+		}
+	}
+
+	itemIDs := make(map[uuid.UUID]bool, len(reservations))
+	for _, reservation := range reservations {
+		itemIDs[reservation.PartItemID] = true
+	}
+	items := make(map[uuid.UUID]domain.PartItem, len(itemIDs))
+	for _, batch := range idBatches(mapKeys(itemIDs)) {
+		page, err := s.PartItems.List(ctx, ports.PartItemFilter{OrgID: &orgID, IDs: batch, Limit: conflictBatchSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			items[item.ID] = item
+		}
+	}
+
+	var conflicts []ScheduleConflict
+	for _, reservation := range reservations {
+		task, ok := byID[reservation.TaskID]
+		if !ok {
+			continue
+		}
+		itemID := reservation.PartItemID
+		conflict := ScheduleConflict{TaskID: task.ID, StartTime: task.StartTime, PartItemID: &itemID}
+		item, ok := items[itemID]
+		switch {
+		case !ok:
+			conflict.ConflictType = conflictPartUnavailable
+			conflict.Severity = domain.ConflictSeverityHigh
+			conflict.Description = fmt.Sprintf("Reserved part %s no longer exists", itemID)
+		case item.ExpiryDate != nil && item.ExpiryDate.Before(task.EndTime):
+			conflict.ConflictType = conflictPartExpired
+			conflict.Severity = domain.ConflictSeverityCritical
+			conflict.Description = fmt.Sprintf("Reserved part %s expires on %s, before the task ends", item.SerialNumber, item.ExpiryDate.Format(time.RFC3339))
+		case item.Status != domain.PartItemInStock:
+			conflict.ConflictType = conflictPartUnavailable
+			conflict.Severity = domain.ConflictSeverityHigh
+			conflict.Description = fmt.Sprintf("Reserved part %s is %s", item.SerialNumber, item.Status)
+		default:
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// qualificationConflicts reports tasks whose assigned mechanic is no longer
+// qualified for the task type and aircraft type. Qualified mechanics are
+// looked up once per combination.
+func (s *SchedulingService) qualificationConflicts(ctx context.Context, orgID uuid.UUID, tasks []domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.Certs == nil {
+		return nil, nil
+	}
+	aircraftTypes := make(map[uuid.UUID]*uuid.UUID)
+	qualified := make(map[string]map[uuid.UUID]bool)
+	var conflicts []ScheduleConflict
+	for _, task := range tasks {
+		if task.AssignedMechanicID == nil {
 			continue
 		}
-		for _, dep := range deps {
-			depTask, err := s.Tasks.GetByID(ctx, actor.OrgID, dep.DependsOnTaskID)
+		aircraftTypeID, ok := aircraftTypes[task.AircraftID]
+		if !ok && s.Aircraft != nil {
+			aircraft, err := s.Aircraft.GetByID(ctx, orgID, task.AircraftID)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return nil, err
+			}
+			aircraftTypeID = aircraft.AircraftTypeID
+			aircraftTypes[task.AircraftID] = aircraftTypeID
+		}
+		key := string(task.Type)
+		if aircraftTypeID != nil {
+			key += ":" + aircraftTypeID.String()
+		}
+		mechanics, ok := qualified[key]
+		if !ok {
+			ids, err := s.Certs.GetQualifiedMechanics(ctx, orgID, task.Type, aircraftTypeID)
 			if err != nil {
-				continue
+				return nil, err
 			}
-			if dep.DependencyType == domain.DependencyFinishToStart && depTask.State != domain.TaskStateCompleted {
-				if task.StartTime.Before(depTask.EndTime) {
-					conflicts = append(conflicts, ScheduleConflict{
-						TaskID:          task.ID,
-						ConflictType:    "unmet_dependency",
-						Description:     fmt.Sprintf("Task starts before dependency %s completes", dep.DependsOnTaskID),
-						BlockingTaskIDs: []uuid.UUID{dep.DependsOnTaskID},
-					})
-				}
+			mechanics = make(map[uuid.UUID]bool, len(ids))
+			for _, id := range ids {
+				mechanics[id] = true
 			}
+			qualified[key] = mechanics
 		}
+		if mechanics[*task.AssignedMechanicID] {
+			continue
+		}
+		mechanicID := *task.AssignedMechanicID
+		conflicts = append(conflicts, ScheduleConflict{
+			TaskID:       task.ID,
+			ConflictType: conflictQualificationLapsed,
+			Severity:     domain.ConflictSeverityCritical,
+			Description:  fmt.Sprintf("Mechanic %s is no longer qualified for %s tasks", mechanicID, task.Type),
+			StartTime:    task.StartTime,
+			MechanicID:   &mechanicID,
+		})
 	}
+	return conflicts, nil
+}
 
+// returnToServiceConflicts reports tasks whose work package can no longer
+// finish by its visit end because of them: tasks with negative total float
+// in the package's critical path analysis. Each package is analysed with
+// all its open tasks, taking the set's windows for the tasks in the set.
+func (s *SchedulingService) returnToServiceConflicts(ctx context.Context, orgID uuid.UUID, byID map[uuid.UUID]domain.MaintenanceTask) ([]ScheduleConflict, error) {
+	if s.WorkPackages == nil {
+		return nil, nil
+	}
+	packages := make(map[uuid.UUID]bool)
+	for _, task := range byID {
+		if task.WorkPackageID != nil {
+			packages[*task.WorkPackageID] = true
+		}
+	}
+
+	var conflicts []ScheduleConflict
+	for pkgID := range packages {
+		pkg, err := s.WorkPackages.GetByID(ctx, orgID, pkgID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if pkg.State == domain.WorkPackageCompleted || pkg.State == domain.WorkPackageCancelled {
+			continue
+		}
+		tasks, err := s.allTasks(ctx, ports.TaskFilter{OrgID: &orgID, WorkPackageID: &pkg.ID, ActiveOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		for i, task := range tasks {
+			if current, ok := byID[task.ID]; ok {
+				tasks[i] = current
+			}
+		}
+		visitEnd := pkg.VisitEnd
+		path, err := s.criticalPath(ctx, orgID, tasks, &visitEnd)
+		if errors.Is(err, domain.ErrValidation) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, float := range path.Tasks {
+			task, ok := byID[float.TaskID]
+			if !ok || float.TotalFloatMinutes >= 0 {
+				continue
+			}
+			pkgID := pkg.ID
+			conflicts = append(conflicts, ScheduleConflict{
+				TaskID:        task.ID,
+				ConflictType:  conflictReturnToService,
+				Severity:      domain.ConflictSeverityHigh,
+				Description:   fmt.Sprintf("Task's dependency chain finishes %s after work package %s visit end", time.Duration(-float.TotalFloatMinutes)*time.Minute, pkg.ID),
+				StartTime:     task.StartTime,
+				WorkPackageID: &pkgID,
+			})
+		}
+	}
 	return conflicts, nil
 }
+
+// --- Critical Path ---
+
+// TaskFloat is a task's place in a critical path analysis. Earliest times
+// come from a forward pass over the dependencies in which no task starts
+// before its planned start; latest times from a backward pass from the
+// return to service.
+type TaskFloat struct {
+	TaskID         uuid.UUID        `json:"task_id"`
+	State          domain.TaskState `json:"state"`
+	StartTime      time.Time        `json:"start_time"`
+	EndTime        time.Time        `json:"end_time"`
+	EarliestStart  time.Time        `json:"earliest_start"`
+	EarliestFinish time.Time        `json:"earliest_finish"`
+	LatestStart    time.Time        `json:"latest_start"`
+	LatestFinish   time.Time        `json:"latest_finish"`
+	// TotalFloatMinutes is how far the task can slip without delaying the
+	// return to service. It is negative when the work can no longer finish
+	// in time.
+	TotalFloatMinutes int64 `json:"total_float_minutes"`
+	// Critical marks the tasks with the least float, which together make
+	// up the critical path.
+	Critical bool `json:"critical"`
+	// DelaysReturnToService marks tasks that cannot slip at all without
+	// delaying the aircraft's return to service.
+	DelaysReturnToService bool `json:"delays_return_to_service"`
+}
+
+// CriticalPath is the critical path analysis of a work package or of an
+// aircraft's open tasks.
+type CriticalPath struct {
+	WorkPackageID *uuid.UUID `json:"work_package_id,omitempty"`
+	AircraftID    *uuid.UUID `json:"aircraft_id,omitempty"`
+	// ProjectedFinish is the earliest the last task can finish.
+	ProjectedFinish *time.Time `json:"projected_finish,omitempty"`
+	// ReturnToService is the work package's visit end, or for an aircraft
+	// the projected finish.
+	ReturnToService *time.Time `json:"return_to_service,omitempty"`
+	// Tasks are ordered by earliest start.
+	Tasks []TaskFloat `json:"tasks"`
+	// CriticalChain lists, first to last, the tasks whose dependencies
+	// drive the projected finish.
+	CriticalChain []uuid.UUID `json:"critical_chain"`
+}
+
+// CriticalPathInput names the work package or the aircraft to analyse;
+// exactly one must be set.
+type CriticalPathInput struct {
+	OrgID         *uuid.UUID
+	WorkPackageID *uuid.UUID
+	AircraftID    *uuid.UUID
+}
+
+// CriticalPath computes the earliest and latest start, total float and
+// critical chain of the open tasks of a work package, measured against its
+// visit end, or of an aircraft, measured against their projected finish.
+// Open prerequisites outside the analysed tasks hold their planned windows.
+func (s *SchedulingService) CriticalPath(ctx context.Context, actor app.Actor, input CriticalPathInput) (CriticalPath, error) {
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	if (input.WorkPackageID == nil) == (input.AircraftID == nil) {
+		return CriticalPath{}, domain.NewValidationError("exactly one of work_package_id and aircraft_id is required")
+	}
+
+	filter := ports.TaskFilter{OrgID: &orgID, ActiveOnly: true}
+	var finishBy *time.Time
+	var result CriticalPath
+	if input.WorkPackageID != nil {
+		if s.WorkPackages == nil {
+			return CriticalPath{}, domain.ErrNotFound
+		}
+		pkg, err := s.WorkPackages.GetByID(ctx, orgID, *input.WorkPackageID)
+		if err != nil {
+			return CriticalPath{}, err
+		}
+		visitEnd := pkg.VisitEnd
+		finishBy = &visitEnd
+		filter.WorkPackageID = &pkg.ID
+		result.WorkPackageID = &pkg.ID
+		result.AircraftID = &pkg.AircraftID
+	} else {
+		aircraftID := *input.AircraftID
+		filter.AircraftID = &aircraftID
+		result.AircraftID = &aircraftID
+	}
+
+	tasks, err := s.allTasks(ctx, filter)
+	if err != nil {
+		return CriticalPath{}, err
+	}
+	path, err := s.criticalPath(ctx, orgID, tasks, finishBy)
+	if err != nil {
+		return CriticalPath{}, err
+	}
+	path.WorkPackageID = result.WorkPackageID
+	path.AircraftID = result.AircraftID
+	return path, nil
+}
+
+// criticalPath reads the dependencies of tasks and analyses them. finishBy
+// is the return to service; when nil the projected finish is used.
+func (s *SchedulingService) criticalPath(ctx context.Context, orgID uuid.UUID, tasks []domain.MaintenanceTask, finishBy *time.Time) (CriticalPath, error) {
+	byID := make(map[uuid.UUID]domain.MaintenanceTask, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	var deps []domain.TaskDependency
+	if s.Dependencies != nil {
+		for _, batch := range idBatches(mapKeys(byID)) {
+			page, err := s.Dependencies.ListByTasks(ctx, orgID, batch)
+			if err != nil {
+				return CriticalPath{}, err
+			}
+			deps = append(deps, page...)
+		}
+	}
+
+	missing := make(map[uuid.UUID]bool)
+	for _, dep := range deps {
+		if _, ok := byID[dep.DependsOnTaskID]; !ok {
+			missing[dep.DependsOnTaskID] = true
+		}
+	}
+	external := make(map[uuid.UUID]domain.MaintenanceTask, len(missing))
+	for _, batch := range idBatches(mapKeys(missing)) {
+		page, err := s.Tasks.List(ctx, ports.TaskFilter{OrgID: &orgID, IDs: batch, ActiveOnly: true, Limit: conflictBatchSize})
+		if err != nil {
+			return CriticalPath{}, err
+		}
+		for _, task := range page {
+			external[task.ID] = task
+		}
+	}
+	return analyzeCriticalPath(tasks, deps, external, finishBy)
+}
+
+// analyzeCriticalPath runs the forward and backward passes. deps are the
+// dependencies of tasks; external holds the open prerequisites that are not
+// among tasks, which constrain the forward pass with their planned windows.
+// Dependencies on other tasks are ignored, as they no longer hold the work
+// up.
+func analyzeCriticalPath(tasks []domain.MaintenanceTask, deps []domain.TaskDependency, external map[uuid.UUID]domain.MaintenanceTask, finishBy *time.Time) (CriticalPath, error) {
+	path := CriticalPath{Tasks: []TaskFloat{}, CriticalChain: []uuid.UUID{}}
+	if len(tasks) == 0 {
+		path.ReturnToService = finishBy
+		return path, nil
+	}
+
+	byID := make(map[uuid.UUID]domain.MaintenanceTask, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	prereqs := make(map[uuid.UUID][]domain.TaskDependency)
+	dependents := make(map[uuid.UUID][]domain.TaskDependency)
+	pending := make(map[uuid.UUID]int, len(tasks))
+	for _, dep := range deps {
+		if _, ok := byID[dep.TaskID]; !ok {
+			continue
+		}
+		if _, ok := byID[dep.DependsOnTaskID]; ok {
+			dependents[dep.DependsOnTaskID] = append(dependents[dep.DependsOnTaskID], dep)
+			pending[dep.TaskID]++
+		} else if _, ok := external[dep.DependsOnTaskID]; !ok {
+			continue
+		}
+		prereqs[dep.TaskID] = append(prereqs[dep.TaskID], dep)
+	}
+
+	// Topological order, taking ready tasks by planned start so the result
+	// does not depend on the order tasks were read in.
+	sorted := append([]domain.MaintenanceTask(nil), tasks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].StartTime.Equal(sorted[j].StartTime) {
+			return sorted[i].StartTime.Before(sorted[j].StartTime)
+		}
+		return sorted[i].ID.String() < sorted[j].ID.String()
+	})
+	order := make([]domain.MaintenanceTask, 0, len(tasks))
+	done := make(map[uuid.UUID]bool, len(tasks))
+	for len(order) < len(sorted) {
+		progressed := false
+		for _, task := range sorted {
+			if done[task.ID] || pending[task.ID] > 0 {
+				continue
+			}
+			done[task.ID] = true
+			order = append(order, task)
+			for _, dep := range dependents[task.ID] {
+				pending[dep.TaskID]--
+			}
+			progressed = true
+		}
+		if !progressed {
+			return CriticalPath{}, domain.NewValidationError("task dependencies form a cycle")
+		}
+	}
+
+	// Forward pass. A task's driver is the prerequisite that holds its
+	// earliest start.
+	floats := make(map[uuid.UUID]*TaskFloat, len(tasks))
+	drivers := make(map[uuid.UUID]uuid.UUID)
+	var projected time.Time
+	for _, task := range order {
+		duration := task.EndTime.Sub(task.StartTime)
+		earliest := task.StartTime
+		for _, dep := range prereqs[task.ID] {
+			start, end := external[dep.DependsOnTaskID].StartTime, external[dep.DependsOnTaskID].EndTime
+			if prereq, ok := floats[dep.DependsOnTaskID]; ok {
+				start, end = prereq.EarliestStart, prereq.EarliestFinish
+			}
+			var bound time.Time
+			switch dep.DependencyType {
+			case domain.DependencyStartToStart:
+				bound = start
+			case domain.DependencyFinishToFinish:
+				bound = end.Add(-duration)
+			default:
+				bound = end
+			}
+			if bound.Before(earliest) {
+				continue
+			}
+			if _, ok := byID[dep.DependsOnTaskID]; ok {
+				drivers[task.ID] = dep.DependsOnTaskID
+			} else {
+				delete(drivers, task.ID)
+			}
+			earliest = bound
+		}
+		floats[task.ID] = &TaskFloat{
+			TaskID:         task.ID,
+			State:          task.State,
+			StartTime:      task.StartTime,
+			EndTime:        task.EndTime,
+			EarliestStart:  earliest,
+			EarliestFinish: earliest.Add(duration),
+		}
+		if finish := earliest.Add(duration); finish.After(projected) {
+			projected = finish
+		}
+	}
+	returnToService := projected
+	if finishBy != nil {
+		returnToService = *finishBy
+	}
+
+	// Backward pass.
+	var least time.Duration
+	for i := len(order) - 1; i >= 0; i-- {
+		task := order[i]
+		float := floats[task.ID]
+		duration := task.EndTime.Sub(task.StartTime)
+		latest := returnToService
+		for _, dep := range dependents[task.ID] {
+			dependent := floats[dep.TaskID]
+			var bound time.Time
+			switch dep.DependencyType {
+			case domain.DependencyStartToStart:
+				bound = dependent.LatestStart.Add(duration)
+			case domain.DependencyFinishToFinish:
+				bound = dependent.LatestFinish
+			default:
+				bound = dependent.LatestStart
+			}
+			if bound.Before(latest) {
+				latest = bound
+			}
+		}
+		float.LatestFinish = latest
+		float.LatestStart = latest.Add(-duration)
+		total := float.LatestStart.Sub(float.EarliestStart)
+		float.TotalFloatMinutes = int64(total / time.Minute)
+		float.DelaysReturnToService = total <= 0
+		if i == len(order)-1 || total < least {
+			least = total
+		}
+	}
+
+	var last *TaskFloat
+	for _, task := range order {
+		float := floats[task.ID]
+		float.Critical = float.LatestStart.Sub(float.EarliestStart) == least
+		path.Tasks = append(path.Tasks, *float)
+		// Of critical tasks finishing together, the chain ends at the one
+		// latest in dependency order.
+		if float.Critical && (last == nil || !float.EarliestFinish.Before(last.EarliestFinish)) {
+			last = float
+		}
+	}
+	sort.SliceStable(path.Tasks, func(i, j int) bool {
+		return path.Tasks[i].EarliestStart.Before(path.Tasks[j].EarliestStart)
+	})
+	for id, ok := last.TaskID, true; ok; id, ok = drivers[id] {
+		path.CriticalChain = append([]uuid.UUID{id}, path.CriticalChain...)
+	}
+	path.ProjectedFinish = &projected
+	path.ReturnToService = &returnToService
+	return path, nil
+}
+
+func mapKeys[V any](m map[uuid.UUID]V) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// idBatches splits ids into batches of at most conflictBatchSize.
+func idBatches(ids []uuid.UUID) [][]uuid.UUID {
+	var batches [][]uuid.UUID
+	for len(ids) > conflictBatchSize {
+		batches = append(batches, ids[:conflictBatchSize])
+		ids = ids[conflictBatchSize:]
+	}
+	if len(ids) > 0 {
+		batches = append(batches, ids)
+	}
+	return batches
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+type TaskCardService struct {
+	Cards    ports.TaskCardRepository
+	Tasks    ports.TaskRepository
+	Aircraft ports.AircraftRepository
+	// Certifications checks that inspectors of required inspection items
+	// hold an inspection authorization. Optional; without it RII steps
+	// cannot be inspected.
+	Certifications *CertificationService
+	Audit          ports.AuditRepository
+	Outbox         ports.OutboxRepository
+	Clock          app.Clock
+}
+
+type TaskCardCreateInput struct {
+	OrgID     *uuid.UUID
+	TaskID    uuid.UUID
+	Title     string
+	Reference string
+	Steps     []TaskCardStepInput
+}
+
+type TaskCardStepInput struct {
+	Instruction        string
+	RequiredInspection bool
+	MeasurementUnit    string
+	MinValue           *float64
+	MaxValue           *float64
+}
+
+func (s *TaskCardService) Create(ctx context.Context, actor app.Actor, input TaskCardCreateInput) (domain.TaskCard, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canManageCapacity(actor) {
+		return domain.TaskCard{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	task, err := s.Tasks.GetByID(ctx, orgID, input.TaskID)
+	if err != nil {
+		return domain.TaskCard{}, err
+	}
+	if !task.IsActive() {
+		return domain.TaskCard{}, domain.NewConflictError("task is " + string(task.State))
+	}
+
+	now := s.Clock.Now().UTC()
+	card := domain.TaskCard{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		TaskID:    task.ID,
+		Title:     strings.TrimSpace(input.Title),
+		Reference: strings.TrimSpace(input.Reference),
+		CreatedBy: actor.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i, step := range input.Steps {
+		card.Steps = append(card.Steps, domain.TaskCardStep{
+			ID:                 uuid.New(),
+			OrgID:              orgID,
+			CardID:             card.ID,
+			Position:           i + 1,
+			Instruction:        strings.TrimSpace(step.Instruction),
+			RequiredInspection: step.RequiredInspection,
+			MeasurementUnit:    strings.TrimSpace(step.MeasurementUnit),
+			MinValue:           step.MinValue,
+			MaxValue:           step.MaxValue,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		})
+	}
+	if err := card.Validate(); err != nil {
+		return domain.TaskCard{}, err
+	}
+
+	created, err := s.Cards.Create(ctx, card)
+	if err != nil {
+		return domain.TaskCard{}, err
+	}
+	inspections := 0
+	for _, step := range created.Steps {
+		if step.RequiredInspection {
+			inspections++
+		}
+	}
+	s.audit(ctx, actor, created.OrgID, created.ID, domain.AuditActionCreate, map[string]any{
+		"task_id":              created.TaskID,
+		"title":                created.Title,
+		"steps":                len(created.Steps),
+		"required_inspections": inspections,
+	})
+	return created, nil
+}
+
+func (s *TaskCardService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.TaskCard, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.TaskCard{}, domain.ErrForbidden
+	}
+	return s.Cards.GetByID(ctx, orgID, id)
+}
+
+func (s *TaskCardService) ListByTask(ctx context.Context, actor app.Actor, orgID, taskID uuid.UUID) ([]domain.TaskCard, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return nil, domain.ErrForbidden
+	}
+	if _, err := s.Tasks.GetByID(ctx, orgID, taskID); err != nil {
+		return nil, err
+	}
+	return s.Cards.ListByTask(ctx, orgID, taskID)
+}
+
+// PerformStep signs a step off as performed by the acting mechanic,
+// recording the measured value for steps with a tolerance. The task must be
+// in progress.
+func (s *TaskCardService) PerformStep(ctx context.Context, actor app.Actor, cardID, stepID uuid.UUID, value *float64) (domain.TaskCardStep, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	card, step, err := s.loadStep(ctx, actor.OrgID, cardID, stepID)
+	if err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	if err := step.CanPerform(actor.Role, value); err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	if _, err := s.taskInProgress(ctx, card); err != nil {
+		return domain.TaskCardStep{}, err
+	}
+
+	performed, err := s.Cards.PerformStep(ctx, card.OrgID, step.ID, actor.UserID, value, s.Clock.Now().UTC())
+	if err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	details := map[string]any{
+		"step_id":  performed.ID,
+		"position": performed.Position,
+	}
+	if performed.MeasuredValue != nil {
+		details["measured_value"] = *performed.MeasuredValue
+		details["measurement_unit"] = performed.MeasurementUnit
+	}
+	s.audit(ctx, actor, card.OrgID, card.ID, domain.AuditActionUpdate, details)
+	s.emitStepOutbox(ctx, card, performed, "task_card_step_performed", actor.UserID)
+	return performed, nil
+}
+
+// InspectStep signs a performed RII step off as inspected. The inspector
+// must not have performed the step and must hold the inspection
+// authorization required for the task's type and aircraft type.
+func (s *TaskCardService) InspectStep(ctx context.Context, actor app.Actor, cardID, stepID uuid.UUID) (domain.TaskCardStep, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	card, step, err := s.loadStep(ctx, actor.OrgID, cardID, stepID)
+	if err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	if err := step.CanInspect(actor.Role, actor.UserID); err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	task, err := s.taskInProgress(ctx, card)
+	if err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	if err := s.checkInspector(ctx, task, actor.UserID); err != nil {
+		return domain.TaskCardStep{}, err
+	}
+
+	inspected, err := s.Cards.InspectStep(ctx, card.OrgID, step.ID, actor.UserID, s.Clock.Now().UTC())
+	if err != nil {
+		return domain.TaskCardStep{}, err
+	}
+	s.audit(ctx, actor, card.OrgID, card.ID, domain.AuditActionUpdate, map[string]any{
+		"step_id":      inspected.ID,
+		"position":     inspected.Position,
+		"inspected_by": actor.UserID,
+		"performed_by": inspected.PerformedBy,
+	})
+	s.emitStepOutbox(ctx, card, inspected, "task_card_step_inspected", actor.UserID)
+	return inspected, nil
+}
+
+func (s *TaskCardService) loadStep(ctx context.Context, orgID, cardID, stepID uuid.UUID) (domain.TaskCard, domain.TaskCardStep, error) {
+	card, err := s.Cards.GetByID(ctx, orgID, cardID)
+	if err != nil {
+		return domain.TaskCard{}, domain.TaskCardStep{}, err
+	}
+	step, ok := card.Step(stepID)
+	if !ok {
+		return domain.TaskCard{}, domain.TaskCardStep{}, domain.ErrNotFound
+	}
+	return card, step, nil
+}
+
+func (s *TaskCardService) taskInProgress(ctx context.Context, card domain.TaskCard) (domain.MaintenanceTask, error) {
+	task, err := s.Tasks.GetByID(ctx, card.OrgID, card.TaskID)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if task.State != domain.TaskStateInProgress {
+		return domain.MaintenanceTask{}, domain.NewConflictError("task must be in progress")
+	}
+	return task, nil
+}
+
+// checkInspector checks the inspector's authorization against the
+// inspection requirements of the task's type and aircraft type.
+func (s *TaskCardService) checkInspector(ctx context.Context, task domain.MaintenanceTask, inspectorID uuid.UUID) error {
+	if s.Certifications == nil {
+		return domain.NewValidationError("inspection authorization unavailable")
+	}
+	aircraft, err := s.Aircraft.GetByID(ctx, task.OrgID, task.AircraftID)
+	if err != nil {
+		return err
+	}
+	result, err := s.Certifications.CheckInspectionAuthorization(ctx, task.OrgID, inspectorID, task.Type, aircraft.AircraftTypeID)
+	if err != nil {
+		return err
+	}
+	if !result.Qualified {
+		return domain.NewValidationError("inspector lacks inspection authorization: " + strings.Join(result.Reasons, ", "))
+	}
+	return nil
+}
+
+func (s *TaskCardService) audit(ctx context.Context, actor app.Actor, orgID, cardID uuid.UUID, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EntityType: "task_card",
+		EntityID:   cardID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *TaskCardService) emitStepOutbox(ctx context.Context, card domain.TaskCard, step domain.TaskCardStep, eventType string, userID uuid.UUID) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":             1,
+		"org_id":              card.OrgID,
+		"task_id":             card.TaskID,
+		"task_card_id":        card.ID,
+		"step_id":             step.ID,
+		"position":            step.Position,
+		"required_inspection": step.RequiredInspection,
+		"user_id":             userID,
+		"timestamp":           s.Clock.Now(),
+	}
+	if step.MeasuredValue != nil {
+		payload["measured_value"] = *step.MeasuredValue
+	}
+	_ = s.Outbox.Enqueue(ctx, card.OrgID, eventType, "task_card", card.ID, payload, fmt.Sprintf("%s:%s:%s", eventType, card.OrgID, step.ID))
+}
@@ -17,9 +17,33 @@ type TaskService struct {
 	Reservations ports.PartReservationRepository
 	Compliance   ports.ComplianceRepository
 	Certs        ports.CertificationRepository
-	Audit        ports.AuditRepository
-	Outbox       ports.OutboxRepository
-	Clock        app.Clock
+	// Cards holds the task cards whose steps must all be performed, and
+	// their required inspection items inspected, before the task is
+	// completed. Optional.
+	Cards ports.TaskCardRepository
+	// Labor blocks completion while mechanics are clocked in and logs the
+	// hours worked as recency once the task is completed. Optional.
+	Labor *LaborService
+	// Crew lets any crew member start the task and requires its certifying
+	// staff, still authorized, to complete it. Optional; without it only
+	// the assigned mechanic works the task.
+	Crew *CrewService
+	// Capacity checks tasks against the aircraft's other bookings and places
+	// them into hangar bay slots. Optional; without it tasks cannot be
+	// booked into a bay.
+	Capacity *CapacityService
+	// Roster rejects assignments outside the mechanic's shifts, during
+	// their absences or overlapping their other tasks. Optional.
+	Roster *RosterService
+	// Preemption lets AOG and critical tasks displace lower-priority work
+	// holding their bay or mechanic. Optional.
+	Preemption *PreemptionService
+	// Flights rejects tasks whose aircraft is planned to fly during the
+	// task's window. Optional.
+	Flights *FlightScheduleService
+	Audit   ports.AuditRepository
+	Outbox  ports.OutboxRepository
+	Clock   app.Clock
 }
 
 type TaskTransitionOptions struct {
@@ -33,24 +57,98 @@ type TaskCreateInput struct {
 	OrgID              *uuid.UUID
 	AircraftID         uuid.UUID
 	ProgramID          *uuid.UUID
+	WorkPackageID      *uuid.UUID
+	BayID              *uuid.UUID
 	Type               domain.TaskType
+	Priority           domain.TaskPriority
 	StartTime          time.Time
 	EndTime            time.Time
 	AssignedMechanicID *uuid.UUID
 	Notes              string
+	// ComplianceChecklist creates a pending compliance item per entry on the
+	// new task.
+	ComplianceChecklist []string
+	// Preempt lets an AOG or critical task displace lower-priority work
+	// holding its bay or mechanic instead of failing on the conflict.
+	Preempt bool
+	// AllowFlightOverlap schedules the task even though its aircraft is
+	// planned to fly during the window.
+	AllowFlightOverlap bool
 }
 
 type TaskUpdateInput struct {
 	OrgID              *uuid.UUID
 	ProgramID          *uuid.UUID
+	BayID              *uuid.UUID
 	Type               *domain.TaskType
 	StartTime          *time.Time
 	EndTime            *time.Time
 	AssignedMechanicID *uuid.UUID
 	Notes              *string
+	// AllowFlightOverlap keeps a new window even though the aircraft is
+	// planned to fly during it.
+	AllowFlightOverlap bool
 }
 
 func (s *TaskService) Create(ctx context.Context, actor app.Actor, input TaskCreateInput) (domain.MaintenanceTask, error) {
+	task, err := s.newTask(actor, input)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if !input.AllowFlightOverlap {
+		if err := s.checkFlights(ctx, task); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+
+	checklist := make([]domain.ComplianceItem, 0, len(input.ComplianceChecklist))
+	for _, description := range input.ComplianceChecklist {
+		checklist = append(checklist, domain.ComplianceItem{
+			ID:          uuid.New(),
+			OrgID:       task.OrgID,
+			TaskID:      task.ID,
+			Description: description,
+			Result:      domain.CompliancePending,
+			CreatedAt:   s.Clock.Now(),
+			UpdatedAt:   s.Clock.Now(),
+		})
+	}
+
+	var created domain.MaintenanceTask
+	if input.Preempt {
+		created, err = s.createPreempting(ctx, actor, task, checklist)
+	} else {
+		created, err = s.create(ctx, task, checklist)
+	}
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+
+	s.emitTaskCreateAudit(ctx, actor, created)
+	s.emitTaskCreated(ctx, created)
+	return created, nil
+}
+
+// PlanPreemption works out what creating the task with Preempt would
+// displace and which conflicts would remain, without writing anything.
+func (s *TaskService) PlanPreemption(ctx context.Context, actor app.Actor, input TaskCreateInput) (PreemptionPlan, error) {
+	task, err := s.newTask(actor, input)
+	if err != nil {
+		return PreemptionPlan{}, err
+	}
+	if s.Preemption == nil {
+		return PreemptionPlan{}, domain.NewValidationError("preemption unavailable")
+	}
+	if task.AssignedMechanicID != nil {
+		if err := s.validateMechanicQualification(ctx, task.OrgID, *task.AssignedMechanicID, task.Type, task.AircraftID); err != nil {
+			return PreemptionPlan{}, err
+		}
+	}
+	return s.Preemption.Plan(ctx, actor, task)
+}
+
+// newTask builds and validates a scheduled task from input.
+func (s *TaskService) newTask(actor app.Actor, input TaskCreateInput) (domain.MaintenanceTask, error) {
 	if s.Clock == nil {
 		s.Clock = app.RealClock{}
 	}
@@ -67,8 +165,11 @@ func (s *TaskService) Create(ctx context.Context, actor app.Actor, input TaskCre
 		OrgID:              orgID,
 		AircraftID:         input.AircraftID,
 		ProgramID:          input.ProgramID,
+		WorkPackageID:      input.WorkPackageID,
+		BayID:              input.BayID,
 		Type:               input.Type,
 		State:              domain.TaskStateScheduled,
+		Priority:           input.Priority,
 		StartTime:          input.StartTime.UTC(),
 		EndTime:            input.EndTime.UTC(),
 		AssignedMechanicID: input.AssignedMechanicID,
@@ -77,25 +178,86 @@ func (s *TaskService) Create(ctx context.Context, actor app.Actor, input TaskCre
 		UpdatedAt:          s.Clock.Now(),
 	}
 
+	if task.Priority == "" {
+		task.Priority = domain.PriorityRoutine
+	}
+	if !task.Priority.IsValid() {
+		return domain.MaintenanceTask{}, domain.NewValidationError("priority must be routine, urgent, aog or critical")
+	}
 	if err := task.ValidateCreate(); err != nil {
 		return domain.MaintenanceTask{}, err
 	}
+	return task, nil
+}
 
-	// Validate mechanic qualifications if assigned
-	if task.AssignedMechanicID != nil {
-		if err := s.validateMechanicQualification(ctx, orgID, *task.AssignedMechanicID, task.Type, task.AircraftID); err != nil {
-			return domain.MaintenanceTask{}, err
-		}
+// create books the task's capacity and mechanic, failing on any conflict,
+// and stores it with its compliance checklist.
+func (s *TaskService) create(ctx context.Context, task domain.MaintenanceTask, checklist []domain.ComplianceItem) (domain.MaintenanceTask, error) {
+	if err := s.book(ctx, &task); err != nil {
+		return domain.MaintenanceTask{}, err
 	}
+	return s.Tasks.CreateWithCompliance(ctx, task, checklist)
+}
 
-	created, err := s.Tasks.Create(ctx, task)
+// prepare builds the task from input and books its capacity and mechanic
+// like Create, without storing it, for callers that write the task in the
+// same transaction as records of their own. The caller reports the created
+// task with created.
+func (s *TaskService) prepare(ctx context.Context, actor app.Actor, input TaskCreateInput) (domain.MaintenanceTask, error) {
+	task, err := s.newTask(actor, input)
 	if err != nil {
 		return domain.MaintenanceTask{}, err
 	}
+	if !input.AllowFlightOverlap {
+		if err := s.checkFlights(ctx, task); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+	if err := s.book(ctx, &task); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	return task, nil
+}
 
-	s.emitTaskCreateAudit(ctx, actor, created)
-	s.emitTaskCreated(ctx, created)
-	return created, nil
+// created records the audit entry and event for a task stored outside
+// Create.
+func (s *TaskService) created(ctx context.Context, actor app.Actor, task domain.MaintenanceTask) {
+	s.emitTaskCreateAudit(ctx, actor, task)
+	s.emitTaskCreated(ctx, task)
+}
+
+// book assigns the task's bay slot and checks its mechanic, failing on any
+// conflict.
+func (s *TaskService) book(ctx context.Context, task *domain.MaintenanceTask) error {
+	if err := s.reserveCapacity(ctx, task); err != nil {
+		return err
+	}
+
+	// Validate mechanic qualifications if assigned
+	if task.AssignedMechanicID != nil {
+		if err := s.validateMechanicQualification(ctx, task.OrgID, *task.AssignedMechanicID, task.Type, task.AircraftID); err != nil {
+			return err
+		}
+		if err := s.checkMechanicAvailability(ctx, *task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createPreempting stores the task with its compliance checklist, displacing
+// lower-priority work holding its bay or mechanic.
+func (s *TaskService) createPreempting(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, checklist []domain.ComplianceItem) (domain.MaintenanceTask, error) {
+	if s.Preemption == nil {
+		return domain.MaintenanceTask{}, domain.NewValidationError("preemption unavailable")
+	}
+	if task.AssignedMechanicID != nil {
+		if err := s.validateMechanicQualification(ctx, task.OrgID, *task.AssignedMechanicID, task.Type, task.AircraftID); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+	created, _, err := s.Preemption.Preempt(ctx, actor, task, checklist)
+	return created, err
 }
 
 func (s *TaskService) Get(ctx context.Context, actor app.Actor, orgID uuid.UUID, id uuid.UUID) (domain.MaintenanceTask, error) {
@@ -133,6 +295,12 @@ func (s *TaskService) Update(ctx context.Context, actor app.Actor, orgID uuid.UU
 	if input.Type != nil {
 		task.Type = *input.Type
 	}
+	if input.BayID != nil {
+		if !task.IsActive() {
+			return domain.MaintenanceTask{}, domain.NewConflictError("closed tasks cannot change bay")
+		}
+		task.BayID = input.BayID
+	}
 	if input.StartTime != nil {
 		task.StartTime = input.StartTime.UTC()
 	}
@@ -150,6 +318,16 @@ func (s *TaskService) Update(ctx context.Context, actor app.Actor, orgID uuid.UU
 	if err := task.ValidateCreate(); err != nil {
 		return domain.MaintenanceTask{}, err
 	}
+	if !input.AllowFlightOverlap && (input.StartTime != nil || input.EndTime != nil) {
+		if err := s.checkFlights(ctx, task); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+	if input.BayID != nil || input.StartTime != nil || input.EndTime != nil {
+		if err := s.reserveCapacity(ctx, &task); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
 
 	// Validate mechanic qualifications if mechanic is being changed
 	if input.AssignedMechanicID != nil {
@@ -157,6 +335,11 @@ func (s *TaskService) Update(ctx context.Context, actor app.Actor, orgID uuid.UU
 			return domain.MaintenanceTask{}, err
 		}
 	}
+	if task.AssignedMechanicID != nil && (input.AssignedMechanicID != nil || input.StartTime != nil || input.EndTime != nil) {
+		if err := s.checkMechanicAvailability(ctx, task); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
 
 	updated, err := s.Tasks.Update(ctx, task)
 	if err != nil {
@@ -234,6 +417,31 @@ func (s *TaskService) TransitionState(ctx context.Context, actor app.Actor, task
 		complianceSignedOff = allComplianceSignedOff(items)
 	}
 
+	taskCardsComplete := true
+	if s.Cards != nil {
+		cards, err := s.Cards.ListByTask(ctx, task.OrgID, task.ID)
+		if err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+		taskCardsComplete = allTaskCardsComplete(cards)
+	}
+
+	laborClockedOut := true
+	if s.Labor != nil && newState == domain.TaskStateCompleted {
+		laborClockedOut, err = s.Labor.ClockedOut(ctx, task)
+		if err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+
+	var crew domain.Crew
+	if s.Crew != nil {
+		crew, err = s.Crew.Assignments.ListByTask(ctx, task.OrgID, task.ID)
+		if err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+
 	notes := opts.Notes
 	if notes == "" {
 		notes = task.Notes
@@ -249,6 +457,9 @@ func (s *TaskService) TransitionState(ctx context.Context, actor app.Actor, task
 		AllReservationsClosed: allClosed,
 		RequiredPartsUsed:     !opts.RequireAllPartsUsed || allUsed,
 		ComplianceSignedOff:   complianceSignedOff,
+		TaskCardsComplete:     taskCardsComplete,
+		LaborClockedOut:       laborClockedOut,
+		Crew:                  crew,
 		Notes:                 notes,
 	}
 
@@ -257,7 +468,11 @@ func (s *TaskService) TransitionState(ctx context.Context, actor app.Actor, task
 	}
 
 	// Re-validate mechanic qualifications at completion (sign-off)
-	if newState == domain.TaskStateCompleted && task.AssignedMechanicID != nil {
+	if newState == domain.TaskStateCompleted && len(crew) > 0 {
+		if err := s.Crew.CheckSignOff(ctx, actor, task, crew); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	} else if newState == domain.TaskStateCompleted && task.AssignedMechanicID != nil {
 		if err := s.validateMechanicQualification(ctx, task.OrgID, *task.AssignedMechanicID, task.Type, task.AircraftID); err != nil {
 			return domain.MaintenanceTask{}, err
 		}
@@ -280,6 +495,47 @@ func (s *TaskService) TransitionState(ctx context.Context, actor app.Actor, task
 	return updated, nil
 }
 
+// reserveCapacity checks the task's window and assigns its bay slot. Without
+// a capacity service the database constraints are the only check, and tasks
+// cannot be placed into a bay.
+func (s *TaskService) reserveCapacity(ctx context.Context, task *domain.MaintenanceTask) error {
+	if s.Capacity == nil {
+		if task.BayID != nil {
+			return domain.NewValidationError("hangar capacity unavailable")
+		}
+		return nil
+	}
+	return s.Capacity.Reserve(ctx, task)
+}
+
+// checkFlights rejects the task when its aircraft is planned to fly during
+// the task's window. Without a flight schedule any window is accepted.
+func (s *TaskService) checkFlights(ctx context.Context, task domain.MaintenanceTask) error {
+	if s.Flights == nil {
+		return nil
+	}
+	return s.Flights.CheckTask(ctx, task)
+}
+
+// OverlappingFlights returns the planned flights of the task's aircraft
+// during its window, for warning about tasks scheduled with
+// AllowFlightOverlap.
+func (s *TaskService) OverlappingFlights(ctx context.Context, task domain.MaintenanceTask) ([]domain.PlannedFlight, error) {
+	if s.Flights == nil || !task.IsActive() {
+		return nil, nil
+	}
+	return s.Flights.FlightsDuring(ctx, task.OrgID, []uuid.UUID{task.AircraftID}, task.StartTime, task.EndTime)
+}
+
+// checkMechanicAvailability checks the assigned mechanic's roster. Without a
+// roster service any qualified mechanic can be assigned.
+func (s *TaskService) checkMechanicAvailability(ctx context.Context, task domain.MaintenanceTask) error {
+	if s.Roster == nil {
+		return nil
+	}
+	return s.Roster.CheckAssignment(ctx, task)
+}
+
 func summarizeReservations(reservations []domain.PartReservation) (allClosed bool, allUsed bool) {
 	if len(reservations) == 0 {
 		return true, true
@@ -313,6 +569,15 @@ func allComplianceSignedOff(items []domain.ComplianceItem) bool {
 	return true
 }
 
+func allTaskCardsComplete(cards []domain.TaskCard) bool {
+	for _, card := range cards {
+		if !card.Complete() {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *TaskService) emitTaskAudit(ctx context.Context, actor app.Actor, task domain.MaintenanceTask, newState domain.TaskState) {
 	if s.Audit == nil {
 		return
@@ -430,11 +695,12 @@ func (s *TaskService) validateMechanicQualification(ctx context.Context, orgID,
 				if err == nil && certType.RecencyRequiredMonths != nil && certType.RecencyPeriodMonths != nil {
 					since := now.AddDate(0, -*certType.RecencyPeriodMonths, 0)
 					hours, err := s.Certs.GetRecencyHours(ctx, orgID, mechanicID, *aircraft.AircraftTypeID, since)
-					if err == nil {
-						requiredHours := float64(*certType.RecencyRequiredMonths) * 160
-						if hours < requiredHours {
-							return domain.NewValidationError("mechanic has insufficient recency hours")
-						}
+					if err != nil {
+						return err
+					}
+					requiredHours := float64(*certType.RecencyRequiredMonths) * 160
+					if hours < requiredHours {
+						return domain.NewValidationError("mechanic has insufficient recency hours")
 					}
 				}
 			}
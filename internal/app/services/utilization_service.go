@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+// utilizationRecordAttempts bounds how often Record re-derives an entry
+// whose totals were overtaken by a concurrent entry.
+const utilizationRecordAttempts = 3
+
+type AircraftUtilizationService struct {
+	Utilization ports.AircraftUtilizationRepository
+	Aircraft    ports.AircraftRepository
+	Audit       ports.AuditRepository
+	Outbox      ports.OutboxRepository
+	Clock       app.Clock
+}
+
+// UtilizationRecordInput describes a per-flight or daily utilization entry.
+// Usage can be given as the block hours and cycles flown, as the aircraft
+// totals read from the tech log, or both; when both are given they must agree.
+type UtilizationRecordInput struct {
+	OrgID        *uuid.UUID
+	AircraftID   uuid.UUID
+	EntryType    domain.UtilizationEntryType
+	Date         time.Time
+	FlightNumber string
+	BlockHours   *float64
+	Cycles       *int
+	HoursTotal   *float64
+	CyclesTotal  *int
+}
+
+func (s *AircraftUtilizationService) Record(ctx context.Context, actor app.Actor, input UtilizationRecordInput) (domain.AircraftUtilization, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if actor.Role != domain.RoleScheduler && actor.Role != domain.RoleMechanic && actor.Role != domain.RoleAdmin {
+		return domain.AircraftUtilization{}, domain.ErrForbidden
+	}
+	if !input.EntryType.IsValid() {
+		return domain.AircraftUtilization{}, domain.NewValidationError("entry_type must be flight or daily")
+	}
+	if input.Date.IsZero() {
+		return domain.AircraftUtilization{}, domain.NewValidationError("date is required")
+	}
+	if input.BlockHours == nil && input.Cycles == nil && input.HoursTotal == nil && input.CyclesTotal == nil {
+		return domain.AircraftUtilization{}, domain.NewValidationError("block_hours, cycles, hours_total or cycles_total is required")
+	}
+	now := s.Clock.Now()
+	date := utilizationDay(input.Date)
+	if date.After(now) {
+		return domain.AircraftUtilization{}, domain.NewValidationError("date must not be in the future")
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+
+	var created domain.AircraftUtilization
+	for attempt := 1; ; attempt++ {
+		entry, err := s.newEntry(ctx, actor, orgID, input, date, now)
+		if err != nil {
+			return domain.AircraftUtilization{}, err
+		}
+		created, err = s.Utilization.Create(ctx, entry)
+		if err == nil {
+			break
+		}
+		// Another entry may have moved the totals the entry was derived
+		// from; work it out again from the new totals.
+		if !errors.Is(err, domain.ErrConflict) || attempt == utilizationRecordAttempts {
+			return domain.AircraftUtilization{}, err
+		}
+	}
+
+	if s.Audit != nil {
+		_ = s.Audit.Insert(ctx, domain.AuditLog{
+			ID:         uuid.New(),
+			OrgID:      orgID,
+			EntityType: "aircraft_utilization",
+			EntityID:   created.ID,
+			Action:     domain.AuditActionCreate,
+			UserID:     actor.UserID,
+			RequestID:  uuid.Nil,
+			Timestamp:  now,
+			Details: map[string]any{
+				"aircraft_id":  created.AircraftID,
+				"hours_total":  created.HoursTotal,
+				"cycles_total": created.CyclesTotal,
+			},
+		})
+	}
+	s.emitUtilizationRecorded(ctx, created)
+	return created, nil
+}
+
+// newEntry derives the entry's usage and totals from the aircraft's current
+// totals and its latest entry.
+func (s *AircraftUtilizationService) newEntry(ctx context.Context, actor app.Actor, orgID uuid.UUID, input UtilizationRecordInput, date, now time.Time) (domain.AircraftUtilization, error) {
+	aircraft, err := s.Aircraft.GetByID(ctx, orgID, input.AircraftID)
+	if err != nil {
+		return domain.AircraftUtilization{}, err
+	}
+	prevHours := float64(aircraft.FlightHoursTotal)
+	prevCycles := aircraft.CyclesTotal
+	latest, err := s.Utilization.GetLatest(ctx, orgID, aircraft.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.AircraftUtilization{}, err
+	}
+	if err == nil {
+		if date.Before(latest.UtilizationDate) {
+			return domain.AircraftUtilization{}, domain.NewValidationError("date must not precede the latest utilization entry")
+		}
+		prevHours = math.Max(prevHours, latest.HoursTotal)
+		if latest.CyclesTotal > prevCycles {
+			prevCycles = latest.CyclesTotal
+		}
+	}
+
+	blockHours, hoursTotal, err := resolveUtilizationHours(prevHours, input.BlockHours, input.HoursTotal)
+	if err != nil {
+		return domain.AircraftUtilization{}, err
+	}
+	cycles, cyclesTotal, err := resolveUtilizationCycles(prevCycles, input.Cycles, input.CyclesTotal)
+	if err != nil {
+		return domain.AircraftUtilization{}, err
+	}
+
+	var recordedBy *uuid.UUID
+	if actor.UserID != uuid.Nil {
+		userID := actor.UserID
+		recordedBy = &userID
+	}
+	return domain.AircraftUtilization{
+		ID:              uuid.New(),
+		OrgID:           orgID,
+		AircraftID:      aircraft.ID,
+		EntryType:       input.EntryType,
+		UtilizationDate: date,
+		FlightNumber:    input.FlightNumber,
+		BlockHours:      blockHours,
+		Cycles:          cycles,
+		HoursTotal:      hoursTotal,
+		CyclesTotal:     cyclesTotal,
+		RecordedBy:      recordedBy,
+		CreatedAt:       now,
+	}, nil
+}
+
+func (s *AircraftUtilizationService) List(ctx context.Context, actor app.Actor, filter ports.AircraftUtilizationFilter) ([]domain.AircraftUtilization, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Utilization.List(ctx, filter)
+}
+
+func (s *AircraftUtilizationService) emitUtilizationRecorded(ctx context.Context, entry domain.AircraftUtilization) {
+	if s.Outbox == nil {
+		return
+	}
+	eventType := "aircraft_utilization_recorded"
+	dedupeKey := fmt.Sprintf("aircraft_utilization_recorded:%s:%s", entry.OrgID, entry.ID)
+	payload := map[string]any{
+		"version":        1,
+		"org_id":         entry.OrgID,
+		"aircraft_id":    entry.AircraftID,
+		"utilization_id": entry.ID,
+		"entry_type":     entry.EntryType,
+		"date":           entry.UtilizationDate.Format("2006-01-02"),
+		"block_hours":    entry.BlockHours,
+		"cycles":         entry.Cycles,
+		"hours_total":    entry.HoursTotal,
+		"cycles_total":   entry.CyclesTotal,
+		"timestamp":      s.Clock.Now(),
+	}
+	_ = s.Outbox.Enqueue(ctx, entry.OrgID, eventType, "aircraft", entry.AircraftID, payload, dedupeKey)
+}
+
+func utilizationDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// resolveUtilizationHours derives the block hours flown and the resulting
+// total from whichever of the two was supplied, rejecting totals that would
+// go backwards.
+func resolveUtilizationHours(prev float64, blockHours, hoursTotal *float64) (float64, float64, error) {
+	switch {
+	case hoursTotal != nil:
+		total := roundHours(*hoursTotal)
+		if total < prev {
+			return 0, 0, domain.NewValidationError("hours_total must not be less than the previous total")
+		}
+		delta := roundHours(total - prev)
+		if blockHours != nil && math.Abs(roundHours(*blockHours)-delta) > 0.005 {
+			return 0, 0, domain.NewValidationError("block_hours does not match hours_total")
+		}
+		return delta, total, nil
+	case blockHours != nil:
+		if *blockHours < 0 {
+			return 0, 0, domain.NewValidationError("block_hours must not be negative")
+		}
+		delta := roundHours(*blockHours)
+		return delta, roundHours(prev + delta), nil
+	default:
+		return 0, prev, nil
+	}
+}
+
+func resolveUtilizationCycles(prev int, cycles, cyclesTotal *int) (int, int, error) {
+	switch {
+	case cyclesTotal != nil:
+		if *cyclesTotal < prev {
+			return 0, 0, domain.NewValidationError("cycles_total must not be less than the previous total")
+		}
+		delta := *cyclesTotal - prev
+		if cycles != nil && *cycles != delta {
+			return 0, 0, domain.NewValidationError("cycles does not match cycles_total")
+		}
+		return delta, *cyclesTotal, nil
+	case cycles != nil:
+		if *cycles < 0 {
+			return 0, 0, domain.NewValidationError("cycles must not be negative")
+		}
+		return *cycles, prev + *cycles, nil
+	default:
+		return 0, prev, nil
+	}
+}
+
+func roundHours(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,576 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+)
+
+const workPackagePageSize = 200
+
+type WorkPackageService struct {
+	Packages     ports.WorkPackageRepository
+	Tasks        ports.TaskRepository
+	Aircraft     ports.AircraftRepository
+	Reservations ports.PartReservationRepository
+	Compliance   ports.ComplianceRepository
+	// Findings keeps packages with open findings from completing. Optional.
+	Findings ports.FindingRepository
+	// Programs and TaskSvc are required for auto-fill only.
+	Programs *MaintenanceProgramService
+	TaskSvc  *TaskService
+	Audit    ports.AuditRepository
+	Outbox   ports.OutboxRepository
+	Clock    app.Clock
+}
+
+type WorkPackageCreateInput struct {
+	OrgID      *uuid.UUID
+	AircraftID uuid.UUID
+	Name       string
+	VisitStart time.Time
+	VisitEnd   time.Time
+	Notes      string
+}
+
+type WorkPackageUpdateInput struct {
+	Name       *string
+	VisitStart *time.Time
+	VisitEnd   *time.Time
+	Notes      *string
+}
+
+// WorkPackageDetail is a package together with its tasks and roll-up.
+type WorkPackageDetail struct {
+	Package domain.WorkPackage
+	Tasks   []domain.MaintenanceTask
+	Summary domain.WorkPackageSummary
+}
+
+// WorkPackageAutoFillResult lists the tasks auto-fill added to the package
+// and the due programs it could not place, either because the visit has no
+// room left or because their open task belongs to another package.
+type WorkPackageAutoFillResult struct {
+	Added    []domain.MaintenanceTask
+	Unplaced []domain.ProgramForecast
+}
+
+func (s *WorkPackageService) Create(ctx context.Context, actor app.Actor, input WorkPackageCreateInput) (domain.WorkPackage, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return domain.WorkPackage{}, domain.ErrForbidden
+	}
+	orgID := actor.OrgID
+	if actor.IsAdmin() && input.OrgID != nil && *input.OrgID != uuid.Nil {
+		orgID = *input.OrgID
+	}
+	now := s.Clock.Now()
+	pkg := domain.WorkPackage{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: input.AircraftID,
+		Name:       input.Name,
+		State:      domain.WorkPackagePlanned,
+		VisitStart: input.VisitStart.UTC(),
+		VisitEnd:   input.VisitEnd.UTC(),
+		Notes:      input.Notes,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := pkg.Validate(); err != nil {
+		return domain.WorkPackage{}, err
+	}
+	if s.Aircraft != nil {
+		if _, err := s.Aircraft.GetByID(ctx, orgID, input.AircraftID); err != nil {
+			return domain.WorkPackage{}, err
+		}
+	}
+	created, err := s.Packages.Create(ctx, pkg)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	s.audit(ctx, actor, created, domain.AuditActionCreate, nil)
+	s.emit(ctx, created, "work_package_created", fmt.Sprintf("work_package_created:%s:%s", created.OrgID, created.ID))
+	return created, nil
+}
+
+func (s *WorkPackageService) Get(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (domain.WorkPackage, error) {
+	if !actor.IsAdmin() && actor.OrgID != orgID {
+		return domain.WorkPackage{}, domain.ErrForbidden
+	}
+	return s.Packages.GetByID(ctx, orgID, id)
+}
+
+// Detail returns the package with its tasks and the roll-up of their
+// completion, part readiness and compliance sign-off.
+func (s *WorkPackageService) Detail(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (WorkPackageDetail, error) {
+	pkg, err := s.Get(ctx, actor, orgID, id)
+	if err != nil {
+		return WorkPackageDetail{}, err
+	}
+	tasks, err := s.packageTasks(ctx, pkg)
+	if err != nil {
+		return WorkPackageDetail{}, err
+	}
+	summary, err := s.summarize(ctx, pkg, tasks)
+	if err != nil {
+		return WorkPackageDetail{}, err
+	}
+	return WorkPackageDetail{Package: pkg, Tasks: tasks, Summary: summary}, nil
+}
+
+func (s *WorkPackageService) List(ctx context.Context, actor app.Actor, filter ports.WorkPackageFilter) ([]domain.WorkPackage, error) {
+	if !actor.IsAdmin() {
+		filter.OrgID = &actor.OrgID
+	}
+	return s.Packages.List(ctx, filter)
+}
+
+func (s *WorkPackageService) Update(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, input WorkPackageUpdateInput) (domain.WorkPackage, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return domain.WorkPackage{}, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	if !pkg.IsOpen() {
+		return domain.WorkPackage{}, domain.NewConflictError("work package is closed")
+	}
+	if input.Name != nil {
+		pkg.Name = *input.Name
+	}
+	if input.VisitStart != nil {
+		pkg.VisitStart = input.VisitStart.UTC()
+	}
+	if input.VisitEnd != nil {
+		pkg.VisitEnd = input.VisitEnd.UTC()
+	}
+	if input.Notes != nil {
+		pkg.Notes = *input.Notes
+	}
+	if err := pkg.Validate(); err != nil {
+		return domain.WorkPackage{}, err
+	}
+	if input.VisitStart != nil || input.VisitEnd != nil {
+		tasks, err := s.packageTasks(ctx, pkg)
+		if err != nil {
+			return domain.WorkPackage{}, err
+		}
+		for _, task := range tasks {
+			if isActiveTask(task) && !pkg.Covers(task) {
+				return domain.WorkPackage{}, domain.NewConflictError("visit window must cover the package's open tasks")
+			}
+		}
+	}
+	pkg.UpdatedAt = s.Clock.Now()
+	updated, err := s.Packages.Update(ctx, pkg)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	s.audit(ctx, actor, updated, domain.AuditActionUpdate, nil)
+	return updated, nil
+}
+
+// Delete removes a planned or cancelled package. Its tasks are kept and
+// detached.
+func (s *WorkPackageService) Delete(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if pkg.State != domain.WorkPackagePlanned && pkg.State != domain.WorkPackageCancelled {
+		return domain.NewConflictError("only planned or cancelled work packages can be deleted")
+	}
+	if err := s.Packages.SoftDelete(ctx, orgID, id, s.Clock.Now()); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, pkg, domain.AuditActionDelete, nil)
+	return nil
+}
+
+func (s *WorkPackageService) TransitionState(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, newState domain.WorkPackageState) (domain.WorkPackage, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	tasks, err := s.packageTasks(ctx, pkg)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	summary, err := s.summarize(ctx, pkg, tasks)
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	if err := pkg.CanTransition(newState, domain.WorkPackageTransitionContext{ActorRole: actor.Role, Summary: summary}); err != nil {
+		return domain.WorkPackage{}, err
+	}
+	if pkg.State == newState {
+		return pkg, nil
+	}
+	updated, err := s.Packages.UpdateState(ctx, pkg.OrgID, pkg.ID, newState, s.Clock.Now())
+	if err != nil {
+		return domain.WorkPackage{}, err
+	}
+	s.audit(ctx, actor, updated, domain.AuditActionStateChange, map[string]any{
+		"from_state": pkg.State,
+		"new_state":  newState,
+	})
+	s.emit(ctx, updated, "work_package_state_changed", fmt.Sprintf("work_package_state_changed:%s:%s:%s", updated.OrgID, updated.ID, newState))
+	return updated, nil
+}
+
+// AddTasks adds existing tasks of the package's aircraft. Every task must be
+// open, scheduled inside the visit window and not part of another package.
+func (s *WorkPackageService) AddTasks(ctx context.Context, actor app.Actor, orgID, id uuid.UUID, taskIDs []uuid.UUID) ([]domain.MaintenanceTask, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return nil, domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]domain.MaintenanceTask, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		task, err := s.Tasks.GetByID(ctx, pkg.OrgID, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if err := pkg.CanAddTask(task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	added := make([]domain.MaintenanceTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.WorkPackageID != nil {
+			added = append(added, task)
+			continue
+		}
+		updated, err := s.attach(ctx, pkg, task)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, updated)
+	}
+	s.audit(ctx, actor, pkg, domain.AuditActionUpdate, map[string]any{"added_task_ids": taskIDs})
+	return added, nil
+}
+
+func (s *WorkPackageService) RemoveTask(ctx context.Context, actor app.Actor, orgID, id, taskID uuid.UUID) error {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return domain.ErrForbidden
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if !pkg.IsOpen() {
+		return domain.NewConflictError("work package is closed")
+	}
+	task, err := s.Tasks.GetByID(ctx, pkg.OrgID, taskID)
+	if err != nil {
+		return err
+	}
+	if task.WorkPackageID == nil || *task.WorkPackageID != pkg.ID {
+		return domain.ErrNotFound
+	}
+	task.WorkPackageID = nil
+	task.UpdatedAt = s.Clock.Now()
+	if _, err := s.Tasks.Update(ctx, task); err != nil {
+		return err
+	}
+	s.audit(ctx, actor, pkg, domain.AuditActionUpdate, map[string]any{"removed_task_id": taskID})
+	return nil
+}
+
+// AutoFill pulls every program of the aircraft falling due by the end of the
+// visit into the package. Open tasks of those programs are added, and moved
+// into the visit if still scheduled elsewhere; programs without an open task
+// get a new task lasting the program's estimated duration. Tasks are placed
+// one after another in the free time of the visit, ordered by due date.
+func (s *WorkPackageService) AutoFill(ctx context.Context, actor app.Actor, orgID, id uuid.UUID) (WorkPackageAutoFillResult, error) {
+	if s.Clock == nil {
+		s.Clock = app.RealClock{}
+	}
+	if !canPlanWorkPackages(actor) {
+		return WorkPackageAutoFillResult{}, domain.ErrForbidden
+	}
+	if s.Programs == nil || s.TaskSvc == nil {
+		return WorkPackageAutoFillResult{}, domain.NewValidationError("program service unavailable")
+	}
+	if !actor.IsAdmin() {
+		orgID = actor.OrgID
+	}
+	pkg, err := s.Packages.GetByID(ctx, orgID, id)
+	if err != nil {
+		return WorkPackageAutoFillResult{}, err
+	}
+	if !pkg.IsOpen() {
+		return WorkPackageAutoFillResult{}, domain.NewConflictError("work package is closed")
+	}
+	now := s.Clock.Now()
+	if !pkg.VisitEnd.After(now) {
+		return WorkPackageAutoFillResult{}, domain.NewConflictError("visit window has already ended")
+	}
+	horizon := int(math.Ceil(pkg.VisitEnd.Sub(now).Hours() / 24))
+	if horizon > maxForecastHorizonDays {
+		return WorkPackageAutoFillResult{}, domain.NewValidationError("visit window is too far out to auto-fill")
+	}
+	due, err := s.Programs.Forecast(ctx, actor, ProgramForecastInput{
+		OrgID:       &pkg.OrgID,
+		AircraftID:  &pkg.AircraftID,
+		HorizonDays: horizon,
+		Occurrences: 1,
+	})
+	if err != nil {
+		return WorkPackageAutoFillResult{}, err
+	}
+	busy, err := s.openTasks(ctx, ports.TaskFilter{OrgID: &pkg.OrgID, AircraftID: &pkg.AircraftID, StartTo: &pkg.VisitEnd})
+	if err != nil {
+		return WorkPackageAutoFillResult{}, err
+	}
+
+	result := WorkPackageAutoFillResult{}
+	for _, item := range due {
+		if item.DueAt.After(pkg.VisitEnd) {
+			continue
+		}
+		open, err := s.openTasks(ctx, ports.TaskFilter{OrgID: &pkg.OrgID, AircraftID: &pkg.AircraftID, ProgramID: &item.ProgramID})
+		if err != nil {
+			return result, err
+		}
+		if len(open) == 0 {
+			program, err := s.Programs.Programs.GetByID(ctx, pkg.OrgID, item.ProgramID)
+			if err != nil {
+				return result, err
+			}
+			start, end, ok := pkg.NextSlot(busy, now, program.TaskDuration())
+			if !ok {
+				result.Unplaced = append(result.Unplaced, item)
+				continue
+			}
+			input := programTaskInput(program, start, end)
+			input.WorkPackageID = &pkg.ID
+			created, err := s.TaskSvc.Create(ctx, actor, input)
+			if err != nil {
+				return result, err
+			}
+			busy = append(busy, created)
+			result.Added = append(result.Added, created)
+			continue
+		}
+
+		task := open[0]
+		if task.WorkPackageID != nil {
+			if *task.WorkPackageID != pkg.ID {
+				result.Unplaced = append(result.Unplaced, item)
+			}
+			continue
+		}
+		if !pkg.Covers(task) {
+			if task.State != domain.TaskStateScheduled {
+				result.Unplaced = append(result.Unplaced, item)
+				continue
+			}
+			start, end, ok := pkg.NextSlot(withoutTask(busy, task.ID), now, task.EndTime.Sub(task.StartTime))
+			if !ok {
+				result.Unplaced = append(result.Unplaced, item)
+				continue
+			}
+			task, err = s.TaskSvc.Update(ctx, actor, pkg.OrgID, task.ID, TaskUpdateInput{StartTime: &start, EndTime: &end})
+			if err != nil {
+				return result, err
+			}
+		}
+		attached, err := s.attach(ctx, pkg, task)
+		if err != nil {
+			return result, err
+		}
+		busy = append(withoutTask(busy, attached.ID), attached)
+		result.Added = append(result.Added, attached)
+	}
+
+	if len(result.Added) > 0 {
+		taskIDs := make([]uuid.UUID, 0, len(result.Added))
+		for _, task := range result.Added {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		s.audit(ctx, actor, pkg, domain.AuditActionUpdate, map[string]any{"auto_filled_task_ids": taskIDs})
+	}
+	return result, nil
+}
+
+func (s *WorkPackageService) attach(ctx context.Context, pkg domain.WorkPackage, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
+	task.WorkPackageID = &pkg.ID
+	task.UpdatedAt = s.Clock.Now()
+	return s.Tasks.Update(ctx, task)
+}
+
+func (s *WorkPackageService) packageTasks(ctx context.Context, pkg domain.WorkPackage) ([]domain.MaintenanceTask, error) {
+	var tasks []domain.MaintenanceTask
+	for offset := 0; ; offset += workPackagePageSize {
+		page, err := s.Tasks.List(ctx, ports.TaskFilter{
+			OrgID:         &pkg.OrgID,
+			WorkPackageID: &pkg.ID,
+			Limit:         workPackagePageSize,
+			Offset:        offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < workPackagePageSize {
+			return tasks, nil
+		}
+	}
+}
+
+// openTasks lists the scheduled and in progress tasks matching the filter.
+func (s *WorkPackageService) openTasks(ctx context.Context, filter ports.TaskFilter) ([]domain.MaintenanceTask, error) {
+	var tasks []domain.MaintenanceTask
+	for _, state := range []domain.TaskState{domain.TaskStateScheduled, domain.TaskStateInProgress} {
+		state := state
+		filter.State = &state
+		for offset := 0; ; offset += workPackagePageSize {
+			filter.Limit = workPackagePageSize
+			filter.Offset = offset
+			page, err := s.Tasks.List(ctx, filter)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, page...)
+			if len(page) < workPackagePageSize {
+				break
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (s *WorkPackageService) summarize(ctx context.Context, pkg domain.WorkPackage, tasks []domain.MaintenanceTask) (domain.WorkPackageSummary, error) {
+	var reservations []domain.PartReservation
+	var compliance []domain.ComplianceItem
+	for _, task := range tasks {
+		if s.Reservations != nil {
+			list, err := s.Reservations.ListByTask(ctx, pkg.OrgID, task.ID)
+			if err != nil {
+				return domain.WorkPackageSummary{}, err
+			}
+			reservations = append(reservations, list...)
+		}
+		if s.Compliance != nil {
+			items, err := s.Compliance.ListByTask(ctx, pkg.OrgID, task.ID)
+			if err != nil {
+				return domain.WorkPackageSummary{}, err
+			}
+			compliance = append(compliance, items...)
+		}
+	}
+	summary := domain.SummarizeWorkPackage(tasks, reservations, compliance)
+	if s.Findings != nil && len(tasks) > 0 {
+		taskIDs := make([]uuid.UUID, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		open, err := s.Findings.CountOpen(ctx, pkg.OrgID, taskIDs)
+		if err != nil {
+			return domain.WorkPackageSummary{}, err
+		}
+		summary.OpenFindings = open
+	}
+	return summary, nil
+}
+
+func (s *WorkPackageService) audit(ctx context.Context, actor app.Actor, pkg domain.WorkPackage, action domain.AuditAction, details map[string]any) {
+	if s.Audit == nil {
+		return
+	}
+	_ = s.Audit.Insert(ctx, domain.AuditLog{
+		ID:         uuid.New(),
+		OrgID:      pkg.OrgID,
+		EntityType: "work_package",
+		EntityID:   pkg.ID,
+		Action:     action,
+		UserID:     actor.UserID,
+		RequestID:  uuid.Nil,
+		Timestamp:  s.Clock.Now(),
+		Details:    details,
+	})
+}
+
+func (s *WorkPackageService) emit(ctx context.Context, pkg domain.WorkPackage, eventType, dedupeKey string) {
+	if s.Outbox == nil {
+		return
+	}
+	payload := map[string]any{
+		"version":         1,
+		"org_id":          pkg.OrgID,
+		"work_package_id": pkg.ID,
+		"aircraft_id":     pkg.AircraftID,
+		"state":           pkg.State,
+		"visit_start":     pkg.VisitStart,
+		"visit_end":       pkg.VisitEnd,
+		"timestamp":       s.Clock.Now(),
+	}
+	_ = s.Outbox.Enqueue(ctx, pkg.OrgID, eventType, "work_package", pkg.ID, payload, dedupeKey)
+}
+
+func canPlanWorkPackages(actor app.Actor) bool {
+	return actor.Role == domain.RoleScheduler || actor.Role == domain.RoleAdmin || actor.Role == domain.RoleTenantAdmin
+}
+
+func isActiveTask(task domain.MaintenanceTask) bool {
+	return task.State == domain.TaskStateScheduled || task.State == domain.TaskStateInProgress
+}
+
+func withoutTask(tasks []domain.MaintenanceTask, id uuid.UUID) []domain.MaintenanceTask {
+	out := make([]domain.MaintenanceTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ID != id {
+			out = append(out, task)
+		}
+	}
+	return out
+}
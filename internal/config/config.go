@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -27,6 +28,18 @@ type Config struct {
 	CorsAllowedOrigins       []string
 	WorkerID                 string
 	RetentionCleanupInterval time.Duration
+	ProgramLookAheadDays     int
+	ProgramLookAheadHours    int
+	ProgramLookAheadCycles   int
+	PartLifeWarningPercent   int
+	PartLifeCriticalPercent  int
+	AttachmentStore          string
+	AttachmentStorageDir     string
+	S3Endpoint               string
+	S3Region                 string
+	S3Bucket                 string
+	S3AccessKeyID            string
+	S3SecretAccessKey        string
 }
 
 func Load() (Config, error) {
@@ -49,6 +62,18 @@ func Load() (Config, error) {
 		CorsAllowedOrigins:       splitCSV(os.Getenv("CORS_ALLOWED_ORIGINS")),
 		WorkerID:                 getEnv("WORKER_ID", "worker-1"),
 		RetentionCleanupInterval: getDuration("RETENTION_CLEANUP_INTERVAL", 24*time.Hour),
+		ProgramLookAheadDays:     getInt("PROGRAM_LOOKAHEAD_DAYS", 7),
+		ProgramLookAheadHours:    getInt("PROGRAM_LOOKAHEAD_HOURS", 25),
+		ProgramLookAheadCycles:   getInt("PROGRAM_LOOKAHEAD_CYCLES", 10),
+		PartLifeWarningPercent:   getInt("PART_LIFE_WARNING_PERCENT", 10),
+		PartLifeCriticalPercent:  getInt("PART_LIFE_CRITICAL_PERCENT", 5),
+		AttachmentStore:          getEnv("ATTACHMENT_STORE", "filesystem"),
+		AttachmentStorageDir:     getEnv("ATTACHMENT_STORAGE_DIR", "./data/attachments"),
+		S3Endpoint:               os.Getenv("S3_ENDPOINT"),
+		S3Region:                 getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:                 os.Getenv("S3_BUCKET"),
+		S3AccessKeyID:            os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey:        os.Getenv("S3_SECRET_ACCESS_KEY"),
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -75,6 +100,21 @@ func (c Config) Validate() error {
 	if c.AccessTokenTTL <= 0 || c.RefreshTokenTTL <= 0 {
 		return errors.New("token TTLs must be positive")
 	}
+	if c.ProgramLookAheadDays < 0 || c.ProgramLookAheadHours < 0 || c.ProgramLookAheadCycles < 0 {
+		return errors.New("program look-ahead values must not be negative")
+	}
+	if c.PartLifeCriticalPercent < 0 || c.PartLifeWarningPercent < c.PartLifeCriticalPercent || c.PartLifeWarningPercent > 100 {
+		return errors.New("part life alert percentages must satisfy 0 <= critical <= warning <= 100")
+	}
+	switch c.AttachmentStore {
+	case "filesystem":
+	case "s3":
+		if c.S3Endpoint == "" || c.S3Bucket == "" {
+			return errors.New("ATTACHMENT_STORE=s3 requires S3_ENDPOINT and S3_BUCKET")
+		}
+	default:
+		return fmt.Errorf("ATTACHMENT_STORE must be filesystem or s3, got %q", c.AttachmentStore)
+	}
 	return nil
 }
 
@@ -97,6 +137,18 @@ func getDuration(key string, fallback time.Duration) time.Duration {
 	return d
 }
 
+func getInt(key string, fallback int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
 func getBool(key string, fallback bool) bool {
 	v := strings.TrimSpace(os.Getenv(key))
 	if v == "" {
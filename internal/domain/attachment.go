@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AttachmentEntityType string
+
+const (
+	AttachmentTask           AttachmentEntityType = "task"
+	AttachmentComplianceItem AttachmentEntityType = "compliance_item"
+	AttachmentFinding        AttachmentEntityType = "finding"
+	AttachmentCertification  AttachmentEntityType = "certification"
+)
+
+func (t AttachmentEntityType) IsValid() bool {
+	switch t {
+	case AttachmentTask, AttachmentComplianceItem, AttachmentFinding, AttachmentCertification:
+		return true
+	}
+	return false
+}
+
+// DefaultMaxAttachmentBytes is the upload limit for organizations without
+// one set in their policy.
+const DefaultMaxAttachmentBytes int64 = 25 << 20
+
+// maxAttachmentFileName caps the stored file name.
+const maxAttachmentFileName = 255
+
+// Attachment is a file, such as a photo or a scanned form, kept as evidence
+// against a task, compliance item, finding or certification. The content
+// lives in the blob store under StorageKey; SHA256 is the hex digest of the
+// content and ContentType is sniffed from it rather than taken from the
+// client.
+type Attachment struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	EntityType  AttachmentEntityType
+	EntityID    uuid.UUID
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	SHA256      string
+	StorageKey  string
+	UploadedBy  uuid.UUID
+	DeletedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (a Attachment) Validate() error {
+	if !a.EntityType.IsValid() {
+		return NewValidationError("entity_type must be task, compliance_item, finding or certification")
+	}
+	if a.EntityID == uuid.Nil {
+		return NewValidationError("entity_id is required")
+	}
+	if strings.TrimSpace(a.FileName) == "" {
+		return NewValidationError("file name is required")
+	}
+	if len(a.FileName) > maxAttachmentFileName {
+		return NewValidationError("file name must be at most 255 characters")
+	}
+	if a.SizeBytes <= 0 {
+		return NewValidationError("file is empty")
+	}
+	return nil
+}
+
+// AttachmentStorageKey is the blob store key for an attachment's content.
+// Keys are prefixed with the organization so a tenant's blobs can be listed
+// and removed together.
+func AttachmentStorageKey(orgID uuid.UUID, entityType AttachmentEntityType, id uuid.UUID) string {
+	return orgID.String() + "/" + string(entityType) + "/" + id.String()
+}
+
+// IsActiveContentType reports whether content of the type would be rendered
+// as a page by a browser. Such uploads are rejected so an attachment cannot
+// be used to serve script from the API's origin.
+func IsActiveContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch mediaType {
+	case "text/html", "text/xml", "application/xml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// SanitizeFileName strips any path from a client supplied file name.
+func SanitizeFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ComponentChangeAction string
+
+type ComponentChangeReason string
+
+const (
+	ComponentInstall ComponentChangeAction = "install"
+	ComponentRemove  ComponentChangeAction = "remove"
+)
+
+const (
+	ComponentChangeScheduled   ComponentChangeReason = "scheduled"
+	ComponentChangeUnscheduled ComponentChangeReason = "unscheduled"
+	// ComponentChangeRobbery marks a serviceable part taken from one aircraft
+	// to be fitted to another.
+	ComponentChangeRobbery ComponentChangeReason = "robbery"
+)
+
+func (r ComponentChangeReason) IsValid() bool {
+	switch r {
+	case ComponentChangeScheduled, ComponentChangeUnscheduled, ComponentChangeRobbery:
+		return true
+	default:
+		return false
+	}
+}
+
+// ComponentChange records a serialized part being fitted to or removed from
+// an aircraft position, with the aircraft and part usage at that moment.
+type ComponentChange struct {
+	ID                      uuid.UUID
+	OrgID                   uuid.UUID
+	PartItemID              uuid.UUID
+	AircraftID              uuid.UUID
+	Action                  ComponentChangeAction
+	Position                string
+	TaskID                  *uuid.UUID
+	Reason                  ComponentChangeReason
+	AircraftHours           float64
+	AircraftCycles          int
+	PartHoursSinceNew       float64
+	PartCyclesSinceNew      int
+	PartHoursSinceOverhaul  float64
+	PartCyclesSinceOverhaul int
+	PerformedBy             *uuid.UUID
+	PerformedAt             time.Time
+	Notes                   string
+	CreatedAt               time.Time
+}
+
+func (c ComponentChange) Validate() error {
+	if c.Action != ComponentInstall && c.Action != ComponentRemove {
+		return NewValidationError("action must be install or remove")
+	}
+	if strings.TrimSpace(c.Position) == "" {
+		return NewValidationError("position is required")
+	}
+	if !c.Reason.IsValid() {
+		return NewValidationError("reason must be scheduled, unscheduled or robbery")
+	}
+	return nil
+}
+
+// CanInstall checks that the part is free to be fitted to an aircraft.
+func (p PartItem) CanInstall() error {
+	if p.AircraftID != nil {
+		return NewConflictError("part is already installed")
+	}
+	if p.Status == PartItemDisposed {
+		return NewConflictError("disposed parts cannot be installed")
+	}
+	if percent, ok := p.RemainingLifePercent(); ok && percent <= 0 {
+		return NewConflictError("part has reached its life limit")
+	}
+	return nil
+}
+
+// CanRemove checks that the part is currently fitted to the aircraft.
+func (p PartItem) CanRemove(aircraftID uuid.UUID) error {
+	if p.AircraftID == nil || *p.AircraftID != aircraftID {
+		return NewConflictError("part is not installed on this aircraft")
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DefectState string
+
+const (
+	DefectOpen      DefectState = "open"
+	DefectDeferred  DefectState = "deferred"
+	DefectRectified DefectState = "rectified"
+	DefectClosed    DefectState = "closed"
+)
+
+// MELCategory is the rectification interval category of a Minimum
+// Equipment List item.
+type MELCategory string
+
+const (
+	// MELCategoryA items state their own interval in the MEL remarks.
+	MELCategoryA MELCategory = "A"
+	MELCategoryB MELCategory = "B"
+	MELCategoryC MELCategory = "C"
+	MELCategoryD MELCategory = "D"
+)
+
+// Defect is a fault reported against an aircraft. An open defect may be
+// deferred under an MEL item, letting the aircraft operate under the item's
+// operational restrictions until ExpiresAt. A deferred defect whose expiry
+// has passed grounds the aircraft until it is rectified.
+type Defect struct {
+	ID                      uuid.UUID
+	OrgID                   uuid.UUID
+	AircraftID              uuid.UUID
+	Title                   string
+	Description             string
+	ATAChapter              string
+	State                   DefectState
+	ReportedBy              uuid.UUID
+	ReportedAt              time.Time
+	MELCategory             *MELCategory
+	MELItem                 string
+	DeferralReference       string
+	OperationalRestrictions string
+	DeferredBy              *uuid.UUID
+	DeferredAt              *time.Time
+	ExpiresAt               *time.Time
+	RectificationTaskID     *uuid.UUID
+	RectifiedBy             *uuid.UUID
+	RectifiedAt             *time.Time
+	ClosedBy                *uuid.UUID
+	ClosedAt                *time.Time
+	Notes                   string
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+// DefectDeferral is what a defect is deferred under. ExpiresAt is required
+// for category A and may shorten the interval of the other categories.
+type DefectDeferral struct {
+	Category                MELCategory
+	MELItem                 string
+	Reference               string
+	OperationalRestrictions string
+	ExpiresAt               *time.Time
+}
+
+// DeferralExtension moves a deferral's expiry out under an approval of the
+// quality department.
+type DeferralExtension struct {
+	ID                uuid.UUID
+	OrgID             uuid.UUID
+	DefectID          uuid.UUID
+	PreviousExpiresAt time.Time
+	NewExpiresAt      time.Time
+	Reason            string
+	ApprovalReference string
+	ApprovedBy        uuid.UUID
+	CreatedAt         time.Time
+}
+
+func (c MELCategory) IsValid() bool {
+	return c == MELCategoryA || c == MELCategoryB || c == MELCategoryC || c == MELCategoryD
+}
+
+// RectificationDays returns the calendar days the category allows. ok is
+// false for category A, whose interval the MEL item states.
+func (c MELCategory) RectificationDays() (int, bool) {
+	switch c {
+	case MELCategoryB:
+		return 3, true
+	case MELCategoryC:
+		return 10, true
+	case MELCategoryD:
+		return 120, true
+	default:
+		return 0, false
+	}
+}
+
+// DeferralExpiry returns the end of the category's rectification interval
+// for a deferral made at deferredAt. The day of deferral is not counted, so
+// the interval ends at UTC midnight after its last calendar day.
+func (c MELCategory) DeferralExpiry(deferredAt time.Time) (time.Time, bool) {
+	days, ok := c.RectificationDays()
+	if !ok {
+		return time.Time{}, false
+	}
+	day := deferredAt.UTC()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return start.AddDate(0, 0, days+1), true
+}
+
+// Extendable reports whether deferrals of the category may be extended.
+// Only categories B and C allow a rectification interval extension.
+func (c MELCategory) Extendable() bool {
+	return c == MELCategoryB || c == MELCategoryC
+}
+
+func (d Defect) Validate() error {
+	if d.AircraftID == uuid.Nil {
+		return NewValidationError("aircraft_id is required")
+	}
+	if strings.TrimSpace(d.Title) == "" {
+		return NewValidationError("title is required")
+	}
+	if d.ReportedAt.IsZero() {
+		return NewValidationError("reported_at is required")
+	}
+	return nil
+}
+
+// Expired reports whether the defect is deferred past its expiry.
+func (d Defect) Expired(now time.Time) bool {
+	return d.State == DefectDeferred && d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)
+}
+
+// Defer records the deferral on an open defect and sets its expiry.
+func (d *Defect) Defer(deferral DefectDeferral, userID uuid.UUID, now time.Time) error {
+	if d.State != DefectOpen {
+		return NewConflictError("only open defects can be deferred")
+	}
+	if !deferral.Category.IsValid() {
+		return NewValidationError("mel_category must be A, B, C or D")
+	}
+	if strings.TrimSpace(deferral.MELItem) == "" {
+		return NewValidationError("mel_item is required")
+	}
+	if strings.TrimSpace(deferral.Reference) == "" {
+		return NewValidationError("deferral_reference is required")
+	}
+	expiresAt, ok := deferral.Category.DeferralExpiry(now)
+	switch {
+	case !ok && deferral.ExpiresAt == nil:
+		return NewValidationError("expires_at is required for category A")
+	case deferral.ExpiresAt != nil && !deferral.ExpiresAt.After(now):
+		return NewValidationError("expires_at must be in the future")
+	case ok && deferral.ExpiresAt != nil && deferral.ExpiresAt.After(expiresAt):
+		return NewValidationError("expires_at is beyond the category " + string(deferral.Category) + " interval ending " + expiresAt.Format(time.RFC3339))
+	case deferral.ExpiresAt != nil:
+		expiresAt = deferral.ExpiresAt.UTC()
+	}
+
+	category := deferral.Category
+	d.State = DefectDeferred
+	d.MELCategory = &category
+	d.MELItem = strings.TrimSpace(deferral.MELItem)
+	d.DeferralReference = strings.TrimSpace(deferral.Reference)
+	d.OperationalRestrictions = strings.TrimSpace(deferral.OperationalRestrictions)
+	d.DeferredBy = &userID
+	d.DeferredAt = &now
+	d.ExpiresAt = &expiresAt
+	return nil
+}
+
+// CanExtend checks that the deferral's expiry may move to newExpiry. A
+// deferral is extended at most once, before it expires, by no more than
+// the category's interval.
+func (d Defect) CanExtend(newExpiry time.Time, extensions int, now time.Time) error {
+	if d.State != DefectDeferred || d.MELCategory == nil || d.ExpiresAt == nil {
+		return NewConflictError("only deferred defects can be extended")
+	}
+	if !d.MELCategory.Extendable() {
+		return NewConflictError("category " + string(*d.MELCategory) + " deferrals cannot be extended")
+	}
+	if extensions > 0 {
+		return NewConflictError("deferral has already been extended")
+	}
+	if d.Expired(now) {
+		return NewConflictError("deferral has already expired")
+	}
+	if !newExpiry.After(*d.ExpiresAt) {
+		return NewValidationError("new_expires_at must be after the current expiry")
+	}
+	days, _ := d.MELCategory.RectificationDays()
+	if limit := d.ExpiresAt.AddDate(0, 0, days); newExpiry.After(limit) {
+		return NewValidationError("extension may not exceed the category " + string(*d.MELCategory) + " interval of " + strconv.Itoa(days) + " days")
+	}
+	return nil
+}
+
+// CanRectify checks that the defect may be signed off as rectified.
+func (d Defect) CanRectify() error {
+	if d.State != DefectOpen && d.State != DefectDeferred {
+		return NewConflictError("defect is " + string(d.State))
+	}
+	return nil
+}
+
+// CanClose checks that the defect may be closed. Rectified defects close
+// after review; open defects close without rectification only with notes
+// saying why, such as no fault found.
+func (d Defect) CanClose(notes string) error {
+	switch d.State {
+	case DefectRectified:
+		return nil
+	case DefectOpen:
+		if strings.TrimSpace(notes) == "" {
+			return NewValidationError("notes are required to close an unrectified defect")
+		}
+		return nil
+	default:
+		return NewConflictError("defect is " + string(d.State))
+	}
+}
+
+// CheckReturnToService rejects returning an aircraft to service while any
+// of its defects is deferred past expiry.
+func CheckReturnToService(defects []Defect, now time.Time) error {
+	var expired []string
+	for _, defect := range defects {
+		if defect.Expired(now) {
+			expired = append(expired, defect.DeferralReference)
+		}
+	}
+	if len(expired) > 0 {
+		return NewConflictError("aircraft has expired deferrals: " + strings.Join(expired, ", "))
+	}
+	return nil
+}
@@ -20,3 +20,48 @@ func NewValidationError(message string) error {
 func NewConflictError(message string) error {
 	return fmt.Errorf("%w: %s", ErrConflict, message)
 }
+
+// CapacityConflictKind identifies which scheduling constraint a booking
+// violates.
+type CapacityConflictKind string
+
+const (
+	// CapacityConflictAircraftOverlap means the aircraft already has an
+	// active task in the window.
+	CapacityConflictAircraftOverlap CapacityConflictKind = "aircraft_overlap"
+	// CapacityConflictBayFull means the hangar bay has no slot free for the
+	// whole window.
+	CapacityConflictBayFull CapacityConflictKind = "bay_capacity"
+	// CapacityConflictMechanicOverlap means the assigned mechanic already
+	// works another active task in the window.
+	CapacityConflictMechanicOverlap CapacityConflictKind = "mechanic_overlap"
+	// CapacityConflictMechanicUnavailable means the window falls outside the
+	// mechanic's rostered shifts or into an absence.
+	CapacityConflictMechanicUnavailable CapacityConflictKind = "mechanic_unavailable"
+	// CapacityConflictFlightOverlap means the aircraft is planned to be
+	// flying during the window.
+	CapacityConflictFlightOverlap CapacityConflictKind = "flight_overlap"
+	// CapacityConflictLaborOverlap means the mechanic already has labor
+	// booked, or is clocked in, during the entry's time.
+	CapacityConflictLaborOverlap CapacityConflictKind = "labor_overlap"
+)
+
+// CapacityConflictError is a conflict raised when a task does not fit the
+// existing bookings of its aircraft, hangar bay or mechanic. It matches
+// ErrConflict.
+type CapacityConflictError struct {
+	Kind    CapacityConflictKind
+	Message string
+}
+
+func (e *CapacityConflictError) Error() string {
+	return ErrConflict.Error() + ": " + e.Message
+}
+
+func (e *CapacityConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+func NewCapacityConflict(kind CapacityConflictKind, message string) error {
+	return &CapacityConflictError{Kind: kind, Message: message}
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindingState string
+
+const (
+	FindingOpen      FindingState = "open"
+	FindingConverted FindingState = "converted"
+	FindingClosed    FindingState = "closed"
+)
+
+// maxFindingPhotos caps the photos attached to one finding.
+const maxFindingPhotos = 20
+
+// Finding is non-routine work discovered while a task is carried out, such
+// as corrosion found during an inspection. An open finding is either
+// converted into a repair task or closed when no work is needed.
+type Finding struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	TaskID     uuid.UUID
+	AircraftID uuid.UUID
+	// ComplianceItemID is the failed compliance item the finding was raised
+	// from, if any.
+	ComplianceItemID *uuid.UUID
+	Description      string
+	Zone             string
+	ATAChapter       string
+	PhotoURLs        []string
+	State            FindingState
+	RaisedBy         uuid.UUID
+	RaisedAt         time.Time
+	RepairTaskID     *uuid.UUID
+	ConvertedBy      *uuid.UUID
+	ConvertedAt      *time.Time
+	ClosedBy         *uuid.UUID
+	ClosedAt         *time.Time
+	ResolutionNotes  string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (f Finding) Validate() error {
+	if f.TaskID == uuid.Nil || f.AircraftID == uuid.Nil {
+		return NewValidationError("task_id and aircraft_id are required")
+	}
+	if strings.TrimSpace(f.Description) == "" {
+		return NewValidationError("description is required")
+	}
+	if len(f.PhotoURLs) > maxFindingPhotos {
+		return NewValidationError("a finding takes at most 20 photos")
+	}
+	for _, photo := range f.PhotoURLs {
+		parsed, err := url.Parse(photo)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return NewValidationError("photo_urls must be http or https URLs")
+		}
+	}
+	return nil
+}
+
+// CanRaiseFinding checks that findings may be raised against the task.
+// Findings come out of work being carried out, so the task must be in
+// progress.
+func CanRaiseFinding(task MaintenanceTask) error {
+	if task.State != TaskStateInProgress {
+		return NewConflictError("findings can only be raised against in progress tasks")
+	}
+	return nil
+}
+
+// CanConvert checks that the finding may be turned into a repair task.
+func (f Finding) CanConvert() error {
+	if f.State != FindingOpen {
+		return NewConflictError("finding is " + string(f.State))
+	}
+	return nil
+}
+
+// CanClose checks that the finding may be closed without a repair task,
+// which needs notes saying why no work is required.
+func (f Finding) CanClose(notes string) error {
+	if f.State != FindingOpen {
+		return NewConflictError("finding is " + string(f.State))
+	}
+	if strings.TrimSpace(notes) == "" {
+		return NewValidationError("notes are required to close a finding")
+	}
+	return nil
+}
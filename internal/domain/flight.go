@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FlightStatus string
+
+const (
+	FlightScheduled FlightStatus = "scheduled"
+	FlightCancelled FlightStatus = "cancelled"
+)
+
+// PlannedFlight is a flight an aircraft is planned to operate. Origin and
+// Destination are station codes; they need not be maintenance stations.
+type PlannedFlight struct {
+	ID            uuid.UUID
+	OrgID         uuid.UUID
+	AircraftID    uuid.UUID
+	FlightNumber  string
+	Origin        string
+	Destination   string
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+	Status        FlightStatus
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// GroundWindow is a stretch of time an aircraft spends on the ground at one
+// station between planned flights. Station is empty when no flight tells
+// where the aircraft is. ArrivalFlightID and DepartureFlightID are nil
+// where the window is cut off by the queried range rather than a flight.
+type GroundWindow struct {
+	AircraftID        uuid.UUID
+	Station           string
+	Start             time.Time
+	End               time.Time
+	ArrivalFlightID   *uuid.UUID
+	DepartureFlightID *uuid.UUID
+}
+
+func (s FlightStatus) IsValid() bool {
+	return s == FlightScheduled || s == FlightCancelled
+}
+
+func (f PlannedFlight) Validate() error {
+	if f.AircraftID == uuid.Nil {
+		return NewValidationError("aircraft_id is required")
+	}
+	if strings.TrimSpace(f.FlightNumber) == "" {
+		return NewValidationError("flight_number is required")
+	}
+	if strings.TrimSpace(f.Origin) == "" || strings.TrimSpace(f.Destination) == "" {
+		return NewValidationError("origin and destination are required")
+	}
+	if f.DepartureTime.IsZero() {
+		return NewValidationError("departure_time is required")
+	}
+	if !f.ArrivalTime.After(f.DepartureTime) {
+		return NewValidationError("arrival_time must be after departure_time")
+	}
+	if !f.Status.IsValid() {
+		return NewValidationError("status must be scheduled or cancelled")
+	}
+	return nil
+}
+
+// Overlaps reports whether the aircraft is away on the flight at some time
+// in [start, end). Cancelled flights never overlap.
+func (f PlannedFlight) Overlaps(start, end time.Time) bool {
+	return f.Status == FlightScheduled && f.DepartureTime.Before(end) && f.ArrivalTime.After(start)
+}
+
+func (w GroundWindow) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Covers reports whether the aircraft is on the ground for all of
+// [start, end) within the window.
+func (w GroundWindow) Covers(start, end time.Time) bool {
+	return !w.Start.After(start) && !w.End.Before(end)
+}
+
+// AtStation reports whether the window is at the station with the given
+// code. A window of unknown station matches any station.
+func (w GroundWindow) AtStation(code string) bool {
+	return w.Station == "" || code == "" || strings.EqualFold(w.Station, code)
+}
+
+// GroundWindows returns the aircraft's time on the ground within
+// [from, to). flights are the aircraft's flights overlapping the range;
+// cancelled ones are ignored. previous, when known, is its last flight
+// arriving by from and tells where the first window is.
+func GroundWindows(aircraftID uuid.UUID, previous *PlannedFlight, flights []PlannedFlight, from, to time.Time) []GroundWindow {
+	if !to.After(from) {
+		return nil
+	}
+	scheduled := make([]PlannedFlight, 0, len(flights))
+	for _, flight := range flights {
+		if flight.AircraftID == aircraftID && flight.Overlaps(from, to) {
+			scheduled = append(scheduled, flight)
+		}
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].DepartureTime.Before(scheduled[j].DepartureTime) })
+
+	var windows []GroundWindow
+	cursor := from
+	station := ""
+	var arrival *uuid.UUID
+	if previous != nil {
+		station = previous.Destination
+		id := previous.ID
+		arrival = &id
+	}
+	for _, flight := range scheduled {
+		if flight.DepartureTime.After(cursor) {
+			window := GroundWindow{
+				AircraftID:      aircraftID,
+				Station:         station,
+				Start:           cursor,
+				End:             flight.DepartureTime,
+				ArrivalFlightID: arrival,
+			}
+			if window.Station == "" {
+				// The aircraft must have been where the flight leaves from.
+				window.Station = flight.Origin
+			}
+			if window.End.After(to) {
+				window.End = to
+			} else {
+				id := flight.ID
+				window.DepartureFlightID = &id
+			}
+			windows = append(windows, window)
+		}
+		if flight.ArrivalTime.After(cursor) {
+			cursor = flight.ArrivalTime
+		}
+		station = flight.Destination
+		id := flight.ID
+		arrival = &id
+	}
+	if to.After(cursor) {
+		windows = append(windows, GroundWindow{
+			AircraftID:      aircraftID,
+			Station:         station,
+			Start:           cursor,
+			End:             to,
+			ArrivalFlightID: arrival,
+		})
+	}
+	return windows
+}
+
+// ClipGroundWindows cuts the windows down to [from, to), dropping those
+// outside it.
+func ClipGroundWindows(windows []GroundWindow, from, to time.Time) []GroundWindow {
+	var out []GroundWindow
+	for _, window := range windows {
+		if !window.End.After(from) || !window.Start.Before(to) {
+			continue
+		}
+		if window.Start.Before(from) {
+			window.Start = from
+			window.ArrivalFlightID = nil
+		}
+		if window.End.After(to) {
+			window.End = to
+			window.DepartureFlightID = nil
+		}
+		out = append(out, window)
+	}
+	return out
+}
@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Station is a maintenance base with one or more hangar bays.
+type Station struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Code      string
+	Name      string
+	Timezone  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+}
+
+// HangarBay is a bay of a station hangar. CapacitySlots is the number of
+// aircraft it holds at once unless a capacity window says otherwise.
+type HangarBay struct {
+	ID            uuid.UUID
+	OrgID         uuid.UUID
+	StationID     uuid.UUID
+	Code          string
+	Name          string
+	Hangar        string
+	CapacitySlots int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     *time.Time
+}
+
+// BayCapacityWindow overrides a bay's slot count between StartTime and
+// EndTime. A capacity of zero closes the bay.
+type BayCapacityWindow struct {
+	ID            uuid.UUID
+	OrgID         uuid.UUID
+	BayID         uuid.UUID
+	StartTime     time.Time
+	EndTime       time.Time
+	CapacitySlots int
+	Reason        string
+	CreatedAt     time.Time
+}
+
+// CapacityInterval is a stretch of time in which a bay's capacity and the
+// slots taken in it do not change.
+type CapacityInterval struct {
+	Start    time.Time
+	End      time.Time
+	Capacity int
+	Occupied int
+	// FreeSlots lists the slot numbers open for the whole interval.
+	FreeSlots []int
+}
+
+func (s Station) Validate() error {
+	if strings.TrimSpace(s.Code) == "" {
+		return NewValidationError("code is required")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return NewValidationError("name is required")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" {
+		return NewValidationError("timezone must be an IANA time zone")
+	}
+	return nil
+}
+
+func (b HangarBay) Validate() error {
+	if strings.TrimSpace(b.Code) == "" {
+		return NewValidationError("code is required")
+	}
+	if strings.TrimSpace(b.Name) == "" {
+		return NewValidationError("name is required")
+	}
+	if b.CapacitySlots <= 0 {
+		return NewValidationError("capacity_slots must be positive")
+	}
+	return nil
+}
+
+func (w BayCapacityWindow) Validate() error {
+	if !w.EndTime.After(w.StartTime) {
+		return NewValidationError("end_time must be after start_time")
+	}
+	if w.CapacitySlots < 0 {
+		return NewValidationError("capacity_slots must not be negative")
+	}
+	return nil
+}
+
+// Free returns the number of slots open for the whole interval.
+func (i CapacityInterval) Free() int {
+	return len(i.FreeSlots)
+}
+
+// CapacityAt returns the bay's slot count at the given instant.
+func (b HangarBay) CapacityAt(windows []BayCapacityWindow, at time.Time) int {
+	for _, window := range windows {
+		if window.BayID == b.ID && !at.Before(window.StartTime) && at.Before(window.EndTime) {
+			return window.CapacitySlots
+		}
+	}
+	return b.CapacitySlots
+}
+
+// CapacityDuring returns the lowest slot count the bay has between start
+// and end.
+func (b HangarBay) CapacityDuring(windows []BayCapacityWindow, start, end time.Time) int {
+	capacity := b.CapacitySlots
+	for _, interval := range b.Timeline(windows, nil, start, end) {
+		if interval.Capacity < capacity {
+			capacity = interval.Capacity
+		}
+	}
+	return capacity
+}
+
+// Timeline splits [from, to) into intervals of constant capacity and
+// occupancy. Only active tasks booked into the bay take a slot. Adjacent
+// intervals are merged when they leave the same slots open.
+func (b HangarBay) Timeline(windows []BayCapacityWindow, tasks []MaintenanceTask, from, to time.Time) []CapacityInterval {
+	if !to.After(from) {
+		return nil
+	}
+	booked := make([]MaintenanceTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.OccupiesBay(b.ID) {
+			booked = append(booked, task)
+		}
+	}
+	points := []time.Time{from, to}
+	addPoint := func(at time.Time) {
+		if at.After(from) && at.Before(to) {
+			points = append(points, at)
+		}
+	}
+	for _, window := range windows {
+		if window.BayID == b.ID {
+			addPoint(window.StartTime)
+			addPoint(window.EndTime)
+		}
+	}
+	for _, task := range booked {
+		addPoint(task.StartTime)
+		addPoint(task.EndTime)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	var intervals []CapacityInterval
+	for i := 0; i+1 < len(points); i++ {
+		start, end := points[i], points[i+1]
+		if !end.After(start) {
+			continue
+		}
+		capacity := b.CapacityAt(windows, start)
+		taken := make(map[int]bool)
+		for _, task := range booked {
+			if !start.Before(task.StartTime) && start.Before(task.EndTime) {
+				taken[*task.BaySlot] = true
+			}
+		}
+		free := make([]int, 0, capacity)
+		for slot := 1; slot <= capacity; slot++ {
+			if !taken[slot] {
+				free = append(free, slot)
+			}
+		}
+		if n := len(intervals); n > 0 && intervals[n-1].Capacity == capacity && intervals[n-1].Occupied == len(taken) && sameSlots(intervals[n-1].FreeSlots, free) {
+			intervals[n-1].End = end
+			continue
+		}
+		intervals = append(intervals, CapacityInterval{
+			Start:     start,
+			End:       end,
+			Capacity:  capacity,
+			Occupied:  len(taken),
+			FreeSlots: free,
+		})
+	}
+	return intervals
+}
+
+// Overbooked returns the tasks that hold the bay at a time when it cannot
+// take them: more tasks than slots, a slot beyond the capacity or a slot
+// another task already holds. Tasks are taken in start order, so the ones
+// that arrived last are reported.
+func (b HangarBay) Overbooked(windows []BayCapacityWindow, tasks []MaintenanceTask) []MaintenanceTask {
+	booked := make([]MaintenanceTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.OccupiesBay(b.ID) {
+			booked = append(booked, task)
+		}
+	}
+	sort.Slice(booked, func(i, j int) bool {
+		if !booked[i].StartTime.Equal(booked[j].StartTime) {
+			return booked[i].StartTime.Before(booked[j].StartTime)
+		}
+		return booked[i].ID.String() < booked[j].ID.String()
+	})
+	// Occupancy only grows when a task starts and capacity only drops at a
+	// window boundary, so those are the instants worth checking.
+	var points []time.Time
+	for _, task := range booked {
+		points = append(points, task.StartTime)
+	}
+	for _, window := range windows {
+		if window.BayID == b.ID {
+			points = append(points, window.StartTime, window.EndTime)
+		}
+	}
+
+	reported := make(map[uuid.UUID]bool)
+	var over []MaintenanceTask
+	for _, at := range points {
+		capacity := b.CapacityAt(windows, at)
+		taken := make(map[int]bool)
+		held := 0
+		for _, task := range booked {
+			if at.Before(task.StartTime) || !at.Before(task.EndTime) {
+				continue
+			}
+			held++
+			slot := *task.BaySlot
+			if held > capacity || slot > capacity || taken[slot] {
+				if !reported[task.ID] {
+					reported[task.ID] = true
+					over = append(over, task)
+				}
+			}
+			taken[slot] = true
+		}
+	}
+	return over
+}
+
+// FreeBaySlot picks a slot of the bay that none of the booked tasks hold,
+// keeping the preferred slot when it is still open. booked must already be
+// limited to tasks overlapping the requested window.
+func FreeBaySlot(capacity int, booked []MaintenanceTask, preferred *int) (int, bool) {
+	taken := make(map[int]bool, len(booked))
+	for _, task := range booked {
+		if task.BaySlot != nil {
+			taken[*task.BaySlot] = true
+		}
+	}
+	if preferred != nil && *preferred >= 1 && *preferred <= capacity && !taken[*preferred] {
+		return *preferred, true
+	}
+	for slot := 1; slot <= capacity; slot++ {
+		if !taken[slot] {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// OccupiesBay reports whether the task holds a slot of the bay, i.e. it is
+// booked into the bay and still scheduled or in progress.
+func (t MaintenanceTask) OccupiesBay(bayID uuid.UUID) bool {
+	if t.BayID == nil || *t.BayID != bayID || t.BaySlot == nil || t.DeletedAt != nil {
+		return false
+	}
+	return t.IsActive()
+}
+
+func sameSlots(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
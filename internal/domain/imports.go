@@ -9,9 +9,11 @@ import (
 type ImportType string
 
 const (
-	ImportTypeAircraft ImportType = "aircraft"
-	ImportTypeParts    ImportType = "parts"
-	ImportTypePrograms ImportType = "programs"
+	ImportTypeAircraft    ImportType = "aircraft"
+	ImportTypeParts       ImportType = "parts"
+	ImportTypePrograms    ImportType = "programs"
+	ImportTypeUtilization ImportType = "utilization"
+	ImportTypeFlights     ImportType = "flight_schedule"
 )
 
 type ImportStatus string
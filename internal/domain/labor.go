@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LaborSource string
+
+const (
+	// LaborSourceClock is an entry opened by clocking in; it stays open
+	// until the mechanic clocks out.
+	LaborSourceClock LaborSource = "clock"
+	// LaborSourceManual is an entry recorded afterwards with both times.
+	LaborSourceManual LaborSource = "manual"
+)
+
+// LaborEntry is time a mechanic actually worked on a maintenance task.
+// EndedAt is nil while the mechanic is clocked in.
+type LaborEntry struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	TaskID     uuid.UUID
+	MechanicID uuid.UUID
+	Source     LaborSource
+	StartedAt  time.Time
+	EndedAt    *time.Time
+	Notes      string
+	CreatedBy  uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TaskLaborSummary compares the hours planned for a task, the crew's planned
+// hours or else the length of its maintenance window, with the hours its
+// mechanics booked against it.
+// ActualHours counts closed entries only; OpenEntries are still running.
+type TaskLaborSummary struct {
+	TaskID        uuid.UUID
+	PlannedHours  float64
+	ActualHours   float64
+	VarianceHours float64
+	OpenEntries   int
+	Mechanics     []MechanicLaborHours
+}
+
+type MechanicLaborHours struct {
+	MechanicID uuid.UUID
+	Hours      float64
+}
+
+// LaborDay is the hours one mechanic worked on one UTC calendar day, the
+// granularity recency is logged at.
+type LaborDay struct {
+	MechanicID uuid.UUID
+	WorkDate   time.Time
+	Hours      float64
+}
+
+func (s LaborSource) IsValid() bool {
+	return s == LaborSourceClock || s == LaborSourceManual
+}
+
+func (e LaborEntry) Validate() error {
+	if e.TaskID == uuid.Nil {
+		return NewValidationError("task_id is required")
+	}
+	if e.MechanicID == uuid.Nil {
+		return NewValidationError("mechanic_id is required")
+	}
+	if !e.Source.IsValid() {
+		return NewValidationError("source must be clock or manual")
+	}
+	if e.StartedAt.IsZero() {
+		return NewValidationError("started_at is required")
+	}
+	if e.EndedAt == nil {
+		if e.Source == LaborSourceManual {
+			return NewValidationError("ended_at is required for manual entries")
+		}
+		return nil
+	}
+	if !e.EndedAt.After(e.StartedAt) {
+		return NewValidationError("ended_at must be after started_at")
+	}
+	return nil
+}
+
+// Open reports whether the mechanic is still clocked in.
+func (e LaborEntry) Open() bool {
+	return e.EndedAt == nil
+}
+
+// Hours is the length of a closed entry in hours; open entries count zero.
+func (e LaborEntry) Hours() float64 {
+	if e.EndedAt == nil {
+		return 0
+	}
+	return e.EndedAt.Sub(e.StartedAt).Hours()
+}
+
+// Overlaps reports whether the entry intersects [start, end). A nil end
+// leaves the range open, as does an open entry.
+func (e LaborEntry) Overlaps(start time.Time, end *time.Time) bool {
+	if end != nil && !e.StartedAt.Before(*end) {
+		return false
+	}
+	return e.EndedAt == nil || e.EndedAt.After(start)
+}
+
+// SummarizeLabor totals the entries booked against task.
+func SummarizeLabor(task MaintenanceTask, crew Crew, entries []LaborEntry) TaskLaborSummary {
+	summary := TaskLaborSummary{
+		TaskID:       task.ID,
+		PlannedHours: task.EndTime.Sub(task.StartTime).Hours(),
+	}
+	if planned := crew.PlannedHours(); planned > 0 {
+		summary.PlannedHours = planned
+	}
+	hours := map[uuid.UUID]float64{}
+	for _, entry := range entries {
+		if entry.Open() {
+			summary.OpenEntries++
+			if _, ok := hours[entry.MechanicID]; !ok {
+				hours[entry.MechanicID] = 0
+			}
+			continue
+		}
+		summary.ActualHours += entry.Hours()
+		hours[entry.MechanicID] += entry.Hours()
+	}
+	summary.VarianceHours = summary.ActualHours - summary.PlannedHours
+	for mechanicID, h := range hours {
+		summary.Mechanics = append(summary.Mechanics, MechanicLaborHours{MechanicID: mechanicID, Hours: h})
+	}
+	sort.Slice(summary.Mechanics, func(i, j int) bool {
+		if summary.Mechanics[i].Hours != summary.Mechanics[j].Hours {
+			return summary.Mechanics[i].Hours > summary.Mechanics[j].Hours
+		}
+		return summary.Mechanics[i].MechanicID.String() < summary.Mechanics[j].MechanicID.String()
+	})
+	return summary
+}
+
+// LaborDays splits closed entries at UTC midnight and totals them per
+// mechanic and day, ordered by mechanic then date. Open entries are left
+// out.
+func LaborDays(entries []LaborEntry) []LaborDay {
+	type key struct {
+		mechanicID uuid.UUID
+		date       time.Time
+	}
+	hours := map[key]float64{}
+	for _, entry := range entries {
+		if entry.Open() {
+			continue
+		}
+		start := entry.StartedAt.UTC()
+		end := entry.EndedAt.UTC()
+		for start.Before(end) {
+			day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			next := day.AddDate(0, 0, 1)
+			if next.After(end) {
+				next = end
+			}
+			hours[key{entry.MechanicID, day}] += next.Sub(start).Hours()
+			start = next
+		}
+	}
+	days := make([]LaborDay, 0, len(hours))
+	for k, h := range hours {
+		days = append(days, LaborDay{MechanicID: k.mechanicID, WorkDate: k.date, Hours: h})
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].MechanicID != days[j].MechanicID {
+			return days[i].MechanicID.String() < days[j].MechanicID.String()
+		}
+		return days[i].WorkDate.Before(days[j].WorkDate)
+	})
+	return days
+}
@@ -30,21 +30,89 @@ type PartDefinition struct {
 	MinStockLevel  int
 	ReorderPoint   int
 	LeadTimeDays   *int
+	// AlternateIDs lists the definitions whose items may be fitted instead
+	// of this one's.
+	AlternateIDs   []uuid.UUID
 	DeletedAt      *time.Time
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
 
 type PartItem struct {
-	ID           uuid.UUID
-	OrgID        uuid.UUID
-	DefinitionID uuid.UUID
-	SerialNumber string
-	Status       PartItemStatus
-	ExpiryDate   *time.Time
-	DeletedAt    *time.Time
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID                  uuid.UUID
+	OrgID               uuid.UUID
+	DefinitionID        uuid.UUID
+	SerialNumber        string
+	Status              PartItemStatus
+	ExpiryDate          *time.Time
+	AircraftID          *uuid.UUID
+	Position            string
+	LifeLimitHours      *float64
+	LifeLimitCycles     *int
+	HoursSinceNew       float64
+	CyclesSinceNew      int
+	HoursSinceOverhaul  float64
+	CyclesSinceOverhaul int
+	DeletedAt           *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// IsLifeLimited reports whether the part has a life limit on either measure.
+func (p PartItem) IsLifeLimited() bool {
+	return p.LifeLimitHours != nil || p.LifeLimitCycles != nil
+}
+
+// RemainingHours returns the flight hours left before the life limit, which
+// is negative once the limit has been exceeded.
+func (p PartItem) RemainingHours() *float64 {
+	if p.LifeLimitHours == nil {
+		return nil
+	}
+	remaining := *p.LifeLimitHours - p.HoursSinceNew
+	return &remaining
+}
+
+// RemainingCycles returns the cycles left before the life limit.
+func (p PartItem) RemainingCycles() *int {
+	if p.LifeLimitCycles == nil {
+		return nil
+	}
+	remaining := *p.LifeLimitCycles - p.CyclesSinceNew
+	return &remaining
+}
+
+// RemainingLifePercent returns the share of life left on whichever measure
+// is closest to its limit. It reports false for parts without a life limit.
+func (p PartItem) RemainingLifePercent() (float64, bool) {
+	percent, ok := 0.0, false
+	if p.LifeLimitHours != nil && *p.LifeLimitHours > 0 {
+		percent, ok = (*p.LifeLimitHours-p.HoursSinceNew) / *p.LifeLimitHours * 100, true
+	}
+	if p.LifeLimitCycles != nil && *p.LifeLimitCycles > 0 {
+		cycles := float64(*p.LifeLimitCycles-p.CyclesSinceNew) / float64(*p.LifeLimitCycles) * 100
+		if !ok || cycles < percent {
+			percent, ok = cycles, true
+		}
+	}
+	return percent, ok
+}
+
+// ValidateLife checks the life limits and usage counters.
+func (p PartItem) ValidateLife() error {
+	if p.LifeLimitHours != nil && *p.LifeLimitHours <= 0 {
+		return NewValidationError("life_limit_hours must be positive")
+	}
+	if p.LifeLimitCycles != nil && *p.LifeLimitCycles <= 0 {
+		return NewValidationError("life_limit_cycles must be positive")
+	}
+	if p.HoursSinceNew < 0 || p.CyclesSinceNew < 0 || p.HoursSinceOverhaul < 0 || p.CyclesSinceOverhaul < 0 {
+		return NewValidationError("usage since new and since overhaul must not be negative")
+	}
+	if p.HoursSinceOverhaul > p.HoursSinceNew || p.CyclesSinceOverhaul > p.CyclesSinceNew {
+		return NewValidationError("usage since overhaul must not exceed usage since new")
+	}
+	return nil
 }
 
 type PartReservation struct {
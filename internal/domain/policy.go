@@ -13,6 +13,7 @@ type OrgPolicy struct {
 	WebhookReplayWindowSeconds int
 	APIRateLimitPerMin         int
 	APIKeyRateLimitPerMin      int
+	MaxAttachmentBytes         int64
 	CreatedAt                  time.Time
 	UpdatedAt                  time.Time
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,15 +15,379 @@ const (
 	ProgramIntervalCalendar    MaintenanceProgramIntervalType = "calendar"
 )
 
+// ProgramThreshold is one interval of a program. TolerancePercent widens the
+// due point into a window of ±TolerancePercent of IntervalValue.
+type ProgramThreshold struct {
+	IntervalType     MaintenanceProgramIntervalType
+	IntervalValue    int
+	TolerancePercent int
+}
+
+const MaxProgramTolerancePercent = 50
+
 type MaintenanceProgram struct {
-	ID            uuid.UUID
-	OrgID         uuid.UUID
-	AircraftID    *uuid.UUID
-	Name          string
+	ID                  uuid.UUID
+	OrgID               uuid.UUID
+	AircraftID          *uuid.UUID
+	Name                string
+	IntervalType        MaintenanceProgramIntervalType
+	IntervalValue       int
+	LastPerformed       *time.Time
+	LastPerformedHours  *int
+	LastPerformedCycles *int
+	// TolerancePercent is the allowed deviation either side of the primary
+	// interval, as a percentage of IntervalValue.
+	TolerancePercent int
+	// Thresholds are additional intervals evaluated on a "whichever comes
+	// first" basis alongside IntervalType/IntervalValue.
+	Thresholds []ProgramThreshold
+	// TemplateID and TemplateRevision are set on programs instantiated from
+	// a MaintenanceProgramTemplate, recording the revision last applied.
+	TemplateID       *uuid.UUID
+	TemplateRevision *int
+	// TaskType, Priority, EstimatedDurationMinutes, ComplianceChecklist and
+	// DefaultNotes shape the tasks generated from the program.
+	TaskType                 TaskType
+	Priority                 TaskPriority
+	EstimatedDurationMinutes *int
+	ComplianceChecklist      []string
+	DefaultNotes             string
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
+	DeletedAt                *time.Time
+}
+
+// DefaultProgramTaskDuration is used for generated tasks when the program has
+// no estimated duration and no tolerance window to span.
+const DefaultProgramTaskDuration = 2 * time.Hour
+
+const defaultProgramTaskNotes = "Generated from maintenance program"
+
+// ProgramLookAhead widens the due check so tasks are generated before a
+// program actually reaches its due point.
+type ProgramLookAhead struct {
+	Days        int
+	FlightHours int
+	Cycles      int
+}
+
+func (t MaintenanceProgramIntervalType) IsValid() bool {
+	return t == ProgramIntervalFlightHours || t == ProgramIntervalCycles || t == ProgramIntervalCalendar
+}
+
+// ValidateTaskDefaults checks the task type, priority, estimated duration and
+// checklist applied to generated tasks. An empty task type or priority falls
+// back to inspection and routine.
+func (p MaintenanceProgram) ValidateTaskDefaults() error {
+	if p.TaskType != "" && !p.TaskType.IsValid() {
+		return NewValidationError("task_type must be inspection, repair or overhaul")
+	}
+	if p.Priority != "" && !p.Priority.IsValid() {
+		return NewValidationError("priority must be routine, urgent, aog or critical")
+	}
+	if p.EstimatedDurationMinutes != nil && *p.EstimatedDurationMinutes <= 0 {
+		return NewValidationError("estimated_duration_minutes must be greater than 0")
+	}
+	for _, item := range p.ComplianceChecklist {
+		if item == "" {
+			return NewValidationError("compliance_checklist items must not be empty")
+		}
+	}
+	return nil
+}
+
+// GeneratedTaskType returns the type of tasks generated from the program.
+func (p MaintenanceProgram) GeneratedTaskType() TaskType {
+	if p.TaskType == "" {
+		return TaskTypeInspection
+	}
+	return p.TaskType
+}
+
+// GeneratedTaskPriority returns the priority of tasks generated from the
+// program.
+func (p MaintenanceProgram) GeneratedTaskPriority() TaskPriority {
+	if p.Priority == "" {
+		return PriorityRoutine
+	}
+	return p.Priority
+}
+
+// TaskDuration returns the estimated duration of a generated task, or
+// DefaultProgramTaskDuration when the program does not set one.
+func (p MaintenanceProgram) TaskDuration() time.Duration {
+	if p.EstimatedDurationMinutes == nil {
+		return DefaultProgramTaskDuration
+	}
+	return time.Duration(*p.EstimatedDurationMinutes) * time.Minute
+}
+
+// TaskNotes returns the notes copied onto generated tasks.
+func (p MaintenanceProgram) TaskNotes() string {
+	if p.DefaultNotes == "" {
+		return defaultProgramTaskNotes
+	}
+	return p.DefaultNotes
+}
+
+func (t ProgramThreshold) IsUsageBased() bool {
+	return t.IntervalType == ProgramIntervalFlightHours || t.IntervalType == ProgramIntervalCycles
+}
+
+// AllThresholds returns the primary interval followed by any additional
+// thresholds.
+func (p MaintenanceProgram) AllThresholds() []ProgramThreshold {
+	out := make([]ProgramThreshold, 0, len(p.Thresholds)+1)
+	out = append(out, ProgramThreshold{
+		IntervalType:     p.IntervalType,
+		IntervalValue:    p.IntervalValue,
+		TolerancePercent: p.TolerancePercent,
+	})
+	return append(out, p.Thresholds...)
+}
+
+// ValidateThresholds checks every threshold has a known type, a positive
+// value and a tolerance within bounds, and that no interval type is repeated.
+func (p MaintenanceProgram) ValidateThresholds() error {
+	seen := make(map[MaintenanceProgramIntervalType]bool)
+	for _, threshold := range p.AllThresholds() {
+		if !threshold.IntervalType.IsValid() {
+			return NewValidationError("interval_type must be flight_hours, cycles or calendar")
+		}
+		if threshold.IntervalValue <= 0 {
+			return NewValidationError("interval_value must be greater than 0")
+		}
+		if threshold.TolerancePercent < 0 || threshold.TolerancePercent > MaxProgramTolerancePercent {
+			return NewValidationError("tolerance_percent must be between 0 and 50")
+		}
+		if seen[threshold.IntervalType] {
+			return NewValidationError("each interval_type may only be used once per program")
+		}
+		seen[threshold.IntervalType] = true
+	}
+	return nil
+}
+
+// IsUsageBased reports whether any threshold of the program is driven by
+// aircraft flight hours or cycles rather than the calendar.
+func (p MaintenanceProgram) IsUsageBased() bool {
+	for _, threshold := range p.AllThresholds() {
+		if threshold.IsUsageBased() {
+			return true
+		}
+	}
+	return false
+}
+
+// DueUsage returns the aircraft flight hours or cycles total at which a usage
+// threshold next falls due. Programs never performed count from zero.
+func (p MaintenanceProgram) DueUsage(threshold ProgramThreshold) int {
+	baseline := 0
+	switch threshold.IntervalType {
+	case ProgramIntervalFlightHours:
+		if p.LastPerformedHours != nil {
+			baseline = *p.LastPerformedHours
+		}
+	case ProgramIntervalCycles:
+		if p.LastPerformedCycles != nil {
+			baseline = *p.LastPerformedCycles
+		}
+	}
+	return baseline + threshold.IntervalValue
+}
+
+// RemainingUsage returns the flight hours or cycles left before a usage
+// threshold falls due on the aircraft. Negative values mean it is overdue.
+func (p MaintenanceProgram) RemainingUsage(threshold ProgramThreshold, aircraft Aircraft) int {
+	switch threshold.IntervalType {
+	case ProgramIntervalFlightHours:
+		return p.DueUsage(threshold) - aircraft.FlightHoursTotal
+	case ProgramIntervalCycles:
+		return p.DueUsage(threshold) - aircraft.CyclesTotal
+	default:
+		return 0
+	}
+}
+
+// IsDue reports whether any threshold of the program has reached its due
+// point on the aircraft or will reach it within the look-ahead window.
+func (p MaintenanceProgram) IsDue(aircraft Aircraft, now time.Time, lookAhead ProgramLookAhead) bool {
+	for _, threshold := range p.AllThresholds() {
+		switch threshold.IntervalType {
+		case ProgramIntervalCalendar:
+			if p.LastPerformed == nil || !p.LastPerformed.AddDate(0, 0, threshold.IntervalValue-lookAhead.Days).After(now) {
+				return true
+			}
+		case ProgramIntervalFlightHours:
+			if p.RemainingUsage(threshold, aircraft) <= lookAhead.FlightHours {
+				return true
+			}
+		case ProgramIntervalCycles:
+			if p.RemainingUsage(threshold, aircraft) <= lookAhead.Cycles {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProgramDue is the next due point of a program, driven by whichever
+// threshold comes first. The window spans the driving threshold's tolerance.
+type ProgramDue struct {
+	Threshold   ProgramThreshold
+	DueAt       time.Time
+	DueUsage    *int
+	Overdue     bool
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
+
+// programState is the point a program was last accomplished, from which the
+// next due point is measured.
+type programState struct {
+	performedAt *time.Time
+	hours       float64
+	cycles      float64
+}
+
+func (p MaintenanceProgram) initialState() programState {
+	state := programState{performedAt: p.LastPerformed}
+	if p.LastPerformedHours != nil {
+		state.hours = float64(*p.LastPerformedHours)
+	}
+	if p.LastPerformedCycles != nil {
+		state.cycles = float64(*p.LastPerformedCycles)
+	}
+	return state
+}
+
+// NextDue returns the earliest due point across all thresholds. Usage
+// thresholds are converted to dates with the aircraft's average daily
+// utilization; ok is false when no threshold can be projected, which happens
+// when only usage thresholds remain and the rate is zero.
+func (p MaintenanceProgram) NextDue(aircraft Aircraft, rate UtilizationRate, now time.Time) (ProgramDue, bool) {
+	return p.nextDueFrom(p.initialState(), aircraft, rate, now)
+}
+
+func (p MaintenanceProgram) nextDueFrom(state programState, aircraft Aircraft, rate UtilizationRate, now time.Time) (ProgramDue, bool) {
+	var best ProgramDue
+	found := false
+	for _, threshold := range p.AllThresholds() {
+		due := ProgramDue{Threshold: threshold}
+		var tolerance time.Duration
+		toleranceUnits := float64(threshold.IntervalValue*threshold.TolerancePercent) / 100
+		switch threshold.IntervalType {
+		case ProgramIntervalCalendar:
+			due.DueAt = now
+			if state.performedAt != nil {
+				due.DueAt = state.performedAt.AddDate(0, 0, threshold.IntervalValue)
+			}
+			due.Overdue = due.DueAt.Before(now)
+			tolerance = days(toleranceUnits)
+		case ProgramIntervalFlightHours, ProgramIntervalCycles:
+			baseline, current, perDay := state.hours, float64(aircraft.FlightHoursTotal), rate.HoursPerDay
+			if threshold.IntervalType == ProgramIntervalCycles {
+				baseline, current, perDay = state.cycles, float64(aircraft.CyclesTotal), rate.CyclesPerDay
+			}
+			dueUsage := baseline + float64(threshold.IntervalValue)
+			remaining := dueUsage - current
+			switch {
+			case remaining <= 0:
+				due.DueAt = now
+				due.Overdue = remaining < 0
+			case perDay > 0:
+				due.DueAt = now.Add(days(remaining / perDay))
+			default:
+				continue
+			}
+			value := int(math.Round(dueUsage))
+			due.DueUsage = &value
+			if perDay > 0 {
+				tolerance = days(toleranceUnits / perDay)
+			}
+		default:
+			continue
+		}
+		due.WindowStart = due.DueAt.Add(-tolerance)
+		due.WindowEnd = due.DueAt.Add(tolerance)
+		if !found || due.DueAt.Before(best.DueAt) {
+			best = due
+			found = true
+		}
+	}
+	return best, found
+}
+
+// ProgramForecast is a projected future occurrence of a program on an
+// aircraft. IntervalType and IntervalValue describe the threshold driving the
+// occurrence; DueHours and DueCycles are set when it is usage based.
+type ProgramForecast struct {
+	ProgramID     uuid.UUID
+	ProgramName   string
+	AircraftID    uuid.UUID
+	TailNumber    string
 	IntervalType  MaintenanceProgramIntervalType
 	IntervalValue int
-	LastPerformed *time.Time
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     *time.Time
+	Occurrence    int
+	DueAt         time.Time
+	WindowStart   time.Time
+	WindowEnd     time.Time
+	DueHours      *int
+	DueCycles     *int
+	Overdue       bool
+	// GroundMinutes is the aircraft's planned time on the ground within
+	// the window and GroundWindow the first stretch of it long enough for
+	// the task. Both are nil when no flight schedule is consulted.
+	GroundMinutes *int
+	GroundWindow  *GroundWindow
+}
+
+// Forecast projects up to limit occurrences of the program falling due on or
+// before until. Each occurrence is assumed to be accomplished when due, or at
+// now if it is already overdue, resetting every threshold.
+func (p MaintenanceProgram) Forecast(aircraft Aircraft, rate UtilizationRate, now, until time.Time, limit int) []ProgramForecast {
+	var out []ProgramForecast
+	state := p.initialState()
+	for len(out) < limit {
+		due, ok := p.nextDueFrom(state, aircraft, rate, now)
+		if !ok || due.DueAt.After(until) {
+			break
+		}
+		item := ProgramForecast{
+			ProgramID:     p.ID,
+			ProgramName:   p.Name,
+			AircraftID:    aircraft.ID,
+			TailNumber:    aircraft.TailNumber,
+			IntervalType:  due.Threshold.IntervalType,
+			IntervalValue: due.Threshold.IntervalValue,
+			Occurrence:    len(out) + 1,
+			DueAt:         due.DueAt,
+			WindowStart:   due.WindowStart,
+			WindowEnd:     due.WindowEnd,
+			Overdue:       due.Overdue,
+		}
+		switch due.Threshold.IntervalType {
+		case ProgramIntervalFlightHours:
+			item.DueHours = due.DueUsage
+		case ProgramIntervalCycles:
+			item.DueCycles = due.DueUsage
+		}
+		out = append(out, item)
+
+		performedAt := due.DueAt
+		if performedAt.Before(now) {
+			performedAt = now
+		}
+		elapsed := performedAt.Sub(now).Hours() / 24
+		state = programState{
+			performedAt: &performedAt,
+			hours:       float64(aircraft.FlightHoursTotal) + rate.HoursPerDay*elapsed,
+			cycles:      float64(aircraft.CyclesTotal) + rate.CyclesPerDay*elapsed,
+		}
+	}
+	return out
+}
+
+func days(value float64) time.Duration {
+	return time.Duration(value * float64(24*time.Hour))
 }
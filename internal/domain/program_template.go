@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceProgramTemplate defines a program once for an aircraft type. It
+// is instantiated as a MaintenanceProgram on every aircraft of the type, and
+// Revision is bumped whenever the definition changes.
+type MaintenanceProgramTemplate struct {
+	ID               uuid.UUID
+	OrgID            uuid.UUID
+	AircraftTypeID   uuid.UUID
+	Name             string
+	IntervalType     MaintenanceProgramIntervalType
+	IntervalValue    int
+	TolerancePercent int
+	Thresholds       []ProgramThreshold
+	Revision         int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        *time.Time
+}
+
+type ProgramTemplateChangeAction string
+
+const (
+	ProgramTemplateChangeCreate ProgramTemplateChangeAction = "create"
+	ProgramTemplateChangeUpdate ProgramTemplateChangeAction = "update"
+)
+
+// ProgramFieldChange is a single field that differs between a template and
+// one of its instances.
+type ProgramFieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+// ProgramTemplateChange describes what propagating a template revision does
+// to one aircraft: create a missing instance or update an outdated one.
+type ProgramTemplateChange struct {
+	AircraftID   uuid.UUID
+	TailNumber   string
+	ProgramID    *uuid.UUID
+	Action       ProgramTemplateChangeAction
+	FromRevision *int
+	ToRevision   int
+	Changes      []ProgramFieldChange
+}
+
+// Definition returns the template's interval definition as a program, so the
+// program threshold validation can be reused.
+func (t MaintenanceProgramTemplate) Definition() MaintenanceProgram {
+	return MaintenanceProgram{
+		OrgID:            t.OrgID,
+		Name:             t.Name,
+		IntervalType:     t.IntervalType,
+		IntervalValue:    t.IntervalValue,
+		TolerancePercent: t.TolerancePercent,
+		Thresholds:       t.Thresholds,
+	}
+}
+
+// Instantiate returns a new program for the aircraft carrying the template's
+// current definition.
+func (t MaintenanceProgramTemplate) Instantiate(aircraftID uuid.UUID, now time.Time) MaintenanceProgram {
+	program := MaintenanceProgram{
+		ID:         uuid.New(),
+		OrgID:      t.OrgID,
+		AircraftID: &aircraftID,
+		TemplateID: &t.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return t.ApplyTo(program)
+}
+
+// ApplyTo copies the template definition onto an instance. Per-aircraft state
+// such as the last performed date and usage is left untouched.
+func (t MaintenanceProgramTemplate) ApplyTo(program MaintenanceProgram) MaintenanceProgram {
+	revision := t.Revision
+	program.Name = t.Name
+	program.IntervalType = t.IntervalType
+	program.IntervalValue = t.IntervalValue
+	program.TolerancePercent = t.TolerancePercent
+	program.Thresholds = append([]ProgramThreshold(nil), t.Thresholds...)
+	program.TemplateRevision = &revision
+	return program
+}
+
+// Diff lists the definition fields of an instance that differ from the
+// template.
+func (t MaintenanceProgramTemplate) Diff(program MaintenanceProgram) []ProgramFieldChange {
+	var changes []ProgramFieldChange
+	if program.Name != t.Name {
+		changes = append(changes, ProgramFieldChange{Field: "name", From: program.Name, To: t.Name})
+	}
+	if program.IntervalType != t.IntervalType {
+		changes = append(changes, ProgramFieldChange{Field: "interval_type", From: program.IntervalType, To: t.IntervalType})
+	}
+	if program.IntervalValue != t.IntervalValue {
+		changes = append(changes, ProgramFieldChange{Field: "interval_value", From: program.IntervalValue, To: t.IntervalValue})
+	}
+	if program.TolerancePercent != t.TolerancePercent {
+		changes = append(changes, ProgramFieldChange{Field: "tolerance_percent", From: program.TolerancePercent, To: t.TolerancePercent})
+	}
+	if !equalThresholds(program.Thresholds, t.Thresholds) {
+		changes = append(changes, ProgramFieldChange{Field: "thresholds", From: program.Thresholds, To: t.Thresholds})
+	}
+	return changes
+}
+
+func equalThresholds(a, b []ProgramThreshold) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WeekdaySet is a set of weekdays, bit n standing for time.Weekday(n).
+type WeekdaySet uint8
+
+// AllWeekdays contains every day of the week.
+const AllWeekdays WeekdaySet = 1<<7 - 1
+
+func NewWeekdaySet(days ...time.Weekday) WeekdaySet {
+	var set WeekdaySet
+	for _, day := range days {
+		set |= 1 << uint(day)
+	}
+	return set
+}
+
+func (s WeekdaySet) Has(day time.Weekday) bool {
+	return s&(1<<uint(day)) != 0
+}
+
+// Days lists the days of the set from Sunday on.
+func (s WeekdaySet) Days() []time.Weekday {
+	var days []time.Weekday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if s.Has(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// ShiftPattern is a recurring weekly shift: on each of Weekdays a shift
+// starts StartMinute minutes after local midnight in Timezone and lasts
+// DurationMinutes, possibly into the next day.
+type ShiftPattern struct {
+	ID              uuid.UUID
+	OrgID           uuid.UUID
+	Name            string
+	Timezone        string
+	StartMinute     int
+	DurationMinutes int
+	Weekdays        WeekdaySet
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+}
+
+// RosterAssignment puts a mechanic on a shift pattern, optionally at a
+// station, from EffectiveFrom until EffectiveTo (open-ended when nil).
+type RosterAssignment struct {
+	ID             uuid.UUID
+	OrgID          uuid.UUID
+	MechanicID     uuid.UUID
+	ShiftPatternID uuid.UUID
+	StationID      *uuid.UUID
+	EffectiveFrom  time.Time
+	EffectiveTo    *time.Time
+	CreatedAt      time.Time
+}
+
+type AbsenceKind string
+
+const (
+	AbsenceLeave    AbsenceKind = "leave"
+	AbsenceSick     AbsenceKind = "sick"
+	AbsenceTraining AbsenceKind = "training"
+	AbsenceOther    AbsenceKind = "other"
+)
+
+// MechanicAbsence takes a mechanic off work between StartTime and EndTime,
+// whatever their roster says.
+type MechanicAbsence struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	MechanicID uuid.UUID
+	Kind       AbsenceKind
+	StartTime  time.Time
+	EndTime    time.Time
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// TimeWindow is the half-open interval [Start, End).
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (p ShiftPattern) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return NewValidationError("name is required")
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "" {
+		return NewValidationError("timezone must be an IANA time zone")
+	}
+	if p.StartMinute < 0 || p.StartMinute >= 24*60 {
+		return NewValidationError("start time must be within the day")
+	}
+	if p.DurationMinutes <= 0 || p.DurationMinutes > 24*60 {
+		return NewValidationError("duration must be between one minute and 24 hours")
+	}
+	if p.Weekdays == 0 || p.Weekdays&^AllWeekdays != 0 {
+		return NewValidationError("weekdays must name at least one day")
+	}
+	return nil
+}
+
+func (a RosterAssignment) Validate() error {
+	if a.MechanicID == uuid.Nil {
+		return NewValidationError("mechanic_id is required")
+	}
+	if a.ShiftPatternID == uuid.Nil {
+		return NewValidationError("shift_pattern_id is required")
+	}
+	if a.EffectiveFrom.IsZero() {
+		return NewValidationError("effective_from is required")
+	}
+	if a.EffectiveTo != nil && !a.EffectiveTo.After(a.EffectiveFrom) {
+		return NewValidationError("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+func (k AbsenceKind) IsValid() bool {
+	switch k {
+	case AbsenceLeave, AbsenceSick, AbsenceTraining, AbsenceOther:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a MechanicAbsence) Validate() error {
+	if a.MechanicID == uuid.Nil {
+		return NewValidationError("mechanic_id is required")
+	}
+	if !a.Kind.IsValid() {
+		return NewValidationError("kind must be leave, sick, training or other")
+	}
+	if !a.EndTime.After(a.StartTime) {
+		return NewValidationError("end_time must be after start_time")
+	}
+	return nil
+}
+
+// Overlaps reports whether the assignment is in effect at any time in
+// [from, to).
+func (a RosterAssignment) Overlaps(from, to time.Time) bool {
+	if !a.EffectiveFrom.Before(to) {
+		return false
+	}
+	return a.EffectiveTo == nil || a.EffectiveTo.After(from)
+}
+
+// Shifts returns the pattern's shifts that intersect [from, to), including
+// one that started the day before and runs past midnight.
+func (p ShiftPattern) Shifts(from, to time.Time) []TimeWindow {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil || !to.After(from) {
+		return nil
+	}
+	duration := time.Duration(p.DurationMinutes) * time.Minute
+	local := from.In(loc).AddDate(0, 0, -1)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	var shifts []TimeWindow
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !p.Weekdays.Has(day.Weekday()) {
+			continue
+		}
+		start := day.Add(time.Duration(p.StartMinute) * time.Minute)
+		end := start.Add(duration)
+		if start.Before(to) && end.After(from) {
+			shifts = append(shifts, TimeWindow{Start: start.UTC(), End: end.UTC()})
+		}
+	}
+	return shifts
+}
+
+// RosterWindows returns the merged working time between from and to given
+// by the assignments, each limited to its effective period.
+func RosterWindows(assignments []RosterAssignment, patterns map[uuid.UUID]ShiftPattern, from, to time.Time) []TimeWindow {
+	var windows []TimeWindow
+	for _, assignment := range assignments {
+		pattern, ok := patterns[assignment.ShiftPatternID]
+		if !ok || !assignment.Overlaps(from, to) {
+			continue
+		}
+		limit := TimeWindow{Start: assignment.EffectiveFrom, End: to}
+		if assignment.EffectiveTo != nil && assignment.EffectiveTo.Before(to) {
+			limit.End = *assignment.EffectiveTo
+		}
+		for _, shift := range pattern.Shifts(from, to) {
+			if shift.Start.Before(limit.Start) {
+				shift.Start = limit.Start
+			}
+			if shift.End.After(limit.End) {
+				shift.End = limit.End
+			}
+			if shift.End.After(shift.Start) {
+				windows = append(windows, shift)
+			}
+		}
+	}
+	return MergeWindows(windows)
+}
+
+// MergeWindows sorts the windows and joins those that overlap or touch.
+func MergeWindows(windows []TimeWindow) []TimeWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	sorted := append([]TimeWindow(nil), windows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	merged := []TimeWindow{sorted[0]}
+	for _, window := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if window.Start.After(last.End) {
+			merged = append(merged, window)
+			continue
+		}
+		if window.End.After(last.End) {
+			last.End = window.End
+		}
+	}
+	return merged
+}
+
+// SubtractWindows removes the busy windows from the merged windows.
+func SubtractWindows(windows, busy []TimeWindow) []TimeWindow {
+	busy = MergeWindows(busy)
+	var out []TimeWindow
+	for _, window := range windows {
+		start := window.Start
+		for _, b := range busy {
+			if !b.End.After(start) || !b.Start.Before(window.End) {
+				continue
+			}
+			if b.Start.After(start) {
+				out = append(out, TimeWindow{Start: start, End: b.Start})
+			}
+			start = b.End
+		}
+		if window.End.After(start) {
+			out = append(out, TimeWindow{Start: start, End: window.End})
+		}
+	}
+	return out
+}
+
+// WindowsCover reports whether one of the merged windows contains all of
+// [start, end).
+func WindowsCover(windows []TimeWindow, start, end time.Time) bool {
+	for _, window := range windows {
+		if !window.Start.After(start) && !window.End.Before(end) {
+			return true
+		}
+	}
+	return false
+}
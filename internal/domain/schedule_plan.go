@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SchedulePlanState string
+
+const (
+	SchedulePlanProposed  SchedulePlanState = "proposed"
+	SchedulePlanApplied   SchedulePlanState = "applied"
+	SchedulePlanDiscarded SchedulePlanState = "discarded"
+)
+
+// SchedulePlan is a set of proposed start times, bays and mechanics for
+// tasks, produced by the optimizer and applied as one change once reviewed.
+type SchedulePlan struct {
+	ID           uuid.UUID
+	OrgID        uuid.UUID
+	State        SchedulePlanState
+	HorizonStart time.Time
+	HorizonEnd   time.Time
+	StationID    *uuid.UUID
+	CreatedBy    uuid.UUID
+	Items        []SchedulePlanItem
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	AppliedAt    *time.Time
+}
+
+// SchedulePlanItem is the proposal for one task. The Old fields and
+// TaskUpdatedAt snapshot the task when the plan was made, so a task edited
+// since can be detected at apply time. StartTime is nil when the optimizer
+// could not place the task; Reason then says why.
+type SchedulePlanItem struct {
+	TaskID        uuid.UUID
+	AircraftID    uuid.UUID
+	Priority      TaskPriority
+	TaskUpdatedAt time.Time
+	OldStartTime  time.Time
+	OldEndTime    time.Time
+	OldBayID      *uuid.UUID
+	OldMechanicID *uuid.UUID
+	StartTime     *time.Time
+	EndTime       *time.Time
+	BayID         *uuid.UUID
+	BaySlot       *int
+	MechanicID    *uuid.UUID
+	Reason        string
+}
+
+// Placed reports whether the optimizer found a slot for the task.
+func (i SchedulePlanItem) Placed() bool {
+	return i.StartTime != nil && i.EndTime != nil
+}
+
+// Moved reports whether applying the item changes the task's window.
+func (i SchedulePlanItem) Moved() bool {
+	return i.Placed() && (!i.StartTime.Equal(i.OldStartTime) || !i.EndTime.Equal(i.OldEndTime))
+}
+
+// Reassigned reports whether applying the item changes the mechanic.
+func (i SchedulePlanItem) Reassigned() bool {
+	if !i.Placed() {
+		return false
+	}
+	if i.MechanicID == nil || i.OldMechanicID == nil {
+		return i.MechanicID != i.OldMechanicID
+	}
+	return *i.MechanicID != *i.OldMechanicID
+}
+
+// CanApply reports whether the plan may still be applied.
+func (p SchedulePlan) CanApply() error {
+	if p.State != SchedulePlanProposed {
+		return NewConflictError("schedule plan is " + string(p.State))
+	}
+	for _, item := range p.Items {
+		if item.Placed() {
+			return nil
+		}
+	}
+	return NewValidationError("schedule plan places no tasks")
+}
@@ -16,6 +16,33 @@ const (
 	PriorityCritical TaskPriority = "critical"
 )
 
+// IsValid reports whether the priority is a known level
+func (p TaskPriority) IsValid() bool {
+	return p == PriorityRoutine || p == PriorityUrgent || p == PriorityAOG || p == PriorityCritical
+}
+
+// Rank orders priorities for planning, higher first. An AOG task grounds
+// the aircraft, so it outranks critical work.
+func (p TaskPriority) Rank() int {
+	switch p {
+	case PriorityAOG:
+		return 3
+	case PriorityCritical:
+		return 2
+	case PriorityUrgent:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Preempts reports whether work of priority p may take the mechanics and
+// bay slots of scheduled work of priority other. Only AOG and critical work
+// preempts, and only work ranked below it.
+func (p TaskPriority) Preempts(other TaskPriority) bool {
+	return p.Rank() >= PriorityCritical.Rank() && p.Rank() > other.Rank()
+}
+
 // DependencyType represents how two tasks are related
 type DependencyType string
 
@@ -35,6 +62,45 @@ type TaskDependency struct {
 	CreatedAt       time.Time
 }
 
+// Shift returns how far task must move later to satisfy its dependency on
+// prereq, or zero when the dependency already holds.
+func (d TaskDependency) Shift(prereq, task MaintenanceTask) time.Duration {
+	var shift time.Duration
+	switch d.DependencyType {
+	case DependencyStartToStart:
+		shift = prereq.StartTime.Sub(task.StartTime)
+	case DependencyFinishToFinish:
+		shift = prereq.EndTime.Sub(task.EndTime)
+	default:
+		shift = prereq.EndTime.Sub(task.StartTime)
+	}
+	if shift < 0 {
+		return 0
+	}
+	return shift
+}
+
+// ConflictSeverity ranks how urgently a schedule conflict needs attention
+type ConflictSeverity string
+
+const (
+	ConflictSeverityCritical ConflictSeverity = "critical"
+	ConflictSeverityHigh     ConflictSeverity = "high"
+	ConflictSeverityMedium   ConflictSeverity = "medium"
+)
+
+// Rank orders severities, most urgent first.
+func (s ConflictSeverity) Rank() int {
+	switch s {
+	case ConflictSeverityCritical:
+		return 2
+	case ConflictSeverityHigh:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // ScheduleChangeType represents the type of schedule change
 type ScheduleChangeType string
 
@@ -43,6 +109,7 @@ const (
 	ScheduleChangeCancelled         ScheduleChangeType = "cancelled"
 	ScheduleChangePriorityChanged   ScheduleChangeType = "priority_changed"
 	ScheduleChangeMechanicReassigned ScheduleChangeType = "mechanic_reassigned"
+	ScheduleChangePartSubstituted   ScheduleChangeType = "part_substituted"
 )
 
 // ScheduleChangeEvent records a change to a task's schedule
@@ -61,12 +128,62 @@ type ScheduleChangeEvent struct {
 	CreatedAt       time.Time
 }
 
-// RescheduleOption represents a possible rescheduling action
+// RescheduleOptionType is a way around a part a task cannot get in time
+type RescheduleOptionType string
+
+const (
+	// RescheduleOptionDelay moves the task to when the part can be on hand.
+	RescheduleOptionDelay      RescheduleOptionType = "delay"
+	// RescheduleOptionSubstitute reserves another in-stock item of the same
+	// or an alternate part definition instead.
+	RescheduleOptionSubstitute RescheduleOptionType = "substitute_part"
+	// RescheduleOptionSwap trades windows with a later task on the same
+	// aircraft that has all its parts.
+	RescheduleOptionSwap       RescheduleOptionType = "swap"
+	// RescheduleOptionSplit keeps the first half of the work in place and
+	// moves the rest to a continuation task after the part arrives.
+	RescheduleOptionSplit      RescheduleOptionType = "split"
+)
+
+type RescheduleOptionState string
+
+const (
+	RescheduleOptionProposed  RescheduleOptionState = "proposed"
+	RescheduleOptionApplied   RescheduleOptionState = "applied"
+	RescheduleOptionDiscarded RescheduleOptionState = "discarded"
+)
+
+// RescheduleOption represents a possible rescheduling action for a task
+// short of a part. ReservationID is the reservation whose item is no longer
+// in stock; it is nil when the part could not be reserved at all.
+// NewStartTime and NewEndTime are the task's new window, or for a split the
+// continuation's. TaskUpdatedAt snapshots the task so an option generated
+// before the task changed is not applied.
 type RescheduleOption struct {
-	OptionType      string
-	Description     string
-	NewStartTime    *time.Time
-	NewEndTime      *time.Time
-	AffectedTasks   []uuid.UUID
-	SubstitutePart  *uuid.UUID
+	ID               uuid.UUID
+	OrgID            uuid.UUID
+	TaskID           uuid.UUID
+	ReservationID    *uuid.UUID
+	PartDefinitionID uuid.UUID
+	Rank             int
+	State            RescheduleOptionState
+	OptionType       RescheduleOptionType
+	Description      string
+	NewStartTime     *time.Time
+	NewEndTime       *time.Time
+	AffectedTasks    []uuid.UUID
+	SubstitutePart   *uuid.UUID
+	TaskUpdatedAt    time.Time
+	ScheduleChangeID *uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	AppliedAt        *time.Time
+}
+
+// CanApply reports whether the option may still be applied.
+func (o RescheduleOption) CanApply() error {
+	if o.State != RescheduleOptionProposed {
+		return NewConflictError("reschedule option is " + string(o.State))
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,13 +24,21 @@ const (
 	TaskStateCancelled  TaskState = "cancelled"
 )
 
+func (t TaskType) IsValid() bool {
+	return t == TaskTypeInspection || t == TaskTypeRepair || t == TaskTypeOverhaul
+}
+
 type MaintenanceTask struct {
 	ID                 uuid.UUID
 	OrgID              uuid.UUID
 	AircraftID         uuid.UUID
 	ProgramID          *uuid.UUID
+	WorkPackageID      *uuid.UUID
+	BayID              *uuid.UUID
+	BaySlot            *int
 	Type               TaskType
 	State              TaskState
+	Priority           TaskPriority
 	StartTime          time.Time
 	EndTime            time.Time
 	AssignedMechanicID *uuid.UUID
@@ -49,7 +58,12 @@ type TaskTransitionContext struct {
 	AllReservationsClosed bool
 	RequiredPartsUsed     bool
 	ComplianceSignedOff   bool
-	Notes                 string
+	TaskCardsComplete     bool
+	LaborClockedOut       bool
+	// Crew is the task's crew assignments. A task with a crew may be started
+	// by any crew member and completed only by its certifying staff.
+	Crew  Crew
+	Notes string
 }
 
 func (t MaintenanceTask) ValidateCreate() error {
@@ -59,6 +73,34 @@ func (t MaintenanceTask) ValidateCreate() error {
 	return nil
 }
 
+// IsActive reports whether the task still needs its maintenance window.
+func (t MaintenanceTask) IsActive() bool {
+	return t.State == TaskStateScheduled || t.State == TaskStateInProgress
+}
+
+// Overlaps reports whether the task's window intersects [start, end).
+func (t MaintenanceTask) Overlaps(start, end time.Time) bool {
+	return t.StartTime.Before(end) && t.EndTime.After(start)
+}
+
+// NextFreeSlot returns the earliest window of the given duration, starting no
+// earlier than notBefore, that does not overlap any busy task.
+func NextFreeSlot(busy []MaintenanceTask, notBefore time.Time, duration time.Duration) (time.Time, time.Time) {
+	start := notBefore
+	sorted := append([]MaintenanceTask(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+	for _, task := range sorted {
+		if !task.EndTime.After(start) {
+			continue
+		}
+		if !task.StartTime.Before(start.Add(duration)) {
+			break
+		}
+		start = task.EndTime
+	}
+	return start, start.Add(duration)
+}
+
 func (t MaintenanceTask) CanTransition(newState TaskState, ctx TaskTransitionContext) error {
 	if t.State == newState {
 		return nil
@@ -74,10 +116,10 @@ func (t MaintenanceTask) CanTransition(newState TaskState, ctx TaskTransitionCon
 		if t.State != TaskStateScheduled {
 			return NewConflictError("task must be scheduled")
 		}
-		if t.AssignedMechanicID == nil {
-			return NewValidationError("assigned_mechanic_id is required")
+		if t.AssignedMechanicID == nil && len(ctx.Crew) == 0 {
+			return NewValidationError("assigned_mechanic_id or a crew is required")
 		}
-		if ctx.ActorRole == RoleMechanic && ctx.ActorID != *t.AssignedMechanicID {
+		if ctx.ActorRole == RoleMechanic && !t.isAssigned(ctx.ActorID) && !ctx.Crew.Includes(ctx.ActorID) {
 			return ErrForbidden
 		}
 		if ctx.AircraftStatus != AircraftGrounded {
@@ -91,11 +133,20 @@ func (t MaintenanceTask) CanTransition(newState TaskState, ctx TaskTransitionCon
 		if t.State != TaskStateInProgress {
 			return NewConflictError("task must be in progress")
 		}
-		if t.AssignedMechanicID == nil {
-			return NewValidationError("assigned_mechanic_id is required")
-		}
-		if ctx.ActorRole == RoleMechanic && ctx.ActorID != *t.AssignedMechanicID {
-			return ErrForbidden
+		if len(ctx.Crew) > 0 {
+			if len(ctx.Crew.Certifying()) == 0 {
+				return NewConflictError("crew needs certifying staff to complete the task")
+			}
+			if ctx.ActorRole == RoleMechanic && !ctx.Crew.HasRole(ctx.ActorID, CrewRoleCertifying) {
+				return ErrForbidden
+			}
+		} else {
+			if t.AssignedMechanicID == nil {
+				return NewValidationError("assigned_mechanic_id or a crew is required")
+			}
+			if ctx.ActorRole == RoleMechanic && !t.isAssigned(ctx.ActorID) {
+				return ErrForbidden
+			}
 		}
 		if ctx.Now.Before(t.EndTime) && !ctx.AllowEarlyCompletion {
 			return NewConflictError("task cannot be completed early")
@@ -109,6 +160,12 @@ func (t MaintenanceTask) CanTransition(newState TaskState, ctx TaskTransitionCon
 		if !ctx.ComplianceSignedOff {
 			return NewConflictError("compliance items must be signed off")
 		}
+		if !ctx.TaskCardsComplete {
+			return NewConflictError("task card steps must be performed and inspected")
+		}
+		if !ctx.LaborClockedOut {
+			return NewConflictError("mechanics must clock out of the task")
+		}
 		if notes == "" {
 			return NewValidationError("notes are required")
 		}
@@ -128,3 +185,7 @@ func (t MaintenanceTask) CanTransition(newState TaskState, ctx TaskTransitionCon
 		return NewValidationError("invalid task state transition")
 	}
 }
+
+func (t MaintenanceTask) isAssigned(mechanicID uuid.UUID) bool {
+	return t.AssignedMechanicID != nil && *t.AssignedMechanicID == mechanicID
+}
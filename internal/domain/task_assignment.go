@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CrewRole is the part a mechanic plays in a task's crew.
+type CrewRole string
+
+const (
+	CrewRoleLead       CrewRole = "lead"
+	CrewRoleTechnician CrewRole = "technician"
+	// CrewRoleCertifying is the certifying staff who signs the task off as
+	// complete.
+	CrewRoleCertifying CrewRole = "certifying_staff"
+	// CrewRoleInspector carries out the independent inspections of the
+	// task's required inspection items.
+	CrewRoleInspector CrewRole = "inspector"
+)
+
+const maxPlannedHours = 1000
+
+func (r CrewRole) IsValid() bool {
+	switch r {
+	case CrewRoleLead, CrewRoleTechnician, CrewRoleCertifying, CrewRoleInspector:
+		return true
+	}
+	return false
+}
+
+// TaskAssignment assigns a mechanic to a task's crew in one role, with the
+// hours planned for them on the task.
+type TaskAssignment struct {
+	ID           uuid.UUID
+	OrgID        uuid.UUID
+	TaskID       uuid.UUID
+	MechanicID   uuid.UUID
+	Role         CrewRole
+	PlannedHours float64
+	AssignedBy   uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (a TaskAssignment) Validate() error {
+	if a.TaskID == uuid.Nil {
+		return NewValidationError("task_id is required")
+	}
+	if a.MechanicID == uuid.Nil {
+		return NewValidationError("mechanic_id is required")
+	}
+	if !a.Role.IsValid() {
+		return NewValidationError("role must be lead, technician, certifying_staff or inspector")
+	}
+	if a.PlannedHours < 0 || a.PlannedHours > maxPlannedHours {
+		return NewValidationError("planned_hours must be between 0 and 1000")
+	}
+	return nil
+}
+
+// Crew is the set of assignments on one task.
+type Crew []TaskAssignment
+
+// Includes reports whether the mechanic holds any role in the crew.
+func (c Crew) Includes(mechanicID uuid.UUID) bool {
+	for _, a := range c {
+		if a.MechanicID == mechanicID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the mechanic holds role in the crew.
+func (c Crew) HasRole(mechanicID uuid.UUID, role CrewRole) bool {
+	for _, a := range c {
+		if a.MechanicID == mechanicID && a.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Certifying returns the crew's certifying staff.
+func (c Crew) Certifying() []uuid.UUID {
+	var out []uuid.UUID
+	for _, a := range c {
+		if a.Role == CrewRoleCertifying {
+			out = append(out, a.MechanicID)
+		}
+	}
+	return out
+}
+
+// PlannedHours totals the hours planned for the crew.
+func (c Crew) PlannedHours() float64 {
+	var total float64
+	for _, a := range c {
+		total += a.PlannedHours
+	}
+	return total
+}
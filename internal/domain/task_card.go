@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskCard is the ordered list of steps a maintenance task is carried out
+// by, such as the steps of a maintenance manual procedure. Reference names
+// the procedure it was taken from.
+type TaskCard struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	TaskID    uuid.UUID
+	Title     string
+	Reference string
+	CreatedBy uuid.UUID
+	Steps     []TaskCardStep
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TaskCardStep is one step of a task card. A step with MinValue or MaxValue
+// records a measured value, such as a torque or tolerance reading, that must
+// fall within those bounds. A required inspection item (RII) step must be
+// inspected by an authorized inspector other than the mechanic who
+// performed it.
+type TaskCardStep struct {
+	ID                 uuid.UUID
+	OrgID              uuid.UUID
+	CardID             uuid.UUID
+	Position           int
+	Instruction        string
+	RequiredInspection bool
+	MeasurementUnit    string
+	MinValue           *float64
+	MaxValue           *float64
+	MeasuredValue      *float64
+	PerformedBy        *uuid.UUID
+	PerformedAt        *time.Time
+	InspectedBy        *uuid.UUID
+	InspectedAt        *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// Validate checks the card and its steps before they are stored.
+func (c TaskCard) Validate() error {
+	if c.Title == "" {
+		return NewValidationError("title is required")
+	}
+	if len(c.Steps) == 0 {
+		return NewValidationError("task card needs at least one step")
+	}
+	for i, step := range c.Steps {
+		if step.Instruction == "" {
+			return NewValidationError("step " + strconv.Itoa(i+1) + " needs an instruction")
+		}
+		if step.MinValue != nil && step.MaxValue != nil && *step.MaxValue < *step.MinValue {
+			return NewValidationError("step " + strconv.Itoa(i+1) + " max_value is below min_value")
+		}
+	}
+	return nil
+}
+
+// Complete reports whether every step is performed and every RII step
+// inspected.
+func (c TaskCard) Complete() bool {
+	for _, step := range c.Steps {
+		if !step.Complete() {
+			return false
+		}
+	}
+	return true
+}
+
+// Step returns the step with the given id.
+func (c TaskCard) Step(id uuid.UUID) (TaskCardStep, bool) {
+	for _, step := range c.Steps {
+		if step.ID == id {
+			return step, true
+		}
+	}
+	return TaskCardStep{}, false
+}
+
+// RequiresMeasurement reports whether performing the step records a value.
+func (s TaskCardStep) RequiresMeasurement() bool {
+	return s.MinValue != nil || s.MaxValue != nil
+}
+
+// Performed reports whether the step has been signed off as performed.
+func (s TaskCardStep) Performed() bool {
+	return s.PerformedAt != nil
+}
+
+// Inspected reports whether the step has been signed off by an inspector.
+func (s TaskCardStep) Inspected() bool {
+	return s.InspectedAt != nil
+}
+
+// Complete reports whether the step needs no further sign-off.
+func (s TaskCardStep) Complete() bool {
+	return s.Performed() && (!s.RequiredInspection || s.Inspected())
+}
+
+// CanPerform checks that a mechanic may sign the step off as performed with
+// the given measured value.
+func (s TaskCardStep) CanPerform(role Role, value *float64) error {
+	if role != RoleMechanic {
+		return ErrForbidden
+	}
+	if s.Performed() {
+		return NewConflictError("step already performed")
+	}
+	if !s.RequiresMeasurement() {
+		if value != nil {
+			return NewValidationError("step does not record a measured value")
+		}
+		return nil
+	}
+	if value == nil {
+		return NewValidationError("measured_value is required")
+	}
+	if s.MinValue != nil && *value < *s.MinValue {
+		return NewValidationError("measured value " + formatMeasurement(*value, s.MeasurementUnit) + " is below the minimum of " + formatMeasurement(*s.MinValue, s.MeasurementUnit))
+	}
+	if s.MaxValue != nil && *value > *s.MaxValue {
+		return NewValidationError("measured value " + formatMeasurement(*value, s.MeasurementUnit) + " is above the maximum of " + formatMeasurement(*s.MaxValue, s.MeasurementUnit))
+	}
+	return nil
+}
+
+// CanInspect checks that userID may sign the step off as inspected. The
+// inspector's authorization is checked separately against the task's
+// inspection requirements.
+func (s TaskCardStep) CanInspect(role Role, userID uuid.UUID) error {
+	if role != RoleMechanic {
+		return ErrForbidden
+	}
+	if !s.RequiredInspection {
+		return NewValidationError("step is not a required inspection item")
+	}
+	if !s.Performed() {
+		return NewConflictError("step must be performed before it is inspected")
+	}
+	if s.Inspected() {
+		return NewConflictError("step already inspected")
+	}
+	if s.PerformedBy != nil && *s.PerformedBy == userID {
+		return NewConflictError("a step cannot be inspected by the mechanic who performed it")
+	}
+	return nil
+}
+
+func formatMeasurement(value float64, unit string) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if unit == "" {
+		return formatted
+	}
+	return formatted + " " + unit
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UtilizationEntryType string
+
+const (
+	UtilizationEntryFlight UtilizationEntryType = "flight"
+	UtilizationEntryDaily  UtilizationEntryType = "daily"
+)
+
+// AircraftUtilization is a single utilization log entry. BlockHours and
+// Cycles are the usage flown for the flight or day; HoursTotal and
+// CyclesTotal are the aircraft's running totals after the entry.
+type AircraftUtilization struct {
+	ID              uuid.UUID
+	OrgID           uuid.UUID
+	AircraftID      uuid.UUID
+	EntryType       UtilizationEntryType
+	UtilizationDate time.Time
+	FlightNumber    string
+	BlockHours      float64
+	Cycles          int
+	HoursTotal      float64
+	CyclesTotal     int
+	RecordedBy      *uuid.UUID
+	CreatedAt       time.Time
+}
+
+func (t UtilizationEntryType) IsValid() bool {
+	return t == UtilizationEntryFlight || t == UtilizationEntryDaily
+}
+
+// UtilizationRate is an aircraft's average daily usage over a period.
+type UtilizationRate struct {
+	HoursPerDay  float64
+	CyclesPerDay float64
+}
@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WorkPackageState string
+
+const (
+	WorkPackagePlanned    WorkPackageState = "planned"
+	WorkPackageInProgress WorkPackageState = "in_progress"
+	WorkPackageCompleted  WorkPackageState = "completed"
+	WorkPackageCancelled  WorkPackageState = "cancelled"
+)
+
+// WorkPackage groups the tasks of one aircraft into a single hangar visit
+// such as an A-check. Every task in the package is scheduled inside the
+// visit window.
+type WorkPackage struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	AircraftID uuid.UUID
+	Name       string
+	State      WorkPackageState
+	VisitStart time.Time
+	VisitEnd   time.Time
+	Notes      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+}
+
+// WorkPackageSummary rolls up the state of a package's tasks, their part
+// reservations and their compliance items.
+type WorkPackageSummary struct {
+	Tasks             int
+	Scheduled         int
+	InProgress        int
+	Completed         int
+	Cancelled         int
+	CompletionPercent int
+	PartsReserved     int
+	PartsUsed         int
+	PartsReleased     int
+	// PartsReady is true when no task still to be worked has a released
+	// reservation, i.e. every part booked for the visit is still held or
+	// already fitted.
+	PartsReady          bool
+	ComplianceItems     int
+	ComplianceSignedOff int
+	// OpenFindings counts findings raised on the package's tasks that are
+	// neither converted into repair tasks nor closed.
+	OpenFindings int
+}
+
+// TasksClosed reports whether every task is completed or cancelled.
+func (s WorkPackageSummary) TasksClosed() bool {
+	return s.Scheduled == 0 && s.InProgress == 0
+}
+
+// ComplianceComplete reports whether every compliance item is signed off.
+func (s WorkPackageSummary) ComplianceComplete() bool {
+	return s.ComplianceSignedOff == s.ComplianceItems
+}
+
+type WorkPackageTransitionContext struct {
+	ActorRole Role
+	Summary   WorkPackageSummary
+}
+
+func (p WorkPackage) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return NewValidationError("name is required")
+	}
+	if !p.VisitEnd.After(p.VisitStart) {
+		return NewValidationError("visit_end must be after visit_start")
+	}
+	return nil
+}
+
+// IsOpen reports whether tasks can still be added to or removed from the
+// package.
+func (p WorkPackage) IsOpen() bool {
+	return p.State == WorkPackagePlanned || p.State == WorkPackageInProgress
+}
+
+// Covers reports whether the task is scheduled inside the visit window.
+func (p WorkPackage) Covers(task MaintenanceTask) bool {
+	return !task.StartTime.Before(p.VisitStart) && !task.EndTime.After(p.VisitEnd)
+}
+
+// CanAddTask checks that the task can join the package.
+func (p WorkPackage) CanAddTask(task MaintenanceTask) error {
+	if !p.IsOpen() {
+		return NewConflictError("work package is closed")
+	}
+	if task.AircraftID != p.AircraftID {
+		return NewValidationError("task belongs to a different aircraft")
+	}
+	if task.State != TaskStateScheduled && task.State != TaskStateInProgress {
+		return NewConflictError("only scheduled or in progress tasks can be added")
+	}
+	if task.WorkPackageID != nil && *task.WorkPackageID != p.ID {
+		return NewConflictError("task already belongs to another work package")
+	}
+	if !p.Covers(task) {
+		return NewValidationError("task must be scheduled within the visit window")
+	}
+	return nil
+}
+
+func (p WorkPackage) CanTransition(newState WorkPackageState, ctx WorkPackageTransitionContext) error {
+	if p.State == newState {
+		return nil
+	}
+	if ctx.ActorRole != RoleScheduler && ctx.ActorRole != RoleAdmin && ctx.ActorRole != RoleTenantAdmin {
+		return ErrForbidden
+	}
+
+	switch newState {
+	case WorkPackageInProgress:
+		if p.State != WorkPackagePlanned {
+			return NewConflictError("work package must be planned")
+		}
+		if ctx.Summary.Tasks == 0 {
+			return NewConflictError("work package has no tasks")
+		}
+		return nil
+	case WorkPackageCompleted:
+		if p.State != WorkPackageInProgress {
+			return NewConflictError("work package must be in progress")
+		}
+		if !ctx.Summary.TasksClosed() {
+			return NewConflictError("all tasks must be completed or cancelled")
+		}
+		if ctx.Summary.PartsReserved > 0 {
+			return NewConflictError("part reservations must be used or released")
+		}
+		if !ctx.Summary.ComplianceComplete() {
+			return NewConflictError("compliance items must be signed off")
+		}
+		if ctx.Summary.OpenFindings > 0 {
+			return NewConflictError("open findings must be converted or closed")
+		}
+		return nil
+	case WorkPackageCancelled:
+		if p.State == WorkPackageCompleted {
+			return NewConflictError("completed work packages cannot be cancelled")
+		}
+		if ctx.Summary.InProgress > 0 {
+			return NewConflictError("tasks in progress must be completed or cancelled first")
+		}
+		return nil
+	default:
+		return NewValidationError("invalid work package state transition")
+	}
+}
+
+// NextSlot returns the earliest window of the given duration inside the visit,
+// starting no earlier than notBefore, that does not overlap any busy task.
+// ok is false when the visit has no room left.
+func (p WorkPackage) NextSlot(busy []MaintenanceTask, notBefore time.Time, duration time.Duration) (time.Time, time.Time, bool) {
+	start := p.VisitStart
+	if notBefore.After(start) {
+		start = notBefore
+	}
+	start, end := NextFreeSlot(busy, start, duration)
+	if end.After(p.VisitEnd) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// SummarizeWorkPackage rolls up the package's tasks with the reservations and
+// compliance items recorded against them.
+func SummarizeWorkPackage(tasks []MaintenanceTask, reservations []PartReservation, compliance []ComplianceItem) WorkPackageSummary {
+	summary := WorkPackageSummary{PartsReady: true}
+	states := make(map[uuid.UUID]TaskState, len(tasks))
+	for _, task := range tasks {
+		states[task.ID] = task.State
+		summary.Tasks++
+		switch task.State {
+		case TaskStateScheduled:
+			summary.Scheduled++
+		case TaskStateInProgress:
+			summary.InProgress++
+		case TaskStateCompleted:
+			summary.Completed++
+		case TaskStateCancelled:
+			summary.Cancelled++
+		}
+	}
+	if active := summary.Tasks - summary.Cancelled; active > 0 {
+		summary.CompletionPercent = summary.Completed * 100 / active
+	}
+	for _, res := range reservations {
+		switch res.State {
+		case ReservationReserved:
+			summary.PartsReserved++
+		case ReservationUsed:
+			summary.PartsUsed++
+		case ReservationReleased:
+			summary.PartsReleased++
+			state := states[res.TaskID]
+			if state == TaskStateScheduled || state == TaskStateInProgress {
+				summary.PartsReady = false
+			}
+		}
+	}
+	for _, item := range compliance {
+		summary.ComplianceItems++
+		if item.SignOffTime != nil && item.Result != CompliancePending {
+			summary.ComplianceSignedOff++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,20 @@
+package blobstore
+
+import (
+	"fmt"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+)
+
+// New returns the store named by kind: "filesystem", keeping blobs under
+// dir, or "s3", using the given S3 settings.
+func New(kind, dir string, s3 S3Store) (ports.BlobStore, error) {
+	switch kind {
+	case "", "filesystem":
+		return &FilesystemStore{Root: dir}, nil
+	case "s3":
+		return &s3, nil
+	default:
+		return nil, fmt.Errorf("blobstore: unknown store %q", kind)
+	}
+}
@@ -40,7 +40,7 @@ func (r *AircraftRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
+		SELECT id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
 		FROM aircraft
 		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
 	`, orgID, id)
@@ -52,7 +52,7 @@ func (r *AircraftRepository) GetByTailNumber(ctx context.Context, orgID uuid.UUI
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
+		SELECT id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
 		FROM aircraft
 		WHERE org_id=$1 AND tail_number=$2 AND deleted_at IS NULL
 	`, orgID, tailNumber)
@@ -60,37 +60,73 @@ func (r *AircraftRepository) GetByTailNumber(ctx context.Context, orgID uuid.UUI
 }
 
 func (r *AircraftRepository) Create(ctx context.Context, aircraft domain.Aircraft) (domain.Aircraft, error) {
+	return r.CreateWithPrograms(ctx, aircraft, nil)
+}
+
+func (r *AircraftRepository) CreateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
 	if r == nil || r.DB == nil {
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
-	row := r.DB.QueryRow(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
 		INSERT INTO aircraft
-			(id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, created_at, updated_at, deleted_at)
+			(id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, created_at, updated_at, deleted_at)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
-		RETURNING id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
-	`, aircraft.ID, aircraft.OrgID, aircraft.TailNumber, aircraft.Model, aircraft.LastMaintenance, aircraft.NextDue, aircraft.Status, aircraft.CapacitySlots, aircraft.FlightHoursTotal, aircraft.CyclesTotal, aircraft.CreatedAt, aircraft.UpdatedAt, aircraft.DeletedAt)
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+		RETURNING id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
+	`, aircraft.ID, aircraft.OrgID, aircraft.TailNumber, aircraft.Model, aircraft.AircraftTypeID, aircraft.LastMaintenance, aircraft.NextDue, aircraft.Status, aircraft.CapacitySlots, aircraft.FlightHoursTotal, aircraft.CyclesTotal, aircraft.CreatedAt, aircraft.UpdatedAt, aircraft.DeletedAt)
 	created, err := scanAircraft(row)
 	if err != nil {
 		return domain.Aircraft{}, TranslateError(err)
 	}
+	for _, program := range programs {
+		if _, err := insertProgram(ctx, tx, program); err != nil {
+			return domain.Aircraft{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Aircraft{}, err
+	}
 	return created, nil
 }
 
 func (r *AircraftRepository) Update(ctx context.Context, aircraft domain.Aircraft) (domain.Aircraft, error) {
+	return r.UpdateWithPrograms(ctx, aircraft, nil)
+}
+
+func (r *AircraftRepository) UpdateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
 	if r == nil || r.DB == nil {
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
-	row := r.DB.QueryRow(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
 		UPDATE aircraft
-		SET tail_number=$1, model=$2, last_maintenance=$3, next_due=$4, status=$5, capacity_slots=$6, flight_hours_total=$7, cycles_total=$8, updated_at=$9
-		WHERE org_id=$10 AND id=$11 AND deleted_at IS NULL
-		RETURNING id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
-	`, aircraft.TailNumber, aircraft.Model, aircraft.LastMaintenance, aircraft.NextDue, aircraft.Status, aircraft.CapacitySlots, aircraft.FlightHoursTotal, aircraft.CyclesTotal, aircraft.UpdatedAt, aircraft.OrgID, aircraft.ID)
+		SET tail_number=$1, model=$2, aircraft_type_id=$3, last_maintenance=$4, next_due=$5, status=$6, capacity_slots=$7, updated_at=$8
+		WHERE org_id=$9 AND id=$10 AND deleted_at IS NULL
+		RETURNING id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
+	`, aircraft.TailNumber, aircraft.Model, aircraft.AircraftTypeID, aircraft.LastMaintenance, aircraft.NextDue, aircraft.Status, aircraft.CapacitySlots, aircraft.UpdatedAt, aircraft.OrgID, aircraft.ID)
 	updated, err := scanAircraft(row)
 	if err != nil {
 		return domain.Aircraft{}, TranslateError(err)
 	}
+	for _, program := range programs {
+		if _, err := insertProgram(ctx, tx, program); err != nil {
+			return domain.Aircraft{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Aircraft{}, err
+	}
 	return updated, nil
 }
 
@@ -134,6 +170,9 @@ func (r *AircraftRepository) List(ctx context.Context, filter ports.AircraftFilt
 	if filter.TailNumber != "" {
 		add("tail_number ILIKE ", "%"+filter.TailNumber+"%")
 	}
+	if filter.AircraftTypeID != nil {
+		add("aircraft_type_id=", *filter.AircraftTypeID)
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -148,7 +187,7 @@ func (r *AircraftRepository) List(ctx context.Context, filter ports.AircraftFilt
 	}
 
 	query := `
-		SELECT id, org_id, tail_number, model, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
+		SELECT id, org_id, tail_number, model, aircraft_type_id, last_maintenance, next_due, status, capacity_slots, flight_hours_total, cycles_total, deleted_at, created_at, updated_at
 		FROM aircraft
 		WHERE deleted_at IS NULL`
 	if len(clauses) > 0 {
@@ -178,7 +217,7 @@ func scanAircraft(row pgx.Row) (domain.Aircraft, error) {
 	var aircraft domain.Aircraft
 	var lastMaintenance *time.Time
 	var nextDue *time.Time
-	if err := row.Scan(&aircraft.ID, &aircraft.OrgID, &aircraft.TailNumber, &aircraft.Model, &lastMaintenance, &nextDue, &aircraft.Status, &aircraft.CapacitySlots, &aircraft.FlightHoursTotal, &aircraft.CyclesTotal, &aircraft.DeletedAt, &aircraft.CreatedAt, &aircraft.UpdatedAt); err != nil {
+	if err := row.Scan(&aircraft.ID, &aircraft.OrgID, &aircraft.TailNumber, &aircraft.Model, &aircraft.AircraftTypeID, &lastMaintenance, &nextDue, &aircraft.Status, &aircraft.CapacitySlots, &aircraft.FlightHoursTotal, &aircraft.CyclesTotal, &aircraft.DeletedAt, &aircraft.CreatedAt, &aircraft.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.Aircraft{}, domain.ErrNotFound
 		}
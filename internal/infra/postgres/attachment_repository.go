@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttachmentRepository struct {
+	DB *pgxpool.Pool
+}
+
+const attachmentColumns = `id, org_id, entity_type, entity_id, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by,
+	deleted_at, created_at, updated_at`
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment domain.Attachment) (domain.Attachment, error) {
+	if r == nil || r.DB == nil {
+		return domain.Attachment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO attachments (id, org_id, entity_type, entity_id, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		RETURNING `+attachmentColumns,
+		attachment.ID, attachment.OrgID, attachment.EntityType, attachment.EntityID, attachment.FileName, attachment.ContentType,
+		attachment.SizeBytes, attachment.SHA256, attachment.StorageKey, attachment.UploadedBy, attachment.CreatedAt, attachment.UpdatedAt)
+	created, err := scanAttachment(row)
+	if err != nil {
+		return domain.Attachment{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *AttachmentRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Attachment, error) {
+	if r == nil || r.DB == nil {
+		return domain.Attachment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL`, orgID, id)
+	return scanAttachment(row)
+}
+
+func (r *AttachmentRepository) ListByEntity(ctx context.Context, orgID uuid.UUID, entityType domain.AttachmentEntityType, entityID uuid.UUID) ([]domain.Attachment, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	return r.query(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE org_id=$1 AND entity_type=$2 AND entity_id=$3 AND deleted_at IS NULL
+		ORDER BY created_at ASC, id ASC
+	`, orgID, entityType, entityID)
+}
+
+func (r *AttachmentRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE attachments SET deleted_at=$3, updated_at=$3
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id, at)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *AttachmentRepository) ListExpired(ctx context.Context, orgID uuid.UUID, cutoff time.Time, limit int) ([]domain.Attachment, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	return r.query(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments a
+		WHERE a.org_id=$1 AND (
+			(a.deleted_at IS NOT NULL AND a.deleted_at < $2)
+			OR (a.entity_type='task' AND EXISTS (
+				SELECT 1 FROM maintenance_tasks
+				WHERE org_id=$1 AND id=a.entity_id AND deleted_at IS NOT NULL AND deleted_at < $2
+			))
+			OR (a.entity_type='compliance_item' AND EXISTS (
+				SELECT 1 FROM compliance_items
+				WHERE org_id=$1 AND id=a.entity_id AND deleted_at IS NOT NULL AND deleted_at < $2
+			))
+		)
+		ORDER BY a.created_at ASC, a.id ASC
+		LIMIT $3
+	`, orgID, cutoff, limit)
+}
+
+func (r *AttachmentRepository) Purge(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM attachments WHERE org_id=$1 AND id=$2`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *AttachmentRepository) query(ctx context.Context, query string, args ...any) ([]domain.Attachment, error) {
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var attachments []domain.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func scanAttachment(row pgx.Row) (domain.Attachment, error) {
+	var attachment domain.Attachment
+	var entityType string
+	if err := row.Scan(&attachment.ID, &attachment.OrgID, &entityType, &attachment.EntityID, &attachment.FileName, &attachment.ContentType,
+		&attachment.SizeBytes, &attachment.SHA256, &attachment.StorageKey, &attachment.UploadedBy,
+		&attachment.DeletedAt, &attachment.CreatedAt, &attachment.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Attachment{}, domain.ErrNotFound
+		}
+		return domain.Attachment{}, err
+	}
+	attachment.EntityType = domain.AttachmentEntityType(entityType)
+	return attachment, nil
+}
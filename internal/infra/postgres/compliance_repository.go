@@ -137,6 +137,20 @@ func (r *ComplianceRepository) Create(ctx context.Context, item domain.Complianc
 	return TranslateError(err)
 }
 
+// insertComplianceItems inserts the items within tx, for writing a task's
+// checklist together with the task.
+func insertComplianceItems(ctx context.Context, tx pgx.Tx, items []domain.ComplianceItem) error {
+	for _, item := range items {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO compliance_items (id, org_id, task_id, description, result, sign_off_user_id, sign_off_time, created_at, updated_at, deleted_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		`, item.ID, item.OrgID, item.TaskID, item.Description, item.Result, item.SignOffUserID, item.SignOffTime, item.CreatedAt, item.UpdatedAt, item.DeletedAt); err != nil {
+			return TranslateError(err)
+		}
+	}
+	return nil
+}
+
 func (r *ComplianceRepository) Update(ctx context.Context, item domain.ComplianceItem) error {
 	if r == nil || r.DB == nil {
 		return nil
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ComponentChangeRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *ComponentChangeRepository) Record(ctx context.Context, change domain.ComponentChange) (domain.ComponentChange, error) {
+	if r == nil || r.DB == nil {
+		return domain.ComponentChange{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.ComponentChange{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The part usage is read back from the updated row so the log reflects
+	// the counters at the moment of the change.
+	var row pgx.Row
+	switch change.Action {
+	case domain.ComponentInstall:
+		row = tx.QueryRow(ctx, `
+			UPDATE part_items
+			SET aircraft_id=$1, position=$2, status='used', updated_at=$3
+			WHERE org_id=$4 AND id=$5 AND deleted_at IS NULL AND aircraft_id IS NULL
+			RETURNING hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul
+		`, change.AircraftID, change.Position, change.CreatedAt, change.OrgID, change.PartItemID)
+	case domain.ComponentRemove:
+		row = tx.QueryRow(ctx, `
+			UPDATE part_items
+			SET aircraft_id=NULL, position=NULL, status='in_stock', updated_at=$1
+			WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL AND aircraft_id=$4
+			RETURNING hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul
+		`, change.CreatedAt, change.OrgID, change.PartItemID, change.AircraftID)
+	default:
+		return domain.ComponentChange{}, domain.NewValidationError("action must be install or remove")
+	}
+	if err := row.Scan(&change.PartHoursSinceNew, &change.PartCyclesSinceNew, &change.PartHoursSinceOverhaul, &change.PartCyclesSinceOverhaul); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ComponentChange{}, domain.NewConflictError("part is no longer in the expected installation state")
+		}
+		return domain.ComponentChange{}, TranslateError(err)
+	}
+
+	created, err := scanComponentChange(tx.QueryRow(ctx, `
+		INSERT INTO component_changes
+			(id, org_id, part_item_id, aircraft_id, action, position, task_id, reason, aircraft_hours, aircraft_cycles,
+			 part_hours_since_new, part_cycles_since_new, part_hours_since_overhaul, part_cycles_since_overhaul,
+			 performed_by, performed_at, notes, created_at)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
+		RETURNING id, org_id, part_item_id, aircraft_id, action, position, task_id, reason, aircraft_hours, aircraft_cycles,
+		          part_hours_since_new, part_cycles_since_new, part_hours_since_overhaul, part_cycles_since_overhaul,
+		          performed_by, performed_at, notes, created_at
+	`, change.ID, change.OrgID, change.PartItemID, change.AircraftID, change.Action, change.Position, change.TaskID, change.Reason,
+		change.AircraftHours, change.AircraftCycles, change.PartHoursSinceNew, change.PartCyclesSinceNew,
+		change.PartHoursSinceOverhaul, change.PartCyclesSinceOverhaul, change.PerformedBy, change.PerformedAt, change.Notes, change.CreatedAt))
+	if err != nil {
+		return domain.ComponentChange{}, TranslateError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.ComponentChange{}, err
+	}
+	return created, nil
+}
+
+func (r *ComponentChangeRepository) List(ctx context.Context, filter ports.ComponentChangeFilter) ([]domain.ComponentChange, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 3)
+	args := make([]any, 0, 5)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.PartItemID != nil {
+		add("part_item_id=", *filter.PartItemID)
+	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, part_item_id, aircraft_id, action, position, task_id, reason, aircraft_hours, aircraft_cycles,
+		       part_hours_since_new, part_cycles_since_new, part_hours_since_overhaul, part_cycles_since_overhaul,
+		       performed_by, performed_at, notes, created_at
+		FROM component_changes
+		WHERE 1=1`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY performed_at ASC, created_at ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []domain.ComponentChange
+	for rows.Next() {
+		change, err := scanComponentChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+func scanComponentChange(row pgx.Row) (domain.ComponentChange, error) {
+	var change domain.ComponentChange
+	if err := row.Scan(&change.ID, &change.OrgID, &change.PartItemID, &change.AircraftID, &change.Action, &change.Position, &change.TaskID,
+		&change.Reason, &change.AircraftHours, &change.AircraftCycles, &change.PartHoursSinceNew, &change.PartCyclesSinceNew,
+		&change.PartHoursSinceOverhaul, &change.PartCyclesSinceOverhaul, &change.PerformedBy, &change.PerformedAt, &change.Notes, &change.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ComponentChange{}, domain.ErrNotFound
+		}
+		return domain.ComponentChange{}, err
+	}
+	return change, nil
+}
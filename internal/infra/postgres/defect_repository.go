@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DefectRepository struct {
+	DB *pgxpool.Pool
+}
+
+const defectColumns = `id, org_id, aircraft_id, title, description, ata_chapter, state, reported_by, reported_at,
+	mel_category, mel_item, deferral_reference, operational_restrictions, deferred_by, deferred_at, expires_at,
+	rectification_task_id, rectified_by, rectified_at, closed_by, closed_at, notes, created_at, updated_at`
+
+func (r *DefectRepository) Create(ctx context.Context, defect domain.Defect) (domain.Defect, error) {
+	if r == nil || r.DB == nil {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO defects (id, org_id, aircraft_id, title, description, ata_chapter, state, reported_by, reported_at, notes, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		RETURNING `+defectColumns,
+		defect.ID, defect.OrgID, defect.AircraftID, defect.Title, defect.Description, defect.ATAChapter, defect.State,
+		defect.ReportedBy, defect.ReportedAt, defect.Notes, defect.CreatedAt, defect.UpdatedAt)
+	created, err := scanDefect(row)
+	if err != nil {
+		return domain.Defect{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *DefectRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Defect, error) {
+	if r == nil || r.DB == nil {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `SELECT `+defectColumns+` FROM defects WHERE org_id=$1 AND id=$2`, orgID, id)
+	return scanDefect(row)
+}
+
+func (r *DefectRepository) List(ctx context.Context, filter ports.DefectFilter) ([]domain.Defect, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 5)
+	args := make([]any, 0, 7)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+	if filter.State != nil {
+		add("state=", *filter.State)
+	}
+	order := " ORDER BY reported_at DESC, id ASC"
+	if filter.ExpiringBefore != nil {
+		clauses = append(clauses, "state='deferred'")
+		add("expires_at < ", *filter.ExpiringBefore)
+		order = " ORDER BY expires_at ASC, id ASC"
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `SELECT ` + defectColumns + ` FROM defects`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += order + " LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var defects []domain.Defect
+	for rows.Next() {
+		defect, err := scanDefect(rows)
+		if err != nil {
+			return nil, err
+		}
+		defects = append(defects, defect)
+	}
+	return defects, rows.Err()
+}
+
+func (r *DefectRepository) Update(ctx context.Context, defect domain.Defect, from domain.DefectState) (domain.Defect, error) {
+	if r == nil || r.DB == nil {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	var category *string
+	if defect.MELCategory != nil {
+		value := string(*defect.MELCategory)
+		category = &value
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE defects
+		SET state=$4, mel_category=$5, mel_item=$6, deferral_reference=$7, operational_restrictions=$8,
+			deferred_by=$9, deferred_at=$10, expires_at=$11, rectification_task_id=$12,
+			rectified_by=$13, rectified_at=$14, closed_by=$15, closed_at=$16, notes=$17, updated_at=$18
+		WHERE org_id=$1 AND id=$2 AND state=$3
+		RETURNING `+defectColumns,
+		defect.OrgID, defect.ID, from, defect.State, category, defect.MELItem, defect.DeferralReference, defect.OperationalRestrictions,
+		defect.DeferredBy, defect.DeferredAt, defect.ExpiresAt, defect.RectificationTaskID,
+		defect.RectifiedBy, defect.RectifiedAt, defect.ClosedBy, defect.ClosedAt, defect.Notes, defect.UpdatedAt)
+	updated, err := scanDefect(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := r.GetByID(ctx, defect.OrgID, defect.ID); err != nil {
+			return domain.Defect{}, err
+		}
+		return domain.Defect{}, domain.NewConflictError("defect is no longer " + string(from))
+	}
+	if err != nil {
+		return domain.Defect{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *DefectRepository) Extend(ctx context.Context, extension domain.DeferralExtension) (domain.Defect, error) {
+	if r == nil || r.DB == nil {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Defect{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
+		UPDATE defects
+		SET expires_at=$4, updated_at=$5
+		WHERE org_id=$1 AND id=$2 AND state='deferred' AND expires_at=$3
+		RETURNING `+defectColumns,
+		extension.OrgID, extension.DefectID, extension.PreviousExpiresAt, extension.NewExpiresAt, extension.CreatedAt)
+	defect, err := scanDefect(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := r.GetByID(ctx, extension.OrgID, extension.DefectID); err != nil {
+			return domain.Defect{}, err
+		}
+		return domain.Defect{}, domain.NewConflictError("deferral changed in the meantime")
+	}
+	if err != nil {
+		return domain.Defect{}, TranslateError(err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO defect_deferral_extensions
+			(id, org_id, defect_id, previous_expires_at, new_expires_at, reason, approval_reference, approved_by, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`, extension.ID, extension.OrgID, extension.DefectID, extension.PreviousExpiresAt, extension.NewExpiresAt,
+		extension.Reason, extension.ApprovalReference, extension.ApprovedBy, extension.CreatedAt); err != nil {
+		return domain.Defect{}, TranslateError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Defect{}, TranslateError(err)
+	}
+	return defect, nil
+}
+
+func (r *DefectRepository) ListExtensions(ctx context.Context, orgID, defectID uuid.UUID) ([]domain.DeferralExtension, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, defect_id, previous_expires_at, new_expires_at, reason, approval_reference, approved_by, created_at
+		FROM defect_deferral_extensions
+		WHERE org_id=$1 AND defect_id=$2
+		ORDER BY created_at ASC, id ASC
+	`, orgID, defectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var extensions []domain.DeferralExtension
+	for rows.Next() {
+		var ext domain.DeferralExtension
+		if err := rows.Scan(&ext.ID, &ext.OrgID, &ext.DefectID, &ext.PreviousExpiresAt, &ext.NewExpiresAt, &ext.Reason,
+			&ext.ApprovalReference, &ext.ApprovedBy, &ext.CreatedAt); err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions, rows.Err()
+}
+
+func scanDefect(row pgx.Row) (domain.Defect, error) {
+	var defect domain.Defect
+	var state string
+	var category *string
+	if err := row.Scan(&defect.ID, &defect.OrgID, &defect.AircraftID, &defect.Title, &defect.Description, &defect.ATAChapter,
+		&state, &defect.ReportedBy, &defect.ReportedAt, &category, &defect.MELItem, &defect.DeferralReference,
+		&defect.OperationalRestrictions, &defect.DeferredBy, &defect.DeferredAt, &defect.ExpiresAt, &defect.RectificationTaskID,
+		&defect.RectifiedBy, &defect.RectifiedAt, &defect.ClosedBy, &defect.ClosedAt, &defect.Notes,
+		&defect.CreatedAt, &defect.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Defect{}, domain.ErrNotFound
+		}
+		return domain.Defect{}, err
+	}
+	defect.State = domain.DefectState(state)
+	if category != nil {
+		value := domain.MELCategory(*category)
+		defect.MELCategory = &value
+	}
+	return defect, nil
+}
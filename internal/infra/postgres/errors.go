@@ -14,7 +14,19 @@ func TranslateError(err error) error {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
 		switch pgErr.Code {
-		case "23505", "23P01":
+		case "23P01":
+			switch pgErr.ConstraintName {
+			case "maintenance_tasks_no_overlap":
+				return domain.NewCapacityConflict(domain.CapacityConflictAircraftOverlap, "aircraft already has maintenance scheduled in this window")
+			case "maintenance_tasks_bay_slot_no_overlap":
+				return domain.NewCapacityConflict(domain.CapacityConflictBayFull, "bay slot was taken by another booking")
+			case "planned_flights_no_overlap":
+				return domain.NewCapacityConflict(domain.CapacityConflictFlightOverlap, "aircraft already has a flight planned in this window")
+			case "labor_entries_no_overlap":
+				return domain.NewCapacityConflict(domain.CapacityConflictLaborOverlap, "mechanic already has labor booked in this time")
+			}
+			return domain.ErrConflict
+		case "23505":
 			return domain.ErrConflict
 		case "23503":
 			return domain.NewValidationError("invalid reference")
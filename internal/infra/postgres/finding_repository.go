@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FindingRepository struct {
+	DB *pgxpool.Pool
+}
+
+const findingColumns = `id, org_id, task_id, aircraft_id, compliance_item_id, description, zone, ata_chapter, photo_urls, state,
+	raised_by, raised_at, repair_task_id, converted_by, converted_at, closed_by, closed_at, resolution_notes, created_at, updated_at`
+
+func (r *FindingRepository) Create(ctx context.Context, finding domain.Finding) (domain.Finding, error) {
+	if r == nil || r.DB == nil {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	photos := finding.PhotoURLs
+	if photos == nil {
+		photos = []string{}
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO findings (id, org_id, task_id, aircraft_id, compliance_item_id, description, zone, ata_chapter, photo_urls, state, raised_by, raised_at, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+		RETURNING `+findingColumns,
+		finding.ID, finding.OrgID, finding.TaskID, finding.AircraftID, finding.ComplianceItemID, finding.Description, finding.Zone,
+		finding.ATAChapter, photos, finding.State, finding.RaisedBy, finding.RaisedAt, finding.CreatedAt, finding.UpdatedAt)
+	created, err := scanFinding(row)
+	if err != nil {
+		return domain.Finding{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *FindingRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Finding, error) {
+	if r == nil || r.DB == nil {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `SELECT `+findingColumns+` FROM findings WHERE org_id=$1 AND id=$2`, orgID, id)
+	return scanFinding(row)
+}
+
+func (r *FindingRepository) List(ctx context.Context, filter ports.FindingFilter) ([]domain.Finding, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 4)
+	args := make([]any, 0, 6)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.TaskID != nil {
+		add("task_id=", *filter.TaskID)
+	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+	if filter.State != nil {
+		add("state=", *filter.State)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `SELECT ` + findingColumns + ` FROM findings`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY raised_at DESC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var findings []domain.Finding
+	for rows.Next() {
+		finding, err := scanFinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, finding)
+	}
+	return findings, rows.Err()
+}
+
+func (r *FindingRepository) Update(ctx context.Context, finding domain.Finding, from domain.FindingState) (domain.Finding, error) {
+	if r == nil || r.DB == nil {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE findings
+		SET state=$4, repair_task_id=$5, converted_by=$6, converted_at=$7, closed_by=$8, closed_at=$9, resolution_notes=$10, updated_at=$11
+		WHERE org_id=$1 AND id=$2 AND state=$3
+		RETURNING `+findingColumns,
+		finding.OrgID, finding.ID, from, finding.State, finding.RepairTaskID, finding.ConvertedBy, finding.ConvertedAt,
+		finding.ClosedBy, finding.ClosedAt, finding.ResolutionNotes, finding.UpdatedAt)
+	updated, err := scanFinding(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := r.GetByID(ctx, finding.OrgID, finding.ID); err != nil {
+			return domain.Finding{}, err
+		}
+		return domain.Finding{}, domain.NewConflictError("finding is no longer " + string(from))
+	}
+	if err != nil {
+		return domain.Finding{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *FindingRepository) Convert(ctx context.Context, finding domain.Finding, repair domain.MaintenanceTask, dependency domain.TaskDependency) (domain.Finding, error) {
+	if r == nil || r.DB == nil {
+		return domain.Finding{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Finding{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := insertTask(ctx, tx, repair); err != nil {
+		return domain.Finding{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO task_dependencies (id, org_id, task_id, depends_on_task_id, dependency_type, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, dependency.ID, dependency.OrgID, dependency.TaskID, dependency.DependsOnTaskID, dependency.DependencyType, dependency.CreatedAt); err != nil {
+		return domain.Finding{}, TranslateError(err)
+	}
+	row := tx.QueryRow(ctx, `
+		UPDATE findings
+		SET state=$3, repair_task_id=$4, converted_by=$5, converted_at=$6, updated_at=$7
+		WHERE org_id=$1 AND id=$2 AND state='open'
+		RETURNING `+findingColumns,
+		finding.OrgID, finding.ID, finding.State, finding.RepairTaskID, finding.ConvertedBy, finding.ConvertedAt, finding.UpdatedAt)
+	converted, err := scanFinding(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := r.GetByID(ctx, finding.OrgID, finding.ID); err != nil {
+			return domain.Finding{}, err
+		}
+		return domain.Finding{}, domain.NewConflictError("finding is no longer open")
+	}
+	if err != nil {
+		return domain.Finding{}, TranslateError(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Finding{}, err
+	}
+	return converted, nil
+}
+
+func (r *FindingRepository) CountOpen(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) (int, error) {
+	if r == nil || r.DB == nil || len(taskIDs) == 0 {
+		return 0, nil
+	}
+	var count int
+	err := r.DB.QueryRow(ctx, `
+		SELECT count(*) FROM findings
+		WHERE org_id=$1 AND task_id = ANY($2) AND state='open'
+	`, orgID, taskIDs).Scan(&count)
+	return count, err
+}
+
+func scanFinding(row pgx.Row) (domain.Finding, error) {
+	var finding domain.Finding
+	var state string
+	if err := row.Scan(&finding.ID, &finding.OrgID, &finding.TaskID, &finding.AircraftID, &finding.ComplianceItemID,
+		&finding.Description, &finding.Zone, &finding.ATAChapter, &finding.PhotoURLs, &state, &finding.RaisedBy, &finding.RaisedAt,
+		&finding.RepairTaskID, &finding.ConvertedBy, &finding.ConvertedAt, &finding.ClosedBy, &finding.ClosedAt,
+		&finding.ResolutionNotes, &finding.CreatedAt, &finding.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Finding{}, domain.ErrNotFound
+		}
+		return domain.Finding{}, err
+	}
+	finding.State = domain.FindingState(state)
+	return finding, nil
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PlannedFlightRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *PlannedFlightRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.PlannedFlight, error) {
+	if r == nil || r.DB == nil {
+		return domain.PlannedFlight{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, aircraft_id, flight_number, origin, destination, departure_time, arrival_time, status, created_at, updated_at
+		FROM planned_flights
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanPlannedFlight(row)
+}
+
+func (r *PlannedFlightRepository) Upsert(ctx context.Context, flight domain.PlannedFlight) (domain.PlannedFlight, error) {
+	if r == nil || r.DB == nil {
+		return domain.PlannedFlight{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO planned_flights
+			(id, org_id, aircraft_id, flight_number, origin, destination, departure_time, arrival_time, status, created_at, updated_at)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (org_id, aircraft_id, flight_number, departure_time) DO UPDATE
+		SET origin=EXCLUDED.origin, destination=EXCLUDED.destination, arrival_time=EXCLUDED.arrival_time,
+			status=EXCLUDED.status, updated_at=EXCLUDED.updated_at
+		RETURNING id, org_id, aircraft_id, flight_number, origin, destination, departure_time, arrival_time, status, created_at, updated_at
+	`, flight.ID, flight.OrgID, flight.AircraftID, flight.FlightNumber, flight.Origin, flight.Destination, flight.DepartureTime, flight.ArrivalTime, flight.Status, flight.CreatedAt, flight.UpdatedAt)
+	stored, err := scanPlannedFlight(row)
+	if err != nil {
+		return domain.PlannedFlight{}, TranslateError(err)
+	}
+	return stored, nil
+}
+
+func (r *PlannedFlightRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM planned_flights
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PlannedFlightRepository) List(ctx context.Context, filter ports.PlannedFlightFilter) ([]domain.PlannedFlight, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 6)
+	args := make([]any, 0, 8)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.AircraftIDs != nil {
+		args = append(args, filter.AircraftIDs)
+		clauses = append(clauses, "aircraft_id = ANY($"+itoa(len(args))+")")
+	}
+	if filter.Station != "" {
+		args = append(args, strings.ToUpper(filter.Station))
+		clauses = append(clauses, "(origin=$"+itoa(len(args))+" OR destination=$"+itoa(len(args))+")")
+	}
+	if filter.Status != nil {
+		add("status=", *filter.Status)
+	}
+	if filter.From != nil {
+		add("arrival_time > ", *filter.From)
+	}
+	if filter.To != nil {
+		add("departure_time < ", *filter.To)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, aircraft_id, flight_number, origin, destination, departure_time, arrival_time, status, created_at, updated_at
+		FROM planned_flights`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY departure_time ASC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPlannedFlights(rows)
+}
+
+func (r *PlannedFlightRepository) ListPrevious(ctx context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, at time.Time) ([]domain.PlannedFlight, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	query := `
+		SELECT DISTINCT ON (aircraft_id) id, org_id, aircraft_id, flight_number, origin, destination, departure_time, arrival_time, status, created_at, updated_at
+		FROM planned_flights
+		WHERE org_id=$1 AND status='scheduled' AND arrival_time <= $2`
+	args := []any{orgID, at}
+	if aircraftIDs != nil {
+		args = append(args, aircraftIDs)
+		query += " AND aircraft_id = ANY($3)"
+	}
+	query += " ORDER BY aircraft_id, arrival_time DESC"
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPlannedFlights(rows)
+}
+
+func scanPlannedFlights(rows pgx.Rows) ([]domain.PlannedFlight, error) {
+	var flights []domain.PlannedFlight
+	for rows.Next() {
+		flight, err := scanPlannedFlight(rows)
+		if err != nil {
+			return nil, err
+		}
+		flights = append(flights, flight)
+	}
+	return flights, rows.Err()
+}
+
+func scanPlannedFlight(row pgx.Row) (domain.PlannedFlight, error) {
+	var flight domain.PlannedFlight
+	var status string
+	if err := row.Scan(&flight.ID, &flight.OrgID, &flight.AircraftID, &flight.FlightNumber, &flight.Origin, &flight.Destination, &flight.DepartureTime, &flight.ArrivalTime, &status, &flight.CreatedAt, &flight.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.PlannedFlight{}, domain.ErrNotFound
+		}
+		return domain.PlannedFlight{}, err
+	}
+	flight.Status = domain.FlightStatus(status)
+	return flight, nil
+}
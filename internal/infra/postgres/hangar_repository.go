@@ -0,0 +1,402 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StationRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *StationRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.Station, error) {
+	if r == nil || r.DB == nil {
+		return domain.Station{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, code, name, timezone, created_at, updated_at, deleted_at
+		FROM stations
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id)
+	return scanStation(row)
+}
+
+func (r *StationRepository) Create(ctx context.Context, station domain.Station) (domain.Station, error) {
+	if r == nil || r.DB == nil {
+		return domain.Station{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO stations (id, org_id, code, name, timezone, created_at, updated_at, deleted_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id, org_id, code, name, timezone, created_at, updated_at, deleted_at
+	`, station.ID, station.OrgID, station.Code, station.Name, station.Timezone, station.CreatedAt, station.UpdatedAt, station.DeletedAt)
+	created, err := scanStation(row)
+	if err != nil {
+		return domain.Station{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *StationRepository) Update(ctx context.Context, station domain.Station) (domain.Station, error) {
+	if r == nil || r.DB == nil {
+		return domain.Station{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE stations
+		SET name=$1, timezone=$2, updated_at=$3
+		WHERE org_id=$4 AND id=$5 AND deleted_at IS NULL
+		RETURNING id, org_id, code, name, timezone, created_at, updated_at, deleted_at
+	`, station.Name, station.Timezone, station.UpdatedAt, station.OrgID, station.ID)
+	updated, err := scanStation(row)
+	if err != nil {
+		return domain.Station{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+// SoftDelete deletes the station unless it still has bays.
+func (r *StationRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var marker int
+	err = tx.QueryRow(ctx, `
+		SELECT 1 FROM hangar_bays
+		WHERE org_id=$1 AND station_id=$2 AND deleted_at IS NULL
+		LIMIT 1
+	`, orgID, id).Scan(&marker)
+	if err == nil {
+		return domain.NewConflictError("station still has hangar bays")
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+	cmd, err := tx.Exec(ctx, `
+		UPDATE stations
+		SET deleted_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL
+	`, at, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *StationRepository) List(ctx context.Context, filter ports.StationFilter) ([]domain.Station, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 1)
+	args := make([]any, 0, 3)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, code, name, timezone, created_at, updated_at, deleted_at
+		FROM stations
+		WHERE deleted_at IS NULL`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY code ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []domain.Station
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, station)
+	}
+	return stations, rows.Err()
+}
+
+func scanStation(row pgx.Row) (domain.Station, error) {
+	var station domain.Station
+	if err := row.Scan(&station.ID, &station.OrgID, &station.Code, &station.Name, &station.Timezone, &station.CreatedAt, &station.UpdatedAt, &station.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Station{}, domain.ErrNotFound
+		}
+		return domain.Station{}, err
+	}
+	return station, nil
+}
+
+type HangarBayRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *HangarBayRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.HangarBay, error) {
+	if r == nil || r.DB == nil {
+		return domain.HangarBay{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, station_id, code, name, hangar, capacity_slots, created_at, updated_at, deleted_at
+		FROM hangar_bays
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id)
+	return scanHangarBay(row)
+}
+
+func (r *HangarBayRepository) Create(ctx context.Context, bay domain.HangarBay) (domain.HangarBay, error) {
+	if r == nil || r.DB == nil {
+		return domain.HangarBay{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO hangar_bays (id, org_id, station_id, code, name, hangar, capacity_slots, created_at, updated_at, deleted_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		RETURNING id, org_id, station_id, code, name, hangar, capacity_slots, created_at, updated_at, deleted_at
+	`, bay.ID, bay.OrgID, bay.StationID, bay.Code, bay.Name, bay.Hangar, bay.CapacitySlots, bay.CreatedAt, bay.UpdatedAt, bay.DeletedAt)
+	created, err := scanHangarBay(row)
+	if err != nil {
+		return domain.HangarBay{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *HangarBayRepository) Update(ctx context.Context, bay domain.HangarBay) (domain.HangarBay, error) {
+	if r == nil || r.DB == nil {
+		return domain.HangarBay{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE hangar_bays
+		SET name=$1, hangar=$2, capacity_slots=$3, updated_at=$4
+		WHERE org_id=$5 AND id=$6 AND deleted_at IS NULL
+		RETURNING id, org_id, station_id, code, name, hangar, capacity_slots, created_at, updated_at, deleted_at
+	`, bay.Name, bay.Hangar, bay.CapacitySlots, bay.UpdatedAt, bay.OrgID, bay.ID)
+	updated, err := scanHangarBay(row)
+	if err != nil {
+		return domain.HangarBay{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+// SoftDelete deletes the bay unless an open task is still booked into it.
+func (r *HangarBayRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var marker int
+	err = tx.QueryRow(ctx, `
+		SELECT 1 FROM maintenance_tasks
+		WHERE org_id=$1 AND bay_id=$2 AND deleted_at IS NULL AND state IN ('scheduled','in_progress')
+		LIMIT 1
+	`, orgID, id).Scan(&marker)
+	if err == nil {
+		return domain.NewConflictError("hangar bay still has open tasks")
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+	cmd, err := tx.Exec(ctx, `
+		UPDATE hangar_bays
+		SET deleted_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL
+	`, at, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *HangarBayRepository) List(ctx context.Context, filter ports.HangarBayFilter) ([]domain.HangarBay, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.StationID != nil {
+		add("station_id=", *filter.StationID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, station_id, code, name, hangar, capacity_slots, created_at, updated_at, deleted_at
+		FROM hangar_bays
+		WHERE deleted_at IS NULL`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY code ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bays []domain.HangarBay
+	for rows.Next() {
+		bay, err := scanHangarBay(rows)
+		if err != nil {
+			return nil, err
+		}
+		bays = append(bays, bay)
+	}
+	return bays, rows.Err()
+}
+
+func scanHangarBay(row pgx.Row) (domain.HangarBay, error) {
+	var bay domain.HangarBay
+	if err := row.Scan(&bay.ID, &bay.OrgID, &bay.StationID, &bay.Code, &bay.Name, &bay.Hangar, &bay.CapacitySlots, &bay.CreatedAt, &bay.UpdatedAt, &bay.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.HangarBay{}, domain.ErrNotFound
+		}
+		return domain.HangarBay{}, err
+	}
+	return bay, nil
+}
+
+type BayCapacityWindowRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *BayCapacityWindowRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.BayCapacityWindow, error) {
+	if r == nil || r.DB == nil {
+		return domain.BayCapacityWindow{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, bay_id, start_time, end_time, capacity_slots, reason, created_at
+		FROM bay_capacity_windows
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanBayCapacityWindow(row)
+}
+
+func (r *BayCapacityWindowRepository) Create(ctx context.Context, window domain.BayCapacityWindow) (domain.BayCapacityWindow, error) {
+	if r == nil || r.DB == nil {
+		return domain.BayCapacityWindow{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO bay_capacity_windows (id, org_id, bay_id, start_time, end_time, capacity_slots, reason, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id, org_id, bay_id, start_time, end_time, capacity_slots, reason, created_at
+	`, window.ID, window.OrgID, window.BayID, window.StartTime, window.EndTime, window.CapacitySlots, window.Reason, window.CreatedAt)
+	created, err := scanBayCapacityWindow(row)
+	if err != nil {
+		return domain.BayCapacityWindow{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *BayCapacityWindowRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM bay_capacity_windows
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *BayCapacityWindowRepository) ListOverlapping(ctx context.Context, orgID, bayID uuid.UUID, from, to time.Time) ([]domain.BayCapacityWindow, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, bay_id, start_time, end_time, capacity_slots, reason, created_at
+		FROM bay_capacity_windows
+		WHERE org_id=$1 AND bay_id=$2 AND start_time < $4 AND end_time > $3
+		ORDER BY start_time ASC
+	`, orgID, bayID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []domain.BayCapacityWindow
+	for rows.Next() {
+		window, err := scanBayCapacityWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, rows.Err()
+}
+
+func scanBayCapacityWindow(row pgx.Row) (domain.BayCapacityWindow, error) {
+	var window domain.BayCapacityWindow
+	if err := row.Scan(&window.ID, &window.OrgID, &window.BayID, &window.StartTime, &window.EndTime, &window.CapacitySlots, &window.Reason, &window.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.BayCapacityWindow{}, domain.ErrNotFound
+		}
+		return domain.BayCapacityWindow{}, err
+	}
+	return window, nil
+}
@@ -148,12 +148,536 @@ func TestPostgresAircraftAndProgramRepositories(t *testing.T) {
 	if gotProgram.ID != program.ID {
 		t.Fatalf("expected program id %s, got %s", program.ID, gotProgram.ID)
 	}
-	due, err := programRepo.ListDueCalendar(ctx, now, 10)
+	if gotProgram.TaskType != domain.TaskTypeInspection || gotProgram.Priority != domain.PriorityRoutine || gotProgram.EstimatedDurationMinutes != nil || gotProgram.ComplianceChecklist != nil {
+		t.Fatalf("expected default task settings, got %+v", gotProgram)
+	}
+	duration := 180
+	hoursProgram := domain.MaintenanceProgram{
+		ID:                       uuid.New(),
+		OrgID:                    org.ID,
+		AircraftID:               &createdAircraft.ID,
+		Name:                     "Engine borescope",
+		IntervalType:             domain.ProgramIntervalFlightHours,
+		IntervalValue:            500,
+		TaskType:                 domain.TaskTypeRepair,
+		Priority:                 domain.PriorityCritical,
+		EstimatedDurationMinutes: &duration,
+		ComplianceChecklist:      []string{"Borescope HPT stage 1", "Record blade findings"},
+		DefaultNotes:             "Borescope kit required",
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+	if _, err := programRepo.Create(ctx, hoursProgram); err != nil {
+		t.Fatalf("create hours program: %v", err)
+	}
+	gotHours, err := programRepo.GetByID(ctx, org.ID, hoursProgram.ID)
+	if err != nil {
+		t.Fatalf("get hours program: %v", err)
+	}
+	if gotHours.TaskType != domain.TaskTypeRepair || gotHours.Priority != domain.PriorityCritical || gotHours.EstimatedDurationMinutes == nil || *gotHours.EstimatedDurationMinutes != 180 {
+		t.Fatalf("expected task settings to round-trip, got %+v", gotHours)
+	}
+	if len(gotHours.ComplianceChecklist) != 2 || gotHours.ComplianceChecklist[0] != "Borescope HPT stage 1" || gotHours.DefaultNotes != "Borescope kit required" {
+		t.Fatalf("expected checklist and notes to round-trip, got %v %q", gotHours.ComplianceChecklist, gotHours.DefaultNotes)
+	}
+	thresholdProgram := domain.MaintenanceProgram{
+		ID:               uuid.New(),
+		OrgID:            org.ID,
+		AircraftID:       &createdAircraft.ID,
+		Name:             "Gear inspection",
+		IntervalType:     domain.ProgramIntervalFlightHours,
+		IntervalValue:    5000,
+		LastPerformed:    &lastPerformed,
+		TolerancePercent: 10,
+		Thresholds: []domain.ProgramThreshold{
+			{IntervalType: domain.ProgramIntervalCalendar, IntervalValue: 5, TolerancePercent: 20},
+		},
+		CreatedAt: now.Add(time.Second),
+		UpdatedAt: now.Add(time.Second),
+	}
+	if _, err := programRepo.Create(ctx, thresholdProgram); err != nil {
+		t.Fatalf("create threshold program: %v", err)
+	}
+	gotThresholds, err := programRepo.GetByID(ctx, org.ID, thresholdProgram.ID)
+	if err != nil {
+		t.Fatalf("get threshold program: %v", err)
+	}
+	if gotThresholds.TolerancePercent != 10 || len(gotThresholds.Thresholds) != 1 || gotThresholds.Thresholds[0] != thresholdProgram.Thresholds[0] {
+		t.Fatalf("expected thresholds to round-trip, got %d %+v", gotThresholds.TolerancePercent, gotThresholds.Thresholds)
+	}
+	due, err := programRepo.ListDue(ctx, now, domain.ProgramLookAhead{}, 10)
 	if err != nil {
 		t.Fatalf("list due programs: %v", err)
 	}
-	if len(due) == 0 {
-		t.Fatalf("expected due program list to include program")
+	if len(due) != 2 || due[0].ID != program.ID || due[1].ID != thresholdProgram.ID {
+		t.Fatalf("expected the calendar and threshold programs to be due, got %d programs", len(due))
+	}
+	due, err = programRepo.ListDue(ctx, now, domain.ProgramLookAhead{FlightHours: 500}, 10)
+	if err != nil {
+		t.Fatalf("list due programs with look-ahead: %v", err)
+	}
+	if len(due) != 3 {
+		t.Fatalf("expected hours program within look-ahead, got %d programs", len(due))
+	}
+
+	// A program with an open task no longer takes a place in the page.
+	taskRepo := &TaskRepository{DB: pool}
+	if _, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: createdAircraft.ID,
+		ProgramID:  &program.ID,
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}); err != nil {
+		t.Fatalf("create program task: %v", err)
+	}
+	due, err = programRepo.ListDue(ctx, now, domain.ProgramLookAhead{}, 1)
+	if err != nil {
+		t.Fatalf("list due programs with an open task: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != thresholdProgram.ID {
+		t.Fatalf("expected only the threshold program without an open task, got %d programs", len(due))
+	}
+}
+
+func TestPostgresProgramTemplateRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	programRepo := &MaintenanceProgramRepository{DB: pool}
+	templateRepo := &MaintenanceProgramTemplateRepository{DB: pool}
+	now := time.Now().UTC()
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Name:      "Ops",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	var aircraftTypeID uuid.UUID
+	if err := pool.QueryRow(ctx, "SELECT id FROM aircraft_types ORDER BY icao_code LIMIT 1").Scan(&aircraftTypeID); err != nil {
+		t.Fatalf("select aircraft type: %v", err)
+	}
+
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		TailNumber:     "N789AM",
+		Model:          "A320",
+		AircraftTypeID: &aircraftTypeID,
+		Status:         domain.AircraftOperational,
+		CapacitySlots:  2,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	typed, err := aircraftRepo.List(ctx, ports.AircraftFilter{OrgID: &org.ID, AircraftTypeID: &aircraftTypeID})
+	if err != nil {
+		t.Fatalf("list aircraft by type: %v", err)
+	}
+	if len(typed) != 1 || typed[0].AircraftTypeID == nil || *typed[0].AircraftTypeID != aircraftTypeID {
+		t.Fatalf("expected aircraft type to round-trip, got %d aircraft", len(typed))
+	}
+
+	template, err := templateRepo.Create(ctx, domain.MaintenanceProgramTemplate{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		AircraftTypeID: aircraftTypeID,
+		Name:           "A-Check",
+		IntervalType:   domain.ProgramIntervalFlightHours,
+		IntervalValue:  600,
+		Thresholds: []domain.ProgramThreshold{
+			{IntervalType: domain.ProgramIntervalCalendar, IntervalValue: 120},
+		},
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if _, err := templateRepo.Create(ctx, domain.MaintenanceProgramTemplate{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		AircraftTypeID: aircraftTypeID,
+		Name:           "A-Check",
+		IntervalType:   domain.ProgramIntervalCycles,
+		IntervalValue:  400,
+		Revision:       1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected duplicate template name conflict, got %v", err)
+	}
+
+	template.IntervalValue = 750
+	template.Revision = 2
+	template.UpdatedAt = now.Add(time.Minute)
+	updated, err := templateRepo.Update(ctx, template)
+	if err != nil {
+		t.Fatalf("update template: %v", err)
+	}
+	if updated.Revision != 2 || updated.IntervalValue != 750 || len(updated.Thresholds) != 1 {
+		t.Fatalf("expected updated template, got revision %d interval %d", updated.Revision, updated.IntervalValue)
+	}
+	templates, err := templateRepo.List(ctx, ports.MaintenanceProgramTemplateFilter{OrgID: &org.ID, AircraftTypeID: &aircraftTypeID})
+	if err != nil {
+		t.Fatalf("list templates: %v", err)
+	}
+	if len(templates) != 1 {
+		t.Fatalf("expected 1 template, got %d", len(templates))
+	}
+
+	instance := updated.Instantiate(aircraft.ID, now)
+	if _, err := programRepo.Create(ctx, instance); err != nil {
+		t.Fatalf("create instance: %v", err)
+	}
+	duplicate := updated.Instantiate(aircraft.ID, now)
+	duplicate.Name = "A-Check copy"
+	if _, err := programRepo.Create(ctx, duplicate); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected duplicate instance conflict, got %v", err)
+	}
+	instances, err := programRepo.List(ctx, ports.MaintenanceProgramFilter{OrgID: &org.ID, TemplateID: &template.ID})
+	if err != nil {
+		t.Fatalf("list instances: %v", err)
+	}
+	if len(instances) != 1 || instances[0].TemplateRevision == nil || *instances[0].TemplateRevision != 2 {
+		t.Fatalf("expected 1 instance at revision 2, got %d", len(instances))
+	}
+
+	stale := updated
+	stale.Revision = 1
+	if err := templateRepo.Propagate(ctx, stale, nil, instances); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected stale revision conflict, got %v", err)
+	}
+	renamed := updated.ApplyTo(instances[0])
+	renamed.Name = "A-Check renamed"
+	if err := templateRepo.Propagate(ctx, updated, []domain.MaintenanceProgram{duplicate}, []domain.MaintenanceProgram{renamed}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected duplicate instance conflict, got %v", err)
+	}
+	unchanged, err := programRepo.GetByID(ctx, org.ID, instances[0].ID)
+	if err != nil {
+		t.Fatalf("get instance: %v", err)
+	}
+	if unchanged.Name != instances[0].Name {
+		t.Fatalf("expected failed propagation to roll back, got name %q", unchanged.Name)
+	}
+	if err := templateRepo.Propagate(ctx, updated, nil, []domain.MaintenanceProgram{renamed}); err != nil {
+		t.Fatalf("propagate template: %v", err)
+	}
+	propagated, err := programRepo.GetByID(ctx, org.ID, instances[0].ID)
+	if err != nil {
+		t.Fatalf("get propagated instance: %v", err)
+	}
+	if propagated.Name != renamed.Name {
+		t.Fatalf("expected propagated name %q, got %q", renamed.Name, propagated.Name)
+	}
+
+	bCheck := domain.MaintenanceProgramTemplate{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		AircraftTypeID: aircraftTypeID,
+		Name:           "B-Check",
+		IntervalType:   domain.ProgramIntervalCalendar,
+		IntervalValue:  180,
+		Revision:       1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if _, err := templateRepo.CreateWithInstances(ctx, bCheck, []domain.MaintenanceProgram{
+		bCheck.Instantiate(aircraft.ID, now),
+		bCheck.Instantiate(aircraft.ID, now),
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected duplicate instance conflict, got %v", err)
+	}
+	if _, err := templateRepo.GetByID(ctx, org.ID, bCheck.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected failed template create to roll back, got %v", err)
+	}
+	if _, err := templateRepo.CreateWithInstances(ctx, bCheck, []domain.MaintenanceProgram{bCheck.Instantiate(aircraft.ID, now)}); err != nil {
+		t.Fatalf("create template with instances: %v", err)
+	}
+	bInstances, err := programRepo.List(ctx, ports.MaintenanceProgramFilter{OrgID: &org.ID, TemplateID: &bCheck.ID})
+	if err != nil {
+		t.Fatalf("list template instances: %v", err)
+	}
+	if len(bInstances) != 1 {
+		t.Fatalf("expected 1 instance created with the template, got %d", len(bInstances))
+	}
+
+	second := domain.Aircraft{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		TailNumber:     "N790AM",
+		Model:          "A320",
+		AircraftTypeID: &aircraftTypeID,
+		Status:         domain.AircraftOperational,
+		CapacitySlots:  1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if _, err := aircraftRepo.CreateWithPrograms(ctx, second, []domain.MaintenanceProgram{
+		bCheck.Instantiate(second.ID, now),
+		bCheck.Instantiate(second.ID, now),
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected duplicate instance conflict, got %v", err)
+	}
+	if _, err := aircraftRepo.GetByTailNumber(ctx, org.ID, second.TailNumber); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected failed aircraft create to roll back, got %v", err)
+	}
+	if _, err := aircraftRepo.CreateWithPrograms(ctx, second, []domain.MaintenanceProgram{bCheck.Instantiate(second.ID, now)}); err != nil {
+		t.Fatalf("create aircraft with programs: %v", err)
+	}
+	secondPrograms, err := programRepo.List(ctx, ports.MaintenanceProgramFilter{OrgID: &org.ID, AircraftID: &second.ID})
+	if err != nil {
+		t.Fatalf("list aircraft programs: %v", err)
+	}
+	if len(secondPrograms) != 1 {
+		t.Fatalf("expected 1 program created with the aircraft, got %d", len(secondPrograms))
+	}
+
+	if err := templateRepo.SoftDelete(ctx, org.ID, template.ID, now.Add(time.Hour)); err != nil {
+		t.Fatalf("delete template: %v", err)
+	}
+	if _, err := templateRepo.GetByID(ctx, org.ID, template.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected deleted template to be missing, got %v", err)
+	}
+}
+
+func TestPostgresAircraftUtilizationRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	utilizationRepo := &AircraftUtilizationRepository{DB: pool}
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Name:      "Ops",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            org.ID,
+		TailNumber:       "N456AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+
+	entry := domain.AircraftUtilization{
+		ID:              uuid.New(),
+		OrgID:           org.ID,
+		AircraftID:      aircraft.ID,
+		EntryType:       domain.UtilizationEntryFlight,
+		UtilizationDate: day.Add(-24 * time.Hour),
+		FlightNumber:    "AM101",
+		BlockHours:      2.5,
+		Cycles:          1,
+		HoursTotal:      1002.5,
+		CyclesTotal:     401,
+		CreatedAt:       now,
+	}
+	if _, err := utilizationRepo.Create(ctx, entry); err != nil {
+		t.Fatalf("create utilization: %v", err)
+	}
+	updated, err := aircraftRepo.GetByID(ctx, org.ID, aircraft.ID)
+	if err != nil {
+		t.Fatalf("get aircraft: %v", err)
+	}
+	if updated.FlightHoursTotal != 1002 || updated.CyclesTotal != 401 {
+		t.Fatalf("expected aircraft totals 1002/401, got %d/%d", updated.FlightHoursTotal, updated.CyclesTotal)
+	}
+	stale := updated
+	stale.FlightHoursTotal = 1000
+	stale.CyclesTotal = 400
+	stale.Model = "A320neo"
+	renamed, err := aircraftRepo.Update(ctx, stale)
+	if err != nil {
+		t.Fatalf("update aircraft: %v", err)
+	}
+	if renamed.Model != "A320neo" || renamed.FlightHoursTotal != 1002 || renamed.CyclesTotal != 401 {
+		t.Fatalf("expected update to keep totals 1002/401, got %+v", renamed)
+	}
+	latest, err := utilizationRepo.GetLatest(ctx, org.ID, aircraft.ID)
+	if err != nil {
+		t.Fatalf("get latest utilization: %v", err)
+	}
+	if latest.ID != entry.ID || latest.HoursTotal != 1002.5 || latest.FlightNumber != "AM101" {
+		t.Fatalf("unexpected latest utilization %+v", latest)
+	}
+
+	decreasing := entry
+	decreasing.ID = uuid.New()
+	decreasing.UtilizationDate = day
+	decreasing.HoursTotal = 1001
+	if _, err := utilizationRepo.Create(ctx, decreasing); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for decreasing totals, got %v", err)
+	}
+	backdated := entry
+	backdated.ID = uuid.New()
+	backdated.UtilizationDate = day.Add(-48 * time.Hour)
+	backdated.HoursTotal = 1004
+	backdated.CyclesTotal = 402
+	if _, err := utilizationRepo.Create(ctx, backdated); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for backdated entry, got %v", err)
+	}
+
+	items, err := utilizationRepo.List(ctx, ports.AircraftUtilizationFilter{OrgID: &org.ID, AircraftID: &aircraft.ID})
+	if err != nil {
+		t.Fatalf("list utilization: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 utilization entry, got %d", len(items))
+	}
+}
+
+func TestPostgresAircraftUtilizationRepositoryConcurrentEntries(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	defRepo := &PartDefinitionRepository{DB: pool}
+	itemRepo := &PartItemRepository{DB: pool}
+	utilizationRepo := &AircraftUtilizationRepository{DB: pool}
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Name:      "Ops",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            org.ID,
+		TailNumber:       "N457AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	def, err := defRepo.Create(ctx, domain.PartDefinition{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		Name:      "APU",
+		Category:  "Power",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("create part definition: %v", err)
+	}
+	part, err := itemRepo.Create(ctx, domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		DefinitionID: def.ID,
+		SerialNumber: "APU-001",
+		Status:       domain.PartItemInStock,
+		AircraftID:   &aircraft.ID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		t.Fatalf("create part item: %v", err)
+	}
+
+	// Both flights are derived from the same previous totals, as two
+	// requests arriving at once would be.
+	entries := make([]domain.AircraftUtilization, 0, 2)
+	for i, hours := range []float64{2.5, 3} {
+		entries = append(entries, domain.AircraftUtilization{
+			ID:              uuid.New(),
+			OrgID:           org.ID,
+			AircraftID:      aircraft.ID,
+			EntryType:       domain.UtilizationEntryFlight,
+			UtilizationDate: day,
+			FlightNumber:    fmt.Sprintf("AM10%d", i+1),
+			BlockHours:      hours,
+			Cycles:          1,
+			HoursTotal:      1000 + hours,
+			CyclesTotal:     401,
+			CreatedAt:       now,
+		})
+	}
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i := range entries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = utilizationRepo.Create(ctx, entries[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var stored *domain.AircraftUtilization
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if stored != nil {
+				t.Fatalf("expected only one entry derived from the same totals to be stored")
+			}
+			stored = &entries[i]
+		case !errors.Is(err, domain.ErrConflict):
+			t.Fatalf("expected conflict for the stale entry, got %v", err)
+		}
+	}
+	if stored == nil {
+		t.Fatalf("expected one entry to be stored, got %v", errs)
+	}
+	updated, err := aircraftRepo.GetByID(ctx, org.ID, aircraft.ID)
+	if err != nil {
+		t.Fatalf("get aircraft: %v", err)
+	}
+	if updated.FlightHoursTotal != int(stored.HoursTotal) || updated.CyclesTotal != 401 {
+		t.Fatalf("expected aircraft totals %v/401, got %d/%d", stored.HoursTotal, updated.FlightHoursTotal, updated.CyclesTotal)
+	}
+	accrued, err := itemRepo.GetByID(ctx, org.ID, part.ID)
+	if err != nil {
+		t.Fatalf("get part item: %v", err)
+	}
+	if accrued.HoursSinceNew != stored.BlockHours || accrued.CyclesSinceNew != 1 {
+		t.Fatalf("expected part to accrue only the stored entry, got %v/%d", accrued.HoursSinceNew, accrued.CyclesSinceNew)
 	}
 }
 
@@ -250,14 +774,16 @@ func TestPostgresTaskRepository(t *testing.T) {
 	}
 
 	aircraft := domain.Aircraft{
-		ID:            uuid.New(),
-		OrgID:         org.ID,
-		TailNumber:    "N400TS",
-		Model:         "A321",
-		Status:        domain.AircraftGrounded,
-		CapacitySlots: 2,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+		ID:               uuid.New(),
+		OrgID:            org.ID,
+		TailNumber:       "N400TS",
+		Model:            "A321",
+		Status:           domain.AircraftGrounded,
+		CapacitySlots:    2,
+		FlightHoursTotal: 5120,
+		CyclesTotal:      3410,
+		CreatedAt:        now,
+		UpdatedAt:        now,
 	}
 	if _, err := aircraftRepo.Create(ctx, aircraft); err != nil {
 		t.Fatalf("create aircraft: %v", err)
@@ -306,6 +832,9 @@ func TestPostgresTaskRepository(t *testing.T) {
 	if got.Notes != task.Notes {
 		t.Fatalf("expected notes %q, got %q", task.Notes, got.Notes)
 	}
+	if got.Priority != domain.PriorityRoutine {
+		t.Fatalf("expected default priority routine, got %s", got.Priority)
+	}
 
 	listed, err := taskRepo.List(ctx, ports.TaskFilter{OrgID: &org.ID, AircraftID: &aircraft.ID})
 	if err != nil {
@@ -316,11 +845,15 @@ func TestPostgresTaskRepository(t *testing.T) {
 	}
 
 	task.Notes = "updated task"
+	task.Priority = domain.PriorityUrgent
 	task.UpdatedAt = now.Add(2 * time.Minute)
 	updated, err := taskRepo.Update(ctx, task)
 	if err != nil {
 		t.Fatalf("update task: %v", err)
 	}
+	if updated.Priority != domain.PriorityUrgent {
+		t.Fatalf("expected priority urgent, got %s", updated.Priority)
+	}
 	if updated.Notes != "updated task" {
 		t.Fatalf("expected updated notes, got %q", updated.Notes)
 	}
@@ -341,6 +874,21 @@ func TestPostgresTaskRepository(t *testing.T) {
 		t.Fatalf("expected active task for program")
 	}
 
+	completedAt := now.Add(3*time.Minute + 30*time.Second)
+	if _, err := taskRepo.UpdateState(ctx, org.ID, task.ID, domain.TaskStateCompleted, "done", completedAt); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	performed, err := programRepo.GetByID(ctx, org.ID, program.ID)
+	if err != nil {
+		t.Fatalf("get program: %v", err)
+	}
+	if performed.LastPerformed == nil || !performed.LastPerformed.Equal(completedAt.Truncate(time.Microsecond)) {
+		t.Fatalf("expected last performed %s, got %v", completedAt, performed.LastPerformed)
+	}
+	if performed.LastPerformedHours == nil || *performed.LastPerformedHours != aircraft.FlightHoursTotal || performed.LastPerformedCycles == nil || *performed.LastPerformedCycles != aircraft.CyclesTotal {
+		t.Fatalf("expected the aircraft totals recorded on the program, got %+v", performed)
+	}
+
 	if err := taskRepo.SoftDelete(ctx, org.ID, task.ID, now.Add(4*time.Minute)); err != nil {
 		t.Fatalf("soft delete: %v", err)
 	}
@@ -357,7 +905,7 @@ func TestPostgresTaskRepository(t *testing.T) {
 	}
 }
 
-func TestPostgresPartRepositories(t *testing.T) {
+func TestPostgresWorkPackageRepository(t *testing.T) {
 	pool, cleanup := setupTestDB(t)
 	defer cleanup()
 	ctx := context.Background()
@@ -365,14 +913,12 @@ func TestPostgresPartRepositories(t *testing.T) {
 	orgRepo := &OrganizationRepository{DB: pool}
 	aircraftRepo := &AircraftRepository{DB: pool}
 	taskRepo := &TaskRepository{DB: pool}
-	defRepo := &PartDefinitionRepository{DB: pool}
-	itemRepo := &PartItemRepository{DB: pool}
-	reservationRepo := &PartReservationRepository{DB: pool}
-	now := time.Now().UTC()
+	packageRepo := &WorkPackageRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Microsecond)
 
 	org := domain.Organization{
 		ID:        uuid.New(),
-		Name:      "Parts Ops",
+		Name:      "Hangar Ops",
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -383,10 +929,10 @@ func TestPostgresPartRepositories(t *testing.T) {
 	aircraft := domain.Aircraft{
 		ID:            uuid.New(),
 		OrgID:         org.ID,
-		TailNumber:    "N100PT",
-		Model:         "737",
-		Status:        domain.AircraftGrounded,
-		CapacitySlots: 1,
+		TailNumber:    "N410WP",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 2,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -394,49 +940,184 @@ func TestPostgresPartRepositories(t *testing.T) {
 		t.Fatalf("create aircraft: %v", err)
 	}
 
-	task := domain.MaintenanceTask{
+	visitStart := now.Add(24 * time.Hour)
+	pkg, err := packageRepo.Create(ctx, domain.WorkPackage{
 		ID:         uuid.New(),
 		OrgID:      org.ID,
 		AircraftID: aircraft.ID,
-		Type:       domain.TaskTypeRepair,
-		State:      domain.TaskStateScheduled,
-		StartTime:  now.Add(1 * time.Hour),
-		EndTime:    now.Add(3 * time.Hour),
+		Name:       "A-Check",
+		State:      domain.WorkPackagePlanned,
+		VisitStart: visitStart,
+		VisitEnd:   visitStart.Add(12 * time.Hour),
 		CreatedAt:  now,
 		UpdatedAt:  now,
-	}
-	if _, err := taskRepo.Create(ctx, task); err != nil {
-		t.Fatalf("create task: %v", err)
+	})
+	if err != nil {
+		t.Fatalf("create work package: %v", err)
 	}
 
-	def := domain.PartDefinition{
-		ID:        uuid.New(),
-		OrgID:     org.ID,
-		Name:      "Hydraulic Pump",
-		Category:  "Hydraulics",
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	createdDef, err := defRepo.Create(ctx, def)
+	pkg.Notes = "Night shift"
+	pkg.VisitEnd = visitStart.Add(16 * time.Hour)
+	pkg.UpdatedAt = now.Add(time.Minute)
+	updated, err := packageRepo.Update(ctx, pkg)
 	if err != nil {
-		t.Fatalf("create part definition: %v", err)
+		t.Fatalf("update work package: %v", err)
 	}
-	if createdDef.ID != def.ID {
-		t.Fatalf("expected definition id %s, got %s", def.ID, createdDef.ID)
+	if updated.Notes != "Night shift" || !updated.VisitEnd.Equal(pkg.VisitEnd) {
+		t.Fatalf("expected update to persist, got %+v", updated)
 	}
 
-	expiry := now.Add(30 * 24 * time.Hour)
-	item := domain.PartItem{
-		ID:           uuid.New(),
-		OrgID:        org.ID,
-		DefinitionID: def.ID,
-		SerialNumber: "SN-100",
-		Status:       domain.PartItemInStock,
-		ExpiryDate:   &expiry,
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-	createdItem, err := itemRepo.Create(ctx, item)
+	_, err = packageRepo.Create(ctx, domain.WorkPackage{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Name:       "Inverted",
+		State:      domain.WorkPackagePlanned,
+		VisitStart: visitStart,
+		VisitEnd:   visitStart.Add(-time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err == nil {
+		t.Fatalf("expected inverted visit window to be rejected")
+	}
+
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		AircraftID:    aircraft.ID,
+		WorkPackageID: &pkg.ID,
+		Type:          domain.TaskTypeInspection,
+		State:         domain.TaskStateScheduled,
+		StartTime:     visitStart,
+		EndTime:       visitStart.Add(2 * time.Hour),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	tasks, err := taskRepo.List(ctx, ports.TaskFilter{OrgID: &org.ID, WorkPackageID: &pkg.ID})
+	if err != nil {
+		t.Fatalf("list tasks by work package: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected task in work package, got %d", len(tasks))
+	}
+
+	started, err := packageRepo.UpdateState(ctx, org.ID, pkg.ID, domain.WorkPackageInProgress, now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("update work package state: %v", err)
+	}
+	if started.State != domain.WorkPackageInProgress {
+		t.Fatalf("expected in_progress, got %s", started.State)
+	}
+
+	state := domain.WorkPackageInProgress
+	from := visitStart.Add(time.Hour)
+	listed, err := packageRepo.List(ctx, ports.WorkPackageFilter{OrgID: &org.ID, AircraftID: &aircraft.ID, State: &state, VisitFrom: &from})
+	if err != nil {
+		t.Fatalf("list work packages: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != pkg.ID {
+		t.Fatalf("expected one work package, got %d", len(listed))
+	}
+
+	if err := packageRepo.SoftDelete(ctx, org.ID, pkg.ID, now.Add(3*time.Minute)); err != nil {
+		t.Fatalf("soft delete work package: %v", err)
+	}
+	if _, err := packageRepo.GetByID(ctx, org.ID, pkg.ID); err != domain.ErrNotFound {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+	detached, err := taskRepo.GetByID(ctx, org.ID, task.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if detached.WorkPackageID != nil {
+		t.Fatalf("expected task to be detached from deleted work package")
+	}
+}
+
+func TestPostgresPartRepositories(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	defRepo := &PartDefinitionRepository{DB: pool}
+	itemRepo := &PartItemRepository{DB: pool}
+	reservationRepo := &PartReservationRepository{DB: pool}
+	now := time.Now().UTC()
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Name:      "Parts Ops",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+
+	aircraft := domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N100PT",
+		Model:         "737",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := aircraftRepo.Create(ctx, aircraft); err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+
+	task := domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(1 * time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := taskRepo.Create(ctx, task); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	def := domain.PartDefinition{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		Name:      "Hydraulic Pump",
+		Category:  "Hydraulics",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	createdDef, err := defRepo.Create(ctx, def)
+	if err != nil {
+		t.Fatalf("create part definition: %v", err)
+	}
+	if createdDef.ID != def.ID {
+		t.Fatalf("expected definition id %s, got %s", def.ID, createdDef.ID)
+	}
+
+	expiry := now.Add(30 * 24 * time.Hour)
+	item := domain.PartItem{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		DefinitionID: def.ID,
+		SerialNumber: "SN-100",
+		Status:       domain.PartItemInStock,
+		ExpiryDate:   &expiry,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	createdItem, err := itemRepo.Create(ctx, item)
 	if err != nil {
 		t.Fatalf("create part item: %v", err)
 	}
@@ -473,18 +1154,48 @@ func TestPostgresPartRepositories(t *testing.T) {
 		t.Fatalf("expected status %s, got %s", domain.PartItemUsed, updatedItem.Status)
 	}
 
+	cyclesLimit := 20000
 	item2 := domain.PartItem{
-		ID:           uuid.New(),
-		OrgID:        org.ID,
-		DefinitionID: def.ID,
-		SerialNumber: "SN-200",
-		Status:       domain.PartItemInStock,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		ID:              uuid.New(),
+		OrgID:           org.ID,
+		DefinitionID:    def.ID,
+		SerialNumber:    "SN-200",
+		Status:          domain.PartItemInStock,
+		AircraftID:      &aircraft.ID,
+		LifeLimitCycles: &cyclesLimit,
+		HoursSinceNew:   1200.5,
+		CyclesSinceNew:  900,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 	if _, err := itemRepo.Create(ctx, item2); err != nil {
 		t.Fatalf("create part item 2: %v", err)
 	}
+	utilizationRepo := &AircraftUtilizationRepository{DB: pool}
+	if _, err := utilizationRepo.Create(ctx, domain.AircraftUtilization{
+		ID:              uuid.New(),
+		OrgID:           org.ID,
+		AircraftID:      aircraft.ID,
+		EntryType:       domain.UtilizationEntryDaily,
+		UtilizationDate: now.Truncate(24 * time.Hour),
+		BlockHours:      2.25,
+		Cycles:          2,
+		HoursTotal:      2.25,
+		CyclesTotal:     2,
+		CreatedAt:       now.Add(2 * time.Minute),
+	}); err != nil {
+		t.Fatalf("record utilization: %v", err)
+	}
+	lifeLimited, err := itemRepo.List(ctx, ports.PartItemFilter{OrgID: &org.ID, AircraftID: &aircraft.ID, LifeLimited: true})
+	if err != nil {
+		t.Fatalf("list life-limited parts: %v", err)
+	}
+	if len(lifeLimited) != 1 || lifeLimited[0].ID != item2.ID {
+		t.Fatalf("expected life-limited part %s, got %+v", item2.ID, lifeLimited)
+	}
+	if got := lifeLimited[0]; got.HoursSinceNew != 1202.75 || got.CyclesSinceNew != 902 || got.HoursSinceOverhaul != 2.25 || got.CyclesSinceOverhaul != 2 {
+		t.Fatalf("expected accrued usage, got %+v", got)
+	}
 
 	reservation := domain.PartReservation{
 		ID:         uuid.New(),
@@ -682,6 +1393,49 @@ func TestPostgresComplianceRepository(t *testing.T) {
 	if signed.SignOffTime == nil || signed.SignOffTime.UTC().Truncate(time.Microsecond) != signAt.UTC().Truncate(time.Microsecond) {
 		t.Fatalf("expected sign off time %v", signAt)
 	}
+
+	checklistTask := task
+	checklistTask.ID = uuid.New()
+	checklistTask.StartTime = now.Add(3 * time.Hour)
+	checklistTask.EndTime = now.Add(4 * time.Hour)
+	checklistItem := func(taskID uuid.UUID, description string) domain.ComplianceItem {
+		return domain.ComplianceItem{
+			ID:          uuid.New(),
+			OrgID:       org.ID,
+			TaskID:      taskID,
+			Description: description,
+			Result:      domain.CompliancePending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+	if _, err := taskRepo.CreateWithCompliance(ctx, checklistTask, []domain.ComplianceItem{
+		checklistItem(checklistTask.ID, "Inspect seals"),
+		checklistItem(checklistTask.ID, "Record torque"),
+	}); err != nil {
+		t.Fatalf("create task with checklist: %v", err)
+	}
+	byTask, err = complianceRepo.ListByTask(ctx, org.ID, checklistTask.ID)
+	if err != nil {
+		t.Fatalf("list checklist: %v", err)
+	}
+	if len(byTask) != 2 {
+		t.Fatalf("expected 2 checklist items, got %d", len(byTask))
+	}
+
+	// A failing checklist item leaves no task behind.
+	failedTask := task
+	failedTask.ID = uuid.New()
+	failedTask.StartTime = now.Add(5 * time.Hour)
+	failedTask.EndTime = now.Add(6 * time.Hour)
+	duplicate := checklistItem(failedTask.ID, "Duplicate")
+	duplicate.ID = item.ID
+	if _, err := taskRepo.CreateWithCompliance(ctx, failedTask, []domain.ComplianceItem{duplicate}); err == nil {
+		t.Fatalf("expected duplicate checklist item to fail")
+	}
+	if _, err := taskRepo.GetByID(ctx, org.ID, failedTask.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected task to roll back with its checklist, got %v", err)
+	}
 }
 
 func TestPostgresImportRepository(t *testing.T) {
@@ -1792,3 +2546,1452 @@ func findMigrationsDir(t *testing.T) string {
 	t.Fatalf("migrations directory not found")
 	return ""
 }
+
+func TestPostgresComponentChangeRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	defRepo := &PartDefinitionRepository{DB: pool}
+	itemRepo := &PartItemRepository{DB: pool}
+	changeRepo := &ComponentChangeRepository{DB: pool}
+	now := time.Now().UTC()
+
+	org := domain.Organization{ID: uuid.New(), Name: "Component Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft := domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N200CC",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := aircraftRepo.Create(ctx, aircraft); err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	def := domain.PartDefinition{ID: uuid.New(), OrgID: org.ID, Name: "Main Gear", Category: "Landing Gear", CreatedAt: now, UpdatedAt: now}
+	if _, err := defRepo.Create(ctx, def); err != nil {
+		t.Fatalf("create part definition: %v", err)
+	}
+	gear := domain.PartItem{ID: uuid.New(), OrgID: org.ID, DefinitionID: def.ID, SerialNumber: "MLG-1", Status: domain.PartItemInStock, CyclesSinceNew: 1500, CreatedAt: now, UpdatedAt: now}
+	other := domain.PartItem{ID: uuid.New(), OrgID: org.ID, DefinitionID: def.ID, SerialNumber: "MLG-2", Status: domain.PartItemInStock, CreatedAt: now, UpdatedAt: now}
+	for _, item := range []domain.PartItem{gear, other} {
+		if _, err := itemRepo.Create(ctx, item); err != nil {
+			t.Fatalf("create part item: %v", err)
+		}
+	}
+
+	install := domain.ComponentChange{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		PartItemID:     gear.ID,
+		AircraftID:     aircraft.ID,
+		Action:         domain.ComponentInstall,
+		Position:       "MLG-LH",
+		Reason:         domain.ComponentChangeScheduled,
+		AircraftHours:  1234.5,
+		AircraftCycles: 800,
+		PerformedAt:    now,
+		CreatedAt:      now,
+	}
+	recorded, err := changeRepo.Record(ctx, install)
+	if err != nil {
+		t.Fatalf("record install: %v", err)
+	}
+	if recorded.PartCyclesSinceNew != 1500 {
+		t.Fatalf("expected part cycles 1500 captured, got %d", recorded.PartCyclesSinceNew)
+	}
+	fitted, err := itemRepo.GetByID(ctx, org.ID, gear.ID)
+	if err != nil {
+		t.Fatalf("get fitted part: %v", err)
+	}
+	if fitted.AircraftID == nil || *fitted.AircraftID != aircraft.ID || fitted.Position != "MLG-LH" || fitted.Status != domain.PartItemUsed {
+		t.Fatalf("expected part fitted at MLG-LH, got %+v", fitted)
+	}
+
+	occupied := install
+	occupied.ID = uuid.New()
+	occupied.PartItemID = other.ID
+	if _, err := changeRepo.Record(ctx, occupied); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for occupied position, got %v", err)
+	}
+
+	removal := install
+	removal.ID = uuid.New()
+	removal.Action = domain.ComponentRemove
+	removal.Reason = domain.ComponentChangeUnscheduled
+	removal.PerformedAt = now.Add(time.Hour)
+	removal.CreatedAt = now.Add(time.Hour)
+	if _, err := changeRepo.Record(ctx, removal); err != nil {
+		t.Fatalf("record removal: %v", err)
+	}
+	if _, err := changeRepo.Record(ctx, removal); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict removing a part that is not fitted, got %v", err)
+	}
+
+	history, err := changeRepo.List(ctx, ports.ComponentChangeFilter{OrgID: &org.ID, PartItemID: &gear.ID})
+	if err != nil {
+		t.Fatalf("list component changes: %v", err)
+	}
+	if len(history) != 2 || history[0].Action != domain.ComponentInstall || history[1].Action != domain.ComponentRemove {
+		t.Fatalf("expected install then removal, got %+v", history)
+	}
+}
+
+func TestPostgresHangarBaySlotConflict(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	stationRepo := &StationRepository{DB: pool}
+	bayRepo := &HangarBayRepository{DB: pool}
+	windowRepo := &BayCapacityWindowRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Hangar Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	var aircraft []domain.Aircraft
+	for _, tail := range []string{"N100HB", "N200HB", "N300HB"} {
+		created, err := aircraftRepo.Create(ctx, domain.Aircraft{
+			ID:            uuid.New(),
+			OrgID:         org.ID,
+			TailNumber:    tail,
+			Model:         "A320",
+			Status:        domain.AircraftOperational,
+			CapacitySlots: 2,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			t.Fatalf("create aircraft %s: %v", tail, err)
+		}
+		aircraft = append(aircraft, created)
+	}
+
+	station, err := stationRepo.Create(ctx, domain.Station{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		Code:      "HAM",
+		Name:      "Hamburg",
+		Timezone:  "Europe/Berlin",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("create station: %v", err)
+	}
+	bay, err := bayRepo.Create(ctx, domain.HangarBay{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		StationID:     station.ID,
+		Code:          "B1",
+		Name:          "Bay 1",
+		CapacitySlots: 2,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create bay: %v", err)
+	}
+
+	slot := 1
+	task1 := domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft[0].ID,
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(1 * time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		BayID:      &bay.ID,
+		BaySlot:    &slot,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := taskRepo.Create(ctx, task1); err != nil {
+		t.Fatalf("create task1: %v", err)
+	}
+
+	task2 := task1
+	task2.ID = uuid.New()
+	task2.AircraftID = aircraft[1].ID
+	task2.StartTime = now.Add(2 * time.Hour)
+	task2.EndTime = now.Add(4 * time.Hour)
+	var capacityConflict *domain.CapacityConflictError
+	if _, err := taskRepo.Create(ctx, task2); !errors.As(err, &capacityConflict) || capacityConflict.Kind != domain.CapacityConflictBayFull {
+		t.Fatalf("expected bay capacity conflict, got %v", err)
+	}
+
+	otherSlot := 2
+	task2.BaySlot = &otherSlot
+	if _, err := taskRepo.Create(ctx, task2); err != nil {
+		t.Fatalf("create task in second slot: %v", err)
+	}
+
+	overlap := task1
+	overlap.ID = uuid.New()
+	overlap.BayID = nil
+	overlap.BaySlot = nil
+	if _, err := taskRepo.Create(ctx, overlap); !errors.As(err, &capacityConflict) || capacityConflict.Kind != domain.CapacityConflictAircraftOverlap {
+		t.Fatalf("expected aircraft overlap conflict, got %v", err)
+	}
+
+	booked, err := taskRepo.List(ctx, ports.TaskFilter{OrgID: &org.ID, BayID: &bay.ID, ActiveOnly: true, OverlapFrom: &task1.StartTime, OverlapTo: &task1.EndTime})
+	if err != nil {
+		t.Fatalf("list booked tasks: %v", err)
+	}
+	if len(booked) != 2 {
+		t.Fatalf("expected 2 booked tasks, got %d", len(booked))
+	}
+
+	window := domain.BayCapacityWindow{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		BayID:         bay.ID,
+		StartTime:     now.Add(6 * time.Hour),
+		EndTime:       now.Add(8 * time.Hour),
+		CapacitySlots: 0,
+		Reason:        "floor resurfacing",
+		CreatedAt:     now,
+	}
+	if _, err := windowRepo.Create(ctx, window); err != nil {
+		t.Fatalf("create capacity window: %v", err)
+	}
+	clash := window
+	clash.ID = uuid.New()
+	clash.StartTime = now.Add(7 * time.Hour)
+	clash.EndTime = now.Add(9 * time.Hour)
+	if _, err := windowRepo.Create(ctx, clash); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected overlapping window conflict, got %v", err)
+	}
+	windows, err := windowRepo.ListOverlapping(ctx, org.ID, bay.ID, now, now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list capacity windows: %v", err)
+	}
+	if len(windows) != 1 || windows[0].CapacitySlots != 0 {
+		t.Fatalf("expected the closing window, got %+v", windows)
+	}
+
+	if err := bayRepo.SoftDelete(ctx, org.ID, bay.ID, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict deleting a bay with open tasks, got %v", err)
+	}
+	if err := stationRepo.SoftDelete(ctx, org.ID, station.ID, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict deleting a station with bays, got %v", err)
+	}
+}
+
+func TestPostgresSchedulePlanApplySwapsWindows(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	planRepo := &SchedulePlanRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Plan Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N100SP",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 2,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+
+	first := domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateScheduled,
+		Priority:   domain.PriorityRoutine,
+		StartTime:  now.Add(1 * time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	second := first
+	second.ID = uuid.New()
+	second.Priority = domain.PriorityAOG
+	second.StartTime = now.Add(3 * time.Hour)
+	second.EndTime = now.Add(5 * time.Hour)
+	for _, task := range []domain.MaintenanceTask{first, second} {
+		if _, err := taskRepo.Create(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	item := func(task domain.MaintenanceTask, start, end time.Time) domain.SchedulePlanItem {
+		return domain.SchedulePlanItem{
+			TaskID:        task.ID,
+			AircraftID:    task.AircraftID,
+			Priority:      task.Priority,
+			TaskUpdatedAt: task.UpdatedAt,
+			OldStartTime:  task.StartTime,
+			OldEndTime:    task.EndTime,
+			StartTime:     &start,
+			EndTime:       &end,
+		}
+	}
+	plan := domain.SchedulePlan{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		State:        domain.SchedulePlanProposed,
+		HorizonStart: now,
+		HorizonEnd:   now.Add(24 * time.Hour),
+		CreatedBy:    uuid.New(),
+		Items: []domain.SchedulePlanItem{
+			item(second, first.StartTime, first.EndTime),
+			item(first, second.StartTime, second.EndTime),
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := planRepo.Create(ctx, plan); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	stale := plan
+	stale.ID = uuid.New()
+	if _, err := planRepo.Create(ctx, stale); err != nil {
+		t.Fatalf("create second plan: %v", err)
+	}
+
+	applied, err := planRepo.Apply(ctx, plan, plan.CreatedBy, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("apply plan: %v", err)
+	}
+	if applied.State != domain.SchedulePlanApplied || len(applied.Items) != 2 {
+		t.Fatalf("expected applied plan with 2 items, got %s with %d", applied.State, len(applied.Items))
+	}
+	moved, err := taskRepo.GetByID(ctx, org.ID, second.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if !moved.StartTime.Equal(first.StartTime) {
+		t.Fatalf("expected aog task moved to %s, got %s", first.StartTime, moved.StartTime)
+	}
+	if _, err := planRepo.Apply(ctx, plan, plan.CreatedBy, now.Add(time.Minute)); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict re-applying plan, got %v", err)
+	}
+	if _, err := planRepo.Apply(ctx, stale, stale.CreatedBy, now.Add(2*time.Minute)); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict applying stale plan, got %v", err)
+	}
+	if _, err := planRepo.Discard(ctx, org.ID, stale.ID, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("discard stale plan: %v", err)
+	}
+}
+
+func TestPostgresScheduleChangeRescheduleIsAtomic(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	changeRepo := &ScheduleChangeRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Cascade Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N200CR",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 2,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+
+	root := domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateScheduled,
+		Priority:   domain.PriorityRoutine,
+		StartTime:  now.Add(1 * time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	dependent := root
+	dependent.ID = uuid.New()
+	dependent.StartTime = now.Add(3 * time.Hour)
+	dependent.EndTime = now.Add(5 * time.Hour)
+	for _, task := range []domain.MaintenanceTask{root, dependent} {
+		if _, err := taskRepo.Create(ctx, task); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+	}
+
+	event := func(task domain.MaintenanceTask, start, end time.Time) domain.ScheduleChangeEvent {
+		return domain.ScheduleChangeEvent{
+			ID:              uuid.New(),
+			OrgID:           org.ID,
+			TaskID:          task.ID,
+			ChangeType:      domain.ScheduleChangeRescheduled,
+			Reason:          "slip",
+			OldStartTime:    &task.StartTime,
+			NewStartTime:    &start,
+			OldEndTime:      &task.EndTime,
+			NewEndTime:      &end,
+			AffectedTaskIDs: []uuid.UUID{dependent.ID},
+			CreatedAt:       now,
+		}
+	}
+	// The root moves into the dependent's stored window; the overlap only
+	// clears once the dependent has moved too.
+	movedRoot := root
+	movedRoot.StartTime, movedRoot.EndTime = now.Add(2*time.Hour), now.Add(4*time.Hour)
+	movedDependent := dependent
+	movedDependent.StartTime, movedDependent.EndTime = now.Add(4*time.Hour), now.Add(6*time.Hour)
+	if _, err := changeRepo.Reschedule(ctx, event(root, movedRoot.StartTime, movedRoot.EndTime), []domain.MaintenanceTask{movedRoot, movedDependent}, now.Add(time.Minute)); err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	stored, err := taskRepo.GetByID(ctx, org.ID, dependent.ID)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if !stored.StartTime.Equal(movedDependent.StartTime) {
+		t.Fatalf("expected dependent moved to %s, got %s", movedDependent.StartTime, stored.StartTime)
+	}
+
+	// Both tasks now carry a newer updated_at, so a second attempt with the
+	// stale versions must write nothing.
+	later := movedRoot
+	later.StartTime, later.EndTime = now.Add(10*time.Hour), now.Add(12*time.Hour)
+	if _, err := changeRepo.Reschedule(ctx, event(movedRoot, later.StartTime, later.EndTime), []domain.MaintenanceTask{later}, now.Add(2*time.Minute)); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for stale task, got %v", err)
+	}
+	events, err := changeRepo.ListByTask(ctx, org.ID, root.ID)
+	if err != nil {
+		t.Fatalf("list schedule changes: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 schedule change, got %d", len(events))
+	}
+}
+
+func TestPostgresRosterRepositories(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	userRepo := &UserRepository{DB: pool}
+	patternRepo := &ShiftPatternRepository{DB: pool}
+	assignmentRepo := &RosterAssignmentRepository{DB: pool}
+	absenceRepo := &MechanicAbsenceRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Roster Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	mechanic, err := userRepo.Create(ctx, domain.User{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		Email:        "roster@example.com",
+		Role:         domain.RoleMechanic,
+		PasswordHash: "hash",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	weekdays := domain.NewWeekdaySet(time.Monday, time.Wednesday, time.Friday)
+	pattern, err := patternRepo.Create(ctx, domain.ShiftPattern{
+		ID:              uuid.New(),
+		OrgID:           org.ID,
+		Name:            "Early",
+		Timezone:        "Europe/Berlin",
+		StartMinute:     6 * 60,
+		DurationMinutes: 8 * 60,
+		Weekdays:        weekdays,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		t.Fatalf("create shift pattern: %v", err)
+	}
+	fetched, err := patternRepo.GetByID(ctx, org.ID, pattern.ID)
+	if err != nil {
+		t.Fatalf("get shift pattern: %v", err)
+	}
+	if fetched.Weekdays != weekdays || fetched.StartMinute != 6*60 {
+		t.Fatalf("unexpected shift pattern %+v", fetched)
+	}
+
+	assignment, err := assignmentRepo.Create(ctx, domain.RosterAssignment{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		MechanicID:     mechanic.ID,
+		ShiftPatternID: pattern.ID,
+		EffectiveFrom:  now.Add(-24 * time.Hour),
+		CreatedAt:      now,
+	})
+	if err != nil {
+		t.Fatalf("create roster assignment: %v", err)
+	}
+	from, to := now, now.Add(7*24*time.Hour)
+	listed, err := assignmentRepo.List(ctx, ports.RosterAssignmentFilter{OrgID: &org.ID, MechanicID: &mechanic.ID, ActiveFrom: &from, ActiveTo: &to})
+	if err != nil {
+		t.Fatalf("list roster assignments: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != assignment.ID {
+		t.Fatalf("expected the open-ended assignment, got %+v", listed)
+	}
+	if err := patternRepo.SoftDelete(ctx, org.ID, pattern.ID, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict deleting an assigned pattern, got %v", err)
+	}
+	if err := assignmentRepo.Delete(ctx, org.ID, assignment.ID); err != nil {
+		t.Fatalf("delete roster assignment: %v", err)
+	}
+	if err := patternRepo.SoftDelete(ctx, org.ID, pattern.ID, now); err != nil {
+		t.Fatalf("delete shift pattern: %v", err)
+	}
+
+	absence, err := absenceRepo.Create(ctx, domain.MechanicAbsence{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		MechanicID: mechanic.ID,
+		Kind:       domain.AbsenceSick,
+		StartTime:  now.Add(24 * time.Hour),
+		EndTime:    now.Add(48 * time.Hour),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create absence: %v", err)
+	}
+	absences, err := absenceRepo.ListOverlapping(ctx, org.ID, nil, now.Add(36*time.Hour), now.Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("list absences: %v", err)
+	}
+	if len(absences) != 1 || absences[0].ID != absence.ID {
+		t.Fatalf("expected the overlapping absence, got %+v", absences)
+	}
+	absences, err = absenceRepo.ListOverlapping(ctx, org.ID, &mechanic.ID, now.Add(48*time.Hour), now.Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("list absences: %v", err)
+	}
+	if len(absences) != 0 {
+		t.Fatalf("expected no absence after it ends, got %+v", absences)
+	}
+}
+
+func TestPostgresPlannedFlightRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	flightRepo := &PlannedFlightRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+	base := now.Add(24 * time.Hour)
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Name:      "Ops",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N789AM",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+
+	outbound := domain.PlannedFlight{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		AircraftID:    aircraft.ID,
+		FlightNumber:  "AM100",
+		Origin:        "JFK",
+		Destination:   "BOS",
+		DepartureTime: base,
+		ArrivalTime:   base.Add(90 * time.Minute),
+		Status:        domain.FlightScheduled,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := flightRepo.Upsert(ctx, outbound); err != nil {
+		t.Fatalf("create flight: %v", err)
+	}
+	inbound := outbound
+	inbound.ID = uuid.New()
+	inbound.FlightNumber = "AM101"
+	inbound.Origin, inbound.Destination = "BOS", "JFK"
+	inbound.DepartureTime = base.Add(6 * time.Hour)
+	inbound.ArrivalTime = base.Add(7*time.Hour + 30*time.Minute)
+	if _, err := flightRepo.Upsert(ctx, inbound); err != nil {
+		t.Fatalf("create return flight: %v", err)
+	}
+
+	overlapping := outbound
+	overlapping.ID = uuid.New()
+	overlapping.FlightNumber = "AM102"
+	overlapping.DepartureTime = base.Add(time.Hour)
+	overlapping.ArrivalTime = base.Add(3 * time.Hour)
+	_, err = flightRepo.Upsert(ctx, overlapping)
+	var capacityErr *domain.CapacityConflictError
+	if !errors.As(err, &capacityErr) || capacityErr.Kind != domain.CapacityConflictFlightOverlap {
+		t.Fatalf("expected flight overlap conflict, got %v", err)
+	}
+
+	revised := outbound
+	revised.ID = uuid.New()
+	revised.ArrivalTime = base.Add(2 * time.Hour)
+	stored, err := flightRepo.Upsert(ctx, revised)
+	if err != nil {
+		t.Fatalf("update flight: %v", err)
+	}
+	if stored.ID != outbound.ID || !stored.ArrivalTime.Equal(revised.ArrivalTime) {
+		t.Fatalf("expected flight %s updated in place, got %+v", outbound.ID, stored)
+	}
+
+	from, to := base.Add(time.Hour), base.Add(5*time.Hour)
+	status := domain.FlightScheduled
+	flights, err := flightRepo.List(ctx, ports.PlannedFlightFilter{OrgID: &org.ID, AircraftIDs: []uuid.UUID{aircraft.ID}, Status: &status, From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("list flights: %v", err)
+	}
+	if len(flights) != 1 || flights[0].ID != outbound.ID {
+		t.Fatalf("expected only the outbound flight in the air, got %+v", flights)
+	}
+	flights, err = flightRepo.List(ctx, ports.PlannedFlightFilter{OrgID: &org.ID, Station: "bos"})
+	if err != nil {
+		t.Fatalf("list station flights: %v", err)
+	}
+	if len(flights) != 2 {
+		t.Fatalf("expected 2 flights touching BOS, got %d", len(flights))
+	}
+
+	previous, err := flightRepo.ListPrevious(ctx, org.ID, []uuid.UUID{aircraft.ID}, base.Add(8*time.Hour))
+	if err != nil {
+		t.Fatalf("list previous flights: %v", err)
+	}
+	if len(previous) != 1 || previous[0].ID != inbound.ID {
+		t.Fatalf("expected the return flight as last arrival, got %+v", previous)
+	}
+
+	if err := flightRepo.Delete(ctx, org.ID, inbound.ID); err != nil {
+		t.Fatalf("delete flight: %v", err)
+	}
+	if _, err := flightRepo.GetByID(ctx, org.ID, inbound.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected deleted flight to be gone, got %v", err)
+	}
+}
+
+func TestPostgresTaskCardRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	cardRepo := &TaskCardRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Card Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N321TC",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	minTorque, maxTorque := 20.0, 25.0
+	card := domain.TaskCard{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		TaskID:    task.ID,
+		Title:     "Brake assembly",
+		Reference: "AMM 32-42-27",
+		CreatedBy: uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	card.Steps = []domain.TaskCardStep{
+		{ID: uuid.New(), Position: 1, Instruction: "Torque bolts", RequiredInspection: true, MeasurementUnit: "Nm", MinValue: &minTorque, MaxValue: &maxTorque, CreatedAt: now, UpdatedAt: now},
+		{ID: uuid.New(), Position: 2, Instruction: "Safety wire", CreatedAt: now, UpdatedAt: now},
+	}
+	created, err := cardRepo.Create(ctx, card)
+	if err != nil {
+		t.Fatalf("create task card: %v", err)
+	}
+	if len(created.Steps) != 2 || created.Steps[0].Position != 1 || *created.Steps[0].MaxValue != maxTorque {
+		t.Fatalf("unexpected task card %+v", created)
+	}
+
+	torqueStep := created.Steps[0].ID
+	mechanic, inspector := uuid.New(), uuid.New()
+	if _, err := cardRepo.InspectStep(ctx, org.ID, torqueStep, inspector, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict inspecting an unperformed step, got %v", err)
+	}
+	value := 22.0
+	performed, err := cardRepo.PerformStep(ctx, org.ID, torqueStep, mechanic, &value, now)
+	if err != nil {
+		t.Fatalf("perform step: %v", err)
+	}
+	if performed.MeasuredValue == nil || *performed.MeasuredValue != value || *performed.PerformedBy != mechanic {
+		t.Fatalf("unexpected performed step %+v", performed)
+	}
+	if _, err := cardRepo.PerformStep(ctx, org.ID, torqueStep, mechanic, &value, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict performing a step twice, got %v", err)
+	}
+	if _, err := cardRepo.InspectStep(ctx, org.ID, torqueStep, mechanic, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict when the performer inspects, got %v", err)
+	}
+	if _, err := cardRepo.InspectStep(ctx, org.ID, uuid.New(), inspector, now); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found for an unknown step, got %v", err)
+	}
+	if _, err := pool.Exec(ctx, `UPDATE task_card_steps SET inspected_by=performed_by, inspected_at=$2 WHERE id=$1`, torqueStep, now); err == nil {
+		t.Fatalf("expected the database to reject an inspection by the performer")
+	}
+	if _, err := cardRepo.InspectStep(ctx, org.ID, torqueStep, inspector, now); err != nil {
+		t.Fatalf("inspect step: %v", err)
+	}
+
+	cards, err := cardRepo.ListByTask(ctx, org.ID, task.ID)
+	if err != nil {
+		t.Fatalf("list task cards: %v", err)
+	}
+	if len(cards) != 1 || len(cards[0].Steps) != 2 || !cards[0].Steps[0].Complete() || cards[0].Complete() {
+		t.Fatalf("expected one card with only the inspected step complete, got %+v", cards)
+	}
+}
+
+func TestPostgresLaborEntryRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	userRepo := &UserRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	laborRepo := &LaborEntryRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Labor Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	mechanic := domain.User{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		Email:        "labor@ops.local",
+		Role:         domain.RoleMechanic,
+		PasswordHash: "hash",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := userRepo.Create(ctx, mechanic); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	var aircraftTypeID uuid.UUID
+	if err := pool.QueryRow(ctx, "SELECT id FROM aircraft_types ORDER BY icao_code LIMIT 1").Scan(&aircraftTypeID); err != nil {
+		t.Fatalf("load aircraft type: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:             uuid.New(),
+		OrgID:          org.ID,
+		TailNumber:     "N321LB",
+		Model:          "A320",
+		AircraftTypeID: &aircraftTypeID,
+		Status:         domain.AircraftGrounded,
+		CapacitySlots:  1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateInProgress,
+		StartTime:  now.Add(-4 * time.Hour),
+		EndTime:    now.Add(time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	entry := func(start time.Time, end *time.Time, source domain.LaborSource) domain.LaborEntry {
+		return domain.LaborEntry{
+			ID:         uuid.New(),
+			OrgID:      org.ID,
+			TaskID:     task.ID,
+			MechanicID: mechanic.ID,
+			Source:     source,
+			StartedAt:  start,
+			EndedAt:    end,
+			CreatedBy:  mechanic.ID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+	manualEnd := now.Add(-2 * time.Hour)
+	manual, err := laborRepo.Create(ctx, entry(now.Add(-4*time.Hour), &manualEnd, domain.LaborSourceManual))
+	if err != nil {
+		t.Fatalf("create manual entry: %v", err)
+	}
+	if manual.Hours() != 2 || manual.Source != domain.LaborSourceManual {
+		t.Fatalf("unexpected manual entry %+v", manual)
+	}
+	overlapEnd := now.Add(-time.Hour)
+	_, err = laborRepo.Create(ctx, entry(now.Add(-3*time.Hour), &overlapEnd, domain.LaborSourceManual))
+	var capacityConflict *domain.CapacityConflictError
+	if !errors.As(err, &capacityConflict) || capacityConflict.Kind != domain.CapacityConflictLaborOverlap {
+		t.Fatalf("expected labor_overlap conflict, got %v", err)
+	}
+
+	clocked, err := laborRepo.Create(ctx, entry(now.Add(-time.Hour), nil, domain.LaborSourceClock))
+	if err != nil {
+		t.Fatalf("clock in: %v", err)
+	}
+	if _, err := laborRepo.Create(ctx, entry(now.Add(time.Hour), nil, domain.LaborSourceClock)); !errors.As(err, &capacityConflict) {
+		t.Fatalf("expected conflict for a second open clock, got %v", err)
+	}
+	overlapping, err := laborRepo.ListOverlapping(ctx, org.ID, mechanic.ID, now.Add(2*time.Hour), nil)
+	if err != nil {
+		t.Fatalf("list overlapping: %v", err)
+	}
+	if len(overlapping) != 1 || overlapping[0].ID != clocked.ID {
+		t.Fatalf("expected the open clock to overlap later time, got %+v", overlapping)
+	}
+
+	closed, err := laborRepo.ClockOut(ctx, org.ID, clocked.ID, now)
+	if err != nil {
+		t.Fatalf("clock out: %v", err)
+	}
+	if closed.Open() || closed.Hours() != 1 {
+		t.Fatalf("unexpected closed entry %+v", closed)
+	}
+	if _, err := laborRepo.ClockOut(ctx, org.ID, clocked.ID, now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict clocking out twice, got %v", err)
+	}
+	if _, err := laborRepo.ClockOut(ctx, org.ID, uuid.New(), now); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found for a missing entry, got %v", err)
+	}
+
+	entries, err := laborRepo.ListByTask(ctx, org.ID, task.ID)
+	if err != nil {
+		t.Fatalf("list by task: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != manual.ID {
+		t.Fatalf("expected 2 entries in start order, got %+v", entries)
+	}
+	if err := laborRepo.Delete(ctx, org.ID, manual.ID); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if err := laborRepo.Delete(ctx, org.ID, manual.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+
+	if _, err := taskRepo.UpdateState(ctx, org.ID, task.ID, domain.TaskStateCompleted, "done", now); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	certRepo := &CertificationRepository{DB: pool}
+	recency, err := certRepo.GetRecencyHours(ctx, org.ID, mechanic.ID, aircraftTypeID, now.Add(-48*time.Hour))
+	if err != nil {
+		t.Fatalf("get recency hours: %v", err)
+	}
+	if recency != 1 {
+		t.Fatalf("expected 1 hour of recency logged on completion, got %.2f", recency)
+	}
+}
+
+func TestPostgresDefectRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	defectRepo := &DefectRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Defect Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N654DF",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	reporter := uuid.New()
+	report := func(title string) domain.Defect {
+		t.Helper()
+		defect, err := defectRepo.Create(ctx, domain.Defect{
+			ID:         uuid.New(),
+			OrgID:      org.ID,
+			AircraftID: aircraft.ID,
+			Title:      title,
+			ATAChapter: "33",
+			State:      domain.DefectOpen,
+			ReportedBy: reporter,
+			ReportedAt: now,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			t.Fatalf("create defect: %v", err)
+		}
+		return defect
+	}
+	defect := report("Landing light inoperative")
+
+	if err := defect.Defer(domain.DefectDeferral{Category: domain.MELCategoryC, MELItem: "33-41-01", Reference: "DD-1"}, reporter, now); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateScheduled,
+		StartTime:  defect.ExpiresAt.Add(-2 * time.Hour),
+		EndTime:    *defect.ExpiresAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	defect.RectificationTaskID = &task.ID
+	deferred, err := defectRepo.Update(ctx, defect, domain.DefectOpen)
+	if err != nil {
+		t.Fatalf("update defect: %v", err)
+	}
+	if deferred.State != domain.DefectDeferred || deferred.MELCategory == nil || *deferred.MELCategory != domain.MELCategoryC {
+		t.Fatalf("unexpected deferred defect %+v", deferred)
+	}
+	if _, err := defectRepo.Update(ctx, defect, domain.DefectOpen); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict updating from a stale state, got %v", err)
+	}
+
+	other := report("Cabin PA inoperative")
+	if err := other.Defer(domain.DefectDeferral{Category: domain.MELCategoryB, MELItem: "23-31-01", Reference: "DD-1"}, reporter, now); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	if _, err := defectRepo.Update(ctx, other, domain.DefectOpen); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict reusing a deferral reference, got %v", err)
+	}
+
+	newExpiry := deferred.ExpiresAt.AddDate(0, 0, 5)
+	extension := domain.DeferralExtension{
+		ID:                uuid.New(),
+		OrgID:             org.ID,
+		DefectID:          deferred.ID,
+		PreviousExpiresAt: *deferred.ExpiresAt,
+		NewExpiresAt:      newExpiry,
+		Reason:            "Awaiting spares",
+		ApprovalReference: "QA-1",
+		ApprovedBy:        uuid.New(),
+		CreatedAt:         now,
+	}
+	extended, err := defectRepo.Extend(ctx, extension)
+	if err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if !extended.ExpiresAt.Equal(newExpiry) {
+		t.Fatalf("expected expiry %s, got %s", newExpiry, extended.ExpiresAt)
+	}
+	extension.ID = uuid.New()
+	if _, err := defectRepo.Extend(ctx, extension); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict extending from a stale expiry, got %v", err)
+	}
+	extensions, err := defectRepo.ListExtensions(ctx, org.ID, deferred.ID)
+	if err != nil || len(extensions) != 1 || extensions[0].ApprovalReference != "QA-1" {
+		t.Fatalf("expected one extension, got %+v (%v)", extensions, err)
+	}
+
+	before := newExpiry.Add(time.Hour)
+	expiring, err := defectRepo.List(ctx, ports.DefectFilter{OrgID: &org.ID, ExpiringBefore: &before})
+	if err != nil || len(expiring) != 1 || expiring[0].ID != deferred.ID {
+		t.Fatalf("expected the deferred defect to be expiring, got %+v (%v)", expiring, err)
+	}
+	open := domain.DefectOpen
+	opened, err := defectRepo.List(ctx, ports.DefectFilter{OrgID: &org.ID, AircraftID: &aircraft.ID, State: &open})
+	if err != nil || len(opened) != 1 || opened[0].ID != other.ID {
+		t.Fatalf("expected one open defect, got %+v (%v)", opened, err)
+	}
+}
+
+func TestPostgresFindingRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	findingRepo := &FindingRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Finding Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N655FN",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	createTask := func(start time.Time, taskType domain.TaskType) domain.MaintenanceTask {
+		t.Helper()
+		task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+			ID:         uuid.New(),
+			OrgID:      org.ID,
+			AircraftID: aircraft.ID,
+			Type:       taskType,
+			State:      domain.TaskStateInProgress,
+			StartTime:  start,
+			EndTime:    start.Add(2 * time.Hour),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		return task
+	}
+	parent := createTask(now, domain.TaskTypeInspection)
+	raiser := uuid.New()
+	raise := func(description string, complianceItemID *uuid.UUID) (domain.Finding, error) {
+		return findingRepo.Create(ctx, domain.Finding{
+			ID:               uuid.New(),
+			OrgID:            org.ID,
+			TaskID:           parent.ID,
+			AircraftID:       aircraft.ID,
+			ComplianceItemID: complianceItemID,
+			Description:      description,
+			Zone:             "822",
+			ATAChapter:       "52-30",
+			State:            domain.FindingOpen,
+			RaisedBy:         raiser,
+			RaisedAt:         now,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+	}
+	finding, err := raise("Corrosion on cargo door frame", nil)
+	if err != nil {
+		t.Fatalf("create finding: %v", err)
+	}
+	if finding.PhotoURLs == nil || len(finding.PhotoURLs) != 0 {
+		t.Fatalf("expected an empty photo list, got %#v", finding.PhotoURLs)
+	}
+	if _, err := raise("Second finding", nil); err != nil {
+		t.Fatalf("create finding: %v", err)
+	}
+	count, err := findingRepo.CountOpen(ctx, org.ID, []uuid.UUID{parent.ID})
+	if err != nil || count != 2 {
+		t.Fatalf("expected two open findings, got %d (%v)", count, err)
+	}
+
+	newRepair := func(start time.Time) (domain.MaintenanceTask, domain.TaskDependency) {
+		repair := domain.MaintenanceTask{
+			ID:         uuid.New(),
+			OrgID:      org.ID,
+			AircraftID: aircraft.ID,
+			Type:       domain.TaskTypeRepair,
+			State:      domain.TaskStateScheduled,
+			StartTime:  start,
+			EndTime:    start.Add(2 * time.Hour),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		return repair, domain.TaskDependency{
+			ID:              uuid.New(),
+			OrgID:           org.ID,
+			TaskID:          repair.ID,
+			DependsOnTaskID: parent.ID,
+			DependencyType:  domain.DependencyFinishToStart,
+			CreatedAt:       now,
+		}
+	}
+	repair, dependency := newRepair(now.Add(3 * time.Hour))
+	convertedAt := now.Add(time.Minute)
+	finding.State = domain.FindingConverted
+	finding.RepairTaskID = &repair.ID
+	finding.ConvertedBy = &raiser
+	finding.ConvertedAt = &convertedAt
+	finding.UpdatedAt = convertedAt
+	converted, err := findingRepo.Convert(ctx, finding, repair, dependency)
+	if err != nil {
+		t.Fatalf("convert finding: %v", err)
+	}
+	if converted.State != domain.FindingConverted || converted.RepairTaskID == nil || *converted.RepairTaskID != repair.ID {
+		t.Fatalf("unexpected converted finding %+v", converted)
+	}
+	if _, err := taskRepo.GetByID(ctx, org.ID, repair.ID); err != nil {
+		t.Fatalf("expected the repair task stored, got %v", err)
+	}
+	deps, err := (&TaskDependencyRepository{DB: pool}).ListByTask(ctx, org.ID, repair.ID)
+	if err != nil || len(deps) != 1 || deps[0].DependsOnTaskID != parent.ID {
+		t.Fatalf("expected the repair to depend on the parent, got %+v (%v)", deps, err)
+	}
+
+	// Converting again fails and leaves no second repair task behind.
+	again, againDependency := newRepair(now.Add(6 * time.Hour))
+	finding.RepairTaskID = &again.ID
+	if _, err := findingRepo.Convert(ctx, finding, again, againDependency); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict converting twice, got %v", err)
+	}
+	if _, err := taskRepo.GetByID(ctx, org.ID, again.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected the second repair task rolled back, got %v", err)
+	}
+	if _, err := findingRepo.Update(ctx, finding, domain.FindingOpen); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict updating from a stale state, got %v", err)
+	}
+	count, err = findingRepo.CountOpen(ctx, org.ID, []uuid.UUID{parent.ID})
+	if err != nil || count != 1 {
+		t.Fatalf("expected one open finding, got %d (%v)", count, err)
+	}
+
+	open := domain.FindingOpen
+	listed, err := findingRepo.List(ctx, ports.FindingFilter{OrgID: &org.ID, TaskID: &parent.ID, State: &open})
+	if err != nil || len(listed) != 1 || listed[0].Description != "Second finding" {
+		t.Fatalf("expected one open finding listed, got %+v (%v)", listed, err)
+	}
+}
+
+func TestPostgresTaskAssignmentRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	userRepo := &UserRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	crewRepo := &TaskAssignmentRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Crew Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	mechanic := domain.User{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		Email:        "crew@ops.local",
+		Role:         domain.RoleMechanic,
+		PasswordHash: "hash",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := userRepo.Create(ctx, mechanic); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N322CR",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeOverhaul,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(9 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	assign := func(role domain.CrewRole, hours float64, at time.Time) (domain.TaskAssignment, error) {
+		return crewRepo.Create(ctx, domain.TaskAssignment{
+			ID:           uuid.New(),
+			OrgID:        org.ID,
+			TaskID:       task.ID,
+			MechanicID:   mechanic.ID,
+			Role:         role,
+			PlannedHours: hours,
+			AssignedBy:   uuid.New(),
+			CreatedAt:    at,
+			UpdatedAt:    at,
+		})
+	}
+	lead, err := assign(domain.CrewRoleLead, 6, now)
+	if err != nil {
+		t.Fatalf("assign lead: %v", err)
+	}
+	if _, err := assign(domain.CrewRoleCertifying, 2, now.Add(time.Second)); err != nil {
+		t.Fatalf("assign certifying staff: %v", err)
+	}
+	if _, err := assign(domain.CrewRoleLead, 1, now.Add(2*time.Second)); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict assigning the same role twice, got %v", err)
+	}
+
+	lead.PlannedHours = 5.5
+	lead.UpdatedAt = now.Add(time.Minute)
+	updated, err := crewRepo.UpdatePlannedHours(ctx, lead)
+	if err != nil || updated.PlannedHours != 5.5 {
+		t.Fatalf("expected 5.5 planned hours, got %+v (%v)", updated, err)
+	}
+	crew, err := crewRepo.ListByTask(ctx, org.ID, task.ID)
+	if err != nil || len(crew) != 2 || crew[0].Role != domain.CrewRoleLead || crew.PlannedHours() != 7.5 {
+		t.Fatalf("expected lead and certifying staff planned for 7.5 hours, got %+v (%v)", crew, err)
+	}
+
+	if err := crewRepo.Delete(ctx, org.ID, lead.ID); err != nil {
+		t.Fatalf("delete assignment: %v", err)
+	}
+	if err := crewRepo.Delete(ctx, org.ID, lead.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+}
+
+func TestPostgresAttachmentRepository(t *testing.T) {
+	pool, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	orgRepo := &OrganizationRepository{DB: pool}
+	userRepo := &UserRepository{DB: pool}
+	aircraftRepo := &AircraftRepository{DB: pool}
+	taskRepo := &TaskRepository{DB: pool}
+	policyRepo := &OrgPolicyRepository{DB: pool}
+	attachmentRepo := &AttachmentRepository{DB: pool}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	org := domain.Organization{ID: uuid.New(), Name: "Evidence Ops", CreatedAt: now, UpdatedAt: now}
+	if _, err := orgRepo.Create(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	mechanic := domain.User{
+		ID:           uuid.New(),
+		OrgID:        org.ID,
+		Email:        "evidence@ops.local",
+		Role:         domain.RoleMechanic,
+		PasswordHash: "hash",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := userRepo.Create(ctx, mechanic); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	aircraft, err := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         org.ID,
+		TailNumber:    "N325EV",
+		Model:         "A320",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		t.Fatalf("create aircraft: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		AircraftID: aircraft.ID,
+		Type:       domain.TaskTypeInspection,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(3 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	policy, err := policyRepo.Upsert(ctx, domain.OrgPolicy{
+		OrgID:                      org.ID,
+		RetentionInterval:          30 * 24 * time.Hour,
+		MaxWebhookAttempts:         10,
+		WebhookReplayWindowSeconds: 300,
+		APIRateLimitPerMin:         100,
+		APIKeyRateLimitPerMin:      10,
+		MaxAttachmentBytes:         5 << 20,
+		CreatedAt:                  now,
+		UpdatedAt:                  now,
+	})
+	if err != nil || policy.MaxAttachmentBytes != 5<<20 {
+		t.Fatalf("expected a 5 MiB attachment limit, got %+v (%v)", policy, err)
+	}
+
+	attach := func(entityType domain.AttachmentEntityType, entityID uuid.UUID, at time.Time) (domain.Attachment, error) {
+		id := uuid.New()
+		return attachmentRepo.Create(ctx, domain.Attachment{
+			ID:          id,
+			OrgID:       org.ID,
+			EntityType:  entityType,
+			EntityID:    entityID,
+			FileName:    "photo.png",
+			ContentType: "image/png",
+			SizeBytes:   2048,
+			SHA256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			StorageKey:  domain.AttachmentStorageKey(org.ID, entityType, id),
+			UploadedBy:  mechanic.ID,
+			CreatedAt:   at,
+			UpdatedAt:   at,
+		})
+	}
+	first, err := attach(domain.AttachmentTask, task.ID, now)
+	if err != nil {
+		t.Fatalf("create attachment: %v", err)
+	}
+	if _, err := attach(domain.AttachmentTask, task.ID, now.Add(time.Second)); err != nil {
+		t.Fatalf("create second attachment: %v", err)
+	}
+	finding, err := attach(domain.AttachmentFinding, uuid.New(), now)
+	if err != nil {
+		t.Fatalf("create finding attachment: %v", err)
+	}
+	duplicate := first
+	duplicate.ID = uuid.New()
+	if _, err := attachmentRepo.Create(ctx, duplicate); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict reusing a storage key, got %v", err)
+	}
+
+	listed, err := attachmentRepo.ListByEntity(ctx, org.ID, domain.AttachmentTask, task.ID)
+	if err != nil || len(listed) != 2 || listed[0].ID != first.ID {
+		t.Fatalf("expected two task attachments oldest first, got %+v (%v)", listed, err)
+	}
+
+	if err := attachmentRepo.SoftDelete(ctx, org.ID, finding.ID, now); err != nil {
+		t.Fatalf("soft delete attachment: %v", err)
+	}
+	if _, err := attachmentRepo.GetByID(ctx, org.ID, finding.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected deleted attachment to be hidden, got %v", err)
+	}
+	if err := attachmentRepo.SoftDelete(ctx, org.ID, finding.ID, now); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+
+	expired, err := attachmentRepo.ListExpired(ctx, org.ID, now.Add(time.Hour), 10)
+	if err != nil || len(expired) != 1 || expired[0].ID != finding.ID {
+		t.Fatalf("expected only the deleted attachment to expire, got %+v (%v)", expired, err)
+	}
+	if err := taskRepo.SoftDelete(ctx, org.ID, task.ID, now); err != nil {
+		t.Fatalf("soft delete task: %v", err)
+	}
+	expired, err = attachmentRepo.ListExpired(ctx, org.ID, now.Add(time.Hour), 10)
+	if err != nil || len(expired) != 3 {
+		t.Fatalf("expected the deleted task's attachments to expire too, got %+v (%v)", expired, err)
+	}
+	if expired, err := attachmentRepo.ListExpired(ctx, org.ID, now.Add(-time.Hour), 10); err != nil || len(expired) != 0 {
+		t.Fatalf("expected nothing to expire before the deletions, got %+v (%v)", expired, err)
+	}
+
+	for _, attachment := range expired {
+		if err := attachmentRepo.Purge(ctx, org.ID, attachment.ID); err != nil {
+			t.Fatalf("purge attachment: %v", err)
+		}
+	}
+	if err := attachmentRepo.Purge(ctx, org.ID, first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found purging twice, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LaborEntryRepository struct {
+	DB *pgxpool.Pool
+}
+
+const laborEntryColumns = `id, org_id, task_id, mechanic_id, source, started_at, ended_at, notes, created_by, created_at, updated_at`
+
+func (r *LaborEntryRepository) Create(ctx context.Context, entry domain.LaborEntry) (domain.LaborEntry, error) {
+	if r == nil || r.DB == nil {
+		return domain.LaborEntry{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO labor_entries (id, org_id, task_id, mechanic_id, source, started_at, ended_at, notes, created_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		RETURNING `+laborEntryColumns,
+		entry.ID, entry.OrgID, entry.TaskID, entry.MechanicID, entry.Source, entry.StartedAt, entry.EndedAt, entry.Notes,
+		entry.CreatedBy, entry.CreatedAt, entry.UpdatedAt)
+	created, err := scanLaborEntry(row)
+	if err != nil {
+		return domain.LaborEntry{}, translateLaborError(err)
+	}
+	return created, nil
+}
+
+func (r *LaborEntryRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.LaborEntry, error) {
+	if r == nil || r.DB == nil {
+		return domain.LaborEntry{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT `+laborEntryColumns+`
+		FROM labor_entries
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanLaborEntry(row)
+}
+
+func (r *LaborEntryRepository) ClockOut(ctx context.Context, orgID, id uuid.UUID, endedAt time.Time) (domain.LaborEntry, error) {
+	if r == nil || r.DB == nil {
+		return domain.LaborEntry{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE labor_entries
+		SET ended_at=$3, updated_at=$3
+		WHERE org_id=$1 AND id=$2 AND ended_at IS NULL
+		RETURNING `+laborEntryColumns, orgID, id, endedAt)
+	entry, err := scanLaborEntry(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := r.GetByID(ctx, orgID, id); err != nil {
+			return domain.LaborEntry{}, err
+		}
+		return domain.LaborEntry{}, domain.NewConflictError("labor entry already clocked out")
+	}
+	if err != nil {
+		return domain.LaborEntry{}, TranslateError(err)
+	}
+	return entry, nil
+}
+
+func (r *LaborEntryRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tag, err := r.DB.Exec(ctx, `DELETE FROM labor_entries WHERE org_id=$1 AND id=$2`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *LaborEntryRepository) ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.LaborEntry, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+laborEntryColumns+`
+		FROM labor_entries
+		WHERE org_id=$1 AND task_id=$2
+		ORDER BY started_at ASC, id ASC
+	`, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return collectLaborEntries(rows)
+}
+
+func (r *LaborEntryRepository) ListOverlapping(ctx context.Context, orgID, mechanicID uuid.UUID, start time.Time, end *time.Time) ([]domain.LaborEntry, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+laborEntryColumns+`
+		FROM labor_entries
+		WHERE org_id=$1 AND mechanic_id=$2 AND worked && tstzrange($3, $4, '[)')
+		ORDER BY started_at ASC, id ASC
+	`, orgID, mechanicID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return collectLaborEntries(rows)
+}
+
+// translateLaborError reports a second open clock of a mechanic, which the
+// partial unique index rejects, as the overlap it is.
+func translateLaborError(err error) error {
+	err = TranslateError(err)
+	if errors.Is(err, domain.ErrConflict) {
+		var capacityConflict *domain.CapacityConflictError
+		if !errors.As(err, &capacityConflict) {
+			return domain.NewCapacityConflict(domain.CapacityConflictLaborOverlap, "mechanic is already clocked in")
+		}
+	}
+	return err
+}
+
+func collectLaborEntries(rows pgx.Rows) ([]domain.LaborEntry, error) {
+	defer rows.Close()
+	var entries []domain.LaborEntry
+	for rows.Next() {
+		entry, err := scanLaborEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func scanLaborEntry(row pgx.Row) (domain.LaborEntry, error) {
+	var entry domain.LaborEntry
+	var source string
+	if err := row.Scan(&entry.ID, &entry.OrgID, &entry.TaskID, &entry.MechanicID, &source, &entry.StartedAt, &entry.EndedAt,
+		&entry.Notes, &entry.CreatedBy, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.LaborEntry{}, domain.ErrNotFound
+		}
+		return domain.LaborEntry{}, err
+	}
+	entry.Source = domain.LaborSource(source)
+	return entry, nil
+}
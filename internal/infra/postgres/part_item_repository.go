@@ -21,7 +21,8 @@ func (r *PartItemRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (
 		return domain.PartItem{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, deleted_at, created_at, updated_at
+		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, COALESCE(position, ''), life_limit_hours, life_limit_cycles,
+		       hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, deleted_at, created_at, updated_at
 		FROM part_items
 		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
 	`, orgID, id)
@@ -33,10 +34,13 @@ func (r *PartItemRepository) Create(ctx context.Context, item domain.PartItem) (
 		return domain.PartItem{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		INSERT INTO part_items (id, org_id, part_definition_id, serial_number, status, expiry_date, created_at, updated_at, deleted_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-		RETURNING id, org_id, part_definition_id, serial_number, status, expiry_date, deleted_at, created_at, updated_at
-	`, item.ID, item.OrgID, item.DefinitionID, item.SerialNumber, item.Status, item.ExpiryDate, item.CreatedAt, item.UpdatedAt, item.DeletedAt)
+		INSERT INTO part_items (id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, position, life_limit_hours, life_limit_cycles,
+			hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, created_at, updated_at, deleted_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8, ''),$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		RETURNING id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, COALESCE(position, ''), life_limit_hours, life_limit_cycles,
+		       hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, deleted_at, created_at, updated_at
+	`, item.ID, item.OrgID, item.DefinitionID, item.SerialNumber, item.Status, item.ExpiryDate, item.AircraftID, item.Position, item.LifeLimitHours, item.LifeLimitCycles,
+		item.HoursSinceNew, item.CyclesSinceNew, item.HoursSinceOverhaul, item.CyclesSinceOverhaul, item.CreatedAt, item.UpdatedAt, item.DeletedAt)
 	created, err := scanPartItem(row)
 	if err != nil {
 		return domain.PartItem{}, TranslateError(err)
@@ -50,10 +54,13 @@ func (r *PartItemRepository) Update(ctx context.Context, item domain.PartItem) (
 	}
 	row := r.DB.QueryRow(ctx, `
 		UPDATE part_items
-		SET status=$1, expiry_date=$2, updated_at=$3
-		WHERE org_id=$4 AND id=$5 AND deleted_at IS NULL
-		RETURNING id, org_id, part_definition_id, serial_number, status, expiry_date, deleted_at, created_at, updated_at
-	`, item.Status, item.ExpiryDate, item.UpdatedAt, item.OrgID, item.ID)
+		SET status=$1, expiry_date=$2, life_limit_hours=$3, life_limit_cycles=$4,
+			hours_since_new=$5, cycles_since_new=$6, hours_since_overhaul=$7, cycles_since_overhaul=$8, updated_at=$9
+		WHERE org_id=$10 AND id=$11 AND deleted_at IS NULL
+		RETURNING id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, COALESCE(position, ''), life_limit_hours, life_limit_cycles,
+		       hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, deleted_at, created_at, updated_at
+	`, item.Status, item.ExpiryDate, item.LifeLimitHours, item.LifeLimitCycles,
+		item.HoursSinceNew, item.CyclesSinceNew, item.HoursSinceOverhaul, item.CyclesSinceOverhaul, item.UpdatedAt, item.OrgID, item.ID)
 	updated, err := scanPartItem(row)
 	if err != nil {
 		return domain.PartItem{}, TranslateError(err)
@@ -74,6 +81,10 @@ func (r *PartItemRepository) List(ctx context.Context, filter ports.PartItemFilt
 	if filter.OrgID != nil {
 		add("org_id=", *filter.OrgID)
 	}
+	if filter.IDs != nil {
+		args = append(args, filter.IDs)
+		clauses = append(clauses, "id = ANY($"+itoa(len(args))+")")
+	}
 	if filter.DefinitionID != nil {
 		add("part_definition_id=", *filter.DefinitionID)
 	}
@@ -83,6 +94,15 @@ func (r *PartItemRepository) List(ctx context.Context, filter ports.PartItemFilt
 	if filter.ExpiryBefore != nil {
 		add("expiry_date <= ", *filter.ExpiryBefore)
 	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+	if filter.LifeLimited {
+		clauses = append(clauses, "(life_limit_hours IS NOT NULL OR life_limit_cycles IS NOT NULL)")
+	}
+	if filter.Unreserved {
+		clauses = append(clauses, "NOT EXISTS (SELECT 1 FROM part_reservations pr WHERE pr.org_id = part_items.org_id AND pr.part_item_id = part_items.id AND pr.state = 'reserved')")
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -97,7 +117,8 @@ func (r *PartItemRepository) List(ctx context.Context, filter ports.PartItemFilt
 	}
 
 	query := `
-		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, deleted_at, created_at, updated_at
+		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, COALESCE(position, ''), life_limit_hours, life_limit_cycles,
+		       hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, deleted_at, created_at, updated_at
 		FROM part_items
 		WHERE deleted_at IS NULL`
 	if len(clauses) > 0 {
@@ -155,7 +176,8 @@ func (r *PartItemRepository) UpdateStatus(ctx context.Context, orgID, id uuid.UU
 
 func scanPartItem(row pgx.Row) (domain.PartItem, error) {
 	var item domain.PartItem
-	if err := row.Scan(&item.ID, &item.OrgID, &item.DefinitionID, &item.SerialNumber, &item.Status, &item.ExpiryDate, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
+	if err := row.Scan(&item.ID, &item.OrgID, &item.DefinitionID, &item.SerialNumber, &item.Status, &item.ExpiryDate, &item.AircraftID, &item.Position, &item.LifeLimitHours, &item.LifeLimitCycles,
+		&item.HoursSinceNew, &item.CyclesSinceNew, &item.HoursSinceOverhaul, &item.CyclesSinceOverhaul, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.PartItem{}, domain.ErrNotFound
 		}
@@ -169,12 +191,14 @@ func (r *PartItemRepository) GetBySerialNumber(ctx context.Context, orgID uuid.U
 		return domain.PartItem{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, deleted_at, created_at, updated_at
+		SELECT id, org_id, part_definition_id, serial_number, status, expiry_date, aircraft_id, COALESCE(position, ''), life_limit_hours, life_limit_cycles,
+		       hours_since_new, cycles_since_new, hours_since_overhaul, cycles_since_overhaul, deleted_at, created_at, updated_at
 		FROM part_items
 		WHERE org_id=$1 AND serial_number=$2 AND deleted_at IS NULL
 	`, orgID, serial)
 	var item domain.PartItem
-	if err := row.Scan(&item.ID, &item.OrgID, &item.DefinitionID, &item.SerialNumber, &item.Status, &item.ExpiryDate, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
+	if err := row.Scan(&item.ID, &item.OrgID, &item.DefinitionID, &item.SerialNumber, &item.Status, &item.ExpiryDate, &item.AircraftID, &item.Position, &item.LifeLimitHours, &item.LifeLimitCycles,
+		&item.HoursSinceNew, &item.CyclesSinceNew, &item.HoursSinceOverhaul, &item.CyclesSinceOverhaul, &item.DeletedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.PartItem{}, domain.ErrNotFound
 		}
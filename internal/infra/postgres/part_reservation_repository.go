@@ -69,6 +69,31 @@ func (r *PartReservationRepository) ListByTask(ctx context.Context, orgID, taskI
 	return reservations, rows.Err()
 }
 
+func (r *PartReservationRepository) ListByTasks(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.PartReservation, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, task_id, part_item_id, state, quantity, created_at, updated_at
+		FROM part_reservations
+		WHERE org_id=$1 AND task_id = ANY($2)
+	`, orgID, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []domain.PartReservation
+	for rows.Next() {
+		var reservation domain.PartReservation
+		if err := rows.Scan(&reservation.ID, &reservation.OrgID, &reservation.TaskID, &reservation.PartItemID, &reservation.State, &reservation.Quantity, &reservation.CreatedAt, &reservation.UpdatedAt); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
+
 func (r *PartReservationRepository) UpdateState(ctx context.Context, orgID, id uuid.UUID, state domain.PartReservationState, now time.Time) error {
 	if r == nil || r.DB == nil {
 		return nil
@@ -92,3 +117,39 @@ func (r *PartReservationRepository) ReleaseByTask(ctx context.Context, orgID, ta
 	`, now, orgID, taskID)
 	return TranslateError(err)
 }
+
+func (r *PartReservationRepository) ListUnavailable(ctx context.Context, limit int) ([]domain.PartReservation, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT pr.id, pr.org_id, pr.task_id, pr.part_item_id, pr.state, pr.quantity, pr.created_at, pr.updated_at
+		FROM part_reservations pr
+		JOIN part_items pi ON pi.org_id = pr.org_id AND pi.id = pr.part_item_id
+		JOIN maintenance_tasks t ON t.org_id = pr.org_id AND t.id = pr.task_id
+		WHERE pr.state = 'reserved'
+		  AND pi.status <> 'in_stock'
+		  AND pi.deleted_at IS NULL
+		  AND t.state = 'scheduled'
+		  AND t.deleted_at IS NULL
+		ORDER BY t.start_time, pr.id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []domain.PartReservation
+	for rows.Next() {
+		var reservation domain.PartReservation
+		if err := rows.Scan(&reservation.ID, &reservation.OrgID, &reservation.TaskID, &reservation.PartItemID, &reservation.State, &reservation.Quantity, &reservation.CreatedAt, &reservation.UpdatedAt); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
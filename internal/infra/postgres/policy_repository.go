@@ -20,7 +20,7 @@ func (r *OrgPolicyRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) (
 		return domain.OrgPolicy{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, created_at, updated_at
+		SELECT org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, max_attachment_bytes, created_at, updated_at
 		FROM org_policies
 		WHERE org_id=$1
 	`, orgID)
@@ -33,18 +33,19 @@ func (r *OrgPolicyRepository) Upsert(ctx context.Context, policy domain.OrgPolic
 	}
 	row := r.DB.QueryRow(ctx, `
 		INSERT INTO org_policies
-			(org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, created_at, updated_at)
+			(org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, max_attachment_bytes, created_at, updated_at)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8)
+			($1,$2,$3,$4,$5,$6,$7,$8,$9)
 		ON CONFLICT (org_id) DO UPDATE
 		SET retention_interval=$2,
 			max_webhook_attempts=$3,
 			webhook_replay_window_seconds=$4,
 			api_rate_limit_per_min=$5,
 			api_key_rate_limit_per_min=$6,
-			updated_at=$8
-		RETURNING org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, created_at, updated_at
-	`, policy.OrgID, durationToInterval(policy.RetentionInterval), policy.MaxWebhookAttempts, policy.WebhookReplayWindowSeconds, policy.APIRateLimitPerMin, policy.APIKeyRateLimitPerMin, policy.CreatedAt, policy.UpdatedAt)
+			max_attachment_bytes=$7,
+			updated_at=$9
+		RETURNING org_id, retention_interval, max_webhook_attempts, webhook_replay_window_seconds, api_rate_limit_per_min, api_key_rate_limit_per_min, max_attachment_bytes, created_at, updated_at
+	`, policy.OrgID, durationToInterval(policy.RetentionInterval), policy.MaxWebhookAttempts, policy.WebhookReplayWindowSeconds, policy.APIRateLimitPerMin, policy.APIKeyRateLimitPerMin, policy.MaxAttachmentBytes, policy.CreatedAt, policy.UpdatedAt)
 	updated, err := scanPolicy(row)
 	if err != nil {
 		return domain.OrgPolicy{}, TranslateError(err)
@@ -55,7 +56,7 @@ func (r *OrgPolicyRepository) Upsert(ctx context.Context, policy domain.OrgPolic
 func scanPolicy(row pgx.Row) (domain.OrgPolicy, error) {
 	var policy domain.OrgPolicy
 	var retention pgtype.Interval
-	if err := row.Scan(&policy.OrgID, &retention, &policy.MaxWebhookAttempts, &policy.WebhookReplayWindowSeconds, &policy.APIRateLimitPerMin, &policy.APIKeyRateLimitPerMin, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
+	if err := row.Scan(&policy.OrgID, &retention, &policy.MaxWebhookAttempts, &policy.WebhookReplayWindowSeconds, &policy.APIRateLimitPerMin, &policy.APIKeyRateLimitPerMin, &policy.MaxAttachmentBytes, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.OrgPolicy{}, domain.ErrNotFound
 		}
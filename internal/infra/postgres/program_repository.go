@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -21,7 +22,7 @@ func (r *MaintenanceProgramRepository) GetByID(ctx context.Context, orgID, id uu
 		return domain.MaintenanceProgram{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
+		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at
 		FROM maintenance_programs
 		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
 	`, orgID, id)
@@ -33,7 +34,7 @@ func (r *MaintenanceProgramRepository) GetByName(ctx context.Context, orgID uuid
 		return domain.MaintenanceProgram{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
+		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at
 		FROM maintenance_programs
 		WHERE org_id=$1 AND name=$2 AND aircraft_id IS NOT DISTINCT FROM $3 AND deleted_at IS NULL
 	`, orgID, name, aircraftID)
@@ -44,13 +45,38 @@ func (r *MaintenanceProgramRepository) Create(ctx context.Context, program domai
 	if r == nil || r.DB == nil {
 		return domain.MaintenanceProgram{}, domain.ErrNotFound
 	}
-	row := r.DB.QueryRow(ctx, `
+	return insertProgram(ctx, r.DB, program)
+}
+
+func (r *MaintenanceProgramRepository) Update(ctx context.Context, program domain.MaintenanceProgram) (domain.MaintenanceProgram, error) {
+	if r == nil || r.DB == nil {
+		return domain.MaintenanceProgram{}, domain.ErrNotFound
+	}
+	return updateProgram(ctx, r.DB, program)
+}
+
+// programQuerier is satisfied by both the pool and a transaction, so
+// template propagation can write programs inside its own transaction.
+type programQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertProgram(ctx context.Context, q programQuerier, program domain.MaintenanceProgram) (domain.MaintenanceProgram, error) {
+	thresholds, err := marshalProgramThresholds(program.Thresholds)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	checklist, err := marshalProgramChecklist(program.ComplianceChecklist)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	row := q.QueryRow(ctx, `
 		INSERT INTO maintenance_programs
-			(id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at)
+			(id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
-		RETURNING id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
-	`, program.ID, program.OrgID, program.AircraftID, program.Name, program.IntervalType, program.IntervalValue, program.LastPerformed, program.CreatedAt, program.UpdatedAt, program.DeletedAt)
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21)
+		RETURNING id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at
+	`, program.ID, program.OrgID, program.AircraftID, program.Name, program.IntervalType, program.IntervalValue, program.LastPerformed, program.LastPerformedHours, program.LastPerformedCycles, program.TolerancePercent, thresholds, program.TemplateID, program.TemplateRevision, program.GeneratedTaskType(), program.GeneratedTaskPriority(), program.EstimatedDurationMinutes, checklist, program.DefaultNotes, program.CreatedAt, program.UpdatedAt, program.DeletedAt)
 	created, err := scanProgram(row)
 	if err != nil {
 		return domain.MaintenanceProgram{}, TranslateError(err)
@@ -58,16 +84,21 @@ func (r *MaintenanceProgramRepository) Create(ctx context.Context, program domai
 	return created, nil
 }
 
-func (r *MaintenanceProgramRepository) Update(ctx context.Context, program domain.MaintenanceProgram) (domain.MaintenanceProgram, error) {
-	if r == nil || r.DB == nil {
-		return domain.MaintenanceProgram{}, domain.ErrNotFound
+func updateProgram(ctx context.Context, q programQuerier, program domain.MaintenanceProgram) (domain.MaintenanceProgram, error) {
+	thresholds, err := marshalProgramThresholds(program.Thresholds)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
 	}
-	row := r.DB.QueryRow(ctx, `
+	checklist, err := marshalProgramChecklist(program.ComplianceChecklist)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	row := q.QueryRow(ctx, `
 		UPDATE maintenance_programs
-		SET aircraft_id=$1, name=$2, interval_type=$3, interval_value=$4, last_performed=$5, updated_at=$6
-		WHERE org_id=$7 AND id=$8 AND deleted_at IS NULL
-		RETURNING id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
-	`, program.AircraftID, program.Name, program.IntervalType, program.IntervalValue, program.LastPerformed, program.UpdatedAt, program.OrgID, program.ID)
+		SET aircraft_id=$1, name=$2, interval_type=$3, interval_value=$4, last_performed=$5, last_performed_hours=$6, last_performed_cycles=$7, tolerance_percent=$8, thresholds=$9, template_id=$10, template_revision=$11, task_type=$12, priority=$13, estimated_duration_minutes=$14, compliance_checklist=$15, default_notes=$16, updated_at=$17
+		WHERE org_id=$18 AND id=$19 AND deleted_at IS NULL
+		RETURNING id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at
+	`, program.AircraftID, program.Name, program.IntervalType, program.IntervalValue, program.LastPerformed, program.LastPerformedHours, program.LastPerformedCycles, program.TolerancePercent, thresholds, program.TemplateID, program.TemplateRevision, program.GeneratedTaskType(), program.GeneratedTaskPriority(), program.EstimatedDurationMinutes, checklist, program.DefaultNotes, program.UpdatedAt, program.OrgID, program.ID)
 	updated, err := scanProgram(row)
 	if err != nil {
 		return domain.MaintenanceProgram{}, TranslateError(err)
@@ -109,6 +140,9 @@ func (r *MaintenanceProgramRepository) List(ctx context.Context, filter ports.Ma
 	if filter.AircraftID != nil {
 		add("aircraft_id=", *filter.AircraftID)
 	}
+	if filter.TemplateID != nil {
+		add("template_id=", *filter.TemplateID)
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -123,7 +157,7 @@ func (r *MaintenanceProgramRepository) List(ctx context.Context, filter ports.Ma
 	}
 
 	query := `
-		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
+		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, last_performed_hours, last_performed_cycles, tolerance_percent, thresholds, template_id, template_revision, task_type, priority, estimated_duration_minutes, compliance_checklist, default_notes, created_at, updated_at, deleted_at
 		FROM maintenance_programs
 		WHERE deleted_at IS NULL`
 	if len(clauses) > 0 {
@@ -149,7 +183,11 @@ func (r *MaintenanceProgramRepository) List(ctx context.Context, filter ports.Ma
 	return programs, rows.Err()
 }
 
-func (r *MaintenanceProgramRepository) ListDueCalendar(ctx context.Context, now time.Time, limit int) ([]domain.MaintenanceProgram, error) {
+// ListDue returns aircraft-bound programs with any threshold that is due, or
+// will be within the look-ahead window, using the calendar or the aircraft's
+// usage totals. Programs that already have a scheduled or in-progress task
+// are left out, so they cannot crowd newer due programs out of the page.
+func (r *MaintenanceProgramRepository) ListDue(ctx context.Context, now time.Time, lookAhead domain.ProgramLookAhead, limit int) ([]domain.MaintenanceProgram, error) {
 	if r == nil || r.DB == nil {
 		return nil, nil
 	}
@@ -157,18 +195,36 @@ func (r *MaintenanceProgramRepository) ListDueCalendar(ctx context.Context, now
 		limit = 100
 	}
 	rows, err := r.DB.Query(ctx, `
-		SELECT id, org_id, aircraft_id, name, interval_type, interval_value, last_performed, created_at, updated_at, deleted_at
-		FROM maintenance_programs
-		WHERE interval_type='calendar'
-		  AND deleted_at IS NULL
-		  AND aircraft_id IS NOT NULL
-		  AND (
-		    last_performed IS NULL
-		    OR last_performed + (interval_value || ' days')::interval <= $1
+		SELECT p.id, p.org_id, p.aircraft_id, p.name, p.interval_type, p.interval_value, p.last_performed, p.last_performed_hours, p.last_performed_cycles, p.tolerance_percent, p.thresholds, p.template_id, p.template_revision, p.task_type, p.priority, p.estimated_duration_minutes, p.compliance_checklist, p.default_notes, p.created_at, p.updated_at, p.deleted_at
+		FROM maintenance_programs p
+		JOIN aircraft a ON a.org_id=p.org_id AND a.id=p.aircraft_id AND a.deleted_at IS NULL
+		WHERE p.deleted_at IS NULL
+		  AND NOT EXISTS (
+		    SELECT 1
+		    FROM maintenance_tasks t
+		    WHERE t.org_id=p.org_id AND t.program_id=p.id AND t.deleted_at IS NULL
+		      AND t.state IN ('scheduled','in_progress')
+		  )
+		  AND EXISTS (
+		    SELECT 1
+		    FROM (
+		      SELECT p.interval_type::text AS interval_type, p.interval_value AS interval_value
+		      UNION ALL
+		      SELECT t->>'interval_type', (t->>'interval_value')::int
+		      FROM jsonb_array_elements(p.thresholds) t
+		    ) th
+		    WHERE (th.interval_type='calendar' AND (
+		        p.last_performed IS NULL
+		        OR p.last_performed + make_interval(days => th.interval_value - $2) <= $1
+		      ))
+		      OR (th.interval_type='flight_hours'
+		        AND a.flight_hours_total >= COALESCE(p.last_performed_hours, 0) + th.interval_value - $3)
+		      OR (th.interval_type='cycles'
+		        AND a.cycles_total >= COALESCE(p.last_performed_cycles, 0) + th.interval_value - $4)
 		  )
-		ORDER BY created_at ASC
-		LIMIT $2
-	`, now, limit)
+		ORDER BY p.created_at ASC
+		LIMIT $5
+	`, now, lookAhead.Days, lookAhead.FlightHours, lookAhead.Cycles, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -185,11 +241,64 @@ func (r *MaintenanceProgramRepository) ListDueCalendar(ctx context.Context, now
 	return programs, rows.Err()
 }
 
+// programThresholdRecord is the jsonb representation of a ProgramThreshold.
+type programThresholdRecord struct {
+	IntervalType     domain.MaintenanceProgramIntervalType `json:"interval_type"`
+	IntervalValue    int                                   `json:"interval_value"`
+	TolerancePercent int                                   `json:"tolerance_percent"`
+}
+
+func marshalProgramThresholds(thresholds []domain.ProgramThreshold) ([]byte, error) {
+	records := make([]programThresholdRecord, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		records = append(records, programThresholdRecord(threshold))
+	}
+	return json.Marshal(records)
+}
+
+func unmarshalProgramThresholds(data []byte) ([]domain.ProgramThreshold, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var records []programThresholdRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	var thresholds []domain.ProgramThreshold
+	for _, record := range records {
+		thresholds = append(thresholds, domain.ProgramThreshold(record))
+	}
+	return thresholds, nil
+}
+
+func marshalProgramChecklist(items []string) ([]byte, error) {
+	if items == nil {
+		items = []string{}
+	}
+	return json.Marshal(items)
+}
+
+func unmarshalProgramChecklist(data []byte) ([]string, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items, nil
+}
+
 func scanProgram(row pgx.Row) (domain.MaintenanceProgram, error) {
 	var program domain.MaintenanceProgram
 	var aircraftID *uuid.UUID
 	var lastPerformed *time.Time
-	if err := row.Scan(&program.ID, &program.OrgID, &aircraftID, &program.Name, &program.IntervalType, &program.IntervalValue, &lastPerformed, &program.CreatedAt, &program.UpdatedAt, &program.DeletedAt); err != nil {
+	var thresholdsJSON []byte
+	var checklistJSON []byte
+	if err := row.Scan(&program.ID, &program.OrgID, &aircraftID, &program.Name, &program.IntervalType, &program.IntervalValue, &lastPerformed, &program.LastPerformedHours, &program.LastPerformedCycles, &program.TolerancePercent, &thresholdsJSON, &program.TemplateID, &program.TemplateRevision, &program.TaskType, &program.Priority, &program.EstimatedDurationMinutes, &checklistJSON, &program.DefaultNotes, &program.CreatedAt, &program.UpdatedAt, &program.DeletedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.MaintenanceProgram{}, domain.ErrNotFound
 		}
@@ -197,5 +306,15 @@ func scanProgram(row pgx.Row) (domain.MaintenanceProgram, error) {
 	}
 	program.AircraftID = aircraftID
 	program.LastPerformed = lastPerformed
+	thresholds, err := unmarshalProgramThresholds(thresholdsJSON)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	program.Thresholds = thresholds
+	checklist, err := unmarshalProgramChecklist(checklistJSON)
+	if err != nil {
+		return domain.MaintenanceProgram{}, err
+	}
+	program.ComplianceChecklist = checklist
 	return program, nil
 }
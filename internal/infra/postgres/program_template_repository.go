@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MaintenanceProgramTemplateRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *MaintenanceProgramTemplateRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.MaintenanceProgramTemplate, error) {
+	if r == nil || r.DB == nil {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, aircraft_type_id, name, interval_type, interval_value, tolerance_percent, thresholds, revision, created_at, updated_at, deleted_at
+		FROM maintenance_program_templates
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id)
+	return scanProgramTemplate(row)
+}
+
+func (r *MaintenanceProgramTemplateRepository) Create(ctx context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	return r.CreateWithInstances(ctx, template, nil)
+}
+
+func (r *MaintenanceProgramTemplateRepository) CreateWithInstances(ctx context.Context, template domain.MaintenanceProgramTemplate, instances []domain.MaintenanceProgram) (domain.MaintenanceProgramTemplate, error) {
+	if r == nil || r.DB == nil {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	thresholds, err := marshalProgramThresholds(template.Thresholds)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO maintenance_program_templates
+			(id, org_id, aircraft_type_id, name, interval_type, interval_value, tolerance_percent, thresholds, revision, created_at, updated_at, deleted_at)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		RETURNING id, org_id, aircraft_type_id, name, interval_type, interval_value, tolerance_percent, thresholds, revision, created_at, updated_at, deleted_at
+	`, template.ID, template.OrgID, template.AircraftTypeID, template.Name, template.IntervalType, template.IntervalValue, template.TolerancePercent, thresholds, template.Revision, template.CreatedAt, template.UpdatedAt, template.DeletedAt)
+	created, err := scanProgramTemplate(row)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, TranslateError(err)
+	}
+	for _, program := range instances {
+		if _, err := insertProgram(ctx, tx, program); err != nil {
+			return domain.MaintenanceProgramTemplate{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	return created, nil
+}
+
+func (r *MaintenanceProgramTemplateRepository) Update(ctx context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	if r == nil || r.DB == nil {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	thresholds, err := marshalProgramThresholds(template.Thresholds)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE maintenance_program_templates
+		SET name=$1, interval_type=$2, interval_value=$3, tolerance_percent=$4, thresholds=$5, revision=$6, updated_at=$7
+		WHERE org_id=$8 AND id=$9 AND deleted_at IS NULL
+		RETURNING id, org_id, aircraft_type_id, name, interval_type, interval_value, tolerance_percent, thresholds, revision, created_at, updated_at, deleted_at
+	`, template.Name, template.IntervalType, template.IntervalValue, template.TolerancePercent, thresholds, template.Revision, template.UpdatedAt, template.OrgID, template.ID)
+	updated, err := scanProgramTemplate(row)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *MaintenanceProgramTemplateRepository) Propagate(ctx context.Context, template domain.MaintenanceProgramTemplate, created, updated []domain.MaintenanceProgram) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return TranslateError(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var revision int
+	if err := tx.QueryRow(ctx, `
+		SELECT revision
+		FROM maintenance_program_templates
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, template.OrgID, template.ID).Scan(&revision); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ErrNotFound
+		}
+		return TranslateError(err)
+	}
+	if revision != template.Revision {
+		return domain.NewConflictError("template revision has changed since the preview")
+	}
+	for _, program := range created {
+		if _, err := insertProgram(ctx, tx, program); err != nil {
+			return err
+		}
+	}
+	for _, program := range updated {
+		if _, err := updateProgram(ctx, tx, program); err != nil {
+			return err
+		}
+	}
+	return TranslateError(tx.Commit(ctx))
+}
+
+func (r *MaintenanceProgramTemplateRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE maintenance_program_templates
+		SET deleted_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL
+	`, at, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MaintenanceProgramTemplateRepository) List(ctx context.Context, filter ports.MaintenanceProgramTemplateFilter) ([]domain.MaintenanceProgramTemplate, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.AircraftTypeID != nil {
+		add("aircraft_type_id=", *filter.AircraftTypeID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, aircraft_type_id, name, interval_type, interval_value, tolerance_percent, thresholds, revision, created_at, updated_at, deleted_at
+		FROM maintenance_program_templates
+		WHERE deleted_at IS NULL`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY name ASC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []domain.MaintenanceProgramTemplate
+	for rows.Next() {
+		template, err := scanProgramTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func scanProgramTemplate(row pgx.Row) (domain.MaintenanceProgramTemplate, error) {
+	var template domain.MaintenanceProgramTemplate
+	var thresholdsJSON []byte
+	if err := row.Scan(&template.ID, &template.OrgID, &template.AircraftTypeID, &template.Name, &template.IntervalType, &template.IntervalValue, &template.TolerancePercent, &thresholdsJSON, &template.Revision, &template.CreatedAt, &template.UpdatedAt, &template.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+		}
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	thresholds, err := unmarshalProgramThresholds(thresholdsJSON)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	template.Thresholds = thresholds
+	return template, nil
+}
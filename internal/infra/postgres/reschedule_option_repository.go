@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RescheduleOptionRepository struct {
+	DB *pgxpool.Pool
+}
+
+const rescheduleOptionColumns = `id, org_id, task_id, reservation_id, part_definition_id, rank, state, option_type, description,
+		       new_start_time, new_end_time, affected_task_ids, substitute_part_id, task_updated_at, schedule_change_id,
+		       created_at, updated_at, applied_at`
+
+func (r *RescheduleOptionRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.RescheduleOption, error) {
+	if r == nil || r.DB == nil {
+		return domain.RescheduleOption{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT `+rescheduleOptionColumns+`
+		FROM reschedule_options
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanRescheduleOption(row)
+}
+
+func (r *RescheduleOptionRepository) ListByTask(ctx context.Context, orgID, taskID uuid.UUID, state *domain.RescheduleOptionState) ([]domain.RescheduleOption, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	query := `
+		SELECT ` + rescheduleOptionColumns + `
+		FROM reschedule_options
+		WHERE org_id=$1 AND task_id=$2`
+	args := []any{orgID, taskID}
+	if state != nil {
+		args = append(args, *state)
+		query += " AND state=$" + itoa(len(args))
+	}
+	query += " ORDER BY created_at DESC, rank ASC"
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var options []domain.RescheduleOption
+	for rows.Next() {
+		option, err := scanRescheduleOption(rows)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+	return options, rows.Err()
+}
+
+func (r *RescheduleOptionRepository) Replace(ctx context.Context, orgID, taskID uuid.UUID, options []domain.RescheduleOption, now time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE reschedule_options
+		SET state='discarded', updated_at=$1
+		WHERE org_id=$2 AND task_id=$3 AND state='proposed'
+	`, now, orgID, taskID); err != nil {
+		return TranslateError(err)
+	}
+	for _, option := range options {
+		affected := option.AffectedTasks
+		if affected == nil {
+			affected = []uuid.UUID{}
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO reschedule_options
+				(id, org_id, task_id, reservation_id, part_definition_id, rank, state, option_type, description,
+				 new_start_time, new_end_time, affected_task_ids, substitute_part_id, task_updated_at, created_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		`, option.ID, orgID, taskID, option.ReservationID, option.PartDefinitionID, option.Rank, option.State, option.OptionType, option.Description,
+			option.NewStartTime, option.NewEndTime, affected, option.SubstitutePart, option.TaskUpdatedAt, option.CreatedAt, option.UpdatedAt); err != nil {
+			return TranslateError(err)
+		}
+	}
+	return TranslateError(tx.Commit(ctx))
+}
+
+func (r *RescheduleOptionRepository) Apply(ctx context.Context, option domain.RescheduleOption, change ports.RescheduleOptionChange, now time.Time) (domain.ScheduleChangeEvent, error) {
+	if r == nil || r.DB == nil {
+		return domain.ScheduleChangeEvent{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// A swap moves each task into the other's old window, so overlaps are
+	// only checked once both have moved.
+	if _, err := tx.Exec(ctx, `SET CONSTRAINTS maintenance_tasks_no_overlap, maintenance_tasks_bay_slot_no_overlap DEFERRED`); err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	cmd, err := tx.Exec(ctx, `
+		UPDATE reschedule_options
+		SET state='applied', schedule_change_id=$1, applied_at=$2, updated_at=$2
+		WHERE org_id=$3 AND id=$4 AND state='proposed'
+	`, change.Event.ID, now, option.OrgID, option.ID)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ScheduleChangeEvent{}, domain.NewConflictError("reschedule option is no longer proposed")
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE reschedule_options
+		SET state='discarded', updated_at=$1
+		WHERE org_id=$2 AND task_id=$3 AND state='proposed'
+	`, now, option.OrgID, option.TaskID); err != nil {
+		return domain.ScheduleChangeEvent{}, TranslateError(err)
+	}
+
+	for _, task := range change.Moved {
+		if err := moveTask(ctx, tx, task, now); err != nil {
+			return domain.ScheduleChangeEvent{}, err
+		}
+	}
+	if task := change.Continuation; task != nil {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO maintenance_tasks
+				(id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, created_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		`, task.ID, task.OrgID, task.AircraftID, task.ProgramID, task.WorkPackageID, task.BayID, task.BaySlot, task.Type, task.State, taskPriority(*task),
+			task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.CreatedAt, task.UpdatedAt); err != nil {
+			return domain.ScheduleChangeEvent{}, TranslateError(err)
+		}
+		if option.ReservationID != nil {
+			if err := updateReservation(ctx, tx, option.OrgID, *option.ReservationID, `task_id=$1`, task.ID, now); err != nil {
+				return domain.ScheduleChangeEvent{}, err
+			}
+		}
+	}
+	if reservation := change.Substitute; reservation != nil {
+		if option.ReservationID != nil {
+			if err := updateReservation(ctx, tx, option.OrgID, *option.ReservationID, `state=$1`, domain.ReservationReleased, now); err != nil {
+				return domain.ScheduleChangeEvent{}, err
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO part_reservations (id, org_id, task_id, part_item_id, state, quantity, created_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		`, reservation.ID, reservation.OrgID, reservation.TaskID, reservation.PartItemID, reservation.State, reservation.Quantity, reservation.CreatedAt, reservation.UpdatedAt); err != nil {
+			return domain.ScheduleChangeEvent{}, TranslateError(err)
+		}
+	}
+
+	event := change.Event
+	created, err := scanScheduleChange(tx.QueryRow(ctx, insertScheduleChange, event.ID, event.OrgID, event.TaskID, event.ChangeType, event.Reason,
+		event.OldStartTime, event.NewStartTime, event.OldEndTime, event.NewEndTime,
+		event.TriggeredBy, event.AffectedTaskIDs, event.CreatedAt))
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.ScheduleChangeEvent{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+// updateReservation sets one column of a reservation that is still held.
+func updateReservation(ctx context.Context, tx pgx.Tx, orgID, id uuid.UUID, set string, value any, now time.Time) error {
+	cmd, err := tx.Exec(ctx, `
+		UPDATE part_reservations
+		SET `+set+`, updated_at=$2
+		WHERE org_id=$3 AND id=$4 AND state='reserved'
+	`, value, now, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.NewConflictError(fmt.Sprintf("reservation %s is no longer held", id))
+	}
+	return nil
+}
+
+func scanRescheduleOption(row pgx.Row) (domain.RescheduleOption, error) {
+	var option domain.RescheduleOption
+	if err := row.Scan(&option.ID, &option.OrgID, &option.TaskID, &option.ReservationID, &option.PartDefinitionID, &option.Rank,
+		&option.State, &option.OptionType, &option.Description, &option.NewStartTime, &option.NewEndTime, &option.AffectedTasks,
+		&option.SubstitutePart, &option.TaskUpdatedAt, &option.ScheduleChangeID, &option.CreatedAt, &option.UpdatedAt, &option.AppliedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.RescheduleOption{}, domain.ErrNotFound
+		}
+		return domain.RescheduleOption{}, err
+	}
+	return option, nil
+}
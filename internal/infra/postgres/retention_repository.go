@@ -56,6 +56,22 @@ func (r *RetentionRepository) Cleanup(ctx context.Context, orgID uuid.UUID, cuto
 				SELECT 1 FROM compliance_items
 				WHERE org_id=$1 AND task_id=maintenance_tasks.id
 			)
+			AND NOT EXISTS (
+				SELECT 1 FROM task_cards
+				WHERE org_id=$1 AND task_id=maintenance_tasks.id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM labor_entries
+				WHERE org_id=$1 AND task_id=maintenance_tasks.id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM defects
+				WHERE org_id=$1 AND rectification_task_id=maintenance_tasks.id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM findings
+				WHERE org_id=$1 AND (task_id=maintenance_tasks.id OR repair_task_id=maintenance_tasks.id)
+			)
 	`, orgID, cutoff)
 	if err != nil {
 		return stats, err
@@ -0,0 +1,385 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShiftPatternRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *ShiftPatternRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.ShiftPattern, error) {
+	if r == nil || r.DB == nil {
+		return domain.ShiftPattern{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, name, timezone, start_minute, duration_minutes, weekdays, created_at, updated_at, deleted_at
+		FROM shift_patterns
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id)
+	return scanShiftPattern(row)
+}
+
+func (r *ShiftPatternRepository) Create(ctx context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error) {
+	if r == nil || r.DB == nil {
+		return domain.ShiftPattern{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO shift_patterns
+			(id, org_id, name, timezone, start_minute, duration_minutes, weekdays, created_at, updated_at, deleted_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		RETURNING id, org_id, name, timezone, start_minute, duration_minutes, weekdays, created_at, updated_at, deleted_at
+	`, pattern.ID, pattern.OrgID, pattern.Name, pattern.Timezone, pattern.StartMinute, pattern.DurationMinutes,
+		int16(pattern.Weekdays), pattern.CreatedAt, pattern.UpdatedAt, pattern.DeletedAt)
+	created, err := scanShiftPattern(row)
+	if err != nil {
+		return domain.ShiftPattern{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *ShiftPatternRepository) Update(ctx context.Context, pattern domain.ShiftPattern) (domain.ShiftPattern, error) {
+	if r == nil || r.DB == nil {
+		return domain.ShiftPattern{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE shift_patterns
+		SET name=$1, timezone=$2, start_minute=$3, duration_minutes=$4, weekdays=$5, updated_at=$6
+		WHERE org_id=$7 AND id=$8 AND deleted_at IS NULL
+		RETURNING id, org_id, name, timezone, start_minute, duration_minutes, weekdays, created_at, updated_at, deleted_at
+	`, pattern.Name, pattern.Timezone, pattern.StartMinute, pattern.DurationMinutes, int16(pattern.Weekdays),
+		pattern.UpdatedAt, pattern.OrgID, pattern.ID)
+	updated, err := scanShiftPattern(row)
+	if err != nil {
+		return domain.ShiftPattern{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+// SoftDelete deletes the pattern unless a current or future assignment
+// still uses it.
+func (r *ShiftPatternRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var marker int
+	err = tx.QueryRow(ctx, `
+		SELECT 1 FROM roster_assignments
+		WHERE org_id=$1 AND shift_pattern_id=$2 AND (effective_to IS NULL OR effective_to > $3)
+		LIMIT 1
+	`, orgID, id, at).Scan(&marker)
+	if err == nil {
+		return domain.NewConflictError("shift pattern is still assigned")
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+	cmd, err := tx.Exec(ctx, `
+		UPDATE shift_patterns
+		SET deleted_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL
+	`, at, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *ShiftPatternRepository) List(ctx context.Context, filter ports.ShiftPatternFilter) ([]domain.ShiftPattern, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 1)
+	args := make([]any, 0, 3)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, name, timezone, start_minute, duration_minutes, weekdays, created_at, updated_at, deleted_at
+		FROM shift_patterns
+		WHERE deleted_at IS NULL`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY name ASC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []domain.ShiftPattern
+	for rows.Next() {
+		pattern, err := scanShiftPattern(rows)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, rows.Err()
+}
+
+func scanShiftPattern(row pgx.Row) (domain.ShiftPattern, error) {
+	var pattern domain.ShiftPattern
+	var weekdays int16
+	if err := row.Scan(&pattern.ID, &pattern.OrgID, &pattern.Name, &pattern.Timezone, &pattern.StartMinute, &pattern.DurationMinutes,
+		&weekdays, &pattern.CreatedAt, &pattern.UpdatedAt, &pattern.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.ShiftPattern{}, domain.ErrNotFound
+		}
+		return domain.ShiftPattern{}, err
+	}
+	pattern.Weekdays = domain.WeekdaySet(weekdays)
+	return pattern, nil
+}
+
+type RosterAssignmentRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *RosterAssignmentRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.RosterAssignment, error) {
+	if r == nil || r.DB == nil {
+		return domain.RosterAssignment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, mechanic_id, shift_pattern_id, station_id, effective_from, effective_to, created_at
+		FROM roster_assignments
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanRosterAssignment(row)
+}
+
+func (r *RosterAssignmentRepository) Create(ctx context.Context, assignment domain.RosterAssignment) (domain.RosterAssignment, error) {
+	if r == nil || r.DB == nil {
+		return domain.RosterAssignment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO roster_assignments
+			(id, org_id, mechanic_id, shift_pattern_id, station_id, effective_from, effective_to, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id, org_id, mechanic_id, shift_pattern_id, station_id, effective_from, effective_to, created_at
+	`, assignment.ID, assignment.OrgID, assignment.MechanicID, assignment.ShiftPatternID, assignment.StationID,
+		assignment.EffectiveFrom, assignment.EffectiveTo, assignment.CreatedAt)
+	created, err := scanRosterAssignment(row)
+	if err != nil {
+		return domain.RosterAssignment{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *RosterAssignmentRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM roster_assignments
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RosterAssignmentRepository) List(ctx context.Context, filter ports.RosterAssignmentFilter) ([]domain.RosterAssignment, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 5)
+	args := make([]any, 0, 7)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.MechanicID != nil {
+		add("mechanic_id=", *filter.MechanicID)
+	}
+	if filter.StationID != nil {
+		add("station_id=", *filter.StationID)
+	}
+	if filter.ActiveFrom != nil {
+		args = append(args, *filter.ActiveFrom)
+		clauses = append(clauses, "(effective_to IS NULL OR effective_to > $"+itoa(len(args))+")")
+	}
+	if filter.ActiveTo != nil {
+		add("effective_from < ", *filter.ActiveTo)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, mechanic_id, shift_pattern_id, station_id, effective_from, effective_to, created_at
+		FROM roster_assignments`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY effective_from ASC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []domain.RosterAssignment
+	for rows.Next() {
+		assignment, err := scanRosterAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+func scanRosterAssignment(row pgx.Row) (domain.RosterAssignment, error) {
+	var assignment domain.RosterAssignment
+	if err := row.Scan(&assignment.ID, &assignment.OrgID, &assignment.MechanicID, &assignment.ShiftPatternID, &assignment.StationID,
+		&assignment.EffectiveFrom, &assignment.EffectiveTo, &assignment.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.RosterAssignment{}, domain.ErrNotFound
+		}
+		return domain.RosterAssignment{}, err
+	}
+	return assignment, nil
+}
+
+type MechanicAbsenceRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *MechanicAbsenceRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.MechanicAbsence, error) {
+	if r == nil || r.DB == nil {
+		return domain.MechanicAbsence{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, mechanic_id, kind, start_time, end_time, reason, created_at
+		FROM mechanic_absences
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	return scanMechanicAbsence(row)
+}
+
+func (r *MechanicAbsenceRepository) Create(ctx context.Context, absence domain.MechanicAbsence) (domain.MechanicAbsence, error) {
+	if r == nil || r.DB == nil {
+		return domain.MechanicAbsence{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO mechanic_absences (id, org_id, mechanic_id, kind, start_time, end_time, reason, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id, org_id, mechanic_id, kind, start_time, end_time, reason, created_at
+	`, absence.ID, absence.OrgID, absence.MechanicID, absence.Kind, absence.StartTime, absence.EndTime, absence.Reason, absence.CreatedAt)
+	created, err := scanMechanicAbsence(row)
+	if err != nil {
+		return domain.MechanicAbsence{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *MechanicAbsenceRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM mechanic_absences
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MechanicAbsenceRepository) ListOverlapping(ctx context.Context, orgID uuid.UUID, mechanicID *uuid.UUID, from, to time.Time) ([]domain.MechanicAbsence, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, mechanic_id, kind, start_time, end_time, reason, created_at
+		FROM mechanic_absences
+		WHERE org_id=$1 AND ($2::uuid IS NULL OR mechanic_id=$2) AND start_time < $4 AND end_time > $3
+		ORDER BY start_time ASC, id ASC
+	`, orgID, mechanicID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var absences []domain.MechanicAbsence
+	for rows.Next() {
+		absence, err := scanMechanicAbsence(rows)
+		if err != nil {
+			return nil, err
+		}
+		absences = append(absences, absence)
+	}
+	return absences, rows.Err()
+}
+
+func scanMechanicAbsence(row pgx.Row) (domain.MechanicAbsence, error) {
+	var absence domain.MechanicAbsence
+	if err := row.Scan(&absence.ID, &absence.OrgID, &absence.MechanicID, &absence.Kind, &absence.StartTime, &absence.EndTime,
+		&absence.Reason, &absence.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.MechanicAbsence{}, domain.ErrNotFound
+		}
+		return domain.MechanicAbsence{}, err
+	}
+	return absence, nil
+}
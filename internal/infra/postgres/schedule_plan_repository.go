@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SchedulePlanRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *SchedulePlanRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.SchedulePlan, error) {
+	if r == nil || r.DB == nil {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, state, horizon_start, horizon_end, station_id, created_by, created_at, updated_at, applied_at
+		FROM schedule_plans
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	plan, err := scanSchedulePlan(row)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT task_id, aircraft_id, priority, task_updated_at, old_start_time, old_end_time, old_bay_id, old_mechanic_id,
+		       start_time, end_time, bay_id, bay_slot, mechanic_id, reason
+		FROM schedule_plan_items
+		WHERE org_id=$1 AND plan_id=$2
+		ORDER BY position
+	`, orgID, id)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.SchedulePlanItem
+		if err := rows.Scan(&item.TaskID, &item.AircraftID, &item.Priority, &item.TaskUpdatedAt, &item.OldStartTime, &item.OldEndTime,
+			&item.OldBayID, &item.OldMechanicID, &item.StartTime, &item.EndTime, &item.BayID, &item.BaySlot, &item.MechanicID, &item.Reason); err != nil {
+			return domain.SchedulePlan{}, err
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	return plan, rows.Err()
+}
+
+func (r *SchedulePlanRepository) Create(ctx context.Context, plan domain.SchedulePlan) (domain.SchedulePlan, error) {
+	if r == nil || r.DB == nil {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO schedule_plans
+			(id, org_id, state, horizon_start, horizon_end, station_id, created_by, created_at, updated_at, applied_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	`, plan.ID, plan.OrgID, plan.State, plan.HorizonStart, plan.HorizonEnd, plan.StationID, plan.CreatedBy, plan.CreatedAt, plan.UpdatedAt, plan.AppliedAt); err != nil {
+		return domain.SchedulePlan{}, TranslateError(err)
+	}
+	for i, item := range plan.Items {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO schedule_plan_items
+				(plan_id, org_id, task_id, position, aircraft_id, priority, task_updated_at, old_start_time, old_end_time,
+				 old_bay_id, old_mechanic_id, start_time, end_time, bay_id, bay_slot, mechanic_id, reason)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		`, plan.ID, plan.OrgID, item.TaskID, i, item.AircraftID, item.Priority, item.TaskUpdatedAt, item.OldStartTime, item.OldEndTime,
+			item.OldBayID, item.OldMechanicID, item.StartTime, item.EndTime, item.BayID, item.BaySlot, item.MechanicID, item.Reason); err != nil {
+			return domain.SchedulePlan{}, TranslateError(err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.SchedulePlan{}, TranslateError(err)
+	}
+	return r.GetByID(ctx, plan.OrgID, plan.ID)
+}
+
+func (r *SchedulePlanRepository) List(ctx context.Context, filter ports.SchedulePlanFilter) ([]domain.SchedulePlan, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 2)
+	args := make([]any, 0, 4)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.State != nil {
+		add("state=", *filter.State)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, state, horizon_start, horizon_end, station_id, created_by, created_at, updated_at, applied_at
+		FROM schedule_plans`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY created_at DESC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []domain.SchedulePlan
+	for rows.Next() {
+		plan, err := scanSchedulePlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+func (r *SchedulePlanRepository) Discard(ctx context.Context, orgID, id uuid.UUID, now time.Time) (domain.SchedulePlan, error) {
+	if r == nil || r.DB == nil {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE schedule_plans
+		SET state='discarded', updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND state='proposed'
+		RETURNING id, org_id, state, horizon_start, horizon_end, station_id, created_by, created_at, updated_at, applied_at
+	`, now, orgID, id)
+	plan, err := scanSchedulePlan(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, getErr := r.GetByID(ctx, orgID, id); getErr != nil {
+			return domain.SchedulePlan{}, getErr
+		}
+		return domain.SchedulePlan{}, domain.NewConflictError("schedule plan is no longer proposed")
+	}
+	if err != nil {
+		return domain.SchedulePlan{}, TranslateError(err)
+	}
+	return plan, nil
+}
+
+func (r *SchedulePlanRepository) Apply(ctx context.Context, plan domain.SchedulePlan, actorID uuid.UUID, now time.Time) (domain.SchedulePlan, error) {
+	if r == nil || r.DB == nil {
+		return domain.SchedulePlan{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Tasks may trade windows or bay slots with each other, so overlaps are
+	// only checked once every task has moved.
+	if _, err := tx.Exec(ctx, `SET CONSTRAINTS maintenance_tasks_no_overlap, maintenance_tasks_bay_slot_no_overlap DEFERRED`); err != nil {
+		return domain.SchedulePlan{}, err
+	}
+	cmd, err := tx.Exec(ctx, `
+		UPDATE schedule_plans
+		SET state='applied', applied_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND state='proposed'
+	`, now, plan.OrgID, plan.ID)
+	if err != nil {
+		return domain.SchedulePlan{}, TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.SchedulePlan{}, domain.NewConflictError("schedule plan is no longer proposed")
+	}
+
+	reason := "schedule plan " + plan.ID.String()
+	for _, item := range plan.Items {
+		if !item.Placed() {
+			continue
+		}
+		cmd, err := tx.Exec(ctx, `
+			UPDATE maintenance_tasks
+			SET start_time=$1, end_time=$2, bay_id=$3, bay_slot=$4, assigned_mechanic_id=$5, updated_at=$6
+			WHERE org_id=$7 AND id=$8 AND updated_at=$9 AND state='scheduled' AND deleted_at IS NULL
+		`, *item.StartTime, *item.EndTime, item.BayID, item.BaySlot, item.MechanicID, now, plan.OrgID, item.TaskID, item.TaskUpdatedAt)
+		if err != nil {
+			return domain.SchedulePlan{}, TranslateError(err)
+		}
+		if cmd.RowsAffected() == 0 {
+			return domain.SchedulePlan{}, domain.NewConflictError(fmt.Sprintf("task %s changed since the plan was made", item.TaskID))
+		}
+		if item.Moved() {
+			if err := insertPlanChange(ctx, tx, plan.OrgID, item, domain.ScheduleChangeRescheduled, reason, actorID, now); err != nil {
+				return domain.SchedulePlan{}, err
+			}
+		}
+		if item.Reassigned() {
+			if err := insertPlanChange(ctx, tx, plan.OrgID, item, domain.ScheduleChangeMechanicReassigned, reason, actorID, now); err != nil {
+				return domain.SchedulePlan{}, err
+			}
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.SchedulePlan{}, TranslateError(err)
+	}
+	return r.GetByID(ctx, plan.OrgID, plan.ID)
+}
+
+func insertPlanChange(ctx context.Context, tx pgx.Tx, orgID uuid.UUID, item domain.SchedulePlanItem, changeType domain.ScheduleChangeType, reason string, actorID uuid.UUID, now time.Time) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO schedule_change_events
+			(id, org_id, task_id, change_type, reason, old_start_time, new_start_time,
+			 old_end_time, new_end_time, triggered_by, affected_task_ids, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+	`, uuid.New(), orgID, item.TaskID, changeType, reason, item.OldStartTime, *item.StartTime,
+		item.OldEndTime, *item.EndTime, actorID, []uuid.UUID{}, now)
+	return TranslateError(err)
+}
+
+func scanSchedulePlan(row pgx.Row) (domain.SchedulePlan, error) {
+	var plan domain.SchedulePlan
+	if err := row.Scan(&plan.ID, &plan.OrgID, &plan.State, &plan.HorizonStart, &plan.HorizonEnd, &plan.StationID,
+		&plan.CreatedBy, &plan.CreatedAt, &plan.UpdatedAt, &plan.AppliedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.SchedulePlan{}, domain.ErrNotFound
+		}
+		return domain.SchedulePlan{}, err
+	}
+	return plan, nil
+}
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/aeromaintain/amss/internal/app/ports"
 	"github.com/aeromaintain/amss/internal/domain"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -70,6 +72,20 @@ func (r *TaskDependencyRepository) ListDependents(ctx context.Context, orgID, ta
 	return scanDependencies(rows)
 }
 
+func (r *TaskDependencyRepository) ListByTasks(ctx context.Context, orgID uuid.UUID, taskIDs []uuid.UUID) ([]domain.TaskDependency, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, task_id, depends_on_task_id, dependency_type, created_at
+		FROM task_dependencies
+		WHERE org_id=$1 AND task_id = ANY($2)
+		ORDER BY created_at
+	`, orgID, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDependencies(rows)
+}
+
 func scanDependencies(rows pgx.Rows) ([]domain.TaskDependency, error) {
 	var items []domain.TaskDependency
 	for rows.Next() {
@@ -88,18 +104,125 @@ type ScheduleChangeRepository struct {
 	DB *pgxpool.Pool
 }
 
+const insertScheduleChange = `
+	INSERT INTO schedule_change_events
+		(id, org_id, task_id, change_type, reason, old_start_time, new_start_time,
+		 old_end_time, new_end_time, triggered_by, affected_task_ids, created_at)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+	RETURNING id, org_id, task_id, change_type, reason, old_start_time, new_start_time,
+	          old_end_time, new_end_time, triggered_by, affected_task_ids, created_at
+`
+
 func (r *ScheduleChangeRepository) Create(ctx context.Context, event domain.ScheduleChangeEvent) (domain.ScheduleChangeEvent, error) {
-	row := r.DB.QueryRow(ctx, `
-		INSERT INTO schedule_change_events
-			(id, org_id, task_id, change_type, reason, old_start_time, new_start_time,
-			 old_end_time, new_end_time, triggered_by, affected_task_ids, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
-		RETURNING id, org_id, task_id, change_type, reason, old_start_time, new_start_time,
-		          old_end_time, new_end_time, triggered_by, affected_task_ids, created_at
-	`, event.ID, event.OrgID, event.TaskID, event.ChangeType, event.Reason,
+	return scanScheduleChange(r.DB.QueryRow(ctx, insertScheduleChange, event.ID, event.OrgID, event.TaskID, event.ChangeType, event.Reason,
 		event.OldStartTime, event.NewStartTime, event.OldEndTime, event.NewEndTime,
-		event.TriggeredBy, event.AffectedTaskIDs, event.CreatedAt)
+		event.TriggeredBy, event.AffectedTaskIDs, event.CreatedAt))
+}
+
+func (r *ScheduleChangeRepository) Reschedule(ctx context.Context, event domain.ScheduleChangeEvent, tasks []domain.MaintenanceTask, now time.Time) (domain.ScheduleChangeEvent, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// A cascade moves tasks into each other's old windows, so overlaps are
+	// only checked once every task has moved.
+	if _, err := tx.Exec(ctx, `SET CONSTRAINTS maintenance_tasks_no_overlap, maintenance_tasks_bay_slot_no_overlap DEFERRED`); err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	for _, task := range tasks {
+		if err := moveTask(ctx, tx, task, now); err != nil {
+			return domain.ScheduleChangeEvent{}, err
+		}
+	}
+	created, err := scanScheduleChange(tx.QueryRow(ctx, insertScheduleChange, event.ID, event.OrgID, event.TaskID, event.ChangeType, event.Reason,
+		event.OldStartTime, event.NewStartTime, event.OldEndTime, event.NewEndTime,
+		event.TriggeredBy, event.AffectedTaskIDs, event.CreatedAt))
+	if err != nil {
+		return domain.ScheduleChangeEvent{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.ScheduleChangeEvent{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *ScheduleChangeRepository) Preempt(ctx context.Context, change ports.PreemptionChange, now time.Time) (domain.MaintenanceTask, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The preempting task takes the slots the displaced tasks leave, so
+	// overlaps are only checked once everything is written.
+	if _, err := tx.Exec(ctx, `SET CONSTRAINTS maintenance_tasks_no_overlap, maintenance_tasks_bay_slot_no_overlap DEFERRED`); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	for _, task := range change.Moved {
+		if err := moveTask(ctx, tx, task, now); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+	for _, task := range change.Released {
+		cmd, err := tx.Exec(ctx, `
+			UPDATE maintenance_tasks
+			SET assigned_mechanic_id=NULL, updated_at=$1
+			WHERE org_id=$2 AND id=$3 AND updated_at=$4 AND state='scheduled' AND deleted_at IS NULL
+		`, now, task.OrgID, task.ID, task.UpdatedAt)
+		if err != nil {
+			return domain.MaintenanceTask{}, TranslateError(err)
+		}
+		if cmd.RowsAffected() == 0 {
+			return domain.MaintenanceTask{}, domain.NewConflictError(fmt.Sprintf("task %s changed during preemption", task.ID))
+		}
+	}
+	task := change.Task
+	created, err := scanTask(tx.QueryRow(ctx, `
+		INSERT INTO maintenance_tasks
+			(id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		RETURNING id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+	`, task.ID, task.OrgID, task.AircraftID, task.ProgramID, task.WorkPackageID, task.BayID, task.BaySlot, task.Type, task.State, taskPriority(task),
+		task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.CreatedAt, task.UpdatedAt))
+	if err != nil {
+		return domain.MaintenanceTask{}, TranslateError(err)
+	}
+	if err := insertComplianceItems(ctx, tx, change.Compliance); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	for _, event := range change.Events {
+		if _, err := scanScheduleChange(tx.QueryRow(ctx, insertScheduleChange, event.ID, event.OrgID, event.TaskID, event.ChangeType, event.Reason,
+			event.OldStartTime, event.NewStartTime, event.OldEndTime, event.NewEndTime,
+			event.TriggeredBy, event.AffectedTaskIDs, event.CreatedAt)); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.MaintenanceTask{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+// moveTask writes a task's new window and bay slot, provided it has not
+// changed since it was read.
+func moveTask(ctx context.Context, tx pgx.Tx, task domain.MaintenanceTask, now time.Time) error {
+	cmd, err := tx.Exec(ctx, `
+		UPDATE maintenance_tasks
+		SET start_time=$1, end_time=$2, bay_slot=$3, updated_at=$4
+		WHERE org_id=$5 AND id=$6 AND updated_at=$7 AND state IN ('scheduled','in_progress') AND deleted_at IS NULL
+	`, task.StartTime, task.EndTime, task.BaySlot, now, task.OrgID, task.ID, task.UpdatedAt)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.NewConflictError(fmt.Sprintf("task %s changed during rescheduling", task.ID))
+	}
+	return nil
+}
 
+func scanScheduleChange(row pgx.Row) (domain.ScheduleChangeEvent, error) {
 	var created domain.ScheduleChangeEvent
 	if err := row.Scan(&created.ID, &created.OrgID, &created.TaskID, &created.ChangeType,
 		&created.Reason, &created.OldStartTime, &created.NewStartTime,
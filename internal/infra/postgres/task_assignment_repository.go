@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TaskAssignmentRepository struct {
+	DB *pgxpool.Pool
+}
+
+const taskAssignmentColumns = `id, org_id, task_id, mechanic_id, role, planned_hours, assigned_by, created_at, updated_at`
+
+func (r *TaskAssignmentRepository) Create(ctx context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskAssignment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO task_assignments (id, org_id, task_id, mechanic_id, role, planned_hours, assigned_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		RETURNING `+taskAssignmentColumns,
+		assignment.ID, assignment.OrgID, assignment.TaskID, assignment.MechanicID, assignment.Role, assignment.PlannedHours,
+		assignment.AssignedBy, assignment.CreatedAt, assignment.UpdatedAt)
+	created, err := scanTaskAssignment(row)
+	if err != nil {
+		return domain.TaskAssignment{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *TaskAssignmentRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.TaskAssignment, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskAssignment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `SELECT `+taskAssignmentColumns+` FROM task_assignments WHERE org_id=$1 AND id=$2`, orgID, id)
+	return scanTaskAssignment(row)
+}
+
+func (r *TaskAssignmentRepository) ListByTask(ctx context.Context, orgID, taskID uuid.UUID) (domain.Crew, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+taskAssignmentColumns+` FROM task_assignments
+		WHERE org_id=$1 AND task_id=$2
+		ORDER BY created_at ASC, id ASC
+	`, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var crew domain.Crew
+	for rows.Next() {
+		assignment, err := scanTaskAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		crew = append(crew, assignment)
+	}
+	return crew, rows.Err()
+}
+
+func (r *TaskAssignmentRepository) UpdatePlannedHours(ctx context.Context, assignment domain.TaskAssignment) (domain.TaskAssignment, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskAssignment{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE task_assignments SET planned_hours=$3, updated_at=$4
+		WHERE org_id=$1 AND id=$2
+		RETURNING `+taskAssignmentColumns,
+		assignment.OrgID, assignment.ID, assignment.PlannedHours, assignment.UpdatedAt)
+	updated, err := scanTaskAssignment(row)
+	if err != nil {
+		return domain.TaskAssignment{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *TaskAssignmentRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM task_assignments WHERE org_id=$1 AND id=$2`, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanTaskAssignment(row pgx.Row) (domain.TaskAssignment, error) {
+	var assignment domain.TaskAssignment
+	var role string
+	if err := row.Scan(&assignment.ID, &assignment.OrgID, &assignment.TaskID, &assignment.MechanicID, &role, &assignment.PlannedHours,
+		&assignment.AssignedBy, &assignment.CreatedAt, &assignment.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.TaskAssignment{}, domain.ErrNotFound
+		}
+		return domain.TaskAssignment{}, err
+	}
+	assignment.Role = domain.CrewRole(role)
+	return assignment, nil
+}
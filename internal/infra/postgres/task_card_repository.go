@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TaskCardRepository struct {
+	DB *pgxpool.Pool
+}
+
+const taskCardStepColumns = `id, org_id, card_id, position, instruction, required_inspection, measurement_unit, min_value, max_value,
+	measured_value, performed_by, performed_at, inspected_by, inspected_at, created_at, updated_at`
+
+func (r *TaskCardRepository) Create(ctx context.Context, card domain.TaskCard) (domain.TaskCard, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskCard{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.TaskCard{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO task_cards (id, org_id, task_id, title, reference, created_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	`, card.ID, card.OrgID, card.TaskID, card.Title, card.Reference, card.CreatedBy, card.CreatedAt, card.UpdatedAt); err != nil {
+		return domain.TaskCard{}, TranslateError(err)
+	}
+	for _, step := range card.Steps {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO task_card_steps
+				(id, org_id, card_id, position, instruction, required_inspection, measurement_unit, min_value, max_value, created_at, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		`, step.ID, card.OrgID, card.ID, step.Position, step.Instruction, step.RequiredInspection, step.MeasurementUnit,
+			step.MinValue, step.MaxValue, step.CreatedAt, step.UpdatedAt); err != nil {
+			return domain.TaskCard{}, TranslateError(err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.TaskCard{}, TranslateError(err)
+	}
+	return r.GetByID(ctx, card.OrgID, card.ID)
+}
+
+func (r *TaskCardRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.TaskCard, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskCard{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, task_id, title, reference, created_by, created_at, updated_at
+		FROM task_cards
+		WHERE org_id=$1 AND id=$2
+	`, orgID, id)
+	card, err := scanTaskCard(row)
+	if err != nil {
+		return domain.TaskCard{}, err
+	}
+	cards := []domain.TaskCard{card}
+	if err := r.loadSteps(ctx, orgID, cards); err != nil {
+		return domain.TaskCard{}, err
+	}
+	return cards[0], nil
+}
+
+func (r *TaskCardRepository) ListByTask(ctx context.Context, orgID, taskID uuid.UUID) ([]domain.TaskCard, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, org_id, task_id, title, reference, created_by, created_at, updated_at
+		FROM task_cards
+		WHERE org_id=$1 AND task_id=$2
+		ORDER BY created_at ASC, id ASC
+	`, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cards []domain.TaskCard
+	for rows.Next() {
+		card, err := scanTaskCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadSteps(ctx, orgID, cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func (r *TaskCardRepository) PerformStep(ctx context.Context, orgID, stepID, userID uuid.UUID, value *float64, at time.Time) (domain.TaskCardStep, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskCardStep{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE task_card_steps
+		SET measured_value=$4, performed_by=$3, performed_at=$5, updated_at=$5
+		WHERE org_id=$1 AND id=$2 AND performed_at IS NULL
+		RETURNING `+taskCardStepColumns, orgID, stepID, userID, value, at)
+	step, err := scanTaskCardStep(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.TaskCardStep{}, r.stepConflict(ctx, orgID, stepID, "step already performed")
+	}
+	if err != nil {
+		return domain.TaskCardStep{}, TranslateError(err)
+	}
+	return step, nil
+}
+
+func (r *TaskCardRepository) InspectStep(ctx context.Context, orgID, stepID, userID uuid.UUID, at time.Time) (domain.TaskCardStep, error) {
+	if r == nil || r.DB == nil {
+		return domain.TaskCardStep{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE task_card_steps
+		SET inspected_by=$3, inspected_at=$4, updated_at=$4
+		WHERE org_id=$1 AND id=$2 AND required_inspection AND performed_at IS NOT NULL
+		  AND inspected_at IS NULL AND performed_by <> $3
+		RETURNING `+taskCardStepColumns, orgID, stepID, userID, at)
+	step, err := scanTaskCardStep(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.TaskCardStep{}, r.stepConflict(ctx, orgID, stepID, "step cannot be inspected")
+	}
+	if err != nil {
+		return domain.TaskCardStep{}, TranslateError(err)
+	}
+	return step, nil
+}
+
+// stepConflict tells a guarded update that matched no step apart from one
+// that found no step at all.
+func (r *TaskCardRepository) stepConflict(ctx context.Context, orgID, stepID uuid.UUID, message string) error {
+	var exists bool
+	if err := r.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM task_card_steps WHERE org_id=$1 AND id=$2)
+	`, orgID, stepID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrNotFound
+	}
+	return domain.NewConflictError(message)
+}
+
+func (r *TaskCardRepository) loadSteps(ctx context.Context, orgID uuid.UUID, cards []domain.TaskCard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(cards))
+	index := make(map[uuid.UUID]int, len(cards))
+	for i, card := range cards {
+		ids[i] = card.ID
+		index[card.ID] = i
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+taskCardStepColumns+`
+		FROM task_card_steps
+		WHERE org_id=$1 AND card_id = ANY($2)
+		ORDER BY card_id, position
+	`, orgID, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		step, err := scanTaskCardStep(rows)
+		if err != nil {
+			return err
+		}
+		i := index[step.CardID]
+		cards[i].Steps = append(cards[i].Steps, step)
+	}
+	return rows.Err()
+}
+
+func scanTaskCard(row pgx.Row) (domain.TaskCard, error) {
+	var card domain.TaskCard
+	if err := row.Scan(&card.ID, &card.OrgID, &card.TaskID, &card.Title, &card.Reference, &card.CreatedBy, &card.CreatedAt, &card.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.TaskCard{}, domain.ErrNotFound
+		}
+		return domain.TaskCard{}, err
+	}
+	return card, nil
+}
+
+func scanTaskCardStep(row pgx.Row) (domain.TaskCardStep, error) {
+	var step domain.TaskCardStep
+	if err := row.Scan(&step.ID, &step.OrgID, &step.CardID, &step.Position, &step.Instruction, &step.RequiredInspection, &step.MeasurementUnit,
+		&step.MinValue, &step.MaxValue, &step.MeasuredValue, &step.PerformedBy, &step.PerformedAt, &step.InspectedBy, &step.InspectedAt,
+		&step.CreatedAt, &step.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.TaskCardStep{}, domain.ErrNotFound
+		}
+		return domain.TaskCardStep{}, err
+	}
+	return step, nil
+}
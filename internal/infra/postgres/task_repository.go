@@ -21,7 +21,7 @@ func (r *TaskRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (doma
 		return domain.MaintenanceTask{}, domain.ErrNotFound
 	}
 	row := r.DB.QueryRow(ctx, `
-		SELECT id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+		SELECT id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
 		FROM maintenance_tasks
 		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
 	`, orgID, id)
@@ -29,16 +29,42 @@ func (r *TaskRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (doma
 }
 
 func (r *TaskRepository) Create(ctx context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
+	return r.CreateWithCompliance(ctx, task, nil)
+}
+
+func (r *TaskRepository) CreateWithCompliance(ctx context.Context, task domain.MaintenanceTask, items []domain.ComplianceItem) (domain.MaintenanceTask, error) {
 	if r == nil || r.DB == nil {
 		return domain.MaintenanceTask{}, domain.ErrNotFound
 	}
-	row := r.DB.QueryRow(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	created, err := insertTask(ctx, tx, task)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if err := insertComplianceItems(ctx, tx, items); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	return created, nil
+}
+
+// insertTask inserts the task within tx, for writing it together with the
+// records that depend on it.
+func insertTask(ctx context.Context, tx pgx.Tx, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
+	row := tx.QueryRow(ctx, `
 		INSERT INTO maintenance_tasks
-			(id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, created_at, updated_at, deleted_at)
+			(id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, created_at, updated_at, deleted_at)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
-		RETURNING id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
-	`, task.ID, task.OrgID, task.AircraftID, task.ProgramID, task.Type, task.State, task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.CreatedAt, task.UpdatedAt, task.DeletedAt)
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		RETURNING id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+	`, task.ID, task.OrgID, task.AircraftID, task.ProgramID, task.WorkPackageID, task.BayID, task.BaySlot, task.Type, task.State, taskPriority(task), task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.CreatedAt, task.UpdatedAt, task.DeletedAt)
 	created, err := scanTask(row)
 	if err != nil {
 		return domain.MaintenanceTask{}, TranslateError(err)
@@ -52,10 +78,10 @@ func (r *TaskRepository) Update(ctx context.Context, task domain.MaintenanceTask
 	}
 	row := r.DB.QueryRow(ctx, `
 		UPDATE maintenance_tasks
-		SET program_id=$1, type=$2, start_time=$3, end_time=$4, assigned_mechanic_id=$5, notes=$6, updated_at=$7
-		WHERE org_id=$8 AND id=$9 AND deleted_at IS NULL
-		RETURNING id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
-	`, task.ProgramID, task.Type, task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.UpdatedAt, task.OrgID, task.ID)
+		SET program_id=$1, work_package_id=$2, bay_id=$3, bay_slot=$4, type=$5, priority=$6, start_time=$7, end_time=$8, assigned_mechanic_id=$9, notes=$10, updated_at=$11
+		WHERE org_id=$12 AND id=$13 AND deleted_at IS NULL
+		RETURNING id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+	`, task.ProgramID, task.WorkPackageID, task.BayID, task.BaySlot, task.Type, taskPriority(task), task.StartTime, task.EndTime, task.AssignedMechanicID, task.Notes, task.UpdatedAt, task.OrgID, task.ID)
 	updated, err := scanTask(row)
 	if err != nil {
 		return domain.MaintenanceTask{}, TranslateError(err)
@@ -85,8 +111,8 @@ func (r *TaskRepository) List(ctx context.Context, filter ports.TaskFilter) ([]d
 	if r == nil || r.DB == nil {
 		return nil, nil
 	}
-	clauses := make([]string, 0, 6)
-	args := make([]any, 0, 8)
+	clauses := make([]string, 0, 12)
+	args := make([]any, 0, 13)
 	add := func(condition string, value any) {
 		args = append(args, value)
 		clauses = append(clauses, condition+"$"+itoa(len(args)))
@@ -94,9 +120,25 @@ func (r *TaskRepository) List(ctx context.Context, filter ports.TaskFilter) ([]d
 	if filter.OrgID != nil {
 		add("org_id=", *filter.OrgID)
 	}
+	if filter.IDs != nil {
+		args = append(args, filter.IDs)
+		clauses = append(clauses, "id = ANY($"+itoa(len(args))+")")
+	}
 	if filter.AircraftID != nil {
 		add("aircraft_id=", *filter.AircraftID)
 	}
+	if filter.ProgramID != nil {
+		add("program_id=", *filter.ProgramID)
+	}
+	if filter.WorkPackageID != nil {
+		add("work_package_id=", *filter.WorkPackageID)
+	}
+	if filter.BayID != nil {
+		add("bay_id=", *filter.BayID)
+	}
+	if filter.AssignedMechanicID != nil {
+		add("assigned_mechanic_id=", *filter.AssignedMechanicID)
+	}
 	if filter.State != nil {
 		add("state=", *filter.State)
 	}
@@ -109,6 +151,15 @@ func (r *TaskRepository) List(ctx context.Context, filter ports.TaskFilter) ([]d
 	if filter.StartTo != nil {
 		add("start_time <= ", *filter.StartTo)
 	}
+	if filter.ActiveOnly {
+		clauses = append(clauses, "state IN ('scheduled','in_progress')")
+	}
+	if filter.OverlapFrom != nil {
+		add("end_time > ", *filter.OverlapFrom)
+	}
+	if filter.OverlapTo != nil {
+		add("start_time < ", *filter.OverlapTo)
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -123,7 +174,7 @@ func (r *TaskRepository) List(ctx context.Context, filter ports.TaskFilter) ([]d
 	}
 
 	query := `
-		SELECT id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+		SELECT id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
 		FROM maintenance_tasks
 		WHERE deleted_at IS NULL`
 	if len(clauses) > 0 {
@@ -153,20 +204,85 @@ func (r *TaskRepository) UpdateState(ctx context.Context, orgID, id uuid.UUID, n
 	if r == nil || r.DB == nil {
 		return domain.MaintenanceTask{}, domain.ErrNotFound
 	}
-	row := r.DB.QueryRow(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, `
 		UPDATE maintenance_tasks
 		SET state=$1, notes=$2, updated_at=$3
 		WHERE org_id=$4 AND id=$5 AND deleted_at IS NULL
-		RETURNING id, org_id, aircraft_id, program_id, type, state, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
+		RETURNING id, org_id, aircraft_id, program_id, work_package_id, bay_id, bay_slot, type, state, priority, start_time, end_time, assigned_mechanic_id, notes, deleted_at, created_at, updated_at
 	`, newState, notes, now, orgID, id)
-
 	task, err := scanTask(row)
 	if err != nil {
 		return domain.MaintenanceTask{}, TranslateError(err)
 	}
+
+	// A completed program task resets the program's baseline to the
+	// completion time and the aircraft's totals at that point.
+	if newState == domain.TaskStateCompleted && task.ProgramID != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE maintenance_programs p
+			SET last_performed=$1, last_performed_hours=a.flight_hours_total, last_performed_cycles=a.cycles_total, updated_at=$1
+			FROM aircraft a
+			WHERE p.org_id=$2 AND p.id=$3 AND p.deleted_at IS NULL
+			  AND a.org_id=p.org_id AND a.id=$4
+		`, now, task.OrgID, *task.ProgramID, task.AircraftID); err != nil {
+			return domain.MaintenanceTask{}, TranslateError(err)
+		}
+	}
+	if newState == domain.TaskStateCompleted {
+		if err := logTaskRecency(ctx, tx, task, now); err != nil {
+			return domain.MaintenanceTask{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.MaintenanceTask{}, err
+	}
 	return task, nil
 }
 
+// logTaskRecency logs the hours worked on a completed task as recency on the
+// aircraft's type, one log entry per mechanic and day. Aircraft without a
+// type earn no recency.
+func logTaskRecency(ctx context.Context, tx pgx.Tx, task domain.MaintenanceTask, now time.Time) error {
+	var aircraftTypeID *uuid.UUID
+	if err := tx.QueryRow(ctx, `
+		SELECT aircraft_type_id FROM aircraft WHERE org_id=$1 AND id=$2
+	`, task.OrgID, task.AircraftID).Scan(&aircraftTypeID); err != nil {
+		return TranslateError(err)
+	}
+	if aircraftTypeID == nil {
+		return nil
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT `+laborEntryColumns+`
+		FROM labor_entries
+		WHERE org_id=$1 AND task_id=$2
+	`, task.OrgID, task.ID)
+	if err != nil {
+		return TranslateError(err)
+	}
+	entries, err := collectLaborEntries(rows)
+	if err != nil {
+		return TranslateError(err)
+	}
+	for _, day := range domain.LaborDays(entries) {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO employee_recency_log
+				(id, org_id, user_id, aircraft_type_id, task_id, work_date, hours_logged, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		`, uuid.New(), task.OrgID, day.MechanicID, *aircraftTypeID, task.ID, day.WorkDate, day.Hours, now); err != nil {
+			return TranslateError(err)
+		}
+	}
+	return nil
+}
+
 func (r *TaskRepository) HasActiveForProgram(ctx context.Context, orgID, programID uuid.UUID) (bool, error) {
 	if r == nil || r.DB == nil {
 		return false, nil
@@ -191,7 +307,7 @@ func scanTask(row pgx.Row) (domain.MaintenanceTask, error) {
 	var task domain.MaintenanceTask
 	var programID *uuid.UUID
 	var assignedID *uuid.UUID
-	if err := row.Scan(&task.ID, &task.OrgID, &task.AircraftID, &programID, &task.Type, &task.State, &task.StartTime, &task.EndTime, &assignedID, &task.Notes, &task.DeletedAt, &task.CreatedAt, &task.UpdatedAt); err != nil {
+	if err := row.Scan(&task.ID, &task.OrgID, &task.AircraftID, &programID, &task.WorkPackageID, &task.BayID, &task.BaySlot, &task.Type, &task.State, &task.Priority, &task.StartTime, &task.EndTime, &assignedID, &task.Notes, &task.DeletedAt, &task.CreatedAt, &task.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return domain.MaintenanceTask{}, domain.ErrNotFound
 		}
@@ -201,3 +317,12 @@ func scanTask(row pgx.Row) (domain.MaintenanceTask, error) {
 	task.AssignedMechanicID = assignedID
 	return task, nil
 }
+
+// taskPriority defaults tasks created without a priority to routine, matching
+// the column default.
+func taskPriority(task domain.MaintenanceTask) domain.TaskPriority {
+	if task.Priority == "" {
+		return domain.PriorityRoutine
+	}
+	return task.Priority
+}
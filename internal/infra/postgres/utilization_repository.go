@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AircraftUtilizationRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *AircraftUtilizationRepository) Create(ctx context.Context, entry domain.AircraftUtilization) (domain.AircraftUtilization, error) {
+	if r == nil || r.DB == nil {
+		return domain.AircraftUtilization{}, domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.AircraftUtilization{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the aircraft row so concurrent entries are applied in order.
+	var flightHoursTotal, cyclesTotal int
+	if err := tx.QueryRow(ctx, `
+		SELECT flight_hours_total, cycles_total
+		FROM aircraft
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, entry.OrgID, entry.AircraftID).Scan(&flightHoursTotal, &cyclesTotal); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.AircraftUtilization{}, domain.ErrNotFound
+		}
+		return domain.AircraftUtilization{}, err
+	}
+	if entry.HoursTotal < float64(flightHoursTotal) || entry.CyclesTotal < cyclesTotal {
+		return domain.AircraftUtilization{}, domain.NewConflictError("utilization totals must not decrease")
+	}
+
+	var latestHours float64
+	var latestCycles int
+	var latestDate time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT hours_total, cycles_total, utilization_date
+		FROM aircraft_utilization
+		WHERE org_id=$1 AND aircraft_id=$2
+		ORDER BY utilization_date DESC, created_at DESC
+		LIMIT 1
+	`, entry.OrgID, entry.AircraftID).Scan(&latestHours, &latestCycles, &latestDate)
+	if err != nil && err != pgx.ErrNoRows {
+		return domain.AircraftUtilization{}, err
+	}
+	prevHours := float64(flightHoursTotal)
+	prevCycles := cyclesTotal
+	if err == nil {
+		if entry.UtilizationDate.Before(latestDate) {
+			return domain.AircraftUtilization{}, domain.NewConflictError("utilization entries must be recorded in date order")
+		}
+		if entry.HoursTotal < latestHours || entry.CyclesTotal < latestCycles {
+			return domain.AircraftUtilization{}, domain.NewConflictError("utilization totals must not decrease")
+		}
+		prevHours = math.Max(prevHours, latestHours)
+		if latestCycles > prevCycles {
+			prevCycles = latestCycles
+		}
+	}
+	// The entry's usage was worked out from the totals before it. If another
+	// entry landed in between, applying it would drop that entry's usage from
+	// the aircraft while the parts still accrue both.
+	if math.Abs(entry.HoursTotal-entry.BlockHours-prevHours) > 0.005 || entry.CyclesTotal-entry.Cycles != prevCycles {
+		return domain.AircraftUtilization{}, domain.NewConflictError("aircraft totals changed while the entry was recorded")
+	}
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO aircraft_utilization
+			(id, org_id, aircraft_id, entry_type, utilization_date, flight_number, block_hours, cycles, hours_total, cycles_total, recorded_by, created_at)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		RETURNING id, org_id, aircraft_id, entry_type, utilization_date, flight_number, block_hours, cycles, hours_total, cycles_total, recorded_by, created_at
+	`, entry.ID, entry.OrgID, entry.AircraftID, entry.EntryType, entry.UtilizationDate, entry.FlightNumber, entry.BlockHours, entry.Cycles, entry.HoursTotal, entry.CyclesTotal, entry.RecordedBy, entry.CreatedAt)
+	created, err := scanAircraftUtilization(row)
+	if err != nil {
+		return domain.AircraftUtilization{}, TranslateError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE aircraft
+		SET flight_hours_total=$1, cycles_total=$2, updated_at=$3
+		WHERE org_id=$4 AND id=$5
+	`, int(math.Floor(created.HoursTotal)), created.CyclesTotal, created.CreatedAt, created.OrgID, created.AircraftID); err != nil {
+		return domain.AircraftUtilization{}, TranslateError(err)
+	}
+
+	// Parts fitted to the aircraft accrue the same usage, which drives their
+	// remaining life.
+	if created.BlockHours > 0 || created.Cycles > 0 {
+		if _, err := tx.Exec(ctx, `
+			UPDATE part_items
+			SET hours_since_new = hours_since_new + $1,
+			    cycles_since_new = cycles_since_new + $2,
+			    hours_since_overhaul = hours_since_overhaul + $1,
+			    cycles_since_overhaul = cycles_since_overhaul + $2,
+			    updated_at = $3
+			WHERE org_id=$4 AND aircraft_id=$5 AND deleted_at IS NULL
+		`, created.BlockHours, created.Cycles, created.CreatedAt, created.OrgID, created.AircraftID); err != nil {
+			return domain.AircraftUtilization{}, TranslateError(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domain.AircraftUtilization{}, err
+	}
+	return created, nil
+}
+
+func (r *AircraftUtilizationRepository) GetLatest(ctx context.Context, orgID, aircraftID uuid.UUID) (domain.AircraftUtilization, error) {
+	if r == nil || r.DB == nil {
+		return domain.AircraftUtilization{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, aircraft_id, entry_type, utilization_date, flight_number, block_hours, cycles, hours_total, cycles_total, recorded_by, created_at
+		FROM aircraft_utilization
+		WHERE org_id=$1 AND aircraft_id=$2
+		ORDER BY utilization_date DESC, created_at DESC
+		LIMIT 1
+	`, orgID, aircraftID)
+	return scanAircraftUtilization(row)
+}
+
+func (r *AircraftUtilizationRepository) List(ctx context.Context, filter ports.AircraftUtilizationFilter) ([]domain.AircraftUtilization, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 4)
+	args := make([]any, 0, 6)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+	if filter.From != nil {
+		add("utilization_date>=", *filter.From)
+	}
+	if filter.To != nil {
+		add("utilization_date<=", *filter.To)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, aircraft_id, entry_type, utilization_date, flight_number, block_hours, cycles, hours_total, cycles_total, recorded_by, created_at
+		FROM aircraft_utilization`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY utilization_date DESC, created_at DESC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.AircraftUtilization
+	for rows.Next() {
+		item, err := scanAircraftUtilization(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *AircraftUtilizationRepository) AverageDaily(ctx context.Context, orgID, aircraftID uuid.UUID, from, to time.Time) (domain.UtilizationRate, error) {
+	if r == nil || r.DB == nil {
+		return domain.UtilizationRate{}, nil
+	}
+	var hours float64
+	var cycles int
+	var first *time.Time
+	if err := r.DB.QueryRow(ctx, `
+		SELECT COALESCE(SUM(block_hours), 0), COALESCE(SUM(cycles), 0), MIN(utilization_date)
+		FROM aircraft_utilization
+		WHERE org_id=$1 AND aircraft_id=$2 AND utilization_date >= $3 AND utilization_date <= $4
+	`, orgID, aircraftID, from, to).Scan(&hours, &cycles, &first); err != nil {
+		return domain.UtilizationRate{}, err
+	}
+	if first == nil {
+		return domain.UtilizationRate{}, nil
+	}
+	days := math.Floor(to.Sub(*first).Hours()/24) + 1
+	if days < 1 {
+		days = 1
+	}
+	return domain.UtilizationRate{
+		HoursPerDay:  hours / days,
+		CyclesPerDay: float64(cycles) / days,
+	}, nil
+}
+
+func scanAircraftUtilization(row pgx.Row) (domain.AircraftUtilization, error) {
+	var entry domain.AircraftUtilization
+	var flightNumber *string
+	if err := row.Scan(&entry.ID, &entry.OrgID, &entry.AircraftID, &entry.EntryType, &entry.UtilizationDate, &flightNumber, &entry.BlockHours, &entry.Cycles, &entry.HoursTotal, &entry.CyclesTotal, &entry.RecordedBy, &entry.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.AircraftUtilization{}, domain.ErrNotFound
+		}
+		return domain.AircraftUtilization{}, err
+	}
+	if flightNumber != nil {
+		entry.FlightNumber = *flightNumber
+	}
+	return entry, nil
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WorkPackageRepository struct {
+	DB *pgxpool.Pool
+}
+
+func (r *WorkPackageRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (domain.WorkPackage, error) {
+	if r == nil || r.DB == nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		SELECT id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at
+		FROM work_packages
+		WHERE org_id=$1 AND id=$2 AND deleted_at IS NULL
+	`, orgID, id)
+	return scanWorkPackage(row)
+}
+
+func (r *WorkPackageRepository) Create(ctx context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error) {
+	if r == nil || r.DB == nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		INSERT INTO work_packages
+			(id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		RETURNING id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at
+	`, pkg.ID, pkg.OrgID, pkg.AircraftID, pkg.Name, pkg.State, pkg.VisitStart, pkg.VisitEnd, pkg.Notes, pkg.CreatedAt, pkg.UpdatedAt, pkg.DeletedAt)
+	created, err := scanWorkPackage(row)
+	if err != nil {
+		return domain.WorkPackage{}, TranslateError(err)
+	}
+	return created, nil
+}
+
+func (r *WorkPackageRepository) Update(ctx context.Context, pkg domain.WorkPackage) (domain.WorkPackage, error) {
+	if r == nil || r.DB == nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE work_packages
+		SET name=$1, visit_start=$2, visit_end=$3, notes=$4, updated_at=$5
+		WHERE org_id=$6 AND id=$7 AND deleted_at IS NULL
+		RETURNING id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at
+	`, pkg.Name, pkg.VisitStart, pkg.VisitEnd, pkg.Notes, pkg.UpdatedAt, pkg.OrgID, pkg.ID)
+	updated, err := scanWorkPackage(row)
+	if err != nil {
+		return domain.WorkPackage{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *WorkPackageRepository) UpdateState(ctx context.Context, orgID, id uuid.UUID, newState domain.WorkPackageState, now time.Time) (domain.WorkPackage, error) {
+	if r == nil || r.DB == nil {
+		return domain.WorkPackage{}, domain.ErrNotFound
+	}
+	row := r.DB.QueryRow(ctx, `
+		UPDATE work_packages
+		SET state=$1, updated_at=$2
+		WHERE org_id=$3 AND id=$4 AND deleted_at IS NULL
+		RETURNING id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at
+	`, newState, now, orgID, id)
+	updated, err := scanWorkPackage(row)
+	if err != nil {
+		return domain.WorkPackage{}, TranslateError(err)
+	}
+	return updated, nil
+}
+
+func (r *WorkPackageRepository) SoftDelete(ctx context.Context, orgID, id uuid.UUID, at time.Time) error {
+	if r == nil || r.DB == nil {
+		return domain.ErrNotFound
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	cmd, err := tx.Exec(ctx, `
+		UPDATE work_packages
+		SET deleted_at=$1, updated_at=$1
+		WHERE org_id=$2 AND id=$3 AND deleted_at IS NULL
+	`, at, orgID, id)
+	if err != nil {
+		return TranslateError(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE maintenance_tasks
+		SET work_package_id=NULL, updated_at=$1
+		WHERE org_id=$2 AND work_package_id=$3
+	`, at, orgID, id); err != nil {
+		return TranslateError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *WorkPackageRepository) List(ctx context.Context, filter ports.WorkPackageFilter) ([]domain.WorkPackage, error) {
+	if r == nil || r.DB == nil {
+		return nil, nil
+	}
+	clauses := make([]string, 0, 5)
+	args := make([]any, 0, 7)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		clauses = append(clauses, condition+"$"+itoa(len(args)))
+	}
+	if filter.OrgID != nil {
+		add("org_id=", *filter.OrgID)
+	}
+	if filter.AircraftID != nil {
+		add("aircraft_id=", *filter.AircraftID)
+	}
+	if filter.State != nil {
+		add("state=", *filter.State)
+	}
+	if filter.VisitFrom != nil {
+		add("visit_end >= ", *filter.VisitFrom)
+	}
+	if filter.VisitTo != nil {
+		add("visit_start <= ", *filter.VisitTo)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+		SELECT id, org_id, aircraft_id, name, state, visit_start, visit_end, notes, created_at, updated_at, deleted_at
+		FROM work_packages
+		WHERE deleted_at IS NULL`
+	if len(clauses) > 0 {
+		query += " AND " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += " ORDER BY visit_start ASC, id ASC LIMIT $" + itoa(len(args)-1) + " OFFSET $" + itoa(len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var packages []domain.WorkPackage
+	for rows.Next() {
+		pkg, err := scanWorkPackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, rows.Err()
+}
+
+func scanWorkPackage(row pgx.Row) (domain.WorkPackage, error) {
+	var pkg domain.WorkPackage
+	if err := row.Scan(&pkg.ID, &pkg.OrgID, &pkg.AircraftID, &pkg.Name, &pkg.State, &pkg.VisitStart, &pkg.VisitEnd, &pkg.Notes, &pkg.CreatedAt, &pkg.UpdatedAt, &pkg.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.WorkPackage{}, domain.ErrNotFound
+		}
+		return domain.WorkPackage{}, err
+	}
+	return pkg, nil
+}
@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/aeromaintain/amss/internal/app/ports"
@@ -17,13 +18,27 @@ import (
 // - Expiring certifications (30/60/90 day)
 // - Overdue maintenance tasks
 // - Overdue compliance directives
+// - Life-limited parts nearing their life limit
+// - MEL deferrals nearing or past their expiry
 type AlertTrigger struct {
-	DB       *pgxpool.Pool
-	Alerts   ports.AlertRepository
-	Logger   zerolog.Logger
-	Interval time.Duration
+	DB        *pgxpool.Pool
+	Alerts    ports.AlertRepository
+	PartItems ports.PartItemRepository
+	Defects   ports.DefectRepository
+	Logger    zerolog.Logger
+	Interval  time.Duration
+	// PartLifeWarningPercent and PartLifeCriticalPercent are the remaining
+	// life percentages at which life-limited parts raise warning and critical
+	// alerts. Both zero selects 10 and 5.
+	PartLifeWarningPercent  int
+	PartLifeCriticalPercent int
+	// DeferralWarning is how long before a deferral expires it raises a
+	// warning alert. Zero selects 72 hours.
+	DeferralWarning time.Duration
 }
 
+const partLifePageSize = 200
+
 func (t *AlertTrigger) Run(ctx context.Context) {
 	interval := t.Interval
 	if interval == 0 {
@@ -47,14 +62,18 @@ func (t *AlertTrigger) Run(ctx context.Context) {
 }
 
 func (t *AlertTrigger) process(ctx context.Context) {
-	if t.DB == nil || t.Alerts == nil {
+	if t.Alerts == nil {
 		return
 	}
 	observability.IncJobRun("alert_trigger")
 
-	t.checkExpiringCerts(ctx)
-	t.checkOverdueTasks(ctx)
-	t.checkOverdueDirectives(ctx)
+	if t.DB != nil {
+		t.checkExpiringCerts(ctx)
+		t.checkOverdueTasks(ctx)
+		t.checkOverdueDirectives(ctx)
+	}
+	t.checkLifeLimitedParts(ctx)
+	t.checkExpiringDeferrals(ctx)
 }
 
 func (t *AlertTrigger) checkExpiringCerts(ctx context.Context) {
@@ -230,3 +249,190 @@ func (t *AlertTrigger) checkOverdueDirectives(ctx context.Context) {
 		`, now, complianceID, orgID)
 	}
 }
+
+// checkLifeLimitedParts raises an alert when a life-limited part's remaining
+// life drops to the warning or critical percentage. A part only raises a new
+// alert when it crosses into a more severe band than its unresolved alerts.
+func (t *AlertTrigger) checkLifeLimitedParts(ctx context.Context) {
+	if t.PartItems == nil {
+		return
+	}
+	now := time.Now().UTC()
+	warning, critical := float64(t.PartLifeWarningPercent), float64(t.PartLifeCriticalPercent)
+	if warning == 0 && critical == 0 {
+		warning, critical = 10, 5
+	}
+
+	raised, err := t.openAlertLevels(ctx, "part_life_limit")
+	if err != nil {
+		t.Logger.Error().Err(err).Msg("failed to list part life alerts")
+		return
+	}
+
+	for offset := 0; ; offset += partLifePageSize {
+		items, err := t.PartItems.List(ctx, ports.PartItemFilter{LifeLimited: true, Limit: partLifePageSize, Offset: offset})
+		if err != nil {
+			t.Logger.Error().Err(err).Msg("failed to query life-limited parts")
+			return
+		}
+		for _, item := range items {
+			remaining, ok := item.RemainingLifePercent()
+			if !ok {
+				continue
+			}
+			var level domain.AlertLevel
+			var threshold float64
+			switch {
+			case remaining <= critical:
+				level, threshold = domain.AlertCritical, critical
+			case remaining <= warning:
+				level, threshold = domain.AlertWarning, warning
+			default:
+				continue
+			}
+			if existing, ok := raised[item.ID]; ok && alertSeverity(existing) >= alertSeverity(level) {
+				continue
+			}
+
+			title := fmt.Sprintf("Life-limited part nearing limit: %s", item.SerialNumber)
+			if remaining <= 0 {
+				title = fmt.Sprintf("Life limit reached: %s", item.SerialNumber)
+			}
+			current := math.Round(remaining*100) / 100
+			alert := domain.Alert{
+				ID:             uuid.New(),
+				OrgID:          item.OrgID,
+				Level:          level,
+				Category:       "part_life_limit",
+				Title:          title,
+				Description:    partLifeDescription(item, current),
+				EntityType:     "part_item",
+				EntityID:       item.ID,
+				ThresholdValue: &threshold,
+				CurrentValue:   &current,
+				CreatedAt:      now,
+			}
+			if level == domain.AlertCritical {
+				escalateAt := now.Add(time.Hour)
+				alert.AutoEscalateAt = &escalateAt
+			}
+			if _, err := t.Alerts.Create(ctx, alert); err != nil {
+				t.Logger.Error().Err(err).Msg("failed to create part life alert")
+				continue
+			}
+			raised[item.ID] = level
+		}
+		if len(items) < partLifePageSize {
+			return
+		}
+	}
+}
+
+// checkExpiringDeferrals raises a warning alert for MEL deferrals expiring
+// within the warning window and a critical one for deferrals that have
+// expired, which ground the aircraft until the defect is rectified.
+func (t *AlertTrigger) checkExpiringDeferrals(ctx context.Context) {
+	if t.Defects == nil {
+		return
+	}
+	now := time.Now().UTC()
+	window := t.DeferralWarning
+	if window == 0 {
+		window = 72 * time.Hour
+	}
+
+	raised, err := t.openAlertLevels(ctx, "mel_deferral_expiry")
+	if err != nil {
+		t.Logger.Error().Err(err).Msg("failed to list deferral alerts")
+		return
+	}
+
+	before := now.Add(window)
+	for offset := 0; ; offset += partLifePageSize {
+		defects, err := t.Defects.List(ctx, ports.DefectFilter{ExpiringBefore: &before, Limit: partLifePageSize, Offset: offset})
+		if err != nil {
+			t.Logger.Error().Err(err).Msg("failed to query expiring deferrals")
+			return
+		}
+		for _, defect := range defects {
+			level := domain.AlertWarning
+			title := fmt.Sprintf("MEL deferral expiring: %s", defect.DeferralReference)
+			description := fmt.Sprintf("MEL item %s expires in %d hours: %s", defect.MELItem, int(defect.ExpiresAt.Sub(now).Hours()), defect.Title)
+			if defect.Expired(now) {
+				level = domain.AlertCritical
+				title = fmt.Sprintf("MEL deferral expired: %s", defect.DeferralReference)
+				description = fmt.Sprintf("MEL item %s expired %s; aircraft may not return to service: %s", defect.MELItem, defect.ExpiresAt.Format(time.RFC3339), defect.Title)
+			}
+			if existing, ok := raised[defect.ID]; ok && alertSeverity(existing) >= alertSeverity(level) {
+				continue
+			}
+
+			alert := domain.Alert{
+				ID:          uuid.New(),
+				OrgID:       defect.OrgID,
+				Level:       level,
+				Category:    "mel_deferral_expiry",
+				Title:       title,
+				Description: description,
+				EntityType:  "defect",
+				EntityID:    defect.ID,
+				CreatedAt:   now,
+			}
+			if level == domain.AlertCritical {
+				escalateAt := now.Add(time.Hour)
+				alert.AutoEscalateAt = &escalateAt
+			}
+			if _, err := t.Alerts.Create(ctx, alert); err != nil {
+				t.Logger.Error().Err(err).Msg("failed to create deferral expiry alert")
+				continue
+			}
+			raised[defect.ID] = level
+		}
+		if len(defects) < partLifePageSize {
+			return
+		}
+	}
+}
+
+// openAlertLevels returns the most severe unresolved alert level of the
+// category per entity.
+func (t *AlertTrigger) openAlertLevels(ctx context.Context, category string) (map[uuid.UUID]domain.AlertLevel, error) {
+	resolved := false
+	raised := make(map[uuid.UUID]domain.AlertLevel)
+	for offset := 0; ; offset += partLifePageSize {
+		alerts, err := t.Alerts.List(ctx, ports.AlertFilter{Category: category, Resolved: &resolved, Limit: partLifePageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		for _, alert := range alerts {
+			if existing, ok := raised[alert.EntityID]; !ok || alertSeverity(alert.Level) > alertSeverity(existing) {
+				raised[alert.EntityID] = alert.Level
+			}
+		}
+		if len(alerts) < partLifePageSize {
+			return raised, nil
+		}
+	}
+}
+
+func partLifeDescription(item domain.PartItem, remainingPercent float64) string {
+	desc := fmt.Sprintf("%.2f%% of life remaining", remainingPercent)
+	if hours := item.RemainingHours(); hours != nil {
+		desc += fmt.Sprintf(", %.2f hours left (TSN %.2f of %.2f)", *hours, item.HoursSinceNew, *item.LifeLimitHours)
+	}
+	if cycles := item.RemainingCycles(); cycles != nil {
+		desc += fmt.Sprintf(", %d cycles left (CSN %d of %d)", *cycles, item.CyclesSinceNew, *item.LifeLimitCycles)
+	}
+	return desc
+}
+
+func alertSeverity(level domain.AlertLevel) int {
+	switch level {
+	case domain.AlertCritical:
+		return 2
+	case domain.AlertWarning:
+		return 1
+	default:
+		return 0
+	}
+}
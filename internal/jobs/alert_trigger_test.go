@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestAlertTriggerRaisesPartLifeAlertsOnThresholdCrossing(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	hoursLimit := 20000.0
+	cyclesLimit := 10000
+
+	itemRepo := newFakePartItemRepo()
+	healthy := domain.PartItem{ID: uuid.New(), OrgID: orgID, SerialNumber: "DISK-OK", LifeLimitCycles: &cyclesLimit, CyclesSinceNew: 5000}
+	nearing := domain.PartItem{ID: uuid.New(), OrgID: orgID, SerialNumber: "DISK-NEAR", LifeLimitHours: &hoursLimit, HoursSinceNew: 18500}
+	expired := domain.PartItem{ID: uuid.New(), OrgID: orgID, SerialNumber: "DISK-OUT", LifeLimitCycles: &cyclesLimit, CyclesSinceNew: 10000}
+	untracked := domain.PartItem{ID: uuid.New(), OrgID: orgID, SerialNumber: "FILTER", HoursSinceNew: 99999}
+	for _, item := range []domain.PartItem{healthy, nearing, expired, untracked} {
+		_, _ = itemRepo.Create(ctx, item)
+	}
+	alertRepo := &fakeAlertRepo{}
+	trigger := &AlertTrigger{
+		Alerts:                  alertRepo,
+		PartItems:               itemRepo,
+		Logger:                  zerolog.Nop(),
+		PartLifeWarningPercent:  10,
+		PartLifeCriticalPercent: 5,
+	}
+
+	trigger.process(ctx)
+	if len(alertRepo.alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %+v", alertRepo.alerts)
+	}
+	levels := map[uuid.UUID]domain.AlertLevel{}
+	for _, alert := range alertRepo.alerts {
+		if alert.Category != "part_life_limit" || alert.EntityType != "part_item" {
+			t.Fatalf("unexpected alert %+v", alert)
+		}
+		levels[alert.EntityID] = alert.Level
+	}
+	if levels[nearing.ID] != domain.AlertWarning || levels[expired.ID] != domain.AlertCritical {
+		t.Fatalf("expected warning for %s and critical for %s, got %+v", nearing.SerialNumber, expired.SerialNumber, levels)
+	}
+
+	// Unchanged parts do not raise duplicate alerts.
+	trigger.process(ctx)
+	if len(alertRepo.alerts) != 2 {
+		t.Fatalf("expected no duplicate alerts, got %d", len(alertRepo.alerts))
+	}
+
+	// Crossing into the critical band raises a new alert for the same part.
+	nearing.HoursSinceNew = 19200
+	_, _ = itemRepo.Update(ctx, nearing)
+	trigger.process(ctx)
+	if len(alertRepo.alerts) != 3 {
+		t.Fatalf("expected a third alert, got %d", len(alertRepo.alerts))
+	}
+	last := alertRepo.alerts[2]
+	if last.EntityID != nearing.ID || last.Level != domain.AlertCritical {
+		t.Fatalf("expected critical alert for %s, got %+v", nearing.SerialNumber, last)
+	}
+	if last.ThresholdValue == nil || *last.ThresholdValue != 5 || last.CurrentValue == nil || *last.CurrentValue != 4 {
+		t.Fatalf("expected threshold 5 and current 4, got %v/%v", last.ThresholdValue, last.CurrentValue)
+	}
+}
+
+func TestAlertTriggerRaisesDeferralExpiryAlerts(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	now := time.Now().UTC()
+	category := domain.MELCategoryC
+	deferred := func(reference string, expiresAt time.Time) domain.Defect {
+		deferredAt := now.AddDate(0, 0, -5)
+		return domain.Defect{
+			ID:                uuid.New(),
+			OrgID:             orgID,
+			AircraftID:        uuid.New(),
+			Title:             "Cabin PA inoperative",
+			State:             domain.DefectDeferred,
+			ReportedAt:        deferredAt,
+			MELCategory:       &category,
+			MELItem:           "23-31-01",
+			DeferralReference: reference,
+			DeferredAt:        &deferredAt,
+			ExpiresAt:         &expiresAt,
+		}
+	}
+
+	defectRepo := newFakeDefectRepo()
+	distant := deferred("DD-1", now.AddDate(0, 0, 5))
+	soon := deferred("DD-2", now.Add(24*time.Hour))
+	expired := deferred("DD-3", now.Add(-time.Hour))
+	for _, defect := range []domain.Defect{distant, soon, expired} {
+		_, _ = defectRepo.Create(ctx, defect)
+	}
+	alertRepo := &fakeAlertRepo{}
+	trigger := &AlertTrigger{Alerts: alertRepo, Defects: defectRepo, Logger: zerolog.Nop()}
+
+	trigger.process(ctx)
+	levels := map[uuid.UUID]domain.AlertLevel{}
+	for _, alert := range alertRepo.alerts {
+		if alert.Category != "mel_deferral_expiry" || alert.EntityType != "defect" {
+			t.Fatalf("unexpected alert %+v", alert)
+		}
+		levels[alert.EntityID] = alert.Level
+	}
+	if len(levels) != 2 || levels[soon.ID] != domain.AlertWarning || levels[expired.ID] != domain.AlertCritical {
+		t.Fatalf("expected warning for DD-2 and critical for DD-3, got %+v", levels)
+	}
+
+	trigger.process(ctx)
+	if len(alertRepo.alerts) != 2 {
+		t.Fatalf("expected no duplicate alerts, got %d", len(alertRepo.alerts))
+	}
+
+	// A deferral that runs out escalates its warning to a critical alert.
+	past := now.Add(-time.Minute)
+	soon.ExpiresAt = &past
+	_, _ = defectRepo.Update(ctx, soon, domain.DefectDeferred)
+	trigger.process(ctx)
+	if len(alertRepo.alerts) != 3 || alertRepo.alerts[2].EntityID != soon.ID || alertRepo.alerts[2].Level != domain.AlertCritical {
+		t.Fatalf("expected a critical alert for DD-2, got %+v", alertRepo.alerts)
+	}
+}
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -119,6 +120,8 @@ func (f *fakeImportRowRepo) ListByImport(_ context.Context, filter ports.ImportR
 type fakeAircraftRepo struct {
 	mu       sync.Mutex
 	aircraft map[uuid.UUID]domain.Aircraft
+	// programs, when set, receives the programs written with an aircraft.
+	programs *fakeProgramRepo
 }
 
 func newFakeAircraftRepo() *fakeAircraftRepo {
@@ -167,13 +170,44 @@ func (f *fakeAircraftRepo) Create(_ context.Context, aircraft domain.Aircraft) (
 func (f *fakeAircraftRepo) Update(_ context.Context, aircraft domain.Aircraft) (domain.Aircraft, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	if _, ok := f.aircraft[aircraft.ID]; !ok {
+	existing, ok := f.aircraft[aircraft.ID]
+	if !ok {
 		return domain.Aircraft{}, domain.ErrNotFound
 	}
+	aircraft.FlightHoursTotal = existing.FlightHoursTotal
+	aircraft.CyclesTotal = existing.CyclesTotal
 	f.aircraft[aircraft.ID] = aircraft
 	return aircraft, nil
 }
 
+func (f *fakeAircraftRepo) CreateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	if len(programs) > 0 && f.programs == nil {
+		return domain.Aircraft{}, domain.NewValidationError("program repository unavailable")
+	}
+	created, err := f.Create(ctx, aircraft)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	for _, program := range programs {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return created, nil
+}
+
+func (f *fakeAircraftRepo) UpdateWithPrograms(ctx context.Context, aircraft domain.Aircraft, programs []domain.MaintenanceProgram) (domain.Aircraft, error) {
+	if len(programs) > 0 && f.programs == nil {
+		return domain.Aircraft{}, domain.NewValidationError("program repository unavailable")
+	}
+	updated, err := f.Update(ctx, aircraft)
+	if err != nil {
+		return domain.Aircraft{}, err
+	}
+	for _, program := range programs {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return updated, nil
+}
+
 func (f *fakeAircraftRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -206,11 +240,172 @@ func (f *fakeAircraftRepo) List(_ context.Context, filter ports.AircraftFilter)
 		if filter.TailNumber != "" && !strings.EqualFold(item.TailNumber, filter.TailNumber) {
 			continue
 		}
+		if filter.AircraftTypeID != nil && (item.AircraftTypeID == nil || *item.AircraftTypeID != *filter.AircraftTypeID) {
+			continue
+		}
 		out = append(out, item)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
 }
 
+type fakeUtilizationRepo struct {
+	mu       sync.Mutex
+	entries  []domain.AircraftUtilization
+	aircraft *fakeAircraftRepo
+}
+
+func newFakeUtilizationRepo(aircraft *fakeAircraftRepo) *fakeUtilizationRepo {
+	return &fakeUtilizationRepo{aircraft: aircraft}
+}
+
+func (f *fakeUtilizationRepo) Create(_ context.Context, entry domain.AircraftUtilization) (domain.AircraftUtilization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.aircraft != nil {
+		f.aircraft.mu.Lock()
+		item, ok := f.aircraft.aircraft[entry.AircraftID]
+		if ok {
+			item.FlightHoursTotal = int(entry.HoursTotal)
+			item.CyclesTotal = entry.CyclesTotal
+			f.aircraft.aircraft[entry.AircraftID] = item
+		}
+		f.aircraft.mu.Unlock()
+	}
+	f.entries = append(f.entries, entry)
+	return entry, nil
+}
+
+func (f *fakeUtilizationRepo) GetLatest(_ context.Context, orgID, aircraftID uuid.UUID) (domain.AircraftUtilization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		entry := f.entries[i]
+		if entry.OrgID == orgID && entry.AircraftID == aircraftID {
+			return entry, nil
+		}
+	}
+	return domain.AircraftUtilization{}, domain.ErrNotFound
+}
+
+func (f *fakeUtilizationRepo) List(_ context.Context, filter ports.AircraftUtilizationFilter) ([]domain.AircraftUtilization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.AircraftUtilization
+	// Entries are appended in date order; list them newest first.
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		entry := f.entries[i]
+		if filter.OrgID != nil && entry.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && entry.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.From != nil && entry.UtilizationDate.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && entry.UtilizationDate.After(*filter.To) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeUtilizationRepo) AverageDaily(_ context.Context, orgID, aircraftID uuid.UUID, from, to time.Time) (domain.UtilizationRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var hours float64
+	var cycles int
+	var first *time.Time
+	for _, entry := range f.entries {
+		if entry.OrgID != orgID || entry.AircraftID != aircraftID {
+			continue
+		}
+		if entry.UtilizationDate.Before(from) || entry.UtilizationDate.After(to) {
+			continue
+		}
+		hours += entry.BlockHours
+		cycles += entry.Cycles
+		if first == nil || entry.UtilizationDate.Before(*first) {
+			date := entry.UtilizationDate
+			first = &date
+		}
+	}
+	if first == nil {
+		return domain.UtilizationRate{}, nil
+	}
+	days := float64(int(to.Sub(*first).Hours()/24) + 1)
+	return domain.UtilizationRate{HoursPerDay: hours / days, CyclesPerDay: float64(cycles) / days}, nil
+}
+
+type fakePlannedFlightRepo struct {
+	mu      sync.Mutex
+	flights map[uuid.UUID]domain.PlannedFlight
+}
+
+func newFakePlannedFlightRepo() *fakePlannedFlightRepo {
+	return &fakePlannedFlightRepo{flights: map[uuid.UUID]domain.PlannedFlight{}}
+}
+
+func (f *fakePlannedFlightRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flight, ok := f.flights[id]
+	if !ok || flight.OrgID != orgID {
+		return domain.PlannedFlight{}, domain.ErrNotFound
+	}
+	return flight, nil
+}
+
+func (f *fakePlannedFlightRepo) Upsert(_ context.Context, flight domain.PlannedFlight) (domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, existing := range f.flights {
+		if existing.OrgID == flight.OrgID && existing.AircraftID == flight.AircraftID && existing.FlightNumber == flight.FlightNumber && existing.DepartureTime.Equal(flight.DepartureTime) {
+			flight.ID = id
+			flight.CreatedAt = existing.CreatedAt
+			continue
+		}
+		if existing.AircraftID == flight.AircraftID && flight.Status == domain.FlightScheduled && existing.Overlaps(flight.DepartureTime, flight.ArrivalTime) {
+			return domain.PlannedFlight{}, domain.NewCapacityConflict(domain.CapacityConflictFlightOverlap, "aircraft already has a flight planned in this window")
+		}
+	}
+	f.flights[flight.ID] = flight
+	return flight, nil
+}
+
+func (f *fakePlannedFlightRepo) Delete(_ context.Context, orgID, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flight, ok := f.flights[id]
+	if !ok || flight.OrgID != orgID {
+		return domain.ErrNotFound
+	}
+	delete(f.flights, id)
+	return nil
+}
+
+func (f *fakePlannedFlightRepo) List(_ context.Context, filter ports.PlannedFlightFilter) ([]domain.PlannedFlight, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.PlannedFlight
+	for _, flight := range f.flights {
+		if filter.OrgID != nil && flight.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.Status != nil && flight.Status != *filter.Status {
+			continue
+		}
+		out = append(out, flight)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DepartureTime.Before(out[j].DepartureTime) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakePlannedFlightRepo) ListPrevious(_ context.Context, orgID uuid.UUID, aircraftIDs []uuid.UUID, at time.Time) ([]domain.PlannedFlight, error) {
+	return nil, nil
+}
+
 type fakePartDefinitionRepo struct {
 	mu   sync.Mutex
 	defs map[uuid.UUID]domain.PartDefinition
@@ -345,6 +540,12 @@ func (f *fakePartItemRepo) List(_ context.Context, filter ports.PartItemFilter)
 		if filter.Status != nil && item.Status != *filter.Status {
 			continue
 		}
+		if filter.AircraftID != nil && (item.AircraftID == nil || *item.AircraftID != *filter.AircraftID) {
+			continue
+		}
+		if filter.LifeLimited && !item.IsLifeLimited() {
+			continue
+		}
 		out = append(out, item)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
@@ -440,18 +641,26 @@ func (f *fakeProgramRepo) List(_ context.Context, filter ports.MaintenanceProgra
 				continue
 			}
 		}
+		if filter.TemplateID != nil {
+			if program.TemplateID == nil || *program.TemplateID != *filter.TemplateID {
+				continue
+			}
+		}
 		out = append(out, program)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
 }
 
-func (f *fakeProgramRepo) ListDueCalendar(_ context.Context, _ time.Time, limit int) ([]domain.MaintenanceProgram, error) {
+func (f *fakeProgramRepo) ListDue(_ context.Context, now time.Time, lookAhead domain.ProgramLookAhead, limit int) ([]domain.MaintenanceProgram, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	if len(f.due) == 0 {
 		var out []domain.MaintenanceProgram
 		for _, program := range f.programs {
-			if program.DeletedAt != nil || program.IntervalType != domain.ProgramIntervalCalendar {
+			if program.DeletedAt != nil || program.AircraftID == nil {
+				continue
+			}
+			if !program.IsUsageBased() && !program.IsDue(domain.Aircraft{}, now, lookAhead) {
 				continue
 			}
 			out = append(out, program)
@@ -484,9 +693,127 @@ func (f *fakeProgramRepo) GetByName(_ context.Context, orgID uuid.UUID, name str
 	return domain.MaintenanceProgram{}, domain.ErrNotFound
 }
 
+type fakeProgramTemplateRepo struct {
+	mu        sync.Mutex
+	templates map[uuid.UUID]domain.MaintenanceProgramTemplate
+	// programs, when set, receives the instances written by Propagate.
+	programs *fakeProgramRepo
+}
+
+func newFakeProgramTemplateRepo() *fakeProgramTemplateRepo {
+	return &fakeProgramTemplateRepo{templates: make(map[uuid.UUID]domain.MaintenanceProgramTemplate)}
+}
+
+func (f *fakeProgramTemplateRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	template, ok := f.templates[id]
+	if !ok || template.OrgID != orgID || template.DeletedAt != nil {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) Create(_ context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.templates[template.ID] = template
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) Update(_ context.Context, template domain.MaintenanceProgramTemplate) (domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.templates[template.ID]; !ok {
+		return domain.MaintenanceProgramTemplate{}, domain.ErrNotFound
+	}
+	f.templates[template.ID] = template
+	return template, nil
+}
+
+func (f *fakeProgramTemplateRepo) CreateWithInstances(ctx context.Context, template domain.MaintenanceProgramTemplate, instances []domain.MaintenanceProgram) (domain.MaintenanceProgramTemplate, error) {
+	if len(instances) > 0 && f.programs == nil {
+		return domain.MaintenanceProgramTemplate{}, domain.NewValidationError("program repository unavailable")
+	}
+	created, err := f.Create(ctx, template)
+	if err != nil {
+		return domain.MaintenanceProgramTemplate{}, err
+	}
+	for _, program := range instances {
+		_, _ = f.programs.Create(ctx, program)
+	}
+	return created, nil
+}
+
+func (f *fakeProgramTemplateRepo) Propagate(_ context.Context, template domain.MaintenanceProgramTemplate, created, updated []domain.MaintenanceProgram) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.templates[template.ID]
+	if !ok || stored.OrgID != template.OrgID || stored.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	if stored.Revision != template.Revision {
+		return domain.NewConflictError("template revision has changed since the preview")
+	}
+	if f.programs == nil {
+		return domain.NewValidationError("program repository unavailable")
+	}
+	f.programs.mu.Lock()
+	defer f.programs.mu.Unlock()
+	for _, program := range updated {
+		if _, ok := f.programs.programs[program.ID]; !ok {
+			return domain.ErrNotFound
+		}
+	}
+	for _, program := range created {
+		f.programs.programs[program.ID] = program
+	}
+	for _, program := range updated {
+		f.programs.programs[program.ID] = program
+	}
+	return nil
+}
+
+func (f *fakeProgramTemplateRepo) SoftDelete(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	template, ok := f.templates[id]
+	if !ok || template.OrgID != orgID || template.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	template.DeletedAt = &at
+	f.templates[id] = template
+	return nil
+}
+
+func (f *fakeProgramTemplateRepo) List(_ context.Context, filter ports.MaintenanceProgramTemplateFilter) ([]domain.MaintenanceProgramTemplate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.MaintenanceProgramTemplate
+	for _, template := range f.templates {
+		if template.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && template.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftTypeID != nil && template.AircraftTypeID != *filter.AircraftTypeID {
+			continue
+		}
+		out = append(out, template)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
 type fakeTaskRepo struct {
 	mu    sync.Mutex
 	tasks map[uuid.UUID]domain.MaintenanceTask
+	// programs and aircraft, when set, receive the program's last
+	// performance when a program task completes.
+	programs *fakeProgramRepo
+	aircraft *fakeAircraftRepo
+	// compliance, when set, receives the checklist of created tasks.
+	compliance *fakeComplianceRepo
 }
 
 func newFakeTaskRepo() *fakeTaskRepo {
@@ -510,6 +837,20 @@ func (f *fakeTaskRepo) Create(_ context.Context, task domain.MaintenanceTask) (d
 	return task, nil
 }
 
+func (f *fakeTaskRepo) CreateWithCompliance(ctx context.Context, task domain.MaintenanceTask, items []domain.ComplianceItem) (domain.MaintenanceTask, error) {
+	if len(items) > 0 && f.compliance == nil {
+		return domain.MaintenanceTask{}, domain.NewValidationError("compliance repository unavailable")
+	}
+	created, err := f.Create(ctx, task)
+	if err != nil {
+		return domain.MaintenanceTask{}, err
+	}
+	for _, item := range items {
+		_ = f.compliance.Create(ctx, item)
+	}
+	return created, nil
+}
+
 func (f *fakeTaskRepo) Update(_ context.Context, task domain.MaintenanceTask) (domain.MaintenanceTask, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -546,12 +887,24 @@ func (f *fakeTaskRepo) List(_ context.Context, filter ports.TaskFilter) ([]domai
 		if filter.AircraftID != nil && task.AircraftID != *filter.AircraftID {
 			continue
 		}
+		if filter.ProgramID != nil && (task.ProgramID == nil || *task.ProgramID != *filter.ProgramID) {
+			continue
+		}
+		if filter.WorkPackageID != nil && (task.WorkPackageID == nil || *task.WorkPackageID != *filter.WorkPackageID) {
+			continue
+		}
 		if filter.State != nil && task.State != *filter.State {
 			continue
 		}
 		if filter.Type != nil && task.Type != *filter.Type {
 			continue
 		}
+		if filter.ActiveOnly && !task.IsActive() {
+			continue
+		}
+		if filter.OverlapFrom != nil && !task.EndTime.After(*filter.OverlapFrom) {
+			continue
+		}
 		out = append(out, task)
 	}
 	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
@@ -568,6 +921,23 @@ func (f *fakeTaskRepo) UpdateState(_ context.Context, orgID, id uuid.UUID, newSt
 	task.Notes = notes
 	task.UpdatedAt = now
 	f.tasks[id] = task
+	if newState == domain.TaskStateCompleted && task.ProgramID != nil && f.programs != nil && f.aircraft != nil {
+		aircraft, err := f.aircraft.GetByID(context.Background(), orgID, task.AircraftID)
+		if err == nil {
+			f.programs.mu.Lock()
+			if program, ok := f.programs.programs[*task.ProgramID]; ok {
+				performed := now
+				hours := aircraft.FlightHoursTotal
+				cycles := aircraft.CyclesTotal
+				program.LastPerformed = &performed
+				program.LastPerformedHours = &hours
+				program.LastPerformedCycles = &cycles
+				program.UpdatedAt = now
+				f.programs.programs[program.ID] = program
+			}
+			f.programs.mu.Unlock()
+		}
+	}
 	return task, nil
 }
 
@@ -585,6 +955,100 @@ func (f *fakeTaskRepo) HasActiveForProgram(_ context.Context, orgID, programID u
 	return false, nil
 }
 
+type fakeComplianceRepo struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]domain.ComplianceItem
+}
+
+func newFakeComplianceRepo() *fakeComplianceRepo {
+	return &fakeComplianceRepo{items: make(map[uuid.UUID]domain.ComplianceItem)}
+}
+
+func (f *fakeComplianceRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.ComplianceItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[id]
+	if !ok || item.OrgID != orgID || item.DeletedAt != nil {
+		return domain.ComplianceItem{}, domain.ErrNotFound
+	}
+	return item, nil
+}
+
+func (f *fakeComplianceRepo) ListByTask(_ context.Context, orgID, taskID uuid.UUID) ([]domain.ComplianceItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.ComplianceItem
+	for _, item := range f.items {
+		if item.DeletedAt != nil {
+			continue
+		}
+		if item.OrgID != orgID || item.TaskID != taskID {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (f *fakeComplianceRepo) List(_ context.Context, filter ports.ComplianceFilter) ([]domain.ComplianceItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.ComplianceItem
+	for _, item := range f.items {
+		if item.DeletedAt != nil {
+			continue
+		}
+		if filter.OrgID != nil && item.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.TaskID != nil && item.TaskID != *filter.TaskID {
+			continue
+		}
+		if filter.Result != nil && item.Result != *filter.Result {
+			continue
+		}
+		if filter.Signed != nil {
+			isSigned := item.SignOffTime != nil
+			if isSigned != *filter.Signed {
+				continue
+			}
+		}
+		out = append(out, item)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeComplianceRepo) Create(_ context.Context, item domain.ComplianceItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[item.ID] = item
+	return nil
+}
+
+func (f *fakeComplianceRepo) Update(_ context.Context, item domain.ComplianceItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.items[item.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	f.items[item.ID] = item
+	return nil
+}
+
+func (f *fakeComplianceRepo) SignOff(_ context.Context, orgID, id, userID uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[id]
+	if !ok || item.OrgID != orgID || item.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	item.SignOffUserID = &userID
+	item.SignOffTime = &at
+	item.UpdatedAt = at
+	f.items[id] = item
+	return nil
+}
+
 type fakeWebhookRepo struct {
 	mu    sync.Mutex
 	hooks map[uuid.UUID]domain.Webhook
@@ -741,3 +1205,181 @@ func (f *fakeOutboxRepo) GetByID(_ context.Context, _ uuid.UUID, id uuid.UUID) (
 	}
 	return event, nil
 }
+
+type fakeAlertRepo struct {
+	mu     sync.Mutex
+	alerts []domain.Alert
+}
+
+func (f *fakeAlertRepo) Create(_ context.Context, alert domain.Alert) (domain.Alert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alerts = append(f.alerts, alert)
+	return alert, nil
+}
+
+func (f *fakeAlertRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Alert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, alert := range f.alerts {
+		if alert.OrgID == orgID && alert.ID == id {
+			return alert, nil
+		}
+	}
+	return domain.Alert{}, domain.ErrNotFound
+}
+
+func (f *fakeAlertRepo) List(_ context.Context, filter ports.AlertFilter) ([]domain.Alert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Alert
+	for _, alert := range f.alerts {
+		if filter.OrgID != nil && alert.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.Level != nil && alert.Level != *filter.Level {
+			continue
+		}
+		if filter.Category != "" && alert.Category != filter.Category {
+			continue
+		}
+		if filter.Resolved != nil && alert.Resolved != *filter.Resolved {
+			continue
+		}
+		out = append(out, alert)
+	}
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeAlertRepo) Acknowledge(_ context.Context, orgID, id, userID uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, alert := range f.alerts {
+		if alert.OrgID == orgID && alert.ID == id {
+			f.alerts[i].Acknowledged = true
+			f.alerts[i].AcknowledgedBy = &userID
+			f.alerts[i].AcknowledgedAt = &at
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (f *fakeAlertRepo) Resolve(_ context.Context, orgID, id uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, alert := range f.alerts {
+		if alert.OrgID == orgID && alert.ID == id {
+			f.alerts[i].Resolved = true
+			f.alerts[i].ResolvedAt = &at
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (f *fakeAlertRepo) CountUnresolved(_ context.Context, orgID uuid.UUID) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, alert := range f.alerts {
+		if alert.OrgID == orgID && !alert.Resolved {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeDefectRepo struct {
+	mu         sync.Mutex
+	defects    map[uuid.UUID]domain.Defect
+	extensions []domain.DeferralExtension
+}
+
+func newFakeDefectRepo() *fakeDefectRepo {
+	return &fakeDefectRepo{defects: make(map[uuid.UUID]domain.Defect)}
+}
+
+func (f *fakeDefectRepo) Create(_ context.Context, defect domain.Defect) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.defects[defect.ID] = defect
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) GetByID(_ context.Context, orgID, id uuid.UUID) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defect, ok := f.defects[id]
+	if !ok || defect.OrgID != orgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) List(_ context.Context, filter ports.DefectFilter) ([]domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.Defect
+	for _, defect := range f.defects {
+		if filter.OrgID != nil && defect.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.AircraftID != nil && defect.AircraftID != *filter.AircraftID {
+			continue
+		}
+		if filter.State != nil && defect.State != *filter.State {
+			continue
+		}
+		if filter.ExpiringBefore != nil && (defect.State != domain.DefectDeferred || !defect.ExpiresAt.Before(*filter.ExpiringBefore)) {
+			continue
+		}
+		out = append(out, defect)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ReportedAt.After(out[j].ReportedAt) })
+	return applyOffsetLimit(out, filter.Offset, filter.Limit), nil
+}
+
+func (f *fakeDefectRepo) Update(_ context.Context, defect domain.Defect, from domain.DefectState) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.defects[defect.ID]
+	if !ok || existing.OrgID != defect.OrgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	if existing.State != from {
+		return domain.Defect{}, domain.NewConflictError("defect is no longer " + string(from))
+	}
+	f.defects[defect.ID] = defect
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) Extend(_ context.Context, extension domain.DeferralExtension) (domain.Defect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defect, ok := f.defects[extension.DefectID]
+	if !ok || defect.OrgID != extension.OrgID {
+		return domain.Defect{}, domain.ErrNotFound
+	}
+	if defect.State != domain.DefectDeferred || !defect.ExpiresAt.Equal(extension.PreviousExpiresAt) {
+		return domain.Defect{}, domain.NewConflictError("deferral changed in the meantime")
+	}
+	expiresAt := extension.NewExpiresAt
+	defect.ExpiresAt = &expiresAt
+	defect.UpdatedAt = extension.CreatedAt
+	f.defects[defect.ID] = defect
+	f.extensions = append(f.extensions, extension)
+	return defect, nil
+}
+
+func (f *fakeDefectRepo) ListExtensions(_ context.Context, orgID, defectID uuid.UUID) ([]domain.DeferralExtension, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []domain.DeferralExtension
+	for _, ext := range f.extensions {
+		if ext.OrgID == orgID && ext.DefectID == defectID {
+			out = append(out, ext)
+		}
+	}
+	return out, nil
+}
@@ -3,14 +3,18 @@ package jobs
 import (
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aeromaintain/amss/internal/app"
 	"github.com/aeromaintain/amss/internal/app/ports"
+	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/domain"
 	"github.com/aeromaintain/amss/pkg/observability"
 	"github.com/google/uuid"
@@ -31,8 +35,14 @@ type ImportProcessor struct {
 	Definitions ports.PartDefinitionRepository
 	Items       ports.PartItemRepository
 	Programs    ports.MaintenanceProgramRepository
-	Logger      zerolog.Logger
-	WorkerID    string
+	Utilization *services.AircraftUtilizationService
+	Flights     *services.FlightScheduleService
+	Templates   *services.ProgramTemplateService
+	// Defects blocks returning an imported aircraft to service while any of
+	// its deferrals has expired.
+	Defects  *services.DefectService
+	Logger   zerolog.Logger
+	WorkerID string
 }
 
 func (p *ImportProcessor) Run(ctx context.Context) {
@@ -113,38 +123,25 @@ func (p *ImportProcessor) processImport(ctx context.Context, importID uuid.UUID)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
-	header, err := reader.Read()
+	records, err := readImportRecords(imp.FilePath, file)
 	if err != nil {
 		observability.IncJobFailure("import_processor")
-		p.Imports.UpdateStatus(ctx, imp.OrgID, imp.ID, domain.ImportStatusFailed, map[string]any{"error": "invalid header"}, time.Now().UTC())
+		p.Imports.UpdateStatus(ctx, imp.OrgID, imp.ID, domain.ImportStatusFailed, map[string]any{"error": err.Error()}, time.Now().UTC())
 		return
 	}
-	columns := normalizeColumns(header)
 
 	var rows []domain.ImportRow
-	rowNumber := 1
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		rowNumber++
-		if err != nil {
-			continue
-		}
-		rowData := mapColumns(columns, record)
+	for _, record := range records {
 		row := domain.ImportRow{
 			ID:        uuid.New(),
 			OrgID:     imp.OrgID,
 			ImportID:  imp.ID,
-			RowNumber: rowNumber,
-			Raw:       toAnyMap(rowData),
+			RowNumber: record.number,
+			Raw:       toAnyMap(record.data),
 			CreatedAt: time.Now().UTC(),
 			UpdatedAt: time.Now().UTC(),
 		}
-		errorsList := validateRow(imp.Type, rowData)
+		errorsList := validateRow(imp.Type, record.data)
 		if len(errorsList) > 0 {
 			row.Status = domain.ImportRowInvalid
 			row.Errors = errorsList
@@ -194,20 +191,25 @@ func (p *ImportProcessor) processImport(ctx context.Context, importID uuid.UUID)
 func (p *ImportProcessor) applyRow(ctx context.Context, imp domain.Import, row domain.ImportRow) error {
 	switch imp.Type {
 	case domain.ImportTypeAircraft:
-		return p.applyAircraft(ctx, imp.OrgID, row)
+		return p.applyAircraft(ctx, imp, row)
 	case domain.ImportTypeParts:
 		return p.applyParts(ctx, imp.OrgID, row)
 	case domain.ImportTypePrograms:
 		return p.applyPrograms(ctx, imp.OrgID, row)
+	case domain.ImportTypeUtilization:
+		return p.applyUtilization(ctx, imp, row)
+	case domain.ImportTypeFlights:
+		return p.applyFlight(ctx, imp, row)
 	default:
 		return errors.New("unsupported import type")
 	}
 }
 
-func (p *ImportProcessor) applyAircraft(ctx context.Context, orgID uuid.UUID, row domain.ImportRow) error {
+func (p *ImportProcessor) applyAircraft(ctx context.Context, imp domain.Import, row domain.ImportRow) error {
 	if p.Aircraft == nil {
 		return errors.New("aircraft repo unavailable")
 	}
+	orgID := imp.OrgID
 	data := toStringMap(row.Raw)
 	tailNumber := data["tail_number"]
 	if tailNumber == "" {
@@ -215,38 +217,74 @@ func (p *ImportProcessor) applyAircraft(ctx context.Context, orgID uuid.UUID, ro
 	}
 	model := data["model"]
 	status := domain.AircraftStatus(data["status"])
-	if status == "" {
-		status = domain.AircraftOperational
-	}
 	capacity, _ := strconv.Atoi(data["capacity_slots"])
 	lastMaintenance := parseOptionalTime(data["last_maintenance"])
 	nextDue := parseOptionalTime(data["next_due"])
-	flightHours, _ := strconv.Atoi(data["flight_hours_total"])
-	cycles, _ := strconv.Atoi(data["cycles_total"])
+	flightHours, err := parseOptionalNonNegativeInt(data["flight_hours_total"])
+	if err != nil {
+		return errors.New("invalid flight_hours_total")
+	}
+	cycles, err := parseOptionalNonNegativeInt(data["cycles_total"])
+	if err != nil {
+		return errors.New("invalid cycles_total")
+	}
+	var aircraftTypeID *uuid.UUID
+	if data["aircraft_type_id"] != "" {
+		parsed, err := uuid.Parse(data["aircraft_type_id"])
+		if err != nil {
+			return errors.New("invalid aircraft_type_id")
+		}
+		aircraftTypeID = &parsed
+	}
 
 	existing, err := p.Aircraft.GetByTailNumber(ctx, orgID, tailNumber)
 	if err != nil {
 		aircraft := domain.Aircraft{
-			ID:               uuid.New(),
-			OrgID:            orgID,
-			TailNumber:       tailNumber,
-			Model:            model,
-			Status:           status,
-			CapacitySlots:    capacity,
-			LastMaintenance:  lastMaintenance,
-			NextDue:          nextDue,
-			FlightHoursTotal: flightHours,
-			CyclesTotal:      cycles,
-			CreatedAt:        time.Now().UTC(),
-			UpdatedAt:        time.Now().UTC(),
-		}
-		_, err = p.Aircraft.Create(ctx, aircraft)
+			ID:              uuid.New(),
+			OrgID:           orgID,
+			TailNumber:      tailNumber,
+			Model:           model,
+			AircraftTypeID:  aircraftTypeID,
+			Status:          domain.AircraftOperational,
+			CapacitySlots:   capacity,
+			LastMaintenance: lastMaintenance,
+			NextDue:         nextDue,
+			CreatedAt:       time.Now().UTC(),
+			UpdatedAt:       time.Now().UTC(),
+		}
+		if flightHours != nil {
+			aircraft.FlightHoursTotal = *flightHours
+		}
+		if cycles != nil {
+			aircraft.CyclesTotal = *cycles
+		}
+		if status != "" {
+			aircraft.Status = status
+		}
+		programs, err := p.templateInstances(ctx, aircraft)
+		if err != nil {
+			return err
+		}
+		_, err = p.Aircraft.CreateWithPrograms(ctx, aircraft, programs)
 		return err
 	}
 	if model != "" {
 		existing.Model = model
 	}
-	existing.Status = status
+	typeChanged := false
+	if aircraftTypeID != nil {
+		typeChanged = existing.AircraftTypeID == nil || *existing.AircraftTypeID != *aircraftTypeID
+		existing.AircraftTypeID = aircraftTypeID
+	}
+	if status != "" {
+		returning := existing.Status != domain.AircraftOperational && status == domain.AircraftOperational
+		if returning && p.Defects != nil {
+			if err := p.Defects.CheckReturnToService(ctx, existing.OrgID, existing.ID); err != nil {
+				return err
+			}
+		}
+		existing.Status = status
+	}
 	if capacity > 0 {
 		existing.CapacitySlots = capacity
 	}
@@ -256,17 +294,74 @@ func (p *ImportProcessor) applyAircraft(ctx context.Context, orgID uuid.UUID, ro
 	if nextDue != nil {
 		existing.NextDue = nextDue
 	}
-	if flightHours > 0 {
-		existing.FlightHoursTotal = flightHours
-	}
-	if cycles > 0 {
-		existing.CyclesTotal = cycles
+	if err := p.recordAircraftTotals(ctx, imp, existing, flightHours, cycles); err != nil {
+		return err
 	}
 	existing.UpdatedAt = time.Now().UTC()
-	_, err = p.Aircraft.Update(ctx, existing)
+	var programs []domain.MaintenanceProgram
+	if typeChanged {
+		programs, err = p.templateInstances(ctx, existing)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = p.Aircraft.UpdateWithPrograms(ctx, existing, programs)
 	return err
 }
 
+// recordAircraftTotals turns higher totals on an imported aircraft row into a
+// utilization entry, so the history and part usage follow the aircraft.
+// Totals lower than the recorded ones are rejected.
+func (p *ImportProcessor) recordAircraftTotals(ctx context.Context, imp domain.Import, aircraft domain.Aircraft, flightHours, cycles *int) error {
+	var hoursTotal *float64
+	if flightHours != nil {
+		if *flightHours < aircraft.FlightHoursTotal {
+			return errors.New("flight_hours_total must not be less than the current total")
+		}
+		if *flightHours > aircraft.FlightHoursTotal {
+			value := float64(*flightHours)
+			hoursTotal = &value
+		}
+	}
+	var cyclesTotal *int
+	if cycles != nil {
+		if *cycles < aircraft.CyclesTotal {
+			return errors.New("cycles_total must not be less than the current total")
+		}
+		if *cycles > aircraft.CyclesTotal {
+			cyclesTotal = cycles
+		}
+	}
+	if hoursTotal == nil && cyclesTotal == nil {
+		return nil
+	}
+	if p.Utilization == nil {
+		return errors.New("utilization service unavailable")
+	}
+	actor := app.Actor{
+		UserID: imp.CreatedBy,
+		OrgID:  imp.OrgID,
+		Role:   domain.RoleScheduler,
+	}
+	_, err := p.Utilization.Record(ctx, actor, services.UtilizationRecordInput{
+		AircraftID:  aircraft.ID,
+		EntryType:   domain.UtilizationEntryDaily,
+		Date:        time.Now().UTC(),
+		HoursTotal:  hoursTotal,
+		CyclesTotal: cyclesTotal,
+	})
+	return err
+}
+
+// templateInstances builds the programs defined by the aircraft type's
+// templates for an imported aircraft, stored with the aircraft row.
+func (p *ImportProcessor) templateInstances(ctx context.Context, aircraft domain.Aircraft) ([]domain.MaintenanceProgram, error) {
+	if p.Templates == nil {
+		return nil, nil
+	}
+	return p.Templates.InstancesForAircraft(ctx, aircraft)
+}
+
 func (p *ImportProcessor) applyParts(ctx context.Context, orgID uuid.UUID, row domain.ImportRow) error {
 	if p.Definitions == nil || p.Items == nil {
 		return errors.New("parts repo unavailable")
@@ -347,20 +442,62 @@ func (p *ImportProcessor) applyPrograms(ctx context.Context, orgID uuid.UUID, ro
 		aircraftID = &parsed
 	}
 	lastPerformed := parseOptionalTime(data["last_performed"])
+	lastPerformedHours, err := parseOptionalNonNegativeInt(data["last_performed_hours"])
+	if err != nil {
+		return errors.New("invalid last_performed_hours")
+	}
+	lastPerformedCycles, err := parseOptionalNonNegativeInt(data["last_performed_cycles"])
+	if err != nil {
+		return errors.New("invalid last_performed_cycles")
+	}
+	tolerancePercent, err := parseOptionalNonNegativeInt(data["tolerance_percent"])
+	if err != nil {
+		return errors.New("invalid tolerance_percent")
+	}
+	thresholds, err := parseProgramThresholds(data["thresholds"])
+	if err != nil {
+		return err
+	}
+	estimatedDuration, err := parseOptionalNonNegativeInt(data["estimated_duration_minutes"])
+	if err != nil {
+		return errors.New("invalid estimated_duration_minutes")
+	}
+	taskType := domain.TaskType(strings.TrimSpace(data["task_type"]))
+	priority := domain.TaskPriority(strings.TrimSpace(data["priority"]))
+	checklist := parseChecklist(data["compliance_checklist"])
 
 	existing, err := p.Programs.GetByName(ctx, orgID, name, aircraftID)
 	if err != nil {
 		program := domain.MaintenanceProgram{
-			ID:            uuid.New(),
-			OrgID:         orgID,
-			AircraftID:    aircraftID,
-			Name:          name,
-			IntervalType:  intervalType,
-			IntervalValue: intervalValue,
-			LastPerformed: lastPerformed,
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
+			ID:                  uuid.New(),
+			OrgID:               orgID,
+			AircraftID:          aircraftID,
+			Name:                name,
+			IntervalType:        intervalType,
+			IntervalValue:       intervalValue,
+			LastPerformed:       lastPerformed,
+			LastPerformedHours:  lastPerformedHours,
+			LastPerformedCycles: lastPerformedCycles,
+			Thresholds:          thresholds,
+			TaskType:            taskType,
+			Priority:            priority,
+			ComplianceChecklist: checklist,
+			DefaultNotes:        data["default_notes"],
+			CreatedAt:           time.Now().UTC(),
+			UpdatedAt:           time.Now().UTC(),
+		}
+		if tolerancePercent != nil {
+			program.TolerancePercent = *tolerancePercent
 		}
+		program.EstimatedDurationMinutes = estimatedDuration
+		if err := program.ValidateThresholds(); err != nil {
+			return err
+		}
+		if err := program.ValidateTaskDefaults(); err != nil {
+			return err
+		}
+		program.TaskType = program.GeneratedTaskType()
+		program.Priority = program.GeneratedTaskPriority()
 		_, err = p.Programs.Create(ctx, program)
 		return err
 	}
@@ -369,11 +506,132 @@ func (p *ImportProcessor) applyPrograms(ctx context.Context, orgID uuid.UUID, ro
 	if lastPerformed != nil {
 		existing.LastPerformed = lastPerformed
 	}
+	if lastPerformedHours != nil {
+		existing.LastPerformedHours = lastPerformedHours
+	}
+	if lastPerformedCycles != nil {
+		existing.LastPerformedCycles = lastPerformedCycles
+	}
+	if tolerancePercent != nil {
+		existing.TolerancePercent = *tolerancePercent
+	}
+	if _, ok := data["thresholds"]; ok {
+		existing.Thresholds = thresholds
+	}
+	if taskType != "" {
+		existing.TaskType = taskType
+	}
+	if priority != "" {
+		existing.Priority = priority
+	}
+	if estimatedDuration != nil {
+		existing.EstimatedDurationMinutes = estimatedDuration
+	}
+	if _, ok := data["compliance_checklist"]; ok {
+		existing.ComplianceChecklist = checklist
+	}
+	if notes, ok := data["default_notes"]; ok {
+		existing.DefaultNotes = notes
+	}
+	if err := existing.ValidateThresholds(); err != nil {
+		return err
+	}
+	if err := existing.ValidateTaskDefaults(); err != nil {
+		return err
+	}
 	existing.UpdatedAt = time.Now().UTC()
 	_, err = p.Programs.Update(ctx, existing)
 	return err
 }
 
+func (p *ImportProcessor) applyUtilization(ctx context.Context, imp domain.Import, row domain.ImportRow) error {
+	if p.Utilization == nil || p.Aircraft == nil {
+		return errors.New("utilization service unavailable")
+	}
+	data := toStringMap(row.Raw)
+	aircraft, err := p.Aircraft.GetByTailNumber(ctx, imp.OrgID, data["tail_number"])
+	if err != nil {
+		return errors.New("unknown tail_number")
+	}
+	date := parseOptionalDate(data["date"])
+	if date == nil {
+		return errors.New("invalid date")
+	}
+	entryType := domain.UtilizationEntryType(data["entry_type"])
+	if entryType == "" {
+		entryType = domain.UtilizationEntryDaily
+	}
+	blockHours, err := parseOptionalNonNegativeFloat(data["block_hours"])
+	if err != nil {
+		return errors.New("invalid block_hours")
+	}
+	cycles, err := parseOptionalNonNegativeInt(data["cycles"])
+	if err != nil {
+		return errors.New("invalid cycles")
+	}
+	hoursTotal, err := parseOptionalNonNegativeFloat(data["hours_total"])
+	if err != nil {
+		return errors.New("invalid hours_total")
+	}
+	cyclesTotal, err := parseOptionalNonNegativeInt(data["cycles_total"])
+	if err != nil {
+		return errors.New("invalid cycles_total")
+	}
+
+	// Rows are applied in file order, so entries must be listed chronologically.
+	actor := app.Actor{
+		UserID: imp.CreatedBy,
+		OrgID:  imp.OrgID,
+		Role:   domain.RoleScheduler,
+	}
+	_, err = p.Utilization.Record(ctx, actor, services.UtilizationRecordInput{
+		AircraftID:   aircraft.ID,
+		EntryType:    entryType,
+		Date:         *date,
+		FlightNumber: data["flight_number"],
+		BlockHours:   blockHours,
+		Cycles:       cycles,
+		HoursTotal:   hoursTotal,
+		CyclesTotal:  cyclesTotal,
+	})
+	return err
+}
+
+func (p *ImportProcessor) applyFlight(ctx context.Context, imp domain.Import, row domain.ImportRow) error {
+	if p.Flights == nil || p.Aircraft == nil {
+		return errors.New("flight schedule service unavailable")
+	}
+	data := toStringMap(row.Raw)
+	aircraft, err := p.Aircraft.GetByTailNumber(ctx, imp.OrgID, data["tail_number"])
+	if err != nil {
+		return errors.New("unknown tail_number")
+	}
+	departure := parseOptionalTime(data["departure_time"])
+	if departure == nil {
+		return errors.New("invalid departure_time")
+	}
+	arrival := parseOptionalTime(data["arrival_time"])
+	if arrival == nil {
+		return errors.New("invalid arrival_time")
+	}
+
+	actor := app.Actor{
+		UserID: imp.CreatedBy,
+		OrgID:  imp.OrgID,
+		Role:   domain.RoleScheduler,
+	}
+	_, err = p.Flights.Record(ctx, actor, services.FlightRecordInput{
+		AircraftID:    aircraft.ID,
+		FlightNumber:  data["flight_number"],
+		Origin:        data["origin"],
+		Destination:   data["destination"],
+		DepartureTime: *departure,
+		ArrivalTime:   *arrival,
+		Status:        domain.FlightStatus(strings.ToLower(data["status"])),
+	})
+	return err
+}
+
 func validateRow(importType domain.ImportType, row map[string]string) []string {
 	var errorsList []string
 	switch importType {
@@ -407,12 +665,102 @@ func validateRow(importType domain.ImportType, row map[string]string) []string {
 		if row["interval_value"] == "" {
 			errorsList = append(errorsList, "interval_value is required")
 		}
+	case domain.ImportTypeUtilization:
+		if row["tail_number"] == "" {
+			errorsList = append(errorsList, "tail_number is required")
+		}
+		if row["date"] == "" {
+			errorsList = append(errorsList, "date is required")
+		}
+		if row["block_hours"] == "" && row["cycles"] == "" && row["hours_total"] == "" && row["cycles_total"] == "" {
+			errorsList = append(errorsList, "block_hours, cycles, hours_total or cycles_total is required")
+		}
+	case domain.ImportTypeFlights:
+		for _, column := range []string{"tail_number", "flight_number", "origin", "destination", "departure_time", "arrival_time"} {
+			if row[column] == "" {
+				errorsList = append(errorsList, column+" is required")
+			}
+		}
 	default:
 		errorsList = append(errorsList, "unsupported import type")
 	}
 	return errorsList
 }
 
+// importRecord is one row of an import file by its row number.
+type importRecord struct {
+	number int
+	data   map[string]string
+}
+
+// readImportRecords reads the rows of an import file. Files ending in .json
+// hold an array of objects; anything else is CSV with a header row.
+func readImportRecords(path string, file io.Reader) ([]importRecord, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return readJSONRecords(file)
+	}
+	return readCSVRecords(file)
+}
+
+func readCSVRecords(file io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid header")
+	}
+	columns := normalizeColumns(header)
+
+	var records []importRecord
+	rowNumber := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		rowNumber++
+		if err != nil {
+			continue
+		}
+		records = append(records, importRecord{number: rowNumber, data: mapColumns(columns, record)})
+	}
+	return records, nil
+}
+
+// readJSONRecords reads an array of flat objects. Keys are matched like CSV
+// columns and values are kept as their text.
+func readJSONRecords(file io.Reader) ([]importRecord, error) {
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, errors.New("invalid json")
+	}
+	records := make([]importRecord, 0, len(objects))
+	for i, object := range objects {
+		data := make(map[string]string, len(object))
+		for key, value := range object {
+			key = strings.TrimSpace(strings.ToLower(key))
+			switch v := value.(type) {
+			case nil:
+				data[key] = ""
+			case string:
+				data[key] = strings.TrimSpace(v)
+			case json.Number:
+				data[key] = v.String()
+			case bool:
+				data[key] = strconv.FormatBool(v)
+			default:
+				// Nested values are kept as JSON text.
+				encoded, _ := json.Marshal(v)
+				data[key] = string(encoded)
+			}
+		}
+		records = append(records, importRecord{number: i + 1, data: data})
+	}
+	return records, nil
+}
+
 func normalizeColumns(cols []string) []string {
 	out := make([]string, 0, len(cols))
 	for _, col := range cols {
@@ -463,6 +811,86 @@ func parseOptionalTime(value string) *time.Time {
 	return &parsed
 }
 
+// parseProgramThresholds parses additional program thresholds written as
+// semicolon separated "interval_type:interval_value[:tolerance_percent]"
+// entries, e.g. "cycles:3000:10;calendar:365".
+func parseProgramThresholds(value string) ([]domain.ProgramThreshold, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	var thresholds []domain.ProgramThreshold
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, errors.New("invalid thresholds")
+		}
+		threshold := domain.ProgramThreshold{IntervalType: domain.MaintenanceProgramIntervalType(strings.TrimSpace(parts[0]))}
+		intervalValue, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, errors.New("invalid thresholds")
+		}
+		threshold.IntervalValue = intervalValue
+		if len(parts) == 3 {
+			tolerance, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+			if err != nil {
+				return nil, errors.New("invalid thresholds")
+			}
+			threshold.TolerancePercent = tolerance
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// parseChecklist splits semicolon separated compliance checklist items,
+// dropping blank entries.
+func parseChecklist(value string) []string {
+	var items []string
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			items = append(items, entry)
+		}
+	}
+	return items
+}
+
+// parseOptionalDate accepts RFC 3339 timestamps as well as plain dates.
+func parseOptionalDate(value string) *time.Time {
+	if parsed := parseOptionalTime(value); parsed != nil {
+		return parsed
+	}
+	parsed, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+func parseOptionalNonNegativeFloat(value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		return nil, errors.New("invalid number")
+	}
+	return &parsed, nil
+}
+
+func parseOptionalNonNegativeInt(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return nil, errors.New("invalid integer")
+	}
+	return &parsed, nil
+}
+
 func findDefinitionByName(ctx context.Context, repo ports.PartDefinitionRepository, orgID uuid.UUID, name string) (domain.PartDefinition, error) {
 	defs, err := repo.List(ctx, ports.PartDefinitionFilter{
 		OrgID: &orgID,
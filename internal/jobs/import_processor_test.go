@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/domain"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -58,12 +59,238 @@ func TestImportProcessorProcessAircraft(t *testing.T) {
 	}
 }
 
+func TestImportProcessorRecordsAircraftTotalsAsUtilization(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aircraft.csv")
+	content := "tail_number,model,capacity_slots,flight_hours_total,cycles_total\n" +
+		"N123,737,1,1012,405\n" +
+		"N123,737,1,1005,410\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	orgID := uuid.New()
+	importID := uuid.New()
+	importRepo := newFakeImportRepo()
+	rowRepo := newFakeImportRowRepo()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N123",
+		Model:            "737",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+	})
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+
+	_, _ = importRepo.Create(ctx, domain.Import{
+		ID:        importID,
+		OrgID:     orgID,
+		Type:      domain.ImportTypeAircraft,
+		Status:    domain.ImportStatusPending,
+		FileName:  "aircraft.csv",
+		FilePath:  path,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	processor := &ImportProcessor{
+		Imports:    importRepo,
+		ImportRows: rowRepo,
+		Aircraft:   aircraftRepo,
+		Utilization: &services.AircraftUtilizationService{
+			Utilization: utilizationRepo,
+			Aircraft:    aircraftRepo,
+		},
+		Logger: zerolog.Nop(),
+	}
+	processor.processImport(ctx, importID)
+
+	updated, err := importRepo.GetByID(ctx, orgID, importID)
+	if err != nil {
+		t.Fatalf("fetch import: %v", err)
+	}
+	if updated.Summary["applied"] != 1 || updated.Summary["invalid"] != 1 {
+		t.Fatalf("expected 1 applied and 1 invalid rows, got %v", updated.Summary)
+	}
+	current, _ := aircraftRepo.GetByID(ctx, orgID, aircraft.ID)
+	if current.FlightHoursTotal != 1012 || current.CyclesTotal != 405 {
+		t.Fatalf("expected aircraft totals 1012/405, got %d/%d", current.FlightHoursTotal, current.CyclesTotal)
+	}
+	if len(utilizationRepo.entries) != 1 {
+		t.Fatalf("expected 1 utilization entry, got %d", len(utilizationRepo.entries))
+	}
+	if entry := utilizationRepo.entries[0]; entry.BlockHours != 12 || entry.Cycles != 5 {
+		t.Fatalf("expected utilization of 12h/5 cycles, got %.2f/%d", entry.BlockHours, entry.Cycles)
+	}
+}
+
+func TestImportProcessorAircraftStatus(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aircraft.csv")
+	content := "tail_number,model,capacity_slots,status\n" +
+		"N123,737,1,operational\n" +
+		"N456,A320,2,\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	orgID := uuid.New()
+	importID := uuid.New()
+	importRepo := newFakeImportRepo()
+	rowRepo := newFakeImportRowRepo()
+	aircraftRepo := newFakeAircraftRepo()
+	grounded, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N123",
+		Model:         "737",
+		Status:        domain.AircraftGrounded,
+		CapacitySlots: 1,
+	})
+	inMaintenance, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N456",
+		Model:         "A320",
+		Status:        domain.AircraftMaintenance,
+		CapacitySlots: 1,
+	})
+	defectRepo := newFakeDefectRepo()
+	expiredAt := time.Now().UTC().Add(-time.Hour)
+	_, _ = defectRepo.Create(ctx, domain.Defect{
+		ID:                uuid.New(),
+		OrgID:             orgID,
+		AircraftID:        grounded.ID,
+		Title:             "Cabin PA inoperative",
+		State:             domain.DefectDeferred,
+		DeferralReference: "DD-001",
+		ExpiresAt:         &expiredAt,
+	})
+
+	_, _ = importRepo.Create(ctx, domain.Import{
+		ID:        importID,
+		OrgID:     orgID,
+		Type:      domain.ImportTypeAircraft,
+		Status:    domain.ImportStatusPending,
+		FileName:  "aircraft.csv",
+		FilePath:  path,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	processor := &ImportProcessor{
+		Imports:    importRepo,
+		ImportRows: rowRepo,
+		Aircraft:   aircraftRepo,
+		Defects:    &services.DefectService{Defects: defectRepo},
+		Logger:     zerolog.Nop(),
+	}
+	processor.processImport(ctx, importID)
+
+	updated, err := importRepo.GetByID(ctx, orgID, importID)
+	if err != nil {
+		t.Fatalf("fetch import: %v", err)
+	}
+	if updated.Summary["applied"] != 1 || updated.Summary["invalid"] != 1 {
+		t.Fatalf("expected 1 applied and 1 invalid rows, got %v", updated.Summary)
+	}
+	current, _ := aircraftRepo.GetByID(ctx, orgID, grounded.ID)
+	if current.Status != domain.AircraftGrounded {
+		t.Fatalf("expected aircraft with expired deferral to stay grounded, got %s", current.Status)
+	}
+	current, _ = aircraftRepo.GetByID(ctx, orgID, inMaintenance.ID)
+	if current.Status != domain.AircraftMaintenance || current.CapacitySlots != 2 {
+		t.Fatalf("expected status kept as maintenance with 2 slots, got %s with %d", current.Status, current.CapacitySlots)
+	}
+}
+
+func TestImportProcessorInstantiatesProgramTemplates(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	aircraftTypeID := uuid.New()
+	path := filepath.Join(dir, "aircraft.csv")
+	content := "tail_number,model,capacity_slots,aircraft_type_id\nN321,A320,2," + aircraftTypeID.String() + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	orgID := uuid.New()
+	importID := uuid.New()
+	importRepo := newFakeImportRepo()
+	rowRepo := newFakeImportRowRepo()
+	aircraftRepo := newFakeAircraftRepo()
+	programRepo := newFakeProgramRepo()
+	aircraftRepo.programs = programRepo
+	templateRepo := newFakeProgramTemplateRepo()
+	template, _ := templateRepo.Create(ctx, domain.MaintenanceProgramTemplate{
+		ID:             uuid.New(),
+		OrgID:          orgID,
+		AircraftTypeID: aircraftTypeID,
+		Name:           "A-Check",
+		IntervalType:   domain.ProgramIntervalFlightHours,
+		IntervalValue:  600,
+		Revision:       1,
+	})
+
+	_, _ = importRepo.Create(ctx, domain.Import{
+		ID:        importID,
+		OrgID:     orgID,
+		Type:      domain.ImportTypeAircraft,
+		Status:    domain.ImportStatusPending,
+		FileName:  "aircraft.csv",
+		FilePath:  path,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	processor := &ImportProcessor{
+		Imports:    importRepo,
+		ImportRows: rowRepo,
+		Aircraft:   aircraftRepo,
+		Templates: &services.ProgramTemplateService{
+			Templates: templateRepo,
+			Programs:  programRepo,
+			Aircraft:  aircraftRepo,
+		},
+		Logger: zerolog.Nop(),
+	}
+	processor.processImport(ctx, importID)
+
+	updated, err := importRepo.GetByID(ctx, orgID, importID)
+	if err != nil {
+		t.Fatalf("fetch import: %v", err)
+	}
+	if updated.Status != domain.ImportStatusCompleted {
+		t.Fatalf("expected status completed, got %s", updated.Status)
+	}
+	if len(programRepo.programs) != 1 {
+		t.Fatalf("expected 1 program, got %d", len(programRepo.programs))
+	}
+	for _, program := range programRepo.programs {
+		if program.TemplateID == nil || *program.TemplateID != template.ID {
+			t.Fatalf("expected program from template %s, got %v", template.ID, program.TemplateID)
+		}
+		if program.Name != "A-Check" || program.IntervalValue != 600 {
+			t.Fatalf("expected template definition, got %s/%d", program.Name, program.IntervalValue)
+		}
+	}
+}
+
 func TestImportProcessorProcessPrograms(t *testing.T) {
 	ctx := context.Background()
 	dir := t.TempDir()
 	aircraftID := uuid.New()
 	path := filepath.Join(dir, "programs.csv")
-	content := "name,interval_type,interval_value,aircraft_id\nA-Check,calendar,30," + aircraftID.String() + "\n"
+	content := "name,interval_type,interval_value,aircraft_id,tolerance_percent,thresholds,task_type,priority,estimated_duration_minutes,compliance_checklist\nA-Check,calendar,30," + aircraftID.String() + ",10,flight_hours:600:5;cycles:400,repair,urgent,240,Check brakes; Check tyres\n"
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		t.Fatalf("write csv: %v", err)
 	}
@@ -108,6 +335,167 @@ func TestImportProcessorProcessPrograms(t *testing.T) {
 		if program.AircraftID == nil || *program.AircraftID != aircraftID {
 			t.Fatalf("expected aircraft id %s, got %v", aircraftID, program.AircraftID)
 		}
+		if program.TolerancePercent != 10 {
+			t.Fatalf("expected tolerance 10, got %d", program.TolerancePercent)
+		}
+		if len(program.Thresholds) != 2 || program.Thresholds[0].IntervalType != domain.ProgramIntervalFlightHours || program.Thresholds[0].TolerancePercent != 5 || program.Thresholds[1].IntervalValue != 400 {
+			t.Fatalf("unexpected thresholds %+v", program.Thresholds)
+		}
+		if program.TaskType != domain.TaskTypeRepair || program.Priority != domain.PriorityUrgent {
+			t.Fatalf("expected urgent repair program, got %s %s", program.Priority, program.TaskType)
+		}
+		if program.EstimatedDurationMinutes == nil || *program.EstimatedDurationMinutes != 240 {
+			t.Fatalf("expected 240 minute duration, got %v", program.EstimatedDurationMinutes)
+		}
+		if len(program.ComplianceChecklist) != 2 || program.ComplianceChecklist[1] != "Check tyres" {
+			t.Fatalf("unexpected checklist %v", program.ComplianceChecklist)
+		}
+	}
+}
+
+func TestImportProcessorProcessUtilization(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "utilization.csv")
+	content := "tail_number,date,entry_type,block_hours,cycles,hours_total,cycles_total\n" +
+		"N123,2024-03-01,daily,6.5,4,,\n" +
+		"N123,2024-03-02,daily,,,1010,408\n" +
+		"N123,2024-03-03,daily,,,1005,409\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	orgID := uuid.New()
+	importID := uuid.New()
+	importRepo := newFakeImportRepo()
+	rowRepo := newFakeImportRowRepo()
+	aircraftRepo := newFakeAircraftRepo()
+	aircraft, _ := aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               uuid.New(),
+		OrgID:            orgID,
+		TailNumber:       "N123",
+		Model:            "737",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1000,
+		CyclesTotal:      400,
+	})
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+
+	_, _ = importRepo.Create(ctx, domain.Import{
+		ID:        importID,
+		OrgID:     orgID,
+		Type:      domain.ImportTypeUtilization,
+		Status:    domain.ImportStatusPending,
+		FileName:  "utilization.csv",
+		FilePath:  path,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	processor := &ImportProcessor{
+		Imports:    importRepo,
+		ImportRows: rowRepo,
+		Aircraft:   aircraftRepo,
+		Utilization: &services.AircraftUtilizationService{
+			Utilization: utilizationRepo,
+			Aircraft:    aircraftRepo,
+		},
+		Logger: zerolog.Nop(),
+	}
+	processor.processImport(ctx, importID)
+
+	updated, err := importRepo.GetByID(ctx, orgID, importID)
+	if err != nil {
+		t.Fatalf("fetch import: %v", err)
+	}
+	if updated.Status != domain.ImportStatusCompleted {
+		t.Fatalf("expected status completed, got %s", updated.Status)
+	}
+	if updated.Summary["applied"] != 2 || updated.Summary["invalid"] != 1 {
+		t.Fatalf("expected 2 applied and 1 invalid rows, got %v", updated.Summary)
+	}
+	current, _ := aircraftRepo.GetByID(ctx, orgID, aircraft.ID)
+	if current.FlightHoursTotal != 1010 || current.CyclesTotal != 408 {
+		t.Fatalf("expected aircraft totals 1010/408, got %d/%d", current.FlightHoursTotal, current.CyclesTotal)
+	}
+	if len(utilizationRepo.entries) != 2 {
+		t.Fatalf("expected 2 utilization entries, got %d", len(utilizationRepo.entries))
+	}
+}
+
+func TestImportProcessorProcessFlightScheduleJSON(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flights.json")
+	content := `[
+		{"tail_number": "N123", "flight_number": "am100", "origin": "jfk", "destination": "bos", "departure_time": "2024-03-01T08:00:00Z", "arrival_time": "2024-03-01T09:30:00Z"},
+		{"Tail_Number": "N123", "flight_number": "AM101", "origin": "BOS", "destination": "JFK", "departure_time": "2024-03-01T14:00:00Z", "arrival_time": "2024-03-01T15:30:00Z", "status": "scheduled"},
+		{"tail_number": "N123", "flight_number": "AM102", "origin": "JFK", "destination": "ORD", "departure_time": "2024-03-01T09:00:00Z", "arrival_time": "2024-03-01T11:00:00Z"},
+		{"tail_number": "N123", "flight_number": "AM103", "origin": "JFK"}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+
+	orgID := uuid.New()
+	importID := uuid.New()
+	importRepo := newFakeImportRepo()
+	rowRepo := newFakeImportRowRepo()
+	aircraftRepo := newFakeAircraftRepo()
+	_, _ = aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		TailNumber:    "N123",
+		Model:         "737",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+	})
+	flightRepo := newFakePlannedFlightRepo()
+
+	_, _ = importRepo.Create(ctx, domain.Import{
+		ID:        importID,
+		OrgID:     orgID,
+		Type:      domain.ImportTypeFlights,
+		Status:    domain.ImportStatusPending,
+		FileName:  "flights.json",
+		FilePath:  path,
+		CreatedBy: uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+
+	processor := &ImportProcessor{
+		Imports:    importRepo,
+		ImportRows: rowRepo,
+		Aircraft:   aircraftRepo,
+		Flights: &services.FlightScheduleService{
+			Flights:  flightRepo,
+			Aircraft: aircraftRepo,
+		},
+		Logger: zerolog.Nop(),
+	}
+	processor.processImport(ctx, importID)
+
+	updated, err := importRepo.GetByID(ctx, orgID, importID)
+	if err != nil {
+		t.Fatalf("fetch import: %v", err)
+	}
+	if updated.Status != domain.ImportStatusCompleted {
+		t.Fatalf("expected status completed, got %s", updated.Status)
+	}
+	// The third flight overlaps the first and the fourth is incomplete.
+	if updated.Summary["applied"] != 2 || updated.Summary["invalid"] != 2 {
+		t.Fatalf("expected 2 applied and 2 invalid rows, got %v", updated.Summary)
+	}
+	if len(flightRepo.flights) != 2 {
+		t.Fatalf("expected 2 planned flights, got %d", len(flightRepo.flights))
+	}
+	for _, flight := range flightRepo.flights {
+		if flight.FlightNumber == "AM100" && (flight.Origin != "JFK" || flight.Destination != "BOS") {
+			t.Fatalf("expected station codes upper-cased, got %+v", flight)
+		}
 	}
 }
 
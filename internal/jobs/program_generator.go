@@ -39,13 +39,22 @@ func (g *ProgramGenerator) process(ctx context.Context) {
 		OrgID:  uuidNew(),
 		Role:   domain.RoleAdmin,
 	}
-	created, err := g.Programs.GenerateDueTasks(ctx, actor, 100)
+	result, err := g.Programs.GenerateDueTasks(ctx, actor, 100)
 	if err != nil {
 		observability.IncJobFailure("program_generator")
 		g.Logger.Error().Err(err).Msg("program generation failed")
 		return
 	}
-	if created > 0 {
-		g.Logger.Info().Int("tasks", created).Msg("program tasks generated")
+	for _, failure := range result.Failed {
+		g.Logger.Warn().Err(failure.Err).
+			Str("program_id", failure.ProgramID.String()).
+			Str("aircraft_id", failure.AircraftID.String()).
+			Msg("program task generation failed")
+	}
+	if len(result.Failed) > 0 {
+		observability.IncJobFailure("program_generator")
+	}
+	if result.Created > 0 || len(result.Failed) > 0 {
+		g.Logger.Info().Int("tasks", result.Created).Int("failed", len(result.Failed)).Msg("program tasks generated")
 	}
 }
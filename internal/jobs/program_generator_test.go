@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aeromaintain/amss/internal/app"
 	"github.com/aeromaintain/amss/internal/app/services"
 	"github.com/aeromaintain/amss/internal/domain"
 	"github.com/google/uuid"
@@ -57,3 +58,411 @@ func TestProgramGeneratorCreatesTasks(t *testing.T) {
 		}
 	}
 }
+
+func TestProgramGeneratorCreatesTasksForUsagePrograms(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	now := time.Now().UTC()
+	lastHours := 1200
+	lastCycles := 900
+
+	aircraftRepo := newFakeAircraftRepo()
+	_, _ = aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               aircraftID,
+		OrgID:            orgID,
+		TailNumber:       "N100AM",
+		Model:            "A320",
+		Status:           domain.AircraftOperational,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1790,
+		CyclesTotal:      950,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+
+	hoursDue := domain.MaintenanceProgram{
+		ID:                 uuid.New(),
+		OrgID:              orgID,
+		AircraftID:         &aircraftID,
+		Name:               "600 FH inspection",
+		IntervalType:       domain.ProgramIntervalFlightHours,
+		IntervalValue:      600,
+		LastPerformedHours: &lastHours,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	cyclesNotDue := domain.MaintenanceProgram{
+		ID:                  uuid.New(),
+		OrgID:               orgID,
+		AircraftID:          &aircraftID,
+		Name:                "Landing gear check",
+		IntervalType:        domain.ProgramIntervalCycles,
+		IntervalValue:       100,
+		LastPerformedCycles: &lastCycles,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	programRepo := newFakeProgramRepo()
+	programRepo.due = []domain.MaintenanceProgram{hoursDue, cyclesNotDue}
+	taskRepo := newFakeTaskRepo()
+	programService := &services.MaintenanceProgramService{
+		Programs:  programRepo,
+		Aircraft:  aircraftRepo,
+		Tasks:     taskRepo,
+		TaskSvc:   &services.TaskService{Tasks: taskRepo},
+		LookAhead: domain.ProgramLookAhead{FlightHours: 25, Cycles: 10},
+	}
+
+	generator := &ProgramGenerator{
+		Programs: programService,
+		Logger:   zerolog.Nop(),
+	}
+	generator.process(ctx)
+
+	if len(taskRepo.tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(taskRepo.tasks))
+	}
+	for _, task := range taskRepo.tasks {
+		if task.ProgramID == nil || *task.ProgramID != hoursDue.ID {
+			t.Fatalf("expected task for program %s, got %v", hoursDue.ID, task.ProgramID)
+		}
+	}
+}
+
+func TestProgramGeneratorUsesEarliestThresholdWindow(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	now := time.Now().UTC()
+	lastPerformed := now.AddDate(0, 0, -100)
+	lastCycles := 900
+
+	aircraftRepo := newFakeAircraftRepo()
+	_, _ = aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:            aircraftID,
+		OrgID:         orgID,
+		TailNumber:    "N200AM",
+		Model:         "A320",
+		Status:        domain.AircraftOperational,
+		CapacitySlots: 1,
+		CyclesTotal:   995,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	utilizationRepo := newFakeUtilizationRepo(aircraftRepo)
+	utilizationRepo.entries = append(utilizationRepo.entries, domain.AircraftUtilization{
+		ID:              uuid.New(),
+		OrgID:           orgID,
+		AircraftID:      aircraftID,
+		EntryType:       domain.UtilizationEntryDaily,
+		UtilizationDate: now.AddDate(0, 0, -9),
+		Cycles:          20,
+		CyclesTotal:     995,
+		CreatedAt:       now,
+	})
+
+	// The 365 day calendar interval is months away, but the cycle threshold
+	// is 5 cycles out at 2 cycles per day with a ±10% (10 cycle) tolerance.
+	program := domain.MaintenanceProgram{
+		ID:                  uuid.New(),
+		OrgID:               orgID,
+		AircraftID:          &aircraftID,
+		Name:                "Gear inspection",
+		IntervalType:        domain.ProgramIntervalCalendar,
+		IntervalValue:       365,
+		LastPerformed:       &lastPerformed,
+		LastPerformedCycles: &lastCycles,
+		Thresholds: []domain.ProgramThreshold{
+			{IntervalType: domain.ProgramIntervalCycles, IntervalValue: 100, TolerancePercent: 10},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	programRepo := newFakeProgramRepo()
+	programRepo.due = []domain.MaintenanceProgram{program}
+	taskRepo := newFakeTaskRepo()
+	programService := &services.MaintenanceProgramService{
+		Programs:    programRepo,
+		Aircraft:    aircraftRepo,
+		Utilization: utilizationRepo,
+		Tasks:       taskRepo,
+		TaskSvc:     &services.TaskService{Tasks: taskRepo},
+		LookAhead:   domain.ProgramLookAhead{Days: 7, FlightHours: 25, Cycles: 10},
+	}
+
+	generator := &ProgramGenerator{
+		Programs: programService,
+		Logger:   zerolog.Nop(),
+	}
+	generator.process(ctx)
+
+	if len(taskRepo.tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(taskRepo.tasks))
+	}
+	for _, task := range taskRepo.tasks {
+		if task.StartTime.Sub(now) > 2*time.Hour {
+			t.Fatalf("expected task to start within the open tolerance window, got %s", task.StartTime)
+		}
+		// Due in 2.5 days, window closes 5 days later.
+		expectedEnd := now.Add(180 * time.Hour)
+		if diff := task.EndTime.Sub(expectedEnd); diff < -time.Hour || diff > time.Hour {
+			t.Fatalf("expected task to end near %s, got %s", expectedEnd, task.EndTime)
+		}
+	}
+}
+
+func TestProgramGeneratorAppliesProgramTaskDefaults(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	now := time.Now().UTC()
+	duration := 90
+	program := domain.MaintenanceProgram{
+		ID:                       uuid.New(),
+		OrgID:                    orgID,
+		AircraftID:               &aircraftID,
+		Name:                     "Landing gear overhaul",
+		IntervalType:             domain.ProgramIntervalCalendar,
+		IntervalValue:            30,
+		TaskType:                 domain.TaskTypeOverhaul,
+		Priority:                 domain.PriorityUrgent,
+		EstimatedDurationMinutes: &duration,
+		ComplianceChecklist:      []string{"Inspect actuator seals", "Record torque values"},
+		DefaultNotes:             "Use overhaul kit 32-11",
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+
+	programRepo := newFakeProgramRepo()
+	programRepo.due = []domain.MaintenanceProgram{program}
+	taskRepo := newFakeTaskRepo()
+	complianceRepo := newFakeComplianceRepo()
+	taskRepo.compliance = complianceRepo
+	programService := &services.MaintenanceProgramService{
+		Programs: programRepo,
+		Tasks:    taskRepo,
+		TaskSvc:  &services.TaskService{Tasks: taskRepo, Compliance: complianceRepo},
+	}
+
+	generator := &ProgramGenerator{
+		Programs: programService,
+		Logger:   zerolog.Nop(),
+	}
+	generator.process(ctx)
+
+	if len(taskRepo.tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(taskRepo.tasks))
+	}
+	for _, task := range taskRepo.tasks {
+		if task.Type != domain.TaskTypeOverhaul {
+			t.Fatalf("expected overhaul task, got %s", task.Type)
+		}
+		if task.Priority != domain.PriorityUrgent {
+			t.Fatalf("expected urgent priority, got %s", task.Priority)
+		}
+		if got := task.EndTime.Sub(task.StartTime); got != 90*time.Minute {
+			t.Fatalf("expected 90 minute task, got %s", got)
+		}
+		if task.Notes != "Use overhaul kit 32-11" {
+			t.Fatalf("expected program notes, got %q", task.Notes)
+		}
+		items, _ := complianceRepo.ListByTask(ctx, orgID, task.ID)
+		if len(items) != 2 {
+			t.Fatalf("expected 2 compliance items, got %d", len(items))
+		}
+		for _, item := range items {
+			if item.Result != domain.CompliancePending || item.SignOffTime != nil {
+				t.Fatalf("expected pending unsigned compliance item, got %+v", item)
+			}
+		}
+	}
+}
+
+func TestProgramGeneratorSkipsProgramsCompletedSinceDue(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	mechanicID := uuid.New()
+	now := time.Now().UTC()
+	lastHours := 1200
+
+	aircraftRepo := newFakeAircraftRepo()
+	_, _ = aircraftRepo.Create(ctx, domain.Aircraft{
+		ID:               aircraftID,
+		OrgID:            orgID,
+		TailNumber:       "N300AM",
+		Model:            "A320",
+		Status:           domain.AircraftGrounded,
+		CapacitySlots:    1,
+		FlightHoursTotal: 1810,
+		CyclesTotal:      950,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	programRepo := newFakeProgramRepo()
+	program, _ := programRepo.Create(ctx, domain.MaintenanceProgram{
+		ID:                 uuid.New(),
+		OrgID:              orgID,
+		AircraftID:         &aircraftID,
+		Name:               "600 FH inspection",
+		IntervalType:       domain.ProgramIntervalFlightHours,
+		IntervalValue:      600,
+		LastPerformedHours: &lastHours,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	})
+	taskRepo := newFakeTaskRepo()
+	taskRepo.programs = programRepo
+	taskRepo.aircraft = aircraftRepo
+	taskService := &services.TaskService{Tasks: taskRepo, Aircraft: aircraftRepo}
+	generator := &ProgramGenerator{
+		Programs: &services.MaintenanceProgramService{
+			Programs: programRepo,
+			Aircraft: aircraftRepo,
+			Tasks:    taskRepo,
+			TaskSvc:  taskService,
+		},
+		Logger: zerolog.Nop(),
+	}
+
+	generator.process(ctx)
+	if len(taskRepo.tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(taskRepo.tasks))
+	}
+	var generated domain.MaintenanceTask
+	for _, task := range taskRepo.tasks {
+		generated = task
+	}
+	generated.State = domain.TaskStateInProgress
+	generated.AssignedMechanicID = &mechanicID
+	_, _ = taskRepo.Update(ctx, generated)
+
+	scheduler := app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleScheduler}
+	if _, err := taskService.TransitionState(ctx, scheduler, generated.ID, domain.TaskStateCompleted, services.TaskTransitionOptions{
+		AllowEarlyCompletion: true,
+		Notes:                "Inspection carried out",
+	}); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	updated, _ := programRepo.GetByID(ctx, orgID, program.ID)
+	if updated.LastPerformed == nil || updated.LastPerformedHours == nil || *updated.LastPerformedHours != 1810 || updated.LastPerformedCycles == nil || *updated.LastPerformedCycles != 950 {
+		t.Fatalf("expected the completion to be recorded on the program, got %+v", updated)
+	}
+
+	generator.process(ctx)
+	if len(taskRepo.tasks) != 1 {
+		t.Fatalf("expected no new task after completion, got %d tasks", len(taskRepo.tasks))
+	}
+}
+
+func TestProgramGeneratorPlacesTasksAfterAircraftBookings(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	now := time.Now().UTC()
+	duration := 120
+
+	programRepo := newFakeProgramRepo()
+	for _, name := range []string{"A-Check", "Cabin inspection", "Engine borescope"} {
+		programRepo.due = append(programRepo.due, domain.MaintenanceProgram{
+			ID:                       uuid.New(),
+			OrgID:                    orgID,
+			AircraftID:               &aircraftID,
+			Name:                     name,
+			IntervalType:             domain.ProgramIntervalCalendar,
+			IntervalValue:            30,
+			EstimatedDurationMinutes: &duration,
+			CreatedAt:                now,
+			UpdatedAt:                now,
+		})
+	}
+	taskRepo := newFakeTaskRepo()
+	booked, _ := taskRepo.Create(ctx, domain.MaintenanceTask{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AircraftID: aircraftID,
+		Type:       domain.TaskTypeRepair,
+		State:      domain.TaskStateScheduled,
+		StartTime:  now.Add(30 * time.Minute),
+		EndTime:    now.Add(4 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	programService := &services.MaintenanceProgramService{
+		Programs: programRepo,
+		Tasks:    taskRepo,
+		TaskSvc:  &services.TaskService{Tasks: taskRepo},
+	}
+
+	result, err := programService.GenerateDueTasks(ctx, app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleAdmin}, 100)
+	if err != nil {
+		t.Fatalf("generate due tasks: %v", err)
+	}
+	if result.Created != 3 || len(result.Failed) != 0 {
+		t.Fatalf("expected 3 tasks and no failures, got %+v", result)
+	}
+	tasks := make([]domain.MaintenanceTask, 0, len(taskRepo.tasks))
+	for _, task := range taskRepo.tasks {
+		tasks = append(tasks, task)
+	}
+	for i, task := range tasks {
+		for _, other := range tasks[i+1:] {
+			if task.Overlaps(other.StartTime, other.EndTime) {
+				t.Fatalf("expected tasks not to overlap, got %s-%s and %s-%s", task.StartTime, task.EndTime, other.StartTime, other.EndTime)
+			}
+		}
+		if task.ID != booked.ID && task.StartTime.Before(booked.EndTime) {
+			t.Fatalf("expected generated task after the existing booking, got start %s", task.StartTime)
+		}
+	}
+}
+
+func TestGenerateDueTasksReportsFailures(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	aircraftID := uuid.New()
+	now := time.Now().UTC()
+	failing := domain.MaintenanceProgram{
+		ID:                  uuid.New(),
+		OrgID:               orgID,
+		AircraftID:          &aircraftID,
+		Name:                "Cabin inspection",
+		IntervalType:        domain.ProgramIntervalCalendar,
+		IntervalValue:       30,
+		ComplianceChecklist: []string{"Check seat belts"},
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	programRepo := newFakeProgramRepo()
+	programRepo.due = []domain.MaintenanceProgram{failing, {
+		ID:            uuid.New(),
+		OrgID:         orgID,
+		AircraftID:    &aircraftID,
+		Name:          "A-Check",
+		IntervalType:  domain.ProgramIntervalCalendar,
+		IntervalValue: 30,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}}
+	// Without a compliance repository the checklist task cannot be stored.
+	taskRepo := newFakeTaskRepo()
+	programService := &services.MaintenanceProgramService{
+		Programs: programRepo,
+		Tasks:    taskRepo,
+		TaskSvc:  &services.TaskService{Tasks: taskRepo},
+	}
+
+	result, err := programService.GenerateDueTasks(ctx, app.Actor{UserID: uuid.New(), OrgID: orgID, Role: domain.RoleAdmin}, 100)
+	if err != nil {
+		t.Fatalf("generate due tasks: %v", err)
+	}
+	if result.Created != 1 {
+		t.Fatalf("expected 1 task created, got %d", result.Created)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].ProgramID != failing.ID || result.Failed[0].Err == nil {
+		t.Fatalf("expected the checklist program to be reported as failed, got %+v", result.Failed)
+	}
+}
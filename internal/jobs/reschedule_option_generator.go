@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeromaintain/amss/internal/app"
+	"github.com/aeromaintain/amss/internal/app/services"
+	"github.com/aeromaintain/amss/internal/domain"
+	"github.com/aeromaintain/amss/pkg/observability"
+	"github.com/rs/zerolog"
+)
+
+// RescheduleOptionGenerator periodically looks for scheduled tasks holding
+// a reservation on a part item that is no longer in stock and proposes
+// reschedule options for them.
+type RescheduleOptionGenerator struct {
+	Options  *services.RescheduleOptionService
+	Logger   zerolog.Logger
+	Interval time.Duration
+}
+
+func (g *RescheduleOptionGenerator) Run(ctx context.Context) {
+	interval := g.Interval
+	if interval == 0 {
+		interval = 15 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.process(ctx)
+		}
+	}
+}
+
+func (g *RescheduleOptionGenerator) process(ctx context.Context) {
+	if g.Options == nil {
+		return
+	}
+	observability.IncJobRun("reschedule_option_generator")
+	actor := app.Actor{
+		UserID: uuidNew(),
+		OrgID:  uuidNew(),
+		Role:   domain.RoleAdmin,
+	}
+	generated, err := g.Options.GenerateForUnavailableParts(ctx, actor, 100)
+	if err != nil {
+		observability.IncJobFailure("reschedule_option_generator")
+		g.Logger.Error().Err(err).Msg("reschedule option generation failed")
+		return
+	}
+	if generated > 0 {
+		g.Logger.Info().Int("tasks", generated).Msg("reschedule options generated")
+	}
+}
@@ -14,8 +14,10 @@ type RetentionCleaner struct {
 	Orgs      ports.OrganizationRepository
 	Retention ports.RetentionRepository
 	Policies  *services.OrgPolicyService
-	Logger    zerolog.Logger
-	Interval  time.Duration
+	// Attachments purges expired attachments and their content. Optional.
+	Attachments *services.AttachmentService
+	Logger      zerolog.Logger
+	Interval    time.Duration
 }
 
 func (c *RetentionCleaner) Run(ctx context.Context) {
@@ -69,12 +71,24 @@ func (c *RetentionCleaner) processOnce(ctx context.Context) {
 				}
 			}
 			cutoff := now.Add(-retentionInterval)
+			// Attachments go before the rows they hang off, which are
+			// needed to tell which attachments have expired.
+			var purged int64
+			if c.Attachments != nil {
+				purged, err = c.Attachments.PurgeExpired(ctx, org.ID, cutoff)
+				if err != nil {
+					hadError = true
+					c.Logger.Error().Err(err).Str("org_id", org.ID.String()).Msg("retention attachment purge failed")
+					continue
+				}
+			}
 			stats, err := c.Retention.Cleanup(ctx, org.ID, cutoff)
 			if err != nil {
 				hadError = true
 				c.Logger.Error().Err(err).Str("org_id", org.ID.String()).Msg("retention cleanup failed")
 				continue
 			}
+			stats.Attachments = purged
 			if stats.Total() > 0 {
 				c.Logger.Info().
 					Str("org_id", org.ID.String()).
@@ -87,6 +101,7 @@ func (c *RetentionCleaner) processOnce(ctx context.Context) {
 					Int64("part_definitions", stats.PartDefinitions).
 					Int64("aircraft", stats.Aircraft).
 					Int64("users", stats.Users).
+					Int64("attachments", stats.Attachments).
 					Msg("retention cleanup complete")
 			}
 		}
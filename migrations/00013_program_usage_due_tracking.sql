@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- Aircraft usage recorded when a program was last performed, so flight-hour
+-- and cycle based programs can compute their next due point.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN last_performed_hours int CHECK (last_performed_hours >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN last_performed_cycles int CHECK (last_performed_cycles >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS maintenance_programs_due_idx ON maintenance_programs (interval_type, org_id, aircraft_id) WHERE deleted_at IS NULL AND aircraft_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS maintenance_programs_due_idx;
+
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS last_performed_cycles;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS last_performed_hours;
@@ -0,0 +1,41 @@
+-- +goose Up
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  CREATE TYPE utilization_entry_type AS ENUM ('flight', 'daily');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+ALTER TYPE import_type ADD VALUE IF NOT EXISTS 'utilization';
+
+-- Aircraft utilization log. Each entry records the block hours and cycles
+-- flown plus the running totals after the entry, which are rolled up onto
+-- aircraft.flight_hours_total and aircraft.cycles_total.
+CREATE TABLE IF NOT EXISTS aircraft_utilization (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  aircraft_id uuid NOT NULL,
+  entry_type utilization_entry_type NOT NULL,
+  utilization_date date NOT NULL,
+  flight_number text,
+  block_hours numeric(8,2) NOT NULL CHECK (block_hours >= 0),
+  cycles int NOT NULL CHECK (cycles >= 0),
+  hours_total numeric(12,2) NOT NULL CHECK (hours_total >= 0),
+  cycles_total int NOT NULL CHECK (cycles_total >= 0),
+  recorded_by uuid,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id),
+  FOREIGN KEY (org_id, recorded_by) REFERENCES users(org_id, id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS aircraft_utilization_aircraft_idx ON aircraft_utilization (org_id, aircraft_id, utilization_date DESC, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS aircraft_utilization_daily_uniq ON aircraft_utilization (org_id, aircraft_id, utilization_date) WHERE entry_type = 'daily';
+
+-- +goose Down
+DROP INDEX IF EXISTS aircraft_utilization_daily_uniq;
+DROP INDEX IF EXISTS aircraft_utilization_aircraft_idx;
+DROP TABLE IF EXISTS aircraft_utilization;
+DROP TYPE IF EXISTS utilization_entry_type;
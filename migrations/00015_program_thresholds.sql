@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- Tolerance on the primary interval, as a percentage either side of the due
+-- point.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN tolerance_percent int NOT NULL DEFAULT 0 CHECK (tolerance_percent BETWEEN 0 AND 50);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Additional "whichever comes first" thresholds, stored as an array of
+-- {interval_type, interval_value, tolerance_percent} objects.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN thresholds jsonb NOT NULL DEFAULT '[]'::jsonb;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS thresholds;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS tolerance_percent;
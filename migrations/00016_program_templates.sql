@@ -0,0 +1,57 @@
+-- +goose Up
+
+-- Program templates are defined once per aircraft type and instantiated as a
+-- maintenance program on every aircraft of that type. revision is bumped when
+-- the definition changes so instances can be brought up to date.
+CREATE TABLE IF NOT EXISTS maintenance_program_templates (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  aircraft_type_id uuid NOT NULL REFERENCES aircraft_types(id),
+  name text NOT NULL,
+  interval_type maintenance_program_interval_type NOT NULL,
+  interval_value int NOT NULL CHECK (interval_value > 0),
+  tolerance_percent int NOT NULL DEFAULT 0 CHECK (tolerance_percent BETWEEN 0 AND 50),
+  thresholds jsonb NOT NULL DEFAULT '[]'::jsonb,
+  revision int NOT NULL DEFAULT 1 CHECK (revision > 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  deleted_at timestamptz,
+  UNIQUE (org_id, id)
+);
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN template_id uuid;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN template_revision int;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD CONSTRAINT maintenance_programs_template_fk
+    FOREIGN KEY (org_id, template_id) REFERENCES maintenance_program_templates(org_id, id);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS maintenance_program_templates_name_uniq ON maintenance_program_templates (org_id, aircraft_type_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS maintenance_programs_template_aircraft_uniq ON maintenance_programs (org_id, template_id, aircraft_id) WHERE deleted_at IS NULL AND template_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS aircraft_type_idx ON aircraft (org_id, aircraft_type_id) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS aircraft_type_idx;
+DROP INDEX IF EXISTS maintenance_programs_template_aircraft_uniq;
+DROP INDEX IF EXISTS maintenance_program_templates_name_uniq;
+
+ALTER TABLE maintenance_programs DROP CONSTRAINT IF EXISTS maintenance_programs_template_fk;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS template_revision;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS maintenance_program_templates;
@@ -0,0 +1,57 @@
+-- +goose Up
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  CREATE TYPE work_package_state AS ENUM ('planned', 'in_progress', 'completed', 'cancelled');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- A work package bundles the tasks of one aircraft into a single hangar
+-- visit, e.g. an A-check, planned between visit_start and visit_end.
+CREATE TABLE IF NOT EXISTS work_packages (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  aircraft_id uuid NOT NULL,
+  name text NOT NULL,
+  state work_package_state NOT NULL DEFAULT 'planned',
+  visit_start timestamptz NOT NULL,
+  visit_end timestamptz NOT NULL,
+  notes text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  deleted_at timestamptz,
+  UNIQUE (org_id, id),
+  CHECK (visit_end > visit_start),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id)
+);
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD COLUMN work_package_id uuid;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD CONSTRAINT maintenance_tasks_work_package_fk
+    FOREIGN KEY (org_id, work_package_id) REFERENCES work_packages(org_id, id);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS work_packages_aircraft_idx ON work_packages (org_id, aircraft_id, visit_start) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS work_packages_state_idx ON work_packages (org_id, state) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS maintenance_tasks_work_package_idx ON maintenance_tasks (org_id, work_package_id) WHERE deleted_at IS NULL AND work_package_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS maintenance_tasks_work_package_idx;
+DROP INDEX IF EXISTS work_packages_state_idx;
+DROP INDEX IF EXISTS work_packages_aircraft_idx;
+
+ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_work_package_fk;
+ALTER TABLE maintenance_tasks DROP COLUMN IF EXISTS work_package_id;
+DROP TABLE IF EXISTS work_packages;
+DROP TYPE IF EXISTS work_package_state;
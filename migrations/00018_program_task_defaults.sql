@@ -0,0 +1,47 @@
+-- +goose Up
+
+-- Defaults applied to tasks generated from a program. A NULL
+-- estimated_duration_minutes keeps the task spanning the due window.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN task_type maintenance_task_type NOT NULL DEFAULT 'inspection';
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN priority task_priority NOT NULL DEFAULT 'routine';
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN estimated_duration_minutes int CHECK (estimated_duration_minutes > 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Compliance checklist, stored as an array of item descriptions. Each entry
+-- becomes a pending compliance item on every generated task.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN compliance_checklist jsonb NOT NULL DEFAULT '[]'::jsonb;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_programs ADD COLUMN default_notes text NOT NULL DEFAULT '';
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS default_notes;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS compliance_checklist;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS estimated_duration_minutes;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS priority;
+ALTER TABLE maintenance_programs DROP COLUMN IF EXISTS task_type;
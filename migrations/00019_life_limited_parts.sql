@@ -0,0 +1,79 @@
+-- +goose Up
+
+-- Aircraft a serialized part is currently fitted to. Utilization recorded
+-- against that aircraft accrues onto the part's usage counters.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN aircraft_id uuid;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD CONSTRAINT part_items_aircraft_fk
+    FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Life limits apply to usage since new. A NULL limit means the part is not
+-- life-limited on that measure.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN life_limit_hours numeric(12,2) CHECK (life_limit_hours > 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN life_limit_cycles int CHECK (life_limit_cycles > 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN hours_since_new numeric(12,2) NOT NULL DEFAULT 0 CHECK (hours_since_new >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN cycles_since_new int NOT NULL DEFAULT 0 CHECK (cycles_since_new >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN hours_since_overhaul numeric(12,2) NOT NULL DEFAULT 0 CHECK (hours_since_overhaul >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN cycles_since_overhaul int NOT NULL DEFAULT 0 CHECK (cycles_since_overhaul >= 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS part_items_aircraft_idx ON part_items (org_id, aircraft_id) WHERE deleted_at IS NULL AND aircraft_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS part_items_life_limited_idx ON part_items (org_id) WHERE deleted_at IS NULL AND (life_limit_hours IS NOT NULL OR life_limit_cycles IS NOT NULL);
+
+-- +goose Down
+DROP INDEX IF EXISTS part_items_life_limited_idx;
+DROP INDEX IF EXISTS part_items_aircraft_idx;
+
+ALTER TABLE part_items DROP COLUMN IF EXISTS cycles_since_overhaul;
+ALTER TABLE part_items DROP COLUMN IF EXISTS hours_since_overhaul;
+ALTER TABLE part_items DROP COLUMN IF EXISTS cycles_since_new;
+ALTER TABLE part_items DROP COLUMN IF EXISTS hours_since_new;
+ALTER TABLE part_items DROP COLUMN IF EXISTS life_limit_cycles;
+ALTER TABLE part_items DROP COLUMN IF EXISTS life_limit_hours;
+ALTER TABLE part_items DROP CONSTRAINT IF EXISTS part_items_aircraft_fk;
+ALTER TABLE part_items DROP COLUMN IF EXISTS aircraft_id;
@@ -0,0 +1,66 @@
+-- +goose Up
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  CREATE TYPE component_change_action AS ENUM ('install', 'remove');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  CREATE TYPE component_change_reason AS ENUM ('scheduled', 'unscheduled', 'robbery');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Position the part occupies on its aircraft, e.g. "ENG1" or "MLG-LH".
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_items ADD COLUMN position text;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Installation and removal log kept for traceability. Rows are never updated
+-- or deleted; the aircraft and part usage are captured at the time of the
+-- change.
+CREATE TABLE IF NOT EXISTS component_changes (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  part_item_id uuid NOT NULL,
+  aircraft_id uuid NOT NULL,
+  action component_change_action NOT NULL,
+  position text NOT NULL,
+  task_id uuid,
+  reason component_change_reason NOT NULL,
+  aircraft_hours numeric(12,2) NOT NULL CHECK (aircraft_hours >= 0),
+  aircraft_cycles int NOT NULL CHECK (aircraft_cycles >= 0),
+  part_hours_since_new numeric(12,2) NOT NULL CHECK (part_hours_since_new >= 0),
+  part_cycles_since_new int NOT NULL CHECK (part_cycles_since_new >= 0),
+  part_hours_since_overhaul numeric(12,2) NOT NULL CHECK (part_hours_since_overhaul >= 0),
+  part_cycles_since_overhaul int NOT NULL CHECK (part_cycles_since_overhaul >= 0),
+  performed_by uuid,
+  performed_at timestamptz NOT NULL,
+  notes text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, part_item_id) REFERENCES part_items(org_id, id),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS part_items_position_uniq ON part_items (org_id, aircraft_id, position) WHERE deleted_at IS NULL AND aircraft_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS component_changes_part_idx ON component_changes (org_id, part_item_id, performed_at);
+CREATE INDEX IF NOT EXISTS component_changes_aircraft_idx ON component_changes (org_id, aircraft_id, performed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS component_changes_aircraft_idx;
+DROP INDEX IF EXISTS component_changes_part_idx;
+DROP INDEX IF EXISTS part_items_position_uniq;
+
+DROP TABLE IF EXISTS component_changes;
+ALTER TABLE part_items DROP COLUMN IF EXISTS position;
+DROP TYPE IF EXISTS component_change_reason;
+DROP TYPE IF EXISTS component_change_action;
@@ -0,0 +1,110 @@
+-- +goose Up
+
+-- A station is a maintenance base; each of its hangar bays holds up to
+-- capacity_slots aircraft at a time.
+CREATE TABLE IF NOT EXISTS stations (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  code text NOT NULL,
+  name text NOT NULL,
+  timezone text NOT NULL DEFAULT 'UTC',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  deleted_at timestamptz,
+  UNIQUE (org_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS hangar_bays (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  station_id uuid NOT NULL,
+  code text NOT NULL,
+  name text NOT NULL,
+  hangar text NOT NULL DEFAULT '',
+  capacity_slots int NOT NULL CHECK (capacity_slots > 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  deleted_at timestamptz,
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, station_id) REFERENCES stations(org_id, id)
+);
+
+-- Capacity windows override a bay's slot count for a period, e.g. a bay
+-- closed for floor works (0 slots). Windows of one bay never overlap.
+CREATE TABLE IF NOT EXISTS bay_capacity_windows (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  bay_id uuid NOT NULL,
+  start_time timestamptz NOT NULL,
+  end_time timestamptz NOT NULL,
+  capacity_slots int NOT NULL CHECK (capacity_slots >= 0),
+  reason text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  period tstzrange GENERATED ALWAYS AS (tstzrange(start_time, end_time, '[)')) STORED,
+  CHECK (end_time > start_time),
+  FOREIGN KEY (org_id, bay_id) REFERENCES hangar_bays(org_id, id),
+  CONSTRAINT bay_capacity_windows_no_overlap EXCLUDE USING gist (bay_id WITH =, period WITH &&)
+);
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD COLUMN bay_id uuid;
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD COLUMN bay_slot int CHECK (bay_slot > 0);
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD CONSTRAINT maintenance_tasks_bay_fk
+    FOREIGN KEY (org_id, bay_id) REFERENCES hangar_bays(org_id, id);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks ADD CONSTRAINT maintenance_tasks_bay_slot_check
+    CHECK ((bay_id IS NULL) = (bay_slot IS NULL));
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Each slot of a bay holds one active task at a time. active_window is null
+-- once a task is closed or deleted, which frees its slot.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks
+    ADD CONSTRAINT maintenance_tasks_bay_slot_no_overlap
+    EXCLUDE USING gist (bay_id WITH =, bay_slot WITH =, active_window WITH &&);
+EXCEPTION WHEN duplicate_object THEN NULL;
+         WHEN duplicate_table THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS stations_org_code_uniq ON stations (org_id, code) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS hangar_bays_station_code_uniq ON hangar_bays (org_id, station_id, code) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS bay_capacity_windows_bay_idx ON bay_capacity_windows (org_id, bay_id, start_time);
+CREATE INDEX IF NOT EXISTS maintenance_tasks_bay_idx ON maintenance_tasks (org_id, bay_id, start_time) WHERE deleted_at IS NULL AND bay_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS maintenance_tasks_bay_idx;
+DROP INDEX IF EXISTS bay_capacity_windows_bay_idx;
+DROP INDEX IF EXISTS hangar_bays_station_code_uniq;
+DROP INDEX IF EXISTS stations_org_code_uniq;
+
+ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_bay_slot_no_overlap;
+ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_bay_slot_check;
+ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_bay_fk;
+ALTER TABLE maintenance_tasks DROP COLUMN IF EXISTS bay_slot;
+ALTER TABLE maintenance_tasks DROP COLUMN IF EXISTS bay_id;
+DROP TABLE IF EXISTS bay_capacity_windows;
+DROP TABLE IF EXISTS hangar_bays;
+DROP TABLE IF EXISTS stations;
@@ -0,0 +1,95 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS schedule_plans (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  state text NOT NULL DEFAULT 'proposed'
+    CHECK (state IN ('proposed', 'applied', 'discarded')),
+  horizon_start timestamptz NOT NULL,
+  horizon_end timestamptz NOT NULL,
+  station_id uuid,
+  created_by uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  applied_at timestamptz,
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, station_id) REFERENCES stations(org_id, id),
+  CHECK (horizon_end > horizon_start)
+);
+
+CREATE TABLE IF NOT EXISTS schedule_plan_items (
+  plan_id uuid NOT NULL,
+  org_id uuid NOT NULL,
+  task_id uuid NOT NULL,
+  position int NOT NULL,
+  aircraft_id uuid NOT NULL,
+  priority task_priority NOT NULL,
+  task_updated_at timestamptz NOT NULL,
+  old_start_time timestamptz NOT NULL,
+  old_end_time timestamptz NOT NULL,
+  old_bay_id uuid,
+  old_mechanic_id uuid,
+  start_time timestamptz,
+  end_time timestamptz,
+  bay_id uuid,
+  bay_slot int,
+  mechanic_id uuid,
+  reason text NOT NULL DEFAULT '',
+  PRIMARY KEY (plan_id, task_id),
+  FOREIGN KEY (org_id, plan_id) REFERENCES schedule_plans(org_id, id) ON DELETE CASCADE,
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id),
+  CHECK ((start_time IS NULL) = (end_time IS NULL)),
+  CHECK (end_time IS NULL OR end_time > start_time)
+);
+
+-- Applying a plan moves several tasks in one transaction, possibly into
+-- windows another planned task is leaving. The overlap constraints are
+-- therefore checked at commit rather than per statement when a transaction
+-- asks for it.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_no_overlap;
+  ALTER TABLE maintenance_tasks
+    ADD CONSTRAINT maintenance_tasks_no_overlap
+    EXCLUDE USING gist (org_id WITH =, aircraft_id WITH =, active_window WITH &&)
+    DEFERRABLE INITIALLY IMMEDIATE;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_bay_slot_no_overlap;
+  ALTER TABLE maintenance_tasks
+    ADD CONSTRAINT maintenance_tasks_bay_slot_no_overlap
+    EXCLUDE USING gist (bay_id WITH =, bay_slot WITH =, active_window WITH &&)
+    DEFERRABLE INITIALLY IMMEDIATE;
+END $$;
+-- +goose StatementEnd
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS schedule_plans_org_state_idx ON schedule_plans (org_id, state, created_at DESC);
+CREATE INDEX IF NOT EXISTS schedule_plan_items_task_idx ON schedule_plan_items (org_id, task_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS schedule_plan_items_task_idx;
+DROP INDEX IF EXISTS schedule_plans_org_state_idx;
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_bay_slot_no_overlap;
+  ALTER TABLE maintenance_tasks
+    ADD CONSTRAINT maintenance_tasks_bay_slot_no_overlap
+    EXCLUDE USING gist (bay_id WITH =, bay_slot WITH =, active_window WITH &&);
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE maintenance_tasks DROP CONSTRAINT IF EXISTS maintenance_tasks_no_overlap;
+  ALTER TABLE maintenance_tasks
+    ADD CONSTRAINT maintenance_tasks_no_overlap
+    EXCLUDE USING gist (org_id WITH =, aircraft_id WITH =, active_window WITH &&);
+END $$;
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS schedule_plan_items;
+DROP TABLE IF EXISTS schedule_plans;
@@ -0,0 +1,64 @@
+-- +goose Up
+
+-- A shift pattern repeats weekly: on each day in weekdays (bit 0 = Sunday) a
+-- shift starts start_minute minutes after local midnight in timezone and
+-- lasts duration_minutes.
+CREATE TABLE IF NOT EXISTS shift_patterns (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  name text NOT NULL,
+  timezone text NOT NULL DEFAULT 'UTC',
+  start_minute int NOT NULL CHECK (start_minute >= 0 AND start_minute < 1440),
+  duration_minutes int NOT NULL CHECK (duration_minutes > 0 AND duration_minutes <= 1440),
+  weekdays smallint NOT NULL CHECK (weekdays > 0 AND weekdays < 128),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  deleted_at timestamptz,
+  UNIQUE (org_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS roster_assignments (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  mechanic_id uuid NOT NULL,
+  shift_pattern_id uuid NOT NULL,
+  station_id uuid,
+  effective_from timestamptz NOT NULL,
+  effective_to timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CHECK (effective_to IS NULL OR effective_to > effective_from),
+  FOREIGN KEY (org_id, mechanic_id) REFERENCES users(org_id, id),
+  FOREIGN KEY (org_id, shift_pattern_id) REFERENCES shift_patterns(org_id, id),
+  FOREIGN KEY (org_id, station_id) REFERENCES stations(org_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS mechanic_absences (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  mechanic_id uuid NOT NULL,
+  kind text NOT NULL CHECK (kind IN ('leave', 'sick', 'training', 'other')),
+  start_time timestamptz NOT NULL,
+  end_time timestamptz NOT NULL,
+  reason text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CHECK (end_time > start_time),
+  FOREIGN KEY (org_id, mechanic_id) REFERENCES users(org_id, id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS shift_patterns_org_idx ON shift_patterns (org_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS roster_assignments_mechanic_idx ON roster_assignments (org_id, mechanic_id, effective_from);
+CREATE INDEX IF NOT EXISTS roster_assignments_station_idx ON roster_assignments (org_id, station_id) WHERE station_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS mechanic_absences_mechanic_idx ON mechanic_absences (org_id, mechanic_id, start_time);
+CREATE INDEX IF NOT EXISTS maintenance_tasks_mechanic_window_idx ON maintenance_tasks (org_id, assigned_mechanic_id, start_time)
+  WHERE assigned_mechanic_id IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS maintenance_tasks_mechanic_window_idx;
+DROP INDEX IF EXISTS mechanic_absences_mechanic_idx;
+DROP INDEX IF EXISTS roster_assignments_station_idx;
+DROP INDEX IF EXISTS roster_assignments_mechanic_idx;
+DROP INDEX IF EXISTS shift_patterns_org_idx;
+DROP TABLE IF EXISTS mechanic_absences;
+DROP TABLE IF EXISTS roster_assignments;
+DROP TABLE IF EXISTS shift_patterns;
@@ -0,0 +1,64 @@
+-- +goose Up
+
+-- Part definitions that may stand in for this one when it is not in stock.
+-- +goose StatementBegin
+DO $$ BEGIN
+  ALTER TABLE part_definitions ADD COLUMN alternate_ids uuid[] NOT NULL DEFAULT '{}';
+EXCEPTION WHEN duplicate_column THEN NULL;
+END $$;
+-- +goose StatementEnd
+
+-- Using a substitute part changes a task's reservations rather than its
+-- window, and is recorded as a schedule change of its own.
+ALTER TABLE schedule_change_events DROP CONSTRAINT IF EXISTS schedule_change_events_change_type_check;
+ALTER TABLE schedule_change_events
+  ADD CONSTRAINT schedule_change_events_change_type_check
+  CHECK (change_type IN ('rescheduled', 'cancelled', 'priority_changed', 'mechanic_reassigned', 'part_substituted'));
+
+-- Ranked ways around a part a task cannot get in time. The proposed
+-- options of a task are replaced each time they are generated; applying one
+-- discards the rest.
+CREATE TABLE IF NOT EXISTS reschedule_options (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  task_id uuid NOT NULL,
+  reservation_id uuid,
+  part_definition_id uuid NOT NULL,
+  rank int NOT NULL CHECK (rank > 0),
+  state text NOT NULL DEFAULT 'proposed'
+    CHECK (state IN ('proposed', 'applied', 'discarded')),
+  option_type text NOT NULL
+    CHECK (option_type IN ('delay', 'substitute_part', 'swap', 'split')),
+  description text NOT NULL,
+  new_start_time timestamptz,
+  new_end_time timestamptz,
+  affected_task_ids uuid[] NOT NULL DEFAULT '{}',
+  substitute_part_id uuid,
+  task_updated_at timestamptz NOT NULL,
+  schedule_change_id uuid,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  applied_at timestamptz,
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id),
+  FOREIGN KEY (org_id, reservation_id) REFERENCES part_reservations(org_id, id),
+  FOREIGN KEY (org_id, part_definition_id) REFERENCES part_definitions(org_id, id),
+  FOREIGN KEY (org_id, substitute_part_id) REFERENCES part_items(org_id, id),
+  CHECK ((new_start_time IS NULL) = (new_end_time IS NULL)),
+  CHECK (new_end_time IS NULL OR new_end_time > new_start_time)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS reschedule_options_task_idx ON reschedule_options (org_id, task_id, state);
+
+-- +goose Down
+DROP INDEX IF EXISTS reschedule_options_task_idx;
+DROP TABLE IF EXISTS reschedule_options;
+
+DELETE FROM schedule_change_events WHERE change_type = 'part_substituted';
+ALTER TABLE schedule_change_events DROP CONSTRAINT IF EXISTS schedule_change_events_change_type_check;
+ALTER TABLE schedule_change_events
+  ADD CONSTRAINT schedule_change_events_change_type_check
+  CHECK (change_type IN ('rescheduled', 'cancelled', 'priority_changed', 'mechanic_reassigned'));
+
+ALTER TABLE part_definitions DROP COLUMN IF EXISTS alternate_ids;
@@ -0,0 +1,41 @@
+-- +goose Up
+
+ALTER TYPE import_type ADD VALUE IF NOT EXISTS 'flight_schedule';
+
+-- Flights each aircraft is planned to operate, which tell when and where it
+-- is on the ground for maintenance. Origin and destination are station
+-- codes. airborne is null once a flight is cancelled, so only scheduled
+-- flights of one aircraft are kept from overlapping.
+CREATE TABLE IF NOT EXISTS planned_flights (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  aircraft_id uuid NOT NULL,
+  flight_number text NOT NULL,
+  origin text NOT NULL,
+  destination text NOT NULL,
+  departure_time timestamptz NOT NULL,
+  arrival_time timestamptz NOT NULL,
+  status text NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'cancelled')),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  airborne tstzrange GENERATED ALWAYS AS (
+    CASE WHEN status = 'scheduled' THEN tstzrange(departure_time, arrival_time, '[)') ELSE NULL END
+  ) STORED,
+  CHECK (arrival_time > departure_time),
+  UNIQUE (org_id, aircraft_id, flight_number, departure_time),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id),
+  CONSTRAINT planned_flights_no_overlap EXCLUDE USING gist (aircraft_id WITH =, airborne WITH &&)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS planned_flights_aircraft_idx ON planned_flights (org_id, aircraft_id, departure_time);
+CREATE INDEX IF NOT EXISTS planned_flights_arrival_idx ON planned_flights (org_id, aircraft_id, arrival_time DESC) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS planned_flights_origin_idx ON planned_flights (org_id, origin, departure_time);
+CREATE INDEX IF NOT EXISTS planned_flights_destination_idx ON planned_flights (org_id, destination, arrival_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS planned_flights_destination_idx;
+DROP INDEX IF EXISTS planned_flights_origin_idx;
+DROP INDEX IF EXISTS planned_flights_arrival_idx;
+DROP INDEX IF EXISTS planned_flights_aircraft_idx;
+DROP TABLE IF EXISTS planned_flights;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Task cards break a maintenance task into ordered steps. A step may ask for
+-- a measured value within a tolerance, and a required inspection item (RII)
+-- step needs a second sign-off by an authorized inspector who did not
+-- perform it.
+CREATE TABLE IF NOT EXISTS task_cards (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  task_id uuid NOT NULL,
+  title text NOT NULL,
+  reference text NOT NULL DEFAULT '',
+  created_by uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS task_card_steps (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL,
+  card_id uuid NOT NULL,
+  position int NOT NULL,
+  instruction text NOT NULL,
+  required_inspection boolean NOT NULL DEFAULT false,
+  measurement_unit text NOT NULL DEFAULT '',
+  min_value double precision,
+  max_value double precision,
+  measured_value double precision,
+  performed_by uuid,
+  performed_at timestamptz,
+  inspected_by uuid,
+  inspected_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (card_id, position),
+  FOREIGN KEY (org_id, card_id) REFERENCES task_cards(org_id, id) ON DELETE CASCADE,
+  CHECK (min_value IS NULL OR max_value IS NULL OR max_value >= min_value),
+  CHECK ((performed_by IS NULL) = (performed_at IS NULL)),
+  CHECK ((inspected_by IS NULL) = (inspected_at IS NULL)),
+  CHECK (inspected_by IS NULL OR (required_inspection AND performed_by IS NOT NULL)),
+  CONSTRAINT task_card_steps_independent_inspection CHECK (inspected_by IS NULL OR inspected_by <> performed_by)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS task_cards_task_idx ON task_cards (org_id, task_id);
+CREATE INDEX IF NOT EXISTS task_card_steps_card_idx ON task_card_steps (org_id, card_id, position);
+
+-- +goose Down
+DROP INDEX IF EXISTS task_card_steps_card_idx;
+DROP INDEX IF EXISTS task_cards_task_idx;
+DROP TABLE IF EXISTS task_card_steps;
+DROP TABLE IF EXISTS task_cards;
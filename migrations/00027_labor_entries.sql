@@ -0,0 +1,35 @@
+-- +goose Up
+-- Hours mechanics actually worked on tasks, either clocked in and out or
+-- entered by hand afterwards. An entry without ended_at is a running clock.
+-- A mechanic cannot book the same time twice; a running clock blocks every
+-- later entry of the mechanic until it is clocked out.
+CREATE TABLE IF NOT EXISTS labor_entries (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  task_id uuid NOT NULL,
+  mechanic_id uuid NOT NULL,
+  source text NOT NULL CHECK (source IN ('clock', 'manual')),
+  started_at timestamptz NOT NULL,
+  ended_at timestamptz,
+  notes text NOT NULL DEFAULT '',
+  created_by uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  worked tstzrange GENERATED ALWAYS AS (tstzrange(started_at, ended_at, '[)')) STORED,
+  CHECK (ended_at IS NULL OR ended_at > started_at),
+  CHECK (source = 'clock' OR ended_at IS NOT NULL),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id),
+  FOREIGN KEY (org_id, mechanic_id) REFERENCES users(org_id, id),
+  CONSTRAINT labor_entries_no_overlap EXCLUDE USING gist (org_id WITH =, mechanic_id WITH =, worked WITH &&)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS labor_entries_task_idx ON labor_entries (org_id, task_id, started_at);
+CREATE INDEX IF NOT EXISTS labor_entries_mechanic_idx ON labor_entries (org_id, mechanic_id, started_at);
+CREATE UNIQUE INDEX IF NOT EXISTS labor_entries_open_clock_idx ON labor_entries (org_id, mechanic_id) WHERE ended_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS labor_entries_open_clock_idx;
+DROP INDEX IF EXISTS labor_entries_mechanic_idx;
+DROP INDEX IF EXISTS labor_entries_task_idx;
+DROP TABLE IF EXISTS labor_entries;
@@ -0,0 +1,70 @@
+-- +goose Up
+-- Defects reported against an aircraft. An open defect may be deferred under
+-- a Minimum Equipment List (MEL) item, which lets the aircraft operate with
+-- the listed restrictions until the category's rectification interval runs
+-- out. Rectified defects are closed once reviewed.
+CREATE TABLE IF NOT EXISTS defects (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  aircraft_id uuid NOT NULL,
+  title text NOT NULL,
+  description text NOT NULL DEFAULT '',
+  ata_chapter text NOT NULL DEFAULT '',
+  state text NOT NULL CHECK (state IN ('open', 'deferred', 'rectified', 'closed')),
+  reported_by uuid NOT NULL,
+  reported_at timestamptz NOT NULL,
+  mel_category text CHECK (mel_category IN ('A', 'B', 'C', 'D')),
+  mel_item text NOT NULL DEFAULT '',
+  deferral_reference text NOT NULL DEFAULT '',
+  operational_restrictions text NOT NULL DEFAULT '',
+  deferred_by uuid,
+  deferred_at timestamptz,
+  expires_at timestamptz,
+  rectification_task_id uuid,
+  rectified_by uuid,
+  rectified_at timestamptz,
+  closed_by uuid,
+  closed_at timestamptz,
+  notes text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  CHECK ((mel_category IS NULL) = (deferred_at IS NULL)),
+  CHECK ((deferred_at IS NULL) = (expires_at IS NULL)),
+  CHECK ((deferred_at IS NULL) = (deferred_by IS NULL)),
+  CHECK (state <> 'deferred' OR deferred_at IS NOT NULL),
+  CHECK ((rectified_at IS NULL) = (rectified_by IS NULL)),
+  CHECK ((closed_at IS NULL) = (closed_by IS NULL)),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id),
+  FOREIGN KEY (org_id, rectification_task_id) REFERENCES maintenance_tasks(org_id, id)
+);
+
+-- Extensions of a deferral's rectification interval, each approved by the
+-- quality department under its own approval reference.
+CREATE TABLE IF NOT EXISTS defect_deferral_extensions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  defect_id uuid NOT NULL,
+  previous_expires_at timestamptz NOT NULL,
+  new_expires_at timestamptz NOT NULL,
+  reason text NOT NULL,
+  approval_reference text NOT NULL,
+  approved_by uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CHECK (new_expires_at > previous_expires_at),
+  FOREIGN KEY (org_id, defect_id) REFERENCES defects(org_id, id) ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS defects_aircraft_idx ON defects (org_id, aircraft_id, state);
+CREATE INDEX IF NOT EXISTS defects_expiry_idx ON defects (expires_at) WHERE state = 'deferred';
+CREATE UNIQUE INDEX IF NOT EXISTS defects_deferral_reference_idx ON defects (org_id, deferral_reference) WHERE deferral_reference <> '';
+CREATE INDEX IF NOT EXISTS defect_deferral_extensions_defect_idx ON defect_deferral_extensions (org_id, defect_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS defect_deferral_extensions_defect_idx;
+DROP INDEX IF EXISTS defects_deferral_reference_idx;
+DROP INDEX IF EXISTS defects_expiry_idx;
+DROP INDEX IF EXISTS defects_aircraft_idx;
+DROP TABLE IF EXISTS defect_deferral_extensions;
+DROP TABLE IF EXISTS defects;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Non-routine findings raised by mechanics while working a task, such as
+-- corrosion found during an inspection. A scheduler converts a finding into
+-- a repair task on the same aircraft that follows the task it was found on,
+-- or closes it when no work is needed.
+CREATE TABLE IF NOT EXISTS findings (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  task_id uuid NOT NULL,
+  aircraft_id uuid NOT NULL,
+  compliance_item_id uuid,
+  description text NOT NULL,
+  zone text NOT NULL DEFAULT '',
+  ata_chapter text NOT NULL DEFAULT '',
+  photo_urls text[] NOT NULL DEFAULT '{}',
+  state text NOT NULL CHECK (state IN ('open', 'converted', 'closed')),
+  raised_by uuid NOT NULL,
+  raised_at timestamptz NOT NULL,
+  repair_task_id uuid,
+  converted_by uuid,
+  converted_at timestamptz,
+  closed_by uuid,
+  closed_at timestamptz,
+  resolution_notes text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  CHECK ((state = 'converted') = (repair_task_id IS NOT NULL)),
+  CHECK ((converted_at IS NULL) = (converted_by IS NULL)),
+  CHECK ((closed_at IS NULL) = (closed_by IS NULL)),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id),
+  FOREIGN KEY (org_id, aircraft_id) REFERENCES aircraft(org_id, id),
+  FOREIGN KEY (org_id, repair_task_id) REFERENCES maintenance_tasks(org_id, id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS findings_task_idx ON findings (org_id, task_id, state);
+CREATE INDEX IF NOT EXISTS findings_aircraft_idx ON findings (org_id, aircraft_id, raised_at);
+CREATE UNIQUE INDEX IF NOT EXISTS findings_compliance_item_idx ON findings (org_id, compliance_item_id) WHERE compliance_item_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS findings_compliance_item_idx;
+DROP INDEX IF EXISTS findings_aircraft_idx;
+DROP INDEX IF EXISTS findings_task_idx;
+DROP TABLE IF EXISTS findings;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- The crew working a maintenance task, each mechanic in a role with the
+-- hours planned for them. Any crew member may start the task; completing it
+-- needs the certifying staff. A mechanic may hold more than one role on the
+-- same task, such as lead and certifying staff.
+CREATE TABLE IF NOT EXISTS task_assignments (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  task_id uuid NOT NULL,
+  mechanic_id uuid NOT NULL,
+  role text NOT NULL CHECK (role IN ('lead', 'technician', 'certifying_staff', 'inspector')),
+  planned_hours double precision NOT NULL DEFAULT 0 CHECK (planned_hours >= 0),
+  assigned_by uuid NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  UNIQUE (task_id, mechanic_id, role),
+  FOREIGN KEY (org_id, task_id) REFERENCES maintenance_tasks(org_id, id) ON DELETE CASCADE,
+  FOREIGN KEY (org_id, mechanic_id) REFERENCES users(org_id, id)
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS task_assignments_task_idx ON task_assignments (org_id, task_id);
+CREATE INDEX IF NOT EXISTS task_assignments_mechanic_idx ON task_assignments (org_id, mechanic_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS task_assignments_mechanic_idx;
+DROP INDEX IF EXISTS task_assignments_task_idx;
+DROP TABLE IF EXISTS task_assignments;
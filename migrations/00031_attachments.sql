@@ -0,0 +1,35 @@
+-- +goose Up
+-- Files kept as evidence against tasks, compliance items, findings and
+-- certifications. The content lives in the blob store under storage_key;
+-- sha256 is the hex digest of the content. Deleted attachments keep their
+-- row and blob until the retention cleanup purges both.
+CREATE TABLE IF NOT EXISTS attachments (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  org_id uuid NOT NULL REFERENCES organizations(id),
+  entity_type text NOT NULL CHECK (entity_type IN ('task', 'compliance_item', 'finding', 'certification')),
+  entity_id uuid NOT NULL,
+  file_name text NOT NULL,
+  content_type text NOT NULL,
+  size_bytes bigint NOT NULL CHECK (size_bytes > 0),
+  sha256 text NOT NULL,
+  storage_key text NOT NULL UNIQUE,
+  uploaded_by uuid NOT NULL,
+  deleted_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (org_id, id),
+  FOREIGN KEY (org_id, uploaded_by) REFERENCES users(org_id, id)
+);
+
+ALTER TABLE org_policies
+  ADD COLUMN IF NOT EXISTS max_attachment_bytes bigint NOT NULL DEFAULT 26214400 CHECK (max_attachment_bytes > 0);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS attachments_entity_idx ON attachments (org_id, entity_type, entity_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS attachments_deleted_idx ON attachments (org_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE org_policies DROP COLUMN IF EXISTS max_attachment_bytes;
+DROP INDEX IF EXISTS attachments_deleted_idx;
+DROP INDEX IF EXISTS attachments_entity_idx;
+DROP TABLE IF EXISTS attachments;